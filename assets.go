@@ -0,0 +1,167 @@
+package fuego
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Assets serves the static files of a filesystem under fingerprinted names, like "css/app.3f2a1b9c0d.css",
+// derived from the hash of their content. The fingerprinted files are sent with immutable cache headers:
+// browsers keep them until the next deployment changes their content, and so their names.
+// The templates get the fingerprinted URLs with the asset function, see [Assets.TemplateFuncs].
+//
+//	//go:embed static
+//	var static embed.FS
+//
+//	assets, err := fuego.NewAssets(must(fs.Sub(static, "static")), "/static/")
+//	s := fuego.NewServer(
+//		fuego.WithAssets(assets),
+//		fuego.WithTemplateGlobs("pages/*.html"),
+//	)
+//
+//	<link rel="stylesheet" href="{{ asset "css/app.css" }}">
+type Assets struct {
+	fs     fs.FS
+	prefix string
+	// Fingerprinted names of the files, by name.
+	manifest map[string]string
+	// Files, by fingerprinted name.
+	files map[string]assetFile
+}
+
+type assetFile struct {
+	name string
+	hash string
+}
+
+// NewAssets fingerprints the files of the filesystem, served under the URL prefix, like "/static/".
+func NewAssets(fsys fs.FS, prefix string) (*Assets, error) {
+	a := &Assets{
+		fs:       fsys,
+		prefix:   "/" + strings.Trim(prefix, "/") + "/",
+		manifest: make(map[string]string),
+		files:    make(map[string]assetFile),
+	}
+	if a.prefix == "//" {
+		a.prefix = "/"
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:5])
+
+		fingerprinted := fingerprintedName(name, hash)
+		a.manifest[name] = fingerprinted
+		a.files[fingerprinted] = assetFile{name: name, hash: hash}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// fingerprintedName inserts the hash before the extension of the name: "css/app.css" becomes "css/app.<hash>.css".
+func fingerprintedName(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// Path returns the URL of the fingerprinted file, like "/static/css/app.3f2a1b9c0d.css" for "css/app.css".
+// Unknown files keep their name.
+func (a *Assets) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if fingerprinted, ok := a.manifest[name]; ok {
+		return a.prefix + fingerprinted
+	}
+	return a.prefix + name
+}
+
+// Manifest returns the fingerprinted names of the files, by name.
+// It can be written as JSON for the tools that need the URLs of the assets, like a service worker.
+func (a *Assets) Manifest() map[string]string {
+	return maps.Clone(a.manifest)
+}
+
+// TemplateFuncs returns the asset function, resolving the URLs of the files with [Assets.Path].
+// It is added to the templates of the server by [WithAssets].
+// Use it when parsing the templates yourself:
+//
+//	templates := template.Must(template.New("").Funcs(fuego.TemplateFuncs()).Funcs(assets.TemplateFuncs()).ParseFS(fs, "*.html"))
+func (a *Assets) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"asset": a.Path,
+	}
+}
+
+// ServeHTTP serves the files under the prefix. The fingerprinted files are cached by the browsers for a year;
+// the files requested by their original name are revalidated with their ETag.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, a.prefix)
+	if file, ok := a.files[name]; ok {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		a.serve(w, r, file)
+		return
+	}
+	if fingerprinted, ok := a.manifest[name]; ok {
+		w.Header().Set("Cache-Control", "no-cache")
+		a.serve(w, r, a.files[fingerprinted])
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (a *Assets) serve(w http.ResponseWriter, r *http.Request, file assetFile) {
+	f, err := a.fs.Open(file.name)
+	if err != nil {
+		http.Error(w, "Cannot open asset", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, "Cannot read asset", http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+
+	w.Header().Set("ETag", `"`+file.hash+`"`)
+	http.ServeContent(w, r, file.name, time.Time{}, content)
+}
+
+// WithAssets serves the fingerprinted static files under their prefix, and adds the asset function
+// to the templates of the server. The route is hidden from the OpenAPI spec. See [Assets].
+func WithAssets(assets *Assets) func(*Server) {
+	return func(s *Server) { s.assets = assets }
+}
+
+// registerAssets registers the route of the static files, and resolves the asset function of the templates, if enabled.
+func (s *Server) registerAssets() {
+	if s.assets == nil {
+		return
+	}
+	if s.template != nil {
+		s.template.Funcs(s.assets.TemplateFuncs())
+	}
+	GetStd(s, s.assets.prefix+"{asset...}", s.assets.ServeHTTP, OptionHide())
+}
@@ -0,0 +1,81 @@
+package fuego
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssets(t *testing.T) {
+	static := fstest.MapFS{
+		"css/app.css":   {Data: []byte("body { color: red; }")},
+		"js/app.min.js": {Data: []byte("console.log('hello')")},
+	}
+	assets, err := NewAssets(static, "static")
+	require.NoError(t, err)
+
+	cssPath := assets.Path("css/app.css")
+	require.Regexp(t, `^/static/css/app\.[0-9a-f]{10}\.css$`, cssPath)
+	require.Regexp(t, `^/static/js/app\.min\.[0-9a-f]{10}\.js$`, assets.Path("/js/app.min.js"))
+	require.Equal(t, "/static/unknown.png", assets.Path("unknown.png"))
+	require.Len(t, assets.Manifest(), 2)
+
+	t.Run("fingerprint changes with the content", func(t *testing.T) {
+		changed, err := NewAssets(fstest.MapFS{"css/app.css": {Data: []byte("body { color: blue; }")}}, "/static/")
+		require.NoError(t, err)
+		require.NotEqual(t, cssPath, changed.Path("css/app.css"))
+	})
+
+	templates := template.Must(template.New("").Funcs(TemplateFuncs()).Parse(
+		`{{define "page.html"}}<link rel="stylesheet" href="{{ asset "css/app.css" }}">{{end}}`,
+	))
+	s := NewServer(WithoutLogger(), WithTemplateFS(testdata), WithTemplates(templates), WithAssets(assets))
+	Get(s, "/", func(c ContextNoBody) (CtxRenderer, error) {
+		return c.Render("page.html", nil)
+	})
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("asset template function", func(t *testing.T) {
+		w := get("/")
+		require.Equal(t, `<link rel="stylesheet" href="`+cssPath+`">`, w.Body.String())
+	})
+
+	t.Run("fingerprinted files are immutable", func(t *testing.T) {
+		w := get(cssPath)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "body { color: red; }", w.Body.String())
+		require.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+		require.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		require.Equal(t, http.StatusNotModified, get(cssPath, "If-None-Match", etag).Code)
+	})
+
+	t.Run("original names are revalidated", func(t *testing.T) {
+		w := get("/static/css/app.css")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("unknown files", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, get("/static/css/app.0000000000.css").Code)
+		require.NotEqual(t, http.StatusOK, get("/static/../assets.go").Code)
+	})
+
+	t.Run("route hidden from the spec", func(t *testing.T) {
+		require.Nil(t, s.OpenAPI.Description().Paths.Find("/static/{asset...}"))
+	})
+}
@@ -0,0 +1,73 @@
+package fuego
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// BatchResponse is the response of a [Batch] route: the result of each item of the request, in the same order.
+type BatchResponse[R any] struct {
+	Results []BatchItemResult[R] `json:"results" xml:"results" yaml:"results"`
+}
+
+// BatchItemResult is the result of one item of a [Batch] request.
+// Result is set if the item succeeded, Error otherwise.
+type BatchItemResult[R any] struct {
+	// HTTP status code of the item: 200 if it succeeded, the status of its error otherwise.
+	Status int        `json:"status" xml:"status" yaml:"status" description:"HTTP status code of the item" example:"200"`
+	Result *R         `json:"result,omitempty" xml:"result,omitempty" yaml:"result,omitempty"`
+	Error  *HTTPError `json:"error,omitempty" xml:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Batch registers a POST route accepting a list of items, and calls the handler for each item.
+// Each item is transformed and validated on its own, like a request body:
+// an invalid item, or an item for which the handler returns an error, does not fail the others.
+//
+// The route responds with a 207 Multi-Status status and a [BatchResponse] giving the status
+// and the result or the error of each item, in the order of the request.
+// The request fails as a whole only if the body cannot be read.
+// The request body is documented as an array of T, and the response as the [BatchResponse] envelope.
+//
+//	fuego.Batch(s, "/users:batchCreate", func(c fuego.ContextWithBody[[]UserCreate], user UserCreate) (User, error) {
+//		return userService.Create(c, user)
+//	}, option.Summary("Create users"))
+func Batch[T, R any](s *Server, path string, handler func(c ContextWithBody[[]T], item T) (R, error), options ...func(*BaseRoute)) *Route[BatchResponse[R], []T] {
+	options = slices.Concat([]func(*BaseRoute){OptionDefaultStatusCode(http.StatusMultiStatus)}, options, []func(*BaseRoute){
+		func(r *BaseRoute) { r.FullName = FuncName(handler) },
+	})
+
+	return registerFuegoController(s, http.MethodPost, path, func(c ContextWithBody[[]T]) (BatchResponse[R], error) {
+		items, err := c.Body()
+		if err != nil {
+			return BatchResponse[R]{}, err
+		}
+
+		response := BatchResponse[R]{Results: make([]BatchItemResult[R], len(items))}
+		for i, item := range items {
+			response.Results[i] = batchItem(s, c, item, handler)
+		}
+		return response, nil
+	}, options...)
+}
+
+// batchItem validates the item and calls the handler with it.
+func batchItem[T, R any](s *Server, c ContextWithBody[[]T], item T, handler func(ContextWithBody[[]T], T) (R, error)) BatchItemResult[R] {
+	item, err := TransformAndValidate(c, item)
+	if err == nil {
+		var result R
+		result, err = handler(c, item)
+		if err == nil {
+			return BatchItemResult[R]{Status: http.StatusOK, Result: &result}
+		}
+	}
+
+	err = s.handleError(c, err)
+	var httpError HTTPError
+	if !errors.As(err, &httpError) {
+		// Same as the errors without status of the controllers: the details are not sent
+		httpError = HTTPError{Err: err, Title: http.StatusText(http.StatusInternalServerError)}
+	}
+	httpError.Status = httpError.StatusCode()
+	return BatchItemResult[R]{Status: httpError.Status, Error: &httpError}
+}
@@ -0,0 +1,88 @@
+package fuego
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type batchUser struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestBatch(t *testing.T) {
+	s := NewServer()
+	Batch(s, "/users:batchCreate", func(c ContextWithBody[[]batchUser], user batchUser) (MyStruct, error) {
+		if user.Name == "taken" {
+			return MyStruct{}, ConflictError{Detail: "name is already taken"}
+		}
+		if user.Name == "broken" {
+			return MyStruct{}, errors.New("database is down")
+		}
+		return MyStruct{B: user.Name}, nil
+	}, OptionTags("users"))
+
+	t.Run("returns the result of each item", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/users:batchCreate", strings.NewReader(`[{"name":"Ada"},{},{"name":"taken"},{"name":"broken"}]`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusMultiStatus, w.Code)
+		var response BatchResponse[MyStruct]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Results, 4)
+
+		require.Equal(t, BatchItemResult[MyStruct]{Status: http.StatusOK, Result: &MyStruct{B: "Ada"}}, response.Results[0])
+
+		require.Equal(t, http.StatusBadRequest, response.Results[1].Status)
+		require.Nil(t, response.Results[1].Result)
+		require.Equal(t, "Validation Error", response.Results[1].Error.Title)
+		require.Equal(t, "batchUser.Name", response.Results[1].Error.Errors[0].Name)
+
+		require.Equal(t, http.StatusConflict, response.Results[2].Status)
+		require.Equal(t, "name is already taken", response.Results[2].Error.Detail)
+
+		require.Equal(t, http.StatusInternalServerError, response.Results[3].Status)
+		require.Equal(t, HTTPError{Title: "Internal Server Error", Status: http.StatusInternalServerError}, *response.Results[3].Error)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/users:batchCreate", strings.NewReader(`[]`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusMultiStatus, w.Code)
+		require.JSONEq(t, `{"results":[]}`, w.Body.String())
+	})
+
+	t.Run("invalid body fails the whole request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/users:batchCreate", strings.NewReader(`{"name":"Ada"}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("documents the items and the envelope", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/users:batchCreate").Post
+		require.NotNil(t, operation)
+		require.Equal(t, []string{"users"}, operation.Tags)
+
+		body := operation.RequestBody.Value.Content.Get("application/json").Schema.Value
+		require.True(t, body.Type.Is("array"))
+		require.Equal(t, "#/components/schemas/batchUser", body.Items.Ref)
+
+		response := operation.Responses.Status(http.StatusMultiStatus)
+		require.NotNil(t, response)
+		require.Equal(t, "#/components/schemas/BatchResponse_fuego.MyStruct", response.Value.Content.Get("application/json").Schema.Ref)
+		require.Nil(t, operation.Responses.Status(http.StatusOK))
+	})
+}
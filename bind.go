@@ -0,0 +1,296 @@
+package fuego
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-playground/validator/v10"
+)
+
+// Struct tags read by [Bind] and [OptionParams], by location of the parameter.
+var paramTags = []struct {
+	tag       string
+	paramType ParamType
+}{
+	{"path", PathParamType},
+	{"query", QueryParamType},
+	{"header", HeaderParamType},
+	{"cookie", CookieParamType},
+}
+
+// OptionParams declares the parameters of the request struct R in the OpenAPI spec.
+// The fields are read from the path, query, header and cookie struct tags. The parameter type comes from
+// the field type, the description from the description tag, and a validate:"required" tag makes it required.
+// See [Bind] to decode the request into R.
+//
+//	type GetPetRequest struct {
+//		ID    int    `path:"id"`
+//		Page  int    `query:"page" description:"Page number" validate:"min=1"`
+//		OrgID string `header:"X-Org" validate:"required"`
+//	}
+//
+//	fuego.Get(s, "/pets/{id}", getPet, fuego.OptionParams[GetPetRequest]())
+func OptionParams[R any]() func(*BaseRoute) {
+	typeOfParams := reflect.TypeFor[R]()
+	if typeOfParams.Kind() == reflect.Pointer {
+		typeOfParams = typeOfParams.Elem()
+	}
+	if typeOfParams.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("params type %s must be a struct", typeOfParams))
+	}
+
+	return func(r *BaseRoute) {
+		registerStructParams(r, typeOfParams)
+	}
+}
+
+// registerStructParams declares a parameter for each field of the struct with a parameter tag.
+func registerStructParams(r *BaseRoute, typeOfParams reflect.Type) {
+	for i := range typeOfParams.NumField() {
+		field := typeOfParams.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		for _, paramTag := range paramTags {
+			name, ok := field.Tag.Lookup(paramTag.tag)
+			if !ok {
+				continue
+			}
+
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			isArray := fieldType.Kind() == reflect.Slice
+			if isArray {
+				fieldType = fieldType.Elem()
+			}
+
+			options := []func(*OpenAPIParam){
+				ParamDescription(field.Tag.Get("description")),
+				paramType(paramTag.paramType),
+				func(param *OpenAPIParam) { param.GoType = openAPIType(fieldType) },
+			}
+			if paramTag.paramType == PathParamType || isRequired(field) {
+				options = append(options, ParamRequired())
+			}
+			OptionParam(name, options...)(r)
+
+			if isArray {
+				parameter := r.Operation.Parameters.GetByInAndName(string(paramTag.paramType), name)
+				parameter.Schema = openapi3.NewArraySchema().WithItems(parameter.Schema.Value).NewRef()
+			}
+		}
+	}
+}
+
+// openAPIType returns the OpenAPI type of a parameter of the given Go type.
+func openAPIType(t reflect.Type) string {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// Bind decodes the request into a struct of type R, in one step:
+//   - the fields with a path, query, header or cookie tag are read from the corresponding parameter,
+//   - the field named Body is set to the request body. Its type must be the body type B of the route.
+//
+// Parameters that cannot be converted to their field type are all reported together in a 400 error.
+// Then the struct is validated with its validate tags, the body validation errors being
+// reported along with the parameters ones.
+// Declare the parameters in the OpenAPI spec with [OptionParams].
+//
+//	type UpdatePetRequest struct {
+//		ID    int       `path:"id"`
+//		OrgID string    `header:"X-Org" validate:"required"`
+//		Body  PetUpdate
+//	}
+//
+//	fuego.Put(s, "/pets/{id}", func(c fuego.ContextWithBody[PetUpdate]) (Pet, error) {
+//		req, err := fuego.Bind[UpdatePetRequest](c)
+//		if err != nil {
+//			return Pet{}, err
+//		}
+//		...
+//	}, fuego.OptionParams[UpdatePetRequest]())
+func Bind[R, B any](c ContextWithBody[B]) (R, error) {
+	var request R
+	requestValue := reflect.ValueOf(&request).Elem()
+	if requestValue.Kind() != reflect.Struct {
+		return request, fmt.Errorf("cannot bind the request into %T: it must be a struct", request)
+	}
+
+	var decodingErrors []ErrorItem
+	var summary []string
+	for i := range requestValue.NumField() {
+		field := requestValue.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		for _, paramTag := range paramTags {
+			name, ok := field.Tag.Lookup(paramTag.tag)
+			if !ok {
+				continue
+			}
+			values := paramValues(c, paramTag.paramType, name)
+			if len(values) == 0 {
+				continue
+			}
+			if err := setFieldFromStrings(requestValue.Field(i), values); err != nil {
+				value := strings.Join(values, ",")
+				summary = append(summary, fmt.Sprintf("%s=%s is not of type %s", name, value, field.Type))
+				decodingErrors = append(decodingErrors, ErrorItem{
+					Name:   name,
+					Reason: fmt.Sprintf("invalid value, expected %s", field.Type),
+					More:   map[string]any{"in": string(paramTag.paramType), "field": field.Name, "value": value},
+				})
+			}
+		}
+	}
+
+	if len(decodingErrors) > 0 {
+		return request, BadRequestError{
+			Title:  "Invalid Parameters",
+			Detail: "cannot parse request parameters: " + strings.Join(summary, ", "),
+			Errors: decodingErrors,
+		}
+	}
+
+	var bodyValidationErr error
+	if bodyField := requestValue.FieldByName("Body"); bodyField.IsValid() {
+		if bodyField.Type() != reflect.TypeFor[B]() {
+			return request, fmt.Errorf("cannot bind the request into %T: its Body field is of type %s but the route body is of type %s", request, bodyField.Type(), reflect.TypeFor[B]())
+		}
+		body, err := c.Body()
+		if err != nil && !errors.As(err, &validator.ValidationErrors{}) {
+			return request, err
+		}
+		bodyValidationErr = err
+		bodyField.Set(reflect.ValueOf(&body).Elem())
+	}
+
+	paramsValidationErr := validationError(v.StructExcept(request, "Body"))
+	return request, mergeValidationErrors(paramsValidationErr, bodyValidationErr)
+}
+
+// mergeValidationErrors reports two validation errors, like the ones of the parameters and of the body, as one error.
+// If one of them is not a validation error, it is returned as is.
+func mergeValidationErrors(first, second error) error {
+	if first == nil {
+		return second
+	}
+	var firstValidationErr, secondValidationErr HTTPError
+	if second == nil || !errors.As(first, &firstValidationErr) || !errors.As(second, &secondValidationErr) {
+		return first
+	}
+
+	firstValidationErr.Err = errors.Join(firstValidationErr.Err, secondValidationErr.Err)
+	firstValidationErr.Errors = append(firstValidationErr.Errors, secondValidationErr.Errors...)
+	firstValidationErr.Detail += ", " + secondValidationErr.Detail
+	return firstValidationErr
+}
+
+// paramValues returns the values of the parameter of the request.
+func paramValues[B any](c ContextWithBody[B], paramType ParamType, name string) []string {
+	switch paramType {
+	case PathParamType:
+		if value := c.PathParam(name); value != "" {
+			return []string{value}
+		}
+	case QueryParamType:
+		return c.QueryParams()[name]
+	case HeaderParamType:
+		if value := c.Header(name); value != "" {
+			return []string{value}
+		}
+	case CookieParamType:
+		if cookie, err := c.Cookie(name); err == nil {
+			return []string{cookie.Value}
+		}
+	}
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// setFieldFromStrings converts the values to the type of the field.
+// Slices receive all the values, other types only the first one.
+func setFieldFromStrings(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Pointer {
+		value := reflect.New(field.Type().Elem())
+		if err := setFieldFromStrings(value.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+
+	switch field.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFieldFromStrings(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.String:
+		field.SetString(values[0])
+	case reflect.Bool:
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(values[0], 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(values[0], 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(values[0], field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported parameter type %s", field.Type())
+	}
+	return nil
+}
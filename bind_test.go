@@ -0,0 +1,144 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type petUpdate struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type updatePetRequest struct {
+	ID      int       `path:"id"`
+	Tags    []string  `query:"tag" description:"Filter by tag"`
+	Limit   *int      `query:"limit" validate:"omitempty,max=100"`
+	Since   time.Time `query:"since"`
+	OrgID   string    `header:"X-Org" validate:"required"`
+	Session string    `cookie:"session"`
+	Body    petUpdate
+}
+
+func TestBind(t *testing.T) {
+	s := NewServer()
+	Put(s, "/pets/{id}", func(c ContextWithBody[petUpdate]) (updatePetRequest, error) {
+		return Bind[updatePetRequest](c)
+	}, OptionParams[updatePetRequest]())
+
+	newRequest := func(url, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+
+	t.Run("binds all the request parts", func(t *testing.T) {
+		r := newRequest("/pets/42?tag=cute&tag=small&limit=10&since=2025-01-02T15:04:05Z", `{"name":"Rex"}`)
+		r.Header.Set("X-Org", "acme")
+		r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.JSONEq(t, `{
+			"ID": 42,
+			"Tags": ["cute", "small"],
+			"Limit": 10,
+			"Since": "2025-01-02T15:04:05Z",
+			"OrgID": "acme",
+			"Session": "abc",
+			"Body": {"name": "Rex"}
+		}`, w.Body.String())
+	})
+
+	t.Run("conversion errors are reported together", func(t *testing.T) {
+		r := newRequest("/pets/abc?limit=ten", `{"name":"Rex"}`)
+		r.Header.Set("X-Org", "acme")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "cannot parse request parameters: id=abc is not of type int, limit=ten is not of type *int")
+		require.Contains(t, w.Body.String(), `"in":"path"`)
+		require.Contains(t, w.Body.String(), `"in":"query"`)
+	})
+
+	t.Run("parameters and body are validated together", func(t *testing.T) {
+		r := newRequest("/pets/42?limit=1000", `{}`)
+		r.Header.Set("X-Org", "acme")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		body := w.Body.String()
+		require.Contains(t, body, "Validation Error")
+		require.Contains(t, body, "updatePetRequest.Limit")
+		require.Contains(t, body, "petUpdate.Name")
+	})
+
+	t.Run("body type must match the route body", func(t *testing.T) {
+		_, err := Bind[updatePetRequest](NewMockContextNoBody())
+		require.ErrorContains(t, err, "its Body field is of type fuego.petUpdate but the route body is of type interface {}")
+	})
+
+	t.Run("request must be a struct", func(t *testing.T) {
+		_, err := Bind[string](NewMockContextNoBody())
+		require.Error(t, err)
+	})
+}
+
+func TestOptionParams(t *testing.T) {
+	s := NewServer()
+	route := Put(s, "/pets/{id}", func(c ContextWithBody[petUpdate]) (string, error) {
+		return "", nil
+	}, OptionParams[updatePetRequest]())
+
+	params := route.Operation.Parameters
+	require.Len(t, params, 7, "6 declared parameters and the Accept header")
+
+	id := params.GetByInAndName("path", "id")
+	require.True(t, id.Required)
+	require.True(t, id.Schema.Value.Type.Is("integer"))
+
+	tags := params.GetByInAndName("query", "tag")
+	require.Equal(t, "Filter by tag", tags.Description)
+	require.True(t, tags.Schema.Value.Type.Is("array"))
+	require.True(t, tags.Schema.Value.Items.Value.Type.Is("string"))
+
+	require.True(t, params.GetByInAndName("query", "limit").Schema.Value.Type.Is("integer"))
+	require.True(t, params.GetByInAndName("query", "since").Schema.Value.Type.Is("string"))
+	require.True(t, params.GetByInAndName("header", "X-Org").Required)
+	require.False(t, params.GetByInAndName("cookie", "session").Required)
+
+	require.Panics(t, func() { OptionParams[int]() })
+}
+
+type fuzzParams struct {
+	Limit  int8       `query:"limit"`
+	Offset *uint16    `query:"offset"`
+	Ratio  float32    `query:"ratio"`
+	Active bool       `query:"active"`
+	IDs    []int      `query:"id"`
+	Since  *time.Time `query:"since"`
+	Org    string     `header:"X-Org"`
+}
+
+func FuzzBind(f *testing.F) {
+	f.Add("limit=10&offset=20&ratio=0.5&active=true&id=1&id=2&since=2025-01-02T15:04:05Z", "acme")
+	f.Add("limit=99999999999999999999&offset=-1&ratio=1e999", "")
+	f.Add("id=1&id=x&since=yesterday&active=maybe", "\xff")
+	f.Add("limit=%zz;offset=1", "acme")
+
+	f.Fuzz(func(t *testing.T, query, org string) {
+		r := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/", RawQuery: query}, Header: http.Header{"X-Org": {org}}}
+		c := NewNetHTTPContext[any](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+
+		_, err := Bind[fuzzParams](c)
+		requireDecodingError(t, err)
+	})
+}
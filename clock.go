@@ -0,0 +1,30 @@
+package fuego
+
+import "time"
+
+// WithClock sets the clock read by the built-in features instead of [time.Now]: the issue and expiry
+// of the JWT tokens (see [Security]), the windows of the in-memory rate limiter (see [WithRateLimit]),
+// the evaluation intervals of the load shedding (see [WithLoadShedding]), and the start time and uptime of the server.
+// Tests can freeze or advance the time, instead of sleeping:
+//
+//	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+//	s := fuego.NewServer(fuego.WithClock(func() time.Time { return now }))
+//
+//	token, _ := s.Security.GenerateToken(claims)
+//	now = now.Add(25 * time.Hour) // the token is now expired
+//
+// The durations measured for the logs and the Server-Timing headers keep using the monotonic clock.
+func WithClock(now func() time.Time) func(*Server) {
+	if now == nil {
+		panic("clock must not be nil")
+	}
+	return func(s *Server) {
+		s.clock = now
+		s.Security.Now = now
+	}
+}
+
+// now returns the time of the clock of the server. See [WithClock].
+func (s *Server) now() time.Time {
+	return s.clock()
+}
@@ -0,0 +1,66 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithClock(t *testing.T) {
+	newServer := func(options ...func(*Server)) (*Server, *time.Time) {
+		now := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := NewServer(append([]func(*Server){WithoutLogger(), WithClock(func() time.Time { return now })}, options...)...)
+		return s, &now
+	}
+
+	t.Run("expiry of the tokens", func(t *testing.T) {
+		s, now := newServer()
+		token, err := s.Security.GenerateToken(jwt.MapClaims{
+			"sub": "user",
+			"exp": now.Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+
+		_, err = s.Security.ValidateToken(token)
+		require.NoError(t, err, "issued in 2100")
+
+		*now = now.Add(2 * time.Hour)
+		_, err = s.Security.ValidateToken(token)
+		require.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("windows of the rate limiter", func(t *testing.T) {
+		s, now := newServer(WithRateLimit(RateLimitConfig{Limit: 1, Window: time.Minute}))
+		Get(s, "/", func(c ContextNoBody) (string, error) { return "ok", nil })
+		call := func() *http.Response {
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			return w.Result()
+		}
+
+		require.Equal(t, http.StatusOK, call().StatusCode)
+		*now = now.Add(15 * time.Second)
+		res := call()
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		require.Equal(t, "45", res.Header.Get("Retry-After"))
+
+		*now = now.Add(time.Minute)
+		require.Equal(t, http.StatusOK, call().StatusCode)
+	})
+
+	t.Run("start time and uptime", func(t *testing.T) {
+		s, now := newServer()
+		require.Equal(t, *now, s.startTime)
+
+		*now = now.Add(90 * time.Minute)
+		require.Equal(t, 90*time.Minute, s.now().Sub(s.startTime))
+	})
+
+	t.Run("nil clock", func(t *testing.T) {
+		require.Panics(t, func() { WithClock(nil) })
+	})
+}
@@ -0,0 +1,106 @@
+package fuego
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ConnStats is a snapshot of the connections of the server. See [WithConnMetrics].
+type ConnStats struct {
+	// Connections currently open, by state.
+	New    int64 `json:"new"`
+	Active int64 `json:"active"`
+	Idle   int64 `json:"idle"`
+
+	// Connections accepted, hijacked (like the WebSockets, no longer tracked by the server)
+	// and closed since the server started.
+	Accepted int64 `json:"accepted"`
+	Hijacked int64 `json:"hijacked"`
+	Closed   int64 `json:"closed"`
+}
+
+// Open returns the number of connections currently open and tracked by the server.
+func (c ConnStats) Open() int64 {
+	return c.New + c.Active + c.Idle
+}
+
+// WithConnMetrics tracks the state of the connections of the server, read with [Server.ConnStats].
+// A number of idle connections growing with the traffic reveals a connection leak,
+// like clients that never reuse their keep-alive connections.
+// The hooks are called at each change of state of a connection, like [http.Server.ConnState].
+// To cap the connections per IP address, see [ConnectionLimits.MaxConnectionsPerIP].
+//
+//	s := fuego.NewServer(fuego.WithConnMetrics())
+//	expvar.Publish("connections", expvar.Func(func() any { return s.ConnStats() }))
+func WithConnMetrics(hooks ...func(net.Conn, http.ConnState)) func(*Server) {
+	return func(s *Server) {
+		if s.connTracker == nil {
+			s.connTracker = &connTracker{states: map[net.Conn]http.ConnState{}}
+			hooks = append([]func(net.Conn, http.ConnState){s.connTracker.track}, hooks...)
+		}
+		if previous := s.Server.ConnState; previous != nil {
+			hooks = append([]func(net.Conn, http.ConnState){previous}, hooks...)
+		}
+		s.Server.ConnState = func(conn net.Conn, state http.ConnState) {
+			for _, hook := range hooks {
+				hook(conn, state)
+			}
+		}
+	}
+}
+
+// ConnStats returns the current state of the connections of the server.
+// It is empty without [WithConnMetrics].
+func (s *Server) ConnStats() ConnStats {
+	if s.connTracker == nil {
+		return ConnStats{}
+	}
+	s.connTracker.mu.Lock()
+	defer s.connTracker.mu.Unlock()
+	return s.connTracker.stats
+}
+
+// connTracker counts the connections by state.
+type connTracker struct {
+	mu     sync.Mutex
+	states map[net.Conn]http.ConnState
+	stats  ConnStats
+}
+
+func (t *connTracker) track(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if previous, ok := t.states[conn]; ok {
+		*t.gauge(previous)--
+	}
+	switch state {
+	case http.StateNew:
+		t.stats.Accepted++
+	case http.StateHijacked:
+		t.stats.Hijacked++
+	case http.StateClosed:
+		t.stats.Closed++
+	}
+
+	if gauge := t.gauge(state); gauge != nil {
+		*gauge++
+		t.states[conn] = state
+	} else {
+		delete(t.states, conn)
+	}
+}
+
+// gauge returns the number of open connections in the state, nil for the final states.
+func (t *connTracker) gauge(state http.ConnState) *int64 {
+	switch state {
+	case http.StateNew:
+		return &t.stats.New
+	case http.StateActive:
+		return &t.stats.Active
+	case http.StateIdle:
+		return &t.stats.Idle
+	}
+	return nil
+}
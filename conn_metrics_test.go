@@ -0,0 +1,73 @@
+package fuego
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConnMetrics(t *testing.T) {
+	t.Run("counts the connections by state", func(t *testing.T) {
+		var hooked []http.ConnState
+		s := NewServer(WithConnMetrics(func(_ net.Conn, state http.ConnState) { hooked = append(hooked, state) }))
+		first, second := &net.TCPConn{}, &net.TCPConn{}
+
+		for _, state := range []http.ConnState{http.StateNew, http.StateActive, http.StateIdle, http.StateActive} {
+			s.Server.ConnState(first, state)
+		}
+		s.Server.ConnState(second, http.StateNew)
+		require.Equal(t, ConnStats{New: 1, Active: 1, Accepted: 2}, s.ConnStats())
+		require.EqualValues(t, 2, s.ConnStats().Open())
+
+		s.Server.ConnState(first, http.StateHijacked)
+		s.Server.ConnState(second, http.StateClosed)
+		require.Equal(t, ConnStats{Accepted: 2, Hijacked: 1, Closed: 1}, s.ConnStats())
+
+		require.Equal(t, []http.ConnState{
+			http.StateNew, http.StateActive, http.StateIdle, http.StateActive,
+			http.StateNew, http.StateHijacked, http.StateClosed,
+		}, hooked)
+	})
+
+	t.Run("keeps the previous hooks", func(t *testing.T) {
+		calls := 0
+		hook := func(net.Conn, http.ConnState) { calls++ }
+		s := NewServer(WithConnMetrics(hook), WithConnMetrics(hook))
+
+		s.Server.ConnState(&net.TCPConn{}, http.StateNew)
+		require.Equal(t, 2, calls)
+		require.EqualValues(t, 1, s.ConnStats().Accepted, "tracked once")
+	})
+
+	t.Run("without metrics", func(t *testing.T) {
+		require.Zero(t, NewServer().ConnStats())
+	})
+
+	t.Run("running server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := NewServer(WithoutLogger(), WithoutStartupMessages(), WithListener(listener), WithConnMetrics())
+		shutdown := runServer(t, s)
+		defer shutdown()
+
+		client := &http.Client{Transport: &http.Transport{}}
+		require.Eventually(t, func() bool {
+			res, err := client.Get("http://" + listener.Addr().String() + "/test")
+			return err == nil && res.Body.Close() == nil && res.StatusCode == http.StatusOK
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			stats := s.ConnStats()
+			return stats.Idle == 1 && stats.Accepted == 1
+		}, 5*time.Second, 10*time.Millisecond, "keep-alive connection")
+
+		client.CloseIdleConnections()
+		require.Eventually(t, func() bool {
+			stats := s.ConnStats()
+			return stats.Open() == 0 && stats.Closed == 1
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
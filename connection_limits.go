@@ -0,0 +1,143 @@
+package fuego
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/netutil"
+)
+
+// ConnectionLimits protects the server from slow or greedy clients, like slowloris attacks
+// that keep many connections open by sending their headers byte by byte. See [WithConnectionLimits].
+// Zero fields keep the current value.
+type ConnectionLimits struct {
+	// Maximum size of the request headers, including the request line. Larger headers get a 431 error.
+	// Defaults to 1 MiB, 64 KiB in the [Prod] environment.
+	MaxHeaderBytes int
+	// Time allowed to read the request headers. Defaults to 30s, 5s in the [Prod] environment.
+	ReadHeaderTimeout time.Duration
+	// Time allowed to read the whole request, body included. Defaults to 30s.
+	ReadTimeout time.Duration
+	// Time allowed to write the response. Defaults to 30s.
+	WriteTimeout time.Duration
+	// Time a keep-alive connection is kept open between two requests. Defaults to 30s.
+	IdleTimeout time.Duration
+	// Maximum number of connections open at the same time. Once reached, the new connections wait
+	// until another one is closed. Unlimited by default.
+	MaxConnections int
+	// Maximum number of connections open at the same time from the same IP address.
+	// The connections over the limit are closed right away. Unlimited by default.
+	// Behind a reverse proxy, all the connections come from the proxy: leave it unlimited.
+	MaxConnectionsPerIP int
+}
+
+// Safe defaults of the [Prod] environment.
+var prodConnectionLimits = ConnectionLimits{
+	MaxHeaderBytes:    64 << 10,
+	ReadHeaderTimeout: 5 * time.Second,
+}
+
+// WithConnectionLimits sets the limits of the connections and of the request headers,
+// instead of setting the fields of the embedded [http.Server].
+//
+//	s := fuego.NewServer(
+//		fuego.WithConnectionLimits(fuego.ConnectionLimits{
+//			MaxHeaderBytes:      16 << 10,
+//			ReadHeaderTimeout:   2 * time.Second,
+//			MaxConnections:      10_000,
+//			MaxConnectionsPerIP: 100,
+//		}),
+//	)
+func WithConnectionLimits(limits ConnectionLimits) func(*Server) {
+	if limits.MaxHeaderBytes < 0 || limits.ReadHeaderTimeout < 0 || limits.ReadTimeout < 0 || limits.WriteTimeout < 0 ||
+		limits.IdleTimeout < 0 || limits.MaxConnections < 0 || limits.MaxConnectionsPerIP < 0 {
+		panic("connection limits must not be negative")
+	}
+	return func(s *Server) {
+		setIfNotZero(&s.Server.MaxHeaderBytes, limits.MaxHeaderBytes)
+		setIfNotZero(&s.Server.ReadHeaderTimeout, limits.ReadHeaderTimeout)
+		setIfNotZero(&s.Server.ReadTimeout, limits.ReadTimeout)
+		setIfNotZero(&s.Server.WriteTimeout, limits.WriteTimeout)
+		setIfNotZero(&s.Server.IdleTimeout, limits.IdleTimeout)
+		setIfNotZero(&s.maxConnections, limits.MaxConnections)
+		setIfNotZero(&s.maxConnectionsPerIP, limits.MaxConnectionsPerIP)
+	}
+}
+
+func setIfNotZero[T comparable](field *T, value T) {
+	var zero T
+	if value != zero {
+		*field = value
+	}
+}
+
+// limitListener applies the connection limits of [WithConnectionLimits] to the listener.
+func (s *Server) limitListener(listener net.Listener) net.Listener {
+	if s.maxConnectionsPerIP > 0 {
+		listener = &perIPListener{Listener: listener, max: s.maxConnectionsPerIP, conns: map[string]int{}}
+	}
+	if s.maxConnections > 0 {
+		listener = netutil.LimitListener(listener, s.maxConnections)
+	}
+	return listener
+}
+
+// perIPListener closes the connections of the IP addresses that already have too many open connections.
+type perIPListener struct {
+	net.Listener
+	max int
+
+	mu    sync.Mutex
+	conns map[string]int
+}
+
+func (l *perIPListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		ip := conn.RemoteAddr().String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		if l.acquire(ip) {
+			return &perIPConn{Conn: conn, release: func() { l.release(ip) }}, nil
+		}
+		_ = conn.Close()
+	}
+}
+
+func (l *perIPListener) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conns[ip] >= l.max {
+		return false
+	}
+	l.conns[ip]++
+	return true
+}
+
+func (l *perIPListener) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conns[ip]--
+	if l.conns[ip] == 0 {
+		delete(l.conns, ip)
+	}
+}
+
+// perIPConn releases its slot once closed.
+type perIPConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *perIPConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
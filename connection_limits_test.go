@@ -0,0 +1,86 @@
+package fuego
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConnectionLimits(t *testing.T) {
+	t.Run("sets the limits of the http server", func(t *testing.T) {
+		s := NewServer(WithConnectionLimits(ConnectionLimits{
+			MaxHeaderBytes:    16 << 10,
+			ReadHeaderTimeout: 2 * time.Second,
+			MaxConnections:    100,
+		}))
+		require.Equal(t, 16<<10, s.Server.MaxHeaderBytes)
+		require.Equal(t, 2*time.Second, s.Server.ReadHeaderTimeout)
+		require.Equal(t, 30*time.Second, s.Server.ReadTimeout, "zero fields keep the defaults")
+		require.Equal(t, 100, s.maxConnections)
+	})
+
+	t.Run("safe defaults in production", func(t *testing.T) {
+		s := NewServer(WithEnvironment(Prod))
+		require.Equal(t, 64<<10, s.Server.MaxHeaderBytes)
+		require.Equal(t, 5*time.Second, s.Server.ReadHeaderTimeout)
+
+		s = NewServer(WithEnvironment(Prod), WithConnectionLimits(ConnectionLimits{ReadHeaderTimeout: time.Second}))
+		require.Equal(t, 64<<10, s.Server.MaxHeaderBytes)
+		require.Equal(t, time.Second, s.Server.ReadHeaderTimeout)
+	})
+
+	t.Run("negative limits", func(t *testing.T) {
+		require.Panics(t, func() { WithConnectionLimits(ConnectionLimits{MaxConnectionsPerIP: -1}) })
+	})
+
+	t.Run("limits the running server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := NewServer(WithoutLogger(), WithoutStartupMessages(), WithListener(listener), WithConnectionLimits(ConnectionLimits{
+			MaxHeaderBytes:      1024,
+			MaxConnectionsPerIP: 1,
+		}))
+		shutdown := runServer(t, s)
+		defer shutdown()
+		url := "http://" + listener.Addr().String() + "/test"
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+		require.Eventually(t, func() bool {
+			res, err := client.Get(url)
+			return err == nil && res.Body.Close() == nil && res.StatusCode == http.StatusOK
+		}, 5*time.Second, 10*time.Millisecond)
+
+		t.Run("request headers too large", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Large", strings.Repeat("a", 10_000))
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusRequestHeaderFieldsTooLarge, res.StatusCode)
+		})
+
+		t.Run("too many connections from the same IP", func(t *testing.T) {
+			first, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+
+			second, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(t, err)
+			defer second.Close()
+			require.NoError(t, second.SetReadDeadline(time.Now().Add(5*time.Second)))
+			_, err = second.Read(make([]byte, 1))
+			require.ErrorIs(t, err, io.EOF, "closed by the server")
+
+			require.NoError(t, first.Close())
+			require.Eventually(t, func() bool {
+				res, err := client.Get(url)
+				return err == nil && res.Body.Close() == nil && res.StatusCode == http.StatusOK
+			}, 5*time.Second, 10*time.Millisecond, "the slot is released")
+		})
+	})
+}
@@ -0,0 +1,339 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// ContractConfig configures the contract verification of the server. See [Server.VerifyContract].
+type ContractConfig struct {
+	// Client sending the requests, like the client given to the SDK generated from the spec,
+	// so that its transport and its credentials are verified too. Defaults to a client without credentials.
+	Client *http.Client
+	// Prepares the requests before they are sent, for example to set their credentials.
+	Prepare func(*http.Request)
+	// Operations that are not verified, like "GET /events" for a stream, as written in the spec.
+	Skip []string
+	// Time allowed to each operation. Defaults to 10s.
+	Timeout time.Duration
+}
+
+// ContractError is an operation whose response does not match the OpenAPI spec of the server.
+type ContractError struct {
+	// Operation, like "GET /pets/{id}", as written in the spec.
+	Operation  string
+	StatusCode int
+	Err        error
+}
+
+func (e ContractError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %v", e.Operation, e.Err)
+	}
+	return fmt.Sprintf("%s: %d response does not match the spec: %v", e.Operation, e.StatusCode, e.Err)
+}
+
+func (e ContractError) Unwrap() error { return e.Err }
+
+// VerifyContract serves the server on a local port and calls each operation of its OpenAPI spec
+// with the examples of its parameters and request body, or values generated from their schemas.
+// It returns the [ContractError] of each response that does not match the spec: undocumented status code,
+// unexpected content type, or body not matching the documented schema.
+// It is meant to be called from the tests, so that the spec and the generated clients stay trustworthy:
+//
+//	func TestContract(t *testing.T) {
+//		s := newServer()
+//		require.NoError(t, s.VerifyContract(fuego.ContractConfig{
+//			Prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+testToken) },
+//		}))
+//	}
+//
+// The operations are called in the order of their paths: the requests can change the state of the server.
+func (s *Server) VerifyContract(config ContractConfig) error {
+	if config.Client == nil {
+		config.Client = &http.Client{}
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: s.handler(), ReadHeaderTimeout: config.Timeout}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+	baseURL := "http://" + listener.Addr().String()
+
+	spec := s.OpenAPI.Description()
+	var errs []error
+	for _, path := range spec.Paths.InMatchingOrder() {
+		pathItem := spec.Paths.Value(path)
+		for _, method := range slices.Sorted(maps.Keys(pathItem.Operations())) {
+			operation := method + " " + path
+			if slices.Contains(config.Skip, operation) {
+				continue
+			}
+			route := &routers.Route{Spec: spec, Path: path, PathItem: pathItem, Method: method, Operation: pathItem.GetOperation(method)}
+			if err := verifyOperation(config, baseURL, route); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verifyOperation calls the operation and validates its response against the spec.
+func verifyOperation(config ContractConfig, baseURL string, route *routers.Route) error {
+	operation := route.Method + " " + route.Path
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	req, pathParams, err := exampleRequest(ctx, baseURL, route)
+	if err != nil {
+		return ContractError{Operation: operation, Err: err}
+	}
+	if config.Prepare != nil {
+		config.Prepare(req)
+	}
+	res, err := config.Client.Do(req)
+	if err != nil {
+		return ContractError{Operation: operation, Err: err}
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return ContractError{Operation: operation, StatusCode: res.StatusCode, Err: err}
+	}
+
+	if !documentedStatus(route.Operation, res.StatusCode) {
+		return ContractError{Operation: operation, StatusCode: res.StatusCode, Err: errUndocumentedStatus}
+	}
+	err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req, PathParams: pathParams, Route: route},
+		Status:                 res.StatusCode,
+		Header:                 res.Header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	if err != nil {
+		return ContractError{Operation: operation, StatusCode: res.StatusCode, Err: err}
+	}
+	return nil
+}
+
+var errUndocumentedStatus = errors.New("status code not documented")
+
+// documentedStatus reports whether the status code is documented by the operation.
+// The default response, added without content to all the operations, does not document any status code.
+func documentedStatus(operation *openapi3.Operation, status int) bool {
+	if operation.Responses.Status(status) != nil {
+		return true
+	}
+	response := operation.Responses.Default()
+	return response != nil && response.Value != nil && len(response.Value.Content) > 0
+}
+
+// exampleRequest returns a request of the operation, built from the examples of the spec.
+func exampleRequest(ctx context.Context, baseURL string, route *routers.Route) (*http.Request, map[string]string, error) {
+	operation := route.Operation
+	parameters := append(slices.Clone(route.PathItem.Parameters), operation.Parameters...)
+
+	path := route.Path
+	pathParams := map[string]string{}
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []*http.Cookie
+	for _, ref := range parameters {
+		param := ref.Value
+		if param == nil || (!param.Required && param.Example == nil && len(param.Examples) == 0) {
+			continue
+		}
+		values := exampleStrings(parameterExample(param))
+		if len(values) == 0 {
+			continue
+		}
+		switch param.In {
+		case openapi3.ParameterInPath:
+			pathParams[param.Name] = values[0]
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(values[0]))
+		case openapi3.ParameterInQuery:
+			query[param.Name] = values
+		case openapi3.ParameterInHeader:
+			header.Set(param.Name, values[0])
+		case openapi3.ParameterInCookie:
+			cookies = append(cookies, &http.Cookie{Name: param.Name, Value: values[0]})
+		}
+	}
+
+	var body io.Reader
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		if mediaType := operation.RequestBody.Value.Content.Get("application/json"); mediaType != nil {
+			data, err := json.Marshal(mediaTypeExample(mediaType))
+			if err != nil {
+				return nil, nil, err
+			}
+			body = bytes.NewReader(data)
+			header.Set("Content-Type", "application/json")
+		}
+	}
+
+	target := baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, route.Method, target, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	maps.Copy(req.Header, header)
+	req.Header.Set("Accept", "application/json")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	return req, pathParams, nil
+}
+
+func parameterExample(param *openapi3.Parameter) any {
+	if param.Example != nil {
+		return param.Example
+	}
+	for _, name := range slices.Sorted(maps.Keys(param.Examples)) {
+		if example := param.Examples[name]; example != nil && example.Value != nil {
+			return example.Value.Value
+		}
+	}
+	return schemaExample(param.Schema, 0)
+}
+
+func mediaTypeExample(mediaType *openapi3.MediaType) any {
+	if mediaType.Example != nil {
+		return mediaType.Example
+	}
+	for _, name := range slices.Sorted(maps.Keys(mediaType.Examples)) {
+		if example := mediaType.Examples[name]; example != nil && example.Value != nil {
+			return example.Value.Value
+		}
+	}
+	return schemaExample(mediaType.Schema, 0)
+}
+
+// exampleStrings formats an example as parameter values.
+func exampleStrings(example any) []string {
+	switch example := example.(type) {
+	case nil:
+		return nil
+	case []any:
+		values := make([]string, 0, len(example))
+		for _, item := range example {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case string:
+		return []string{example}
+	}
+	return []string{fmt.Sprint(example)}
+}
+
+// schemaExample returns a value matching the schema, from its example, its default or its first enum value,
+// or generated from its type, format and bounds.
+func schemaExample(ref *openapi3.SchemaRef, depth int) any {
+	if ref == nil || ref.Value == nil || depth > 10 {
+		return nil
+	}
+	schema := ref.Value
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.OneOf) > 0:
+		return schemaExample(schema.OneOf[0], depth+1)
+	case len(schema.AnyOf) > 0:
+		return schemaExample(schema.AnyOf[0], depth+1)
+	case len(schema.AllOf) > 0:
+		example := map[string]any{}
+		for _, ref := range schema.AllOf {
+			if part, ok := schemaExample(ref, depth+1).(map[string]any); ok {
+				maps.Copy(example, part)
+			}
+		}
+		return example
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeObject) || len(schema.Properties) > 0:
+		example := map[string]any{}
+		for name, property := range schema.Properties {
+			if property.Value != nil && property.Value.ReadOnly {
+				continue
+			}
+			if value := schemaExample(property, depth+1); value != nil {
+				example[name] = value
+			}
+		}
+		return example
+	case schema.Type.Is(openapi3.TypeArray):
+		item := schemaExample(schema.Items, depth+1)
+		if item == nil {
+			return []any{}
+		}
+		return []any{item}
+	case schema.Type.Is(openapi3.TypeString):
+		return stringExample(schema)
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		switch {
+		case schema.Min != nil && schema.ExclusiveMin:
+			return *schema.Min + 1
+		case schema.Min != nil:
+			return *schema.Min
+		case schema.Max != nil && *schema.Max < 1:
+			return *schema.Max
+		}
+		return 1
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return true
+	}
+	return nil
+}
+
+func stringExample(schema *openapi3.Schema) string {
+	switch schema.Format {
+	case "date-time":
+		return "2006-01-02T15:04:05Z"
+	case "date":
+		return "2006-01-02"
+	case "uuid":
+		return "123e4567-e89b-12d3-a456-426614174000"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	}
+	example := "example"
+	if minLength := int(schema.MinLength); len(example) < minLength {
+		example += strings.Repeat("x", minLength-len(example))
+	}
+	if schema.MaxLength != nil && uint64(len(example)) > *schema.MaxLength {
+		example = example[:*schema.MaxLength]
+	}
+	return example
+}
@@ -0,0 +1,98 @@
+package fuego
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type contractPet struct {
+	ID   string `json:"id" readOnly:"true"`
+	Name string `json:"name" validate:"required,min=2" example:"Rex"`
+	Age  int    `json:"age" validate:"min=0,max=30"`
+}
+
+func TestVerifyContract(t *testing.T) {
+	newServer := func() *Server {
+		s := NewServer(WithoutLogger())
+		Get(s, "/pets", func(c ContextNoBody) ([]contractPet, error) {
+			return []contractPet{{ID: "1", Name: "Rex"}}, nil
+		}, OptionQueryInt("page", "Page", ParamRequired(), ParamExample("first", 1)))
+		Get(s, "/pets/{id}", func(c ContextNoBody) (contractPet, error) {
+			if c.PathParam("id") != "42" {
+				return contractPet{}, NotFoundError{Title: "Pet not found"}
+			}
+			return contractPet{ID: "42", Name: "Rex"}, nil
+		}, OptionPath("id", "Pet ID", ParamExample("existing", "42")), OptionAddError(http.StatusNotFound, "Pet not found"))
+		Post(s, "/pets", func(c ContextWithBody[contractPet]) (contractPet, error) {
+			pet, err := c.Body()
+			pet.ID = "43"
+			return pet, err
+		})
+		return s
+	}
+
+	t.Run("matching responses", func(t *testing.T) {
+		require.NoError(t, newServer().VerifyContract(ContractConfig{}))
+	})
+
+	t.Run("undocumented status code", func(t *testing.T) {
+		s := newServer()
+		Delete(s, "/pets/{id}", func(c ContextNoBody) (any, error) {
+			return nil, HTTPError{Status: http.StatusConflict}
+		})
+
+		err := s.VerifyContract(ContractConfig{})
+		var contractErr ContractError
+		require.ErrorAs(t, err, &contractErr)
+		require.Equal(t, "DELETE /pets/{id}", contractErr.Operation)
+		require.Equal(t, http.StatusConflict, contractErr.StatusCode)
+		require.ErrorIs(t, err, errUndocumentedStatus)
+		require.ErrorContains(t, err, "DELETE /pets/{id}: 409 response does not match the spec: status code not documented")
+	})
+
+	t.Run("body not matching the schema", func(t *testing.T) {
+		s := newServer()
+		GetStd(s, "/owners", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"1","name":12}`))
+		}, OptionAddResponse(http.StatusOK, "Owner", Response{Type: contractPet{}}))
+
+		err := s.VerifyContract(ContractConfig{})
+		require.ErrorContains(t, err, "GET /owners: 200 response does not match the spec")
+		require.ErrorContains(t, err, `"/name": value must be a string`)
+	})
+
+	t.Run("prepares the requests and skips operations", func(t *testing.T) {
+		s := newServer()
+		Get(s, "/admin", func(c ContextNoBody) (string, error) {
+			if c.Header("Authorization") != "Bearer token" {
+				return "", HTTPError{Status: http.StatusTeapot}
+			}
+			return "ok", nil
+		})
+		Get(s, "/broken", func(c ContextNoBody) (string, error) {
+			return "", HTTPError{Status: http.StatusTeapot}
+		})
+
+		require.NoError(t, s.VerifyContract(ContractConfig{
+			Prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") },
+			Skip:    []string{"GET /broken"},
+		}))
+	})
+
+	t.Run("client errors", func(t *testing.T) {
+		client := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})}
+		err := newServer().VerifyContract(ContractConfig{Client: client})
+		require.ErrorContains(t, err, "GET /pets: ")
+		require.ErrorContains(t, err, "connection refused")
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
@@ -0,0 +1,285 @@
+package fuego
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the built-in CORS middleware.
+// See [WithCORS].
+type CORSOptions struct {
+	// Origins allowed to make cross-origin requests.
+	// "*" allows any origin. A single wildcard can be used in the origin,
+	// for example "https://*.example.com".
+	// Defaults to "*".
+	AllowedOrigins []string
+	// Custom function to validate the origin. Takes precedence over AllowedOrigins.
+	AllowOriginFunc func(origin string) bool
+	// Methods allowed for cross-origin requests.
+	// Defaults to GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowedMethods []string
+	// Request headers allowed for cross-origin requests. "*" allows any header.
+	// If empty, the headers requested by the preflight are reflected.
+	AllowedHeaders []string
+	// Response headers exposed to the browser.
+	ExposedHeaders []string
+	// How long the results of a preflight request can be cached by the browser.
+	// Zero means the header is not sent, a negative value disables caching.
+	MaxAge time.Duration
+	// Status code sent to successful preflight requests. Defaults to 204.
+	OptionsSuccessStatus int
+	// Allows cookies and HTTP authentication to be sent with cross-origin requests.
+	// Needs an explicit list of AllowedOrigins or an AllowOriginFunc:
+	// allowing the credentials of every origin lets any website act as the user.
+	AllowCredentials bool
+	// Answers to Private Network Access preflights
+	// (requests from public websites to private networks).
+	// See https://wicg.github.io/private-network-access/
+	AllowPrivateNetwork bool
+}
+
+var defaultCORSMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// WithCORS adds a CORS middleware to the server, without any external dependency.
+// It is registered as a global middleware (see [WithGlobalMiddlewares]),
+// so it also answers to preflight requests on routes that are not registered,
+// like `OPTIONS /foo` when only `GET /foo` is declared.
+// For example:
+//
+//	s := fuego.NewServer(
+//		fuego.WithCORS(fuego.CORSOptions{
+//			AllowedOrigins:   []string{"https://*.example.com"},
+//			AllowCredentials: true,
+//			MaxAge:           10 * time.Minute,
+//		}),
+//	)
+func WithCORS(options CORSOptions) func(*Server) {
+	return WithGlobalMiddlewares(NewCORS(options))
+}
+
+// NewCORS returns a CORS middleware.
+// Prefer [WithCORS], that registers it correctly as a global middleware.
+// Panics if the credentials are allowed for all the origins.
+func NewCORS(options CORSOptions) func(http.Handler) http.Handler {
+	c := newCORS(options)
+	return c.middleware
+}
+
+type cors struct {
+	allowOrigin       func(origin string) bool
+	allowedMethods    []string
+	allowedHeaders    []string
+	maxAge            string
+	exposedHeaders    string
+	successStatus     int
+	allowAllOrigins   bool
+	allowAllHeaders   bool
+	credentials       bool
+	privateNetwork    bool
+	reflectReqHeaders bool
+}
+
+func newCORS(options CORSOptions) cors {
+	c := cors{
+		allowedMethods: defaultCORSMethods,
+		successStatus:  options.OptionsSuccessStatus,
+		exposedHeaders: strings.Join(options.ExposedHeaders, ", "),
+		credentials:    options.AllowCredentials,
+		privateNetwork: options.AllowPrivateNetwork,
+	}
+
+	if len(options.AllowedMethods) > 0 {
+		c.allowedMethods = make([]string, 0, len(options.AllowedMethods))
+		for _, method := range options.AllowedMethods {
+			c.allowedMethods = append(c.allowedMethods, strings.ToUpper(method))
+		}
+	}
+
+	if c.successStatus == 0 {
+		c.successStatus = http.StatusNoContent
+	}
+
+	switch {
+	case options.MaxAge > 0:
+		c.maxAge = strconv.Itoa(int(options.MaxAge.Seconds()))
+	case options.MaxAge < 0:
+		c.maxAge = "0"
+	}
+
+	switch {
+	case len(options.AllowedHeaders) == 0:
+		c.reflectReqHeaders = true
+	case slices.Contains(options.AllowedHeaders, "*"):
+		c.allowAllHeaders = true
+	default:
+		for _, header := range options.AllowedHeaders {
+			c.allowedHeaders = append(c.allowedHeaders, http.CanonicalHeaderKey(header))
+		}
+	}
+
+	switch {
+	case options.AllowOriginFunc != nil:
+		c.allowOrigin = options.AllowOriginFunc
+	case len(options.AllowedOrigins) == 0 || slices.Contains(options.AllowedOrigins, "*"):
+		if options.AllowCredentials {
+			panic("CORS credentials need an explicit list of allowed origins or an AllowOriginFunc")
+		}
+		c.allowAllOrigins = true
+		c.allowOrigin = func(string) bool { return true }
+	default:
+		c.allowOrigin = originMatcher(options.AllowedOrigins)
+	}
+
+	return c
+}
+
+// originMatcher matches an origin against a list of allowed origins,
+// each of them containing at most one wildcard.
+func originMatcher(allowedOrigins []string) func(origin string) bool {
+	type wildcard struct{ prefix, suffix string }
+
+	exact := make([]string, 0, len(allowedOrigins))
+	wildcards := []wildcard{}
+	for _, origin := range allowedOrigins {
+		origin = strings.ToLower(origin)
+		if prefix, suffix, found := strings.Cut(origin, "*"); found {
+			wildcards = append(wildcards, wildcard{prefix: prefix, suffix: suffix})
+			continue
+		}
+		exact = append(exact, origin)
+	}
+
+	return func(origin string) bool {
+		origin = strings.ToLower(origin)
+		if slices.Contains(exact, origin) {
+			return true
+		}
+		for _, w := range wildcards {
+			if len(origin) >= len(w.prefix)+len(w.suffix) && strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (c cors) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			c.handlePreflight(w, r)
+			return
+		}
+
+		c.handleActualRequest(w, r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c cors) handlePreflight(w http.ResponseWriter, r *http.Request) {
+	headers := w.Header()
+	origin := r.Header.Get("Origin")
+
+	headers.Add("Vary", "Origin")
+	headers.Add("Vary", "Access-Control-Request-Method")
+	headers.Add("Vary", "Access-Control-Request-Headers")
+	if c.privateNetwork {
+		headers.Add("Vary", "Access-Control-Request-Private-Network")
+	}
+
+	if origin == "" || !c.allowOrigin(origin) {
+		w.WriteHeader(c.successStatus)
+		return
+	}
+
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if method != http.MethodOptions && !slices.Contains(c.allowedMethods, method) {
+		w.WriteHeader(c.successStatus)
+		return
+	}
+
+	requestedHeaders := parseHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+	if !c.areHeadersAllowed(requestedHeaders) {
+		w.WriteHeader(c.successStatus)
+		return
+	}
+
+	c.setAllowOrigin(headers, origin)
+	headers.Set("Access-Control-Allow-Methods", method)
+	if len(requestedHeaders) > 0 {
+		headers.Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+	}
+	if c.credentials {
+		headers.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if c.privateNetwork && r.Header.Get("Access-Control-Request-Private-Network") == "true" {
+		headers.Set("Access-Control-Allow-Private-Network", "true")
+	}
+	if c.maxAge != "" {
+		headers.Set("Access-Control-Max-Age", c.maxAge)
+	}
+
+	w.WriteHeader(c.successStatus)
+}
+
+func (c cors) handleActualRequest(w http.ResponseWriter, r *http.Request) {
+	headers := w.Header()
+	origin := r.Header.Get("Origin")
+
+	if !c.allowAllOrigins {
+		headers.Add("Vary", "Origin")
+	}
+
+	if origin == "" || !c.allowOrigin(origin) {
+		return
+	}
+
+	c.setAllowOrigin(headers, origin)
+	if c.exposedHeaders != "" {
+		headers.Set("Access-Control-Expose-Headers", c.exposedHeaders)
+	}
+	if c.credentials {
+		headers.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// setAllowOrigin sets the Access-Control-Allow-Origin header.
+func (c cors) setAllowOrigin(headers http.Header, origin string) {
+	if c.allowAllOrigins {
+		headers.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	headers.Set("Access-Control-Allow-Origin", origin)
+}
+
+func (c cors) areHeadersAllowed(requestedHeaders []string) bool {
+	if c.allowAllHeaders || c.reflectReqHeaders {
+		return true
+	}
+	for _, header := range requestedHeaders {
+		if !slices.Contains(c.allowedHeaders, header) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseHeaderList(headerList string) []string {
+	if headerList == "" {
+		return nil
+	}
+	headers := strings.Split(headerList, ",")
+	for i, header := range headers {
+		headers[i] = http.CanonicalHeaderKey(strings.TrimSpace(header))
+	}
+	return headers
+}
@@ -0,0 +1,188 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newCORSTestServer(options CORSOptions) http.Handler {
+	s := NewServer(
+		WithoutLogger(),
+		WithCORS(options),
+	)
+	Get(s, "/", func(c ContextNoBody) (string, error) {
+		return "Hello, World!", nil
+	})
+
+	handler := http.Handler(s.Mux)
+	for _, middleware := range s.globalMiddlewares {
+		handler = middleware(handler)
+	}
+	return handler
+}
+
+func preflightRequest(origin, method string) *http.Request {
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", method)
+	return r
+}
+
+func TestWithCORS(t *testing.T) {
+	t.Run("allows any origin by default", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Origin", "https://example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "Hello, World!", w.Body.String())
+		require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("request without origin is not a CORS request", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{AllowedOrigins: []string{"https://example.com"}})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("preflight on unregistered OPTIONS route", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{
+			AllowedOrigins: []string{"https://example.com"},
+			MaxAge:         10 * time.Minute,
+		})
+
+		r := preflightRequest("https://example.com", http.MethodPost)
+		r.Header.Set("Access-Control-Request-Headers", "content-type, x-custom")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "POST", w.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Content-Type, X-Custom", w.Header().Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+		require.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("preflight with disallowed origin", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{AllowedOrigins: []string{"https://example.com"}})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, preflightRequest("https://evil.com", http.MethodGet))
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("preflight with disallowed method", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{AllowedMethods: []string{"get"}})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, preflightRequest("https://example.com", http.MethodDelete))
+
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("preflight with disallowed header", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{AllowedHeaders: []string{"Content-Type"}})
+
+		r := preflightRequest("https://example.com", http.MethodPost)
+		r.Header.Set("Access-Control-Request-Headers", "X-Custom")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("wildcard origin", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{AllowedOrigins: []string{"https://*.example.com"}})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Origin", "https://api.example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, "https://api.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+		r = httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Origin", "https://example.org")
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("credentials reflect the allowed origin", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{
+			AllowedOrigins:   []string{"https://*.com"},
+			AllowCredentials: true,
+			ExposedHeaders:   []string{"X-Request-ID", "Content-Range"},
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Origin", "https://example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, "X-Request-ID, Content-Range", w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("credentials need an explicit list of origins", func(t *testing.T) {
+		require.Panics(t, func() { NewCORS(CORSOptions{AllowCredentials: true}) })
+		require.Panics(t, func() { NewCORS(CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}) })
+		require.NotPanics(t, func() {
+			NewCORS(CORSOptions{AllowOriginFunc: func(string) bool { return true }, AllowCredentials: true})
+		})
+	})
+
+	t.Run("private network access", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{AllowPrivateNetwork: true})
+
+		r := preflightRequest("https://example.com", http.MethodGet)
+		r.Header.Set("Access-Control-Request-Private-Network", "true")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Private-Network"))
+	})
+
+	t.Run("private network access not allowed", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{})
+
+		r := preflightRequest("https://example.com", http.MethodGet)
+		r.Header.Set("Access-Control-Request-Private-Network", "true")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Private-Network"))
+	})
+
+	t.Run("custom origin func and success status", func(t *testing.T) {
+		handler := newCORSTestServer(CORSOptions{
+			AllowOriginFunc:      func(origin string) bool { return origin == "https://custom.com" },
+			OptionsSuccessStatus: http.StatusOK,
+			MaxAge:               -1,
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, preflightRequest("https://custom.com", http.MethodGet))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "https://custom.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "0", w.Header().Get("Access-Control-Max-Age"))
+	})
+}
@@ -0,0 +1,357 @@
+package fuego
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUserNotFound is returned by the [UserStore] for unknown users.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUnsupportedPasswordHash is returned when verifying a password against a hash of an unknown format.
+	ErrUnsupportedPasswordHash = errors.New("unsupported password hash")
+)
+
+// PasswordHasher hashes the passwords stored by a [UserStore].
+type PasswordHasher interface {
+	// Hash returns the hash of the password, with its salt and parameters.
+	Hash(password string) (string, error)
+}
+
+// Argon2idHasher hashes the passwords with Argon2id, in the PHC string format:
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>.
+// The zero value uses the parameters recommended by OWASP: 19 MiB of memory, 2 iterations, 1 thread.
+type Argon2idHasher struct {
+	// Memory in KiB.
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+var _ PasswordHasher = Argon2idHasher{}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	if h.Memory == 0 {
+		h.Memory = 19 * 1024
+	}
+	if h.Time == 0 {
+		h.Time = 2
+	}
+	if h.Threads == 0 {
+		h.Threads = 1
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, 32)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// BcryptHasher hashes the passwords with bcrypt. The zero value uses [bcrypt.DefaultCost].
+// The passwords longer than 72 bytes are rejected by bcrypt: prefer [Argon2idHasher] for new applications.
+type BcryptHasher struct {
+	Cost int
+}
+
+var _ PasswordHasher = BcryptHasher{}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	cost := h.Cost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	return string(hash), err
+}
+
+// VerifyPassword reports whether the password matches the hash, created by [Argon2idHasher] or [BcryptHasher],
+// so that the hashes of both formats can be verified while migrating from one to the other.
+// The comparison takes a constant time.
+func VerifyPassword(password, hash string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return verifyArgon2id(password, hash)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	return false, ErrUnsupportedPasswordHash
+}
+
+func verifyArgon2id(password, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, ErrUnsupportedPasswordHash
+	}
+	var version int
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrUnsupportedPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, ErrUnsupportedPasswordHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrUnsupportedPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, ErrUnsupportedPasswordHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, computed) == 1, nil
+}
+
+// User is a user of a [UserStore].
+type User struct {
+	ID       string
+	Username string
+	// Hash of the password, created by a [PasswordHasher].
+	PasswordHash string
+	Roles        []string
+}
+
+// UserStore finds the users logging in. See [Credentials].
+type UserStore interface {
+	// UserByName returns the user with the given username, or [ErrUserNotFound].
+	UserByName(ctx context.Context, username string) (User, error)
+}
+
+// SQLUserStore is a [UserStore] reading the users from a SQL database.
+type SQLUserStore struct {
+	DB *sql.DB
+	// Query selecting the ID and the password hash of the user, given its username as single argument.
+	// The roles can be selected as a third column, separated by commas.
+	// Defaults to "SELECT id, password_hash FROM users WHERE username = ?": use "$1" instead of "?" with PostgreSQL.
+	Query string
+}
+
+var _ UserStore = SQLUserStore{}
+
+func (store SQLUserStore) UserByName(ctx context.Context, username string) (User, error) {
+	query := store.Query
+	if query == "" {
+		query = "SELECT id, password_hash FROM users WHERE username = ?"
+	}
+	rows, err := store.DB.QueryContext(ctx, query, username)
+	if err != nil {
+		return User{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return User{}, err
+		}
+		return User{}, ErrUserNotFound
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return User{}, err
+	}
+
+	user := User{Username: username}
+	var roles sql.NullString
+	dest := []any{&user.ID, &user.PasswordHash}
+	if len(columns) > 2 {
+		dest = append(dest, &roles)
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return User{}, err
+	}
+	if roles.String != "" {
+		user.Roles = strings.Split(roles.String, ",")
+	}
+	return user, rows.Err()
+}
+
+// LoginThrottle locks the accounts out after too many failed logins, to slow down the password guessing.
+type LoginThrottle struct {
+	// Failed logins allowed in the window before the account is locked out. Defaults to 5.
+	MaxAttempts int
+	// Window of the failed logins. Defaults to 15 minutes.
+	Window time.Duration
+	// Duration of the lockout. Defaults to 15 minutes.
+	Lockout time.Duration
+}
+
+// CredentialsConfig configures the verification of the credentials. See [NewCredentials].
+type CredentialsConfig struct {
+	Users UserStore
+	// Claims of the token of the logged in user. Defaults to the "sub" (ID of the user), "username" and "roles" claims.
+	Claims func(User) jwt.Claims
+	// Locks the accounts out after too many failed logins. Disabled if nil.
+	Throttle *LoginThrottle
+	// Clock of the throttling. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Credentials verifies the usernames and passwords of a [UserStore], for [WithAutoAuth]:
+//
+//	credentials := fuego.NewCredentials(fuego.CredentialsConfig{
+//		Users:    fuego.SQLUserStore{DB: db, Query: "SELECT id, password_hash, roles FROM users WHERE email = $1"},
+//		Throttle: &fuego.LoginThrottle{MaxAttempts: 5},
+//	})
+//	s := fuego.NewServer(fuego.WithAutoAuth(credentials.Verify))
+//
+// The passwords are stored hashed with a [PasswordHasher], like [Argon2idHasher].
+type Credentials struct {
+	config CredentialsConfig
+	// Hash verified for the unknown users, so that they take as long as the known ones.
+	dummyHash string
+
+	mu        sync.Mutex
+	failures  map[string]*loginFailures
+	nextSweep time.Time
+}
+
+type loginFailures struct {
+	count       int
+	since       time.Time
+	lockedUntil time.Time
+}
+
+// NewCredentials creates a [Credentials] verifying the users of the store.
+func NewCredentials(config CredentialsConfig) *Credentials {
+	if config.Users == nil {
+		panic("credentials need a user store")
+	}
+	if config.Claims == nil {
+		config.Claims = func(user User) jwt.Claims {
+			return jwt.MapClaims{"sub": user.ID, "username": user.Username, "roles": user.Roles}
+		}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	if config.Throttle != nil {
+		throttle := *config.Throttle
+		config.Throttle = &throttle
+		if throttle.MaxAttempts <= 0 {
+			throttle.MaxAttempts = 5
+		}
+		if throttle.Window <= 0 {
+			throttle.Window = 15 * time.Minute
+		}
+		if throttle.Lockout <= 0 {
+			throttle.Lockout = 15 * time.Minute
+		}
+	}
+	dummyHash, err := Argon2idHasher{}.Hash("")
+	if err != nil {
+		panic(err)
+	}
+	return &Credentials{
+		config:    config,
+		dummyHash: dummyHash,
+		failures:  map[string]*loginFailures{},
+	}
+}
+
+// Verify returns the claims of the user if the password is correct. It can be given to [WithAutoAuth].
+// Unknown users and wrong passwords get the same [UnauthorizedError], so that the usernames cannot be guessed.
+// Locked out accounts get a 429 Too Many Requests error.
+func (c *Credentials) Verify(username, password string) (jwt.Claims, error) {
+	if until, locked := c.lockedOut(username); locked {
+		return nil, HTTPError{
+			Title:  "Too many failed logins",
+			Detail: "Try again after " + until.UTC().Format(time.RFC3339),
+			Status: http.StatusTooManyRequests,
+		}
+	}
+
+	user, err := c.config.Users.UserByName(context.Background(), username)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+	hash := user.PasswordHash
+	if hash == "" {
+		hash = c.dummyHash
+	}
+
+	ok, err := VerifyPassword(password, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || user.PasswordHash == "" {
+		c.fail(username)
+		return nil, UnauthorizedError{Title: "Invalid username or password"}
+	}
+
+	c.succeed(username)
+	return c.config.Claims(user), nil
+}
+
+// lockedOut reports whether the account is locked out, and until when.
+func (c *Credentials) lockedOut(username string) (time.Time, bool) {
+	if c.config.Throttle == nil {
+		return time.Time{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failures, ok := c.failures[username]
+	if !ok {
+		return time.Time{}, false
+	}
+	return failures.lockedUntil, c.config.Now().Before(failures.lockedUntil)
+}
+
+func (c *Credentials) fail(username string) {
+	throttle := c.config.Throttle
+	if throttle == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.config.Now()
+	if now.After(c.nextSweep) {
+		// Forget the failures of the windows and lockouts that are over
+		for name, failures := range c.failures {
+			if now.Sub(failures.since) > throttle.Window && now.After(failures.lockedUntil) {
+				delete(c.failures, name)
+			}
+		}
+		c.nextSweep = now.Add(throttle.Window)
+	}
+	failures, ok := c.failures[username]
+	if !ok || now.Sub(failures.since) > throttle.Window {
+		failures = &loginFailures{since: now}
+		c.failures[username] = failures
+	}
+	failures.count++
+	if failures.count >= throttle.MaxAttempts {
+		failures.lockedUntil = now.Add(throttle.Lockout)
+		failures.count = 0
+		failures.since = failures.lockedUntil
+	}
+}
+
+func (c *Credentials) succeed(username string) {
+	if c.config.Throttle == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.failures, username)
+}
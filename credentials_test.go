@@ -0,0 +1,216 @@
+package fuego
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordHashing(t *testing.T) {
+	for name, hasher := range map[string]PasswordHasher{
+		"argon2id": Argon2idHasher{Memory: 1024, Time: 1},
+		"bcrypt":   BcryptHasher{Cost: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hash, err := hasher.Hash("correct horse")
+			require.NoError(t, err)
+			require.NotContains(t, hash, "correct horse")
+
+			ok, err := VerifyPassword("correct horse", hash)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = VerifyPassword("battery staple", hash)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			other, err := hasher.Hash("correct horse")
+			require.NoError(t, err)
+			require.NotEqual(t, hash, other, "salted")
+		})
+	}
+
+	t.Run("argon2id format", func(t *testing.T) {
+		hash, err := Argon2idHasher{}.Hash("correct horse")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$"), hash)
+	})
+
+	t.Run("unsupported hashes", func(t *testing.T) {
+		for _, hash := range []string{"", "plain", "$argon2id$v=19$m=1024", "$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5"} {
+			_, err := VerifyPassword("correct horse", hash)
+			require.ErrorIs(t, err, ErrUnsupportedPasswordHash, hash)
+		}
+	})
+}
+
+type userStoreFunc func(ctx context.Context, username string) (User, error)
+
+func (f userStoreFunc) UserByName(ctx context.Context, username string) (User, error) {
+	return f(ctx, username)
+}
+
+func TestCredentials(t *testing.T) {
+	hash, err := Argon2idHasher{Memory: 1024, Time: 1}.Hash("correct horse")
+	require.NoError(t, err)
+	users := userStoreFunc(func(_ context.Context, username string) (User, error) {
+		if username != "alice" {
+			return User{}, ErrUserNotFound
+		}
+		return User{ID: "1", Username: "alice", PasswordHash: hash, Roles: []string{"admin"}}, nil
+	})
+
+	t.Run("verifies the password", func(t *testing.T) {
+		credentials := NewCredentials(CredentialsConfig{Users: users})
+		claims, err := credentials.Verify("alice", "correct horse")
+		require.NoError(t, err)
+		require.Equal(t, jwt.MapClaims{"sub": "1", "username": "alice", "roles": []string{"admin"}}, claims)
+
+		_, err = credentials.Verify("alice", "battery staple")
+		require.ErrorAs(t, err, &UnauthorizedError{})
+		_, unknownErr := credentials.Verify("bob", "correct horse")
+		require.Equal(t, err, unknownErr, "same error for unknown users")
+	})
+
+	t.Run("locks the accounts out", func(t *testing.T) {
+		now := time.Now()
+		credentials := NewCredentials(CredentialsConfig{
+			Users:    users,
+			Throttle: &LoginThrottle{MaxAttempts: 3, Lockout: time.Minute},
+			Now:      func() time.Time { return now },
+		})
+
+		for range 3 {
+			_, err := credentials.Verify("alice", "battery staple")
+			require.ErrorAs(t, err, &UnauthorizedError{})
+		}
+		_, err := credentials.Verify("alice", "correct horse")
+		var httpError HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusTooManyRequests, httpError.StatusCode())
+
+		now = now.Add(time.Minute)
+		_, err = credentials.Verify("alice", "correct horse")
+		require.NoError(t, err)
+	})
+
+	t.Run("failures are forgotten after the window or a login", func(t *testing.T) {
+		now := time.Now()
+		credentials := NewCredentials(CredentialsConfig{
+			Users:    users,
+			Throttle: &LoginThrottle{MaxAttempts: 2, Window: time.Minute},
+			Now:      func() time.Time { return now },
+		})
+
+		_, _ = credentials.Verify("alice", "battery staple")
+		now = now.Add(2 * time.Minute)
+		_, _ = credentials.Verify("alice", "battery staple")
+		_, err := credentials.Verify("alice", "correct horse")
+		require.NoError(t, err)
+
+		_, _ = credentials.Verify("alice", "battery staple")
+		_, err = credentials.Verify("alice", "correct horse")
+		require.NoError(t, err)
+	})
+
+	t.Run("with WithAutoAuth", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithAutoAuth(NewCredentials(CredentialsConfig{Users: users}).Verify))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"user":"alice","password":"correct horse"}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"token"`)
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"user":"alice","password":"battery staple"}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestSQLUserStore(t *testing.T) {
+	if !slices.Contains(sql.Drivers(), "fuego-users") {
+		sql.Register("fuego-users", usersDriver{
+			"alice": {"1", "$argon2id$hash", "admin,editor"},
+		})
+	}
+	db, err := sql.Open("fuego-users", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	user, err := SQLUserStore{DB: db, Query: "SELECT id, password_hash, roles FROM users WHERE username = $1"}.UserByName(context.Background(), "alice")
+	require.NoError(t, err)
+	require.Equal(t, User{ID: "1", Username: "alice", PasswordHash: "$argon2id$hash", Roles: []string{"admin", "editor"}}, user)
+
+	user, err = SQLUserStore{DB: db}.UserByName(context.Background(), "alice")
+	require.NoError(t, err)
+	require.Empty(t, user.Roles, "without the roles column")
+
+	_, err = SQLUserStore{DB: db}.UserByName(context.Background(), "bob")
+	require.ErrorIs(t, err, ErrUserNotFound)
+}
+
+// usersDriver is a SQL driver answering the queries of [SQLUserStore] from a map of users.
+type usersDriver map[string][]string
+
+func (d usersDriver) Open(string) (driver.Conn, error) { return usersConn{d}, nil }
+
+type usersConn struct{ users usersDriver }
+
+func (c usersConn) Prepare(query string) (driver.Stmt, error) {
+	return usersStmt{users: c.users, withRoles: strings.Contains(query, "roles")}, nil
+}
+func (c usersConn) Close() error              { return nil }
+func (c usersConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type usersStmt struct {
+	users     usersDriver
+	withRoles bool
+}
+
+func (s usersStmt) Close() error                               { return nil }
+func (s usersStmt) NumInput() int                              { return 1 }
+func (s usersStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s usersStmt) Query(args []driver.Value) (driver.Rows, error) {
+	columns := []string{"id", "password_hash"}
+	if s.withRoles {
+		columns = append(columns, "roles")
+	}
+	row, ok := s.users[args[0].(string)]
+	if !ok {
+		return &usersRows{columns: columns, done: true}, nil
+	}
+	return &usersRows{columns: columns, row: row[:len(columns)]}, nil
+}
+
+type usersRows struct {
+	columns []string
+	row     []string
+	done    bool
+}
+
+func (r *usersRows) Columns() []string { return r.columns }
+func (r *usersRows) Close() error      { return nil }
+func (r *usersRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	for i, value := range r.row {
+		dest[i] = value
+	}
+	return nil
+}
@@ -1,22 +1,30 @@
 package fuego
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gorilla/schema"
+
 	"github.com/go-fuego/fuego/internal"
 )
 
 const (
 	maxBodySize = 1048576
+	// Maximum nesting depth of the JSON bodies, by default. See [JSONDecoderOptions].
+	maxBodyDepth = 100
 )
 
 type ContextNoBody = ContextWithBody[any]
@@ -37,6 +45,27 @@ type ContextWithBody[B any] interface {
 	// MustBody works like Body, but panics if there is an error.
 	MustBody() B
 
+	// RawBody returns the exact bytes of the request body, even after [ContextWithBody.Body] has decoded them.
+	// It is useful to verify signatures or to archive payloads.
+	// The body size is limited by [WithMaxBodySize].
+	RawBody() []byte
+
+	// FormFile returns the first file of the field of the multipart/form-data request body.
+	// Declare it with [OptionFile] to document it.
+	// Example:
+	//   fuego.Post(s, "/avatars", func(c fuego.ContextNoBody) (any, error) {
+	//   	header, err := c.FormFile("avatar")
+	//   	if err != nil {
+	//   		return nil, err
+	//   	}
+	//   	file, err := header.Open()
+	//   	...
+	//   }, option.File("avatar", "Profile picture", param.Required()))
+	FormFile(name string) (*multipart.FileHeader, error)
+	// FormFiles returns all the files of the multipart/form-data request body, by field.
+	// Declare them with [OptionFiles] to document them.
+	FormFiles() (map[string][]*multipart.FileHeader, error)
+
 	// PathParam returns the path parameter with the given name.
 	// If it does not exist, it returns an empty string.
 	// Example:
@@ -57,6 +86,16 @@ type ContextWithBody[B any] interface {
 	QueryParamBoolErr(name string) (bool, error)
 	QueryParams() url.Values
 
+	// Pagination returns the page asked by the request, from the page, per_page and cursor query parameters,
+	// with the defaults and the cap set with [WithPagination]. They are documented on the routes returning a [Page].
+	// Example:
+	//   fuego.Get(s, "/pets", func(c fuego.ContextNoBody) (fuego.Page[Pet], error) {
+	//   	p := c.Pagination()
+	//   	pets, total, err := store.ListPets(c, p.Offset(), p.PerPage)
+	//   	return fuego.NewPage(pets, p, total), err
+	//   })
+	Pagination() Pagination
+
 	MainLang() string   // ex: fr. MainLang returns the main language of the request. It is the first language of the Accept-Language header. To get the main locale (ex: fr-CA), use [Ctx.MainLocale].
 	MainLocale() string // ex: en-US. MainLocale returns the main locale of the request. It is the first locale of the Accept-Language header. To get the main language (ex: en), use [Ctx.MainLang].
 
@@ -78,6 +117,15 @@ type ContextWithBody[B any] interface {
 	// By default, [templateToExecute] is added to the list of templates to override.
 	Render(templateToExecute string, data any, templateGlobsToOverride ...string) (CtxRenderer, error)
 
+	// RenderPDF renders the given templates like [Ctx.Render], and converts the HTML to a PDF document
+	// with the converter set with [WithPDFConverter]. The route is documented as application/pdf.
+	// Example:
+	//   fuego.Get(s, "/invoices/{id}.pdf", func(c fuego.ContextNoBody) (fuego.PDF, error) {
+	//   	invoice, _ := invoices.Get(c.Context(), c.PathParam("id"))
+	//   	return c.RenderPDF("invoice.html", invoice)
+	//   })
+	RenderPDF(templateToExecute string, data any, templateGlobsToOverride ...string) (PDF, error)
+
 	Cookie(name string) (*http.Cookie, error) // Get request cookie
 	SetCookie(cookie http.Cookie)             // Sets response cookie
 	Header(key string) string                 // Get request header
@@ -91,9 +139,27 @@ type ContextWithBody[B any] interface {
 	//  ctx := c.Context().(echo.Context) // echo: Safe because the underlying context is always a [echo.Context]
 	Context() context.Context
 
+	// IsAborted reports whether the client has disconnected before the response.
+	// Long-running controllers can check it, or wait on Done, to stop early.
+	// See [WithClientDisconnectDetection] to skip the response of the aborted requests.
+	IsAborted() bool
+
 	Request() *http.Request        // Request returns the underlying HTTP request.
 	Response() http.ResponseWriter // Response returns the underlying HTTP response writer.
 
+	// HTTPClient returns a client for the outgoing requests of the controller, whose requests carry
+	// the request ID and the trace context (traceparent, tracestate and baggage headers) of the incoming request,
+	// so the downstream services can be correlated. The request context should be given to the outgoing requests.
+	// Its base client is set with [WithHTTPClient].
+	// Example:
+	//   req, _ := http.NewRequestWithContext(c, http.MethodGet, "http://inventory/items", nil)
+	//   res, err := c.HTTPClient().Do(req)
+	HTTPClient() *http.Client
+
+	// SSE starts a stream of Server-Sent Events, and returns the writer of the events. See [SSEWriter].
+	// It must be called once, before anything else is written to the response.
+	SSE() *SSEWriter
+
 	// SetStatus sets the status code of the response.
 	// Alias to http.ResponseWriter.WriteHeader.
 	SetStatus(code int)
@@ -111,10 +177,11 @@ type ContextWithBody[B any] interface {
 func NewNetHTTPContext[B any](route BaseRoute, w http.ResponseWriter, r *http.Request, options readOptions) *netHttpContext[B] {
 	c := &netHttpContext[B]{
 		CommonContext: internal.CommonContext[B]{
-			CommonCtx:         r.Context(),
-			UrlValues:         r.URL.Query(),
-			OpenAPIParams:     route.Params,
-			DefaultStatusCode: route.DefaultStatusCode,
+			CommonCtx:          r.Context(),
+			UrlValues:          r.URL.Query(),
+			OpenAPIParams:      route.Params,
+			DefaultStatusCode:  route.DefaultStatusCode,
+			ValidationScenario: route.ValidationScenario,
 		},
 		Req:         r,
 		Res:         w,
@@ -132,11 +199,22 @@ type netHttpContext[Body any] struct {
 
 	fs fs.FS
 
-	body *Body // Cache the body in request context, because it is not possible to read an HTTP request body multiple times.
+	body       *Body  // Cache the body in request context, because it is not possible to read an HTTP request body multiple times.
+	rawBody    []byte // Exact bytes of the body, captured while reading it.
+	rawBodyErr error  // Error encountered while reading the raw body, if any.
 
 	Req       *http.Request
 	templates *template.Template
 
+	// Fields of the render data sanitized before rendering.
+	sanitizedRenderFields []string
+	// Timeout of the renderings, set with [WithRenderTimeout].
+	renderTimeout time.Duration
+	// Converter of the PDF renderings, set with [WithPDFConverter].
+	pdfConverter PDFConverter
+	// Default and maximum number of items per page, set with [WithPagination].
+	pagination PaginationConfig
+
 	serializer      Sender
 	errorSerializer ErrorSender
 
@@ -162,6 +240,23 @@ type readOptions struct {
 	MaxBodySize           int64
 	DisallowUnknownFields bool
 	LogBody               bool
+	// Maximum nesting depth of the arrays and objects of the JSON bodies. 0 means no limit.
+	MaxDepth int
+	// If true, the numbers of the JSON bodies are decoded into any as [json.Number].
+	UseNumber bool
+	// If true, the JSON bodies holding NaN or infinite numbers are rejected.
+	DisallowNaN bool
+	// Custom decoders, by media type. They take precedence over the built-in decoders.
+	BodyDecoders map[string]BodyDecoder
+	// Content types accepted for binary bodies ([]byte or io.Reader). If empty, all content types are accepted.
+	BinaryContentTypes []string
+	// Layouts used to decode time.Time form fields, tried before the default ones.
+	TimeLayouts []string
+	// If true, an empty body decodes to the zero value. See [OptionOptionalBody].
+	OptionalBody bool
+	// Decoder of the form bodies, keeping the fields of the decoded structs in cache.
+	// Built on each request if nil.
+	formDecoder *schema.Decoder
 }
 
 func (c netHttpContext[B]) Redirect(code int, url string) (any, error) {
@@ -215,10 +310,25 @@ func (c netHttpContext[B]) Render(templateToExecute string, data any, layoutsGlo
 		templates:         c.templates,
 		layoutsGlobs:      layoutsGlobs,
 		fs:                c.fs,
-		data:              data,
+		data:              sanitizeRenderData(data, c.sanitizedRenderFields),
+		timeout:           c.renderTimeout,
 	}, nil
 }
 
+// RenderPDF renders the given templates, and converts the HTML to a PDF document once the controller returns.
+func (c netHttpContext[B]) RenderPDF(templateToExecute string, data any, layoutsGlobs ...string) (PDF, error) {
+	if c.pdfConverter == nil {
+		return PDF{}, errNoPDFConverter
+	}
+	html, err := c.Render(templateToExecute, data, layoutsGlobs...)
+	return PDF{html: html, converter: c.pdfConverter}, err
+}
+
+// Pagination returns the page asked by the request.
+func (c netHttpContext[B]) Pagination() Pagination {
+	return c.pagination.Parse(c.UrlValues)
+}
+
 // PathParam returns the path parameters of the request.
 func (c netHttpContext[B]) PathParam(name string) string {
 	return c.Req.PathValue(name)
@@ -270,8 +380,16 @@ func PathParamIntErr(c ContextWithPathParam, name string) (int, error) {
 	return i, nil
 }
 
+// requestPathParams reads the path parameters of a request.
+// Unlike the context, it fits in an interface without being copied to the heap.
+type requestPathParams struct{ r *http.Request }
+
+func (p requestPathParams) PathParam(name string) string {
+	return p.r.PathValue(name)
+}
+
 func (c netHttpContext[B]) PathParamIntErr(name string) (int, error) {
-	return PathParamIntErr(c, name)
+	return PathParamIntErr(requestPathParams{c.Req}, name)
 }
 
 func PathParamInt(c ContextWithPathParam, name string) int {
@@ -286,7 +404,7 @@ func PathParamInt(c ContextWithPathParam, name string) int {
 // PathParamInt returns the path parameter with the given name as an int.
 // If the query parameter does not exist, or if it is not an int, it returns 0.
 func (c netHttpContext[B]) PathParamInt(name string) int {
-	return PathParamInt(c, name)
+	return PathParamInt(requestPathParams{c.Req}, name)
 }
 
 func (c netHttpContext[B]) MainLang() string {
@@ -307,6 +425,16 @@ func (c netHttpContext[B]) Response() http.ResponseWriter {
 	return c.Res
 }
 
+// HTTPClient returns a client propagating the request ID and the trace context of the request.
+func (c netHttpContext[B]) HTTPClient() *http.Client {
+	return c.PropagatingHTTPClient(c.Req.Header, c.Res.Header())
+}
+
+// SSE starts a stream of Server-Sent Events.
+func (c netHttpContext[B]) SSE() *SSEWriter {
+	return NewSSEWriter(c.Res, c.Req)
+}
+
 // MustBody works like Body, but panics if there is an error.
 func (c *netHttpContext[B]) MustBody() B {
 	b, err := c.Body()
@@ -326,11 +454,123 @@ func (c *netHttpContext[B]) Body() (B, error) {
 		return *c.body, nil
 	}
 
-	body, err := body[B](*c)
+	if isBinaryBody[B]() {
+		body, err := c.binaryBody()
+		c.body = &body
+		return body, err
+	}
+
+	if c.rawBody == nil {
+		raw := &bytes.Buffer{}
+		c.Req.Body = teeReadCloser{Reader: io.TeeReader(c.limitedBody(), raw), Closer: c.Req.Body}
+		defer func() {
+			// Capture what the decoder did not read, like trailing whitespace.
+			_, _ = io.Copy(io.Discard, c.Req.Body)
+			c.rawBody = raw.Bytes()
+		}()
+	}
+
+	if c.readOptions.OptionalBody && isEmptyBody(c.Req) {
+		var body B
+		c.body = &body
+		return body, nil
+	}
+
+	body, err := body[B](c)
 	c.body = &body
 	return body, err
 }
 
+// isEmptyBody reports whether the request has no body, reading ahead if its length is unknown.
+func isEmptyBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return true
+	}
+	reader := bufio.NewReader(r.Body)
+	_, err := reader.Peek(1)
+	r.Body = teeReadCloser{Reader: reader, Closer: r.Body}
+	return errors.Is(err, io.EOF)
+}
+
+// binaryBody returns the body as-is, for []byte and io.Reader body types.
+// io.Reader bodies are streamed: they are not buffered, so [netHttpContext.RawBody] is not available.
+func (c *netHttpContext[B]) binaryBody() (B, error) {
+	var body B
+	contentType := c.Req.Header.Get("Content-Type")
+	if !acceptsContentType(contentType, c.readOptions.BinaryContentTypes) {
+		return body, HTTPError{
+			Title:  "Unsupported Media Type",
+			Status: http.StatusUnsupportedMediaType,
+			Detail: fmt.Sprintf("unsupported Content-Type %q, expected one of %v", contentType, c.readOptions.BinaryContentTypes),
+		}
+	}
+
+	switch b := any(&body).(type) {
+	case *io.Reader:
+		if c.rawBody != nil {
+			*b = bytes.NewReader(c.rawBody)
+		} else {
+			*b = c.limitedBody()
+		}
+	case *[]byte:
+		*b = c.RawBody()
+		if c.rawBodyErr != nil {
+			return body, bodyError(c.rawBodyErr, "", "cannot read request body")
+		}
+	}
+
+	return body, nil
+}
+
+// RawBody returns the exact bytes of the request body, even after [netHttpContext.Body] has decoded them.
+// If the body has not been decoded yet, it is read and can still be decoded afterward.
+func (c *netHttpContext[B]) RawBody() []byte {
+	if c.rawBody != nil || c.body != nil {
+		return c.rawBody
+	}
+
+	rawBody, err := io.ReadAll(c.limitedBody())
+	c.rawBody = rawBody
+	c.rawBodyErr = err
+	c.Req.Body = io.NopCloser(bytes.NewReader(rawBody))
+	return c.rawBody
+}
+
+func (c *netHttpContext[B]) FormFile(name string) (*multipart.FileHeader, error) {
+	return FormFile(c.multipartRequest(), name)
+}
+
+func (c *netHttpContext[B]) FormFiles() (map[string][]*multipart.FileHeader, error) {
+	return FormFiles(c.multipartRequest())
+}
+
+// multipartRequest returns the request, with its body limited if it has not been read yet.
+func (c *netHttpContext[B]) multipartRequest() *http.Request {
+	if c.Req.MultipartForm == nil && c.rawBody == nil && c.body == nil {
+		c.limitedBody()
+	}
+	return c.Req
+}
+
+// setRequestContext replaces the context of the request, seen by the controller.
+func (c *netHttpContext[B]) setRequestContext(ctx context.Context) {
+	c.CommonCtx = ctx
+	c.Req = c.Req.WithContext(ctx)
+}
+
+// limitedBody limits the size of the request body.
+func (c *netHttpContext[B]) limitedBody() io.ReadCloser {
+	if c.readOptions.MaxBodySize != 0 {
+		c.Req.Body = http.MaxBytesReader(nil, c.Req.Body, c.readOptions.MaxBodySize)
+	}
+	return c.Req.Body
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
 // Serialize serializes the given data to the response. It uses the Content-Type header to determine the serialization format.
 func (c netHttpContext[B]) Serialize(data any) error {
 	if c.serializer == nil {
@@ -355,27 +595,24 @@ func (c netHttpContext[B]) SetDefaultStatusCode() {
 	}
 }
 
-func body[B any](c netHttpContext[B]) (B, error) {
-	// Limit the size of the request body.
-	if c.readOptions.MaxBodySize != 0 {
-		c.Req.Body = http.MaxBytesReader(nil, c.Req.Body, c.readOptions.MaxBodySize)
-	}
-
+func body[B any](c *netHttpContext[B]) (B, error) {
 	timeDeserialize := time.Now()
 
 	var body B
 	var err error
+	if decode, ok := c.readOptions.bodyDecoder(c.Req.Header.Get("Content-Type")); ok {
+		body, err = readWithDecoder[B](c, c.Req.Body, decode)
+		c.Res.Header().Add("Server-Timing", Timing{"deserialize", "controller > deserialize", time.Since(timeDeserialize)}.String())
+		return body, err
+	}
+
 	switch c.Req.Header.Get("Content-Type") {
 	case "text/plain":
-		s, errReadingString := readString[string](c.Req.Context(), c.Req.Body, c.readOptions)
+		s, errReadingString := readString[string](c, c.Req.Body, c.readOptions)
 		body = any(s).(B)
 		err = errReadingString
-	case "application/x-www-form-urlencoded", "multipart/form-data":
-		body, err = readURLEncoded[B](c.Req, c.readOptions)
 	case "application/xml":
-		body, err = readXML[B](c.Req.Context(), c.Req.Body, c.readOptions)
-	case "application/x-yaml", "text/yaml; charset=utf-8", "application/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
-		body, err = readYAML[B](c.Req.Context(), c.Req.Body, c.readOptions)
+		body, err = readXML[B](c, c.Req.Body, c.readOptions)
 	case "application/octet-stream":
 		// Read c.Req Body to bytes
 		bytes, err := io.ReadAll(c.Req.Body)
@@ -388,7 +625,14 @@ func body[B any](c netHttpContext[B]) (B, error) {
 		}
 		body = respBytes
 	default:
-		body, err = readJSON[B](c.Req.Context(), c.Req.Body, c.readOptions)
+		switch contentType := c.Req.Header.Get("Content-Type"); {
+		case isFormContentType(contentType):
+			body, err = readURLEncoded[B](c, c.Req, c.readOptions)
+		case isYAMLContentType(contentType):
+			body, err = readYAML[B](c, c.Req.Body, c.readOptions)
+		default:
+			body, err = readJSON[B](c, c.Req.Body, c.readOptions)
+		}
 	}
 
 	c.Res.Header().Add("Server-Timing", Timing{"deserialize", "controller > deserialize", time.Since(timeDeserialize)}.String())
@@ -6,6 +6,8 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -181,6 +183,76 @@ func TestContext_QueryParam(t *testing.T) {
 	})
 }
 
+func TestUndeclaredParamPolicy(t *testing.T) {
+	controller := func(c ContextNoBody) (string, error) {
+		return c.QueryParam("name") + c.QueryParam("undeclared"), nil
+	}
+
+	for _, tc := range []struct {
+		name         string
+		policy       UndeclaredParamPolicy
+		expectedCode int
+	}{
+		{name: "warn", policy: UndeclaredParamWarn, expectedCode: http.StatusOK},
+		{name: "ignore", policy: UndeclaredParamIgnore, expectedCode: http.StatusOK},
+		{name: "error", policy: UndeclaredParamError, expectedCode: http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewServer(WithEngineOptions(WithUndeclaredParamPolicy(tc.policy)))
+			Get(s, "/greet", controller, OptionQuery("name", "Name"))
+
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/greet?name=Ewen&undeclared=1", nil))
+
+			require.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				require.Equal(t, "Ewen1", w.Body.String())
+			} else {
+				require.Contains(t, w.Body.String(), "query parameter undeclared is not declared in the OpenAPI spec")
+			}
+		})
+	}
+
+	t.Run("declared params are allowed", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithUndeclaredParamPolicy(UndeclaredParamError)))
+		Get(s, "/greet", func(c ContextNoBody) (string, error) {
+			return c.QueryParam("name"), nil
+		}, OptionQuery("name", "Name"))
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/greet?name=Ewen", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("first undeclared param reported over the controller error", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithUndeclaredParamPolicy(UndeclaredParamError)))
+		Get(s, "/search", func(c ContextNoBody) (string, error) {
+			if c.QueryParam("q") == "" || c.QueryParamInt("limit") == 0 {
+				return "", BadRequestError{Detail: "missing q"}
+			}
+			return "ok", nil
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search", nil))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Contains(t, w.Body.String(), "query parameter q is not declared in the OpenAPI spec")
+	})
+
+	t.Run("other panics are not recovered", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithUndeclaredParamPolicy(UndeclaredParamError)))
+		Get(s, "/panic", func(c ContextNoBody) (string, error) {
+			panic("boom")
+		})
+
+		require.PanicsWithValue(t, "boom", func() {
+			s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+		})
+	})
+}
+
 func TestContext_QueryParams(t *testing.T) {
 	r := httptest.NewRequest("GET", "http://example.com/foo/123?id=456&other=hello", nil)
 	w := httptest.NewRecorder()
@@ -407,6 +479,20 @@ age: 30
 		require.Equal(t, 30, body.Age)
 	})
 
+	t.Run("can read YAML body with any YAML media type", func(t *testing.T) {
+		for _, contentType := range []string{"application/yaml", "application/yaml; charset=utf-8", "text/yaml", "text/x-yaml"} {
+			r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader("name: John\nage: 30\n"))
+			r.Header.Add("Content-Type", contentType)
+
+			c := NewNetHTTPContext[testStruct](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+
+			body, err := c.Body()
+			require.NoError(t, err, contentType)
+			require.Equal(t, "John", body.Name, contentType)
+			require.Equal(t, 30, body.Age, contentType)
+		}
+	})
+
 	t.Run("unparsable because restricted to 1 byte", func(t *testing.T) {
 		reqBody := strings.NewReader(`{"name":"John","age":30}`)
 		c := NewNetHTTPContext[testStructInTransformerWithError](
@@ -518,6 +604,115 @@ func BenchmarkContext_Body(b *testing.B) {
 	})
 }
 
+func TestContext_BinaryBody(t *testing.T) {
+	t.Run("bytes are not decoded, whatever the content type", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(`{"not":"decoded"}`))
+		r.Header.Set("Content-Type", "image/png")
+
+		c := NewNetHTTPContext[[]byte](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+		body, err := c.Body()
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"not":"decoded"}`), body)
+	})
+
+	t.Run("reader is streamed", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader("firmware"))
+		r.Header.Set("Content-Type", "application/octet-stream")
+
+		c := NewNetHTTPContext[io.Reader](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+		body, err := c.Body()
+		require.NoError(t, err)
+		content, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.Equal(t, "firmware", string(content))
+	})
+
+	t.Run("reader after raw body", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader("firmware"))
+
+		c := NewNetHTTPContext[io.Reader](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+		require.Equal(t, "firmware", string(c.RawBody()))
+		body, err := c.Body()
+		require.NoError(t, err)
+		content, err := io.ReadAll(body)
+		require.NoError(t, err)
+		require.Equal(t, "firmware", string(content))
+	})
+
+	t.Run("enforces the content type", func(t *testing.T) {
+		options := readOptions{BinaryContentTypes: []string{"image/*", "application/pdf"}}
+		for contentType, accepted := range map[string]bool{
+			"image/png":        true,
+			"application/pdf":  true,
+			"application/json": false,
+			"":                 false,
+		} {
+			r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader("data"))
+			r.Header.Set("Content-Type", contentType)
+
+			c := NewNetHTTPContext[[]byte](BaseRoute{}, httptest.NewRecorder(), r, options)
+			_, err := c.Body()
+			if accepted {
+				require.NoError(t, err, contentType)
+				continue
+			}
+			var httpError HTTPError
+			require.ErrorAs(t, err, &httpError, contentType)
+			require.Equal(t, http.StatusUnsupportedMediaType, httpError.StatusCode())
+		}
+	})
+
+	t.Run("enforces the size limit", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader("too large"))
+
+		c := NewNetHTTPContext[[]byte](BaseRoute{}, httptest.NewRecorder(), r, readOptions{MaxBodySize: 3})
+		_, err := c.Body()
+		var httpError HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusRequestEntityTooLarge, httpError.StatusCode())
+	})
+}
+
+func TestContext_RawBody(t *testing.T) {
+	const payload = "{\"name\":\"John\",  \"age\":30}\n"
+
+	t.Run("can read raw body after decoding", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(payload))
+		c := NewNetHTTPContext[testStruct](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+
+		body, err := c.Body()
+		require.NoError(t, err)
+		require.Equal(t, "John", body.Name)
+		require.Equal(t, payload, string(c.RawBody()))
+	})
+
+	t.Run("can decode body after reading raw body", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(payload))
+		c := NewNetHTTPContext[testStruct](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+
+		require.Equal(t, payload, string(c.RawBody()))
+
+		body, err := c.Body()
+		require.NoError(t, err)
+		require.Equal(t, 30, body.Age)
+		require.Equal(t, payload, string(c.RawBody()))
+	})
+
+	t.Run("bounded by max body size", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(payload))
+		c := NewNetHTTPContext[testStruct](BaseRoute{}, httptest.NewRecorder(), r, readOptions{MaxBodySize: 4})
+
+		require.Equal(t, payload[:4], string(c.RawBody()))
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+		c := NewNetHTTPContext[any](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+
+		require.Empty(t, c.RawBody())
+	})
+}
+
 func TestContext_MustBody(t *testing.T) {
 	t.Run("can read JSON body", func(t *testing.T) {
 		// Create new Reader
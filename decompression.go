@@ -0,0 +1,153 @@
+package fuego
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Maximum size of the decompressed request bodies, by default. See [WithRequestDecompression].
+const maxDecompressedBodySize = 10 << 20
+
+// Decompressor returns a reader of the decompressed content of r. See [WithRequestDecompression].
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// RequestDecompressionConfig configures the decompression of the request bodies. See [WithRequestDecompression].
+type RequestDecompressionConfig struct {
+	// Maximum size of the decompressed bodies. Defaults to 10 MiB.
+	MaxSize int64
+	// Decompressors of other content encodings than gzip and deflate, like zstd or br.
+	// They can also replace the built-in ones.
+	Decompressors map[string]Decompressor
+}
+
+var defaultDecompressors = map[string]Decompressor{
+	"gzip": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+	// The deflate content encoding is the zlib format, see RFC 9110.
+	"deflate": zlib.NewReader,
+}
+
+// WithRequestDecompression decompresses the request bodies sent with a Content-Encoding header,
+// gzip and deflate by default, before they are decoded.
+// The size of the decompressed bodies is limited, so a small compressed body cannot exhaust
+// the memory of the server (zip bomb).
+//
+// Bodies larger than the limit get a 413 Request Entity Too Large error, corrupted bodies a 400 Bad Request error,
+// and bodies with an unsupported encoding a 415 Unsupported Media Type error. These errors are documented in the OpenAPI spec.
+//
+//	s := fuego.NewServer(
+//		fuego.WithRequestDecompression(fuego.RequestDecompressionConfig{
+//			MaxSize: 50 << 20,
+//			Decompressors: map[string]fuego.Decompressor{
+//				"zstd": func(r io.Reader) (io.ReadCloser, error) {
+//					decoder, err := zstd.NewReader(r) // github.com/klauspost/compress/zstd
+//					if err != nil {
+//						return nil, err
+//					}
+//					return decoder.IOReadCloser(), nil
+//				},
+//			},
+//		}),
+//	)
+func WithRequestDecompression(config RequestDecompressionConfig) func(*Server) {
+	if config.MaxSize <= 0 {
+		config.MaxSize = maxDecompressedBodySize
+	}
+	decompressors := make(map[string]Decompressor, len(defaultDecompressors)+len(config.Decompressors))
+	for encoding, decompressor := range defaultDecompressors {
+		decompressors[encoding] = decompressor
+	}
+	for encoding, decompressor := range config.Decompressors {
+		decompressors[strings.ToLower(encoding)] = decompressor
+	}
+	config.Decompressors = decompressors
+
+	return func(s *Server) {
+		s.globalMiddlewares = append(s.globalMiddlewares, config.middleware)
+		s.routeOptions = append(s.routeOptions,
+			OptionAddResponse(http.StatusRequestEntityTooLarge, "Request Entity Too Large _(decompressed body too large)_", Response{Type: HTTPError{}}),
+			OptionAddResponse(http.StatusUnsupportedMediaType, "Unsupported Media Type _(unsupported content encoding)_", Response{Type: HTTPError{}}),
+		)
+	}
+}
+
+// middleware replaces the body of the compressed requests by their decompressed content.
+func (config RequestDecompressionConfig) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentEncoding := r.Header.Get("Content-Encoding")
+		if contentEncoding == "" || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := config.decompress(r.Body, contentEncoding)
+		if err != nil {
+			SendError(w, r, err)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, body, config.MaxSize)
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+		r.ContentLength = -1
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decompress returns the decompressed content of the body. The encodings are listed in the order they were applied.
+func (config RequestDecompressionConfig) decompress(body io.ReadCloser, contentEncoding string) (io.ReadCloser, error) {
+	encodings := strings.Split(contentEncoding, ",")
+	readers := make([]io.Closer, 0, len(encodings))
+	var reader io.Reader = body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "identity" || encoding == "" {
+			continue
+		}
+
+		decompressor, ok := config.Decompressors[encoding]
+		if !ok || decompressor == nil {
+			closeAll(readers)
+			return nil, HTTPError{
+				Title:  "Unsupported Media Type",
+				Status: http.StatusUnsupportedMediaType,
+				Detail: fmt.Sprintf("unsupported Content-Encoding %q", encoding),
+			}
+		}
+		decompressed, err := decompressor(reader)
+		if err != nil {
+			closeAll(readers)
+			return nil, BadRequestError{
+				Title:  "Decompression Failed",
+				Err:    err,
+				Detail: fmt.Sprintf("cannot decompress the %s request body: %s", encoding, err),
+			}
+		}
+		readers = append(readers, decompressed)
+		reader = decompressed
+	}
+
+	return decompressedBody{Reader: reader, closers: append(readers, body)}, nil
+}
+
+// decompressedBody closes the decompressors and the original body.
+type decompressedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b decompressedBody) Close() error {
+	return closeAll(b.closers)
+}
+
+func closeAll(closers []io.Closer) error {
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		errs = append(errs, closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package fuego
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func deflated(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestWithRequestDecompression(t *testing.T) {
+	s := NewServer(WithoutLogger(), WithRequestDecompression(RequestDecompressionConfig{
+		MaxSize: 1024,
+		Decompressors: map[string]Decompressor{
+			"reverse": func(r io.Reader) (io.ReadCloser, error) {
+				data, err := io.ReadAll(r)
+				for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+					data[i], data[j] = data[j], data[i]
+				}
+				return io.NopCloser(bytes.NewReader(data)), err
+			},
+		},
+	}))
+	Post(s, "/pets", func(c ContextWithBody[petUpdate]) (petUpdate, error) {
+		require.Empty(t, c.Request().Header.Get("Content-Encoding"))
+		return c.Body()
+	})
+
+	post := func(body []byte, contentEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Encoding", contentEncoding)
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, r)
+		return w
+	}
+	pet := []byte(`{"name":"Rex"}`)
+
+	t.Run("decompresses the bodies", func(t *testing.T) {
+		for encoding, body := range map[string][]byte{
+			"gzip":          gzipped(t, pet),
+			"deflate":       deflated(t, pet),
+			"GZIP":          gzipped(t, pet),
+			"identity":      pet,
+			"deflate, gzip": gzipped(t, deflated(t, pet)),
+			"reverse":       []byte(`}"xeR":"eman"{`),
+		} {
+			w := post(body, encoding)
+			require.Equal(t, http.StatusOK, w.Code, encoding)
+			require.JSONEq(t, `{"name":"Rex"}`, w.Body.String(), encoding)
+		}
+	})
+
+	t.Run("uncompressed bodies", func(t *testing.T) {
+		w := post(pet, "")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("zip bomb", func(t *testing.T) {
+		bomb := gzipped(t, []byte(`{"name":"`+strings.Repeat("a", 10_000)+`"}`))
+		require.Less(t, len(bomb), 1024)
+
+		w := post(bomb, "gzip")
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Contains(t, w.Body.String(), "request body is larger than 1024 bytes")
+	})
+
+	t.Run("corrupted body", func(t *testing.T) {
+		w := post([]byte("not gzip"), "gzip")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "cannot decompress the gzip request body")
+
+		truncated := gzipped(t, pet)
+		w = post(truncated[:15], "gzip")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		w := post(pet, "br")
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		require.Contains(t, w.Body.String(), `unsupported Content-Encoding \"br\"`)
+	})
+
+	t.Run("documents the errors", func(t *testing.T) {
+		responses := s.OpenAPI.Description().Paths.Value("/pets").Post.Responses
+		require.NotNil(t, responses.Value("413"))
+		require.NotNil(t, responses.Value("415"))
+	})
+}
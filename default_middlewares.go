@@ -70,6 +70,11 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.wroteHeader = true
 }
 
+// Unwrap gives access to the features of the underlying writer, like flushing, with [http.ResponseController].
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func (rw *responseWriter) Write(b []byte) (int, error) {
 	if !rw.wroteHeader {
 		rw.WriteHeader(http.StatusOK)
@@ -88,14 +93,18 @@ func logRequest(requestID string, r *http.Request) {
 }
 
 func logResponse(r *http.Request, rw *responseWriter, requestID string, duration time.Duration) {
-	slog.Info("outgoing response",
+	attrs := []any{
 		"status_code", rw.status,
 		"method", r.Method,
 		"path", r.URL.Path,
 		"duration_ms", duration.Milliseconds(),
 		"request_id", requestID,
 		"remote_addr", r.RemoteAddr,
-	)
+	}
+	if variant := rw.Header().Get(VariantHeader); variant != "" {
+		attrs = append(attrs, "variant", variant)
+	}
+	slog.Info("outgoing response", attrs...)
 }
 
 type defaultLogger struct {
@@ -9,8 +9,16 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/schema"
 	"gopkg.in/yaml.v3"
@@ -19,6 +27,8 @@ import (
 // InTransformer is an interface for entities that can be transformed.
 // Useful for example for trimming strings, changing case, etc.
 // Can also raise an error if the entity is not valid.
+// The context is the one of the request: it can be asserted to a [ContextWithBody] to read the parameters,
+// but its Body method must not be called. See [WithTransformer] to register other transformers for a type.
 type InTransformer interface {
 	InTransform(context.Context) error // InTransforms the entity.
 }
@@ -26,6 +36,33 @@ type InTransformer interface {
 var ReadOptions = readOptions{
 	DisallowUnknownFields: true,
 	MaxBodySize:           maxBodySize,
+	MaxDepth:              maxBodyDepth,
+}
+
+// JSONDecoderOptions are the options of the JSON decoder of the request bodies. See [WithJSONDecoderOptions].
+type JSONDecoderOptions struct {
+	// Decodes the numbers into any (like the values of a map[string]any) as [json.Number] instead of float64,
+	// which loses the precision of the integers above 2^53, like some int64 IDs.
+	// Numbers decoded into typed fields, like int64, keep their precision anyway.
+	UseNumber bool
+	// Rejects the bodies holding NaN or infinite numbers, like the ones decoded from strings
+	// with the `json:",string"` option, with a 400 Bad Request error.
+	DisallowNaN bool
+	// Maximum nesting depth of the arrays and objects of the bodies, so deeply nested bodies cannot exhaust
+	// the resources of the server. Deeper bodies are rejected with a 400 Bad Request error.
+	// Defaults to 100, a negative value disables the limit.
+	MaxDepth int
+}
+
+// maxDepth returns the maximum nesting depth of the bodies, 0 for no limit.
+func (o JSONDecoderOptions) maxDepth() int {
+	switch {
+	case o.MaxDepth < 0:
+		return 0
+	case o.MaxDepth == 0:
+		return maxBodyDepth
+	}
+	return o.MaxDepth
 }
 
 // ReadJSON reads the request body as JSON.
@@ -41,10 +78,16 @@ func ReadJSON[B any](context context.Context, input io.Reader) (B, error) {
 // It will also read strings.
 func readJSON[B any](context context.Context, input io.Reader, options readOptions) (B, error) {
 	// Deserialize the request body.
-	dec := json.NewDecoder(input)
+	dec := json.NewDecoder(&jsonGuard{input: input, maxDepth: options.MaxDepth})
 	if options.DisallowUnknownFields {
 		dec.DisallowUnknownFields()
 	}
+	if options.UseNumber {
+		dec.UseNumber()
+	}
+	if options.DisallowNaN {
+		return read[B](context, finiteDecoder{dec})
+	}
 
 	return read[B](context, dec)
 }
@@ -87,26 +130,270 @@ func readYAML[B any](context context.Context, input io.Reader, options readOptio
 	return read[B](context, dec)
 }
 
+// isBinaryBody reports whether B is a raw binary body type ([]byte or io.Reader),
+// read as-is instead of being decoded.
+func isBinaryBody[B any]() bool {
+	var body B
+	switch any(&body).(type) {
+	case *[]byte, *io.Reader:
+		return true
+	}
+	return false
+}
+
+// acceptsContentType reports whether the Content-Type header matches one of the accepted content types.
+// Accepted content types can be wildcards like "image/*" or "*/*". If none is declared, everything is accepted.
+func acceptsContentType(contentType string, accepted []string) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, a := range accepted {
+		if a == "*/*" || strings.EqualFold(a, mediaType) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mediaType, strings.ToLower(prefix)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isYAMLContentType reports whether the Content-Type header is a YAML media type,
+// ignoring parameters like charset.
+func isYAMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
+		return true
+	}
+	return false
+}
+
 type decoder interface {
 	Decode(v any) error
 }
 
+// BodyDecoder decodes a request body into dst, a pointer to the body type.
+// See [WithBodyDecoder] and [OptionBodyDecoder].
+type BodyDecoder func(r io.Reader, dst any) error
+
+// bodyDecoderFunc adapts a [BodyDecoder] to the decoder interface.
+type bodyDecoderFunc struct {
+	input  io.Reader
+	decode BodyDecoder
+}
+
+func (d bodyDecoderFunc) Decode(v any) error {
+	return d.decode(d.input, v)
+}
+
+// readWithDecoder reads the request body with a custom decoder.
+// The decoded body is transformed and validated like any other body.
+func readWithDecoder[B any](context context.Context, input io.Reader, decode BodyDecoder) (B, error) {
+	return read[B](context, bodyDecoderFunc{input: input, decode: decode})
+}
+
+// mergeBodyDecoders merges the server and route decoders, the route ones taking precedence.
+func mergeBodyDecoders(serverDecoders, routeDecoders map[string]BodyDecoder) map[string]BodyDecoder {
+	if len(routeDecoders) == 0 {
+		return serverDecoders
+	}
+	decoders := maps.Clone(serverDecoders)
+	if decoders == nil {
+		decoders = make(map[string]BodyDecoder, len(routeDecoders))
+	}
+	maps.Copy(decoders, routeDecoders)
+	return decoders
+}
+
+// bodyDecoder returns the custom decoder registered for the media type of the given Content-Type header.
+func (o readOptions) bodyDecoder(contentType string) (BodyDecoder, bool) {
+	if len(o.BodyDecoders) == 0 || contentType == "" {
+		return nil, false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	decode, ok := o.BodyDecoders[mediaType]
+	return decode, ok
+}
+
 func read[B any](context context.Context, dec decoder) (B, error) {
 	var body B
 
 	err := dec.Decode(&body)
 	if err != nil && !errors.Is(err, io.EOF) {
-		return body, BadRequestError{
-			Title:  "Decoding Failed",
-			Err:    err,
-			Detail: "cannot decode request body: " + err.Error(),
-		}
+		return body, bodyError(err, "Decoding Failed", "cannot decode request body")
 	}
 	slog.Debug("Decoded body", "body", body)
 
 	return TransformAndValidate(context, body)
 }
 
+var errNonFiniteNumber = errors.New("NaN and infinite numbers are not allowed")
+
+// finiteDecoder rejects the decoded values holding NaN or infinite numbers.
+type finiteDecoder struct {
+	decoder
+}
+
+func (d finiteDecoder) Decode(v any) error {
+	if err := d.decoder.Decode(v); err != nil {
+		return err
+	}
+	if path, ok := nonFiniteNumber(reflect.ValueOf(v), "body"); ok {
+		return fmt.Errorf("%w, got one at %s", errNonFiniteNumber, path)
+	}
+	return nil
+}
+
+// nonFiniteNumber returns the path of the first NaN or infinite number held by v, if any.
+// The fields are named after their JSON name.
+func nonFiniteNumber(v reflect.Value, path string) (string, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return path, math.IsNaN(f) || math.IsInf(f, 0)
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return nonFiniteNumber(v.Elem(), path)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if field := v.Type().Field(i); field.IsExported() {
+				name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if name == "" || name == "-" {
+					name = field.Name
+				}
+				if fieldPath, ok := nonFiniteNumber(v.Field(i), path+"."+name); ok {
+					return fieldPath, true
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if itemPath, ok := nonFiniteNumber(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); ok {
+				return itemPath, true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if valuePath, ok := nonFiniteNumber(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())); ok {
+				return valuePath, true
+			}
+		}
+	}
+	return "", false
+}
+
+// bodyError returns the error of a request body that cannot be read or decoded:
+// a 413 error if the body is too large, a 400 error otherwise.
+func bodyError(err error, title, detail string) error {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return HTTPError{
+			Title:  "Request Entity Too Large",
+			Status: http.StatusRequestEntityTooLarge,
+			Err:    err,
+			Detail: fmt.Sprintf("request body is larger than %d bytes", maxBytesError.Limit),
+		}
+	}
+	return BadRequestError{
+		Title:  title,
+		Err:    err,
+		Detail: detail + ": " + err.Error(),
+	}
+}
+
+var (
+	errBodyTooDeep     = errors.New("request body is nested too deeply")
+	errBodyInvalidUTF8 = errors.New("request body is not valid UTF-8")
+)
+
+// jsonGuard reads a JSON body, failing as soon as its arrays and objects are nested deeper than maxDepth,
+// before the decoder allocates them, or as soon as it is not valid UTF-8, instead of decoding
+// the invalid bytes as replacement characters.
+type jsonGuard struct {
+	input io.Reader
+	// Maximum nesting depth, 0 means no limit.
+	maxDepth int
+
+	depth    int
+	inString bool
+	escaped  bool
+	// Incomplete UTF-8 sequence at the end of the previous read.
+	pending []byte
+	err     error
+}
+
+func (g *jsonGuard) Read(p []byte) (int, error) {
+	if g.err != nil {
+		return 0, g.err
+	}
+	n, err := g.input.Read(p)
+	if scanErr := g.scan(p[:n], errors.Is(err, io.EOF)); scanErr != nil {
+		g.err = scanErr
+		return 0, scanErr
+	}
+	return n, err
+}
+
+// scan checks the bytes read, the end of the body being reached or not.
+func (g *jsonGuard) scan(data []byte, eof bool) error {
+	if len(g.pending) > 0 {
+		data = append(g.pending, data...)
+		g.pending = nil
+	}
+
+	for i := 0; i < len(data); {
+		b := data[i]
+		if b >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size == 1 {
+				if !eof && !utf8.FullRune(data[i:]) {
+					g.pending = slices.Clone(data[i:])
+					return nil
+				}
+				return errBodyInvalidUTF8
+			}
+			i += size
+			continue
+		}
+		i++
+
+		switch {
+		case g.escaped:
+			g.escaped = false
+		case g.inString:
+			switch b {
+			case '\\':
+				g.escaped = true
+			case '"':
+				g.inString = false
+			}
+		case b == '"':
+			g.inString = true
+		case b == '{' || b == '[':
+			g.depth++
+			if g.maxDepth > 0 && g.depth > g.maxDepth {
+				return fmt.Errorf("%w: more than %d levels", errBodyTooDeep, g.maxDepth)
+			}
+		case b == '}' || b == ']':
+			g.depth--
+		}
+	}
+	return nil
+}
+
 // ReadString reads the request body as string.
 // Can be used independently of Fuego framework.
 // Customizable by modifying ReadOptions.
@@ -147,69 +434,202 @@ func convertSQLNullBool(value string) reflect.Value {
 	return reflect.ValueOf(v)
 }
 
-func newDecoder() *schema.Decoder {
+// defaultFormTimeLayouts are the layouts used to decode time.Time form fields:
+// RFC 3339 and the values of the HTML datetime-local, date and time inputs.
+var defaultFormTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"15:04:05",
+	"15:04",
+}
+
+// convertTime returns a converter trying the given layouts in order.
+func convertTime(layouts []string) func(string) reflect.Value {
+	return func(value string) reflect.Value {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return reflect.ValueOf(t)
+			}
+		}
+		return reflect.Value{}
+	}
+}
+
+func newDecoder(options readOptions) *schema.Decoder {
 	decoder := schema.NewDecoder()
 	decoder.RegisterConverter(sql.NullString{}, convertSQLNullString)
 	decoder.RegisterConverter(sql.NullBool{}, convertSQLNullBool)
+	decoder.RegisterConverter(time.Time{}, convertTime(append(slices.Clip(options.TimeLayouts), defaultFormTimeLayouts...)))
+	decoder.IgnoreUnknownKeys(!options.DisallowUnknownFields)
 	return decoder
 }
 
+// isFormContentType reports whether the Content-Type header is an HTML form media type,
+// ignoring parameters like the multipart boundary.
+func isFormContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
 // ReadURLEncoded reads the request body as HTML Form.
 func ReadURLEncoded[B any](r *http.Request) (B, error) {
-	return readURLEncoded[B](r, ReadOptions)
+	return readURLEncoded[B](r.Context(), r, ReadOptions)
 }
 
-// readURLEncoded reads the request body as HTML Form.
+// readURLEncoded reads the request body as HTML Form, url-encoded or multipart.
 // Can be used independently of framework using [ReadURLEncoded],
 // or as a method of Context.
-func readURLEncoded[B any](r *http.Request, options readOptions) (B, error) {
+//
+// Fields are matched by their schema tag, or their name:
+//   - multiple values (like checkboxes) are decoded into slices
+//   - nested structs are decoded from dotted names, like "address.street"
+//   - time.Time fields accept RFC 3339 and the HTML date and time inputs formats, see [WithFormTimeLayouts]
+//   - empty values are ignored, so pointer fields stay nil when the input is left empty
+func readURLEncoded[B any](context context.Context, r *http.Request, options readOptions) (B, error) {
 	var body B
 
-	err := r.ParseForm()
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(multipartMaxMemory)
+	} else {
+		err = r.ParseForm()
+	}
 	if err != nil {
-		return body, fmt.Errorf("cannot parse form: %w", err)
+		return body, bodyError(err, "Decoding Failed", "cannot parse form")
 	}
 
-	decoder := newDecoder()
-	decoder.IgnoreUnknownKeys(!options.DisallowUnknownFields)
+	decoder := options.formDecoder
+	if decoder == nil {
+		decoder = newDecoder(options)
+	}
 
-	err = decoder.Decode(&body, r.PostForm)
+	values := nonEmptyFormValues(r.PostForm)
+	err = decoder.Decode(&body, values)
 	if err != nil {
-		return body, BadRequestError{
-			Detail: "cannot decode x-www-form-urlencoded request body: " + err.Error(),
-			Err:    err,
-			Errors: []ErrorItem{
-				{Name: "form", Reason: "check that the form is valid, and that the content-type is correct"},
-			},
-		}
+		return body, formDecodingError(err, values)
 	}
 	slog.Debug("Decoded body", "body", body)
 
-	return TransformAndValidate(r.Context(), body)
+	return TransformAndValidate(context, body)
 }
 
-// transforms the input if possible.
+// Maximum size of the multipart form kept in memory, the files above are stored in temporary files.
+const multipartMaxMemory = 32 << 20
+
+// nonEmptyFormValues removes the empty values of the form,
+// so they are decoded like missing values instead of invalid ones.
+func nonEmptyFormValues(form url.Values) url.Values {
+	values := make(url.Values, len(form))
+	for key, formValues := range form {
+		for _, value := range formValues {
+			if value != "" {
+				values[key] = append(values[key], value)
+			}
+		}
+	}
+	return values
+}
+
+// formDecodingError returns a 400 error with an item for each invalid field.
+func formDecodingError(err error, values url.Values) error {
+	decodingError := BadRequestError{
+		Title:  "Decoding Failed",
+		Detail: "cannot decode form request body: " + err.Error(),
+		Err:    err,
+	}
+
+	var multiError schema.MultiError
+	if !errors.As(err, &multiError) {
+		decodingError.Errors = []ErrorItem{
+			{Name: "form", Reason: "check that the form is valid, and that the content-type is correct"},
+		}
+		return decodingError
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(multiError)) {
+		item := ErrorItem{
+			Name:   key,
+			Reason: multiError[key].Error(),
+			More:   map[string]any{"field": key},
+		}
+
+		var conversionError schema.ConversionError
+		var unknownKeyError schema.UnknownKeyError
+		switch {
+		case errors.As(multiError[key], &conversionError):
+			item.Reason = fmt.Sprintf("invalid value, expected %s", conversionError.Type)
+			item.More["type"] = conversionError.Type.String()
+			if formValues := values[conversionError.Key]; conversionError.Index >= 0 && conversionError.Index < len(formValues) {
+				item.More["value"] = formValues[conversionError.Index]
+			} else if len(formValues) > 0 {
+				item.More["value"] = formValues[len(formValues)-1]
+			}
+		case errors.As(multiError[key], &unknownKeyError):
+			item.Reason = "unknown field"
+		}
+
+		decodingError.Errors = append(decodingError.Errors, item)
+	}
+
+	return decodingError
+}
+
+// transforms the input if possible: with its [InTransformer] method first,
+// then with the transformers registered with [WithTransformer], in order.
+// When called by Fuego, ctx is the context of the request, a [ContextWithBody].
 func transform[B any](ctx context.Context, body B) (B, error) {
 	if inTransformerBody, ok := any(&body).(InTransformer); ok {
 		err := inTransformerBody.InTransform(ctx)
 		if err != nil {
-			return body, BadRequestError{
-				Title:  "Transformation Failed",
-				Err:    err,
-				Detail: "cannot transform request body: " + err.Error(),
-				Errors: []ErrorItem{
-					{Name: "transformation", Reason: "transformation failed"},
-				},
-			}
+			return body, transformationError(err)
 		}
 		body = *any(inTransformerBody).(*B)
 
 		slog.Debug("InTransformd body", "body", body)
 	}
 
+	provider, ok := ctx.(bodyTransformersProvider)
+	if !ok {
+		return body, nil
+	}
+	c, ok := ctx.(ContextWithBody[B])
+	if !ok {
+		return body, nil
+	}
+	for _, transformer := range provider.GetBodyTransformers() {
+		transform, ok := transformer.(func(ContextWithBody[B], *B) error)
+		if !ok {
+			continue
+		}
+		if err := transform(c, &body); err != nil {
+			return body, transformationError(err)
+		}
+	}
+
 	return body, nil
 }
 
+// bodyTransformersProvider is implemented by the contexts giving access to the transformers of [WithTransformer].
+type bodyTransformersProvider interface {
+	GetBodyTransformers() []any
+}
+
+func transformationError(err error) error {
+	return BadRequestError{
+		Title:  "Transformation Failed",
+		Err:    err,
+		Detail: "cannot transform request body: " + err.Error(),
+		Errors: []ErrorItem{
+			{Name: "transformation", Reason: "transformation failed"},
+		},
+	}
+}
+
 func TransformAndValidate[B any](context context.Context, body B) (B, error) {
 	body, err := transform(context, body)
 	if err != nil {
@@ -217,6 +637,14 @@ func TransformAndValidate[B any](context context.Context, body B) (B, error) {
 	}
 
 	err = validate(body)
+	if provider, ok := context.(validationScenarioProvider); ok && provider.GetValidationScenario() != "" {
+		err = mergeValidationErrors(err, validateScenario(body, provider.GetValidationScenario()))
+	}
+	if err != nil {
+		return body, err
+	}
+
+	err = validateCtx(context, &body)
 	if err != nil {
 		return body, err
 	}
@@ -0,0 +1,74 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func BenchmarkDecodeBody(b *testing.B) {
+	manyTags := `"` + strings.Repeat(`tag", "`, 200) + `tag"`
+	benchmarks := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "json", contentType: "application/json", body: `{"id":1,"name":"Rex","tags":["dog"]}`},
+		{name: "large json", contentType: "application/json", body: `{"id":1,"name":"Rex","tags":[` + manyTags + `]}`},
+		{name: "xml", contentType: "application/xml", body: `<benchPet><ID>1</ID><Name>Rex</Name><Tags>dog</Tags></benchPet>`},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: `id=1&name=Rex&tags=dog`},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(bm.body))
+				r.Header.Set("Content-Type", bm.contentType)
+				c := NewNetHTTPContext[benchPet](BaseRoute{}, httptest.NewRecorder(), r, readOptions{})
+				if _, err := c.Body(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("validation", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := TransformAndValidate(context.Background(), benchPet{ID: 1, Name: "Rex"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkValidationWarmup measures the first validation of a body type, with a validator
+// that has never seen it, like on the first request of a route without [WithValidationWarmup].
+func BenchmarkValidationWarmup(b *testing.B) {
+	body := benchPet{ID: 1, Name: "Rex"}
+	for _, warm := range []bool{false, true} {
+		name := "cold"
+		if warm {
+			name = "warm"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				b.StopTimer()
+				val := validator.New()
+				if warm {
+					warmUpValidator(val, &benchPet{})
+				}
+				b.StartTimer()
+				if err := val.Struct(body); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
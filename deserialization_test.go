@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"io"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/require"
 )
 
@@ -195,6 +202,81 @@ func TestInTransform(t *testing.T) {
 	})
 }
 
+type postWithAuthor struct {
+	Title    string `json:"title" validate:"required"`
+	AuthorID string `json:"author_id"`
+	Org      string `json:"org"`
+}
+
+func (p *postWithAuthor) InTransform(ctx context.Context) error {
+	p.Title = strings.TrimSpace(p.Title)
+	if c, ok := ctx.(ContextWithBody[postWithAuthor]); ok {
+		p.Org = c.PathParam("org")
+	}
+	return nil
+}
+
+type authorKey struct{}
+
+func TestTransformerPipeline(t *testing.T) {
+	var order []string
+	s := NewServer(WithEngineOptions(
+		WithTransformer(
+			func(c ContextWithBody[postWithAuthor], post *postWithAuthor) error {
+				order = append(order, "author")
+				post.AuthorID, _ = c.Value(authorKey{}).(string)
+				return nil
+			},
+			func(c ContextWithBody[postWithAuthor], post *postWithAuthor) error {
+				order = append(order, "check")
+				if post.Title == "forbidden" {
+					return errors.New("title not allowed")
+				}
+				return nil
+			},
+		),
+		WithTransformer(func(c ContextWithBody[BodyTest], body *BodyTest) error {
+			order = append(order, "other type")
+			return nil
+		}),
+	))
+	Post(s, "/orgs/{org}/posts", func(c ContextWithBody[postWithAuthor]) (postWithAuthor, error) {
+		return c.Body()
+	})
+
+	newRequest := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/orgs/acme/posts", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		return r.WithContext(context.WithValue(r.Context(), authorKey{}, "user-42"))
+	}
+
+	t.Run("transformers run in order with the request context", func(t *testing.T) {
+		order = nil
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, newRequest(`{"title":"  Hello  "}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"title":"Hello","author_id":"user-42","org":"acme"}`, w.Body.String())
+		require.Equal(t, []string{"author", "check"}, order)
+	})
+
+	t.Run("a transformer can reject the body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, newRequest(`{"title":"forbidden"}`))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "title not allowed")
+	})
+
+	t.Run("validation runs after the transformers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, newRequest(`{"title":"   "}`))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Validation Error")
+	})
+}
+
 type transformableString string
 
 func (t *transformableString) InTransform(context.Context) error {
@@ -269,6 +351,106 @@ func TestReadURLEncoded(t *testing.T) {
 	})
 }
 
+type formAddress struct {
+	Street string `schema:"street"`
+	City   string `schema:"city" validate:"required"`
+}
+
+type formBody struct {
+	Name      string      `schema:"name"`
+	Toppings  []string    `schema:"toppings"`
+	Address   formAddress `schema:"address"`
+	BirthDate time.Time   `schema:"birth_date"`
+	Age       *int        `schema:"age"`
+	Delivery  *time.Time  `schema:"delivery"`
+}
+
+func TestReadURLEncodedForm(t *testing.T) {
+	newFormRequest := func(form string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	t.Run("multi-value, nested, time and pointer fields", func(t *testing.T) {
+		r := newFormRequest("name=Ewen&toppings=cheese&toppings=ham&address.street=Main+St&address.city=Paris&birth_date=1990-05-12&age=&delivery=2025-01-02T19:30")
+		body, err := ReadURLEncoded[formBody](r)
+		require.NoError(t, err)
+		require.Equal(t, "Ewen", body.Name)
+		require.Equal(t, []string{"cheese", "ham"}, body.Toppings)
+		require.Equal(t, formAddress{Street: "Main St", City: "Paris"}, body.Address)
+		require.Equal(t, time.Date(1990, 5, 12, 0, 0, 0, 0, time.UTC), body.BirthDate)
+		require.Nil(t, body.Age, "empty values leave pointers nil")
+		require.NotNil(t, body.Delivery)
+		require.Equal(t, time.Date(2025, 1, 2, 19, 30, 0, 0, time.UTC), *body.Delivery)
+	})
+
+	t.Run("custom time layouts", func(t *testing.T) {
+		r := newFormRequest("address.city=Paris&birth_date=12/05/1990")
+		body, err := readURLEncoded[formBody](r.Context(), r, readOptions{TimeLayouts: []string{"02/01/2006"}})
+		require.NoError(t, err)
+		require.Equal(t, time.Date(1990, 5, 12, 0, 0, 0, 0, time.UTC), body.BirthDate)
+	})
+
+	t.Run("per-field errors", func(t *testing.T) {
+		r := newFormRequest("address.city=Paris&birth_date=yesterday&age=old&unknown=1")
+		_, err := ReadURLEncoded[formBody](r)
+
+		var badRequest BadRequestError
+		require.ErrorAs(t, err, &badRequest)
+		require.Equal(t, "Decoding Failed", badRequest.Title)
+		require.Len(t, badRequest.Errors, 3)
+		require.Equal(t, "age", badRequest.Errors[0].Name)
+		require.Equal(t, "invalid value, expected int", badRequest.Errors[0].Reason)
+		require.Equal(t, "old", badRequest.Errors[0].More["value"])
+		require.Equal(t, "birth_date", badRequest.Errors[1].Name)
+		require.Equal(t, "time.Time", badRequest.Errors[1].More["type"])
+		require.Equal(t, "unknown", badRequest.Errors[2].Name)
+		require.Equal(t, "unknown field", badRequest.Errors[2].Reason)
+	})
+
+	t.Run("nested fields are validated", func(t *testing.T) {
+		r := newFormRequest("address.street=Main+St")
+		_, err := ReadURLEncoded[formBody](r)
+
+		var validationError HTTPError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, http.StatusBadRequest, validationError.StatusCode())
+		require.Equal(t, "formBody.Address.City", validationError.Errors[0].Name)
+	})
+
+	t.Run("multipart form", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		require.NoError(t, writer.WriteField("name", "Ewen"))
+		require.NoError(t, writer.WriteField("toppings", "cheese"))
+		require.NoError(t, writer.WriteField("toppings", "ham"))
+		require.NoError(t, writer.WriteField("address.city", "Paris"))
+		require.NoError(t, writer.Close())
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", &buf)
+		r.Header.Set("Content-Type", writer.FormDataContentType())
+		c := NewNetHTTPContext[formBody](BaseRoute{}, w, r, readOptions{})
+
+		body, err := c.Body()
+		require.NoError(t, err)
+		require.Equal(t, "Ewen", body.Name)
+		require.Equal(t, []string{"cheese", "ham"}, body.Toppings)
+	})
+
+	t.Run("content type with charset", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=Ewen&address.city=Paris"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+		c := NewNetHTTPContext[formBody](BaseRoute{}, w, r, readOptions{})
+
+		body, err := c.Body()
+		require.NoError(t, err)
+		require.Equal(t, "Ewen", body.Name)
+	})
+}
+
 func TestConvertSQLNullString(t *testing.T) {
 	t.Run("can convert sql.NullString", func(t *testing.T) {
 		v := convertSQLNullString("test")
@@ -287,3 +469,319 @@ func TestConvertSQLNullBool(t *testing.T) {
 		require.Equal(t, v, reflect.Value{})
 	})
 }
+
+// decodeKeyValues decodes "key=value;key=value" bodies, a proprietary format used to test custom decoders.
+func decodeKeyValues(r io.Reader, dst any) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]any)
+	for _, pair := range strings.Split(strings.TrimSpace(string(raw)), ";") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return errors.New("invalid pair " + pair)
+		}
+		if n, err := strconv.Atoi(value); err == nil {
+			values[key] = n
+		} else {
+			values[key] = value
+		}
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+func TestWithBodyDecoder(t *testing.T) {
+	type person struct {
+		Name string `json:"name" validate:"required,min=3"`
+		Age  int    `json:"age"`
+	}
+
+	s := NewServer(
+		WithoutLogger(),
+		WithBodyDecoder("application/vnd.kv", decodeKeyValues),
+	)
+	Post(s, "/people", func(c ContextWithBody[person]) (person, error) {
+		return c.Body()
+	})
+	Post(s, "/transformed", func(c ContextWithBody[BodyTestWithInTransformer]) (BodyTestWithInTransformer, error) {
+		return c.Body()
+	}, OptionBodyDecoder("Application/Vnd.Route", func(r io.Reader, dst any) error {
+		return json.NewDecoder(r).Decode(dst)
+	}))
+	Post(s, "/override", func(c ContextWithBody[person]) (person, error) {
+		return c.Body()
+	},
+		OptionRequestContentType("application/json"),
+		OptionBodyDecoder("application/vnd.kv", func(r io.Reader, dst any) error {
+			return errors.New("route decoder")
+		}),
+	)
+
+	post := func(path, contentType, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("server decoder", func(t *testing.T) {
+		w := post("/people", "application/vnd.kv; charset=utf-8", "name=John;age=30")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name":"John","age":30}`, w.Body.String())
+	})
+
+	t.Run("validation still applies", func(t *testing.T) {
+		w := post("/people", "application/vnd.kv", "name=Jo;age=30")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("decoding error", func(t *testing.T) {
+		w := post("/people", "application/vnd.kv", "name")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "invalid pair")
+	})
+
+	t.Run("built-in decoders still work", func(t *testing.T) {
+		w := post("/people", "application/json", `{"name":"John","age":30}`)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("route decoder with transform", func(t *testing.T) {
+		w := post("/transformed", "application/vnd.route", `{"A":"a","B":1}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "transformed a")
+	})
+
+	t.Run("route decoder takes precedence", func(t *testing.T) {
+		w := post("/override", "application/vnd.kv", "name=John")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "route decoder")
+	})
+
+	t.Run("media type is documented", func(t *testing.T) {
+		content := s.OpenAPI.Description().Paths.Find("/override").Post.RequestBody.Value.Content
+		require.NotNil(t, content.Get("application/json"))
+		require.NotNil(t, content.Get("application/vnd.kv"))
+	})
+}
+
+// nested returns a fuzzBody with arrays and objects nested up to the depth.
+func nested(depth int) string {
+	return `{"extra":{"levels":` + strings.Repeat(`[`, depth-2) + strings.Repeat(`]`, depth-2) + `}}`
+}
+
+func TestReadJSONHardening(t *testing.T) {
+	t.Run("depth limit", func(t *testing.T) {
+		options := readOptions{MaxDepth: 10}
+		_, err := readJSON[fuzzBody](context.Background(), strings.NewReader(nested(10)), options)
+		require.NoError(t, err)
+
+		_, err = readJSON[fuzzBody](context.Background(), strings.NewReader(nested(11)), options)
+		require.ErrorIs(t, err, errBodyTooDeep)
+		require.ErrorAs(t, err, &BadRequestError{})
+
+		_, err = readJSON[fuzzBody](context.Background(), strings.NewReader(nested(11)), readOptions{})
+		require.NoError(t, err, "no limit")
+	})
+
+	t.Run("brackets in strings", func(t *testing.T) {
+		body, err := readJSON[fuzzBody](context.Background(), strings.NewReader(`{"name":"[[[\\\"{{{"}`), readOptions{MaxDepth: 1})
+		require.NoError(t, err)
+		require.Equal(t, `[[[\"{{{`, body.Name)
+	})
+
+	t.Run("invalid UTF-8", func(t *testing.T) {
+		_, err := readJSON[fuzzBody](context.Background(), strings.NewReader("{\"name\":\"\xff\"}"), readOptions{})
+		require.ErrorIs(t, err, errBodyInvalidUTF8)
+
+		_, err = readJSON[fuzzBody](context.Background(), strings.NewReader("{\"name\":\"\xe2\x82"), readOptions{})
+		require.ErrorIs(t, err, errBodyInvalidUTF8, "truncated sequence")
+	})
+
+	t.Run("UTF-8 sequences split between reads", func(t *testing.T) {
+		body, err := readJSON[fuzzBody](context.Background(), iotest.OneByteReader(strings.NewReader(`{"name":"Zoë 🐕"}`)), readOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "Zoë 🐕", body.Name)
+	})
+
+	t.Run("huge numbers", func(t *testing.T) {
+		for _, input := range []string{`{"count":1000}`, `{"price":1e999}`, `{"count":` + strings.Repeat("9", 10000) + `}`} {
+			_, err := readJSON[fuzzBody](context.Background(), strings.NewReader(input), readOptions{})
+			require.ErrorAs(t, err, &BadRequestError{}, input)
+		}
+	})
+
+	t.Run("bodies that are not structs are not validated", func(t *testing.T) {
+		for _, input := range []string{``, `null`, `"Rex"`, `42`} {
+			_, err := readJSON[any](context.Background(), strings.NewReader(input), readOptions{})
+			require.NoError(t, err, input)
+		}
+	})
+
+}
+
+type pricedItem struct {
+	ID    int64   `json:"id"`
+	Price float64 `json:"price,string"`
+}
+
+func TestWithJSONDecoderOptions(t *testing.T) {
+	post := func(s *Server, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+	newServer := func(options JSONDecoderOptions) *Server {
+		s := NewServer(WithJSONDecoderOptions(options))
+		Post(s, "/any", func(c ContextWithBody[map[string]any]) (map[string]any, error) { return c.Body() })
+		Post(s, "/items", func(c ContextWithBody[[]pricedItem]) (int, error) {
+			items, err := c.Body()
+			return len(items), err
+		})
+		Post(s, "/nested", func(c ContextWithBody[fuzzBody]) (string, error) {
+			_, err := c.Body()
+			return "ok", err
+		})
+		return s
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		s := newServer(JSONDecoderOptions{})
+
+		w := post(s, "/any", `{"id":9007199254740993}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"id":9007199254740992}`, w.Body.String(), "precision lost through float64")
+
+		w = post(s, "/items", `[{"id":9007199254740993,"price":"NaN"}]`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		require.Equal(t, http.StatusOK, post(s, "/nested", nested(maxBodyDepth)).Code)
+		require.Equal(t, http.StatusBadRequest, post(s, "/nested", nested(maxBodyDepth+1)).Code)
+	})
+
+	t.Run("use number", func(t *testing.T) {
+		s := newServer(JSONDecoderOptions{UseNumber: true})
+
+		w := post(s, "/any", `{"id":9007199254740993}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"id":9007199254740993}`, w.Body.String())
+	})
+
+	t.Run("disallow NaN", func(t *testing.T) {
+		s := newServer(JSONDecoderOptions{DisallowNaN: true})
+
+		for _, price := range []string{"NaN", "+Inf", "-infinity"} {
+			w := post(s, "/items", `[{"id":1,"price":"1"},{"id":2,"price":"`+price+`"}]`)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Contains(t, w.Body.String(), "NaN and infinite numbers are not allowed, got one at body[1].price")
+		}
+		require.Equal(t, http.StatusOK, post(s, "/items", `[{"id":1,"price":"1.5"}]`).Code)
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		s := newServer(JSONDecoderOptions{MaxDepth: 5})
+		require.Equal(t, http.StatusOK, post(s, "/nested", nested(5)).Code)
+		w := post(s, "/nested", nested(6))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "request body is nested too deeply: more than 5 levels")
+
+		s = newServer(JSONDecoderOptions{MaxDepth: -1})
+		require.Equal(t, http.StatusOK, post(s, "/nested", nested(maxBodyDepth+1)).Code)
+	})
+}
+
+func TestReadURLEncodedErrors(t *testing.T) {
+	t.Run("invalid form", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=%zz"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err := readURLEncoded[fuzzBody](context.Background(), r, readOptions{})
+		require.ErrorAs(t, err, &BadRequestError{})
+	})
+
+	t.Run("form too large", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name="+strings.Repeat("a", 100)))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Body = http.MaxBytesReader(nil, r.Body, 10)
+		_, err := readURLEncoded[fuzzBody](context.Background(), r, readOptions{})
+		var httpError HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusRequestEntityTooLarge, httpError.StatusCode())
+	})
+}
+
+type fuzzBody struct {
+	XMLName xml.Name          `xml:"fuzzBody"`
+	Name    string            `json:"name" xml:"name" schema:"name"`
+	Count   int8              `json:"count" xml:"count" schema:"count"`
+	Price   float32           `json:"price" xml:"price" schema:"price"`
+	Tags    []string          `json:"tags" xml:"tag" schema:"tags"`
+	Address *formAddress      `json:"address" xml:"address" schema:"address"`
+	Extra   map[string]any    `json:"extra" xml:"-" schema:"-"`
+	Date    time.Time         `json:"date" xml:"date" schema:"date"`
+	Labels  map[string]string `json:"labels" xml:"-" schema:"-"`
+}
+
+// requireDecodingError checks that the decoding failed with an error sent as a 4xx response, not a 500.
+func requireDecodingError(t *testing.T, err error) {
+	t.Helper()
+	if err == nil {
+		return
+	}
+	var errorWithStatus ErrorWithStatus
+	if errors.As(err, &errorWithStatus) {
+		require.Less(t, errorWithStatus.StatusCode(), http.StatusInternalServerError, err.Error())
+		return
+	}
+	var validationErrs validator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+}
+
+func FuzzReadJSON(f *testing.F) {
+	f.Add([]byte(`{"name":"Rex","count":3,"price":1.5,"tags":["a","b"],"address":{"city":"Paris"},"extra":{"a":[1,{"b":null}]}}`))
+	f.Add([]byte(`{"count":1e999}`))
+	f.Add([]byte(`{"extra":{"n":123456789012345678901234567890}}`))
+	f.Add([]byte(`{"name":"\xff\xfe"}`))
+	f.Add([]byte(`{"extra":` + strings.Repeat(`[`, 100) + strings.Repeat(`]`, 100) + `}`))
+	f.Add([]byte(`{"date":"2025-13-45T99:99:99Z"}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, err := readJSON[fuzzBody](context.Background(), bytes.NewReader(data), readOptions{MaxDepth: maxBodyDepth})
+		requireDecodingError(t, err)
+		_, err = readJSON[any](context.Background(), bytes.NewReader(data), readOptions{MaxDepth: maxBodyDepth})
+		requireDecodingError(t, err)
+	})
+}
+
+func FuzzReadXML(f *testing.F) {
+	f.Add([]byte(`<fuzzBody><name>Rex</name><count>3</count><tag>a</tag><address><city>Paris</city></address></fuzzBody>`))
+	f.Add([]byte(`<fuzzBody><count>1000</count></fuzzBody>`))
+	f.Add([]byte(`<fuzzBody><name>` + "\xff" + `</name></fuzzBody>`))
+	f.Add([]byte(`<!DOCTYPE x [<!ENTITY a "aaaa">]><fuzzBody><name>&a;</name></fuzzBody>`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, err := readXML[fuzzBody](context.Background(), bytes.NewReader(data), readOptions{})
+		requireDecodingError(t, err)
+	})
+}
+
+func FuzzReadURLEncoded(f *testing.F) {
+	f.Add("name=Rex&count=3&price=1.5&tags=a&tags=b&address.city=Paris&date=2025-01-02")
+	f.Add("count=99999999999999999999&price=1e999")
+	f.Add("address.city=%ff&name=%")
+	f.Add("tags.0=a&address.street.x=b")
+
+	f.Fuzz(func(t *testing.T, form string) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err := readURLEncoded[fuzzBody](context.Background(), r, readOptions{})
+		requireDecodingError(t, err)
+	})
+}
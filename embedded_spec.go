@@ -0,0 +1,76 @@
+package fuego
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// WithEmbeddedSpec serves a spec generated beforehand, like the file saved by the server in development,
+// instead of the spec generated from the routes. The spec is read from the given file system,
+// typically embedded in the binary, for the builds that must serve a reviewed spec:
+//
+//	//go:embed doc/openapi.json
+//	var specFS embed.FS
+//
+//	s := fuego.NewServer(
+//		fuego.WithEmbeddedSpec(specFS, "doc/openapi.json"),
+//	)
+//
+// The generated spec is neither saved to a file nor served. [Server.Run] fails if the embedded spec cannot be read,
+// and checks that the routes of the server are the operations of the embedded spec: the differences are logged,
+// or returned with [WithStrictRouteValidation], so that an outdated spec is noticed.
+func WithEmbeddedSpec(fsys fs.FS, name string) func(*Server) {
+	return func(s *Server) {
+		s.embeddedSpec, s.embeddedSpecErr = loadEmbeddedSpec(fsys, name)
+	}
+}
+
+func loadEmbeddedSpec(fsys fs.FS, name string) (*openapi3.T, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("read embedded spec: %w", err)
+	}
+	spec, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("load embedded spec %s: %w", name, err)
+	}
+	return spec, nil
+}
+
+// checkEmbeddedSpec returns a [RouteValidationError] listing the documented routes missing from the embedded spec,
+// and the operations of the embedded spec that are not registered.
+func (s *Server) checkEmbeddedSpec() error {
+	if s.embeddedSpecErr != nil {
+		return s.embeddedSpecErr
+	}
+	if s.embeddedSpec == nil {
+		return nil
+	}
+
+	var issues []RouteIssue
+	issues = append(issues, missingOperations(s.OpenAPI.Description(), s.embeddedSpec, "not in the embedded spec, regenerate it")...)
+	issues = append(issues, missingOperations(s.embeddedSpec, s.OpenAPI.Description(), "in the embedded spec, but not registered")...)
+	if len(issues) > 0 {
+		return RouteValidationError{Issues: issues}
+	}
+	return nil
+}
+
+// missingOperations returns an issue for each operation of the spec that is not in the other spec.
+func missingOperations(spec, other *openapi3.T, message string) []RouteIssue {
+	var issues []RouteIssue
+	paths := spec.Paths.Map()
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		otherPath := other.Paths.Value(path)
+		for _, method := range slices.Sorted(maps.Keys(paths[path].Operations())) {
+			if otherPath == nil || otherPath.GetOperation(method) == nil {
+				issues = append(issues, RouteIssue{Method: method, Path: path, Message: message})
+			}
+		}
+	}
+	return issues
+}
@@ -0,0 +1,76 @@
+package fuego
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const embeddedSpec = `{
+	"openapi": "3.1.0",
+	"info": {"title": "Reviewed API", "version": "1.0.0"},
+	"paths": {
+		"/pets": {
+			"get": {"responses": {"200": {"description": "OK"}}},
+			"post": {"responses": {"201": {"description": "Created"}}}
+		}
+	}
+}`
+
+func TestWithEmbeddedSpec(t *testing.T) {
+	fsys := fstest.MapFS{"doc/openapi.json": {Data: []byte(embeddedSpec)}}
+
+	newServer := func(t *testing.T, options ...func(*Server)) *Server {
+		t.Helper()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		return NewServer(append([]func(*Server){WithoutLogger(), WithListener(listener), WithoutStartupMessages()}, options...)...)
+	}
+
+	t.Run("serves the embedded spec", func(t *testing.T) {
+		s := newServer(t, WithEmbeddedSpec(fsys, "doc/openapi.json"), WithStrictRouteValidation())
+		Get(s, "/pets", testController)
+		Post(s, "/pets", testController)
+		require.NoError(t, s.setup())
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, s.OpenAPIConfig.SpecURL, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"title":"Reviewed API"`)
+		require.NotContains(t, w.Body.String(), "local server")
+	})
+
+	t.Run("reports the routes that do not match", func(t *testing.T) {
+		s := newServer(t, WithEmbeddedSpec(fsys, "doc/openapi.json"), WithStrictRouteValidation())
+		Get(s, "/pets", testController)
+		Delete(s, "/pets/{id}", testController)
+
+		err := s.setup()
+		var mismatch RouteValidationError
+		require.ErrorAs(t, err, &mismatch)
+		require.Equal(t, []RouteIssue{
+			{Method: http.MethodDelete, Path: "/pets/{id}", Message: "not in the embedded spec, regenerate it"},
+			{Method: http.MethodPost, Path: "/pets", Message: "in the embedded spec, but not registered"},
+		}, mismatch.Issues)
+	})
+
+	t.Run("mismatches are only logged without strict validation", func(t *testing.T) {
+		s := newServer(t, WithEmbeddedSpec(fsys, "doc/openapi.json"))
+		Get(s, "/pets", testController)
+		require.NoError(t, s.setup())
+	})
+
+	t.Run("unreadable spec", func(t *testing.T) {
+		s := newServer(t, WithEmbeddedSpec(fsys, "doc/missing.json"))
+		require.ErrorContains(t, s.setup(), "read embedded spec")
+
+		s = newServer(t, WithEmbeddedSpec(fstest.MapFS{"openapi.json": {Data: []byte("not a spec")}}, "openapi.json"))
+		require.ErrorContains(t, s.setup(), "load embedded spec openapi.json")
+	})
+}
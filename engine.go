@@ -8,8 +8,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/go-fuego/fuego/internal"
 )
 
 // NewEngine creates a new Engine with the given options.
@@ -42,7 +47,42 @@ type Engine struct {
 	ErrorHandler  func(error) error
 	OpenAPIConfig OpenAPIConfig
 
-	requestContentTypes []string
+	// What happens when a controller reads a query parameter not declared in the OpenAPI spec.
+	// See [WithUndeclaredParamPolicy].
+	UndeclaredParamPolicy UndeclaredParamPolicy
+
+	// Dependencies given to the ValidateCtx method of the request bodies. See [CtxValidator].
+	ValidationDeps any
+
+	// Transformers of the request bodies, by body type. See [WithTransformer].
+	bodyTransformers map[reflect.Type][]any
+
+	// Receives the internal errors and the panics. See [WithErrorReporter].
+	errorReporter ErrorReporter
+
+	// Which errors have their details hidden from the responses. See [WithErrorObfuscation].
+	errorObfuscation ErrorObfuscationPolicy
+
+	// Formats each invalid field of the validation errors. See [WithValidationErrorFormatter].
+	validationErrorFormatter func(validator.FieldError) ErrorItem
+
+	// If true, no response is sent to the clients that disconnected. See [WithClientDisconnectDetection].
+	clientDisconnectDetection bool
+
+	// Timeout of the contexts returned by [QueryContext]. See [WithQueryTimeout].
+	queryTimeout time.Duration
+
+	// Base client of the outgoing requests of the controllers. See [WithHTTPClient].
+	httpClient *http.Client
+
+	// If true, the request body types are prepared for validation and decoding at registration. See [WithValidationWarmup].
+	validationWarmup bool
+
+	// Removes or masks the fields of the responses the requester cannot see. See [WithFieldAuthorizer].
+	fieldAuthorizer FieldAuthorizer
+
+	requestContentTypes  []string
+	responseContentTypes []string
 }
 
 type OpenAPIConfig struct {
@@ -79,6 +119,13 @@ func WithRequestContentType(consumes ...string) func(*Engine) {
 	return func(e *Engine) { e.requestContentTypes = consumes }
 }
 
+// WithResponseContentType sets the content types documented for the responses of the engine.
+// By default, the documented content types are application/json and application/xml.
+// It only affects the OpenAPI spec: the response format is negotiated with the Accept header.
+func WithResponseContentType(produces ...string) func(*Engine) {
+	return func(e *Engine) { e.responseContentTypes = produces }
+}
+
 func WithOpenAPIConfig(config OpenAPIConfig) func(*Engine) {
 	return func(e *Engine) {
 		if config.JSONFilePath != "" {
@@ -110,6 +157,39 @@ func WithOpenAPIConfig(config OpenAPIConfig) func(*Engine) {
 	}
 }
 
+// WithSchemaGenerator replaces the generator of the schemas of the OpenAPI spec, openapi3gen by default,
+// for example to honor the jsonschema struct tags with github.com/invopop/jsonschema.
+// The description, example and validate struct tags are still applied to the generated schemas.
+//
+//	s := fuego.NewServer(
+//		fuego.WithEngineOptions(
+//			fuego.WithSchemaGenerator(openapi3gen.NewGenerator(openapi3gen.UseAllExportedFields())),
+//		),
+//	)
+func WithSchemaGenerator(generator SchemaGenerator) func(*Engine) {
+	return func(e *Engine) { e.OpenAPI.schemaGenerator = generator }
+}
+
+// WithDescriptionComposer sets how the descriptions of the routes in the OpenAPI spec are composed
+// from the route: its controller, its middlewares and the description given with the options,
+// available in route.Operation.Description. [ComposeDescription] is the default composition.
+//
+//	fuego.WithDescriptionComposer(func(route *fuego.BaseRoute) string {
+//		return route.Operation.Description + "\n\nHandled by `" + route.FullName + "`"
+//	})
+func WithDescriptionComposer(compose func(route *BaseRoute) string) func(*Engine) {
+	return func(e *Engine) { e.OpenAPI.composeDescription = compose }
+}
+
+// WithoutControllerInfoInDescription removes the controller and middlewares of the routes
+// from their descriptions in the OpenAPI spec, to keep the internals of the server out of public docs.
+// It is the default in the [Prod] environment.
+func WithoutControllerInfoInDescription() func(*Engine) {
+	return WithDescriptionComposer(func(route *BaseRoute) string {
+		return route.Operation.Description
+	})
+}
+
 // WithErrorHandler sets a customer error handler for the server
 func WithErrorHandler(errorHandler func(err error) error) func(*Engine) {
 	return func(e *Engine) {
@@ -121,6 +201,117 @@ func WithErrorHandler(errorHandler func(err error) error) func(*Engine) {
 	}
 }
 
+// UndeclaredParamPolicy defines what happens when a controller reads a query parameter
+// that is not declared in the OpenAPI spec of the route.
+type UndeclaredParamPolicy = internal.UndeclaredParamPolicy
+
+const (
+	// UndeclaredParamWarn logs a warning. This is the default.
+	UndeclaredParamWarn = internal.UndeclaredParamWarn
+	// UndeclaredParamError responds with a 500 error once the controller returns.
+	UndeclaredParamError = internal.UndeclaredParamError
+	// UndeclaredParamIgnore does nothing.
+	UndeclaredParamIgnore = internal.UndeclaredParamIgnore
+)
+
+// QueryParamUndeclaredError is the error returned when a controller reads a query parameter
+// not declared in the OpenAPI spec, with the [UndeclaredParamError] policy.
+type QueryParamUndeclaredError = internal.QueryParamUndeclaredError
+
+// WithUndeclaredParamPolicy sets what happens when a controller reads a query parameter
+// that is not declared in the OpenAPI spec of the route.
+// Use [UndeclaredParamError] in development and tests to make the spec drift impossible to ignore:
+//
+//	s := fuego.NewServer(
+//		fuego.WithEngineOptions(
+//			fuego.WithUndeclaredParamPolicy(fuego.UndeclaredParamError),
+//		),
+//	)
+func WithUndeclaredParamPolicy(policy UndeclaredParamPolicy) func(*Engine) {
+	return func(e *Engine) { e.UndeclaredParamPolicy = policy }
+}
+
+// WithTransformer registers transformers for the request bodies of type T.
+// They run in order after the body is decoded and after its own [InTransformer] method, before validation.
+// Each transformer receives the context of the request, with its parameters and values like the auth claims,
+// and can modify the body or reject it by returning an error, sent as a 400 Bad Request.
+// Transformers must not call c.Body(), the body being decoded is given as argument.
+//
+//	fuego.WithEngineOptions(
+//		fuego.WithTransformer(func(c fuego.ContextWithBody[Post], post *Post) error {
+//			post.AuthorID = c.Value(userIDKey).(string)
+//			return nil
+//		}),
+//	)
+func WithTransformer[T any](transformers ...func(c ContextWithBody[T], body *T) error) func(*Engine) {
+	return func(e *Engine) {
+		if e.bodyTransformers == nil {
+			e.bodyTransformers = make(map[reflect.Type][]any)
+		}
+		bodyType := reflect.TypeFor[T]()
+		for _, transformer := range transformers {
+			e.bodyTransformers[bodyType] = append(e.bodyTransformers[bodyType], transformer)
+		}
+	}
+}
+
+// BodyTransformers returns the transformers registered with [WithTransformer] for the given body type.
+// It is used by the adaptors to initialize the context.
+func (e *Engine) BodyTransformers(bodyType reflect.Type) []any {
+	return e.bodyTransformers[bodyType]
+}
+
+// WithValidationDeps sets the dependencies given to the ValidateCtx method of the request bodies,
+// like a database connection. See [CtxValidator].
+func WithValidationDeps(deps any) func(*Engine) {
+	return func(e *Engine) { e.ValidationDeps = deps }
+}
+
+// WithValidationErrorFormatter sets how the invalid fields of the validation errors are reported,
+// to control their naming, their message or to add error codes in one place.
+// The formatted items replace the errors of the response, and their reasons its detail.
+//
+//	fuego.WithEngineOptions(
+//		fuego.WithValidationErrorFormatter(func(err validator.FieldError) fuego.ErrorItem {
+//			return fuego.ErrorItem{
+//				Name:   jsonName(err),
+//				Reason: translate(err.Tag(), err.Param()),
+//				More:   map[string]any{"code": "invalid_" + err.Tag()},
+//			}
+//		}),
+//	)
+func WithValidationErrorFormatter(formatter func(validator.FieldError) ErrorItem) func(*Engine) {
+	return func(e *Engine) { e.validationErrorFormatter = formatter }
+}
+
+// WithErrorObfuscation hides the details of the errors from the responses, according to the policy.
+// The hidden errors are sent with their status code and a generated error ID, also logged with the error,
+// so that operators can find the details of the error reported by a client.
+// The [Prod] environment hides the internal errors by default.
+func WithErrorObfuscation(policy ErrorObfuscationPolicy) func(*Engine) {
+	return func(e *Engine) { e.errorObfuscation = policy }
+}
+
+// StatusClientClosedRequest is the status code of the requests aborted by the client, as used by nginx.
+// It is never received by the client: it is only set for the logs and the metrics. See [WithClientDisconnectDetection].
+const StatusClientClosedRequest = 499
+
+// WithClientDisconnectDetection stops the requests whose client disconnected while the controller was running:
+// the response is not serialized, errors are neither handled nor reported, and the status is set to
+// [StatusClientClosedRequest] so that logs and metrics distinguish the cancellations from the timeouts and the server errors.
+func WithClientDisconnectDetection() func(*Engine) {
+	return func(e *Engine) { e.clientDisconnectDetection = true }
+}
+
+// WithValidationWarmup prepares the validation and the decoding of the request bodies when the routes are registered,
+// instead of on the first request of each route. The validator and the decoders parse the tags of each body type once,
+// then reuse them: warming them up removes this cost from the first requests, which matters for high-QPS endpoints
+// receiving traffic as soon as they start. It makes the registration slower, and the invalid validation tags
+// panic at startup instead of on the first request.
+func WithValidationWarmup() func(*Engine) {
+	return func(e *Engine) { e.validationWarmup = true }
+}
+
 // DisableErrorHandler overrides ErrorHandler with a simple pass-through
 func DisableErrorHandler() func(*Engine) {
 	return func(e *Engine) {
@@ -130,12 +321,15 @@ func DisableErrorHandler() func(*Engine) {
 
 func (e *Engine) SpecHandler() func(c ContextNoBody) (openapi3.T, error) {
 	return func(c ContextNoBody) (openapi3.T, error) {
-		return *e.OpenAPI.Description(), nil
+		return e.OpenAPI.snapshot(), nil
 	}
 }
 
 // OutputOpenAPISpec takes the OpenAPI spec and outputs it to a JSON file
 func (e *Engine) OutputOpenAPISpec() *openapi3.T {
+	e.OpenAPI.mu.Lock()
+	defer e.OpenAPI.mu.Unlock()
+
 	e.OpenAPI.computeTags()
 
 	// Validate
@@ -3,6 +3,9 @@ package fuego
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -93,3 +96,75 @@ func TestWithRequestContentType(t *testing.T) {
 		require.False(t, ok)
 	})
 }
+
+func TestWithDescriptionComposer(t *testing.T) {
+	t.Run("custom composition", func(t *testing.T) {
+		s := NewServer(
+			WithEngineOptions(WithDescriptionComposer(func(route *BaseRoute) string {
+				return route.Operation.Description + "\n\nHandled by " + route.FullName
+			})),
+		)
+		route := Get(s, "/pets", testController, OptionDescription("List the pets"))
+		require.Equal(t, "List the pets\n\nHandled by github.com/go-fuego/fuego.testController", route.Operation.Description)
+	})
+
+	t.Run("without controller info", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithoutControllerInfoInDescription()))
+		route := Get(s, "/pets", testController, OptionDescription("List the pets"))
+		require.Equal(t, "List the pets", route.Operation.Description)
+	})
+
+	t.Run("overridden description is kept", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithDescriptionComposer(func(route *BaseRoute) string {
+			return "composed"
+		})))
+		route := Get(s, "/pets", testController, OptionOverrideDescription("Overridden"))
+		require.Equal(t, "Overridden", route.Operation.Description)
+	})
+}
+
+func TestWithValidationWarmup(t *testing.T) {
+	type invalidTags struct {
+		Name string `json:"name" validate:"not_a_validation_function"`
+	}
+
+	t.Run("invalid tags panic at registration", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithValidationWarmup()))
+		require.Panics(t, func() {
+			Post(s, "/pets", func(c ContextWithBody[invalidTags]) (invalidTags, error) {
+				return c.Body()
+			})
+		})
+	})
+
+	t.Run("without warmup, invalid tags are found on the first request", func(t *testing.T) {
+		s := NewServer()
+		require.NotPanics(t, func() {
+			Post(s, "/pets", func(c ContextWithBody[invalidTags]) (invalidTags, error) {
+				return c.Body()
+			})
+		})
+	})
+
+	t.Run("warmed up routes validate the requests", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithValidationWarmup()))
+		Post(s, "/pets", func(c ContextWithBody[benchPet]) (benchPet, error) {
+			return c.Body()
+		}, OptionValidationScenario("create"))
+		Batch(s, "/pets:batchCreate", func(c ContextWithBody[[]benchPet], pet benchPet) (benchPet, error) {
+			return pet, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"id":1}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`id=1&name=Rex`))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,62 @@
+package fuego
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Environment is a set of default behaviors of the server. See [WithEnvironment].
+type Environment int
+
+const (
+	// Dev is the development environment: verbose errors with the stack traces of the panics,
+	// unknown fields rejected, templates reloaded on each request, OpenAPI spec saved locally
+	// and colorized startup messages.
+	Dev Environment = iota + 1
+	// Prod is the production environment: internal error details hidden from the responses (see [WithErrorObfuscation]),
+	// unknown fields accepted, templates loaded once, OpenAPI spec not saved locally,
+	// controllers left out of the OpenAPI descriptions and stricter request header limits (see [WithConnectionLimits]).
+	Prod
+)
+
+// WithEnvironment sets the default behaviors of the server for the given environment.
+// The options given after it override these defaults, for example:
+//
+//	s := fuego.NewServer(
+//		fuego.WithEnvironment(fuego.Prod),
+//		fuego.WithDisallowUnknownFields(true),
+//	)
+func WithEnvironment(environment Environment) func(*Server) {
+	return func(s *Server) {
+		s.environment = environment
+		switch environment {
+		case Dev:
+			s.DisallowUnknownFields = true
+			s.OpenAPIConfig.DisableLocalSave = false
+			s.ErrorHandler = verboseErrorHandler
+			s.errorObfuscation = ErrorObfuscationNone
+			s.reloadTemplates = true
+			s.OpenAPI.composeDescription = ComposeDescription
+		case Prod:
+			s.DisallowUnknownFields = false
+			s.OpenAPIConfig.DisableLocalSave = true
+			s.errorObfuscation = ErrorObfuscationInternal
+			s.reloadTemplates = false
+			WithoutControllerInfoInDescription()(s.Engine)
+			WithConnectionLimits(prodConnectionLimits)(s)
+		}
+	}
+}
+
+// verboseErrorHandler is the error handler of the [Dev] environment.
+// Internal errors are reported with their message.
+func verboseErrorHandler(err error) error {
+	var httpError HTTPError
+	if !errors.As(ErrorHandler(err), &httpError) {
+		return ErrorHandler(InternalServerError{Err: err, Detail: err.Error()})
+	}
+	if httpError.StatusCode() >= http.StatusInternalServerError && httpError.Detail == "" && httpError.Err != nil {
+		httpError.Detail = httpError.Err.Error()
+	}
+	return httpError
+}
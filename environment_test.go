@@ -0,0 +1,110 @@
+package fuego
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEnvironment(t *testing.T) {
+	failing := func(c ContextNoBody) (any, error) {
+		return nil, errors.New("connection refused: db.internal:5432")
+	}
+	failingHTTP := func(c ContextNoBody) (any, error) {
+		return nil, InternalServerError{Detail: "db.internal:5432 unreachable", Err: errors.New("connection refused")}
+	}
+	panicking := func(c ContextNoBody) (any, error) {
+		panic("nil map")
+	}
+
+	t.Run("dev", func(t *testing.T) {
+		s := NewServer(WithEnvironment(Dev))
+		Get(s, "/failing", failing)
+		Get(s, "/panic", panicking)
+
+		require.True(t, s.DisallowUnknownFields)
+		require.False(t, s.OpenAPIConfig.DisableLocalSave)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/failing", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Contains(t, w.Body.String(), "connection refused: db.internal:5432")
+
+		w = httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Contains(t, w.Body.String(), `"detail":"nil map"`)
+		require.Contains(t, w.Body.String(), "environment_test.go")
+	})
+
+	t.Run("prod", func(t *testing.T) {
+		s := NewServer(WithEnvironment(Prod))
+		Get(s, "/failing", failing)
+		Get(s, "/failing-http", failingHTTP)
+		Get(s, "/bad-request", func(c ContextNoBody) (any, error) {
+			return nil, BadRequestError{Detail: "name is required"}
+		})
+
+		require.False(t, s.DisallowUnknownFields)
+		require.True(t, s.OpenAPIConfig.DisableLocalSave)
+
+		for _, path := range []string{"/failing", "/failing-http"} {
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, http.StatusInternalServerError, w.Code)
+			require.Contains(t, w.Body.String(), "Internal Server Error")
+			require.NotContains(t, w.Body.String(), "db.internal")
+		}
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bad-request", nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "name is required")
+	})
+
+	t.Run("controllers are only described in dev", func(t *testing.T) {
+		dev := NewServer(WithEnvironment(Dev))
+		route := Get(dev, "/pets", failing, OptionDescription("List the pets"))
+		require.Contains(t, route.Operation.Description, "#### Controller:")
+
+		prod := NewServer(WithEnvironment(Prod))
+		route = Get(prod, "/pets", failing, OptionDescription("List the pets"))
+		require.Equal(t, "List the pets", route.Operation.Description)
+	})
+
+	t.Run("options given after override the environment", func(t *testing.T) {
+		s := NewServer(WithEnvironment(Prod), WithDisallowUnknownFields(true))
+		require.True(t, s.DisallowUnknownFields)
+	})
+
+	t.Run("templates are reloaded in dev", func(t *testing.T) {
+		templates := fstest.MapFS{"page.html": {Data: []byte(`<h1>{{.}}</h1>`)}}
+		newServer := func(environment Environment) *Server {
+			s := NewServer(
+				WithEnvironment(environment),
+				WithTemplateFS(templates),
+				WithTemplateGlobs("*.html"),
+			)
+			Get(s, "/", func(c ContextNoBody) (CtxRenderer, error) {
+				return c.Render("page.html", "Hello")
+			})
+			return s
+		}
+		dev, prod := newServer(Dev), newServer(Prod)
+
+		templates["page.html"] = &fstest.MapFile{Data: []byte(`<h2>{{.}}</h2>`)}
+
+		w := httptest.NewRecorder()
+		dev.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, "<h2>Hello</h2>", strings.TrimSpace(w.Body.String()))
+
+		w = httptest.NewRecorder()
+		prod.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, "<h1>Hello</h1>", strings.TrimSpace(w.Body.String()))
+	})
+}
@@ -0,0 +1,92 @@
+package fuego
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// ErrorReporter receives the internal errors (5xx) and the panics of the controllers,
+// for example to send them to an error tracking service. See [WithErrorReporter].
+type ErrorReporter interface {
+	ReportError(ctx context.Context, report ErrorReport)
+}
+
+// ErrorReport is an error reported to the [ErrorReporter], with the metadata of the request.
+type ErrorReport struct {
+	// Error returned by the controller, or built from the value of the panic.
+	Err error
+	// Status code of the response. 500 for panics.
+	Status int
+	// Value of the panic and its stack trace, if the error comes from a panic.
+	Panic any
+	Stack []byte
+
+	Request *http.Request
+	// Route of the request, like "GET /pets/{id}".
+	Route string
+	// ID of the request, from the X-Request-ID header.
+	RequestID string
+	// Subject of the authentication token, if any.
+	Principal string
+	// ID of the error sent to the client, if its details are hidden with [WithErrorObfuscation].
+	ErrorID string
+}
+
+// WithErrorReporter reports the internal errors (5xx) and the panics of all the controllers
+// to the reporter. Panics are reported then propagated.
+//
+//	fuego.WithEngineOptions(
+//		fuego.WithErrorReporter(fuegosentry.NewReporter()),
+//	)
+func WithErrorReporter(reporter ErrorReporter) func(*Engine) {
+	return func(e *Engine) { e.errorReporter = reporter }
+}
+
+// requestResponder gives access to the request and the response of a controller context.
+type requestResponder interface {
+	Request() *http.Request
+	Response() http.ResponseWriter
+}
+
+// reportError sends the error to the [ErrorReporter], if any.
+func (e *Engine) reportError(c requestResponder, report ErrorReport) {
+	if e.errorReporter == nil {
+		return
+	}
+
+	r := c.Request()
+	report.Request = r
+	report.Route = r.Pattern
+	if report.Route == "" {
+		report.Route = r.Method + " " + r.URL.Path
+	}
+	report.RequestID = r.Header.Get("X-Request-ID")
+	if report.RequestID == "" {
+		report.RequestID = c.Response().Header().Get("X-Request-ID")
+	}
+	if claims, err := TokenFromContext(r.Context()); err == nil {
+		report.Principal, _ = claims.GetSubject()
+	}
+
+	e.errorReporter.ReportError(r.Context(), report)
+}
+
+// reportPanic reports the panic of the controller, if any, and propagates it.
+// It must be deferred.
+func (e *Engine) reportPanic(c requestResponder) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if recovered != http.ErrAbortHandler {
+		e.reportError(c, ErrorReport{
+			Err:    fmt.Errorf("panic: %v", recovered),
+			Status: http.StatusInternalServerError,
+			Panic:  recovered,
+			Stack:  debug.Stack(),
+		})
+	}
+	panic(recovered)
+}
@@ -0,0 +1,84 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingReporter struct {
+	reports []ErrorReport
+}
+
+func (r *recordingReporter) ReportError(_ context.Context, report ErrorReport) {
+	r.reports = append(r.reports, report)
+}
+
+func TestWithErrorReporter(t *testing.T) {
+	reporter := &recordingReporter{}
+	s := NewServer(
+		WithEngineOptions(
+			WithErrorReporter(reporter),
+			WithErrorObfuscation(ErrorObfuscationInternal),
+		),
+	)
+	Get(s, "/pets/{id}", func(c ContextNoBody) (any, error) {
+		return nil, errors.New("connection refused")
+	}, OptionMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithValue(r.Context(), jwt.MapClaims{"sub": "user-42"})))
+		})
+	}))
+	Get(s, "/bad-request", func(c ContextNoBody) (any, error) {
+		return nil, BadRequestError{Detail: "name is required"}
+	})
+	Get(s, "/panic", func(c ContextNoBody) (any, error) {
+		panic("nil map")
+	})
+
+	t.Run("internal errors are reported with the request metadata", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/pets/1", nil)
+		r.Header.Set("X-Request-ID", "req-1")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Len(t, reporter.reports, 1)
+		report := reporter.reports[0]
+		require.ErrorContains(t, report.Err, "connection refused")
+		require.Equal(t, http.StatusInternalServerError, report.Status)
+		require.Equal(t, "GET /pets/{id}", report.Route)
+		require.Equal(t, "req-1", report.RequestID)
+		require.Equal(t, "user-42", report.Principal)
+		require.NotEmpty(t, report.ErrorID)
+		require.Contains(t, w.Body.String(), report.ErrorID)
+		require.Nil(t, report.Panic)
+	})
+
+	t.Run("client errors are not reported", func(t *testing.T) {
+		reporter.reports = nil
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bad-request", nil))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Empty(t, reporter.reports)
+	})
+
+	t.Run("panics are reported and propagated", func(t *testing.T) {
+		reporter.reports = nil
+		require.PanicsWithValue(t, "nil map", func() {
+			s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+		})
+
+		require.Len(t, reporter.reports, 1)
+		report := reporter.reports[0]
+		require.Equal(t, "nil map", report.Panic)
+		require.Contains(t, string(report.Stack), "error_reporter_test.go")
+		require.Equal(t, "GET /panic", report.Route)
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+
+	"github.com/google/uuid"
 )
 
 // ErrorWithStatus is an interface that can be implemented by an error to provide
@@ -43,6 +45,14 @@ type ErrorItem struct {
 	Reason string         `json:"reason" xml:"reason" description:"Human readable error message"`
 }
 
+// Error makes ErrorItem usable as an error, for example to report the invalid fields of a [CtxValidator].
+func (e ErrorItem) Error() string {
+	if e.Name == "" {
+		return e.Reason
+	}
+	return e.Name + ": " + e.Reason
+}
+
 func (e HTTPError) Error() string {
 	code := e.StatusCode()
 	title := e.Title
@@ -75,12 +85,24 @@ func (e HTTPError) DetailMsg() string {
 
 func (e HTTPError) Unwrap() error { return e.Err }
 
+// errorMessage is the message of the errors with a status, like [BadRequestError]:
+// the message of the underlying error, or the status and the detail without one.
+func errorMessage(e HTTPError, status int) string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Status == 0 {
+		e.Status = status
+	}
+	return e.Error()
+}
+
 // BadRequestError is an error used to return a 400 status code.
 type BadRequestError HTTPError
 
 var _ ErrorWithStatus = BadRequestError{}
 
-func (e BadRequestError) Error() string { return e.Err.Error() }
+func (e BadRequestError) Error() string { return errorMessage(HTTPError(e), http.StatusBadRequest) }
 
 func (e BadRequestError) StatusCode() int { return http.StatusBadRequest }
 
@@ -91,7 +113,7 @@ type NotFoundError HTTPError
 
 var _ ErrorWithStatus = NotFoundError{}
 
-func (e NotFoundError) Error() string { return e.Err.Error() }
+func (e NotFoundError) Error() string { return errorMessage(HTTPError(e), http.StatusNotFound) }
 
 func (e NotFoundError) StatusCode() int { return http.StatusNotFound }
 
@@ -102,7 +124,7 @@ type UnauthorizedError HTTPError
 
 var _ ErrorWithStatus = UnauthorizedError{}
 
-func (e UnauthorizedError) Error() string { return e.Err.Error() }
+func (e UnauthorizedError) Error() string { return errorMessage(HTTPError(e), http.StatusUnauthorized) }
 
 func (e UnauthorizedError) StatusCode() int { return http.StatusUnauthorized }
 
@@ -113,7 +135,7 @@ type ForbiddenError HTTPError
 
 var _ ErrorWithStatus = ForbiddenError{}
 
-func (e ForbiddenError) Error() string { return e.Err.Error() }
+func (e ForbiddenError) Error() string { return errorMessage(HTTPError(e), http.StatusForbidden) }
 
 func (e ForbiddenError) StatusCode() int { return http.StatusForbidden }
 
@@ -124,7 +146,7 @@ type ConflictError HTTPError
 
 var _ ErrorWithStatus = ConflictError{}
 
-func (e ConflictError) Error() string { return e.Err.Error() }
+func (e ConflictError) Error() string { return errorMessage(HTTPError(e), http.StatusConflict) }
 
 func (e ConflictError) StatusCode() int { return http.StatusConflict }
 
@@ -138,21 +160,27 @@ type NotAcceptableError HTTPError
 
 var _ ErrorWithStatus = NotAcceptableError{}
 
-func (e NotAcceptableError) Error() string { return e.Err.Error() }
+func (e NotAcceptableError) Error() string {
+	return errorMessage(HTTPError(e), http.StatusNotAcceptable)
+}
 
 func (e NotAcceptableError) StatusCode() int { return http.StatusNotAcceptable }
 
 func (e NotAcceptableError) Unwrap() error { return HTTPError(e) }
 
 // ErrorHandler is the default error handler used by the framework.
-// If the error is an [HTTPError] that error is returned.
+// If the error is a [ProblemDetail] or an [HTTPError] that error is returned.
 // If the error adheres to the [ErrorWithStatus] and/or [ErrorWithDetail] interface
 // the error is transformed to a [HTTPError].
 // If the error is not an [HTTPError] nor does it adhere to an
 // interface the error is returned as is.
 func ErrorHandler(err error) error {
 	var errorStatus ErrorWithStatus
+	var problem ProblemDetail
 	switch {
+	case errors.As(err, &problem):
+		slog.Error("Error "+problem.Title, "status", problem.StatusCode(), "detail", problem.DetailMsg(), "error", problem.Err)
+		return problem
 	case errors.As(err, &HTTPError{}),
 		errors.As(err, &errorStatus):
 		return handleHTTPError(err)
@@ -161,6 +189,53 @@ func ErrorHandler(err error) error {
 	return err
 }
 
+// ErrorObfuscationPolicy defines which errors have their details hidden from the responses.
+// See [WithErrorObfuscation].
+type ErrorObfuscationPolicy int
+
+const (
+	// ErrorObfuscationNone sends the errors with their details. This is the default.
+	ErrorObfuscationNone ErrorObfuscationPolicy = iota
+	// ErrorObfuscationInternal hides the details of the 5xx errors.
+	ErrorObfuscationInternal
+	// ErrorObfuscationAll hides the details of all the errors, including the validation errors.
+	ErrorObfuscationAll
+)
+
+// handleError applies the error handler of the engine, reports the internal errors
+// to the [ErrorReporter], then hides the error details if needed.
+func (e *Engine) handleError(c requestResponder, err error) error {
+	err = e.ErrorHandler(e.queryTimeoutError(err))
+
+	status := http.StatusInternalServerError
+	var errorStatus ErrorWithStatus
+	if errors.As(err, &errorStatus) {
+		status = errorStatus.StatusCode()
+	}
+	hidden := e.errorObfuscation == ErrorObfuscationAll ||
+		e.errorObfuscation == ErrorObfuscationInternal && status >= http.StatusInternalServerError
+
+	var errorID string
+	if hidden {
+		errorID = uuid.New().String()
+		slog.Error("Error hidden from the response", "errorId", errorID, "status", status, "error", err)
+	}
+	if status >= http.StatusInternalServerError {
+		e.reportError(c, ErrorReport{Err: err, Status: status, ErrorID: errorID})
+	}
+	if !hidden {
+		return err
+	}
+
+	return HTTPError{
+		Err:      err,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   "Error ID: " + errorID,
+		Instance: errorID,
+	}
+}
+
 func handleHTTPError(err error) HTTPError {
 	errResponse := HTTPError{
 		Err: err,
@@ -1,11 +1,15 @@
 package fuego
 
 import (
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/thejerf/slogassert"
 )
 
 type myError struct {
@@ -133,6 +137,13 @@ func TestHTTPError_Error(t *testing.T) {
 			require.ErrorContains(t, err, "Internal Server Error")
 		})
 	})
+
+	t.Run("errors with a status", func(t *testing.T) {
+		require.EqualError(t, BadRequestError{Err: errors.New("invalid id")}, "invalid id")
+		require.EqualError(t, BadRequestError{Detail: "name is required"}, "400 Bad Request: name is required")
+		require.EqualError(t, NotFoundError{}, "404 Not Found")
+		require.EqualError(t, ConflictError{Title: "Duplicate", Detail: "name is taken"}, "409 Duplicate: name is taken")
+	})
 }
 
 func TestHTTPError_Unwrap(t *testing.T) {
@@ -146,3 +157,67 @@ func TestHTTPError_Unwrap(t *testing.T) {
 	require.ErrorAs(t, errResponse.Unwrap(), &unwrapped)
 	require.Equal(t, 999, unwrapped.status)
 }
+
+func TestWithErrorObfuscation(t *testing.T) {
+	handler := slogassert.New(t, slog.LevelError, nil)
+	newServer := func(policy ErrorObfuscationPolicy) *Server {
+		s := NewServer(
+			WithLogHandler(handler),
+			WithEngineOptions(WithErrorObfuscation(policy)),
+		)
+		Get(s, "/internal", func(c ContextNoBody) (any, error) {
+			return nil, errors.New("connection refused: db.internal:5432")
+		})
+		Get(s, "/bad-request", func(c ContextNoBody) (any, error) {
+			return nil, BadRequestError{Detail: "name is required"}
+		})
+		return s
+	}
+	get := func(s *Server, path string) (*httptest.ResponseRecorder, HTTPError) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body HTTPError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w, body
+	}
+
+	t.Run("internal errors are hidden", func(t *testing.T) {
+		s := newServer(ErrorObfuscationInternal)
+
+		w, body := get(s, "/internal")
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotContains(t, w.Body.String(), "db.internal")
+		require.Equal(t, "Internal Server Error", body.Title)
+		require.NotEmpty(t, body.Instance)
+		require.Equal(t, "Error ID: "+body.Instance, body.Detail)
+		handler.AssertPrecise(slogassert.LogMessageMatch{
+			Message: "Error hidden from the response",
+			Level:   slog.LevelError,
+			Attrs:   map[string]any{"errorId": body.Instance},
+		})
+
+		w, body = get(s, "/bad-request")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "name is required", body.Detail)
+		handler.Reset()
+	})
+
+	t.Run("all errors are hidden", func(t *testing.T) {
+		s := newServer(ErrorObfuscationAll)
+
+		w, body := get(s, "/bad-request")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.NotContains(t, w.Body.String(), "name is required")
+		require.NotEmpty(t, body.Instance)
+		handler.Reset()
+	})
+
+	t.Run("errors are not hidden by default", func(t *testing.T) {
+		s := newServer(ErrorObfuscationNone)
+
+		_, body := get(s, "/bad-request")
+		require.Equal(t, "name is required", body.Detail)
+		require.Empty(t, body.Instance)
+		handler.Reset()
+	})
+}
@@ -29,4 +29,28 @@ func TestFuegoGin(t *testing.T) {
 		require.Equal(t, http.StatusOK, w.Code)
 		require.JSONEq(t, `{"message":"Hello"}`, w.Body.String())
 	})
+
+	t.Run("test fuego path parameters", func(t *testing.T) {
+		r := httptest.NewRequest("PUT", "/my-group/42/fuego/Ewen", nil)
+		w := httptest.NewRecorder()
+
+		e.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"message":"Hello Ewen from 42"}`, w.Body.String())
+	})
+}
+
+func TestFuegoGinOpenAPI(t *testing.T) {
+	_, openapi := server()
+	spec := openapi.Description()
+
+	group := spec.Paths.Find("/my-group/{id}/fuego")
+	require.NotNil(t, group)
+	require.NotNil(t, group.Get.Parameters.GetByInAndName("path", "id"))
+
+	put := spec.Paths.Find("/my-group/{id}/fuego/{name}")
+	require.NotNil(t, put)
+	require.NotNil(t, put.Put.Parameters.GetByInAndName("path", "id"))
+	require.NotNil(t, put.Put.Parameters.GetByInAndName("path", "name"))
 }
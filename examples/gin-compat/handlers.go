@@ -18,6 +18,12 @@ func fuegoControllerGet(c fuego.ContextNoBody) (HelloResponse, error) {
 	}, nil
 }
 
+func fuegoControllerPut(c fuego.ContextNoBody) (HelloResponse, error) {
+	return HelloResponse{
+		Message: fmt.Sprintf("Hello %s from %s", c.PathParam("name"), c.PathParam("id")),
+	}, nil
+}
+
 func fuegoControllerPost(c fuego.ContextWithBody[HelloRequest]) (*HelloResponse, error) {
 	body, err := c.Body()
 	if err != nil {
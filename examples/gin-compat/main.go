@@ -80,6 +80,12 @@ func server() (*gin.Engine, *fuego.OpenAPI) {
 		option.Tags("Fuego"),
 	)
 
+	// Path parameters can also be declared the Fuego way
+	fuegogin.Put(engine, group, "/fuego/{name}", fuegoControllerPut,
+		option.Summary("Route with Fuego path parameters"),
+		option.Tags("Fuego"),
+	)
+
 	engine.RegisterOpenAPIRoutes(&fuegogin.OpenAPIHandler{GinEngine: ginRouter})
 
 	// Serve the OpenAPI spec
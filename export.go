@@ -0,0 +1,592 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrExportNotFound is returned by an [ExportStore] when an export does not exist.
+var ErrExportNotFound = errors.New("export not found")
+
+// ExportFormat is the file format of an export.
+type ExportFormat string
+
+const (
+	// ExportCSV exports the items as comma-separated values, with a header row.
+	// The text cells starting like a formula are escaped, see [ExportXLSX].
+	ExportCSV ExportFormat = "csv"
+	// ExportXLSX exports the items as an Excel spreadsheet, with a header row. See [XLSX] for the headers.
+	// The text cells starting with "=", "+", "-", "@", a tab or a carriage return are prefixed with "'",
+	// so that the spreadsheets do not evaluate them as formulas.
+	ExportXLSX ExportFormat = "xlsx"
+	// ExportJSONL exports the items as JSON Lines: one JSON object per line.
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+var exportContentTypes = map[ExportFormat]string{
+	ExportCSV:   "text/csv",
+	ExportXLSX:  xlsxContentType,
+	ExportJSONL: "application/jsonl",
+}
+
+// ExportStatus is the status of an [ExportJob].
+type ExportStatus string
+
+const (
+	// ExportPending means the export is waiting to be generated.
+	ExportPending ExportStatus = "pending"
+	// ExportRunning means the export is being generated.
+	ExportRunning ExportStatus = "running"
+	// ExportSucceeded means the file is ready to be downloaded.
+	ExportSucceeded ExportStatus = "succeeded"
+	// ExportFailed means the generation failed. See [ExportJob.Error].
+	ExportFailed ExportStatus = "failed"
+)
+
+// ExportJob is an export generated in the background by an [Export] route.
+type ExportJob struct {
+	ID     string       `json:"id"`
+	Format ExportFormat `json:"format"`
+	Status ExportStatus `json:"status"`
+	// Number of exported items, once succeeded.
+	Rows        int        `json:"rows"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	// Signed URL of the file, set on the responses once succeeded. It expires at ExpiresAt.
+	DownloadURL string     `json:"downloadUrl,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ExportStore stores the exports of [Export] routes and their files.
+// [NewInMemoryExportStore] is provided for tests and single-instance deployments.
+type ExportStore interface {
+	// SaveExport creates or updates an export.
+	SaveExport(ctx context.Context, export ExportJob) error
+	// Export returns an export by ID, or [ErrExportNotFound].
+	Export(ctx context.Context, id string) (ExportJob, error)
+	// SaveExportFile stores the generated file of an export.
+	SaveExportFile(ctx context.Context, id string, content []byte) error
+	// ExportFile returns the generated file of an export, or [ErrExportNotFound].
+	ExportFile(ctx context.Context, id string) (io.ReadSeeker, error)
+}
+
+// InMemoryExportStore is an [ExportStore] keeping everything in memory.
+// The finished exports and their files are evicted after the retention period.
+type InMemoryExportStore struct {
+	// How long the finished exports are kept after their completion. Defaults to 24 hours.
+	Retention time.Duration
+
+	// Clock of the evictions. Set to the clock of the server by [Export], see [WithClock].
+	now func() time.Time
+
+	mu      sync.RWMutex
+	exports map[string]ExportJob
+	files   map[string][]byte
+}
+
+var _ ExportStore = &InMemoryExportStore{}
+
+// NewInMemoryExportStore creates an empty [InMemoryExportStore].
+func NewInMemoryExportStore() *InMemoryExportStore {
+	return &InMemoryExportStore{
+		Retention: 24 * time.Hour,
+		now:       time.Now,
+		exports:   map[string]ExportJob{},
+		files:     map[string][]byte{},
+	}
+}
+
+// expired tells if the export is finished for longer than the retention period.
+func (s *InMemoryExportStore) expired(export ExportJob, now time.Time) bool {
+	return export.CompletedAt != nil && now.Sub(*export.CompletedAt) > s.Retention
+}
+
+func (s *InMemoryExportStore) SaveExport(_ context.Context, export ExportJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Forget the exports finished for longer than the retention period
+	now := s.now()
+	for id, saved := range s.exports {
+		if s.expired(saved, now) {
+			delete(s.exports, id)
+			delete(s.files, id)
+		}
+	}
+	s.exports[export.ID] = export
+	return nil
+}
+
+func (s *InMemoryExportStore) Export(_ context.Context, id string) (ExportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	export, ok := s.exports[id]
+	if !ok || s.expired(export, s.now()) {
+		return ExportJob{}, ErrExportNotFound
+	}
+	return export, nil
+}
+
+func (s *InMemoryExportStore) SaveExportFile(_ context.Context, id string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[id] = content
+	return nil
+}
+
+func (s *InMemoryExportStore) ExportFile(_ context.Context, id string) (io.ReadSeeker, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	content, ok := s.files[id]
+	if !ok || s.expired(s.exports[id], s.now()) {
+		return nil, ErrExportNotFound
+	}
+	return bytes.NewReader(content), nil
+}
+
+// ExportConfig configures an [Export] route.
+type ExportConfig struct {
+	// Store of the exports and their files. Defaults to an empty [InMemoryExportStore].
+	Store ExportStore
+	// Formats offered to the clients. The first one is the default. Defaults to CSV, XLSX and JSONL.
+	Formats []ExportFormat
+	// Secret signing the download URLs. Defaults to a random secret:
+	// the URLs are then invalid after a restart, or on the other instances of the server.
+	Secret []byte
+	// Lifetime of the download URLs. Defaults to 15 minutes.
+	URLTTL time.Duration
+	// Name of the downloaded files, without extension. Defaults to "export".
+	FileName string
+}
+
+// Export turns a list controller into an asynchronous export, with three routes:
+//
+//   - POST path: starts generating the export in the background, in the format of the format query parameter.
+//     It responds with a 202 Accepted status, the [ExportJob] and its URL in the Location header.
+//   - GET path/{id}: returns the [ExportJob], with a signed download URL once it succeeded.
+//   - GET path/{id}/download: downloads the file. Requires the expires and signature query parameters of the signed URL.
+//
+// The list controller is called in the background with the query parameters and the context values
+// of the POST request, but not its cancellation. The options are applied to the first two routes,
+// typically to declare the query parameters of the list controller and to protect the routes:
+// the download route is only protected by the signature of its URL.
+// The exports in progress are cancelled when the server shuts down, and the new ones are rejected
+// with a 503 Service Unavailable error.
+//
+//	fuego.Export(s, "/orders/exports", listOrders, fuego.ExportConfig{FileName: "orders"},
+//		option.Query("status", "Status of the exported orders"),
+//		option.Middleware(authMiddleware),
+//	)
+func Export[T any](s *Server, path string, list func(ContextNoBody) ([]T, error), config ExportConfig, options ...func(*BaseRoute)) {
+	e := newExporter(s, list, config)
+
+	formats := make([]any, len(e.config.Formats))
+	contentTypes := make([]string, len(e.config.Formats))
+	for i, format := range e.config.Formats {
+		formats[i] = string(format)
+		contentTypes[i] = exportContentTypes[format]
+	}
+	notFound := OptionAddResponse(http.StatusNotFound, "Export not found", Response{Type: HTTPError{}})
+
+	e.route = registerFuegoController(s, http.MethodPost, path, e.start, slices.Concat([]func(*BaseRoute){
+		OptionSummary("Start an export"),
+		OptionDefaultStatusCode(http.StatusAccepted),
+		OptionQuery("format", "Format of the exported file", ParamDefault(formats[0]), ParamEnum(formats...)),
+		OptionResponseHeader("Location", "URL of the export status", ParamStatusCodes(http.StatusAccepted)),
+		OptionAddResponse(http.StatusServiceUnavailable, "Server shutting down", Response{Type: HTTPError{}}),
+		func(r *BaseRoute) { r.FullName = FuncName(list) },
+	}, options)...)
+	registerFuegoController(s, http.MethodGet, path+"/{id}", e.status, slices.Concat([]func(*BaseRoute){
+		OptionSummary("Get an export"),
+		notFound,
+	}, options)...)
+	registerFuegoController(s, http.MethodGet, path+"/{id}/download", e.download,
+		OptionSummary("Download an export"),
+		OptionQuery("expires", "Expiration time of the download URL, in seconds since the epoch", ParamRequired()),
+		OptionQuery("signature", "Signature of the download URL", ParamRequired()),
+		OptionResponseContentType(contentTypes...),
+		OptionAddResponse(http.StatusForbidden, "Invalid or expired signature", Response{Type: HTTPError{}}),
+		notFound,
+	)
+}
+
+// exporter serves the routes of an [Export].
+type exporter[T any] struct {
+	s      *Server
+	list   func(ContextNoBody) ([]T, error)
+	config ExportConfig
+	route  *Route[ExportJob, any]
+
+	// Serializes the starts and the shutdown, so that no export starts once the server shuts down
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newExporter[T any](s *Server, list func(ContextNoBody) ([]T, error), config ExportConfig) *exporter[T] {
+	if config.Store == nil {
+		config.Store = NewInMemoryExportStore()
+	}
+	if store, ok := config.Store.(*InMemoryExportStore); ok {
+		store.now = s.now
+	}
+	if len(config.Formats) == 0 {
+		config.Formats = []ExportFormat{ExportCSV, ExportXLSX, ExportJSONL}
+	}
+	for _, format := range config.Formats {
+		if _, ok := exportContentTypes[format]; !ok {
+			panic(fmt.Sprintf("unknown export format %q", format))
+		}
+	}
+	if len(config.Secret) == 0 {
+		config.Secret = make([]byte, 32)
+		_, _ = rand.Read(config.Secret)
+	}
+	if config.URLTTL <= 0 {
+		config.URLTTL = 15 * time.Minute
+	}
+	if config.FileName == "" {
+		config.FileName = "export"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &exporter[T]{s: s, list: list, config: config, ctx: ctx, cancel: cancel}
+	s.OnShutdown(func() {
+		e.mu.Lock()
+		e.cancel()
+		e.mu.Unlock()
+		e.wg.Wait()
+	})
+	return e
+}
+
+func (e *exporter[T]) start(c ContextNoBody) (ExportJob, error) {
+	format := ExportFormat(c.QueryParam("format"))
+	if format == "" {
+		format = e.config.Formats[0]
+	}
+	if !slices.Contains(e.config.Formats, format) {
+		return ExportJob{}, BadRequestError{Detail: fmt.Sprintf("unsupported export format %q", format)}
+	}
+
+	if !e.reserve() {
+		return ExportJob{}, HTTPError{
+			Title:  "Service Unavailable",
+			Status: http.StatusServiceUnavailable,
+			Detail: "the server is shutting down",
+		}
+	}
+
+	export := ExportJob{ID: newWebhookID(), Format: format, Status: ExportPending, CreatedAt: e.s.now()}
+	if err := e.config.Store.SaveExport(c.Context(), export); err != nil {
+		e.wg.Done()
+		return ExportJob{}, err
+	}
+
+	// The list controller runs after the response, with the values of the request context
+	request := c.Request().Clone(context.WithoutCancel(c.Context()))
+	go func() {
+		defer e.wg.Done()
+		e.generate(request, export)
+	}()
+
+	c.SetHeader("Location", e.route.Path+"/"+export.ID)
+	return export, nil
+}
+
+// reserve counts a new export in the wait group of the shutdown, unless the server is shutting down.
+func (e *exporter[T]) reserve() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ctx.Err() != nil {
+		return false
+	}
+	e.wg.Add(1)
+	return true
+}
+
+// generate calls the list controller and stores the file of the export.
+func (e *exporter[T]) generate(r *http.Request, export ExportJob) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	stop := context.AfterFunc(e.ctx, cancel)
+	defer stop()
+	r = r.WithContext(ctx)
+
+	export.Status = ExportRunning
+	err := e.config.Store.SaveExport(ctx, export)
+	if err == nil {
+		export.Rows, err = e.write(r, export)
+	}
+
+	completedAt := e.s.now()
+	export.CompletedAt = &completedAt
+	export.Status = ExportSucceeded
+	if err != nil {
+		export.Status = ExportFailed
+		export.Error = err.Error()
+		slog.Error("export failed", "id", export.ID, "error", err)
+	}
+	// Saved even if the export was cancelled, so that it does not stay running
+	if err := e.config.Store.SaveExport(context.WithoutCancel(ctx), export); err != nil {
+		slog.Error("cannot save export", "id", export.ID, "error", err)
+	}
+}
+
+// write generates and stores the file, and returns the number of items.
+func (e *exporter[T]) write(r *http.Request, export ExportJob) (int, error) {
+	c := NewNetHTTPContext[any](e.route.BaseRoute, &discardResponseWriter{header: http.Header{}}, r, readOptions{})
+	items, err := e.list(c)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.Context().Err(); err != nil {
+		return 0, err
+	}
+
+	var file bytes.Buffer
+	switch export.Format {
+	case ExportJSONL:
+		err = writeJSONL(&file, items)
+	case ExportCSV:
+		err = writeCSV(&file, items)
+	case ExportXLSX:
+		err = writeXLSX(&file, reflect.ValueOf(items))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(items), e.config.Store.SaveExportFile(r.Context(), export.ID, file.Bytes())
+}
+
+func (e *exporter[T]) status(c ContextNoBody) (ExportJob, error) {
+	export, err := e.config.Store.Export(c.Context(), c.PathParam("id"))
+	if err != nil {
+		return ExportJob{}, exportError(err)
+	}
+	if export.Status == ExportSucceeded {
+		expiresAt := e.s.now().Add(e.config.URLTTL).Truncate(time.Second)
+		export.ExpiresAt = &expiresAt
+		export.DownloadURL = e.route.Path + "/" + export.ID + "/download?" + url.Values{
+			"expires":   {strconv.FormatInt(expiresAt.Unix(), 10)},
+			"signature": {e.sign(export.ID, expiresAt.Unix())},
+		}.Encode()
+	}
+	return export, nil
+}
+
+func (e *exporter[T]) download(c ContextNoBody) (SeekableResponse, error) {
+	id := c.PathParam("id")
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	signature := c.QueryParam("signature")
+	if err != nil || !hmac.Equal([]byte(signature), []byte(e.sign(id, expires))) {
+		return SeekableResponse{}, ForbiddenError{Detail: "invalid download signature"}
+	}
+	if e.s.now().Unix() > expires {
+		return SeekableResponse{}, ForbiddenError{Detail: "expired download URL"}
+	}
+
+	export, err := e.config.Store.Export(c.Context(), id)
+	if err != nil {
+		return SeekableResponse{}, exportError(err)
+	}
+	if export.Status != ExportSucceeded {
+		return SeekableResponse{}, NotFoundError{Detail: "the export is not ready"}
+	}
+	content, err := e.config.Store.ExportFile(c.Context(), id)
+	if err != nil {
+		return SeekableResponse{}, exportError(err)
+	}
+
+	name := e.config.FileName + "." + string(export.Format)
+	c.SetHeader("Content-Disposition", `attachment; filename="`+name+`"`)
+	modTime := export.CreatedAt
+	if export.CompletedAt != nil {
+		modTime = *export.CompletedAt
+	}
+	return SeekableResponse{
+		Content:     content,
+		ContentType: exportContentTypes[export.Format],
+		Name:        name,
+		ModTime:     modTime,
+		ETag:        export.ID,
+	}, nil
+}
+
+// sign returns the signature of the download URL of the export.
+func (e *exporter[T]) sign(id string, expires int64) string {
+	return base64.RawURLEncoding.EncodeToString(computeHMACSHA256(e.config.Secret, []byte(id+"."+strconv.FormatInt(expires, 10))))
+}
+
+func exportError(err error) error {
+	if errors.Is(err, ErrExportNotFound) {
+		return NotFoundError{Err: err, Detail: err.Error()}
+	}
+	return err
+}
+
+// discardResponseWriter is the response writer of the list controllers called in the background.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func writeJSONL[T any](w io.Writer, items []T) error {
+	encoder := json.NewEncoder(w)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportCell is a cell of the CSV and XLSX exports.
+type exportCell struct {
+	value  string
+	number bool
+}
+
+// exportColumn is a column of the CSV and XLSX exports: a JSON attribute of the items, and its header.
+type exportColumn struct {
+	attribute string
+	header    string
+}
+
+// exportRows converts the items to a header row and a row per item. The columns are the JSON attributes
+// of the items, in the order of the fields of the items. Objects and arrays are written as JSON.
+// If headerTag is set, the struct tag of that name overrides the headers, and "-" skips the field.
+func exportRows(items reflect.Value, headerTag string) ([]string, [][]exportCell, error) {
+	columns := exportColumns(items.Type().Elem(), headerTag)
+	scalar := columns == nil
+	if scalar {
+		columns = []exportColumn{{attribute: "value", header: "value"}}
+	}
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.header
+	}
+
+	rows := make([][]exportCell, items.Len())
+	for i := range rows {
+		item := items.Index(i).Interface()
+		b, err := json.Marshal(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		object, ok := value.(map[string]any)
+		if scalar {
+			object, ok = map[string]any{"value": value}, true
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("cannot export %T as rows", item)
+		}
+
+		rows[i] = make([]exportCell, len(columns))
+		for j, column := range columns {
+			rows[i][j] = exportCellOf(object[column.attribute])
+		}
+	}
+	return headers, rows, nil
+}
+
+// exportColumns returns the columns of the fields of the struct type, or nil for the other types.
+func exportColumns(t reflect.Type, headerTag string) []exportColumn {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	columns := []exportColumn{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-":
+			continue
+		case field.Anonymous && name == "":
+			columns = append(columns, exportColumns(field.Type, headerTag)...)
+			continue
+		case !field.IsExported():
+			continue
+		case name == "":
+			name = field.Name
+		}
+
+		column := exportColumn{attribute: name, header: name}
+		if header := field.Tag.Get(headerTag); headerTag != "" && header != "" {
+			if header == "-" {
+				continue
+			}
+			column.header = header
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+func exportCellOf(value any) exportCell {
+	switch value := value.(type) {
+	case nil:
+		return exportCell{}
+	case string:
+		// Not evaluated as a formula by the spreadsheets (CSV injection)
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			value = "'" + value
+		}
+		return exportCell{value: value}
+	case json.Number:
+		return exportCell{value: value.String(), number: true}
+	case bool:
+		return exportCell{value: strconv.FormatBool(value)}
+	}
+	b, _ := json.Marshal(value)
+	return exportCell{value: string(b)}
+}
+
+func writeCSV[T any](w io.Writer, items []T) error {
+	headers, rows, err := exportRows(reflect.ValueOf(items), "")
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	_ = writer.Write(headers)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = cell.value
+		}
+		_ = writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
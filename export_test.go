@@ -0,0 +1,315 @@
+package fuego
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type exportedOrder struct {
+	ID     int      `json:"id"`
+	Status string   `json:"status"`
+	Tags   []string `json:"tags"`
+	Paid   bool     `json:"paid"`
+	secret string
+}
+
+func waitForExportStatus(t *testing.T, s *Server, location string, status ExportStatus) ExportJob {
+	t.Helper()
+	var export ExportJob
+	require.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, location, nil))
+		export = ExportJob{}
+		return json.Unmarshal(w.Body.Bytes(), &export) == nil && export.Status == status
+	}, 2*time.Second, 5*time.Millisecond)
+	return export
+}
+
+func startExport(t *testing.T, s *Server, target string) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, nil))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var export ExportJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	require.Equal(t, ExportPending, export.Status)
+	require.Equal(t, "/orders/exports/"+export.ID, w.Header().Get("Location"))
+	return w.Header().Get("Location")
+}
+
+func download(s *Server, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestExport(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	s := NewServer(WithoutLogger(), WithClock(clock))
+
+	type contextKey struct{}
+	listOrders := func(c ContextNoBody) ([]exportedOrder, error) {
+		if c.Context().Value(contextKey{}) != "alice" {
+			return nil, errors.New("missing context value")
+		}
+		orders := []exportedOrder{
+			{ID: 1, Status: "paid", Tags: []string{"gift"}, Paid: true},
+			{ID: 2, Status: `pending, "soon"`},
+			{ID: 3, Status: "paid", Paid: true},
+		}
+		if status := c.QueryParam("status"); status != "" {
+			var filtered []exportedOrder
+			for _, order := range orders {
+				if order.Status == status {
+					filtered = append(filtered, order)
+				}
+			}
+			orders = filtered
+		}
+		return orders, nil
+	}
+	Export(s, "/orders/exports", listOrders, ExportConfig{FileName: "orders", Secret: []byte("secret")},
+		OptionQuery("status", "Status of the orders"),
+		OptionMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, "alice")))
+			})
+		}),
+	)
+
+	t.Run("csv", func(t *testing.T) {
+		location := startExport(t, s, "/orders/exports")
+		export := waitForExportStatus(t, s, location, ExportSucceeded)
+		require.Equal(t, ExportCSV, export.Format)
+		require.Equal(t, 3, export.Rows)
+		require.Equal(t, now.Add(15*time.Minute), *export.ExpiresAt)
+		require.True(t, strings.HasPrefix(export.DownloadURL, location+"/download?expires="))
+
+		w := download(s, export.DownloadURL)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		require.Equal(t, `attachment; filename="orders.csv"`, w.Header().Get("Content-Disposition"))
+		require.Equal(t, "id,status,tags,paid\n"+
+			"1,paid,\"[\"\"gift\"\"]\",true\n"+
+			"2,\"pending, \"\"soon\"\"\",,false\n"+
+			"3,paid,,true\n", w.Body.String())
+	})
+
+	t.Run("jsonl with the query parameters of the list controller", func(t *testing.T) {
+		location := startExport(t, s, "/orders/exports?format=jsonl&status=paid")
+		export := waitForExportStatus(t, s, location, ExportSucceeded)
+		require.Equal(t, 2, export.Rows)
+
+		w := download(s, export.DownloadURL)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/jsonl", w.Header().Get("Content-Type"))
+		require.Equal(t, `{"id":1,"status":"paid","tags":["gift"],"paid":true}`+"\n"+
+			`{"id":3,"status":"paid","tags":null,"paid":true}`+"\n", w.Body.String())
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		location := startExport(t, s, "/orders/exports?format=xlsx")
+		export := waitForExportStatus(t, s, location, ExportSucceeded)
+
+		w := download(s, export.DownloadURL)
+		require.Equal(t, http.StatusOK, w.Code)
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		require.NoError(t, err)
+		var sheet string
+		for _, file := range archive.File {
+			if file.Name == "xl/worksheets/sheet1.xml" {
+				f, err := file.Open()
+				require.NoError(t, err)
+				content, err := io.ReadAll(f)
+				require.NoError(t, err)
+				sheet = string(content)
+			}
+		}
+		require.Len(t, archive.File, 5)
+		require.Contains(t, sheet, `<row r="1"><c t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`)
+		require.Contains(t, sheet, `<row r="3"><c><v>2</v></c><c t="inlineStr"><is><t xml:space="preserve">pending, &#34;soon&#34;</t></is></c>`)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders/exports?format=pdf", nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid and expired signatures", func(t *testing.T) {
+		location := startExport(t, s, "/orders/exports")
+		export := waitForExportStatus(t, s, location, ExportSucceeded)
+		downloadURL, err := url.Parse(export.DownloadURL)
+		require.NoError(t, err)
+
+		query := downloadURL.Query()
+		query.Set("signature", "forged")
+		require.Equal(t, http.StatusForbidden, download(s, location+"/download?"+query.Encode()).Code)
+
+		query = downloadURL.Query()
+		query.Set("expires", "9999999999")
+		require.Equal(t, http.StatusForbidden, download(s, location+"/download?"+query.Encode()).Code)
+
+		require.Equal(t, http.StatusBadRequest, download(s, location+"/download").Code)
+
+		mu.Lock()
+		now = now.Add(16 * time.Minute)
+		mu.Unlock()
+		require.Equal(t, http.StatusForbidden, download(s, export.DownloadURL).Code)
+	})
+
+	t.Run("unknown export", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, download(s, "/orders/exports/unknown").Code)
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		spec := s.OutputOpenAPISpec()
+		start := spec.Paths.Find("/orders/exports").Post
+		require.NotNil(t, start.Parameters.GetByInAndName("query", "format"))
+		require.NotNil(t, start.Parameters.GetByInAndName("query", "status"))
+		require.NotNil(t, start.Responses.Status(http.StatusAccepted))
+
+		status := spec.Paths.Find("/orders/exports/{id}").Get
+		require.NotNil(t, status.Parameters.GetByInAndName("query", "status"))
+		require.NotNil(t, status.Responses.Status(http.StatusNotFound))
+
+		downloadOperation := spec.Paths.Find("/orders/exports/{id}/download").Get
+		require.NotNil(t, downloadOperation.Parameters.GetByInAndName("query", "signature"))
+		require.Nil(t, downloadOperation.Parameters.GetByInAndName("query", "status"))
+		content := downloadOperation.Responses.Status(http.StatusOK).Value.Content
+		require.NotNil(t, content.Get("text/csv"))
+		require.NotNil(t, content.Get("application/jsonl"))
+	})
+}
+
+func TestExportFailure(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Export(s, "/orders/exports", func(c ContextNoBody) ([]exportedOrder, error) {
+		return nil, errors.New("database unavailable")
+	}, ExportConfig{Formats: []ExportFormat{ExportJSONL}})
+
+	location := startExport(t, s, "/orders/exports")
+	export := waitForExportStatus(t, s, location, ExportFailed)
+	require.Equal(t, ExportJSONL, export.Format)
+	require.Equal(t, "database unavailable", export.Error)
+	require.Empty(t, export.DownloadURL)
+}
+
+func TestExportCancelledOnShutdown(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	started := make(chan struct{})
+	Export(s, "/orders/exports", func(c ContextNoBody) ([]exportedOrder, error) {
+		close(started)
+		<-c.Context().Done()
+		return nil, c.Context().Err()
+	}, ExportConfig{})
+
+	location := startExport(t, s, "/orders/exports")
+	<-started
+	s.runShutdownHooks()
+
+	export := waitForExportStatus(t, s, location, ExportFailed)
+	require.Equal(t, context.Canceled.Error(), export.Error)
+
+	t.Run("no export starts after the shutdown", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders/exports", nil))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestExportRetention(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+	s := NewServer(WithoutLogger(), WithClock(clock))
+	store := NewInMemoryExportStore()
+	store.Retention = time.Hour
+	Export(s, "/orders/exports", func(c ContextNoBody) ([]exportedOrder, error) {
+		return []exportedOrder{{ID: 1}}, nil
+	}, ExportConfig{Store: store})
+
+	old := waitForExportStatus(t, s, startExport(t, s, "/orders/exports"), ExportSucceeded)
+	advance(30 * time.Minute)
+	_, err := store.Export(context.Background(), old.ID)
+	require.NoError(t, err, "kept during the retention period")
+
+	advance(31 * time.Minute)
+	_, err = store.Export(context.Background(), old.ID)
+	require.ErrorIs(t, err, ErrExportNotFound)
+	_, err = store.ExportFile(context.Background(), old.ID)
+	require.ErrorIs(t, err, ErrExportNotFound)
+
+	recent := waitForExportStatus(t, s, startExport(t, s, "/orders/exports"), ExportSucceeded)
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	require.NotContains(t, store.exports, old.ID, "evicted when saving another export")
+	require.NotContains(t, store.files, old.ID)
+	require.Contains(t, store.exports, recent.ID)
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	require.PanicsWithValue(t, `unknown export format "pdf"`, func() {
+		Export(s, "/exports", func(c ContextNoBody) ([]int, error) { return nil, nil }, ExportConfig{Formats: []ExportFormat{"pdf"}})
+	})
+}
+
+func TestExportRows(t *testing.T) {
+	t.Run("scalars", func(t *testing.T) {
+		columns, rows, err := exportRows(reflect.ValueOf([]int{1, 2}), "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"value"}, columns)
+		require.Equal(t, [][]exportCell{{{value: "1", number: true}}, {{value: "2", number: true}}}, rows)
+	})
+
+	t.Run("formulas are escaped", func(t *testing.T) {
+		_, rows, err := exportRows(reflect.ValueOf([]any{"=HYPERLINK(\"https://evil.example.com\")", "+1", "-1", "@SUM(A1)", "\t=1", "a=b", -1, ""}), "")
+		require.NoError(t, err)
+		require.Equal(t, [][]exportCell{
+			{{value: `'=HYPERLINK("https://evil.example.com")`}},
+			{{value: "'+1"}},
+			{{value: "'-1"}},
+			{{value: "'@SUM(A1)"}},
+			{{value: "'\t=1"}},
+			{{value: "a=b"}},
+			{{value: "-1", number: true}},
+			{{value: ""}},
+		}, rows)
+	})
+
+	t.Run("unexported fields are ignored", func(t *testing.T) {
+		columns, _, err := exportRows(reflect.ValueOf([]exportedOrder{{secret: "x"}}), "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"id", "status", "tags", "paid"}, columns)
+	})
+}
@@ -0,0 +1,152 @@
+// Package admin generates an HTML administration interface for the resources of a Fuego server,
+// like the Django admin: a list, a detail page and forms for each resource.
+//
+//	admin.New(admin.Config{
+//		Title: "Petstore admin",
+//		Resources: []admin.Resource{
+//			admin.For[Pet]("pets", petStore),
+//			admin.For[Owner]("owners", ownerStore),
+//		},
+//	}).Register(s, "/admin", option.Middleware(fuego.AuthWall("admin")))
+//
+// The pages are generated from the OpenAPI schemas of the resources: the types of the inputs
+// come from the types and formats of the properties, the selects from their enums,
+// and the required and read-only properties are enforced by the forms.
+package admin
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-fuego/fuego"
+)
+
+// ErrNotFound is returned by the [Store] for unknown items.
+var ErrNotFound = errors.New("item not found")
+
+// Store stores the items of a resource, for example in a database table.
+type Store[T any] interface {
+	// List returns the page of items starting at the offset, and the total number of items.
+	List(ctx context.Context, offset, limit int) (items []T, total int, err error)
+	// Get returns the item, or [ErrNotFound].
+	Get(ctx context.Context, id string) (T, error)
+	// Create creates the item and returns it with its ID.
+	Create(ctx context.Context, item T) (T, error)
+	// Update replaces the item, or returns [ErrNotFound].
+	Update(ctx context.Context, id string, item T) (T, error)
+	// Delete deletes the item, or returns [ErrNotFound].
+	Delete(ctx context.Context, id string) error
+}
+
+// Config configures an [Admin].
+type Config struct {
+	// Title of the pages. Defaults to "Admin".
+	Title string
+	// PageSize is the number of items per page of the lists. Defaults to 20.
+	PageSize int
+	// Resources managed by the admin, created with [For].
+	Resources []Resource
+}
+
+// Admin serves the administration pages. Create it with [New] and register its routes with [Admin.Register].
+type Admin struct {
+	config Config
+}
+
+// New returns an [Admin].
+func New(config Config) *Admin {
+	if config.Title == "" {
+		config.Title = "Admin"
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 20
+	}
+	return &Admin{config: config}
+}
+
+// Register registers the pages of the admin on the server, under the path:
+//   - GET path lists the resources
+//   - GET path/{resource} lists the items of a resource, with the page query parameter
+//   - GET path/{resource}/new and POST path/{resource} create an item
+//   - GET path/{resource}/{id} shows an item
+//   - GET path/{resource}/{id}/edit and POST path/{resource}/{id} update an item
+//   - POST path/{resource}/{id}/delete deletes an item
+//
+// The options are applied to all the routes: protect them with an authentication middleware,
+// like option.Middleware(fuego.AuthWall("admin")). The routes are hidden from the OpenAPI spec,
+// unless option.Show() is given.
+func (a *Admin) Register(s *fuego.Server, path string, options ...func(*fuego.BaseRoute)) {
+	path = strings.TrimSuffix(path, "/")
+	options = append([]func(*fuego.BaseRoute){fuego.OptionHide(), fuego.OptionTags("Admin")}, options...)
+
+	for _, resource := range a.config.Resources {
+		resource.init(s.OpenAPI)
+	}
+
+	fuego.GetStd(s, path, func(w http.ResponseWriter, r *http.Request) {
+		a.render(w, r, http.StatusOK, "index", page{Base: path})
+	}, append(options, fuego.OptionSummary("Admin index"))...)
+	for _, resource := range a.config.Resources {
+		resource.register(s, a, path, options)
+	}
+}
+
+// page is the data of the templates.
+type page struct {
+	Title    string
+	Base     string
+	Names    []string
+	Resource string
+	Fields   []field
+	// Items of the list, as displayed values by field.
+	Items []item
+	// Item shown or edited.
+	Item   item
+	Errors map[string]string
+	// Pagination of the list. Previous and Next are 0 on the first and the last pages.
+	Page, Pages, Total, Previous, Next int
+	// Message of the error page.
+	Message string
+}
+
+// item is an item of a resource, with its values formatted for the pages.
+type item struct {
+	ID     string
+	Values map[string]string
+}
+
+func (a *Admin) render(w http.ResponseWriter, r *http.Request, status int, name string, data page) {
+	data.Title = a.config.Title
+	for _, resource := range a.config.Resources {
+		data.Names = append(data.Names, resource.name())
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		slog.ErrorContext(r.Context(), "cannot render admin page", "page", name, "error", err)
+	}
+}
+
+func (a *Admin) renderError(w http.ResponseWriter, r *http.Request, base string, status int, message string) {
+	a.render(w, r, status, "error", page{Base: base, Message: message})
+}
+
+// sameOrigin reports whether the form was submitted from the same origin,
+// to reject the cross-site requests forged with the cookies of an administrator.
+func sameOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin" || site == "none"
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+	return true
+}
+
+var templates = template.Must(template.New("admin").Parse(layoutTemplates))
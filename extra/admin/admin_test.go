@@ -0,0 +1,285 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+type Pet struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name" validate:"required,max=20" description:"Name of the pet"`
+	Species    string    `json:"species" validate:"oneof=cat dog"`
+	Age        int       `json:"age" validate:"min=0"`
+	Vaccinated bool      `json:"vaccinated"`
+	BirthDate  time.Time `json:"birth_date"`
+	Tags       []string  `json:"tags"`
+}
+
+type petStore struct {
+	mu     sync.Mutex
+	pets   []Pet
+	nextID int
+}
+
+func (s *petStore) List(_ context.Context, offset, limit int) ([]Pet, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := min(offset, len(s.pets))
+	end := min(offset+limit, len(s.pets))
+	return slices.Clone(s.pets[start:end]), len(s.pets), nil
+}
+
+func (s *petStore) Get(_ context.Context, id string) (Pet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pet := range s.pets {
+		if strconv.Itoa(pet.ID) == id {
+			return pet, nil
+		}
+	}
+	return Pet{}, ErrNotFound
+}
+
+func (s *petStore) Create(_ context.Context, pet Pet) (Pet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	pet.ID = s.nextID
+	s.pets = append(s.pets, pet)
+	return pet, nil
+}
+
+func (s *petStore) Update(_ context.Context, id string, pet Pet) (Pet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.pets {
+		if strconv.Itoa(s.pets[i].ID) == id {
+			pet.ID = s.pets[i].ID
+			s.pets[i] = pet
+			return pet, nil
+		}
+	}
+	return Pet{}, ErrNotFound
+}
+
+func (s *petStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.pets {
+		if strconv.Itoa(s.pets[i].ID) == id {
+			s.pets = slices.Delete(s.pets, i, i+1)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func newTestServer(t *testing.T, options ...func(*fuego.BaseRoute)) (*fuego.Server, *petStore) {
+	t.Helper()
+	store := &petStore{}
+	s := fuego.NewServer(fuego.WithoutLogger())
+	New(Config{
+		Title:     "Petstore admin",
+		PageSize:  2,
+		Resources: []Resource{For[Pet]("pets", store)},
+	}).Register(s, "/admin", options...)
+	return s, store
+}
+
+func request(s *fuego.Server, method, path string, form url.Values) *httptest.ResponseRecorder {
+	var r *http.Request
+	if form != nil {
+		r = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		r = httptest.NewRequest(method, path, nil)
+	}
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestAdmin(t *testing.T) {
+	s, store := newTestServer(t)
+
+	t.Run("index", func(t *testing.T) {
+		w := request(s, http.MethodGet, "/admin", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), "<title>Petstore admin</title>")
+		require.Contains(t, w.Body.String(), `<a href="/admin/pets">pets</a>`)
+	})
+
+	t.Run("new form", func(t *testing.T) {
+		w := request(s, http.MethodGet, "/admin/pets/new", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		require.Contains(t, body, `<input type="text" id="name" name="name" value="" required>`)
+		require.Contains(t, body, `<div class="help">Name of the pet</div>`)
+		require.Contains(t, body, `<input type="number" id="age" name="age" value="" step="1">`)
+		require.Contains(t, body, `<input type="checkbox" id="vaccinated" name="vaccinated">`)
+		require.Contains(t, body, `<input type="datetime-local" id="birth_date" name="birth_date" value="">`)
+		require.NotContains(t, body, `name="id"`)
+		require.NotContains(t, body, `name="tags"`)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		w := request(s, http.MethodPost, "/admin/pets", url.Values{
+			"name":       {"Felix"},
+			"species":    {"cat"},
+			"age":        {"3"},
+			"vaccinated": {"on"},
+			"birth_date": {"2022-03-04T05:06"},
+		})
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/admin/pets/1", w.Header().Get("Location"))
+		require.Equal(t, []Pet{{
+			ID: 1, Name: "Felix", Species: "cat", Age: 3, Vaccinated: true,
+			BirthDate: time.Date(2022, 3, 4, 5, 6, 0, 0, time.UTC),
+		}}, store.pets)
+	})
+
+	t.Run("invalid form", func(t *testing.T) {
+		w := request(s, http.MethodPost, "/admin/pets", url.Values{
+			"name":    {"Rex"},
+			"species": {"fish"},
+			"age":     {"three"},
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `<div class="error">must be an integer</div>`)
+		require.Contains(t, w.Body.String(), `value="Rex"`)
+
+		w = request(s, http.MethodPost, "/admin/pets", url.Values{"name": {"Rex"}, "species": {"fish"}})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `<div class="error">invalid value (oneof)</div>`)
+		require.Len(t, store.pets, 1)
+	})
+
+	t.Run("detail", func(t *testing.T) {
+		w := request(s, http.MethodGet, "/admin/pets/1", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "<tr><th>name</th><td>Felix</td></tr>")
+		require.Contains(t, w.Body.String(), `<form method="post" action="/admin/pets/1/delete">`)
+
+		w = request(s, http.MethodGet, "/admin/pets/42", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Contains(t, w.Body.String(), "No such pets item")
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		w := request(s, http.MethodGet, "/admin/pets/1/edit", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		require.Contains(t, body, `<form method="post" action="/admin/pets/1">`)
+		require.Contains(t, body, `value="Felix"`)
+		require.Contains(t, body, `value="2022-03-04T05:06"`)
+		require.Contains(t, body, `<input type="checkbox" id="vaccinated" name="vaccinated" checked>`)
+
+		store.pets[0].Tags = []string{"indoor"}
+		w = request(s, http.MethodPost, "/admin/pets/1", url.Values{
+			"name":       {"Felix the cat"},
+			"species":    {"cat"},
+			"age":        {"4"},
+			"birth_date": {"2022-03-04T05:06"},
+		})
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/admin/pets/1", w.Header().Get("Location"))
+		require.Equal(t, "Felix the cat", store.pets[0].Name)
+		require.False(t, store.pets[0].Vaccinated)
+		require.Equal(t, []string{"indoor"}, store.pets[0].Tags, "the fields without input are kept")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		for _, name := range []string{"Rex", "Tom"} {
+			w := request(s, http.MethodPost, "/admin/pets", url.Values{"name": {name}, "species": {"dog"}})
+			require.Equal(t, http.StatusSeeOther, w.Code)
+		}
+
+		w := request(s, http.MethodGet, "/admin/pets", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		require.Contains(t, body, "3 items")
+		require.Contains(t, body, `<a href="/admin/pets/1">1</a>`)
+		require.Contains(t, body, "<td>Felix the cat</td>")
+		require.Contains(t, body, "<td>Rex</td>")
+		require.NotContains(t, body, "<td>Tom</td>")
+		require.Contains(t, body, `<a href="?page=2">Next</a>`)
+
+		w = request(s, http.MethodGet, "/admin/pets?page=2", nil)
+		require.Contains(t, w.Body.String(), "<td>Tom</td>")
+		require.Contains(t, w.Body.String(), `<a href="?page=1">Previous</a>`)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := request(s, http.MethodPost, "/admin/pets/2/delete", url.Values{})
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/admin/pets", w.Header().Get("Location"))
+		require.Len(t, store.pets, 2)
+	})
+
+	t.Run("cross-site forms are rejected", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/admin/pets/1/delete", nil)
+		r.Header.Set("Sec-Fetch-Site", "cross-site")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		r = httptest.NewRequest(http.MethodPost, "/admin/pets", strings.NewReader("name=Evil&species=cat"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("Origin", "https://evil.example")
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Len(t, store.pets, 2)
+	})
+
+	t.Run("hidden from the OpenAPI spec", func(t *testing.T) {
+		spec := s.OutputOpenAPISpec()
+		require.Nil(t, spec.Paths.Find("/admin/pets"))
+	})
+}
+
+func TestAdminProtected(t *testing.T) {
+	s, _ := newTestServer(t, fuego.OptionMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer admin" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}))
+
+	for _, path := range []string{"/admin", "/admin/pets", "/admin/pets/new"} {
+		w := request(s, http.MethodGet, path, nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/pets", nil)
+	r.Header.Set("Authorization", "Bearer admin")
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestForWithoutID(t *testing.T) {
+	type Note struct {
+		Text string `json:"text"`
+	}
+	s := fuego.NewServer(fuego.WithoutLogger())
+	require.PanicsWithValue(t, "admin: the resource notes has no id attribute", func() {
+		New(Config{Resources: []Resource{For[Note]("notes", nil)}}).Register(s, "/admin")
+	})
+}
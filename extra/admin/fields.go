@@ -0,0 +1,210 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Layout of the values of the datetime-local inputs.
+const datetimeLocal = "2006-01-02T15:04"
+
+// field is an attribute of a resource, described by its OpenAPI schema.
+type field struct {
+	// Name of the attribute in JSON.
+	Name        string
+	Description string
+	// Input is the type of the HTML input: text, email, number, checkbox, select or datetime-local.
+	// Empty for the objects and the arrays, which are displayed as JSON but not editable.
+	Input    string
+	Step     string
+	Enum     []string
+	Required bool
+	ReadOnly bool
+	Nullable bool
+	// ID reports whether the field identifies the items.
+	ID bool
+}
+
+// Editable reports whether the field is in the forms.
+func (f field) Editable() bool {
+	return f.Input != "" && !f.ReadOnly
+}
+
+// fieldsOf returns the fields of the schema, in the order of the fields of the struct type.
+func fieldsOf(t reflect.Type, schema *openapi3.Schema) []field {
+	var fields []field
+	for _, name := range jsonNames(t) {
+		property, ok := schema.Properties[name]
+		if !ok || property.Value == nil {
+			continue
+		}
+		fields = append(fields, newField(name, property.Value, slices.Contains(schema.Required, name)))
+	}
+	return fields
+}
+
+func newField(name string, schema *openapi3.Schema, required bool) field {
+	f := field{
+		Name:        name,
+		Description: schema.Description,
+		Required:    required,
+		ReadOnly:    schema.ReadOnly,
+		Nullable:    schema.Nullable,
+	}
+	switch {
+	case schema.Type.Is(openapi3.TypeString) && len(schema.Enum) > 0:
+		f.Input = "select"
+		for _, value := range schema.Enum {
+			f.Enum = append(f.Enum, fmt.Sprint(value))
+		}
+	case schema.Type.Is(openapi3.TypeString) && schema.Format == "date-time":
+		f.Input = "datetime-local"
+	case schema.Type.Is(openapi3.TypeString) && schema.Format == "email":
+		f.Input = "email"
+	case schema.Type.Is(openapi3.TypeString):
+		f.Input = "text"
+	case schema.Type.Is(openapi3.TypeInteger):
+		f.Input, f.Step = "number", "1"
+	case schema.Type.Is(openapi3.TypeNumber):
+		f.Input, f.Step = "number", "any"
+	case schema.Type.Is(openapi3.TypeBoolean):
+		f.Input = "checkbox"
+	}
+	return f
+}
+
+// jsonNames returns the JSON names of the fields of the struct type, including the embedded ones.
+func jsonNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if structField.Anonymous && name == "" {
+			names = append(names, jsonNames(structField.Type)...)
+			continue
+		}
+		if !structField.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// toValues converts the item to its JSON attributes. The numbers are kept as [json.Number].
+func toValues(item any) (map[string]any, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	values := map[string]any{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("cannot convert %T to an object: %w", item, err)
+	}
+	return values, nil
+}
+
+// lookup returns the attribute of the values, matching its name case-insensitively.
+func lookup(values map[string]any, name string) (any, bool) {
+	if v, ok := values[name]; ok {
+		return v, true
+	}
+	for key, v := range values {
+		if strings.EqualFold(key, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// display formats the value for the pages.
+func (f field) display(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number, bool:
+		return fmt.Sprint(value)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// inputValue formats the value for the input of the forms.
+func (f field) inputValue(value any) string {
+	if f.Input == "datetime-local" {
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t.UTC().Format(datetimeLocal)
+			}
+		}
+	}
+	return f.display(value)
+}
+
+// parse converts the value submitted for the field to its JSON value.
+func (f field) parse(form url.Values) (any, error) {
+	value := strings.TrimSpace(form.Get(f.Name))
+	if f.Input == "checkbox" {
+		return value != "", nil
+	}
+	if value == "" {
+		if f.Required {
+			return nil, fmt.Errorf("required")
+		}
+		if f.Input == "text" || f.Input == "email" {
+			return "", nil
+		}
+		return nil, nil
+	}
+
+	switch f.Input {
+	case "select":
+		if !slices.Contains(f.Enum, value) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(f.Enum, ", "))
+		}
+	case "number":
+		if f.Step == "1" {
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, fmt.Errorf("must be an integer")
+			}
+		} else if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(value), nil
+	case "datetime-local":
+		t, err := time.Parse(datetimeLocal, value)
+		if err != nil {
+			t, err = time.Parse(datetimeLocal+":05", value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("must be a date and time")
+		}
+		return t.Format(time.RFC3339), nil
+	}
+	return value, nil
+}
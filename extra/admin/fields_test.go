@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldsOf(t *testing.T) {
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type Order struct {
+		Base
+		Status  string   `json:"status"`
+		Email   string   `json:"email,omitempty"`
+		Total   float64  `json:"total"`
+		Items   []string `json:"items"`
+		Ignored string   `json:"-"`
+	}
+
+	schema := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewStringSchema()).
+		WithProperty("status", openapi3.NewStringSchema().WithEnum("pending", "paid")).
+		WithProperty("email", openapi3.NewStringSchema().WithFormat("email")).
+		WithProperty("total", openapi3.NewFloat64Schema()).
+		WithProperty("items", openapi3.NewArraySchema())
+	schema.Required = []string{"status"}
+
+	fields := fieldsOf(reflect.TypeFor[Order](), schema)
+	require.Equal(t, []field{
+		{Name: "id", Input: "text"},
+		{Name: "status", Input: "select", Enum: []string{"pending", "paid"}, Required: true},
+		{Name: "email", Input: "email"},
+		{Name: "total", Input: "number", Step: "any"},
+		{Name: "items"},
+	}, fields)
+	require.False(t, fields[4].Editable())
+}
+
+func TestFieldParse(t *testing.T) {
+	tests := []struct {
+		field    field
+		value    string
+		expected any
+		err      string
+	}{
+		{field: field{Name: "f", Input: "text"}, value: " hello ", expected: "hello"},
+		{field: field{Name: "f", Input: "text"}, value: "", expected: ""},
+		{field: field{Name: "f", Input: "text", Required: true}, value: "", err: "required"},
+		{field: field{Name: "f", Input: "checkbox"}, value: "on", expected: true},
+		{field: field{Name: "f", Input: "checkbox"}, value: "", expected: false},
+		{field: field{Name: "f", Input: "number", Step: "1"}, value: "42", expected: json.Number("42")},
+		{field: field{Name: "f", Input: "number", Step: "1"}, value: "4.2", err: "must be an integer"},
+		{field: field{Name: "f", Input: "number", Step: "any"}, value: "4.2", expected: json.Number("4.2")},
+		{field: field{Name: "f", Input: "number", Step: "any"}, value: "", expected: nil},
+		{field: field{Name: "f", Input: "select", Enum: []string{"a", "b"}}, value: "b", expected: "b"},
+		{field: field{Name: "f", Input: "select", Enum: []string{"a", "b"}}, value: "c", err: "must be one of a, b"},
+		{field: field{Name: "f", Input: "datetime-local"}, value: "2025-01-02T03:04", expected: "2025-01-02T03:04:00Z"},
+		{field: field{Name: "f", Input: "datetime-local"}, value: "2025-01-02T03:04:05", expected: "2025-01-02T03:04:05Z"},
+		{field: field{Name: "f", Input: "datetime-local"}, value: "tomorrow", err: "must be a date and time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field.Input+" "+tt.value, func(t *testing.T) {
+			value, err := tt.field.parse(url.Values{"f": {tt.value}})
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestFieldInputValue(t *testing.T) {
+	date := field{Input: "datetime-local"}
+	require.Equal(t, "2025-01-02T02:04", date.inputValue("2025-01-02T03:04:05+01:00"))
+	require.Equal(t, "42", field{Input: "number"}.inputValue(json.Number("42")))
+	require.Equal(t, `["a","b"]`, field{}.inputValue([]any{"a", "b"}))
+	require.Equal(t, "", field{Input: "text"}.inputValue(nil))
+}
@@ -0,0 +1,308 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Resource is a resource managed by the admin, created with [For].
+type Resource interface {
+	name() string
+	init(openapi *fuego.OpenAPI)
+	register(s *fuego.Server, a *Admin, path string, options []func(*fuego.BaseRoute))
+}
+
+// For manages the items of type T, stored by the store, under the given name in the URLs.
+// T must have an "id" attribute in JSON, which identifies the items in the URLs.
+func For[T any](name string, store Store[T]) Resource {
+	return &resource[T]{resourceName: name, store: store}
+}
+
+type resource[T any] struct {
+	resourceName string
+	store        Store[T]
+	fields       []field
+	validator    *validator.Validate
+}
+
+func (res *resource[T]) name() string { return res.resourceName }
+
+// init reads the fields from the OpenAPI schema of T.
+func (res *resource[T]) init(openapi *fuego.OpenAPI) {
+	tag := fuego.SchemaTagFromType(openapi, *new(T))
+	if tag.Value == nil {
+		panic("admin: no schema for the resource " + res.resourceName)
+	}
+	res.fields = fieldsOf(reflect.TypeFor[T](), tag.Value)
+	hasID := false
+	for i, f := range res.fields {
+		if strings.EqualFold(f.Name, "id") {
+			res.fields[i].ReadOnly = true
+			res.fields[i].ID = true
+			hasID = true
+		}
+	}
+	if !hasID {
+		panic("admin: the resource " + res.resourceName + " has no id attribute")
+	}
+
+	res.validator = validator.New()
+	res.validator.RegisterTagNameFunc(func(structField reflect.StructField) string {
+		name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "" {
+			return structField.Name
+		}
+		return name
+	})
+}
+
+func (res *resource[T]) register(s *fuego.Server, a *Admin, path string, options []func(*fuego.BaseRoute)) {
+	h := handlers[T]{resource: res, admin: a, base: path, path: path + "/" + res.resourceName}
+	summary := func(s string) func(*fuego.BaseRoute) { return fuego.OptionSummary(s + " " + res.resourceName) }
+
+	fuego.GetStd(s, h.path, h.list, append(options, summary("List"))...)
+	fuego.GetStd(s, h.path+"/new", h.newForm, append(options, summary("New form of"))...)
+	fuego.PostStd(s, h.path, h.create, append(options, summary("Create"))...)
+	fuego.GetStd(s, h.path+"/{id}", h.show, append(options, summary("Show"))...)
+	fuego.GetStd(s, h.path+"/{id}/edit", h.editForm, append(options, summary("Edit form of"))...)
+	fuego.PostStd(s, h.path+"/{id}", h.update, append(options, summary("Update"))...)
+	fuego.PostStd(s, h.path+"/{id}/delete", h.delete, append(options, summary("Delete"))...)
+}
+
+// handlers serves the pages of a resource.
+type handlers[T any] struct {
+	resource *resource[T]
+	admin    *Admin
+	// base is the path of the admin, path the path of the resource.
+	base, path string
+}
+
+func (h handlers[T]) page() page {
+	return page{Base: h.base, Resource: h.resource.resourceName, Fields: h.resource.fields}
+}
+
+func (h handlers[T]) list(w http.ResponseWriter, r *http.Request) {
+	pageSize := h.admin.config.PageSize
+	number, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || number < 1 {
+		number = 1
+	}
+
+	items, total, err := h.resource.store.List(r.Context(), (number-1)*pageSize, pageSize)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	data := h.page()
+	data.Page, data.Total = number, total
+	data.Pages = max((total+pageSize-1)/pageSize, 1)
+	if number > 1 {
+		data.Previous = number - 1
+	}
+	if number < data.Pages {
+		data.Next = number + 1
+	}
+	for _, v := range items {
+		displayed, err := h.display(v)
+		if err != nil {
+			h.fail(w, r, err)
+			return
+		}
+		data.Items = append(data.Items, displayed)
+	}
+	h.admin.render(w, r, http.StatusOK, "list", data)
+}
+
+func (h handlers[T]) show(w http.ResponseWriter, r *http.Request) {
+	v, err := h.resource.store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	data := h.page()
+	data.Item, err = h.display(v)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.admin.render(w, r, http.StatusOK, "detail", data)
+}
+
+func (h handlers[T]) newForm(w http.ResponseWriter, r *http.Request) {
+	data := h.page()
+	data.Item = item{Values: map[string]string{}}
+	h.admin.render(w, r, http.StatusOK, "form", data)
+}
+
+func (h handlers[T]) editForm(w http.ResponseWriter, r *http.Request) {
+	v, err := h.resource.store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	values, err := toValues(v)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	data := h.page()
+	data.Item = item{ID: r.PathValue("id"), Values: map[string]string{}}
+	for _, f := range h.resource.fields {
+		value, _ := lookup(values, f.Name)
+		data.Item.Values[f.Name] = f.inputValue(value)
+	}
+	h.admin.render(w, r, http.StatusOK, "form", data)
+}
+
+func (h handlers[T]) create(w http.ResponseWriter, r *http.Request) {
+	h.save(w, r, "", *new(T))
+}
+
+func (h handlers[T]) update(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	existing, err := h.resource.store.Get(r.Context(), id)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.save(w, r, id, existing)
+}
+
+// save updates the editable fields of the item with the submitted form, validates it, and stores it.
+// The form is displayed again with the errors if the values are invalid.
+func (h handlers[T]) save(w http.ResponseWriter, r *http.Request, id string, existing T) {
+	if !sameOrigin(r) {
+		h.admin.renderError(w, r, h.base, http.StatusForbidden, "Cross-site form submission")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		h.admin.renderError(w, r, h.base, http.StatusBadRequest, "Invalid form")
+		return
+	}
+
+	values, err := toValues(existing)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	errs := map[string]string{}
+	for _, f := range h.resource.fields {
+		if !f.Editable() {
+			continue
+		}
+		value, err := f.parse(r.PostForm)
+		if err != nil {
+			errs[f.Name] = err.Error()
+			continue
+		}
+		if value == nil && !f.Nullable {
+			delete(values, f.Name)
+			continue
+		}
+		values[f.Name] = value
+	}
+
+	var v T
+	if len(errs) == 0 {
+		errs = h.decode(values, &v)
+	}
+	if len(errs) > 0 {
+		data := h.page()
+		data.Item = item{ID: id, Values: map[string]string{}}
+		for _, f := range h.resource.fields {
+			data.Item.Values[f.Name] = r.PostForm.Get(f.Name)
+		}
+		data.Errors = errs
+		h.admin.render(w, r, http.StatusBadRequest, "form", data)
+		return
+	}
+
+	if id == "" {
+		v, err = h.resource.store.Create(r.Context(), v)
+	} else {
+		v, err = h.resource.store.Update(r.Context(), id, v)
+	}
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	saved, err := h.display(v)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	http.Redirect(w, r, h.path+"/"+saved.ID, http.StatusSeeOther)
+}
+
+// decode decodes the attributes into the item, and validates it with its validate tags.
+// It returns the errors by field.
+func (h handlers[T]) decode(values map[string]any, v *T) map[string]string {
+	b, err := json.Marshal(values)
+	if err == nil {
+		err = json.Unmarshal(b, v)
+	}
+	if err != nil {
+		return map[string]string{"": err.Error()}
+	}
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if err := h.resource.validator.Struct(v); !errors.As(err, &validationErrors) {
+		return nil
+	}
+	errs := map[string]string{}
+	for _, fieldError := range validationErrors {
+		errs[fieldError.Field()] = "invalid value (" + fieldError.Tag() + ")"
+	}
+	return errs
+}
+
+func (h handlers[T]) delete(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		h.admin.renderError(w, r, h.base, http.StatusForbidden, "Cross-site form submission")
+		return
+	}
+	if err := h.resource.store.Delete(r.Context(), r.PathValue("id")); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	http.Redirect(w, r, h.path, http.StatusSeeOther)
+}
+
+// display returns the displayed values of the item.
+func (h handlers[T]) display(v T) (item, error) {
+	values, err := toValues(v)
+	if err != nil {
+		return item{}, err
+	}
+	displayed := item{Values: map[string]string{}}
+	for _, f := range h.resource.fields {
+		value, _ := lookup(values, f.Name)
+		displayed.Values[f.Name] = f.display(value)
+	}
+	id, _ := lookup(values, "id")
+	displayed.ID = fmt.Sprint(id)
+	return displayed, nil
+}
+
+// fail renders the error of the store.
+func (h handlers[T]) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrNotFound) {
+		h.admin.renderError(w, r, h.base, http.StatusNotFound, "No such "+h.resource.resourceName+" item")
+		return
+	}
+	slog.ErrorContext(r.Context(), "admin store error", "resource", h.resource.resourceName, "error", err)
+	h.admin.renderError(w, r, h.base, http.StatusInternalServerError, "Internal server error")
+}
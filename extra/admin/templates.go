@@ -0,0 +1,99 @@
+package admin
+
+// layoutTemplates are the templates of the pages, executed with a [page].
+const layoutTemplates = `
+{{define "header"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Resource}}{{.Resource}} - {{end}}{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; display: flex; min-height: 100vh; color: #222; }
+nav { background: #1f2937; padding: 1rem; min-width: 12rem; }
+nav a { color: #e5e7eb; display: block; padding: .25rem 0; text-decoration: none; }
+nav a.title { font-weight: bold; margin-bottom: 1rem; }
+main { padding: 1rem 2rem; flex: 1; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: .5rem; text-align: left; }
+label { display: block; margin-top: 1rem; font-weight: bold; }
+input[type=text], input[type=email], input[type=number], input[type=datetime-local], select { width: 100%; max-width: 30rem; padding: .25rem; }
+.error { color: #b91c1c; }
+.help { color: #666; font-size: .875rem; }
+.actions { margin-top: 1rem; display: flex; gap: 1rem; align-items: center; }
+</style>
+</head>
+<body>
+<nav>
+<a class="title" href="{{.Base}}">{{.Title}}</a>
+{{range .Names}}<a href="{{$.Base}}/{{.}}">{{.}}</a>
+{{end}}</nav>
+<main>
+{{end}}
+
+{{define "footer"}}</main>
+</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "header" .}}
+<h1>{{.Title}}</h1>
+<ul>
+{{range .Names}}<li><a href="{{$.Base}}/{{.}}">{{.}}</a></li>
+{{end}}</ul>
+{{template "footer" .}}{{end}}
+
+{{define "list"}}{{template "header" .}}
+<h1>{{.Resource}}</h1>
+<p><a href="{{.Base}}/{{.Resource}}/new">New</a> · {{.Total}} items</p>
+<table>
+<thead><tr>{{range .Fields}}<th>{{.Name}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Items}}{{$item := .}}<tr>{{range $f := $.Fields}}<td>{{if $f.ID}}<a href="{{$.Base}}/{{$.Resource}}/{{$item.ID}}">{{index $item.Values $f.Name}}</a>{{else}}{{index $item.Values $f.Name}}{{end}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{if gt .Pages 1}}<p>
+{{with .Previous}}<a href="?page={{.}}">Previous</a>{{end}}
+Page {{.Page}} of {{.Pages}}
+{{with .Next}}<a href="?page={{.}}">Next</a>{{end}}
+</p>{{end}}
+{{template "footer" .}}{{end}}
+
+{{define "detail"}}{{template "header" .}}
+<h1>{{.Resource}} {{.Item.ID}}</h1>
+<table>
+{{range .Fields}}<tr><th>{{.Name}}</th><td>{{index $.Item.Values .Name}}</td></tr>
+{{end}}</table>
+<div class="actions">
+<a href="{{.Base}}/{{.Resource}}/{{.Item.ID}}/edit">Edit</a>
+<form method="post" action="{{.Base}}/{{.Resource}}/{{.Item.ID}}/delete"><button type="submit">Delete</button></form>
+</div>
+{{template "footer" .}}{{end}}
+
+{{define "form"}}{{template "header" .}}
+<h1>{{if .Item.ID}}Edit {{.Resource}} {{.Item.ID}}{{else}}New {{.Resource}}{{end}}</h1>
+{{with index .Errors ""}}<p class="error">{{.}}</p>{{end}}
+<form method="post" action="{{.Base}}/{{.Resource}}{{if .Item.ID}}/{{.Item.ID}}{{end}}">
+{{range .Fields}}{{if .Editable}}{{$value := index $.Item.Values .Name}}
+<label for="{{.Name}}">{{.Name}}{{if .Required}} *{{end}}</label>
+{{if eq .Input "select"}}<select id="{{.Name}}" name="{{.Name}}"{{if .Required}} required{{end}}>
+{{if not .Required}}<option value=""></option>{{end}}
+{{range .Enum}}<option{{if eq . $value}} selected{{end}}>{{.}}</option>{{end}}
+</select>
+{{else if eq .Input "checkbox"}}<input type="checkbox" id="{{.Name}}" name="{{.Name}}"{{if eq $value "true" "on"}} checked{{end}}>
+{{else}}<input type="{{.Input}}" id="{{.Name}}" name="{{.Name}}" value="{{$value}}"{{with .Step}} step="{{.}}"{{end}}{{if .Required}} required{{end}}>
+{{end}}{{with .Description}}<div class="help">{{.}}</div>{{end}}
+{{with index $.Errors .Name}}<div class="error">{{.}}</div>{{end}}
+{{end}}{{end}}
+<div class="actions">
+<button type="submit">Save</button>
+<a href="{{.Base}}/{{.Resource}}{{if .Item.ID}}/{{.Item.ID}}{{end}}">Cancel</a>
+</div>
+</form>
+{{template "footer" .}}{{end}}
+
+{{define "error"}}{{template "header" .}}
+<h1>Error</h1>
+<p class="error">{{.Message}}</p>
+{{template "footer" .}}{{end}}
+`
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the requests while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("client: circuit breaker is open")
+
+// BreakerPolicy configures the circuit breaker of a client. See [WithCircuitBreaker].
+type BreakerPolicy struct {
+	// Number of consecutive failures opening the circuit. Defaults to 5.
+	FailureThreshold int
+	// Time the circuit stays open before a request is let through to probe the service. Defaults to 30s.
+	OpenDuration time.Duration
+	// Reports whether an attempt failed. Defaults to the transport errors, except the canceled requests,
+	// and the 5xx responses.
+	IsFailure func(res *http.Response, err error) bool
+}
+
+// WithCircuitBreaker stops sending the requests to a failing service, so that it can recover
+// and the callers fail fast instead of waiting for timeouts.
+// After FailureThreshold consecutive failures, the circuit opens: the requests fail with [ErrCircuitOpen].
+// After OpenDuration, a single request probes the service: the circuit closes if it succeeds, and opens again otherwise.
+//
+// The breaker is shared by all the requests of the client: build one client per called service.
+func WithCircuitBreaker(policy BreakerPolicy) Option {
+	if policy.FailureThreshold < 0 || policy.OpenDuration < 0 {
+		panic("breaker policy must not be negative")
+	}
+	if policy.FailureThreshold == 0 {
+		policy.FailureThreshold = 5
+	}
+	if policy.OpenDuration == 0 {
+		policy.OpenDuration = 30 * time.Second
+	}
+	if policy.IsFailure == nil {
+		policy.IsFailure = isFailure
+	}
+	return func(c *config) { c.breaker = &policy }
+}
+
+func isFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return res.StatusCode >= http.StatusInternalServerError
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breakerTransport struct {
+	base   http.RoundTripper
+	policy BreakerPolicy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreakerTransport(base http.RoundTripper, policy BreakerPolicy) *breakerTransport {
+	return &breakerTransport{base: base, policy: policy, now: time.Now}
+}
+
+func (t *breakerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !t.allow() {
+		closeBody(r)
+		return nil, ErrCircuitOpen
+	}
+	res, err := t.base.RoundTrip(r)
+	t.record(res, err)
+	return res, err
+}
+
+// allow reports whether the request can be sent, and lets a single request probe the service once the circuit
+// has been open for OpenDuration.
+func (t *breakerTransport) allow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == breakerOpen && t.now().Sub(t.openedAt) >= t.policy.OpenDuration {
+		t.state = breakerHalfOpen
+	}
+	switch t.state {
+	case breakerOpen:
+		return false
+	case breakerHalfOpen:
+		if t.probing {
+			return false
+		}
+		t.probing = true
+	}
+	return true
+}
+
+func (t *breakerTransport) record(res *http.Response, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	probe := t.state == breakerHalfOpen
+	t.probing = false
+	switch {
+	case t.policy.IsFailure(res, err):
+		t.failures++
+		if probe || t.failures >= t.policy.FailureThreshold {
+			t.state = breakerOpen
+			t.openedAt = t.now()
+		}
+	case err != nil:
+		// Canceled by the caller: the next request probes the service again.
+	default:
+		t.state = breakerClosed
+		t.failures = 0
+	}
+}
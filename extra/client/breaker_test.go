@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubTransport answers with the given statuses, then 200.
+type stubTransport struct {
+	statuses []int
+	calls    int
+}
+
+func (s *stubTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.calls++
+	status := http.StatusOK
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	if status == 0 {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: status, Body: http.NoBody, Request: r}, nil
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	newBreaker := func(statuses ...int) (*breakerTransport, *stubTransport, *time.Time) {
+		stub := &stubTransport{statuses: statuses}
+		var c config
+		WithCircuitBreaker(BreakerPolicy{FailureThreshold: 2, OpenDuration: time.Minute})(&c)
+		breaker := newBreakerTransport(stub, *c.breaker)
+		now := time.Now()
+		breaker.now = func() time.Time { return now }
+		return breaker, stub, &now
+	}
+	get := func(t *testing.T, breaker *breakerTransport) (int, error) {
+		t.Helper()
+		res, err := breaker.RoundTrip(httptestRequest(t, context.Background()))
+		if err != nil {
+			return 0, err
+		}
+		return res.StatusCode, nil
+	}
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		breaker, stub, now := newBreaker(http.StatusInternalServerError, 0, http.StatusInternalServerError)
+
+		status, _ := get(t, breaker)
+		require.Equal(t, http.StatusInternalServerError, status)
+		_, err := get(t, breaker)
+		require.ErrorContains(t, err, "connection refused")
+
+		_, err = get(t, breaker)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		require.Equal(t, 2, stub.calls, "not sent")
+
+		t.Run("probes the service after the open duration", func(t *testing.T) {
+			*now = now.Add(time.Minute)
+			status, _ := get(t, breaker)
+			require.Equal(t, http.StatusInternalServerError, status)
+			_, err := get(t, breaker)
+			require.ErrorIs(t, err, ErrCircuitOpen, "failed probe")
+
+			*now = now.Add(time.Minute)
+			status, _ = get(t, breaker)
+			require.Equal(t, http.StatusOK, status)
+			status, _ = get(t, breaker)
+			require.Equal(t, http.StatusOK, status, "closed")
+		})
+	})
+
+	t.Run("successes reset the failures", func(t *testing.T) {
+		breaker, _, _ := newBreaker(http.StatusInternalServerError, http.StatusOK, http.StatusInternalServerError, http.StatusOK)
+		for range 4 {
+			_, err := get(t, breaker)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("a single probe at a time", func(t *testing.T) {
+		breaker, _, now := newBreaker(http.StatusInternalServerError, http.StatusInternalServerError)
+		for range 2 {
+			_, _ = get(t, breaker)
+		}
+		*now = now.Add(time.Minute)
+
+		require.True(t, breaker.allow())
+		require.False(t, breaker.allow(), "probe in flight")
+
+		breaker.record(nil, context.Canceled)
+		require.True(t, breaker.allow(), "canceled probe")
+	})
+
+	t.Run("client errors are not failures", func(t *testing.T) {
+		breaker, stub, _ := newBreaker(http.StatusNotFound, http.StatusNotFound, http.StatusNotFound)
+		for range 3 {
+			_, _ = get(t, breaker)
+		}
+		require.Equal(t, 3, stub.calls)
+	})
+
+	t.Run("open circuits are not retried", func(t *testing.T) {
+		stub := &stubTransport{statuses: []int{0, 0, 0, 0}}
+		c := New(
+			WithTransport(stub),
+			WithRetry(RetryPolicy{MaxAttempts: 4, MinBackoff: time.Millisecond}),
+			WithCircuitBreaker(BreakerPolicy{FailureThreshold: 2}),
+		)
+		_, err := c.Get("http://inventory/items")
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		require.Equal(t, 2, stub.calls)
+	})
+}
+
+func httptestRequest(t *testing.T, ctx context.Context) *http.Request {
+	t.Helper()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://inventory/items", nil)
+	require.NoError(t, err)
+	return r
+}
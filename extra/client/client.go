@@ -0,0 +1,176 @@
+// Package client builds the HTTP clients of the service-to-service calls, with consistent resilience behavior:
+// base URL, credentials, retries with jitter, circuit breaking and metrics.
+// The built client is a standard [http.Client], so it can be given to the SDKs generated from the OpenAPI spec
+// of the called service.
+//
+//	inventory := client.New(
+//		client.WithBaseURL("http://inventory.internal"),
+//		client.WithBearerToken(os.Getenv("INVENTORY_TOKEN")),
+//		client.WithRetry(client.RetryPolicy{MaxAttempts: 3}),
+//		client.WithCircuitBreaker(client.BreakerPolicy{FailureThreshold: 5, OpenDuration: 30 * time.Second}),
+//		client.WithMetrics(func(a client.Attempt) {
+//			requestDuration.WithLabelValues("inventory", strconv.Itoa(a.StatusCode)).Observe(a.Duration.Seconds())
+//		}),
+//	)
+//
+//	api, err := inventoryapi.NewClientWithResponses("http://inventory.internal", inventoryapi.WithHTTPClient(inventory))
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures the client built by [New].
+type Option func(*config)
+
+type config struct {
+	baseURL   *url.URL
+	timeout   time.Duration
+	transport http.RoundTripper
+	auth      func(*http.Request) error
+	retry     *RetryPolicy
+	breaker   *BreakerPolicy
+	metrics   func(Attempt)
+}
+
+// Attempt describes an attempt of a request, given to the [WithMetrics] callback.
+type Attempt struct {
+	Request *http.Request
+	// Number of the attempt, starting at 1.
+	Number int
+	// Status code of the response, 0 if there is no response.
+	StatusCode int
+	// Error of the transport, or [ErrCircuitOpen].
+	Err      error
+	Duration time.Duration
+}
+
+// New builds an [http.Client] with the given options.
+// Each attempt of a request is measured, goes through the circuit breaker and gets the credentials,
+// and the retries wrap the attempts.
+func New(options ...Option) *http.Client {
+	c := config{transport: http.DefaultTransport}
+	for _, option := range options {
+		option(&c)
+	}
+
+	transport := c.transport
+	if c.auth != nil {
+		transport = authTransport{base: transport, auth: c.auth}
+	}
+	if c.breaker != nil {
+		transport = newBreakerTransport(transport, *c.breaker)
+	}
+	if c.metrics != nil {
+		transport = metricsTransport{base: transport, metrics: c.metrics}
+	}
+	if c.retry != nil {
+		transport = newRetryTransport(transport, *c.retry)
+	}
+	if c.baseURL != nil {
+		transport = baseURLTransport{base: transport, baseURL: c.baseURL}
+	}
+
+	return &http.Client{Transport: transport, Timeout: c.timeout}
+}
+
+// WithBaseURL resolves the relative URLs of the requests, like "/pets/1", against the base URL.
+// It panics if the base URL is invalid.
+func WithBaseURL(baseURL string) Option {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		panic("invalid base URL " + baseURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return func(c *config) { c.baseURL = u }
+}
+
+// WithTimeout limits the time of the requests, retries included. See [http.Client.Timeout].
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) { c.timeout = timeout }
+}
+
+// WithTransport sets the transport sending the requests. Defaults to [http.DefaultTransport].
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *config) { c.transport = transport }
+}
+
+// WithAuth sets the credentials of each attempt, so that refreshed tokens are used by the retries.
+// The request can be modified: it is a copy of the request of the caller.
+func WithAuth(auth func(*http.Request) error) Option {
+	return func(c *config) { c.auth = auth }
+}
+
+// WithBearerToken sets the Authorization header of the requests to the given token.
+func WithBearerToken(token string) Option {
+	return WithAuth(func(r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// WithMetrics calls the callback after each attempt of the requests.
+func WithMetrics(metrics func(Attempt)) Option {
+	return func(c *config) { c.metrics = metrics }
+}
+
+type baseURLTransport struct {
+	base    http.RoundTripper
+	baseURL *url.URL
+}
+
+func (t baseURLTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.IsAbs() {
+		return t.base.RoundTrip(r)
+	}
+	r = r.Clone(r.Context())
+	r.URL = t.baseURL.ResolveReference(&url.URL{
+		Path:     strings.TrimPrefix(r.URL.Path, "/"),
+		RawQuery: r.URL.RawQuery,
+		Fragment: r.URL.Fragment,
+	})
+	r.Host = r.URL.Host
+	return t.base.RoundTrip(r)
+}
+
+type authTransport struct {
+	base http.RoundTripper
+	auth func(*http.Request) error
+}
+
+func (t authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	if err := t.auth(r); err != nil {
+		closeBody(r)
+		return nil, err
+	}
+	return t.base.RoundTrip(r)
+}
+
+type metricsTransport struct {
+	base    http.RoundTripper
+	metrics func(Attempt)
+}
+
+func (t metricsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(r)
+	attempt := Attempt{Request: r, Number: attemptNumber(r.Context()), Err: err, Duration: time.Since(start)}
+	if res != nil {
+		attempt.StatusCode = res.StatusCode
+	}
+	t.metrics(attempt)
+	return res, err
+}
+
+// closeBody closes the body of a request that is not sent, as a RoundTripper must.
+func closeBody(r *http.Request) {
+	if r.Body != nil {
+		_ = r.Body.Close()
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// service is a test server answering with the given status codes, then 200.
+type service struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func newService(t *testing.T, statuses ...int) *service {
+	t.Helper()
+	s := &service{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *service) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("base URL and credentials", func(t *testing.T) {
+		svc := newService(t)
+		c := New(WithBaseURL(svc.URL+"/api/v1"), WithBearerToken("secret"), WithTimeout(time.Minute))
+		require.Equal(t, time.Minute, c.Timeout)
+
+		res, err := c.Get("/pets/1?fields=name")
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		require.Equal(t, "/api/v1/pets/1", svc.requests[0].URL.Path)
+		require.Equal(t, "fields=name", svc.requests[0].URL.RawQuery)
+		require.Equal(t, "Bearer secret", svc.requests[0].Header.Get("Authorization"))
+
+		t.Run("absolute URLs are kept", func(t *testing.T) {
+			res, err := c.Get(svc.URL + "/health")
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, "/health", svc.requests[1].URL.Path)
+		})
+	})
+
+	t.Run("credentials errors", func(t *testing.T) {
+		svc := newService(t)
+		c := New(WithAuth(func(*http.Request) error { return errors.New("token expired") }))
+
+		_, err := c.Get(svc.URL)
+		require.ErrorContains(t, err, "token expired")
+		require.Zero(t, svc.count())
+	})
+
+	t.Run("metrics of each attempt", func(t *testing.T) {
+		svc := newService(t, http.StatusServiceUnavailable)
+		var attempts []Attempt
+		c := New(WithRetry(RetryPolicy{MinBackoff: time.Millisecond}), WithMetrics(func(a Attempt) { attempts = append(attempts, a) }))
+
+		res, err := c.Get(svc.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		require.Len(t, attempts, 2)
+		require.Equal(t, 1, attempts[0].Number)
+		require.Equal(t, http.StatusServiceUnavailable, attempts[0].StatusCode)
+		require.Equal(t, 2, attempts[1].Number)
+		require.Equal(t, http.StatusOK, attempts[1].StatusCode)
+		require.Positive(t, attempts[1].Duration)
+	})
+
+	t.Run("invalid base URL", func(t *testing.T) {
+		require.Panics(t, func() { WithBaseURL("/relative") })
+	})
+}
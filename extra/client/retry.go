@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures the retries of the requests. See [WithRetry].
+type RetryPolicy struct {
+	// Maximum number of attempts of a request, the first one included. Defaults to 3.
+	MaxAttempts int
+	// Maximum wait before the first retry. It doubles at each retry, up to MaxBackoff. Defaults to 100ms.
+	MinBackoff time.Duration
+	// Maximum wait between two attempts. Defaults to 5s.
+	MaxBackoff time.Duration
+	// Reports whether a failed attempt is retried. Defaults to [Retryable].
+	Retryable func(res *http.Response, err error) bool
+}
+
+// WithRetry retries the failed requests, waiting a random duration between two attempts (full jitter),
+// so that the clients do not retry all at the same time. The Retry-After header of the responses is honored,
+// up to MaxBackoff.
+//
+// Only the idempotent requests are retried: the GET, HEAD, OPTIONS, TRACE, PUT and DELETE requests,
+// and the requests with an Idempotency-Key header. Their body must be replayable, which is the case of the requests
+// created by [http.NewRequest] with a [bytes.Buffer], [bytes.Reader] or [strings.Reader] body.
+func WithRetry(policy RetryPolicy) Option {
+	if policy.MaxAttempts < 0 || policy.MinBackoff < 0 || policy.MaxBackoff < 0 {
+		panic("retry policy must not be negative")
+	}
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.MinBackoff == 0 {
+		policy.MinBackoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = 5 * time.Second
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = Retryable
+	}
+	return func(c *config) { c.retry = &policy }
+}
+
+// Retryable reports whether a failed attempt is worth retrying: the transport errors, except the canceled requests,
+// the deadlines exceeded and the open circuits, and the 429 Too Many Requests, 502 Bad Gateway, 503 Service Unavailable
+// and 504 Gateway Timeout responses.
+func Retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrCircuitOpen)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) retryTransport {
+	return retryTransport{base: base, policy: policy}
+}
+
+func (t retryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !replayable(r) {
+		return t.base.RoundTrip(r)
+	}
+
+	for attempt := 1; ; attempt++ {
+		req := r.WithContext(context.WithValue(r.Context(), attemptKey{}, attempt))
+		if attempt > 1 && r.GetBody != nil {
+			body, err := r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		res, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.MaxAttempts || !t.policy.Retryable(res, err) {
+			return res, err
+		}
+
+		wait := t.backoff(attempt, res)
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+			_ = res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the wait before the next attempt: a random duration up to the exponential backoff,
+// or the Retry-After delay of the response if it is longer.
+func (t retryTransport) backoff(attempt int, res *http.Response) time.Duration {
+	ceiling := t.policy.MaxBackoff
+	if shift := attempt - 1; shift < 32 && t.policy.MinBackoff<<shift < ceiling && t.policy.MinBackoff<<shift > 0 {
+		ceiling = t.policy.MinBackoff << shift
+	}
+	wait := rand.N(ceiling + 1)
+
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			wait = max(wait, min(time.Duration(seconds)*time.Second, t.policy.MaxBackoff))
+		}
+	}
+	return wait
+}
+
+// replayable reports whether the request can be sent again.
+func replayable(r *http.Request) bool {
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.Header.Get("Idempotency-Key") != ""
+}
+
+type attemptKey struct{}
+
+// attemptNumber returns the number of the attempt of the request, starting at 1.
+func attemptNumber(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetry(t *testing.T) {
+	retry := WithRetry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+
+	t.Run("retries the failed requests", func(t *testing.T) {
+		svc := newService(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+		res, err := New(retry).Get(svc.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, 3, svc.count())
+	})
+
+	t.Run("up to the maximum attempts", func(t *testing.T) {
+		svc := newService(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		res, err := New(retry).Get(svc.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		require.Equal(t, 3, svc.count())
+	})
+
+	t.Run("not the client errors", func(t *testing.T) {
+		svc := newService(t, http.StatusNotFound)
+		res, err := New(retry).Get(svc.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.Equal(t, 1, svc.count())
+	})
+
+	t.Run("replays the bodies of the idempotent requests", func(t *testing.T) {
+		svc := newService(t, http.StatusServiceUnavailable)
+		req, err := http.NewRequest(http.MethodPut, svc.URL, strings.NewReader(`{"name":"Rex"}`))
+		require.NoError(t, err)
+		res, err := New(retry).Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, []string{`{"name":"Rex"}`, `{"name":"Rex"}`}, svc.bodies)
+	})
+
+	t.Run("non-idempotent requests", func(t *testing.T) {
+		svc := newService(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		res, err := New(retry).Post(svc.URL, "application/json", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		require.Equal(t, 1, svc.count())
+
+		req, err := http.NewRequest(http.MethodPost, svc.URL, strings.NewReader(`{}`))
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "order-42")
+		res, err = New(retry).Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode, "retried with an idempotency key")
+	})
+
+	t.Run("stops when the request is canceled", func(t *testing.T) {
+		svc := newService(t, http.StatusServiceUnavailable)
+		ctx, cancel := context.WithCancel(context.Background())
+		c := New(WithRetry(RetryPolicy{MinBackoff: time.Hour, MaxBackoff: time.Hour}), WithMetrics(func(Attempt) { cancel() }))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, svc.URL, nil)
+		require.NoError(t, err)
+		_, err = c.Do(req)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, svc.count())
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	transport := newRetryTransport(nil, RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second})
+
+	for range 100 {
+		require.LessOrEqual(t, transport.backoff(1, nil), 100*time.Millisecond)
+		require.LessOrEqual(t, transport.backoff(3, nil), 400*time.Millisecond)
+		require.LessOrEqual(t, transport.backoff(40, nil), time.Second)
+	}
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		res := &http.Response{Header: http.Header{"Retry-After": {"1"}}}
+		require.Equal(t, time.Second, transport.backoff(1, res))
+
+		res.Header.Set("Retry-After", "120")
+		require.Equal(t, time.Second, transport.backoff(1, res), "up to the maximum backoff")
+	})
+}
@@ -0,0 +1,118 @@
+// Package fuegochi registers the routes of a Fuego server on a [chi.Router], for the applications
+// already using chi to adopt Fuego route by route. The routes declared with fuego.Get, fuego.Post...
+// get the typed controllers, the validation and the OpenAPI spec of Fuego, while the chi routes
+// and middlewares keep working on the same router.
+//
+//	r := chi.NewRouter()
+//	r.Use(middleware.Logger)
+//	r.Get("/legacy", legacyHandler)
+//
+//	s := fuego.NewServer(fuego.WithRouter(fuegochi.New(r)))
+//	fuego.Get(s, "/pets/{id}", getPet)
+//
+//	s.Run() // or serve r, after s.Engine.RegisterOpenAPIRoutes(s) to serve the spec
+//
+// The path parameters of the Fuego routes are read with c.PathParam, including the remaining segments
+// of the "/files/{path...}" patterns, registered as "/files/*" on chi.
+package fuegochi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Router registers the routes of a Fuego server on a chi router.
+// It implements [fuego.Router].
+type Router struct {
+	once sync.Once
+	chi  chi.Router
+}
+
+var _ fuego.Router = (*Router)(nil)
+
+// New returns a router registering the routes on r.
+func New(r chi.Router) *Router {
+	return &Router{chi: r}
+}
+
+// router returns the chi router. The zero value, used when a running server rebuilds its routes,
+// registers them on a new chi router.
+func (rt *Router) router() chi.Router {
+	rt.once.Do(func() {
+		if rt.chi == nil {
+			rt.chi = chi.NewRouter()
+		}
+	})
+	return rt.chi
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.router().ServeHTTP(w, r)
+}
+
+// Handle registers the handler for the [http.ServeMux] pattern, translated to a chi pattern.
+// Like with [http.ServeMux], GET routes also match HEAD requests.
+// It panics for host patterns, which chi does not support.
+func (rt *Router) Handle(pattern string, handler http.Handler) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	if !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("fuegochi: unsupported pattern %q: chi does not support host patterns", pattern))
+	}
+
+	path, wildcard := chiPath(path)
+	if wildcard != "" {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.SetPathValue(wildcard, chi.URLParam(r, "*"))
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	switch method {
+	case "":
+		rt.router().Handle(path, handler)
+	case http.MethodGet:
+		rt.router().Method(http.MethodGet, path, handler)
+		rt.router().Method(http.MethodHead, path, handler)
+	default:
+		rt.router().Method(method, path, handler)
+	}
+}
+
+// Handler returns the router and the chi pattern of the route matching the request,
+// or an empty pattern if no route matches.
+func (rt *Router) Handler(r *http.Request) (http.Handler, string) {
+	pattern := rt.router().Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+	if pattern == "" {
+		return http.NotFoundHandler(), ""
+	}
+	return rt.router(), pattern
+}
+
+// chiPath translates the path of a [http.ServeMux] pattern to chi, and returns the name
+// of its remaining segments parameter, if any:
+//   - "/files/{path...}" becomes "/files/*", with the "path" parameter,
+//   - "/static/" matches all the paths below, and becomes "/static/*",
+//   - "/static/{$}" only matches "/static/", and becomes "/static/".
+func chiPath(path string) (string, string) {
+	if prefix, ok := strings.CutSuffix(path, "{$}"); ok {
+		return prefix, ""
+	}
+	if strings.HasSuffix(path, "/") {
+		return path + "*", ""
+	}
+	i := strings.LastIndex(path, "/")
+	if name, ok := strings.CutSuffix(path[i+1:], "...}"); ok && strings.HasPrefix(name, "{") {
+		return path[:i+1] + "*", name[1:]
+	}
+	return path, ""
+}
@@ -0,0 +1,136 @@
+package fuegochi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+type Pet struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestRouter(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Chi-Middleware", "true")
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Get("/legacy", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("legacy"))
+	})
+
+	s := fuego.NewServer(fuego.WithoutLogger(), fuego.WithRouter(New(r)))
+	fuego.Get(s, "/pets/{id}", func(c fuego.ContextNoBody) (string, error) {
+		return "pet " + c.PathParam("id"), nil
+	})
+	fuego.Post(s, "/pets", func(c fuego.ContextWithBody[Pet]) (Pet, error) {
+		return c.Body()
+	})
+	fuego.Get(s, "/files/{path...}", func(c fuego.ContextNoBody) (string, error) {
+		return "file " + c.PathParam("path"), nil
+	})
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("fuego routes are served by chi, with its middlewares", func(t *testing.T) {
+		w := serve(http.MethodGet, "/pets/42", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "pet 42", w.Body.String())
+		require.Equal(t, "true", w.Header().Get("X-Chi-Middleware"))
+	})
+
+	t.Run("chi routes keep working", func(t *testing.T) {
+		w := serve(http.MethodGet, "/legacy", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "legacy", w.Body.String())
+	})
+
+	t.Run("bodies are validated", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, serve(http.MethodPost, "/pets", `{"name":"Rex"}`).Code)
+		require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/pets", `{}`).Code)
+	})
+
+	t.Run("remaining segments", func(t *testing.T) {
+		w := serve(http.MethodGet, "/files/docs/readme.md", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "file docs/readme.md", w.Body.String())
+	})
+
+	t.Run("GET routes match HEAD requests", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, serve(http.MethodHead, "/pets/42", "").Code)
+		require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodDelete, "/pets/42", "").Code)
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		spec := s.OutputOpenAPISpec()
+		require.NotNil(t, spec.Paths.Find("/pets/{id}").Get)
+		require.NotNil(t, spec.Paths.Find("/pets").Post)
+		require.Nil(t, spec.Paths.Find("/legacy"))
+	})
+}
+
+func TestRouterHandler(t *testing.T) {
+	rt := New(chi.NewRouter())
+	rt.Handle("GET /pets/{id}", http.NotFoundHandler())
+
+	_, pattern := rt.Handler(httptest.NewRequest(http.MethodGet, "/pets/42", nil))
+	require.Equal(t, "/pets/{id}", pattern)
+
+	_, pattern = rt.Handler(httptest.NewRequest(http.MethodGet, "/owners", nil))
+	require.Empty(t, pattern)
+}
+
+func TestRouterZeroValue(t *testing.T) {
+	var rt Router
+	rt.Handle("/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	}))
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ping", nil))
+	require.Equal(t, "pong", w.Body.String())
+}
+
+func TestRouterHostPattern(t *testing.T) {
+	require.PanicsWithValue(t, `fuegochi: unsupported pattern "GET example.com/pets": chi does not support host patterns`, func() {
+		New(chi.NewRouter()).Handle("GET example.com/pets", http.NotFoundHandler())
+	})
+}
+
+func TestChiPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		wildcard string
+	}{
+		{path: "/pets", expected: "/pets"},
+		{path: "/pets/{id}", expected: "/pets/{id}"},
+		{path: "/files/{path...}", expected: "/files/*", wildcard: "path"},
+		{path: "/static/", expected: "/static/*"},
+		{path: "/static/{$}", expected: "/static/"},
+		{path: "/", expected: "/*"},
+		{path: "/{$}", expected: "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, wildcard := chiPath(tt.path)
+			require.Equal(t, tt.expected, path)
+			require.Equal(t, tt.wildcard, wildcard)
+		})
+	}
+}
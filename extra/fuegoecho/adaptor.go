@@ -2,6 +2,7 @@ package fuegoecho
 
 import (
 	"net/http"
+	"reflect"
 
 	"github.com/labstack/echo/v4"
 
@@ -129,15 +130,24 @@ func (a echoRouteRegisterer[T, B]) Register() fuego.Route[T, B] {
 
 // Convert a Fuego handler to a Gin handler.
 func EchoHandler[B, T any](engine *fuego.Engine, handler func(c fuego.ContextWithBody[B]) (T, error), route fuego.BaseRoute) echo.HandlerFunc {
+	bodyTransformers := engine.BodyTransformers(reflect.TypeFor[B]())
+
 	return func(c echo.Context) error {
 		context := &echoContext[B]{
 			CommonContext: internal.CommonContext[B]{
-				CommonCtx:         c.Request().Context(),
-				UrlValues:         c.Request().URL.Query(),
-				OpenAPIParams:     route.Params,
-				DefaultStatusCode: route.DefaultStatusCode,
+				CommonCtx:             c.Request().Context(),
+				UrlValues:             c.Request().URL.Query(),
+				OpenAPIParams:         route.Params,
+				DefaultStatusCode:     route.DefaultStatusCode,
+				UndeclaredParamPolicy: engine.UndeclaredParamPolicy,
+				BodyTransformers:      bodyTransformers,
+				ValidationScenario:    route.ValidationScenario,
+				ValidationDeps:        engine.ValidationDeps,
+				QueryTimeout:          engine.QueryTimeout(),
+				BaseHTTPClient:        engine.HTTPClient(),
 			},
-			echoCtx: c,
+			echoCtx:      c,
+			optionalBody: route.OptionalBody,
 		}
 		fuego.Flow(engine, context, handler)
 		return nil
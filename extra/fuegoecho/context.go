@@ -1,8 +1,11 @@
 package fuegoecho
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strings"
 
@@ -14,6 +17,9 @@ import (
 type echoContext[B any] struct {
 	internal.CommonContext[B]
 	echoCtx echo.Context
+
+	// If true, an empty body decodes to the zero value. See [fuego.OptionOptionalBody].
+	optionalBody bool
 }
 
 var (
@@ -22,13 +28,35 @@ var (
 )
 
 func (c echoContext[B]) Body() (B, error) {
+	// Keep the raw body available after binding.
+	rawBody := c.RawBody()
+
 	var body B
+	if c.optionalBody && len(rawBody) == 0 {
+		return body, nil
+	}
 	err := c.echoCtx.Bind(&body)
 	if err != nil {
 		return body, err
 	}
 
-	return fuego.TransformAndValidate(c, body)
+	return fuego.TransformAndValidate(&c, body)
+}
+
+// rawBodyKey is the echo context key of the raw request body.
+const rawBodyKey = "fuego.rawBody"
+
+// RawBody returns the exact bytes of the request body, even after [echoContext.Body] has decoded them.
+func (c echoContext[B]) RawBody() []byte {
+	if rawBody, ok := c.echoCtx.Get(rawBodyKey).([]byte); ok {
+		return rawBody
+	}
+
+	request := c.echoCtx.Request()
+	rawBody, _ := io.ReadAll(request.Body)
+	request.Body = io.NopCloser(bytes.NewReader(rawBody))
+	c.echoCtx.Set(rawBodyKey, rawBody)
+	return rawBody
 }
 
 func (c echoContext[B]) Context() context.Context {
@@ -80,6 +108,14 @@ func (c echoContext[B]) Render(templateToExecute string, data any, templateGlobs
 	panic("unimplemented")
 }
 
+func (c echoContext[B]) RenderPDF(templateToExecute string, data any, templateGlobsToOverride ...string) (fuego.PDF, error) {
+	panic("unimplemented")
+}
+
+func (c echoContext[B]) Pagination() fuego.Pagination {
+	return fuego.PaginationConfig{}.Parse(c.UrlValues)
+}
+
 func (c echoContext[B]) Request() *http.Request {
 	return c.echoCtx.Request()
 }
@@ -88,6 +124,24 @@ func (c echoContext[B]) Response() http.ResponseWriter {
 	return c.echoCtx.Response()
 }
 
+// HTTPClient returns a client propagating the request ID and the trace context of the request.
+func (c echoContext[B]) HTTPClient() *http.Client {
+	return c.PropagatingHTTPClient(c.echoCtx.Request().Header, c.echoCtx.Response().Header())
+}
+
+func (c echoContext[B]) FormFile(name string) (*multipart.FileHeader, error) {
+	return fuego.FormFile(c.echoCtx.Request(), name)
+}
+
+func (c echoContext[B]) FormFiles() (map[string][]*multipart.FileHeader, error) {
+	return fuego.FormFiles(c.echoCtx.Request())
+}
+
+// SSE starts a stream of Server-Sent Events.
+func (c echoContext[B]) SSE() *fuego.SSEWriter {
+	return fuego.NewSSEWriter(c.echoCtx.Response(), c.echoCtx.Request())
+}
+
 func (c echoContext[B]) SetCookie(cookie http.Cookie) {
 	c.echoCtx.SetCookie(&cookie)
 }
@@ -7,8 +7,9 @@ import (
 
 type ContextTest[B any] struct {
 	echoContext[B]
-	BodyInjected  B
-	ErrorInjected error
+	BodyInjected    B
+	RawBodyInjected []byte
+	ErrorInjected   error
 
 	Params url.Values
 }
@@ -17,6 +18,10 @@ func (c *ContextTest[B]) Body() (B, error) {
 	return c.BodyInjected, c.ErrorInjected
 }
 
+func (c *ContextTest[B]) RawBody() []byte {
+	return c.RawBodyInjected
+}
+
 func (c *ContextTest[B]) Request() *http.Request {
 	return c.echoCtx.Request()
 }
@@ -2,6 +2,8 @@ package fuegogin
 
 import (
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -27,6 +29,10 @@ func (o *OpenAPIHandler) UIHandler(e *fuego.Engine) {
 	)
 }
 
+func HandleGin(engine *fuego.Engine, ginRouter gin.IRouter, method, path string, handler gin.HandlerFunc, options ...func(*fuego.BaseRoute)) *fuego.Route[any, any] {
+	return handleGin(engine, ginRouter, method, path, handler, options...)
+}
+
 func GetGin(engine *fuego.Engine, ginRouter gin.IRouter, path string, handler gin.HandlerFunc, options ...func(*fuego.BaseRoute)) *fuego.Route[any, any] {
 	return handleGin(engine, ginRouter, http.MethodGet, path, handler, options...)
 }
@@ -35,6 +41,22 @@ func PostGin(engine *fuego.Engine, ginRouter gin.IRouter, path string, handler g
 	return handleGin(engine, ginRouter, http.MethodPost, path, handler, options...)
 }
 
+func PutGin(engine *fuego.Engine, ginRouter gin.IRouter, path string, handler gin.HandlerFunc, options ...func(*fuego.BaseRoute)) *fuego.Route[any, any] {
+	return handleGin(engine, ginRouter, http.MethodPut, path, handler, options...)
+}
+
+func PatchGin(engine *fuego.Engine, ginRouter gin.IRouter, path string, handler gin.HandlerFunc, options ...func(*fuego.BaseRoute)) *fuego.Route[any, any] {
+	return handleGin(engine, ginRouter, http.MethodPatch, path, handler, options...)
+}
+
+func DeleteGin(engine *fuego.Engine, ginRouter gin.IRouter, path string, handler gin.HandlerFunc, options ...func(*fuego.BaseRoute)) *fuego.Route[any, any] {
+	return handleGin(engine, ginRouter, http.MethodDelete, path, handler, options...)
+}
+
+func Handle[T, B any](engine *fuego.Engine, ginRouter gin.IRouter, method, path string, handler func(c fuego.ContextWithBody[B]) (T, error), options ...func(*fuego.BaseRoute)) *fuego.Route[T, B] {
+	return handleFuego(engine, ginRouter, method, path, handler, options...)
+}
+
 func Get[T, B any](engine *fuego.Engine, ginRouter gin.IRouter, path string, handler func(c fuego.ContextWithBody[B]) (T, error), options ...func(*fuego.BaseRoute)) *fuego.Route[T, B] {
 	return handleFuego(engine, ginRouter, http.MethodGet, path, handler, options...)
 }
@@ -43,6 +65,18 @@ func Post[T, B any](engine *fuego.Engine, ginRouter gin.IRouter, path string, ha
 	return handleFuego(engine, ginRouter, http.MethodPost, path, handler, options...)
 }
 
+func Put[T, B any](engine *fuego.Engine, ginRouter gin.IRouter, path string, handler func(c fuego.ContextWithBody[B]) (T, error), options ...func(*fuego.BaseRoute)) *fuego.Route[T, B] {
+	return handleFuego(engine, ginRouter, http.MethodPut, path, handler, options...)
+}
+
+func Patch[T, B any](engine *fuego.Engine, ginRouter gin.IRouter, path string, handler func(c fuego.ContextWithBody[B]) (T, error), options ...func(*fuego.BaseRoute)) *fuego.Route[T, B] {
+	return handleFuego(engine, ginRouter, http.MethodPatch, path, handler, options...)
+}
+
+func Delete[T, B any](engine *fuego.Engine, ginRouter gin.IRouter, path string, handler func(c fuego.ContextWithBody[B]) (T, error), options ...func(*fuego.BaseRoute)) *fuego.Route[T, B] {
+	return handleFuego(engine, ginRouter, http.MethodDelete, path, handler, options...)
+}
+
 func handleFuego[T, B any](engine *fuego.Engine, ginRouter gin.IRouter, method, path string, fuegoHandler func(c fuego.ContextWithBody[B]) (T, error), options ...func(*fuego.BaseRoute)) *fuego.Route[T, B] {
 	baseRoute := fuego.NewBaseRoute(method, path, fuegoHandler, engine, options...)
 	return fuego.Registers(engine, ginRouteRegisterer[T, B]{
@@ -67,27 +101,72 @@ type ginRouteRegisterer[T, B any] struct {
 	route      fuego.Route[T, B]
 }
 
+// Register registers the route on the Gin router. The path parameters can be declared
+// the Gin way (/pets/:id, /files/*path) or the Fuego way (/pets/{id}, /files/{path...}):
+// the route is documented with the OpenAPI syntax, and registered with the Gin one.
 func (a ginRouteRegisterer[T, B]) Register() fuego.Route[T, B] {
-	if _, ok := a.ginRouter.(*gin.RouterGroup); ok {
-		a.route.Path = a.ginRouter.(*gin.RouterGroup).BasePath() + a.route.Path
-	}
+	a.ginRouter.Handle(a.route.Method, ginPath(a.route.Path), a.ginHandler)
 
-	a.ginRouter.Handle(a.route.Method, a.route.Path, a.ginHandler)
+	if group, ok := a.ginRouter.(*gin.RouterGroup); ok {
+		a.route.Path = group.BasePath() + a.route.Path
+	}
+	a.route.Path = openAPIPath(a.route.Path)
 
 	return a.route
 }
 
+// ginPath converts the path parameters of the path to the Gin syntax: {id} to :id and {path...} to *path.
+func ginPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name := segment[1 : len(segment)-1]
+		if name, ok := strings.CutSuffix(name, "..."); ok {
+			segments[i] = "*" + name
+		} else {
+			segments[i] = ":" + name
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// openAPIPath converts the path parameters of the path to the OpenAPI syntax: :id and *path to {id} and {path}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		} else if name, ok := strings.CutPrefix(segment, "*"); ok {
+			segments[i] = "{" + name + "}"
+		} else if name, ok := strings.CutSuffix(segment, "...}"); ok {
+			segments[i] = name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
 // Convert a Fuego handler to a Gin handler.
 func GinHandler[B, T any](engine *fuego.Engine, handler func(c fuego.ContextWithBody[B]) (T, error), route fuego.BaseRoute) gin.HandlerFunc {
+	bodyTransformers := engine.BodyTransformers(reflect.TypeFor[B]())
+
 	return func(c *gin.Context) {
 		context := &ginContext[B]{
 			CommonContext: internal.CommonContext[B]{
-				CommonCtx:         c,
-				UrlValues:         c.Request.URL.Query(),
-				OpenAPIParams:     route.Params,
-				DefaultStatusCode: route.DefaultStatusCode,
+				CommonCtx:             c,
+				UrlValues:             c.Request.URL.Query(),
+				OpenAPIParams:         route.Params,
+				DefaultStatusCode:     route.DefaultStatusCode,
+				UndeclaredParamPolicy: engine.UndeclaredParamPolicy,
+				BodyTransformers:      bodyTransformers,
+				ValidationScenario:    route.ValidationScenario,
+				ValidationDeps:        engine.ValidationDeps,
+				QueryTimeout:          engine.QueryTimeout(),
+				BaseHTTPClient:        engine.HTTPClient(),
 			},
-			ginCtx: c,
+			ginCtx:       c,
+			optionalBody: route.OptionalBody,
 		}
 
 		fuego.Flow(engine, context, handler)
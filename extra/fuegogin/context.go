@@ -1,8 +1,11 @@
 package fuegogin
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strings"
 
@@ -15,6 +18,9 @@ import (
 type ginContext[B any] struct {
 	internal.CommonContext[B]
 	ginCtx *gin.Context
+
+	// If true, an empty body decodes to the zero value. See [fuego.OptionOptionalBody].
+	optionalBody bool
 }
 
 var (
@@ -23,18 +29,47 @@ var (
 )
 
 func (c ginContext[B]) Body() (B, error) {
+	// Keep the raw body available after binding.
+	rawBody := c.RawBody()
+
 	var body B
+	if c.optionalBody && len(rawBody) == 0 {
+		return body, nil
+	}
 	err := c.ginCtx.Bind(&body)
 	if err != nil {
 		return body, err
 	}
-	return fuego.TransformAndValidate(c, body)
+	return fuego.TransformAndValidate(&c, body)
+}
+
+// RawBody returns the exact bytes of the request body, even after [ginContext.Body] has decoded them.
+func (c ginContext[B]) RawBody() []byte {
+	if cached, ok := c.ginCtx.Get(gin.BodyBytesKey); ok {
+		if rawBody, ok := cached.([]byte); ok {
+			return rawBody
+		}
+	}
+
+	rawBody, _ := io.ReadAll(c.ginCtx.Request.Body)
+	c.ginCtx.Request.Body = io.NopCloser(bytes.NewReader(rawBody))
+	c.ginCtx.Set(gin.BodyBytesKey, rawBody)
+	return rawBody
 }
 
 func (c ginContext[B]) Context() context.Context {
 	return c.ginCtx
 }
 
+// Done is closed when the client disconnects, even without [gin.Engine.ContextWithFallback].
+func (c ginContext[B]) Done() <-chan struct{} {
+	return c.ginCtx.Request.Context().Done()
+}
+
+func (c ginContext[B]) IsAborted() bool {
+	return errors.Is(c.ginCtx.Request.Context().Err(), context.Canceled)
+}
+
 func (c ginContext[B]) Cookie(name string) (*http.Cookie, error) {
 	return c.ginCtx.Request.Cookie(name)
 }
@@ -80,6 +115,14 @@ func (c ginContext[B]) Render(templateToExecute string, data any, templateGlobsT
 	panic("unimplemented")
 }
 
+func (c ginContext[B]) RenderPDF(templateToExecute string, data any, templateGlobsToOverride ...string) (fuego.PDF, error) {
+	panic("unimplemented")
+}
+
+func (c ginContext[B]) Pagination() fuego.Pagination {
+	return fuego.PaginationConfig{}.Parse(c.UrlValues)
+}
+
 func (c ginContext[B]) Request() *http.Request {
 	return c.ginCtx.Request
 }
@@ -88,6 +131,24 @@ func (c ginContext[B]) Response() http.ResponseWriter {
 	return c.ginCtx.Writer
 }
 
+// HTTPClient returns a client propagating the request ID and the trace context of the request.
+func (c ginContext[B]) HTTPClient() *http.Client {
+	return c.PropagatingHTTPClient(c.ginCtx.Request.Header, c.ginCtx.Writer.Header())
+}
+
+func (c ginContext[B]) FormFile(name string) (*multipart.FileHeader, error) {
+	return fuego.FormFile(c.ginCtx.Request, name)
+}
+
+func (c ginContext[B]) FormFiles() (map[string][]*multipart.FileHeader, error) {
+	return fuego.FormFiles(c.ginCtx.Request)
+}
+
+// SSE starts a stream of Server-Sent Events.
+func (c ginContext[B]) SSE() *fuego.SSEWriter {
+	return fuego.NewSSEWriter(c.ginCtx.Writer, c.ginCtx.Request)
+}
+
 func (c ginContext[B]) SetCookie(cookie http.Cookie) {
 	c.ginCtx.SetCookie(cookie.Name, cookie.Value, cookie.MaxAge, cookie.Path, cookie.Domain, cookie.Secure, cookie.HttpOnly)
 }
@@ -0,0 +1,192 @@
+// Package fuegoprotobuf adds Protocol Buffers support to Fuego:
+// request bodies and responses in the application/x-protobuf format,
+// for request and response types that are proto messages.
+// JSON and the other formats keep working on the same routes.
+package fuegoprotobuf
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/go-fuego/fuego"
+)
+
+// ContentType is the media type of Protocol Buffers bodies.
+const ContentType = "application/x-protobuf"
+
+// ErrNotAMessage is returned when a body type is not a proto message.
+var ErrNotAMessage = errors.New("type is not a proto message")
+
+// WithProtobuf enables Protocol Buffers on the server:
+//   - request bodies with the application/x-protobuf Content-Type are decoded with [Decode]
+//   - responses are encoded with [Send] when the Accept header asks for application/x-protobuf
+//
+// Use [Option] to document the content type on the routes.
+func WithProtobuf() func(*fuego.Server) {
+	return func(s *fuego.Server) {
+		fuego.WithBodyDecoder(ContentType, Decode)(s)
+		s.Serialize = Sender(s.Serialize)
+	}
+}
+
+// Decode unmarshals a Protocol Buffers body into dst.
+// dst must be a proto message, or a pointer to a proto message pointer (for ContextWithBody[*pb.Message]).
+// It can be used with [fuego.WithBodyDecoder] or [fuego.OptionBodyDecoder].
+func Decode(r io.Reader, dst any) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if message, ok := dst.(proto.Message); ok {
+		return proto.Unmarshal(raw, message)
+	}
+
+	// dst is a **pb.Message: allocate the message.
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Pointer {
+		return fmt.Errorf("%w: %T", ErrNotAMessage, dst)
+	}
+	message, ok := reflect.New(v.Elem().Type().Elem()).Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotAMessage, dst)
+	}
+	if err := proto.Unmarshal(raw, message); err != nil {
+		return err
+	}
+	v.Elem().Set(reflect.ValueOf(message))
+	return nil
+}
+
+// Sender wraps a [fuego.Sender] to send proto messages in the Protocol Buffers format
+// when the Accept header asks for application/x-protobuf.
+// Other responses are sent with next.
+func Sender(next fuego.Sender) fuego.Sender {
+	return func(w http.ResponseWriter, r *http.Request, ans any) error {
+		if message, ok := ans.(proto.Message); ok && acceptsProtobuf(r) {
+			return Send(w, r, message)
+		}
+		return next(w, r, ans)
+	}
+}
+
+// Send sends a proto message in the Protocol Buffers format.
+func Send(w http.ResponseWriter, _ *http.Request, message proto.Message) error {
+	raw, err := proto.Marshal(message)
+	if err != nil {
+		return fuego.NotAcceptableError{
+			Err:    err,
+			Detail: fmt.Sprintf("Cannot serialize type %T to Protocol Buffers", message),
+		}
+	}
+	w.Header().Set("Content-Type", ContentType)
+	_, err = w.Write(raw)
+	return err
+}
+
+func acceptsProtobuf(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == ContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Option documents the application/x-protobuf content type on the request body and the response of the route,
+// alongside JSON. The given messages (typically the request and response types, like &pb.Pet{})
+// are referenced by their full name in the x-protobuf-messages extension of the operation.
+// For example:
+//
+//	fuego.Post(s, "/pets", createPet,
+//		fuegoprotobuf.Option(&pb.CreatePetRequest{}, &pb.Pet{}),
+//	)
+func Option(messages ...proto.Message) func(*fuego.BaseRoute) {
+	names := make([]string, 0, len(messages))
+	for _, message := range messages {
+		names = append(names, string(message.ProtoReflect().Descriptor().FullName()))
+	}
+
+	return func(r *fuego.BaseRoute) {
+		r.RequestContentTypes = withProtobuf(r.RequestContentTypes)
+		r.ResponseContentTypes = withProtobuf(r.ResponseContentTypes)
+		if len(names) > 0 {
+			r.Operation.Extensions = extend(r.Operation.Extensions, "x-protobuf-messages", names)
+		}
+	}
+}
+
+// OptionWithDescriptor works like [Option], and embeds the descriptors of the messages
+// in the x-protobuf-descriptor extension of the operation:
+// a base64-encoded FileDescriptorSet containing the files of the messages and their dependencies.
+// Clients can use it to decode the bodies without the .proto files.
+func OptionWithDescriptor(messages ...proto.Message) func(*fuego.BaseRoute) {
+	descriptor := base64.StdEncoding.EncodeToString(fileDescriptorSet(messages))
+
+	return func(r *fuego.BaseRoute) {
+		Option(messages...)(r)
+		r.Operation.Extensions = extend(r.Operation.Extensions, "x-protobuf-descriptor", descriptor)
+	}
+}
+
+// withProtobuf adds the Protocol Buffers content type to the documented content types.
+// JSON is kept as the default content type.
+func withProtobuf(contentTypes []string) []string {
+	if contentTypes == nil {
+		contentTypes = []string{"application/json"}
+	}
+	if slices.Contains(contentTypes, ContentType) {
+		return contentTypes
+	}
+	return append(slices.Clip(contentTypes), ContentType)
+}
+
+func extend(extensions map[string]any, key string, value any) map[string]any {
+	if extensions == nil {
+		extensions = make(map[string]any)
+	}
+	extensions[key] = value
+	return extensions
+}
+
+// fileDescriptorSet returns the serialized FileDescriptorSet of the files defining the messages, with their dependencies.
+func fileDescriptorSet(messages []proto.Message) []byte {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+
+	var add func(file protoreflect.FileDescriptor)
+	add = func(file protoreflect.FileDescriptor) {
+		if seen[file.Path()] {
+			return
+		}
+		seen[file.Path()] = true
+		imports := file.Imports()
+		for i := range imports.Len() {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+	}
+
+	for _, message := range messages {
+		add(message.ProtoReflect().Descriptor().ParentFile())
+	}
+
+	raw, err := proto.Marshal(set)
+	if err != nil {
+		panic(fmt.Sprintf("cannot serialize protobuf descriptors: %v", err))
+	}
+	return raw
+}
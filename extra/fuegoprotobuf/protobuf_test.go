@@ -0,0 +1,127 @@
+package fuegoprotobuf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/go-fuego/fuego"
+)
+
+func newTestServer() *fuego.Server {
+	s := fuego.NewServer(
+		fuego.WithoutLogger(),
+		WithProtobuf(),
+	)
+	fuego.Post(s, "/echo", func(c fuego.ContextWithBody[*wrapperspb.StringValue]) (*wrapperspb.StringValue, error) {
+		body, err := c.Body()
+		if err != nil {
+			return nil, err
+		}
+		return wrapperspb.String("echo " + body.GetValue()), nil
+	}, Option(&wrapperspb.StringValue{}))
+	return s
+}
+
+func TestWithProtobuf(t *testing.T) {
+	s := newTestServer()
+
+	t.Run("protobuf in, protobuf out", func(t *testing.T) {
+		raw, err := proto.Marshal(wrapperspb.String("hello"))
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(raw))
+		r.Header.Set("Content-Type", ContentType)
+		r.Header.Set("Accept", ContentType)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+		response := &wrapperspb.StringValue{}
+		require.NoError(t, proto.Unmarshal(w.Body.Bytes(), response))
+		require.Equal(t, "echo hello", response.GetValue())
+	})
+
+	t.Run("JSON still works on the same route", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader([]byte(`{"value":"hello"}`)))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "echo hello")
+	})
+
+	t.Run("invalid protobuf body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader([]byte{0xff, 0xff}))
+		r.Header.Set("Content-Type", ContentType)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	raw, err := proto.Marshal(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	t.Run("into a message", func(t *testing.T) {
+		message := &wrapperspb.StringValue{}
+		require.NoError(t, Decode(bytes.NewReader(raw), message))
+		require.Equal(t, "hello", message.GetValue())
+	})
+
+	t.Run("into a pointer to a message pointer", func(t *testing.T) {
+		var message *wrapperspb.StringValue
+		require.NoError(t, Decode(bytes.NewReader(raw), &message))
+		require.Equal(t, "hello", message.GetValue())
+	})
+
+	t.Run("not a message", func(t *testing.T) {
+		var s string
+		require.ErrorIs(t, Decode(bytes.NewReader(raw), &s), ErrNotAMessage)
+	})
+}
+
+func TestOption(t *testing.T) {
+	s := newTestServer()
+	operation := s.OpenAPI.Description().Paths.Find("/echo").Post
+
+	requestContent := operation.RequestBody.Value.Content
+	require.NotNil(t, requestContent.Get("application/json"))
+	require.NotNil(t, requestContent.Get(ContentType))
+
+	responseContent := operation.Responses.Value("200").Value.Content
+	require.NotNil(t, responseContent.Get("application/json"))
+	require.NotNil(t, responseContent.Get(ContentType))
+
+	require.Equal(t, []string{"google.protobuf.StringValue"}, operation.Extensions["x-protobuf-messages"])
+}
+
+func TestOptionWithDescriptor(t *testing.T) {
+	s := fuego.NewServer(fuego.WithoutLogger(), WithProtobuf())
+	route := fuego.Get(s, "/value", func(c fuego.ContextNoBody) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String("hello"), nil
+	}, OptionWithDescriptor(&wrapperspb.StringValue{}))
+
+	encoded, ok := route.Operation.Extensions["x-protobuf-descriptor"].(string)
+	require.True(t, ok)
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	set := &descriptorpb.FileDescriptorSet{}
+	require.NoError(t, proto.Unmarshal(raw, set))
+	require.Len(t, set.GetFile(), 1)
+	require.Equal(t, "google/protobuf/wrappers.proto", set.GetFile()[0].GetName())
+}
@@ -0,0 +1,9 @@
+module github.com/go-fuego/fuego/extra/fuegosentry
+
+go 1.23.6
+
+require (
+	github.com/getsentry/sentry-go v0.31.1
+	github.com/go-fuego/fuego v0.18.0
+	github.com/stretchr/testify v1.10.0
+)
@@ -0,0 +1,68 @@
+// Package fuegosentry reports the internal errors and the panics of a Fuego server to Sentry.
+//
+//	err := sentry.Init(sentry.ClientOptions{Dsn: os.Getenv("SENTRY_DSN")})
+//	...
+//	s := fuego.NewServer(
+//		fuego.WithEngineOptions(
+//			fuego.WithErrorReporter(fuegosentry.NewReporter()),
+//		),
+//	)
+package fuegosentry
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Reporter is a [fuego.ErrorReporter] sending the errors to Sentry.
+type Reporter struct {
+	hub *sentry.Hub
+}
+
+var _ fuego.ErrorReporter = (*Reporter)(nil)
+
+// NewReporter creates a reporter using the hub initialized with [sentry.Init].
+func NewReporter() *Reporter {
+	return NewReporterWithHub(sentry.CurrentHub())
+}
+
+// NewReporterWithHub creates a reporter using the given hub.
+// The hub of the request context is preferred, if set by a Sentry middleware.
+func NewReporterWithHub(hub *sentry.Hub) *Reporter {
+	return &Reporter{hub: hub}
+}
+
+// ReportError sends the error to Sentry, with the route, the request ID, the error ID and the principal.
+func (r *Reporter) ReportError(ctx context.Context, report fuego.ErrorReport) {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = r.hub.Clone()
+	}
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		if report.Request != nil {
+			scope.SetRequest(report.Request)
+		}
+		scope.SetTag("route", report.Route)
+		scope.SetTag("status", strconv.Itoa(report.Status))
+		if report.RequestID != "" {
+			scope.SetTag("request_id", report.RequestID)
+		}
+		if report.ErrorID != "" {
+			scope.SetTag("error_id", report.ErrorID)
+		}
+		if report.Principal != "" {
+			scope.SetUser(sentry.User{ID: report.Principal})
+		}
+
+		if report.Panic != nil {
+			hub.RecoverWithContext(ctx, report.Panic)
+			return
+		}
+		hub.CaptureException(report.Err)
+	})
+}
@@ -0,0 +1,44 @@
+package fuegosentry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func TestReporter(t *testing.T) {
+	var events []*sentry.Event
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		BeforeSend: func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			events = append(events, event)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	s := fuego.NewServer(
+		fuego.WithEngineOptions(
+			fuego.WithErrorReporter(NewReporterWithHub(sentry.NewHub(client, sentry.NewScope()))),
+		),
+	)
+	fuego.Get(s, "/pets/{id}", func(c fuego.ContextNoBody) (any, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/pets/1", nil)
+	r.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Len(t, events, 1)
+	require.Equal(t, "connection refused", events[0].Exception[0].Value)
+	require.Equal(t, "GET /pets/{id}", events[0].Tags["route"])
+	require.Equal(t, "req-1", events[0].Tags["request_id"])
+}
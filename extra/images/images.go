@@ -0,0 +1,306 @@
+// Package images serves images from a [Store], resized and converted on the fly
+// with the query parameters of the request:
+//
+//	GET /images/photos/cat.jpg?w=400&h=300&fmt=png
+//
+// The image fits in the requested box, keeping its aspect ratio, and is never enlarged.
+// The transformed images are cached, so each variant is only computed once,
+// and are sent with Cache-Control and ETag headers.
+//
+// JPEG, PNG, GIF and WebP images are read. JPEG, PNG and GIF images are written:
+// other formats, like WebP or AVIF, are added with [Config.Encoders].
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+
+	"github.com/go-fuego/fuego"
+)
+
+// Encoder writes images in a format.
+type Encoder struct {
+	// ContentType of the images, like "image/webp".
+	ContentType string
+	// Encode writes the image to w.
+	Encode func(w io.Writer, img image.Image) error
+}
+
+// Config configures a [Handler].
+type Config struct {
+	// Store reads the original images. Required.
+	Store Store
+	// Cache stores the transformed images. Defaults to a [MemoryCache] of 64 MiB.
+	Cache Cache
+	// MaxWidth and MaxHeight are the maximum values of the w and h query parameters. Default to 4096.
+	MaxWidth, MaxHeight int
+	// MaxPixels is the maximum number of pixels of the original images, to protect the server
+	// from decompression bombs. Defaults to 40 million.
+	MaxPixels int
+	// Quality of the JPEG images, from 1 to 100. Defaults to 85.
+	Quality int
+	// MaxAge of the images in the Cache-Control header. Defaults to 24 hours.
+	MaxAge time.Duration
+	// Encoders adds output formats, by value of the fmt query parameter. For example, with github.com/chai2010/webp:
+	//
+	//	Encoders: map[string]images.Encoder{
+	//		"webp": {ContentType: "image/webp", Encode: func(w io.Writer, img image.Image) error {
+	//			return webp.Encode(w, img, &webp.Options{Quality: 80})
+	//		}},
+	//	}
+	Encoders map[string]Encoder
+}
+
+// Handler serves the images. Create it with [New] and register its route with [Handler.Register].
+type Handler struct {
+	config   Config
+	encoders map[string]Encoder
+	formats  []string // sorted keys of encoders
+}
+
+// New returns an image [Handler].
+func New(config Config) *Handler {
+	if config.Store == nil {
+		panic("images: a Store is required")
+	}
+	if config.Cache == nil {
+		config.Cache = NewMemoryCache(64 << 20)
+	}
+	if config.MaxWidth <= 0 {
+		config.MaxWidth = 4096
+	}
+	if config.MaxHeight <= 0 {
+		config.MaxHeight = 4096
+	}
+	if config.MaxPixels <= 0 {
+		config.MaxPixels = 40_000_000
+	}
+	if config.Quality <= 0 || config.Quality > 100 {
+		config.Quality = 85
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 24 * time.Hour
+	}
+
+	h := &Handler{
+		config: config,
+		encoders: map[string]Encoder{
+			"jpeg": {ContentType: "image/jpeg", Encode: func(w io.Writer, img image.Image) error {
+				return jpeg.Encode(w, img, &jpeg.Options{Quality: config.Quality})
+			}},
+			"png": {ContentType: "image/png", Encode: png.Encode},
+			"gif": {ContentType: "image/gif", Encode: func(w io.Writer, img image.Image) error {
+				return gif.Encode(w, img, nil)
+			}},
+		},
+	}
+	for format, encoder := range config.Encoders {
+		h.encoders[format] = encoder
+	}
+	for format := range h.encoders {
+		h.formats = append(h.formats, format)
+	}
+	slices.Sort(h.formats)
+	return h
+}
+
+// Register registers the GET path/{name...} route on the server, serving the image name of the store.
+// The options are applied to the route, for example to add an authentication middleware.
+func (h *Handler) Register(s *fuego.Server, path string, options ...func(*fuego.BaseRoute)) {
+	path = strings.TrimSuffix(path, "/")
+
+	contentTypes := make([]string, 0, len(h.formats))
+	for _, format := range h.formats {
+		contentTypes = append(contentTypes, h.encoders[format].ContentType)
+	}
+	slices.Sort(contentTypes)
+
+	fuego.GetStd(s, path+"/{name...}", h.serve, append(options,
+		fuego.OptionSummary("Get image"),
+		fuego.OptionQueryInt("w", fmt.Sprintf("Maximum width of the image in pixels, up to %d", h.config.MaxWidth)),
+		fuego.OptionQueryInt("h", fmt.Sprintf("Maximum height of the image in pixels, up to %d", h.config.MaxHeight)),
+		fuego.OptionQuery("fmt", "Format of the image. Defaults to the format of the original image", fuego.ParamEnum(toAny(h.formats)...)),
+		imageResponse(slices.Compact(contentTypes)),
+		errorResponse(http.StatusBadRequest, "Invalid query parameters"),
+		errorResponse(http.StatusNotFound, "Image not found"),
+	)...)
+}
+
+// transformation is the parsed query parameters of a request.
+type transformation struct {
+	width, height int
+	format        string
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	t, err := h.parse(r)
+	if err != nil {
+		fuego.SendError(w, r, fuego.BadRequestError{Detail: err.Error(), Err: err})
+		return
+	}
+
+	key := fmt.Sprintf("%s?w=%d&h=%d&fmt=%s", name, t.width, t.height, t.format)
+	data, ok := h.config.Cache.Get(key)
+	if !ok {
+		data, err = h.transform(r, name, t)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+			fuego.SendError(w, r, fuego.NotFoundError{Detail: "image not found", Err: err})
+			return
+		}
+		if err != nil {
+			fuego.SendError(w, r, fuego.InternalServerError{Detail: "cannot transform image", Err: err})
+			return
+		}
+		h.config.Cache.Set(key, data)
+	}
+
+	contentType := http.DetectContentType(data)
+	if t.format != "" {
+		contentType = h.encoders[t.format].ContentType
+	}
+	sum := sha256.Sum256(data)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.config.MaxAge.Seconds())))
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+func (h *Handler) parse(r *http.Request) (transformation, error) {
+	var t transformation
+	var err error
+	query := r.URL.Query()
+	if t.width, err = parseSize(query.Get("w"), h.config.MaxWidth); err != nil {
+		return t, fmt.Errorf("w: %w", err)
+	}
+	if t.height, err = parseSize(query.Get("h"), h.config.MaxHeight); err != nil {
+		return t, fmt.Errorf("h: %w", err)
+	}
+	t.format = query.Get("fmt")
+	if t.format == "jpg" {
+		t.format = "jpeg"
+	}
+	if _, ok := h.encoders[t.format]; t.format != "" && !ok {
+		return t, fmt.Errorf("fmt: unsupported format %q, must be one of %s", t.format, strings.Join(h.formats, ", "))
+	}
+	return t, nil
+}
+
+// parseSize parses a width or a height. 0 means unspecified.
+func parseSize(value string, maxSize int) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 || size > maxSize {
+		return 0, fmt.Errorf("must be an integer between 1 and %d", maxSize)
+	}
+	return size, nil
+}
+
+// transform reads the original image, and returns it resized and converted.
+// The original is returned as is when nothing changes.
+func (h *Handler) transform(r *http.Request, name string, t transformation) ([]byte, error) {
+	file, err := h.config.Store.Open(r.Context(), name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	original, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+	if config.Width*config.Height > h.config.MaxPixels {
+		return nil, fmt.Errorf("decode %s: %dx%d image larger than %d pixels", name, config.Width, config.Height, h.config.MaxPixels)
+	}
+
+	width, height := fit(config.Width, config.Height, t.width, t.height)
+	if t.format == "" {
+		t.format = format
+	}
+	if width == config.Width && height == config.Height && t.format == format {
+		return original, nil
+	}
+	encoder, ok := h.encoders[t.format]
+	if !ok {
+		// Original format without encoder, like WebP
+		encoder = h.encoders["png"]
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+	if width != config.Width || height != config.Height {
+		resized := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(resized, resized.Bounds(), img, img.Bounds(), draw.Src, nil)
+		img = resized
+	}
+
+	var transformed bytes.Buffer
+	if err := encoder.Encode(&transformed, img); err != nil {
+		return nil, fmt.Errorf("encode %s: %w", name, err)
+	}
+	return transformed.Bytes(), nil
+}
+
+// fit returns the size of an image fitting in the maxWidth x maxHeight box, keeping its aspect ratio.
+// Images are never enlarged, and a 0 maximum is ignored.
+func fit(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && maxWidth < width {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && maxHeight < height {
+		scale = min(scale, float64(maxHeight)/float64(height))
+	}
+	if scale == 1 {
+		return width, height
+	}
+	return max(1, int(math.Round(float64(width)*scale))), max(1, int(math.Round(float64(height)*scale)))
+}
+
+func imageResponse(contentTypes []string) func(*fuego.BaseRoute) {
+	return func(r *fuego.BaseRoute) {
+		schema := openapi3.NewStringSchema().WithFormat("binary")
+		r.Operation.AddResponse(http.StatusOK, openapi3.NewResponse().
+			WithDescription("Image").
+			WithContent(openapi3.NewContentWithSchema(schema, contentTypes)))
+	}
+}
+
+func errorResponse(status int, description string) func(*fuego.BaseRoute) {
+	return fuego.OptionAddResponse(status, description, fuego.Response{Type: fuego.HTTPError{}})
+}
+
+func toAny(values []string) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		for y := range height {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// countingStore counts the images opened.
+type countingStore struct {
+	Store
+	opened int
+}
+
+func (s *countingStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	s.opened++
+	return s.Store.Open(ctx, name)
+}
+
+func TestHandler(t *testing.T) {
+	original := testPNG(t, 200, 100)
+	store := &countingStore{Store: FSStore{FS: fstest.MapFS{
+		"photos/cat.png": {Data: original},
+		"notes.txt":      {Data: []byte("not an image")},
+	}}}
+
+	s := fuego.NewServer(fuego.WithoutLogger())
+	New(Config{
+		Store: store,
+		Encoders: map[string]Encoder{
+			"fake": {ContentType: "image/x-fake", Encode: func(w io.Writer, img image.Image) error {
+				_, err := io.WriteString(w, "fake")
+				return err
+			}},
+		},
+	}).Register(s, "/images/")
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) (image.Config, string) {
+		t.Helper()
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		config, format, err := image.DecodeConfig(w.Body)
+		require.NoError(t, err)
+		return config, format
+	}
+
+	t.Run("original", func(t *testing.T) {
+		w := get("/images/photos/cat.png")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, original, w.Body.Bytes())
+		require.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		require.Equal(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+		require.NotEmpty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		config, format := decode(t, get("/images/photos/cat.png?w=50"))
+		require.Equal(t, "png", format)
+		require.Equal(t, 50, config.Width)
+		require.Equal(t, 25, config.Height)
+	})
+
+	t.Run("resize and convert", func(t *testing.T) {
+		w := get("/images/photos/cat.png?w=50&h=10&fmt=jpg")
+		require.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+		config, format := decode(t, w)
+		require.Equal(t, "jpeg", format)
+		require.Equal(t, 20, config.Width)
+		require.Equal(t, 10, config.Height)
+	})
+
+	t.Run("images are never enlarged", func(t *testing.T) {
+		w := get("/images/photos/cat.png?w=1000")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, original, w.Body.Bytes())
+	})
+
+	t.Run("custom encoder", func(t *testing.T) {
+		w := get("/images/photos/cat.png?fmt=fake")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "image/x-fake", w.Header().Get("Content-Type"))
+		require.Equal(t, "fake", w.Body.String())
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		get("/images/photos/cat.png?w=64")
+		opened := store.opened
+		w := get("/images/photos/cat.png?w=64")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, opened, store.opened)
+
+		r := httptest.NewRequest(http.MethodGet, "/images/photos/cat.png?w=64", nil)
+		r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"w=abc", "w=0", "h=5000", "fmt=tiff"} {
+			require.Equal(t, http.StatusBadRequest, get("/images/photos/cat.png?"+query).Code, query)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, get("/images/photos/dog.png").Code)
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		require.Equal(t, http.StatusInternalServerError, get("/images/notes.txt").Code)
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		operation := s.OutputOpenAPISpec().Paths.Find("/images/{name...}").Get
+		require.NotNil(t, operation.Parameters.GetByInAndName("query", "w"))
+		require.NotNil(t, operation.Parameters.GetByInAndName("query", "h"))
+		require.Equal(t, []any{"fake", "gif", "jpeg", "png"}, operation.Parameters.GetByInAndName("query", "fmt").Schema.Value.Enum)
+
+		content := operation.Responses.Status(http.StatusOK).Value.Content
+		require.Len(t, content, 4)
+		require.Equal(t, "binary", content.Get("image/png").Schema.Value.Format)
+		require.NotNil(t, operation.Responses.Status(http.StatusNotFound))
+	})
+}
+
+func TestHandlerMaxPixels(t *testing.T) {
+	s := fuego.NewServer(fuego.WithoutLogger())
+	New(Config{
+		Store:     FSStore{FS: fstest.MapFS{"large.png": {Data: testPNG(t, 20, 20)}}},
+		MaxPixels: 100,
+	}).Register(s, "/images")
+
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/images/large.png", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestNewWithoutStore(t *testing.T) {
+	require.PanicsWithValue(t, "images: a Store is required", func() { New(Config{}) })
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name                          string
+		maxWidth, maxHeight           int
+		expectedWidth, expectedHeight int
+	}{
+		{name: "unspecified", expectedWidth: 200, expectedHeight: 100},
+		{name: "width", maxWidth: 100, expectedWidth: 100, expectedHeight: 50},
+		{name: "height", maxHeight: 20, expectedWidth: 40, expectedHeight: 20},
+		{name: "box", maxWidth: 100, maxHeight: 20, expectedWidth: 40, expectedHeight: 20},
+		{name: "larger", maxWidth: 400, maxHeight: 400, expectedWidth: 200, expectedHeight: 100},
+		{name: "tiny", maxHeight: 1, expectedWidth: 2, expectedHeight: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := fit(200, 100, tt.maxWidth, tt.maxHeight)
+			require.Equal(t, tt.expectedWidth, width)
+			require.Equal(t, tt.expectedHeight, height)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package images
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// Store reads the original images.
+type Store interface {
+	// Open returns the content of the image. It returns an error wrapping [fs.ErrNotExist]
+	// when the image does not exist.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// FSStore reads the images from a file system, like a directory with [os.DirFS] or an [embed.FS].
+type FSStore struct {
+	FS fs.FS
+}
+
+var _ Store = FSStore{}
+
+func (s FSStore) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	return s.FS.Open(name)
+}
+
+// Cache stores the transformed images, so each variant of an image is only computed once.
+// It must be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, image []byte)
+}
+
+// MemoryCache is an in-memory [Cache] with a maximum size,
+// evicting the least recently used images first.
+type MemoryCache struct {
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key   string
+	image []byte
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache returns a [MemoryCache] holding up to maxSize bytes of images.
+func NewMemoryCache(maxSize int64) *MemoryCache {
+	return &MemoryCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return element.Value.(*cacheEntry).image, true
+}
+
+// Set stores the image. Images larger than the cache are not stored.
+func (c *MemoryCache) Set(key string, image []byte) {
+	if int64(len(image)) > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, image: image})
+	c.size += int64(len(image))
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	entry := c.lru.Remove(element).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.image))
+}
@@ -0,0 +1,52 @@
+package images
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSStore(t *testing.T) {
+	store := FSStore{FS: fstest.MapFS{"cat.png": {Data: []byte("cat")}}}
+
+	file, err := store.Open(context.Background(), "cat.png")
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, "cat", string(content))
+
+	_, err = store.Open(context.Background(), "dog.png")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(10)
+
+	cache.Set("a", []byte("aaaa"))
+	cache.Set("b", []byte("bbbb"))
+	_, ok := cache.Get("a") // a is now the most recently used
+	require.True(t, ok)
+
+	cache.Set("c", []byte("cccc"))
+	_, ok = cache.Get("b")
+	require.False(t, ok, "least recently used image should be evicted")
+	image, ok := cache.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "aaaa", string(image))
+
+	cache.Set("a", []byte("aa"))
+	image, _ = cache.Get("a")
+	require.Equal(t, "aa", string(image))
+	require.Equal(t, int64(6), cache.size)
+
+	cache.Set("large", []byte("larger than the cache"))
+	_, ok = cache.Get("large")
+	require.False(t, ok)
+	_, ok = cache.Get("c")
+	require.True(t, ok)
+}
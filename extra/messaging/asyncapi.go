@@ -0,0 +1,132 @@
+package messaging
+
+import (
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/go-fuego/fuego"
+)
+
+// AsyncAPI is the AsyncAPI 3.0 document (https://www.asyncapi.com/docs/reference/specification/v3.0.0)
+// describing the subjects consumed by the [Consumers].
+type AsyncAPI struct {
+	AsyncAPI   string               `json:"asyncapi"`
+	Info       AsyncAPIInfo         `json:"info"`
+	Channels   map[string]Channel   `json:"channels"`
+	Operations map[string]Operation `json:"operations"`
+	Components Components           `json:"components"`
+}
+
+// AsyncAPIInfo is the general information of the [AsyncAPI] document.
+type AsyncAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Channel is a subject of the broker.
+type Channel struct {
+	Address  string               `json:"address"`
+	Messages map[string]Reference `json:"messages"`
+}
+
+// Operation is the consumption of a channel.
+type Operation struct {
+	Action      string      `json:"action"`
+	Channel     Reference   `json:"channel"`
+	Messages    []Reference `json:"messages"`
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Tags        []Tag       `json:"tags,omitempty"`
+}
+
+// Tag of an [Operation].
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// Components holds the messages and the schemas of their payloads.
+type Components struct {
+	Messages map[string]MessageObject `json:"messages"`
+	Schemas  openapi3.Schemas         `json:"schemas"`
+}
+
+// MessageObject describes the payload of a message.
+type MessageObject struct {
+	Name        string              `json:"name"`
+	ContentType string              `json:"contentType"`
+	Payload     *openapi3.SchemaRef `json:"payload"`
+}
+
+// Reference to another object of the document.
+type Reference struct {
+	Ref string `json:"$ref"`
+}
+
+// AsyncAPI returns the AsyncAPI document of the consumers. The title and the version
+// are the ones of the OpenAPI spec of the server.
+func (m *Consumers) AsyncAPI() AsyncAPI {
+	doc := AsyncAPI{
+		AsyncAPI:   "3.0.0",
+		Channels:   make(map[string]Channel),
+		Operations: make(map[string]Operation),
+		Components: Components{Messages: make(map[string]MessageObject)},
+	}
+	if m.server != nil {
+		info := m.server.OpenAPI.Description().Info
+		doc.Info = AsyncAPIInfo{Title: info.Title, Version: info.Version, Description: info.Description}
+	}
+
+	// The payload schemas are generated apart from the HTTP routes ones, to keep them out of the OpenAPI spec.
+	schemas := fuego.NewOpenAPI()
+	for _, c := range m.consumers {
+		payload := c.payload(schemas)
+		channel := channelID(c.subject)
+		messageID := payload.Name
+		if messageID == "" {
+			messageID = channel
+		}
+
+		doc.Components.Messages[messageID] = MessageObject{
+			Name:        messageID,
+			ContentType: "application/json",
+			Payload:     &payload.SchemaRef,
+		}
+		doc.Channels[channel] = Channel{
+			Address:  c.subject,
+			Messages: map[string]Reference{messageID: {Ref: "#/components/messages/" + messageID}},
+		}
+
+		operation := Operation{
+			Action:   "receive",
+			Channel:  Reference{Ref: "#/channels/" + channel},
+			Messages: []Reference{{Ref: "#/channels/" + channel + "/messages/" + messageID}},
+		}
+		if c.route.Operation != nil {
+			operation.Summary = c.route.Operation.Summary
+			operation.Description = c.route.Operation.Description
+			for _, tag := range c.route.Operation.Tags {
+				operation.Tags = append(operation.Tags, Tag{Name: tag})
+			}
+		}
+		doc.Operations["consume"+strings.ToUpper(channel[:1])+channel[1:]] = operation
+	}
+	doc.Components.Schemas = schemas.Description().Components.Schemas
+
+	return doc
+}
+
+// channelID converts a subject like orders.created or orders/created to ordersCreated.
+func channelID(subject string) string {
+	words := strings.FieldsFunc(subject, func(r rune) bool {
+		return r == '.' || r == '/' || r == '-' || r == '_' || r == ':' || r == '*' || r == '>'
+	})
+	if len(words) == 0 {
+		return "channel"
+	}
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
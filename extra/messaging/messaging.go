@@ -0,0 +1,249 @@
+// Package messaging binds Fuego controllers to the subjects of a message broker (NATS, Kafka, RabbitMQ...).
+// The consumers share the deserialization, transformation, validation and error handling of the HTTP routes,
+// and are documented in an AsyncAPI spec.
+//
+//	consumers := messaging.New(messaging.Config{Subscriber: natsSubscriber{conn}})
+//	messaging.Consume(consumers, "orders.created", onOrderCreated, option.Summary("Prepare the order"))
+//
+//	s := fuego.NewServer(
+//		fuego.WithPlugins(consumers), // subscribes on Run, unsubscribes on Shutdown
+//	)
+//
+//	func onOrderCreated(c fuego.ContextWithBody[OrderCreated]) error {
+//		order, err := c.Body() // deserialized, transformed and validated
+//		...
+//	}
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Message received on a subject.
+type Message struct {
+	Subject string
+	// Headers of the message, available with c.Header() in the controllers.
+	Headers http.Header
+	Data    []byte
+}
+
+// Subscriber subscribes to the subjects of a broker.
+// Implement it on top of your broker client, for example with a NATS queue subscription
+// or a Kafka consumer group.
+type Subscriber interface {
+	// Subscribe delivers the messages of the subject to handle until ctx is cancelled. It must not block.
+	// When handle returns an error, the message should be redelivered (nack),
+	// otherwise it is acknowledged.
+	Subscribe(ctx context.Context, subject string, handle func(ctx context.Context, msg Message) error) error
+}
+
+// SubscriberFunc is a function implementing [Subscriber].
+type SubscriberFunc func(ctx context.Context, subject string, handle func(ctx context.Context, msg Message) error) error
+
+func (f SubscriberFunc) Subscribe(ctx context.Context, subject string, handle func(ctx context.Context, msg Message) error) error {
+	return f(ctx, subject, handle)
+}
+
+// Config of the [Consumers].
+type Config struct {
+	// Subscriber to the broker. Required.
+	Subscriber Subscriber
+	// URL of the AsyncAPI spec. Defaults to "/asyncapi.json". Set to "-" to disable the route.
+	SpecURL string
+}
+
+// Consumers are the controllers bound to the subjects of a broker, with [Consume].
+// They are a [fuego.Plugin]: installed with [fuego.WithPlugins], the subscriptions start
+// when the server runs and stop when it shuts down.
+type Consumers struct {
+	config    Config
+	server    *fuego.Server
+	consumers []*consumer
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+var (
+	_ fuego.Plugin        = (*Consumers)(nil)
+	_ fuego.PluginStarter = (*Consumers)(nil)
+	_ fuego.PluginStopper = (*Consumers)(nil)
+)
+
+type consumer struct {
+	subject string
+	route   fuego.BaseRoute
+	// payload registers the schema of the message payload in the given spec.
+	payload func(openapi *fuego.OpenAPI) fuego.SchemaTag
+	// build returns the handler of the messages, once the server is known.
+	build   func(s *fuego.Server) http.Handler
+	handler http.Handler
+}
+
+// New returns the [Consumers] of the broker.
+func New(config Config) *Consumers {
+	if config.Subscriber == nil {
+		panic("messaging: a Subscriber is required")
+	}
+	if config.SpecURL == "" {
+		config.SpecURL = "/asyncapi.json"
+	}
+	return &Consumers{config: config}
+}
+
+// Consume binds the controller to the subject. The message is deserialized into T according to its
+// Content-Type header (JSON by default), then transformed and validated like an HTTP request body.
+// Errors returned by the controller go through the error handler of the server, and nack the message.
+//
+// The route options document the consumer, for example [fuego.OptionSummary] or [fuego.OptionTags],
+// and configure the validation with [fuego.OptionValidationScenario].
+func Consume[T any](consumers *Consumers, subject string, controller func(c fuego.ContextWithBody[T]) error, options ...func(*fuego.BaseRoute)) {
+	c := &consumer{
+		subject: subject,
+		payload: func(openapi *fuego.OpenAPI) fuego.SchemaTag {
+			return fuego.SchemaTagFromType(openapi, *new(T))
+		},
+	}
+	c.build = func(s *fuego.Server) http.Handler {
+		c.route = fuego.NewBaseRoute(http.MethodPost, subject, controller, s.Engine, options...)
+		return fuego.HTTPHandler(s, func(ctx fuego.ContextWithBody[T]) (any, error) {
+			err := controller(ctx)
+			if controllerErr, ok := ctx.Context().Value(controllerErrKey{}).(*error); ok {
+				*controllerErr = err
+			}
+			return nil, err
+		}, c.route)
+	}
+	if consumers.server != nil {
+		c.handler = c.build(consumers.server)
+	}
+	consumers.consumers = append(consumers.consumers, c)
+}
+
+// Install builds the consumers with the configuration of the server, and registers the AsyncAPI spec route.
+func (m *Consumers) Install(s *fuego.Server) error {
+	m.server = s
+	for _, c := range m.consumers {
+		c.handler = c.build(s)
+	}
+	if m.config.SpecURL != "-" {
+		fuego.Get(s, m.config.SpecURL, func(fuego.ContextNoBody) (AsyncAPI, error) {
+			return m.AsyncAPI(), nil
+		}, fuego.OptionHide())
+	}
+	return nil
+}
+
+// Start subscribes to the subjects of the consumers.
+func (m *Consumers) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	m.cancel = cancel
+	for _, c := range m.consumers {
+		if c.handler == nil {
+			cancel()
+			return errors.New("messaging: the consumers must be installed on a server with fuego.WithPlugins")
+		}
+		if err := m.config.Subscriber.Subscribe(ctx, c.subject, c.handle); err != nil {
+			cancel()
+			return fmt.Errorf("subscribe to %s: %w", c.subject, err)
+		}
+	}
+	return nil
+}
+
+// Stop cancels the subscriptions.
+func (m *Consumers) Stop(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	return nil
+}
+
+// handle serves the message to the controller as a POST request on the subject.
+// It returns the error sent by the error handler of the server, as a [fuego.HTTPError] if possible,
+// wrapping the error returned by the controller.
+func (c *consumer) handle(ctx context.Context, msg Message) error {
+	var controllerErr error
+	ctx = context.WithValue(withMessage(ctx, msg), controllerErrKey{}, &controllerErr)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(msg.Data))
+	if err != nil {
+		return err
+	}
+	r.URL.Path = "/" + c.subject
+	for key, values := range msg.Headers {
+		r.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	if r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	w := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	c.handler.ServeHTTP(w, r)
+	if w.status < http.StatusBadRequest {
+		return nil
+	}
+
+	var httpError fuego.HTTPError
+	if err := json.Unmarshal(w.body.Bytes(), &httpError); err != nil || httpError.Status == 0 {
+		httpError = fuego.HTTPError{Status: w.status, Detail: string(bytes.TrimSpace(w.body.Bytes()))}
+	}
+	httpError.Err = controllerErr
+	return httpError
+}
+
+type (
+	messageKey       struct{}
+	controllerErrKey struct{}
+)
+
+func withMessage(ctx context.Context, msg Message) context.Context {
+	return context.WithValue(ctx, messageKey{}, msg)
+}
+
+// MessageFromContext returns the message handled by the controller, for example to read its subject.
+//
+//	msg, _ := messaging.MessageFromContext(c.Context())
+func MessageFromContext(ctx context.Context) (Message, bool) {
+	msg, ok := ctx.Value(messageKey{}).(Message)
+	return msg, ok
+}
+
+// responseRecorder keeps the status and the body of the response, to report the errors.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
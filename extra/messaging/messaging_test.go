@@ -0,0 +1,183 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+type OrderCreated struct {
+	ID       string `json:"id" validate:"required"`
+	Customer string `json:"customer"`
+}
+
+func (o *OrderCreated) InTransform(context.Context) error {
+	o.Customer = strings.ToLower(o.Customer)
+	return nil
+}
+
+// broker delivers the published messages to the subscriptions, synchronously.
+type broker struct {
+	mu            sync.Mutex
+	subscriptions map[string]func(ctx context.Context, msg Message) error
+	ctx           context.Context
+}
+
+func newBroker() *broker {
+	return &broker{subscriptions: make(map[string]func(ctx context.Context, msg Message) error)}
+}
+
+func (b *broker) Subscribe(ctx context.Context, subject string, handle func(ctx context.Context, msg Message) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[subject] = handle
+	b.ctx = ctx
+	return nil
+}
+
+func (b *broker) publish(subject string, data string, headers ...string) error {
+	b.mu.Lock()
+	handle := b.subscriptions[subject]
+	b.mu.Unlock()
+	msg := Message{Subject: subject, Data: []byte(data), Headers: http.Header{}}
+	for i := 0; i+1 < len(headers); i += 2 {
+		msg.Headers.Set(headers[i], headers[i+1])
+	}
+	return handle(context.Background(), msg)
+}
+
+var errOutOfStock = errors.New("out of stock")
+
+func newServer(t *testing.T, consumers *Consumers) *fuego.Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := fuego.NewServer(fuego.WithoutLogger(), fuego.WithListener(listener), fuego.WithPlugins(consumers),
+		fuego.WithEngineOptions(fuego.WithOpenAPIConfig(fuego.OpenAPIConfig{DisableLocalSave: true, DisableMessages: true})),
+	)
+	return s
+}
+
+func TestConsume(t *testing.T) {
+	b := newBroker()
+	consumers := New(Config{Subscriber: b})
+
+	var received []OrderCreated
+	Consume(consumers, "orders.created", func(c fuego.ContextWithBody[OrderCreated]) error {
+		order, err := c.Body()
+		if err != nil {
+			return err
+		}
+		if order.ID == "out-of-stock" {
+			return fuego.ConflictError{Detail: "order " + order.ID + " cannot be prepared", Err: errOutOfStock}
+		}
+		msg, ok := MessageFromContext(c.Context())
+		require.True(t, ok)
+		require.Equal(t, "orders.created", msg.Subject)
+		require.Equal(t, "shop", c.Header("X-Source"))
+		received = append(received, order)
+		return nil
+	}, fuego.OptionSummary("Prepare the order"), fuego.OptionTags("orders"))
+
+	s := newServer(t, consumers)
+	go s.Run()
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.subscriptions["orders.created"] != nil
+	}, time.Second, time.Millisecond)
+
+	t.Run("deserializes, transforms and validates the message", func(t *testing.T) {
+		require.NoError(t, b.publish("orders.created", `{"id":"1","customer":"ALICE"}`, "X-Source", "shop"))
+		require.Equal(t, []OrderCreated{{ID: "1", Customer: "alice"}}, received)
+	})
+
+	t.Run("invalid messages are rejected", func(t *testing.T) {
+		err := b.publish("orders.created", `{"customer":"bob"}`)
+		var httpError fuego.HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusBadRequest, httpError.Status)
+		require.Len(t, received, 1)
+	})
+
+	t.Run("controller errors go through the error handler", func(t *testing.T) {
+		err := b.publish("orders.created", `{"id":"out-of-stock"}`, "X-Source", "shop")
+		var httpError fuego.HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusConflict, httpError.Status)
+		require.Equal(t, "order out-of-stock cannot be prepared", httpError.Detail)
+		require.ErrorIs(t, err, errOutOfStock)
+	})
+
+	t.Run("stop cancels the subscriptions", func(t *testing.T) {
+		require.NoError(t, s.Shutdown(context.Background()))
+		require.Error(t, b.ctx.Err())
+	})
+}
+
+func TestAsyncAPI(t *testing.T) {
+	consumers := New(Config{Subscriber: newBroker()})
+	Consume(consumers, "orders.created", func(c fuego.ContextWithBody[OrderCreated]) error { return nil },
+		fuego.OptionSummary("Prepare the order"), fuego.OptionTags("orders"))
+	s := newServer(t, consumers)
+
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/asyncapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	require.Equal(t, "3.0.0", doc["asyncapi"])
+
+	spec := consumers.AsyncAPI()
+	require.Equal(t, Channel{
+		Address:  "orders.created",
+		Messages: map[string]Reference{"OrderCreated": {Ref: "#/components/messages/OrderCreated"}},
+	}, spec.Channels["ordersCreated"])
+	require.Equal(t, Operation{
+		Action:   "receive",
+		Channel:  Reference{Ref: "#/channels/ordersCreated"},
+		Messages: []Reference{{Ref: "#/channels/ordersCreated/messages/OrderCreated"}},
+		Summary:  "Prepare the order",
+		Tags:     []Tag{{Name: "orders"}},
+	}, spec.Operations["consumeOrdersCreated"])
+	require.Equal(t, "#/components/schemas/OrderCreated", spec.Components.Messages["OrderCreated"].Payload.Ref)
+	require.Contains(t, spec.Components.Schemas["OrderCreated"].Value.Required, "id")
+
+	// The payloads are not added to the OpenAPI spec
+	require.NotContains(t, s.OpenAPI.Description().Components.Schemas, "OrderCreated")
+	require.Nil(t, s.OpenAPI.Description().Paths.Value("/asyncapi.json"))
+}
+
+func TestStartWithoutServer(t *testing.T) {
+	consumers := New(Config{Subscriber: newBroker()})
+	Consume(consumers, "orders.created", func(c fuego.ContextWithBody[OrderCreated]) error { return nil })
+	require.ErrorContains(t, consumers.Start(context.Background()), "installed on a server")
+
+	failing := New(Config{Subscriber: SubscriberFunc(func(context.Context, string, func(context.Context, Message) error) error {
+		return errors.New("connection refused")
+	})})
+	Consume(failing, "orders.created", func(c fuego.ContextWithBody[OrderCreated]) error { return nil })
+	newServer(t, failing)
+	require.EqualError(t, failing.Start(context.Background()), "subscribe to orders.created: connection refused")
+}
+
+func TestChannelID(t *testing.T) {
+	require.Equal(t, "ordersCreated", channelID("orders.created"))
+	require.Equal(t, "shopOrdersEu", channelID("shop/orders-eu"))
+	require.Equal(t, "orders", channelID("orders.>"))
+	require.Equal(t, "channel", channelID(">"))
+}
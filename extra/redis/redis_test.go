@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func newStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	store, err := Open("redis://" + server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Stop(context.Background()) })
+	return store, server
+}
+
+func TestRateLimitStore(t *testing.T) {
+	store, server := newStore(t)
+	limits := store.RateLimit()
+	ctx := context.Background()
+
+	ok, remaining, reset, err := limits.Take(ctx, "client", 3, 5, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 2, remaining)
+	require.WithinDuration(t, time.Now().Add(time.Minute), reset, time.Second)
+
+	ok, remaining, _, err = limits.Take(ctx, "client", 3, 5, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, 2, remaining)
+
+	// Another instance shares the same budget
+	other, err := Open("redis://" + server.Addr())
+	require.NoError(t, err)
+	ok, remaining, _, err = other.RateLimit().Take(ctx, "client", 2, 5, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 0, remaining)
+
+	// The budget is restored after the window
+	server.FastForward(time.Minute)
+	ok, remaining, _, err = limits.Take(ctx, "client", 1, 5, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 4, remaining)
+	require.Equal(t, "1", mustGet(t, server, "fuego:ratelimit:client"))
+}
+
+func TestRateLimitStoreWithServer(t *testing.T) {
+	store, _ := newStore(t)
+	s := fuego.NewServer(
+		fuego.WithoutLogger(),
+		fuego.WithRateLimit(fuego.RateLimitConfig{Limit: 1, Store: store.RateLimit()}),
+	)
+	fuego.Get(s, "/", func(c fuego.ContextNoBody) (string, error) { return "ok", nil })
+
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestCacheStore(t *testing.T) {
+	store, server := newStore(t)
+	responses := store.Cache(time.Minute)
+
+	_, found := responses.Get("/recipes")
+	require.False(t, found)
+
+	responses.Set("/recipes", "[]")
+	value, found := responses.Get("/recipes")
+	require.True(t, found)
+	require.Equal(t, "[]", value)
+	require.Equal(t, time.Minute, server.TTL("fuego:cache:/recipes"))
+
+	server.FastForward(time.Minute)
+	_, found = responses.Get("/recipes")
+	require.False(t, found)
+
+	t.Run("redis errors are cache misses", func(t *testing.T) {
+		server.Close()
+		_, found := responses.Get("/recipes")
+		require.False(t, found)
+	})
+}
+
+func TestStoreLifecycle(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := New(redis.NewClient(&redis.Options{Addr: server.Addr()}), Config{Prefix: "app:"})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	s := fuego.NewServer(fuego.WithoutLogger(), fuego.WithListener(listener), fuego.WithoutStartupMessages(), fuego.WithPlugins(store),
+		fuego.WithEngineOptions(fuego.WithOpenAPIConfig(fuego.OpenAPIConfig{DisableLocalSave: true})),
+	)
+
+	go s.Run()
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/swagger/openapi.json")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	store.Cache(time.Minute).Set("key", "value")
+	require.Equal(t, "value", mustGet(t, server, "app:cache:key"))
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	require.ErrorIs(t, store.Client().Ping(context.Background()).Err(), redis.ErrClosed)
+}
+
+func TestStart(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := Open("redis://" + server.Addr())
+	require.NoError(t, err)
+	require.NoError(t, store.Start(context.Background()))
+
+	server.Close()
+	require.ErrorContains(t, store.Start(context.Background()), "ping redis")
+
+	_, err = Open("http://localhost")
+	require.ErrorContains(t, err, "parse redis url")
+}
+
+func mustGet(t *testing.T, server *miniredis.Miniredis, key string) string {
+	t.Helper()
+	value, err := server.Get(key)
+	require.NoError(t, err)
+	return value
+}
@@ -0,0 +1,462 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed filter of the resources (RFC 7644, section 3.4.2.2), given to the [Provider]
+// to be translated into a query, or evaluated with [Match].
+// It is an [AttributeExpression], a [LogicalExpression], a [NotExpression] or a [ValuePathExpression].
+type Filter interface {
+	filter()
+}
+
+// AttributeExpression compares an attribute with a value, like `userName eq "alice"`.
+type AttributeExpression struct {
+	// Path of the attribute, without the schema URN, like "userName" or "emails.value".
+	Path string
+	// Lowercase operator: eq, ne, co, sw, ew, gt, ge, lt, le or pr (present).
+	Operator string
+	// Value compared: a string, a float64, a bool or nil. Nil for the pr operator.
+	Value any
+}
+
+// LogicalExpression combines two filters, like `active eq true and userName sw "a"`.
+type LogicalExpression struct {
+	// Lowercase operator: and, or.
+	Operator    string
+	Left, Right Filter
+}
+
+// NotExpression negates a filter, like `not (userName eq "alice")`.
+type NotExpression struct {
+	Filter Filter
+}
+
+// ValuePathExpression filters the values of a multi-valued attribute, like `emails[type eq "work"]`.
+// It matches the resources with at least one matching value.
+type ValuePathExpression struct {
+	Path   string
+	Filter Filter
+}
+
+func (AttributeExpression) filter() {}
+func (LogicalExpression) filter()   {}
+func (NotExpression) filter()       {}
+func (ValuePathExpression) filter() {}
+
+var comparisonOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// ParseFilter parses a filter. The errors are [Error] with the invalidFilter type.
+func ParseFilter(filter string) (Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, invalidFilter(err.Error())
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, invalidFilter("unexpected " + p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+func invalidFilter(detail string) *Error {
+	return &Error{Status: 400, ScimType: "invalidFilter", Detail: "invalid filter: " + detail}
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenPunctuation
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(filter string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(filter); {
+		switch c := filter[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, token{kind: tokenPunctuation, text: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(filter) && filter[end] != '"'; end++ {
+				if filter[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(filter) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			var value string
+			if err := json.Unmarshal([]byte(filter[i:end+1]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string %s", filter[i:end+1])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value})
+			i = end + 1
+		default:
+			end := i
+			for end < len(filter) && !strings.ContainsRune(" \t\n()[]\"", rune(filter[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: filter[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) next() (token, error) {
+	t, ok := p.peek()
+	if !ok {
+		return token{}, invalidFilter("unexpected end")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) expect(punctuation string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != tokenPunctuation || t.text != punctuation {
+		return invalidFilter("expected " + punctuation + ", got " + t.text)
+	}
+	return nil
+}
+
+// peekKeyword reports whether the next token is the keyword, case-insensitively.
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t, ok := p.peek()
+	return ok && t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpression{Operator: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = LogicalExpression{Operator: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpression{Filter: f}, p.expect(")")
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == tokenPunctuation && t.text == "(" {
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return f, p.expect(")")
+	}
+	if t.kind != tokenWord {
+		return nil, invalidFilter("expected an attribute, got " + t.text)
+	}
+	path := trimSchema(t.text)
+
+	if next, ok := p.peek(); ok && next.kind == tokenPunctuation && next.text == "[" {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return ValuePathExpression{Path: path, Filter: f}, p.expect("]")
+	}
+
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(operator.text)
+	if operator.kind == tokenWord && op == "pr" {
+		return AttributeExpression{Path: path, Operator: op}, nil
+	}
+	if operator.kind != tokenWord || !comparisonOperators[op] {
+		return nil, invalidFilter("unknown operator " + operator.text)
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	compared, err := parseValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return AttributeExpression{Path: path, Operator: op, Value: compared}, nil
+}
+
+func parseValue(t token) (any, error) {
+	if t.kind == tokenString {
+		return t.text, nil
+	}
+	if t.kind != tokenWord {
+		return nil, invalidFilter("expected a value, got " + t.text)
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, invalidFilter("invalid value " + t.text)
+	}
+	return number, nil
+}
+
+// trimSchema removes the schema URN of the attribute path,
+// like "urn:ietf:params:scim:schemas:core:2.0:User:userName", keeping the colons of its value filter.
+func trimSchema(path string) string {
+	attribute := path
+	if open := strings.Index(path, "["); open >= 0 {
+		attribute = path[:open]
+	}
+	if i := strings.LastIndex(attribute, ":"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// Match reports whether the resource, like a [User] or a [Group], matches the filter.
+// The attribute names and the strings are compared case-insensitively.
+func Match(filter Filter, resource any) bool {
+	if filter == nil {
+		return true
+	}
+	value, err := toJSONValue(resource)
+	if err != nil {
+		return false
+	}
+	return match(filter, value)
+}
+
+// toJSONValue converts the value to its generic JSON representation.
+func toJSONValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	err = json.Unmarshal(b, &value)
+	return value, err
+}
+
+func match(filter Filter, value any) bool {
+	switch f := filter.(type) {
+	case LogicalExpression:
+		if f.Operator == "and" {
+			return match(f.Left, value) && match(f.Right, value)
+		}
+		return match(f.Left, value) || match(f.Right, value)
+	case NotExpression:
+		return !match(f.Filter, value)
+	case ValuePathExpression:
+		for _, element := range lookup(value, f.Path) {
+			if match(f.Filter, element) {
+				return true
+			}
+		}
+		return false
+	case AttributeExpression:
+		values := lookup(value, f.Path)
+		if f.Operator == "pr" {
+			for _, v := range values {
+				if present(v) {
+					return true
+				}
+			}
+			return false
+		}
+		if f.Operator == "ne" {
+			return !match(AttributeExpression{Path: f.Path, Operator: "eq", Value: f.Value}, value)
+		}
+		for _, v := range values {
+			if compare(v, f.Operator, f.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// lookup returns the values of the attribute path. The multi-valued attributes are flattened,
+// and the complex values are compared by their "value" sub-attribute.
+func lookup(value any, path string) []any {
+	values := []any{value}
+	for _, name := range strings.Split(path, ".") {
+		var next []any
+		for _, v := range flatten(values) {
+			object, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			if attribute, ok := getAttribute(object, name); ok {
+				next = append(next, attribute)
+			}
+		}
+		values = next
+	}
+	return flatten(values)
+}
+
+func flatten(values []any) []any {
+	var flat []any
+	for _, v := range values {
+		if array, ok := v.([]any); ok {
+			flat = append(flat, array...)
+		} else {
+			flat = append(flat, v)
+		}
+	}
+	return flat
+}
+
+// getAttribute returns the attribute of the object, matching its name case-insensitively.
+func getAttribute(object map[string]any, name string) (any, bool) {
+	if v, ok := object[name]; ok {
+		return v, true
+	}
+	for key, v := range object {
+		if strings.EqualFold(key, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func present(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case map[string]any:
+		return len(v) > 0
+	}
+	return true
+}
+
+func compare(v any, operator string, expected any) bool {
+	if object, ok := v.(map[string]any); ok {
+		v, _ = getAttribute(object, "value")
+	}
+	switch expected := expected.(type) {
+	case nil:
+		return operator == "eq" && v == nil
+	case bool:
+		actual, ok := v.(bool)
+		return ok && operator == "eq" && actual == expected
+	case float64:
+		actual, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		return compareOrdered(actual, expected, operator)
+	case string:
+		actual, ok := v.(string)
+		if !ok {
+			return false
+		}
+		actual, expected = strings.ToLower(actual), strings.ToLower(expected)
+		switch operator {
+		case "co":
+			return strings.Contains(actual, expected)
+		case "sw":
+			return strings.HasPrefix(actual, expected)
+		case "ew":
+			return strings.HasSuffix(actual, expected)
+		}
+		return compareOrdered(actual, expected, operator)
+	}
+	return false
+}
+
+func compareOrdered[T float64 | string](actual, expected T, operator string) bool {
+	switch operator {
+	case "eq":
+		return actual == expected
+	case "gt":
+		return actual > expected
+	case "ge":
+		return actual >= expected
+	case "lt":
+		return actual < expected
+	case "le":
+		return actual <= expected
+	}
+	return false
+}
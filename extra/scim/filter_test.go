@@ -0,0 +1,118 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Run("attribute expression", func(t *testing.T) {
+		filter, err := ParseFilter(`userName eq "alice"`)
+		require.NoError(t, err)
+		require.Equal(t, AttributeExpression{Path: "userName", Operator: "eq", Value: "alice"}, filter)
+	})
+
+	t.Run("precedence of and over or", func(t *testing.T) {
+		filter, err := ParseFilter(`active eq true or userName sw "a" AND name.familyName pr`)
+		require.NoError(t, err)
+		require.Equal(t, LogicalExpression{
+			Operator: "or",
+			Left:     AttributeExpression{Path: "active", Operator: "eq", Value: true},
+			Right: LogicalExpression{
+				Operator: "and",
+				Left:     AttributeExpression{Path: "userName", Operator: "sw", Value: "a"},
+				Right:    AttributeExpression{Path: "name.familyName", Operator: "pr"},
+			},
+		}, filter)
+	})
+
+	t.Run("not, parentheses and value path", func(t *testing.T) {
+		filter, err := ParseFilter(`not (emails[type eq "work" and value ew "@example.com"]) and (meta.version gt 2)`)
+		require.NoError(t, err)
+		require.Equal(t, LogicalExpression{
+			Operator: "and",
+			Left: NotExpression{Filter: ValuePathExpression{Path: "emails", Filter: LogicalExpression{
+				Operator: "and",
+				Left:     AttributeExpression{Path: "type", Operator: "eq", Value: "work"},
+				Right:    AttributeExpression{Path: "value", Operator: "ew", Value: "@example.com"},
+			}}},
+			Right: AttributeExpression{Path: "meta.version", Operator: "gt", Value: 2.0},
+		}, filter)
+	})
+
+	t.Run("schema URN", func(t *testing.T) {
+		filter, err := ParseFilter(`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "a\"b"`)
+		require.NoError(t, err)
+		require.Equal(t, AttributeExpression{Path: "userName", Operator: "eq", Value: `a"b`}, filter)
+	})
+
+	for _, invalid := range []string{
+		``,
+		`userName`,
+		`userName is "alice"`,
+		`userName eq "alice`,
+		`userName eq alice`,
+		`(userName eq "alice"`,
+		`userName eq "alice" extra`,
+		`emails[type eq "work"`,
+	} {
+		t.Run("invalid "+invalid, func(t *testing.T) {
+			_, err := ParseFilter(invalid)
+			var scimError *Error
+			require.ErrorAs(t, err, &scimError)
+			require.Equal(t, 400, scimError.Status)
+			require.Equal(t, "invalidFilter", scimError.ScimType)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	user := User{
+		Resource:    Resource{ID: "1", ExternalID: "ext-1"},
+		UserName:    "Alice",
+		Name:        &Name{GivenName: "Alice", FamilyName: "Liddell"},
+		Active:      true,
+		Emails:      []Email{{Value: "alice@example.com", Type: "work"}, {Value: "alice@home.org", Type: "home"}},
+		Groups:      []Member{{Value: "admins"}},
+		DisplayName: "",
+	}
+
+	tests := map[string]bool{
+		`userName eq "alice"`:                                true,
+		`username EQ "ALICE"`:                                true,
+		`userName ne "alice"`:                                false,
+		`userName ne "bob"`:                                  true,
+		`userName co "lic"`:                                  true,
+		`userName sw "al"`:                                   true,
+		`userName ew "ce"`:                                   true,
+		`userName gt "aa"`:                                   true,
+		`userName lt "aa"`:                                   false,
+		`active eq true`:                                     true,
+		`active eq false`:                                    false,
+		`name.familyName eq "liddell"`:                       true,
+		`displayName pr`:                                     false,
+		`name pr`:                                            true,
+		`emails.value ew "@home.org"`:                        true,
+		`emails eq "alice@example.com"`:                      true,
+		`emails[type eq "work" and value ew "@home.org"]`:    false,
+		`emails[type eq "home" and value ew "@home.org"]`:    true,
+		`groups eq "admins"`:                                 true,
+		`externalId eq "ext-1" and not (active eq false)`:    true,
+		`userName eq "bob" or name.givenName sw "a"`:         true,
+		`urn:ietf:params:scim:schemas:core:2.0:User:id eq 1`: false,
+		`missing eq "x"`:                                     false,
+		`missing ne "x"`:                                     true,
+	}
+	for filter, expected := range tests {
+		t.Run(filter, func(t *testing.T) {
+			parsed, err := ParseFilter(filter)
+			require.NoError(t, err)
+			require.Equal(t, expected, Match(parsed, user))
+		})
+	}
+
+	t.Run("nil filter", func(t *testing.T) {
+		require.True(t, Match(nil, user))
+	})
+}
@@ -0,0 +1,370 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Config configures a [Handler].
+type Config struct {
+	// Provider stores the resources. Required.
+	Provider Provider
+	// MaxResults is the maximum number of resources per page. Defaults to 100.
+	MaxResults int
+	// BaseURL is the public URL of the SCIM endpoints, like "https://example.com/scim/v2",
+	// used for the locations of the resources. Defaults to the host of the request and the registered path.
+	BaseURL string
+}
+
+// Handler serves the SCIM protocol. Create it with [New] and register its routes with [Handler.Register].
+type Handler struct {
+	config Config
+}
+
+// New returns a SCIM [Handler].
+func New(config Config) *Handler {
+	if config.Provider == nil {
+		panic("scim: a Provider is required")
+	}
+	if config.MaxResults <= 0 {
+		config.MaxResults = 100
+	}
+	return &Handler{config: config}
+}
+
+// Register registers the SCIM routes on the server, under the path:
+//   - GET path/Users lists the users, with the filter, startIndex and count query parameters
+//   - POST path/Users creates a user
+//   - GET, PUT, PATCH and DELETE path/Users/{id} read, replace, modify and delete a user
+//   - the same routes for path/Groups
+//   - GET path/ServiceProviderConfig describes the supported features
+//
+// The options are applied to all the routes, for example to add an authentication middleware.
+func (h *Handler) Register(s *fuego.Server, path string, options ...func(*fuego.BaseRoute)) {
+	path = strings.TrimSuffix(path, "/")
+	options = append(options, fuego.OptionTags("SCIM"))
+
+	users := endpoint[User, *User]{
+		handler: h, name: "User", schema: UserSchema, collection: "/Users",
+		list: h.config.Provider.ListUsers, get: h.config.Provider.GetUser, create: h.config.Provider.CreateUser,
+		replace: h.config.Provider.ReplaceUser, delete: h.config.Provider.DeleteUser,
+		validate: func(user *User) error {
+			if user.UserName == "" {
+				return &Error{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "userName is required"}
+			}
+			user.Groups = nil // read-only
+			return nil
+		},
+	}
+	groups := endpoint[Group, *Group]{
+		handler: h, name: "Group", schema: GroupSchema, collection: "/Groups",
+		list: h.config.Provider.ListGroups, get: h.config.Provider.GetGroup, create: h.config.Provider.CreateGroup,
+		replace: h.config.Provider.ReplaceGroup, delete: h.config.Provider.DeleteGroup,
+		validate: func(group *Group) error {
+			if group.DisplayName == "" {
+				return &Error{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: "displayName is required"}
+			}
+			return nil
+		},
+	}
+	users.register(s, path, options)
+	groups.register(s, path, options)
+
+	fuego.GetStd(s, path+"/ServiceProviderConfig", h.serviceProviderConfig, append(options,
+		fuego.OptionSummary("Get service provider configuration"),
+	)...)
+}
+
+func (h *Handler) serviceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	supported := func(b bool) map[string]any { return map[string]any{"supported": b} }
+	h.send(w, http.StatusOK, map[string]any{
+		"schemas":               []string{ServiceProviderConfigSchema},
+		"patch":                 supported(true),
+		"bulk":                  map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":                map[string]any{"supported": true, "maxResults": h.config.MaxResults},
+		"changePassword":        supported(false),
+		"sort":                  supported(false),
+		"etag":                  supported(false),
+		"authenticationSchemes": []any{},
+	})
+}
+
+// send sends the value with the SCIM content type.
+func (h *Handler) send(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("cannot send SCIM response", "error", err)
+	}
+}
+
+// sendError sends the error as a SCIM [Error].
+func (h *Handler) sendError(w http.ResponseWriter, err error) {
+	var scimError *Error
+	switch {
+	case errors.As(err, &scimError):
+	case errors.Is(err, ErrNotFound):
+		scimError = &Error{Status: http.StatusNotFound, Detail: "resource not found", Err: err}
+	case errors.Is(err, ErrConflict):
+		scimError = &Error{Status: http.StatusConflict, ScimType: "uniqueness", Detail: "resource already exists", Err: err}
+	default:
+		slog.Error("SCIM provider error", "error", err)
+		scimError = &Error{Status: http.StatusInternalServerError, Detail: "internal server error", Err: err}
+	}
+	h.send(w, scimError.Status, scimError)
+}
+
+// resourcePointer is a pointer to a [User] or a [Group].
+type resourcePointer[T any] interface {
+	*T
+	resource() *Resource
+}
+
+// endpoint serves the routes of a resource type.
+type endpoint[T any, PT resourcePointer[T]] struct {
+	handler    *Handler
+	name       string
+	schema     string
+	collection string
+
+	list     func(ctx context.Context, options ListOptions) ([]T, int, error)
+	get      func(ctx context.Context, id string) (T, error)
+	create   func(ctx context.Context, resource T) (T, error)
+	replace  func(ctx context.Context, resource T) (T, error)
+	delete   func(ctx context.Context, id string) error
+	validate func(PT) error
+}
+
+func (e endpoint[T, PT]) register(s *fuego.Server, path string, options []func(*fuego.BaseRoute)) {
+	collection := path + e.collection
+	item := collection + "/{id}"
+	resource := response(http.StatusOK, "The "+strings.ToLower(e.name), *new(T))
+	notFound := errorResponse(http.StatusNotFound, "The "+strings.ToLower(e.name)+" does not exist")
+
+	fuego.GetStd(s, collection, e.serveList, append(options,
+		fuego.OptionSummary("List "+strings.ToLower(e.name)+"s"),
+		fuego.OptionQuery("filter", "Filter of the "+strings.ToLower(e.name)+"s, like userName eq \"alice\" (RFC 7644, section 3.4.2.2)"),
+		fuego.OptionQueryInt("startIndex", "1-based index of the first result", fuego.ParamDefault(1)),
+		fuego.OptionQueryInt("count", "Maximum number of results", fuego.ParamDefault(e.handler.config.MaxResults)),
+		response(http.StatusOK, "Page of "+strings.ToLower(e.name)+"s", ListResponse[T]{}),
+		errorResponse(http.StatusBadRequest, "Invalid filter"),
+	)...)
+	fuego.PostStd(s, collection, e.serveCreate, append(options,
+		fuego.OptionSummary("Create "+strings.ToLower(e.name)),
+		requestBody(s, *new(T)),
+		fuego.OptionDefaultStatusCode(http.StatusCreated),
+		response(http.StatusCreated, "The created "+strings.ToLower(e.name), *new(T)),
+		errorResponse(http.StatusConflict, "The "+strings.ToLower(e.name)+" already exists"),
+	)...)
+	fuego.GetStd(s, item, e.serveGet, append(options,
+		fuego.OptionSummary("Get "+strings.ToLower(e.name)),
+		resource, notFound,
+	)...)
+	fuego.PutStd(s, item, e.serveReplace, append(options,
+		fuego.OptionSummary("Replace "+strings.ToLower(e.name)),
+		requestBody(s, *new(T)),
+		resource, notFound,
+	)...)
+	fuego.PatchStd(s, item, e.servePatch, append(options,
+		fuego.OptionSummary("Modify "+strings.ToLower(e.name)),
+		requestBody(s, PatchRequest{}),
+		resource, notFound,
+		errorResponse(http.StatusBadRequest, "Invalid operation"),
+	)...)
+	fuego.DeleteStd(s, item, e.serveDelete, append(options,
+		fuego.OptionSummary("Delete "+strings.ToLower(e.name)),
+		fuego.OptionDefaultStatusCode(http.StatusNoContent),
+		notFound,
+	)...)
+}
+
+func (e endpoint[T, PT]) serveList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	options := ListOptions{StartIndex: 1, Count: e.handler.config.MaxResults}
+	if filter := query.Get("filter"); filter != "" {
+		parsed, err := ParseFilter(filter)
+		if err != nil {
+			e.handler.sendError(w, err)
+			return
+		}
+		options.Filter = parsed
+	}
+	// Invalid values are interpreted as the closest valid ones (RFC 7644, section 3.4.2.4)
+	if startIndex, err := strconv.Atoi(query.Get("startIndex")); err == nil && startIndex > 1 {
+		options.StartIndex = startIndex
+	}
+	if count, err := strconv.Atoi(query.Get("count")); err == nil && count < options.Count {
+		options.Count = max(count, 0)
+	}
+
+	resources, total, err := e.list(r.Context(), options)
+	if err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	for i := range resources {
+		e.decorate(r, PT(&resources[i]))
+	}
+	if resources == nil {
+		resources = []T{}
+	}
+	e.handler.send(w, http.StatusOK, ListResponse[T]{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   options.StartIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (e endpoint[T, PT]) serveGet(w http.ResponseWriter, r *http.Request) {
+	resource, err := e.get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	e.decorate(r, PT(&resource))
+	e.handler.send(w, http.StatusOK, resource)
+}
+
+func (e endpoint[T, PT]) serveCreate(w http.ResponseWriter, r *http.Request) {
+	var resource T
+	if err := e.decode(r, PT(&resource)); err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	PT(&resource).resource().ID = ""
+
+	created, err := e.create(r.Context(), resource)
+	if err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	e.decorate(r, PT(&created))
+	w.Header().Set("Location", PT(&created).resource().Meta.Location)
+	e.handler.send(w, http.StatusCreated, created)
+}
+
+func (e endpoint[T, PT]) serveReplace(w http.ResponseWriter, r *http.Request) {
+	var resource T
+	if err := e.decode(r, PT(&resource)); err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	e.save(w, r, resource)
+}
+
+func (e endpoint[T, PT]) servePatch(w http.ResponseWriter, r *http.Request) {
+	var patch PatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		e.handler.sendError(w, &Error{Status: http.StatusBadRequest, ScimType: "invalidSyntax", Detail: "invalid JSON body", Err: err})
+		return
+	}
+	resource, err := e.get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	if err := patch.Apply(PT(&resource)); err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	if err := e.validate(PT(&resource)); err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	e.save(w, r, resource)
+}
+
+// save replaces the resource of the request path.
+func (e endpoint[T, PT]) save(w http.ResponseWriter, r *http.Request, resource T) {
+	base := PT(&resource).resource()
+	base.ID = r.PathValue("id")
+	base.Meta = nil
+
+	replaced, err := e.replace(r.Context(), resource)
+	if err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	e.decorate(r, PT(&replaced))
+	e.handler.send(w, http.StatusOK, replaced)
+}
+
+func (e endpoint[T, PT]) serveDelete(w http.ResponseWriter, r *http.Request) {
+	if err := e.delete(r.Context(), r.PathValue("id")); err != nil {
+		e.handler.sendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decode decodes and validates the resource of the request body. The read-only attributes are ignored.
+func (e endpoint[T, PT]) decode(r *http.Request, resource PT) error {
+	if err := json.NewDecoder(r.Body).Decode(resource); err != nil {
+		return &Error{Status: http.StatusBadRequest, ScimType: "invalidSyntax", Detail: "invalid JSON body", Err: err}
+	}
+	resource.resource().Meta = nil
+	return e.validate(resource)
+}
+
+// decorate sets the schema, the resource type and the location of the resource.
+func (e endpoint[T, PT]) decorate(r *http.Request, resource PT) {
+	base := resource.resource()
+	if !slices.Contains(base.Schemas, e.schema) {
+		base.Schemas = append([]string{e.schema}, base.Schemas...)
+	}
+	if base.Meta == nil {
+		base.Meta = &Meta{}
+	}
+	base.Meta.ResourceType = e.name
+	base.Meta.Location = e.baseURL(r) + e.collection + "/" + base.ID
+}
+
+// baseURL returns the URL of the SCIM endpoints, from the configuration or the request.
+func (e endpoint[T, PT]) baseURL(r *http.Request) string {
+	if e.handler.config.BaseURL != "" {
+		return strings.TrimSuffix(e.handler.config.BaseURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	path := r.URL.Path
+	if i := strings.LastIndex(path, e.collection); i >= 0 {
+		path = path[:i]
+	}
+	return scheme + "://" + r.Host + path
+}
+
+// response documents a response of the route, sent as application/scim+json.
+func response(status int, description string, v any) func(*fuego.BaseRoute) {
+	return fuego.OptionAddResponse(status, description, fuego.Response{Type: v, ContentTypes: []string{ContentType}})
+}
+
+// errorResponse documents an error response of the route.
+func errorResponse(status int, description string) func(*fuego.BaseRoute) {
+	return fuego.OptionAddResponse(status, description, fuego.Response{Type: &Error{}, ContentTypes: []string{ContentType}})
+}
+
+// requestBody documents the request body, sent as application/scim+json or application/json.
+func requestBody(s *fuego.Server, v any) func(*fuego.BaseRoute) {
+	return func(r *fuego.BaseRoute) {
+		tag := fuego.SchemaTagFromType(s.OpenAPI, v)
+		r.Operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().
+				WithRequired(true).
+				WithContent(openapi3.NewContentWithSchemaRef(&tag.SchemaRef, []string{ContentType, "application/json"})),
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func newTestServer(t *testing.T, config Config) (*fuego.Server, *InMemoryProvider) {
+	t.Helper()
+	provider := NewInMemoryProvider()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	config.Provider = provider
+
+	s := fuego.NewServer(fuego.WithoutLogger())
+	New(config).Register(s, "/scim/v2")
+	return s, provider
+}
+
+func scimRequest(t *testing.T, s *fuego.Server, method, path, body string, response any) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", ContentType)
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+	if response != nil {
+		require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), response), w.Body.String())
+	}
+	return w
+}
+
+func TestUsers(t *testing.T) {
+	s, _ := newTestServer(t, Config{})
+
+	var alice User
+	w := scimRequest(t, s, http.MethodPost, "/scim/v2/Users", `{
+		"schemas": ["`+UserSchema+`"],
+		"userName": "alice",
+		"name": {"givenName": "Alice"},
+		"emails": [{"value": "alice@example.com", "type": "work"}],
+		"active": true
+	}`, &alice)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotEmpty(t, alice.ID)
+	require.Equal(t, []string{UserSchema}, alice.Schemas)
+	require.Equal(t, "User", alice.Meta.ResourceType)
+	require.Equal(t, "http://example.com/scim/v2/Users/"+alice.ID, alice.Meta.Location)
+	require.Equal(t, alice.Meta.Location, w.Header().Get("Location"))
+	require.False(t, alice.Meta.Created.IsZero())
+
+	t.Run("conflict", func(t *testing.T) {
+		var scimError map[string]any
+		w := scimRequest(t, s, http.MethodPost, "/scim/v2/Users", `{"userName": "ALICE"}`, &scimError)
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Equal(t, map[string]any{
+			"schemas":  []any{ErrorSchema},
+			"status":   "409",
+			"scimType": "uniqueness",
+			"detail":   "resource already exists",
+		}, scimError)
+	})
+
+	t.Run("missing userName", func(t *testing.T) {
+		var scimError map[string]any
+		w := scimRequest(t, s, http.MethodPost, "/scim/v2/Users", `{"displayName": "Bob"}`, &scimError)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "invalidValue", scimError["scimType"])
+	})
+
+	t.Run("get", func(t *testing.T) {
+		var user User
+		w := scimRequest(t, s, http.MethodGet, "/scim/v2/Users/"+alice.ID, "", &user)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, alice, user)
+
+		w = scimRequest(t, s, http.MethodGet, "/scim/v2/Users/unknown", "", &map[string]any{})
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		var user User
+		w := scimRequest(t, s, http.MethodPut, "/scim/v2/Users/"+alice.ID, `{
+			"id": "ignored",
+			"userName": "alice",
+			"displayName": "Alice Liddell",
+			"active": true
+		}`, &user)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, alice.ID, user.ID)
+		require.Equal(t, "Alice Liddell", user.DisplayName)
+		require.Empty(t, user.Emails)
+		require.Equal(t, alice.Meta.Created, user.Meta.Created)
+		require.True(t, user.Meta.LastModified.After(alice.Meta.LastModified))
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		var user User
+		w := scimRequest(t, s, http.MethodPatch, "/scim/v2/Users/"+alice.ID, `{
+			"schemas": ["`+PatchOpSchema+`"],
+			"Operations": [{"op": "replace", "path": "active", "value": false}]
+		}`, &user)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.False(t, user.Active)
+		require.Equal(t, "Alice Liddell", user.DisplayName)
+
+		var scimError map[string]any
+		w = scimRequest(t, s, http.MethodPatch, "/scim/v2/Users/"+alice.ID, `{
+			"Operations": [{"op": "remove", "path": "userName"}]
+		}`, &scimError)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "invalidValue", scimError["scimType"])
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := scimRequest(t, s, http.MethodDelete, "/scim/v2/Users/"+alice.ID, "", nil)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = scimRequest(t, s, http.MethodDelete, "/scim/v2/Users/"+alice.ID, "", &map[string]any{})
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestListUsers(t *testing.T) {
+	s, _ := newTestServer(t, Config{MaxResults: 3, BaseURL: "https://example.com/scim/v2/"})
+	for _, user := range []string{
+		`{"userName": "alice", "active": true}`,
+		`{"userName": "bob", "active": false}`,
+		`{"userName": "carol", "active": true}`,
+		`{"userName": "dave", "active": true}`,
+		`{"userName": "erin", "active": true}`,
+	} {
+		w := scimRequest(t, s, http.MethodPost, "/scim/v2/Users", user, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	userNames := func(response ListResponse[User]) []string {
+		var names []string
+		for _, user := range response.Resources {
+			names = append(names, user.UserName)
+		}
+		return names
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		var response ListResponse[User]
+		w := scimRequest(t, s, http.MethodGet, "/scim/v2/Users", "", &response)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []string{ListResponseSchema}, response.Schemas)
+		require.Equal(t, 5, response.TotalResults)
+		require.Equal(t, 1, response.StartIndex)
+		require.Equal(t, 3, response.ItemsPerPage)
+		require.Equal(t, []string{"alice", "bob", "carol"}, userNames(response))
+		require.True(t, strings.HasPrefix(response.Resources[0].Meta.Location, "https://example.com/scim/v2/Users/"))
+
+		scimRequest(t, s, http.MethodGet, "/scim/v2/Users?startIndex=4&count=10", "", &response)
+		require.Equal(t, 4, response.StartIndex)
+		require.Equal(t, []string{"dave", "erin"}, userNames(response))
+
+		scimRequest(t, s, http.MethodGet, "/scim/v2/Users?startIndex=0&count=-1", "", &response)
+		require.Equal(t, 1, response.StartIndex)
+		require.Equal(t, 5, response.TotalResults)
+		require.Equal(t, 0, response.ItemsPerPage)
+		require.NotNil(t, response.Resources)
+
+		scimRequest(t, s, http.MethodGet, "/scim/v2/Users?startIndex=10", "", &response)
+		require.Empty(t, response.Resources)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		var response ListResponse[User]
+		w := scimRequest(t, s, http.MethodGet, "/scim/v2/Users?filter="+`active+eq+true+and+userName+gt+"b"`, "", &response)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, 3, response.TotalResults)
+		require.Equal(t, []string{"carol", "dave", "erin"}, userNames(response))
+
+		var scimError map[string]any
+		w = scimRequest(t, s, http.MethodGet, "/scim/v2/Users?filter=userName+is+alice", "", &scimError)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, "invalidFilter", scimError["scimType"])
+	})
+}
+
+func TestGroups(t *testing.T) {
+	s, _ := newTestServer(t, Config{})
+
+	var alice, bob User
+	scimRequest(t, s, http.MethodPost, "/scim/v2/Users", `{"userName": "alice", "displayName": "Alice"}`, &alice)
+	scimRequest(t, s, http.MethodPost, "/scim/v2/Users", `{"userName": "bob"}`, &bob)
+
+	var admins Group
+	w := scimRequest(t, s, http.MethodPost, "/scim/v2/Groups", `{
+		"displayName": "Admins",
+		"members": [{"value": "`+alice.ID+`"}]
+	}`, &admins)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, []string{GroupSchema}, admins.Schemas)
+	require.Equal(t, "Group", admins.Meta.ResourceType)
+	require.Equal(t, []Member{{Value: alice.ID, Display: "Alice"}}, admins.Members)
+
+	t.Run("groups of the users", func(t *testing.T) {
+		var user User
+		scimRequest(t, s, http.MethodGet, "/scim/v2/Users/"+alice.ID, "", &user)
+		require.Equal(t, []Member{{Value: admins.ID, Display: "Admins"}}, user.Groups)
+
+		var response ListResponse[User]
+		scimRequest(t, s, http.MethodGet, "/scim/v2/Users?filter=groups+eq+"+`"`+admins.ID+`"`, "", &response)
+		require.Equal(t, 1, response.TotalResults)
+		require.Equal(t, alice.ID, response.Resources[0].ID)
+	})
+
+	t.Run("patch members", func(t *testing.T) {
+		var group Group
+		w := scimRequest(t, s, http.MethodPatch, "/scim/v2/Groups/"+admins.ID, `{
+			"schemas": ["`+PatchOpSchema+`"],
+			"Operations": [
+				{"op": "add", "path": "members", "value": [{"value": "`+bob.ID+`"}]},
+				{"op": "remove", "path": "members[value eq \"`+alice.ID+`\"]"}
+			]
+		}`, &group)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []Member{{Value: bob.ID, Display: "bob"}}, group.Members)
+	})
+
+	t.Run("deleted users leave their groups", func(t *testing.T) {
+		scimRequest(t, s, http.MethodDelete, "/scim/v2/Users/"+bob.ID, "", nil)
+
+		var group Group
+		scimRequest(t, s, http.MethodGet, "/scim/v2/Groups/"+admins.ID, "", &group)
+		require.Empty(t, group.Members)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		w := scimRequest(t, s, http.MethodPost, "/scim/v2/Groups", `{"displayName": "admins"}`, &map[string]any{})
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := scimRequest(t, s, http.MethodDelete, "/scim/v2/Groups/"+admins.ID, "", nil)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		var user User
+		scimRequest(t, s, http.MethodGet, "/scim/v2/Users/"+alice.ID, "", &user)
+		require.Empty(t, user.Groups)
+	})
+}
+
+func TestServiceProviderConfig(t *testing.T) {
+	s, _ := newTestServer(t, Config{MaxResults: 50})
+
+	var config map[string]any
+	w := scimRequest(t, s, http.MethodGet, "/scim/v2/ServiceProviderConfig", "", &config)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []any{ServiceProviderConfigSchema}, config["schemas"])
+	require.Equal(t, map[string]any{"supported": true}, config["patch"])
+	require.Equal(t, map[string]any{"supported": true, "maxResults": 50.0}, config["filter"])
+	require.Equal(t, map[string]any{"supported": false}, config["sort"])
+}
+
+func TestOpenAPI(t *testing.T) {
+	s, _ := newTestServer(t, Config{})
+	spec := s.OutputOpenAPISpec()
+
+	users := spec.Paths.Find("/scim/v2/Users")
+	require.NotNil(t, users)
+	require.Equal(t, []string{"SCIM"}, users.Get.Tags)
+	require.NotNil(t, users.Get.Parameters.GetByInAndName("query", "filter"))
+	require.NotNil(t, users.Post.RequestBody.Value.Content.Get(ContentType))
+	require.NotNil(t, users.Post.Responses.Status(http.StatusCreated).Value.Content.Get(ContentType))
+
+	group := spec.Paths.Find("/scim/v2/Groups/{id}")
+	require.NotNil(t, group)
+	require.NotNil(t, group.Patch)
+	require.NotNil(t, group.Patch.Responses.Status(http.StatusNotFound))
+	require.NotNil(t, spec.Paths.Find("/scim/v2/ServiceProviderConfig"))
+}
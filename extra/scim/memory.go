@@ -0,0 +1,276 @@
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InMemoryProvider is a [Provider] for a single instance of the server, mostly for the tests and the prototypes:
+// the resources are lost on restart.
+type InMemoryProvider struct {
+	mu     sync.RWMutex
+	users  map[string]User
+	groups map[string]Group
+	now    func() time.Time
+}
+
+var _ Provider = &InMemoryProvider{}
+
+// NewInMemoryProvider creates an empty [InMemoryProvider].
+func NewInMemoryProvider() *InMemoryProvider {
+	return &InMemoryProvider{users: map[string]User{}, groups: map[string]Group{}, now: time.Now}
+}
+
+func (p *InMemoryProvider) ListUsers(_ context.Context, options ListOptions) ([]User, int, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	users := make([]User, 0, len(p.users))
+	for _, user := range p.users {
+		users = append(users, p.withGroups(user))
+	}
+	page, total := paginate(users, options)
+	return page, total, nil
+}
+
+func (p *InMemoryProvider) GetUser(_ context.Context, id string) (User, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	user, ok := p.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return p.withGroups(user), nil
+}
+
+func (p *InMemoryProvider) CreateUser(_ context.Context, user User) (User, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.userNameTaken(user.UserName, "") {
+		return User{}, ErrConflict
+	}
+	user.ID = newID()
+	user.Meta = p.meta(nil)
+	user.Groups = nil
+	p.users[user.ID] = cloneUser(user)
+	return p.withGroups(user), nil
+}
+
+func (p *InMemoryProvider) ReplaceUser(_ context.Context, user User) (User, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	existing, ok := p.users[user.ID]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	if p.userNameTaken(user.UserName, user.ID) {
+		return User{}, ErrConflict
+	}
+	user.Meta = p.meta(existing.Meta)
+	user.Groups = nil
+	p.users[user.ID] = cloneUser(user)
+	p.renameMember(user.ID, user.DisplayName)
+	return p.withGroups(user), nil
+}
+
+func (p *InMemoryProvider) DeleteUser(_ context.Context, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(p.users, id)
+	for groupID, group := range p.groups {
+		group.Members = slices.DeleteFunc(group.Members, func(m Member) bool { return m.Value == id })
+		p.groups[groupID] = group
+	}
+	return nil
+}
+
+func (p *InMemoryProvider) ListGroups(_ context.Context, options ListOptions) ([]Group, int, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	groups := make([]Group, 0, len(p.groups))
+	for _, group := range p.groups {
+		groups = append(groups, cloneGroup(group))
+	}
+	page, total := paginate(groups, options)
+	return page, total, nil
+}
+
+func (p *InMemoryProvider) GetGroup(_ context.Context, id string) (Group, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	group, ok := p.groups[id]
+	if !ok {
+		return Group{}, ErrNotFound
+	}
+	return cloneGroup(group), nil
+}
+
+func (p *InMemoryProvider) CreateGroup(_ context.Context, group Group) (Group, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.displayNameTaken(group.DisplayName, "") {
+		return Group{}, ErrConflict
+	}
+	group.ID = newID()
+	group.Meta = p.meta(nil)
+	group.Members = p.members(group.Members)
+	p.groups[group.ID] = cloneGroup(group)
+	return group, nil
+}
+
+func (p *InMemoryProvider) ReplaceGroup(_ context.Context, group Group) (Group, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	existing, ok := p.groups[group.ID]
+	if !ok {
+		return Group{}, ErrNotFound
+	}
+	if p.displayNameTaken(group.DisplayName, group.ID) {
+		return Group{}, ErrConflict
+	}
+	group.Meta = p.meta(existing.Meta)
+	group.Members = p.members(group.Members)
+	p.groups[group.ID] = cloneGroup(group)
+	return group, nil
+}
+
+func (p *InMemoryProvider) DeleteGroup(_ context.Context, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.groups[id]; !ok {
+		return ErrNotFound
+	}
+	delete(p.groups, id)
+	return nil
+}
+
+// userNameTaken reports whether another user than the given ID has the userName, case-insensitively.
+func (p *InMemoryProvider) userNameTaken(userName, id string) bool {
+	for _, user := range p.users {
+		if user.ID != id && strings.EqualFold(user.UserName, userName) {
+			return true
+		}
+	}
+	return false
+}
+
+// displayNameTaken reports whether another group than the given ID has the displayName, case-insensitively.
+func (p *InMemoryProvider) displayNameTaken(displayName, id string) bool {
+	for _, group := range p.groups {
+		if group.ID != id && strings.EqualFold(group.DisplayName, displayName) {
+			return true
+		}
+	}
+	return false
+}
+
+// meta returns the metadata of a created or replaced resource.
+func (p *InMemoryProvider) meta(existing *Meta) *Meta {
+	now := p.now().UTC()
+	if existing == nil {
+		return &Meta{Created: now, LastModified: now}
+	}
+	return &Meta{Created: existing.Created, LastModified: now}
+}
+
+// members removes the duplicated members, and fills their display name from the users.
+func (p *InMemoryProvider) members(members []Member) []Member {
+	var unique []Member
+	for _, member := range members {
+		if slices.ContainsFunc(unique, func(m Member) bool { return m.Value == member.Value }) {
+			continue
+		}
+		if user, ok := p.users[member.Value]; ok && member.Display == "" {
+			member.Display = displayName(user)
+		}
+		unique = append(unique, member)
+	}
+	return unique
+}
+
+// renameMember updates the display name of the user in its groups.
+func (p *InMemoryProvider) renameMember(id, display string) {
+	if display == "" {
+		display = p.users[id].UserName
+	}
+	for _, group := range p.groups {
+		for i, member := range group.Members {
+			if member.Value == id {
+				group.Members[i].Display = display
+			}
+		}
+	}
+}
+
+// withGroups returns a copy of the user, with the groups it is a member of.
+func (p *InMemoryProvider) withGroups(user User) User {
+	user = cloneUser(user)
+	for _, group := range p.groups {
+		if slices.ContainsFunc(group.Members, func(m Member) bool { return m.Value == user.ID }) {
+			user.Groups = append(user.Groups, Member{Value: group.ID, Display: group.DisplayName})
+		}
+	}
+	slices.SortFunc(user.Groups, func(a, b Member) int { return strings.Compare(a.Display, b.Display) })
+	return user
+}
+
+func displayName(user User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.UserName
+}
+
+// paginate filters the resources, sorts them by creation date, and returns the page and the number of matches.
+func paginate[T any, PT resourcePointer[T]](resources []T, options ListOptions) ([]T, int) {
+	resources = slices.DeleteFunc(resources, func(resource T) bool { return !Match(options.Filter, resource) })
+	slices.SortFunc(resources, func(a, b T) int {
+		metaA, metaB := PT(&a).resource().Meta, PT(&b).resource().Meta
+		if c := metaA.Created.Compare(metaB.Created); c != 0 {
+			return c
+		}
+		return strings.Compare(PT(&a).resource().ID, PT(&b).resource().ID)
+	})
+	total := len(resources)
+	start := min(max(options.StartIndex, 1)-1, total)
+	end := min(start+options.Count, total)
+	return resources[start:end], total
+}
+
+func cloneUser(user User) User {
+	user.Schemas = slices.Clone(user.Schemas)
+	user.Emails = slices.Clone(user.Emails)
+	user.Groups = slices.Clone(user.Groups)
+	if user.Name != nil {
+		name := *user.Name
+		user.Name = &name
+	}
+	if user.Meta != nil {
+		meta := *user.Meta
+		user.Meta = &meta
+	}
+	return user
+}
+
+func cloneGroup(group Group) Group {
+	group.Schemas = slices.Clone(group.Schemas)
+	group.Members = slices.Clone(group.Members)
+	if group.Meta != nil {
+		meta := *group.Meta
+		group.Meta = &meta
+	}
+	return group
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
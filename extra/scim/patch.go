@@ -0,0 +1,240 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// PatchRequest modifies a resource (RFC 7644, section 3.5.2).
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is an operation of a [PatchRequest].
+type PatchOperation struct {
+	// add, remove or replace, case-insensitive.
+	Op string `json:"op"`
+	// Attribute modified, like "displayName", "name.givenName" or `members[value eq "2819c223"]`.
+	// Optional for the add and replace operations, whose value is then an object of attributes.
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Apply applies the operations to the resource, a pointer to a [User] or a [Group].
+// The errors are [Error] with the invalidPath, noTarget or invalidValue types.
+func (p PatchRequest) Apply(resource any) error {
+	value, err := toJSONValue(resource)
+	if err != nil {
+		return err
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return &Error{Status: 400, ScimType: "invalidValue", Detail: "resource is not an object"}
+	}
+
+	for _, operation := range p.Operations {
+		if err := applyOperation(object, operation); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	// Zeroed first, so that the removed attributes are not kept
+	reflect.ValueOf(resource).Elem().SetZero()
+	if err := json.Unmarshal(b, resource); err != nil {
+		return &Error{Status: 400, ScimType: "invalidValue", Detail: "invalid value", Err: err}
+	}
+	return nil
+}
+
+// patchPath is a parsed path of a [PatchOperation]: attribute[filter].subAttribute.
+type patchPath struct {
+	attribute    string
+	filter       Filter
+	subAttribute string
+}
+
+func parsePatchPath(path string) (patchPath, error) {
+	var parsed patchPath
+	path = trimSchema(path)
+	if open := strings.Index(path, "["); open >= 0 {
+		end := strings.LastIndex(path, "]")
+		if end < open {
+			return parsed, invalidPath(path)
+		}
+		filter, err := ParseFilter(path[open+1 : end])
+		if err != nil {
+			return parsed, invalidPath(path)
+		}
+		parsed.attribute = path[:open]
+		parsed.filter = filter
+		parsed.subAttribute = strings.TrimPrefix(path[end+1:], ".")
+	} else {
+		parsed.attribute, parsed.subAttribute, _ = strings.Cut(path, ".")
+	}
+	if parsed.attribute == "" || strings.Contains(parsed.subAttribute, ".") {
+		return parsed, invalidPath(path)
+	}
+	return parsed, nil
+}
+
+func invalidPath(path string) *Error {
+	return &Error{Status: 400, ScimType: "invalidPath", Detail: "invalid path " + path}
+}
+
+func applyOperation(object map[string]any, operation PatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return &Error{Status: 400, ScimType: "invalidSyntax", Detail: "unknown operation " + operation.Op}
+	}
+
+	if operation.Path == "" {
+		if op == "remove" {
+			return &Error{Status: 400, ScimType: "noTarget", Detail: "remove operation without path"}
+		}
+		attributes, ok := operation.Value.(map[string]any)
+		if !ok {
+			return &Error{Status: 400, ScimType: "invalidValue", Detail: "the value of an operation without path must be an object"}
+		}
+		for path, value := range attributes {
+			if err := applyOperation(object, PatchOperation{Op: op, Path: path, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(operation.Path)
+	if err != nil {
+		return err
+	}
+	key := attributeKey(object, path.attribute)
+
+	if path.filter != nil {
+		return applyToValues(object, key, path, op, operation.Value)
+	}
+	if path.subAttribute != "" {
+		parent, ok := object[key].(map[string]any)
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			parent = map[string]any{}
+			object[key] = parent
+		}
+		subKey := attributeKey(parent, path.subAttribute)
+		if op == "remove" {
+			delete(parent, subKey)
+		} else {
+			parent[subKey] = operation.Value
+		}
+		return nil
+	}
+
+	switch op {
+	case "add":
+		if existing, ok := object[key].([]any); ok {
+			object[key] = append(existing, asArray(operation.Value)...)
+		} else {
+			object[key] = operation.Value
+		}
+	case "replace":
+		object[key] = operation.Value
+	case "remove":
+		existing, isArray := object[key].([]any)
+		if !isArray || operation.Value == nil {
+			delete(object, key)
+			return nil
+		}
+		// Removes the given values of the multi-valued attribute, like the members of a group
+		var kept []any
+		for _, element := range existing {
+			if !containsValue(asArray(operation.Value), element) {
+				kept = append(kept, element)
+			}
+		}
+		object[key] = kept
+	}
+	return nil
+}
+
+// applyToValues applies the operation to the values of the multi-valued attribute matching the filter of the path.
+func applyToValues(object map[string]any, key string, path patchPath, op string, value any) error {
+	existing, _ := object[key].([]any)
+	var kept []any
+	matched := false
+	for _, element := range existing {
+		if !match(path.filter, element) {
+			kept = append(kept, element)
+			continue
+		}
+		matched = true
+		if op == "remove" && path.subAttribute == "" {
+			continue
+		}
+		elementObject, ok := element.(map[string]any)
+		if !ok {
+			return &Error{Status: 400, ScimType: "invalidPath", Detail: "the values of " + path.attribute + " have no sub-attributes"}
+		}
+		switch {
+		case op == "remove":
+			delete(elementObject, attributeKey(elementObject, path.subAttribute))
+		case path.subAttribute != "":
+			elementObject[attributeKey(elementObject, path.subAttribute)] = value
+		default:
+			replacement, ok := value.(map[string]any)
+			if !ok {
+				return &Error{Status: 400, ScimType: "invalidValue", Detail: "the value of a filtered path must be an object"}
+			}
+			for k, v := range replacement {
+				elementObject[attributeKey(elementObject, k)] = v
+			}
+		}
+		kept = append(kept, element)
+	}
+	if !matched && op != "remove" {
+		return &Error{Status: 400, ScimType: "noTarget", Detail: "no value matches the path"}
+	}
+	object[key] = kept
+	return nil
+}
+
+// attributeKey returns the key of the attribute in the object, matching its name case-insensitively,
+// or the name if the object has no such attribute.
+func attributeKey(object map[string]any, name string) string {
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+func asArray(value any) []any {
+	if array, ok := value.([]any); ok {
+		return array
+	}
+	return []any{value}
+}
+
+// containsValue reports whether the element is one of the values, compared by their "value" sub-attribute.
+func containsValue(values []any, element any) bool {
+	for _, v := range values {
+		if compare(element, "eq", valueOf(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueOf(v any) any {
+	if object, ok := v.(map[string]any); ok {
+		v, _ = getAttribute(object, "value")
+	}
+	return v
+}
@@ -0,0 +1,110 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func patch(t *testing.T, operations string) PatchRequest {
+	t.Helper()
+	var request PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"schemas":["`+PatchOpSchema+`"],"Operations":`+operations+`}`), &request))
+	return request
+}
+
+func TestPatchApply(t *testing.T) {
+	newUser := func() User {
+		return User{
+			Resource:    Resource{ID: "1"},
+			UserName:    "alice",
+			DisplayName: "Alice",
+			Name:        &Name{GivenName: "Alice"},
+			Active:      true,
+			Emails:      []Email{{Value: "alice@example.com", Type: "work", Primary: true}},
+		}
+	}
+
+	t.Run("replace attributes", func(t *testing.T) {
+		user := newUser()
+		err := patch(t, `[
+			{"op": "Replace", "path": "active", "value": false},
+			{"op": "replace", "path": "name.familyName", "value": "Liddell"},
+			{"op": "replace", "value": {"displayName": "Alice L.", "userName": "alice.l"}}
+		]`).Apply(&user)
+		require.NoError(t, err)
+		require.False(t, user.Active)
+		require.Equal(t, &Name{GivenName: "Alice", FamilyName: "Liddell"}, user.Name)
+		require.Equal(t, "Alice L.", user.DisplayName)
+		require.Equal(t, "alice.l", user.UserName)
+		require.Equal(t, "1", user.ID)
+	})
+
+	t.Run("add values", func(t *testing.T) {
+		user := newUser()
+		err := patch(t, `[{"op": "add", "path": "emails", "value": [{"value": "alice@home.org", "type": "home"}]}]`).Apply(&user)
+		require.NoError(t, err)
+		require.Equal(t, []Email{
+			{Value: "alice@example.com", Type: "work", Primary: true},
+			{Value: "alice@home.org", Type: "home"},
+		}, user.Emails)
+	})
+
+	t.Run("filtered path", func(t *testing.T) {
+		user := newUser()
+		err := patch(t, `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "alice@example.org"}]`).Apply(&user)
+		require.NoError(t, err)
+		require.Equal(t, []Email{{Value: "alice@example.org", Type: "work", Primary: true}}, user.Emails)
+
+		err = patch(t, `[{"op": "remove", "path": "emails[type eq \"work\"]"}]`).Apply(&user)
+		require.NoError(t, err)
+		require.Empty(t, user.Emails)
+	})
+
+	t.Run("remove attributes", func(t *testing.T) {
+		user := newUser()
+		err := patch(t, `[
+			{"op": "remove", "path": "displayName"},
+			{"op": "remove", "path": "name.givenName"}
+		]`).Apply(&user)
+		require.NoError(t, err)
+		require.Empty(t, user.DisplayName)
+		require.Equal(t, &Name{}, user.Name)
+	})
+
+	t.Run("group members", func(t *testing.T) {
+		group := Group{DisplayName: "admins", Members: []Member{{Value: "1"}, {Value: "2"}}}
+		err := patch(t, `[
+			{"op": "add", "path": "members", "value": [{"value": "3"}]},
+			{"op": "remove", "path": "members", "value": [{"value": "1"}]},
+			{"op": "remove", "path": "members[value eq \"2\"]"}
+		]`).Apply(&group)
+		require.NoError(t, err)
+		require.Equal(t, []Member{{Value: "3"}}, group.Members)
+	})
+
+	for name, operations := range map[string]string{
+		"invalidSyntax": `[{"op": "move", "path": "active"}]`,
+		"noTarget":      `[{"op": "replace", "path": "emails[type eq \"home\"].value", "value": "x"}]`,
+		"invalidPath":   `[{"op": "replace", "path": "emails[type eq", "value": "x"}]`,
+		"invalidValue":  `[{"op": "replace", "path": "active", "value": "yes"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			user := newUser()
+			err := patch(t, operations).Apply(&user)
+			var scimError *Error
+			require.ErrorAs(t, err, &scimError)
+			require.Equal(t, 400, scimError.Status)
+			require.Equal(t, name, scimError.ScimType)
+		})
+	}
+
+	t.Run("remove without path", func(t *testing.T) {
+		user := newUser()
+		err := patch(t, `[{"op": "remove"}]`).Apply(&user)
+		var scimError *Error
+		require.ErrorAs(t, err, &scimError)
+		require.Equal(t, "noTarget", scimError.ScimType)
+	})
+}
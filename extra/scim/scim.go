@@ -0,0 +1,176 @@
+// Package scim implements the SCIM 2.0 provisioning protocol (RFC 7643 and RFC 7644) on Fuego routes,
+// so identity providers like Okta or Microsoft Entra ID can create, update and deactivate
+// the users and the groups of the application.
+//
+//	scim.New(scim.Config{Provider: provider}).Register(s, "/scim/v2", option.Middleware(bearerAuth))
+//
+// The resources are stored by a [Provider]: the routes handle the protocol,
+// including the filters, the pagination and the PATCH operations.
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// ContentType of the SCIM requests and responses.
+	ContentType = "application/scim+json"
+
+	// UserSchema is the schema of the [User] resources.
+	UserSchema = "urn:ietf:params:scim:schemas:core:2.0:User"
+	// GroupSchema is the schema of the [Group] resources.
+	GroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	// ListResponseSchema is the schema of the [ListResponse] messages.
+	ListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	// PatchOpSchema is the schema of the [PatchRequest] messages.
+	PatchOpSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	// ErrorSchema is the schema of the [Error] messages.
+	ErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+	// ServiceProviderConfigSchema is the schema of the configuration of the service provider.
+	ServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+var (
+	// ErrNotFound is returned by the [Provider] for unknown resources.
+	ErrNotFound = errors.New("resource not found")
+	// ErrConflict is returned by the [Provider] when a unique attribute, like the userName, is already taken.
+	ErrConflict = errors.New("resource already exists")
+)
+
+// Resource holds the attributes common to all the resources.
+type Resource struct {
+	Schemas    []string `json:"schemas"`
+	ID         string   `json:"id,omitempty"`
+	ExternalID string   `json:"externalId,omitempty"`
+	Meta       *Meta    `json:"meta,omitempty"`
+}
+
+func (r *Resource) resource() *Resource { return r }
+
+// Meta holds the metadata of a resource. Created and LastModified are set by the [Provider],
+// the other attributes by the routes.
+type Meta struct {
+	ResourceType string    `json:"resourceType,omitempty"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+	Version      string    `json:"version,omitempty"`
+}
+
+// User is a user of the application (RFC 7643, section 4.1).
+type User struct {
+	Resource
+	UserName    string  `json:"userName"`
+	Name        *Name   `json:"name,omitempty"`
+	DisplayName string  `json:"displayName,omitempty"`
+	Emails      []Email `json:"emails,omitempty"`
+	Active      bool    `json:"active"`
+	// Groups of the user, read-only: the members are managed through the groups.
+	Groups []Member `json:"groups,omitempty"`
+}
+
+// Name is the name of a [User].
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// Email is an email address of a [User].
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Group is a group of users (RFC 7643, section 4.2).
+type Group struct {
+	Resource
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members,omitempty"`
+}
+
+// Member is a member of a [Group], or a group of a [User].
+type Member struct {
+	// ID of the member.
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// ListResponse is a page of resources.
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+// ListOptions selects the resources listed by the [Provider].
+type ListOptions struct {
+	// Filter of the resources, nil to list them all. See [Match] to evaluate it.
+	Filter Filter
+	// 1-based index of the first resource of the page.
+	StartIndex int
+	// Maximum number of resources of the page.
+	Count int
+}
+
+// Provider stores the users and the groups provisioned by the identity provider.
+type Provider interface {
+	// ListUsers returns the page of the users matching the options, and the total number of matching users.
+	ListUsers(ctx context.Context, options ListOptions) (users []User, total int, err error)
+	// GetUser returns the user, or [ErrNotFound].
+	GetUser(ctx context.Context, id string) (User, error)
+	// CreateUser creates the user and returns it with its ID, or [ErrConflict] if the userName is taken.
+	CreateUser(ctx context.Context, user User) (User, error)
+	// ReplaceUser replaces all the attributes of the user, or returns [ErrNotFound].
+	ReplaceUser(ctx context.Context, user User) (User, error)
+	// DeleteUser deletes the user, or returns [ErrNotFound].
+	DeleteUser(ctx context.Context, id string) error
+
+	// ListGroups returns the page of the groups matching the options, and the total number of matching groups.
+	ListGroups(ctx context.Context, options ListOptions) (groups []Group, total int, err error)
+	// GetGroup returns the group, or [ErrNotFound].
+	GetGroup(ctx context.Context, id string) (Group, error)
+	// CreateGroup creates the group and returns it with its ID, or [ErrConflict] if the displayName is taken.
+	CreateGroup(ctx context.Context, group Group) (Group, error)
+	// ReplaceGroup replaces all the attributes of the group, or returns [ErrNotFound].
+	ReplaceGroup(ctx context.Context, group Group) (Group, error)
+	// DeleteGroup deletes the group, or returns [ErrNotFound].
+	DeleteGroup(ctx context.Context, id string) error
+}
+
+// Error is the error response of the SCIM protocol (RFC 7644, section 3.12).
+type Error struct {
+	Status int
+	// Type of the error of a 400 Bad Request, like "invalidFilter" or "invalidPath".
+	ScimType string
+	Detail   string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Detail + ": " + e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (e *Error) StatusCode() int { return e.Status }
+
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail,omitempty"`
+	}{[]string{ErrorSchema}, strconv.Itoa(e.Status), e.ScimType, e.Detail})
+}
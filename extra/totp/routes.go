@@ -0,0 +1,469 @@
+package totp
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/go-fuego/fuego"
+)
+
+// MethodOTP is the authentication method added to the "amr" claim (RFC 8176) of the tokens
+// of the users who verified a code.
+const MethodOTP = "otp"
+
+// Config configures the two-factor authentication routes. See [Register].
+type Config struct {
+	// Issuer shown in the authenticator apps, usually the name of the application. Required.
+	Issuer string
+	// Store of the enrollments. Required.
+	Store Store
+	// Number of recovery codes given to the users when they confirm their enrollment. Defaults to 10.
+	RecoveryCodes int
+	// Account shown in the authenticator apps. Defaults to the "username" claim, or the "sub" claim.
+	Account func(claims jwt.MapClaims) string
+	// Clock of the codes. Defaults to time.Now.
+	Now func() time.Time
+	// Wrong codes allowed before the user is locked out, to prevent the brute-force of the codes. Defaults to 5.
+	MaxFailedAttempts int
+	// Duration of the lockout. Defaults to 15 minutes.
+	Lockout time.Duration
+}
+
+// EnrollResponse is the secret of a new enrollment, to be saved in an authenticator app.
+type EnrollResponse struct {
+	Secret string `json:"secret"`
+	// otpauth:// URI of the secret, to be shown as a QR code.
+	URI string `json:"uri"`
+}
+
+// CodeRequest is a code of the authenticator app of the user.
+type CodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse lists the recovery codes of the user, shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// VerifyRequest is a code of the authenticator app or a recovery code of the user.
+type VerifyRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recoveryCode,omitempty"`
+}
+
+// TokenResponse is the token of the user after the second factor.
+type TokenResponse struct {
+	Token string `json:"token"`
+}
+
+type routes struct {
+	config   Config
+	security fuego.Security
+	locks    *userLocks
+}
+
+// Register registers the two-factor authentication routes of the logged in users, usually on the auth group:
+//   - POST /totp/enroll starts an enrollment, returning the secret and its provisioning URI
+//   - POST /totp/confirm confirms the enrollment with a first code, returning the recovery codes
+//   - POST /totp/verify verifies a code or a recovery code, returning a new token with the "otp" method
+//   - DELETE /totp removes the enrollment, given a code
+//
+// After [Config.MaxFailedAttempts] wrong codes, the user gets a 429 Too Many Requests error
+// until the end of the [Config.Lockout].
+//
+// The token of the user must be in the context, like with [fuego.WithAutoAuth].
+// The options are applied to all the routes.
+func Register(s *fuego.Server, config Config, options ...func(*fuego.BaseRoute)) {
+	if config.Store == nil {
+		panic("totp: a Store is required")
+	}
+	if config.Issuer == "" {
+		panic("totp: an Issuer is required")
+	}
+	if config.RecoveryCodes <= 0 {
+		config.RecoveryCodes = 10
+	}
+	if config.Account == nil {
+		config.Account = func(claims jwt.MapClaims) string {
+			if username, ok := claims["username"].(string); ok && username != "" {
+				return username
+			}
+			subject, _ := claims.GetSubject()
+			return subject
+		}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	if config.MaxFailedAttempts <= 0 {
+		config.MaxFailedAttempts = 5
+	}
+	if config.Lockout <= 0 {
+		config.Lockout = 15 * time.Minute
+	}
+	rs := routes{config: config, security: s.Security, locks: &userLocks{locks: map[string]*userLock{}}}
+
+	options = append(options, fuego.OptionTags("Auth"))
+	fuego.Post(s, "/totp/enroll", rs.enroll, append(options,
+		fuego.OptionSummary("Enroll two-factor authentication"),
+		fuego.OptionAddError(http.StatusConflict, "Two-factor authentication already enrolled"),
+	)...)
+	fuego.Post(s, "/totp/confirm", rs.confirm, append(options,
+		fuego.OptionSummary("Confirm two-factor authentication"),
+		fuego.OptionAddError(http.StatusNotFound, "Two-factor authentication not enrolled"),
+		fuego.OptionAddError(http.StatusTooManyRequests, "Too many wrong codes"),
+	)...)
+	fuego.Post(s, "/totp/verify", rs.verify, append(options,
+		fuego.OptionSummary("Verify two-factor authentication"),
+		fuego.OptionAddError(http.StatusNotFound, "Two-factor authentication not enrolled"),
+		fuego.OptionAddError(http.StatusTooManyRequests, "Too many wrong codes"),
+	)...)
+	fuego.Delete(s, "/totp", rs.remove, append(options,
+		fuego.OptionSummary("Remove two-factor authentication"),
+		fuego.OptionDefaultStatusCode(http.StatusNoContent),
+		fuego.OptionAddError(http.StatusNotFound, "Two-factor authentication not enrolled"),
+		fuego.OptionAddError(http.StatusTooManyRequests, "Too many wrong codes"),
+	)...)
+}
+
+func (rs routes) enroll(c fuego.ContextNoBody) (EnrollResponse, error) {
+	userID, claims, err := user(c.Context())
+	if err != nil {
+		return EnrollResponse{}, err
+	}
+	unlock, err := rs.lock(c.Context(), userID)
+	if err != nil {
+		return EnrollResponse{}, err
+	}
+	defer unlock()
+
+	enrollment, err := rs.config.Store.Enrollment(c.Context(), userID)
+	if err == nil && enrollment.Confirmed {
+		return EnrollResponse{}, fuego.ConflictError{Title: "Two-factor authentication already enrolled"}
+	} else if err != nil && !errors.Is(err, ErrNotEnrolled) {
+		return EnrollResponse{}, err
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return EnrollResponse{}, err
+	}
+	if err := rs.config.Store.SaveEnrollment(c.Context(), userID, Enrollment{Secret: secret}); err != nil {
+		return EnrollResponse{}, err
+	}
+	return EnrollResponse{
+		Secret: secret,
+		URI:    ProvisioningURI(rs.config.Issuer, rs.config.Account(claims), secret),
+	}, nil
+}
+
+func (rs routes) confirm(c fuego.ContextWithBody[CodeRequest]) (RecoveryCodesResponse, error) {
+	body, err := c.Body()
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+	userID, _, err := user(c.Context())
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+	unlock, err := rs.lock(c.Context(), userID)
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+	defer unlock()
+
+	enrollment, err := rs.enrollment(c.Context(), userID)
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+	if enrollment.Confirmed {
+		return RecoveryCodesResponse{}, fuego.ConflictError{Title: "Two-factor authentication already confirmed"}
+	}
+	err = rs.attempt(c.Context(), userID, &enrollment, func() error {
+		return rs.checkCode(&enrollment, body.Code)
+	})
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+
+	codes, hashes, err := GenerateRecoveryCodes(rs.config.RecoveryCodes)
+	if err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+	enrollment.Confirmed = true
+	enrollment.RecoveryCodes = hashes
+	if err := rs.config.Store.SaveEnrollment(c.Context(), userID, enrollment); err != nil {
+		return RecoveryCodesResponse{}, err
+	}
+	return RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (rs routes) verify(c fuego.ContextWithBody[VerifyRequest]) (TokenResponse, error) {
+	body, err := c.Body()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	userID, claims, err := user(c.Context())
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	unlock, err := rs.lock(c.Context(), userID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	defer unlock()
+
+	enrollment, err := rs.confirmedEnrollment(c.Context(), userID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	err = rs.attempt(c.Context(), userID, &enrollment, func() error {
+		switch {
+		case body.Code != "":
+			return rs.checkCode(&enrollment, body.Code)
+		case body.RecoveryCode != "":
+			if !enrollment.useRecoveryCode(body.RecoveryCode) {
+				return invalidCode(ErrInvalidCode)
+			}
+			return nil
+		default:
+			return fuego.BadRequestError{Title: "Missing code", Detail: "Either code or recoveryCode is required"}
+		}
+	})
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	if err := rs.config.Store.SaveEnrollment(c.Context(), userID, enrollment); err != nil {
+		return TokenResponse{}, err
+	}
+
+	claims = maps.Clone(claims)
+	claims["amr"] = addMethod(claims["amr"], MethodOTP)
+	token, err := rs.security.GenerateTokenToCookies(claims, c.Response())
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return TokenResponse{Token: token}, nil
+}
+
+func (rs routes) remove(c fuego.ContextWithBody[CodeRequest]) (any, error) {
+	body, err := c.Body()
+	if err != nil {
+		return nil, err
+	}
+	userID, _, err := user(c.Context())
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := rs.lock(c.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	enrollment, err := rs.confirmedEnrollment(c.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	err = rs.attempt(c.Context(), userID, &enrollment, func() error {
+		return rs.checkCode(&enrollment, body.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, rs.config.Store.DeleteEnrollment(c.Context(), userID)
+}
+
+// enrollment returns the enrollment of the user, or a 404 error.
+func (rs routes) enrollment(ctx context.Context, userID string) (Enrollment, error) {
+	enrollment, err := rs.config.Store.Enrollment(ctx, userID)
+	if errors.Is(err, ErrNotEnrolled) {
+		return Enrollment{}, fuego.NotFoundError{Title: "Two-factor authentication not enrolled", Err: err}
+	}
+	return enrollment, err
+}
+
+func (rs routes) confirmedEnrollment(ctx context.Context, userID string) (Enrollment, error) {
+	enrollment, err := rs.enrollment(ctx, userID)
+	if err == nil && !enrollment.Confirmed {
+		return Enrollment{}, fuego.NotFoundError{Title: "Two-factor authentication not confirmed", Err: ErrNotEnrolled}
+	}
+	return enrollment, err
+}
+
+// checkCode validates the code of the enrollment, rejecting the codes already used.
+func (rs routes) checkCode(enrollment *Enrollment, code string) error {
+	counter, err := Validate(enrollment.Secret, code, rs.config.Now())
+	if err != nil {
+		return invalidCode(err)
+	}
+	if counter <= enrollment.LastCounter {
+		return invalidCode(ErrInvalidCode)
+	}
+	enrollment.LastCounter = counter
+	return nil
+}
+
+// attempt runs the check of a code of the user, unless locked out. The wrong codes are counted and saved,
+// and lock the user out after [Config.MaxFailedAttempts]. The caller saves the enrollment on success.
+func (rs routes) attempt(ctx context.Context, userID string, enrollment *Enrollment, check func() error) error {
+	now := rs.config.Now()
+	if now.Before(enrollment.LockedUntil) {
+		return fuego.HTTPError{
+			Title:  "Too many wrong codes",
+			Detail: "Try again after " + enrollment.LockedUntil.UTC().Format(time.RFC3339),
+			Status: http.StatusTooManyRequests,
+		}
+	}
+
+	err := check()
+	if errors.Is(err, ErrInvalidCode) {
+		enrollment.FailedAttempts++
+		if enrollment.FailedAttempts >= rs.config.MaxFailedAttempts {
+			enrollment.FailedAttempts = 0
+			enrollment.LockedUntil = now.Add(rs.config.Lockout)
+		}
+		if saveErr := rs.config.Store.SaveEnrollment(ctx, userID, *enrollment); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	enrollment.FailedAttempts = 0
+	return nil
+}
+
+// lock locks the enrollment of the user, with the [Locker] of the store if any.
+func (rs routes) lock(ctx context.Context, userID string) (func(), error) {
+	if locker, ok := rs.config.Store.(Locker); ok {
+		return locker.LockEnrollment(ctx, userID)
+	}
+	return rs.locks.lock(userID), nil
+}
+
+// userLocks serializes the requests of each user in the process.
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[string]*userLock
+}
+
+type userLock struct {
+	mu sync.Mutex
+	// Number of requests holding or waiting for the lock.
+	refs int
+}
+
+func (l *userLocks) lock(userID string) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[userID]
+	if !ok {
+		lock = &userLock{}
+		l.locks[userID] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, userID)
+		}
+	}
+}
+
+func invalidCode(err error) error {
+	return fuego.UnauthorizedError{Title: "Invalid code", Err: err}
+}
+
+// user returns the ID and the claims of the logged in user.
+func user(ctx context.Context) (string, jwt.MapClaims, error) {
+	token, err := fuego.TokenFromContext(ctx)
+	if err != nil {
+		return "", nil, fuego.UnauthorizedError{Title: "Not logged in", Err: err}
+	}
+	claims, ok := token.(jwt.MapClaims)
+	if !ok {
+		return "", nil, fuego.UnauthorizedError{Title: "Not logged in", Err: fuego.ErrInvalidTokenType}
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return "", nil, fuego.UnauthorizedError{Title: "Not logged in", Detail: "The token has no subject", Err: err}
+	}
+	return subject, claims, nil
+}
+
+// addMethod adds the method to the "amr" claim, decoded from the token as []any.
+func addMethod(amr any, method string) []string {
+	var methods []string
+	switch amr := amr.(type) {
+	case []string:
+		methods = slices.Clone(amr)
+	case []any:
+		for _, m := range amr {
+			if m, ok := m.(string); ok {
+				methods = append(methods, m)
+			}
+		}
+	}
+	if len(methods) == 0 {
+		methods = append(methods, "pwd")
+	}
+	if !slices.Contains(methods, method) {
+		methods = append(methods, method)
+	}
+	return methods
+}
+
+// HasSecondFactor reports whether the user of the context verified a code,
+// that is whether the "amr" claim of its token contains "otp".
+func HasSecondFactor(ctx context.Context) bool {
+	token, err := fuego.TokenFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	claims, ok := token.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	switch amr := claims["amr"].(type) {
+	case []string:
+		return slices.Contains(amr, MethodOTP)
+	case []any:
+		return slices.Contains(amr, any(MethodOTP))
+	}
+	return false
+}
+
+// Require is a middleware rejecting the users who did not verify a code:
+// 401 Unauthorized if not logged in, 403 Forbidden without the second factor.
+func Require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := fuego.TokenFromContext(r.Context()); err != nil {
+			fuego.SendJSONError(w, nil, fuego.UnauthorizedError{Title: "Not logged in", Err: err})
+			return
+		}
+		if !HasSecondFactor(r.Context()) {
+			fuego.SendJSONError(w, nil, fuego.ForbiddenError{
+				Title:  "Two-factor authentication required",
+				Detail: "Verify a code of your authenticator app",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
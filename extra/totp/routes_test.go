@@ -0,0 +1,223 @@
+package totp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+type testServer struct {
+	t   *testing.T
+	s   *fuego.Server
+	now time.Time
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	ts := &testServer{t: t, now: time.Unix(1111111109, 0)}
+	ts.s = fuego.NewServer(
+		fuego.WithoutLogger(),
+		fuego.WithAutoAuth(func(user, password string) (jwt.Claims, error) {
+			return jwt.MapClaims{"sub": "1", "username": user}, nil
+		}),
+	)
+	auth := fuego.Group(ts.s, "/auth")
+	Register(auth, Config{Issuer: "Recipes", Store: NewInMemoryStore(), RecoveryCodes: 2, Now: func() time.Time { return ts.now }})
+	admin := fuego.Group(ts.s, "/admin")
+	fuego.Use(admin, Require)
+	fuego.Get(admin, "/", func(c fuego.ContextNoBody) (string, error) { return "welcome", nil })
+	return ts
+}
+
+func (ts *testServer) request(method, path, token, body string) *httptest.ResponseRecorder {
+	ts.t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	ts.s.Mux.ServeHTTP(w, r)
+	return w
+}
+
+func (ts *testServer) login() string {
+	ts.t.Helper()
+	w := ts.request(http.MethodPost, "/auth/login", "", `{"user":"alice","password":"secret"}`)
+	require.Equal(ts.t, http.StatusOK, w.Code, w.Body.String())
+	return decode[TokenResponse](ts.t, w).Token
+}
+
+func (ts *testServer) code(secret string) string {
+	ts.t.Helper()
+	code, err := Code(secret, ts.now)
+	require.NoError(ts.t, err)
+	return code
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v), w.Body.String())
+	return v
+}
+
+func TestRoutes(t *testing.T) {
+	ts := newTestServer(t)
+	token := ts.login()
+
+	w := ts.request(http.MethodGet, "/admin/", token, "")
+	require.Equal(t, http.StatusForbidden, w.Code, "password only")
+	w = ts.request(http.MethodGet, "/admin/", "", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = ts.request(http.MethodPost, "/auth/totp/enroll", "", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	w = ts.request(http.MethodPost, "/auth/totp/enroll", token, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	enrolled := decode[EnrollResponse](t, w)
+	require.Equal(t, ProvisioningURI("Recipes", "alice", enrolled.Secret), enrolled.URI)
+
+	w = ts.request(http.MethodPost, "/auth/totp/verify", token, `{"code":"`+ts.code(enrolled.Secret)+`"}`)
+	require.Equal(t, http.StatusNotFound, w.Code, "not confirmed")
+
+	w = ts.request(http.MethodPost, "/auth/totp/confirm", token, `{"code":"000000"}`)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	w = ts.request(http.MethodPost, "/auth/totp/confirm", token, `{"code":"`+ts.code(enrolled.Secret)+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	recoveryCodes := decode[RecoveryCodesResponse](t, w).RecoveryCodes
+	require.Len(t, recoveryCodes, 2)
+
+	w = ts.request(http.MethodPost, "/auth/totp/enroll", token, "")
+	require.Equal(t, http.StatusConflict, w.Code, "already enrolled")
+
+	t.Run("verify a code", func(t *testing.T) {
+		w := ts.request(http.MethodPost, "/auth/totp/verify", token, `{"code":"`+ts.code(enrolled.Secret)+`"}`)
+		require.Equal(t, http.StatusUnauthorized, w.Code, "code already used to confirm")
+
+		ts.now = ts.now.Add(Period)
+		w = ts.request(http.MethodPost, "/auth/totp/verify", token, `{"code":"`+ts.code(enrolled.Secret)+`"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		verified := decode[TokenResponse](t, w).Token
+		require.NotEmpty(t, w.Result().Cookies())
+
+		claims := jwt.MapClaims{}
+		_, _, err := jwt.NewParser().ParseUnverified(verified, claims)
+		require.NoError(t, err)
+		require.Equal(t, []any{"pwd", "otp"}, claims["amr"])
+		require.Equal(t, "alice", claims["username"])
+
+		w = ts.request(http.MethodGet, "/admin/", verified, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "welcome", w.Body.String())
+	})
+
+	t.Run("verify a recovery code", func(t *testing.T) {
+		w := ts.request(http.MethodPost, "/auth/totp/verify", token, `{}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = ts.request(http.MethodPost, "/auth/totp/verify", token, `{"recoveryCode":"`+recoveryCodes[0]+`"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = ts.request(http.MethodPost, "/auth/totp/verify", token, `{"recoveryCode":"`+recoveryCodes[0]+`"}`)
+		require.Equal(t, http.StatusUnauthorized, w.Code, "single use")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		w := ts.request(http.MethodDelete, "/auth/totp", token, `{"code":"000000"}`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		ts.now = ts.now.Add(Period)
+		w = ts.request(http.MethodDelete, "/auth/totp", token, `{"code":"`+ts.code(enrolled.Secret)+`"}`)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		w = ts.request(http.MethodPost, "/auth/totp/verify", token, `{"recoveryCode":"`+recoveryCodes[1]+`"}`)
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAddMethod(t *testing.T) {
+	require.Equal(t, []string{"pwd", "otp"}, addMethod(nil, MethodOTP))
+	require.Equal(t, []string{"hwk", "otp"}, addMethod([]any{"hwk"}, MethodOTP))
+	require.Equal(t, []string{"pwd", "otp"}, addMethod([]string{"pwd", "otp"}, MethodOTP))
+}
+
+// enroll enrolls and confirms the user, returning its secret and recovery codes.
+func (ts *testServer) enroll(token string) (string, []string) {
+	ts.t.Helper()
+	w := ts.request(http.MethodPost, "/auth/totp/enroll", token, "")
+	require.Equal(ts.t, http.StatusOK, w.Code, w.Body.String())
+	secret := decode[EnrollResponse](ts.t, w).Secret
+	w = ts.request(http.MethodPost, "/auth/totp/confirm", token, `{"code":"`+ts.code(secret)+`"}`)
+	require.Equal(ts.t, http.StatusOK, w.Code, w.Body.String())
+	return secret, decode[RecoveryCodesResponse](ts.t, w).RecoveryCodes
+}
+
+func TestLockout(t *testing.T) {
+	ts := newTestServer(t)
+	token := ts.login()
+	secret, _ := ts.enroll(token)
+	ts.now = ts.now.Add(Period)
+
+	for range 4 {
+		w := ts.request(http.MethodPost, "/auth/totp/verify", token, `{"code":"000000"}`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	w := ts.request(http.MethodPost, "/auth/totp/verify", token, `{"code":"`+ts.code(secret)+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, "a valid code resets the failed attempts")
+
+	ts.now = ts.now.Add(Period)
+	for range 5 {
+		w := ts.request(http.MethodPost, "/auth/totp/verify", token, `{"recoveryCode":"wrong"}`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	w = ts.request(http.MethodPost, "/auth/totp/verify", token, `{"code":"`+ts.code(secret)+`"}`)
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "locked out, even with a valid code")
+	w = ts.request(http.MethodDelete, "/auth/totp", token, `{"code":"`+ts.code(secret)+`"}`)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	ts.now = ts.now.Add(15 * time.Minute)
+	w = ts.request(http.MethodPost, "/auth/totp/verify", token, `{"code":"`+ts.code(secret)+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestConcurrentVerify(t *testing.T) {
+	for _, useRecoveryCode := range []bool{false, true} {
+		ts := newTestServer(t)
+		token := ts.login()
+		secret, recoveryCodes := ts.enroll(token)
+		ts.now = ts.now.Add(Period)
+		body := `{"code":"` + ts.code(secret) + `"}`
+		if useRecoveryCode {
+			body = `{"recoveryCode":"` + recoveryCodes[0] + `"}`
+		}
+
+		var succeeded atomic.Int32
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r := httptest.NewRequest(http.MethodPost, "/auth/totp/verify", strings.NewReader(body))
+				r.Header.Set("Content-Type", "application/json")
+				r.Header.Set("Authorization", "Bearer "+token)
+				w := httptest.NewRecorder()
+				ts.s.Mux.ServeHTTP(w, r)
+				if w.Code == http.StatusOK {
+					succeeded.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(1), succeeded.Load(), "single use: %s", body)
+	}
+}
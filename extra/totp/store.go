@@ -0,0 +1,47 @@
+package totp
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// InMemoryStore is a [Store] for a single instance of the server, mostly for the tests and the prototypes:
+// the enrollments are lost on restart.
+type InMemoryStore struct {
+	mu          sync.RWMutex
+	enrollments map[string]Enrollment
+}
+
+var _ Store = &InMemoryStore{}
+
+// NewInMemoryStore creates an empty [InMemoryStore].
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{enrollments: map[string]Enrollment{}}
+}
+
+func (s *InMemoryStore) Enrollment(_ context.Context, userID string) (Enrollment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	enrollment, ok := s.enrollments[userID]
+	if !ok {
+		return Enrollment{}, ErrNotEnrolled
+	}
+	enrollment.RecoveryCodes = slices.Clone(enrollment.RecoveryCodes)
+	return enrollment, nil
+}
+
+func (s *InMemoryStore) SaveEnrollment(_ context.Context, userID string, enrollment Enrollment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enrollment.RecoveryCodes = slices.Clone(enrollment.RecoveryCodes)
+	s.enrollments[userID] = enrollment
+	return nil
+}
+
+func (s *InMemoryStore) DeleteEnrollment(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.enrollments, userID)
+	return nil
+}
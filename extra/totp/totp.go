@@ -0,0 +1,182 @@
+// Package totp adds two-factor authentication with time-based one-time passwords (RFC 6238)
+// to the users logged in with the fuego security, like with [fuego.WithAutoAuth].
+//
+//	s := fuego.NewServer(fuego.WithAutoAuth(credentials.Verify))
+//
+//	auth := fuego.Group(s, "/auth")
+//	totp.Register(auth, totp.Config{Issuer: "Recipes", Store: totp.NewInMemoryStore()})
+//
+//	admin := fuego.Group(s, "/admin")
+//	fuego.Use(admin, totp.Require)
+//
+// Once enrolled, the users verify a code after logging in with their password: the new token carries
+// the "otp" authentication method in its "amr" claim, checked by [Require] and [HasSecondFactor].
+package totp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // SHA-1 is the algorithm of the authenticator apps, HMAC-SHA-1 is not broken
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits of the codes.
+	Digits = 6
+	// Period of validity of each code.
+	Period = 30 * time.Second
+)
+
+var (
+	// ErrInvalidCode is returned for the wrong, expired or already used codes.
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrNotEnrolled is returned by the [Store] for the users without two-factor authentication.
+	ErrNotEnrolled = errors.New("two-factor authentication not enrolled")
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret, encoded in base32 as expected by the authenticator apps.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI returns the otpauth:// URI of the secret, to be shown as a QR code
+// scanned by the authenticator apps.
+func ProvisioningURI(issuer, account, secret string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(Digits)},
+		"period":    {fmt.Sprint(int(Period.Seconds()))},
+	}
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Code returns the code of the secret at the given time.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid secret: %w", err)
+	}
+	return code(key, counter(t)), nil
+}
+
+// Validate checks the code of the secret at the given time, accepting the codes of the previous
+// and next periods to tolerate the clock drift of the devices. It returns the counter of the matching period,
+// to be stored so that the code cannot be used again.
+func Validate(secret, candidate string, t time.Time) (int64, error) {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return 0, fmt.Errorf("invalid secret: %w", err)
+	}
+	current := counter(t)
+	for _, c := range []int64{current, current - 1, current + 1} {
+		if subtle.ConstantTimeCompare([]byte(code(key, c)), []byte(candidate)) == 1 {
+			return c, nil
+		}
+	}
+	return 0, ErrInvalidCode
+}
+
+func counter(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// code computes the HOTP code of the counter (RFC 4226).
+func code(key []byte, counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1_000_000)
+}
+
+// GenerateRecoveryCodes returns n single-use recovery codes, shown once to the user,
+// and their hashes, to be stored in the [Enrollment].
+func GenerateRecoveryCodes(n int) (codes, hashes []string, err error) {
+	for range n {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(b)
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
+
+// Enrollment is the two-factor authentication of a user.
+type Enrollment struct {
+	Secret string
+	// False until the user verifies a first code, proving that the secret is saved in an authenticator app.
+	Confirmed bool
+	// Hashes of the unused recovery codes.
+	RecoveryCodes []string
+	// Counter of the last code used, so that it cannot be used again.
+	LastCounter int64
+	// Wrong codes and recovery codes since the last valid one, or the last lockout.
+	FailedAttempts int
+	// The codes are not checked until then, after too many failed attempts.
+	LockedUntil time.Time
+}
+
+// useRecoveryCode removes the recovery code from the enrollment, if it is one of its codes.
+func (e *Enrollment) useRecoveryCode(code string) bool {
+	hash := hashRecoveryCode(code)
+	for i, stored := range e.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			e.RecoveryCodes = append(e.RecoveryCodes[:i:i], e.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Store stores the enrollments of the users, identified by the subject of their token.
+//
+// The routes read, check and save the enrollment of a user under a lock of the user, so that a code
+// or a recovery code cannot be used twice by concurrent requests. The lock is held in the process:
+// when several instances of the server share the store, it must also implement [Locker].
+type Store interface {
+	// Enrollment returns the enrollment of the user, or [ErrNotEnrolled].
+	Enrollment(ctx context.Context, userID string) (Enrollment, error)
+	// SaveEnrollment creates or replaces the enrollment of the user.
+	SaveEnrollment(ctx context.Context, userID string, enrollment Enrollment) error
+	// DeleteEnrollment removes the enrollment of the user.
+	DeleteEnrollment(ctx context.Context, userID string) error
+}
+
+// Locker is implemented by the stores shared by several instances of the server,
+// to lock the enrollment of a user across the instances, for example with a row lock or a distributed lock.
+type Locker interface {
+	// LockEnrollment locks the enrollment of the user until unlock is called.
+	LockEnrollment(ctx context.Context, userID string) (unlock func(), err error)
+}
@@ -0,0 +1,91 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Secret "12345678901234567890" of the test vectors of RFC 6238.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	for unix, expected := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		code, err := Code(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, expected, code, unix)
+	}
+
+	_, err := Code("not base32!", time.Now())
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	code, err := Code(rfcSecret, now)
+	require.NoError(t, err)
+
+	counter, err := Validate(rfcSecret, code, now)
+	require.NoError(t, err)
+	require.Equal(t, now.Unix()/30, counter)
+
+	_, err = Validate(rfcSecret, code, now.Add(Period))
+	require.NoError(t, err, "previous period accepted")
+	_, err = Validate(rfcSecret, code, now.Add(-Period))
+	require.NoError(t, err, "next period accepted")
+	_, err = Validate(rfcSecret, code, now.Add(3*Period))
+	require.ErrorIs(t, err, ErrInvalidCode)
+	_, err = Validate(rfcSecret, "000000", now)
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	other, err := GenerateSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+
+	_, err = Code(secret, time.Now())
+	require.NoError(t, err)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("My App", "alice@example.com", rfcSecret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/My App:alice@example.com", uri.Path)
+	require.Equal(t, url.Values{
+		"secret":    {rfcSecret},
+		"issuer":    {"My App"},
+		"algorithm": {"SHA1"},
+		"digits":    {"6"},
+		"period":    {"30"},
+	}, uri.Query())
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(3)
+	require.NoError(t, err)
+	require.Len(t, codes, 3)
+	require.Len(t, hashes, 3)
+	require.Regexp(t, `^[0-9a-f]{5}-[0-9a-f]{5}$`, codes[0])
+	require.NotContains(t, hashes, codes[0])
+
+	enrollment := Enrollment{RecoveryCodes: hashes}
+	require.True(t, enrollment.useRecoveryCode(" "+codes[1]+" "))
+	require.False(t, enrollment.useRecoveryCode(codes[1]), "single use")
+	require.Len(t, enrollment.RecoveryCodes, 2)
+	require.Len(t, hashes, 3, "the hashes of the caller are not modified")
+}
@@ -0,0 +1,178 @@
+package tus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrUploadNotFound is returned by a [Store] when the upload does not exist.
+var ErrUploadNotFound = errors.New("upload not found")
+
+// Upload describes the state of an upload.
+type Upload struct {
+	ID string `json:"id"`
+	// Size of the upload in bytes. Unknown until declared if SizeIsDeferred is true.
+	Size int64 `json:"size"`
+	// SizeIsDeferred is true when the client created the upload without knowing its size yet.
+	SizeIsDeferred bool `json:"sizeIsDeferred,omitempty"`
+	// Offset is the number of bytes already received.
+	Offset int64 `json:"offset"`
+	// Metadata sent by the client on creation, for example the filename.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Complete returns true when all the bytes of the upload have been received.
+func (u Upload) Complete() bool {
+	return !u.SizeIsDeferred && u.Offset == u.Size
+}
+
+// Store persists the uploads and their content.
+// Calls for the same upload are never concurrent: the [Handler] serializes them.
+type Store interface {
+	// Create creates a new empty upload, assigns its ID and returns it.
+	Create(ctx context.Context, upload Upload) (Upload, error)
+	// Get returns the upload, or [ErrUploadNotFound].
+	Get(ctx context.Context, id string) (Upload, error)
+	// WriteChunk appends the content of src to the upload, starting at offset,
+	// and returns the number of bytes written.
+	// Bytes written before an error must be persisted and counted, so the client can resume from there.
+	WriteChunk(ctx context.Context, id string, offset int64, src io.Reader) (int64, error)
+	// DeclareSize sets the size of an upload created with a deferred size.
+	DeclareSize(ctx context.Context, id string, size int64) error
+	// Terminate deletes the upload and its content.
+	Terminate(ctx context.Context, id string) error
+}
+
+// FileStore stores the uploads on disk: the content in a file named after the upload ID,
+// and its state in a JSON file next to it, with the .info extension.
+type FileStore struct {
+	dir string
+
+	mu sync.Mutex // protects the .info files
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore returns a [FileStore] storing the uploads in the given directory, created if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create upload directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Path returns the path of the file containing the content of the upload.
+// It can be used to process the file once the upload is complete.
+func (s *FileStore) Path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id))
+}
+
+func (s *FileStore) infoPath(id string) string {
+	return s.Path(id) + ".info"
+}
+
+func (s *FileStore) Create(_ context.Context, upload Upload) (Upload, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Upload{}, err
+	}
+	upload.ID = hex.EncodeToString(id)
+	upload.Offset = 0
+
+	f, err := os.OpenFile(s.Path(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return Upload{}, err
+	}
+	if err := f.Close(); err != nil {
+		return Upload{}, err
+	}
+
+	return upload, s.save(upload)
+}
+
+func (s *FileStore) Get(_ context.Context, id string) (Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+func (s *FileStore) WriteChunk(_ context.Context, id string, offset int64, src io.Reader) (int64, error) {
+	f, err := os.OpenFile(s.Path(id), os.O_WRONLY, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, ErrUploadNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, copyErr := io.Copy(f, src)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, err := s.load(id)
+	if err != nil {
+		return n, err
+	}
+	upload.Offset = offset + n
+	if err := s.save(upload); err != nil {
+		return n, err
+	}
+
+	return n, copyErr
+}
+
+func (s *FileStore) DeclareSize(_ context.Context, id string, size int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, err := s.load(id)
+	if err != nil {
+		return err
+	}
+	upload.Size = size
+	upload.SizeIsDeferred = false
+	return s.save(upload)
+}
+
+func (s *FileStore) Terminate(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.load(id); err != nil {
+		return err
+	}
+	return errors.Join(os.Remove(s.Path(id)), os.Remove(s.infoPath(id)))
+}
+
+func (s *FileStore) load(id string) (Upload, error) {
+	raw, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Upload{}, ErrUploadNotFound
+	} else if err != nil {
+		return Upload{}, err
+	}
+
+	var upload Upload
+	if err := json.Unmarshal(raw, &upload); err != nil {
+		return Upload{}, fmt.Errorf("corrupted upload info %s: %w", id, err)
+	}
+	return upload, nil
+}
+
+func (s *FileStore) save(upload Upload) error {
+	raw, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.infoPath(upload.ID), raw, 0o640)
+}
@@ -0,0 +1,80 @@
+package tus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{ data string }
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	upload, err := store.Create(ctx, Upload{Size: 6, Metadata: map[string]string{"filename": "a.txt"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, upload.ID)
+
+	t.Run("bytes written before an error are kept", func(t *testing.T) {
+		n, err := store.WriteChunk(ctx, upload.ID, 0, &failingReader{data: "abc"})
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.Equal(t, int64(3), n)
+
+		got, err := store.Get(ctx, upload.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), got.Offset)
+		require.False(t, got.Complete())
+	})
+
+	n, err := store.WriteChunk(ctx, upload.ID, 3, strings.NewReader("def"))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+
+	got, err := store.Get(ctx, upload.ID)
+	require.NoError(t, err)
+	require.True(t, got.Complete())
+	require.Equal(t, "a.txt", got.Metadata["filename"])
+
+	content, err := os.ReadFile(store.Path(upload.ID))
+	require.NoError(t, err)
+	require.Equal(t, "abcdef", string(content))
+
+	require.NoError(t, store.Terminate(ctx, upload.ID))
+	_, err = store.Get(ctx, upload.ID)
+	require.True(t, errors.Is(err, ErrUploadNotFound))
+	_, err = store.WriteChunk(ctx, upload.ID, 0, strings.NewReader("abc"))
+	require.ErrorIs(t, err, ErrUploadNotFound)
+}
+
+func TestFileStoreDeclareSize(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	upload, err := store.Create(ctx, Upload{SizeIsDeferred: true})
+	require.NoError(t, err)
+	require.False(t, upload.Complete())
+
+	require.NoError(t, store.DeclareSize(ctx, upload.ID, 0))
+	got, err := store.Get(ctx, upload.ID)
+	require.NoError(t, err)
+	require.True(t, got.Complete())
+
+	require.ErrorIs(t, store.DeclareSize(ctx, "unknown", 1), ErrUploadNotFound)
+}
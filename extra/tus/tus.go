@@ -0,0 +1,458 @@
+// Package tus implements the tus resumable upload protocol (https://tus.io/protocols/resumable-upload) on Fuego routes,
+// so large uploads survive flaky connections: the client creates an upload,
+// sends its content in one or more chunks, and after an interruption asks the server
+// how many bytes were received to resume from there.
+//
+// Supported extensions: creation, creation-defer-length and termination.
+package tus
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/go-fuego/fuego"
+)
+
+const (
+	// Version of the tus protocol implemented by this package.
+	Version = "1.0.0"
+	// Extensions of the tus protocol implemented by this package.
+	Extensions = "creation,creation-defer-length,termination"
+	// ContentType of the chunks sent with PATCH requests.
+	ContentType = "application/offset+octet-stream"
+)
+
+// Config configures a [Handler].
+type Config struct {
+	// Store persists the uploads. Required.
+	Store Store
+	// MaxSize is the maximum size of an upload in bytes. 0 means no limit.
+	MaxSize int64
+	// OnComplete is called when all the bytes of an upload have been received.
+	OnComplete func(ctx context.Context, upload Upload)
+}
+
+// Handler serves the tus protocol. Create it with [New] and register its routes with [Handler.Register].
+type Handler struct {
+	config Config
+	locks  uploadLocks
+}
+
+// New returns a tus [Handler].
+func New(config Config) *Handler {
+	if config.Store == nil {
+		panic("tus: a Store is required")
+	}
+	return &Handler{
+		config: config,
+		locks:  uploadLocks{locks: make(map[string]*uploadLock)},
+	}
+}
+
+// Register registers the tus routes on the server:
+//   - POST path creates an upload, its URL is returned in the Location header
+//   - HEAD path/{id} returns the offset of the upload
+//   - PATCH path/{id} appends a chunk to the upload
+//   - DELETE path/{id} terminates the upload
+//   - OPTIONS path and path/{id} describe the server capabilities
+//
+// The options are applied to all the routes, for example to add an authentication middleware.
+func (h *Handler) Register(s *fuego.Server, path string, options ...func(*fuego.BaseRoute)) {
+	path = strings.TrimSuffix(path, "/")
+	uploadPath := path + "/{id}"
+
+	fuego.AllStd(s, path, h.serveOptions, options...)
+	fuego.PostStd(s, path, h.create, append(options,
+		fuego.OptionSummary("Create upload"),
+		tusHeader(),
+		fuego.OptionHeader("Upload-Length", "Size of the upload in bytes", fuego.ParamInteger()),
+		fuego.OptionHeader("Upload-Defer-Length", "Set to 1 if the size of the upload is not known yet", fuego.ParamInteger()),
+		fuego.OptionHeader("Upload-Metadata", "Comma-separated key and base64-encoded value pairs, for example: filename d29ybGQucGRm"),
+		fuego.OptionDefaultStatusCode(http.StatusCreated),
+		fuego.OptionResponseHeader("Location", "URL of the created upload", fuego.ParamStatusCodes(http.StatusCreated)),
+		errorResponse(http.StatusRequestEntityTooLarge, "Upload larger than the maximum size"),
+	)...)
+
+	head := fuego.AllStd(s, uploadPath, h.serveUpload, options...)
+	h.documentHead(s, *head)
+	fuego.PatchStd(s, uploadPath, h.patch, append(options,
+		fuego.OptionSummary("Upload chunk"),
+		tusHeader(),
+		fuego.OptionHeader("Upload-Offset", "Offset of the chunk, must be the current offset of the upload", fuego.ParamRequired(), fuego.ParamInteger()),
+		fuego.OptionHeader("Upload-Length", "Size of the upload, if it was deferred on creation", fuego.ParamInteger()),
+		chunkRequestBody(),
+		fuego.OptionDefaultStatusCode(http.StatusNoContent),
+		fuego.OptionResponseHeader("Upload-Offset", "New offset of the upload", fuego.ParamStatusCodes(http.StatusNoContent)),
+		errorResponse(http.StatusNotFound, "Upload not found"),
+		errorResponse(http.StatusConflict, "Upload-Offset does not match the offset of the upload"),
+		errorResponse(http.StatusRequestEntityTooLarge, "Chunk exceeds the size of the upload"),
+		errorResponse(http.StatusUnsupportedMediaType, "Content-Type is not "+ContentType),
+	)...)
+	fuego.DeleteStd(s, uploadPath, h.terminate, append(options,
+		fuego.OptionSummary("Terminate upload"),
+		tusHeader(),
+		fuego.OptionDefaultStatusCode(http.StatusNoContent),
+		errorResponse(http.StatusNotFound, "Upload not found"),
+	)...)
+}
+
+// documentHead documents the HEAD route, served by the catch-all route of the upload path.
+func (h *Handler) documentHead(s *fuego.Server, route fuego.Route[any, any]) {
+	route.Method = http.MethodHead
+	for _, option := range []func(*fuego.BaseRoute){
+		fuego.OptionSummary("Get upload offset"),
+		tusHeader(),
+		fuego.OptionResponseHeader("Upload-Offset", "Number of bytes received"),
+		fuego.OptionResponseHeader("Upload-Length", "Size of the upload, if known"),
+		fuego.OptionResponseHeader("Upload-Defer-Length", "1 if the size of the upload is not known yet"),
+		fuego.OptionResponseHeader("Upload-Metadata", "Metadata sent on creation"),
+		errorResponse(http.StatusNotFound, "Upload not found"),
+	} {
+		option(&route.BaseRoute)
+	}
+	if err := route.RegisterOpenAPIOperation(s.OpenAPI); err != nil {
+		slog.Warn("error documenting openapi operation", "error", err)
+	}
+}
+
+func (h *Handler) serveOptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodOptions {
+		h.sendError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	w.Header().Set("Tus-Resumable", Version)
+	w.Header().Set("Tus-Version", Version)
+	w.Header().Set("Tus-Extension", Extensions)
+	if h.config.MaxSize > 0 {
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.config.MaxSize, 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) serveUpload(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodOptions:
+		h.serveOptions(w, r)
+	case http.MethodHead:
+		h.head(w, r)
+	default:
+		h.sendError(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	if !h.checkVersion(w, r) {
+		return
+	}
+
+	var upload Upload
+	if r.Header.Get("Upload-Defer-Length") == "1" {
+		upload.SizeIsDeferred = true
+	} else {
+		size, err := parseSize(r.Header.Get("Upload-Length"))
+		if err != nil {
+			h.sendError(w, r, http.StatusBadRequest, "invalid Upload-Length header", err)
+			return
+		}
+		upload.Size = size
+	}
+	if h.tooLarge(upload.Size) {
+		h.sendError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload larger than %d bytes", h.config.MaxSize), nil)
+		return
+	}
+
+	metadata, err := parseMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid Upload-Metadata header", err)
+		return
+	}
+	upload.Metadata = metadata
+
+	upload, err = h.config.Store.Create(r.Context(), upload)
+	if err != nil {
+		h.sendStoreError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+upload.ID)
+	w.WriteHeader(http.StatusCreated)
+
+	if upload.Complete() {
+		h.complete(r.Context(), upload)
+	}
+}
+
+func (h *Handler) head(w http.ResponseWriter, r *http.Request) {
+	if !h.checkVersion(w, r) {
+		return
+	}
+
+	upload, err := h.config.Store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.sendStoreError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if upload.SizeIsDeferred {
+		w.Header().Set("Upload-Defer-Length", "1")
+	} else {
+		w.Header().Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	}
+	if len(upload.Metadata) > 0 {
+		w.Header().Set("Upload-Metadata", formatMetadata(upload.Metadata))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) patch(w http.ResponseWriter, r *http.Request) {
+	if !h.checkVersion(w, r) {
+		return
+	}
+	if r.Header.Get("Content-Type") != ContentType {
+		h.sendError(w, r, http.StatusUnsupportedMediaType, "Content-Type must be "+ContentType, nil)
+		return
+	}
+	offset, err := parseSize(r.Header.Get("Upload-Offset"))
+	if err != nil {
+		h.sendError(w, r, http.StatusBadRequest, "invalid Upload-Offset header", err)
+		return
+	}
+
+	id := r.PathValue("id")
+	unlock := h.locks.lock(id)
+	defer unlock()
+
+	upload, err := h.config.Store.Get(r.Context(), id)
+	if err != nil {
+		h.sendStoreError(w, r, err)
+		return
+	}
+	if offset != upload.Offset {
+		h.sendError(w, r, http.StatusConflict, fmt.Sprintf("Upload-Offset is %d but the upload offset is %d", offset, upload.Offset), nil)
+		return
+	}
+
+	if upload.SizeIsDeferred && r.Header.Get("Upload-Length") != "" {
+		size, err := parseSize(r.Header.Get("Upload-Length"))
+		if err != nil || size < upload.Offset {
+			h.sendError(w, r, http.StatusBadRequest, "invalid Upload-Length header", err)
+			return
+		}
+		if h.tooLarge(size) {
+			h.sendError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload larger than %d bytes", h.config.MaxSize), nil)
+			return
+		}
+		if err := h.config.Store.DeclareSize(r.Context(), id, size); err != nil {
+			h.sendStoreError(w, r, err)
+			return
+		}
+		upload.Size = size
+		upload.SizeIsDeferred = false
+	}
+
+	body := io.Reader(r.Body)
+	if limit := h.remaining(upload); limit >= 0 {
+		if r.ContentLength > limit {
+			h.sendError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("chunk larger than the %d remaining bytes", limit), nil)
+			return
+		}
+		body = io.LimitReader(r.Body, limit)
+	}
+
+	n, err := h.config.Store.WriteChunk(r.Context(), id, offset, body)
+	upload.Offset += n
+	if err != nil {
+		// The bytes written are kept: the client resumes from the new offset.
+		h.sendStoreError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+
+	if upload.Complete() {
+		h.complete(r.Context(), upload)
+	}
+}
+
+func (h *Handler) terminate(w http.ResponseWriter, r *http.Request) {
+	if !h.checkVersion(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	unlock := h.locks.lock(id)
+	defer unlock()
+
+	if err := h.config.Store.Terminate(r.Context(), id); err != nil {
+		h.sendStoreError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkVersion checks the Tus-Resumable header of the request
+// and sets the one of the response.
+func (h *Handler) checkVersion(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", Version)
+	if r.Header.Get("Tus-Resumable") != Version {
+		w.Header().Set("Tus-Version", Version)
+		h.sendError(w, r, http.StatusPreconditionFailed, "unsupported tus version, Tus-Resumable header must be "+Version, nil)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) tooLarge(size int64) bool {
+	return h.config.MaxSize > 0 && size > h.config.MaxSize
+}
+
+// remaining returns the number of bytes the upload can still receive, or -1 if unlimited.
+func (h *Handler) remaining(upload Upload) int64 {
+	switch {
+	case !upload.SizeIsDeferred:
+		return upload.Size - upload.Offset
+	case h.config.MaxSize > 0:
+		return h.config.MaxSize - upload.Offset
+	default:
+		return -1
+	}
+}
+
+func (h *Handler) complete(ctx context.Context, upload Upload) {
+	if h.config.OnComplete != nil {
+		h.config.OnComplete(ctx, upload)
+	}
+}
+
+func (h *Handler) sendStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrUploadNotFound) {
+		h.sendError(w, r, http.StatusNotFound, "upload not found", err)
+		return
+	}
+	h.sendError(w, r, http.StatusInternalServerError, "cannot store upload", err)
+}
+
+func (h *Handler) sendError(w http.ResponseWriter, r *http.Request, status int, detail string, err error) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	fuego.SendError(w, r, fuego.HTTPError{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Err:    err,
+	})
+}
+
+func parseSize(value string) (int64, error) {
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 {
+		return 0, fmt.Errorf("negative value %d", size)
+	}
+	return size, nil
+}
+
+// parseMetadata parses the Upload-Metadata header: comma-separated "key base64value" pairs, the value being optional.
+func parseMetadata(header string) (map[string]string, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("metadata %s: %w", key, err)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+func formatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		if value == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func tusHeader() func(*fuego.BaseRoute) {
+	return fuego.OptionHeader("Tus-Resumable", "Version of the tus protocol", fuego.ParamRequired(), fuego.ParamExample("version", Version))
+}
+
+func errorResponse(status int, description string) func(*fuego.BaseRoute) {
+	return fuego.OptionAddResponse(status, description, fuego.Response{Type: fuego.HTTPError{}})
+}
+
+func chunkRequestBody() func(*fuego.BaseRoute) {
+	return func(r *fuego.BaseRoute) {
+		schema := openapi3.NewStringSchema().WithFormat("binary")
+		r.Operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().
+				WithRequired(true).
+				WithContent(openapi3.NewContentWithSchema(schema, []string{ContentType})),
+		}
+	}
+}
+
+// uploadLocks serializes the requests modifying the same upload.
+type uploadLocks struct {
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+}
+
+type uploadLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the upload and returns the function to unlock it.
+func (l *uploadLocks) lock(id string) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[id]
+	if !ok {
+		lock = &uploadLock{}
+		l.locks[id] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,212 @@
+package tus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func newTestServer(t *testing.T, config Config) (*fuego.Server, *FileStore) {
+	t.Helper()
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	config.Store = store
+
+	s := fuego.NewServer(fuego.WithoutLogger())
+	New(config).Register(s, "/files")
+	return s, store
+}
+
+func tusRequest(s *fuego.Server, method, path string, body io.Reader, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, body)
+	r.Header.Set("Tus-Resumable", Version)
+	for key, value := range headers {
+		r.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestUpload(t *testing.T) {
+	var completed []Upload
+	s, store := newTestServer(t, Config{
+		OnComplete: func(_ context.Context, upload Upload) { completed = append(completed, upload) },
+	})
+
+	w := tusRequest(s, http.MethodPost, "/files", nil, map[string]string{
+		"Upload-Length":   "11",
+		"Upload-Metadata": "filename aGVsbG8udHh0,is_draft",
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, Version, w.Header().Get("Tus-Resumable"))
+	location := w.Header().Get("Location")
+	require.True(t, strings.HasPrefix(location, "/files/"))
+
+	w = tusRequest(s, http.MethodPatch, location, strings.NewReader("hello"), map[string]string{
+		"Content-Type":  ContentType,
+		"Upload-Offset": "0",
+	})
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "5", w.Header().Get("Upload-Offset"))
+
+	t.Run("resume from the offset", func(t *testing.T) {
+		w := tusRequest(s, http.MethodHead, location, nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "5", w.Header().Get("Upload-Offset"))
+		require.Equal(t, "11", w.Header().Get("Upload-Length"))
+		require.Equal(t, "filename aGVsbG8udHh0,is_draft", w.Header().Get("Upload-Metadata"))
+		require.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("wrong offset", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPatch, location, strings.NewReader("world"), map[string]string{
+			"Content-Type":  ContentType,
+			"Upload-Offset": "0",
+		})
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("wrong content type", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPatch, location, strings.NewReader(" world"), map[string]string{
+			"Content-Type":  "application/octet-stream",
+			"Upload-Offset": "5",
+		})
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("chunk exceeding the size", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPatch, location, strings.NewReader(" world and more"), map[string]string{
+			"Content-Type":  ContentType,
+			"Upload-Offset": "5",
+		})
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	w = tusRequest(s, http.MethodPatch, location, strings.NewReader(" world"), map[string]string{
+		"Content-Type":  ContentType,
+		"Upload-Offset": "5",
+	})
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "11", w.Header().Get("Upload-Offset"))
+
+	require.Len(t, completed, 1)
+	require.Equal(t, map[string]string{"filename": "hello.txt", "is_draft": ""}, completed[0].Metadata)
+	content, err := os.ReadFile(store.Path(completed[0].ID))
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(content))
+
+	t.Run("terminate", func(t *testing.T) {
+		w := tusRequest(s, http.MethodDelete, location, nil, nil)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = tusRequest(s, http.MethodHead, location, nil, nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Empty(t, w.Body.String())
+	})
+}
+
+func TestUploadDeferredLength(t *testing.T) {
+	s, _ := newTestServer(t, Config{MaxSize: 10})
+
+	w := tusRequest(s, http.MethodPost, "/files", nil, map[string]string{"Upload-Defer-Length": "1"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+
+	w = tusRequest(s, http.MethodPatch, location, strings.NewReader("abc"), map[string]string{
+		"Content-Type":  ContentType,
+		"Upload-Offset": "0",
+	})
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = tusRequest(s, http.MethodHead, location, nil, nil)
+	require.Equal(t, "1", w.Header().Get("Upload-Defer-Length"))
+	require.Empty(t, w.Header().Get("Upload-Length"))
+
+	t.Run("declared length above the maximum", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPatch, location, strings.NewReader("def"), map[string]string{
+			"Content-Type":  ContentType,
+			"Upload-Offset": "3",
+			"Upload-Length": "11",
+		})
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	w = tusRequest(s, http.MethodPatch, location, strings.NewReader("def"), map[string]string{
+		"Content-Type":  ContentType,
+		"Upload-Offset": "3",
+		"Upload-Length": "6",
+	})
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = tusRequest(s, http.MethodHead, location, nil, nil)
+	require.Equal(t, "6", w.Header().Get("Upload-Offset"))
+	require.Equal(t, "6", w.Header().Get("Upload-Length"))
+}
+
+func TestProtocol(t *testing.T) {
+	s, _ := newTestServer(t, Config{MaxSize: 1024})
+
+	t.Run("options", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/files", nil))
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, Version, w.Header().Get("Tus-Version"))
+		require.Equal(t, Extensions, w.Header().Get("Tus-Extension"))
+		require.Equal(t, "1024", w.Header().Get("Tus-Max-Size"))
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/files", nil)
+		r.Header.Set("Tus-Resumable", "0.2.2")
+		r.Header.Set("Upload-Length", "10")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusPreconditionFailed, w.Code)
+		require.Equal(t, Version, w.Header().Get("Tus-Version"))
+	})
+
+	t.Run("upload larger than the maximum", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPost, "/files", nil, map[string]string{"Upload-Length": "2048"})
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPost, "/files", nil, map[string]string{"Upload-Length": "-1"})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPost, "/files", nil, map[string]string{
+			"Upload-Length":   "10",
+			"Upload-Metadata": "filename not-base64!",
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown upload", func(t *testing.T) {
+		w := tusRequest(s, http.MethodPatch, "/files/unknown", strings.NewReader("abc"), map[string]string{
+			"Content-Type":  ContentType,
+			"Upload-Offset": "0",
+		})
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("routes are documented", func(t *testing.T) {
+		paths := s.OpenAPI.Description().Paths
+		require.NotNil(t, paths.Find("/files").Post)
+		upload := paths.Find("/files/{id}")
+		require.NotNil(t, upload.Head)
+		require.NotNil(t, upload.Patch)
+		require.NotNil(t, upload.Delete)
+		require.NotNil(t, upload.Patch.RequestBody.Value.Content.Get(ContentType))
+	})
+}
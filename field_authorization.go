@@ -0,0 +1,379 @@
+package fuego
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FieldAccess is the access of a requester to a field of a response. See [FieldAuthorizer].
+type FieldAccess int
+
+const (
+	// FieldVisible fields are serialized as is.
+	FieldVisible FieldAccess = iota
+	// FieldHidden fields are removed from the response.
+	FieldHidden
+	// FieldMasked fields are kept in the response, but their value is replaced:
+	// string fields by their `mask` tag ("***" by default), other fields by their zero value.
+	FieldMasked
+)
+
+// FieldAuthorizer decides which fields of the responses the requester can see.
+// It is consulted before serialization for each exported field of the structs of the response,
+// including the nested ones. See [WithFieldAuthorizer].
+type FieldAuthorizer interface {
+	AuthorizeField(ctx context.Context, field reflect.StructField) FieldAccess
+}
+
+// FieldAuthorizerFunc is a function implementing [FieldAuthorizer].
+type FieldAuthorizerFunc func(ctx context.Context, field reflect.StructField) FieldAccess
+
+func (f FieldAuthorizerFunc) AuthorizeField(ctx context.Context, field reflect.StructField) FieldAccess {
+	return f(ctx, field)
+}
+
+// WithFieldAuthorizer removes or masks the fields of the responses that the requester is not allowed to see,
+// for example the email of the users for the requesters without the admin scope.
+// Streamed and seekable responses are not authorized.
+//
+//	type User struct {
+//		Name  string `json:"name"`
+//		Email string `json:"email" scope:"admin,support"`
+//		Phone string `json:"phone" scope:"admin" mask:"** ** ** ** **"`
+//	}
+//
+//	s := fuego.NewServer(
+//		fuego.WithEngineOptions(fuego.WithFieldAuthorizer(fuego.ScopeFieldAuthorizer{})),
+//	)
+func WithFieldAuthorizer(authorizer FieldAuthorizer) func(*Engine) {
+	return func(e *Engine) { e.fieldAuthorizer = authorizer }
+}
+
+// ScopeFieldAuthorizer shows the fields with a `scope` tag only to the requesters with one of the listed scopes.
+// The scopes of each field are documented in the OpenAPI spec with the x-scopes extension.
+type ScopeFieldAuthorizer struct {
+	// Scopes of the requester. Defaults to the scopes of the token in the context, see [TokenScopes].
+	Scopes func(ctx context.Context) []string
+	// If true, the fields are masked instead of hidden. The fields with a `mask` tag are always masked.
+	Mask bool
+}
+
+func (a ScopeFieldAuthorizer) AuthorizeField(ctx context.Context, field reflect.StructField) FieldAccess {
+	fieldScopes := fieldScopes(field)
+	if fieldScopes == nil {
+		return FieldVisible
+	}
+
+	scopes := a.Scopes
+	if scopes == nil {
+		scopes = TokenScopes
+	}
+	for _, scope := range scopes(ctx) {
+		if slices.Contains(fieldScopes, scope) {
+			return FieldVisible
+		}
+	}
+
+	if _, masked := field.Tag.Lookup("mask"); masked || a.Mask {
+		return FieldMasked
+	}
+	return FieldHidden
+}
+
+// fieldScopes returns the scopes of the `scope` tag of the field, nil if it has none.
+func fieldScopes(field reflect.StructField) []string {
+	tag, ok := field.Tag.Lookup("scope")
+	if !ok {
+		return nil
+	}
+	scopes := []string{}
+	for _, scope := range strings.Split(tag, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// TokenScopes returns the scopes of the token in the context, from its "scope" claim
+// (space-separated, as in OAuth 2) or its "scopes" claim (array). See [TokenFromContext].
+func TokenScopes(ctx context.Context) []string {
+	claims, err := TokenFromContext(ctx)
+	if err != nil {
+		return nil
+	}
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+	if scope, ok := mapClaims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	var scopes []string
+	switch values := mapClaims["scopes"].(type) {
+	case []string:
+		scopes = values
+	case []any:
+		for _, value := range values {
+			if scope, ok := value.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// authorizeFields returns the answer without the fields hidden by the authorizer, and with the masked ones replaced.
+// The structs with hidden fields are copied to new struct types without these fields.
+func authorizeFields(ctx context.Context, authorizer FieldAuthorizer, ans any) any {
+	if ans == nil {
+		return nil
+	}
+	authorized, changed := authorizeValue(ctx, authorizer, reflect.ValueOf(ans))
+	if !changed {
+		return ans
+	}
+	return authorized.Interface()
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// isOpaque reports whether the values of the type are serialized by their own methods, so their fields are not inspected.
+func isOpaque(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// authorizeValue returns the authorized copy of v, and whether it differs from v.
+func authorizeValue(ctx context.Context, authorizer FieldAuthorizer, v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() || isOpaque(v.Type()) {
+		return v, false
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, false
+		}
+		elem, changed := authorizeValue(ctx, authorizer, v.Elem())
+		if !changed {
+			return v, false
+		}
+		ptr := reflect.New(elem.Type())
+		ptr.Elem().Set(elem)
+		return ptr, true
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		return authorizeValue(ctx, authorizer, v.Elem())
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() || isScalar(v.Type().Elem()) {
+			return v, false
+		}
+		items := make([]reflect.Value, v.Len())
+		changed := false
+		for i := range items {
+			var itemChanged bool
+			items[i], itemChanged = authorizeValue(ctx, authorizer, v.Index(i))
+			changed = changed || itemChanged
+		}
+		if !changed {
+			return v, false
+		}
+		authorized := reflect.MakeSlice(reflect.SliceOf(commonType(items, v.Type().Elem())), len(items), len(items))
+		for i, item := range items {
+			authorized.Index(i).Set(item)
+		}
+		return authorized, true
+
+	case reflect.Map:
+		if v.IsNil() || isScalar(v.Type().Elem()) {
+			return v, false
+		}
+		keys := v.MapKeys()
+		values := make([]reflect.Value, len(keys))
+		changed := false
+		for i, key := range keys {
+			var valueChanged bool
+			values[i], valueChanged = authorizeValue(ctx, authorizer, v.MapIndex(key))
+			changed = changed || valueChanged
+		}
+		if !changed {
+			return v, false
+		}
+		authorized := reflect.MakeMapWithSize(reflect.MapOf(v.Type().Key(), commonType(values, v.Type().Elem())), len(keys))
+		for i, key := range keys {
+			authorized.SetMapIndex(key, values[i])
+		}
+		return authorized, true
+
+	case reflect.Struct:
+		return authorizeStruct(ctx, authorizer, v)
+	}
+
+	return v, false
+}
+
+// isScalar reports whether the values of the type cannot hold structs.
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return false
+	}
+	return true
+}
+
+// commonType returns the type of the values if they all have the same, the fallback type otherwise.
+func commonType(values []reflect.Value, fallback reflect.Type) reflect.Type {
+	if len(values) == 0 {
+		return fallback
+	}
+	t := values[0].Type()
+	for _, value := range values[1:] {
+		if value.Type() != t {
+			return reflect.TypeFor[any]()
+		}
+	}
+	if !t.AssignableTo(fallback) {
+		// The items changed type: the slice or map holds the new type.
+		return t
+	}
+	return fallback
+}
+
+func authorizeStruct(ctx context.Context, authorizer FieldAuthorizer, v reflect.Value) (reflect.Value, bool) {
+	t := v.Type()
+	fields := make([]reflect.StructField, 0, t.NumField())
+	values := make([]reflect.Value, 0, t.NumField())
+	// A field is hidden or its type changed: a new struct type is needed.
+	restructured := false
+	// A field value changed, with the same type.
+	modified := false
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !isSerialized(field) {
+			continue
+		}
+
+		value := v.Field(i)
+		switch authorizer.AuthorizeField(ctx, field) {
+		case FieldHidden:
+			restructured = true
+			continue
+		case FieldMasked:
+			value = maskValue(field)
+			modified = true
+		default:
+			var changed bool
+			value, changed = authorizeValue(ctx, authorizer, value)
+			if changed && !value.Type().AssignableTo(field.Type) {
+				field.Type = value.Type()
+				restructured = true
+			} else if changed {
+				modified = true
+			}
+		}
+		fields = append(fields, field)
+		values = append(values, value)
+	}
+
+	if restructured {
+		if authorized, ok := restructure(fields, values); ok {
+			return authorized, true
+		}
+	}
+	if !restructured && !modified {
+		return v, false
+	}
+
+	// Copy of the struct with the same type, for example when it embeds a type with methods,
+	// which cannot be restructured: the hidden fields get their zero value.
+	authorized := reflect.New(t).Elem()
+	authorized.Set(v)
+	kept := make(map[string]reflect.Value, len(fields))
+	for i, field := range fields {
+		kept[field.Name] = values[i]
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !isSerialized(field) {
+			continue
+		}
+		value, ok := kept[field.Name]
+		if !ok || !value.Type().AssignableTo(field.Type) {
+			value = reflect.Zero(field.Type)
+		}
+		if ok && !value.CanInterface() {
+			// Unchanged value of an unexported embedded struct, already in the copy.
+			continue
+		}
+		target := authorized.Field(i)
+		if !target.CanSet() {
+			// Embedded struct of an unexported type: its promoted fields are serialized.
+			target = reflect.NewAt(field.Type, unsafe.Pointer(target.UnsafeAddr())).Elem()
+		}
+		target.Set(value)
+	}
+	return authorized, true
+}
+
+// isSerialized reports whether the field is serialized: exported, or an embedded struct whose fields are promoted.
+func isSerialized(field reflect.StructField) bool {
+	if field.Tag.Get("json") == "-" {
+		return false
+	}
+	if field.Anonymous {
+		t := field.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		return field.IsExported() || t.Kind() == reflect.Struct
+	}
+	return field.IsExported()
+}
+
+// restructure returns a value of a new struct type with the given fields.
+// It fails for the fields that reflect.StructOf does not support, like some embedded types with methods.
+func restructure(fields []reflect.StructField, values []reflect.Value) (authorized reflect.Value, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	for i := range fields {
+		fields[i].Index = nil
+		fields[i].Offset = 0
+	}
+	authorized = reflect.New(reflect.StructOf(fields)).Elem()
+	for i, value := range values {
+		authorized.Field(i).Set(value)
+	}
+	return authorized, true
+}
+
+// maskValue returns the mask of a string field, the zero value of the others.
+func maskValue(field reflect.StructField) reflect.Value {
+	value := reflect.New(field.Type).Elem()
+	if field.Type.Kind() == reflect.String {
+		mask, ok := field.Tag.Lookup("mask")
+		if !ok {
+			mask = "***"
+		}
+		value.SetString(mask)
+	}
+	return value
+}
@@ -0,0 +1,153 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type authorizedAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street" scope:"admin"`
+}
+
+type authorizedUser struct {
+	Name      string             `json:"name"`
+	Email     string             `json:"email" scope:"admin,support"`
+	Phone     string             `json:"phone" scope:"admin" mask:"** ** ** ** **"`
+	Age       int                `json:"age" scope:"admin" mask:""`
+	Address   *authorizedAddress `json:"address,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	internal  string
+}
+
+type auditedUser struct {
+	authorizedAuditInfo
+	Secret string `json:"secret" scope:"admin"`
+}
+
+type authorizedAuditInfo struct {
+	Author string `json:"author"`
+}
+
+func (a authorizedAuditInfo) String() string { return a.Author }
+
+func TestWithFieldAuthorizer(t *testing.T) {
+	user := authorizedUser{
+		Name:      "Ada",
+		Email:     "ada@example.com",
+		Phone:     "06 12 34 56 78",
+		Age:       36,
+		Address:   &authorizedAddress{City: "London", Street: "12 St James's Square"},
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		internal:  "internal",
+	}
+
+	s := NewServer(WithEngineOptions(WithFieldAuthorizer(ScopeFieldAuthorizer{})))
+	Use(s, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := jwt.MapClaims{"scope": r.Header.Get("X-Scopes")}
+			next.ServeHTTP(w, r.WithContext(WithValue(r.Context(), claims)))
+		})
+	})
+	Get(s, "/user", func(c ContextNoBody) (authorizedUser, error) { return user, nil })
+	Get(s, "/users", func(c ContextNoBody) ([]*authorizedUser, error) { return []*authorizedUser{&user, &user}, nil })
+	Get(s, "/any", func(c ContextNoBody) (any, error) { return map[string]any{"user": user}, nil })
+	Get(s, "/audited", func(c ContextNoBody) (auditedUser, error) {
+		return auditedUser{authorizedAuditInfo{Author: "Grace"}, "s3cr3t"}, nil
+	})
+
+	get := func(path, scopes string) string {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("X-Scopes", scopes)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	t.Run("all the fields are visible with the scopes", func(t *testing.T) {
+		require.JSONEq(t, `{"name":"Ada","email":"ada@example.com","phone":"06 12 34 56 78","age":36,
+			"address":{"city":"London","street":"12 St James's Square"},"created_at":"2024-01-02T03:04:05Z"}`,
+			get("/user", "admin"))
+	})
+
+	t.Run("fields are hidden or masked without the scopes", func(t *testing.T) {
+		require.JSONEq(t, `{"name":"Ada","email":"ada@example.com","phone":"** ** ** ** **","age":0,
+			"address":{"city":"London"},"created_at":"2024-01-02T03:04:05Z"}`,
+			get("/user", "support"))
+		require.JSONEq(t, `{"name":"Ada","phone":"** ** ** ** **","age":0,
+			"address":{"city":"London"},"created_at":"2024-01-02T03:04:05Z"}`,
+			get("/user", ""))
+	})
+
+	t.Run("nested values", func(t *testing.T) {
+		body := get("/users", "")
+		require.Equal(t, 2, strings.Count(body, `"phone":"** ** ** ** **"`))
+		require.NotContains(t, body, "ada@example.com")
+
+		require.NotContains(t, get("/any", ""), "ada@example.com")
+		require.Contains(t, get("/any", "admin"), "ada@example.com")
+	})
+
+	t.Run("structs embedding types with methods", func(t *testing.T) {
+		body := get("/audited", "")
+		require.Contains(t, body, `"author":"Grace"`)
+		require.NotContains(t, body, "s3cr3t")
+		require.Contains(t, get("/audited", "admin"), "s3cr3t")
+	})
+
+	t.Run("documents the scopes", func(t *testing.T) {
+		schema := s.OpenAPI.Description().Components.Schemas["authorizedUser"].Value
+		require.Equal(t, []string{"admin", "support"}, schema.Properties["email"].Value.Extensions["x-scopes"])
+		require.Nil(t, schema.Properties["name"].Value.Extensions["x-scopes"])
+	})
+}
+
+func TestAuthorizeFields(t *testing.T) {
+	hideAll := FieldAuthorizerFunc(func(ctx context.Context, field reflect.StructField) FieldAccess {
+		if field.Tag.Get("scope") != "" {
+			return FieldHidden
+		}
+		return FieldVisible
+	})
+
+	t.Run("values without hidden fields are not copied", func(t *testing.T) {
+		address := &authorizedAddress{City: "Paris"}
+		require.Same(t, address, authorizeFields(context.Background(), FieldAuthorizerFunc(
+			func(context.Context, reflect.StructField) FieldAccess { return FieldVisible },
+		), address))
+
+		values := []int{1, 2, 3}
+		require.Equal(t, values, authorizeFields(context.Background(), hideAll, values))
+		require.Nil(t, authorizeFields(context.Background(), hideAll, nil))
+	})
+
+	t.Run("masked fields keep the type", func(t *testing.T) {
+		maskAll := ScopeFieldAuthorizer{Mask: true, Scopes: func(context.Context) []string { return nil }}
+		authorized := authorizeFields(context.Background(), maskAll, authorizedAddress{City: "Paris", Street: "Rue de Rivoli"})
+		require.Equal(t, authorizedAddress{City: "Paris", Street: "***"}, authorized)
+	})
+}
+
+func TestTokenScopes(t *testing.T) {
+	require.Nil(t, TokenScopes(context.Background()))
+
+	ctx := WithValue(context.Background(), jwt.MapClaims{"scope": "pets:read pets:write"})
+	require.Equal(t, []string{"pets:read", "pets:write"}, TokenScopes(ctx))
+
+	ctx = WithValue(context.Background(), jwt.MapClaims{"scopes": []any{"admin", 42}})
+	require.Equal(t, []string{"admin"}, TokenScopes(ctx))
+
+	ctx = WithValue(context.Background(), jwt.MapClaims{"scope": "admin"})
+	require.Equal(t, FieldVisible, ScopeFieldAuthorizer{}.AuthorizeField(ctx, reflect.TypeFor[authorizedUser]().Field(1)))
+	require.Equal(t, FieldHidden, ScopeFieldAuthorizer{}.AuthorizeField(context.Background(), reflect.TypeFor[authorizedUser]().Field(1)))
+}
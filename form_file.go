@@ -0,0 +1,127 @@
+package fuego
+
+import (
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// FormFile returns the first file of the field of the multipart/form-data request body:
+// a 400 error if the request is not multipart or has no file in the field,
+// a 413 error if the body is larger than the maximum size of the server.
+// Open the returned header to read the file.
+// It is used by the adaptors to implement c.FormFile(): prefer it in the controllers.
+func FormFile(r *http.Request, name string) (*multipart.FileHeader, error) {
+	if err := parseMultipartForm(r); err != nil {
+		return nil, err
+	}
+	files := r.MultipartForm.File[name]
+	if len(files) == 0 {
+		return nil, missingFileError(name)
+	}
+	return files[0], nil
+}
+
+func missingFileError(name string) error {
+	return BadRequestError{
+		Title:  "Missing file",
+		Detail: "no file in the " + name + " field of the multipart form",
+		Err:    http.ErrMissingFile,
+	}
+}
+
+// FormFiles returns all the files of the multipart/form-data request body, by field.
+// It is used by the adaptors to implement c.FormFiles(): prefer it in the controllers.
+func FormFiles(r *http.Request) (map[string][]*multipart.FileHeader, error) {
+	if err := parseMultipartForm(r); err != nil {
+		return nil, err
+	}
+	return r.MultipartForm.File, nil
+}
+
+// parseMultipartForm parses the multipart form of the request, once.
+func parseMultipartForm(r *http.Request) error {
+	if r.MultipartForm != nil {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "multipart/form-data" {
+		return BadRequestError{
+			Title:  "Invalid multipart form",
+			Detail: "the content type of the request is not multipart/form-data",
+			Err:    http.ErrNotMultipart,
+		}
+	}
+	if err := r.ParseMultipartForm(multipartMaxMemory); err != nil {
+		return bodyError(err, "Invalid multipart form", "cannot parse multipart form")
+	}
+	return nil
+}
+
+// formFile is a file of the multipart request body, declared with [OptionFile] or [OptionFiles].
+type formFile struct {
+	name        string
+	description string
+	required    bool
+	multiple    bool
+}
+
+// OptionFile declares a file of the multipart/form-data request body, read with c.FormFile(name).
+// The request body is documented as multipart/form-data, with the file as a binary string
+// next to the fields of the body type.
+// The Required and Description param options are supported.
+func OptionFile(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
+	return optionFormFile(name, description, false, options...)
+}
+
+// OptionFiles declares a field of the multipart/form-data request body with several files, read with c.FormFiles().
+// See [OptionFile].
+func OptionFiles(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
+	return optionFormFile(name, description, true, options...)
+}
+
+func optionFormFile(name, description string, multiple bool, options ...func(*OpenAPIParam)) func(*BaseRoute) {
+	param := OpenAPIParam{Name: name, Description: description}
+	for _, option := range options {
+		option(&param)
+	}
+	file := formFile{name: name, description: param.Description, required: param.Required, multiple: multiple}
+
+	return func(r *BaseRoute) {
+		for i, existing := range r.formFiles {
+			if existing.name == name {
+				r.formFiles[i] = file
+				return
+			}
+		}
+		r.formFiles = append(r.formFiles, file)
+	}
+}
+
+// newMultipartRequestBody documents the multipart/form-data request body with the files of the route,
+// and the fields of the body type if any.
+func newMultipartRequestBody(files []formFile, bodyTag *SchemaTag) *openapi3.RequestBody {
+	filesSchema := openapi3.NewObjectSchema()
+	for _, file := range files {
+		schema := openapi3.NewStringSchema().WithFormat("binary")
+		if file.multiple {
+			schema = openapi3.NewArraySchema().WithItems(schema)
+		}
+		schema.Description = file.description
+		filesSchema.WithPropertyRef(file.name, schema.NewRef())
+		if file.required {
+			filesSchema.Required = append(filesSchema.Required, file.name)
+		}
+	}
+
+	schema := filesSchema
+	if bodyTag != nil {
+		schema = openapi3.NewAllOfSchema()
+		schema.AllOf = openapi3.SchemaRefs{&bodyTag.SchemaRef, filesSchema.NewRef()}
+	}
+	return openapi3.NewRequestBody().
+		WithRequired(true).
+		WithDescription("Multipart request body").
+		WithContent(openapi3.NewContentWithFormDataSchema(schema))
+}
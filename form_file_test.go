@@ -0,0 +1,156 @@
+package fuego
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartRequest builds a multipart/form-data request with the fields and files, by name.
+func multipartRequest(t *testing.T, path string, fields map[string]string, files map[string][]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	for name, contents := range files {
+		for i, content := range contents {
+			part, err := writer.CreateFormFile(name, name+string(rune('a'+i))+".txt")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, writer.Close())
+
+	r := httptest.NewRequest(http.MethodPost, path, &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func readFormFile(t *testing.T, header *multipart.FileHeader) string {
+	t.Helper()
+	file, err := header.Open()
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	return string(content)
+}
+
+type avatarForm struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestFormFile(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Post(s, "/avatars", func(c ContextWithBody[avatarForm]) (string, error) {
+		body, err := c.Body()
+		if err != nil {
+			return "", err
+		}
+		header, err := c.FormFile("avatar")
+		if err != nil {
+			return "", err
+		}
+		return body.Name + ": " + header.Filename + " " + readFormFile(t, header), nil
+	}, OptionFile("avatar", "Profile picture", ParamRequired()))
+	Post(s, "/attachments", func(c ContextNoBody) (string, error) {
+		files, err := c.FormFiles()
+		if err != nil {
+			return "", err
+		}
+		var contents []string
+		for _, header := range files["attachments"] {
+			contents = append(contents, readFormFile(t, header))
+		}
+		sort.Strings(contents)
+		return strings.Join(contents, ","), nil
+	}, OptionFiles("attachments", "Attachments of the message"))
+
+	t.Run("reads the file and the fields", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, multipartRequest(t, "/avatars", map[string]string{"name": "alice"}, map[string][]string{"avatar": {"png"}}))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, "alice: avatara.txt png", w.Body.String())
+	})
+
+	t.Run("reads several files", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, multipartRequest(t, "/attachments", nil, map[string][]string{"attachments": {"one", "two"}}))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, "one,two", w.Body.String())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, multipartRequest(t, "/avatars", map[string]string{"name": "alice"}, nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Missing file")
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/attachments", strings.NewReader(`{}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithMaxBodySize(100))
+		Post(s, "/attachments", func(c ContextNoBody) (any, error) {
+			_, err := c.FormFiles()
+			return nil, err
+		})
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, multipartRequest(t, "/attachments", nil, map[string][]string{"attachments": {strings.Repeat("a", 1000)}}))
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("documents the multipart body", func(t *testing.T) {
+		spec := s.OutputOpenAPISpec()
+
+		body := spec.Paths.Find("/avatars").Post.RequestBody.Value
+		require.Len(t, body.Content, 1)
+		schema := body.Content.Get("multipart/form-data").Schema.Value
+		require.Len(t, schema.AllOf, 2)
+		require.Equal(t, "#/components/schemas/avatarForm", schema.AllOf[0].Ref)
+		files := schema.AllOf[1].Value
+		require.Equal(t, []string{"avatar"}, files.Required)
+		require.Equal(t, "binary", files.Properties["avatar"].Value.Format)
+		require.Equal(t, "Profile picture", files.Properties["avatar"].Value.Description)
+
+		schema = spec.Paths.Find("/attachments").Post.RequestBody.Value.Content.Get("multipart/form-data").Schema.Value
+		require.Empty(t, schema.AllOf, "no body type")
+		attachments := schema.Properties["attachments"].Value
+		require.True(t, attachments.Type.Is(openapi3.TypeArray))
+		require.Equal(t, "binary", attachments.Items.Value.Format)
+		require.Empty(t, schema.Required)
+	})
+}
+
+func TestMockContextFormFile(t *testing.T) {
+	c := NewMockContextNoBody().SetFile("avatar", "me.png", []byte("png"))
+
+	header, err := c.FormFile("avatar")
+	require.NoError(t, err)
+	require.Equal(t, "me.png", header.Filename)
+	require.Equal(t, "png", readFormFile(t, header))
+
+	_, err = c.FormFile("other")
+	require.ErrorAs(t, err, &BadRequestError{})
+
+	files, err := c.FormFiles()
+	require.NoError(t, err)
+	require.Len(t, files["avatar"], 1)
+}
@@ -10,6 +10,8 @@ require (
 	github.com/gorilla/schema v1.4.1
 	github.com/stretchr/testify v1.10.0
 	github.com/thejerf/slogassert v0.3.4
+	golang.org/x/crypto v0.32.0
+	golang.org/x/net v0.34.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -29,8 +31,6 @@ require (
 	github.com/perimeterx/marshmallow v1.1.5 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/ugorji/go/codec v1.2.12 // indirect
-	golang.org/x/crypto v0.32.0 // indirect
-	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 )
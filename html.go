@@ -2,12 +2,14 @@ package fuego
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // CtxRenderer is an interface that can be used to render a response.
@@ -62,11 +64,24 @@ type StdRenderer struct {
 	templates         *template.Template
 	templateToExecute string
 	layoutsGlobs      []string
+	// Timeout of the rendering, set with [WithRenderTimeout].
+	timeout time.Duration
 }
 
 var _ CtxRenderer = StdRenderer{}
 
+// Render executes the template. It stops writing, and returns an error, as soon as the context is done:
+// when the client disconnects, or after the timeout set with [WithRenderTimeout].
 func (s StdRenderer) Render(ctx context.Context, w io.Writer) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return renderAbortedError(err, s.templateToExecute)
+	}
+
 	if strings.Contains(s.templateToExecute, "/") || strings.Contains(s.templateToExecute, "*") {
 		s.layoutsGlobs = append(s.layoutsGlobs, s.templateToExecute) // To override all blocks defined in the main template
 		cloned := template.Must(s.templates.Clone())
@@ -92,7 +107,10 @@ func (s StdRenderer) Render(ctx context.Context, w io.Writer) error {
 	myTemplate := strings.Split(s.templateToExecute, "/")
 	s.templateToExecute = myTemplate[len(myTemplate)-1]
 
-	err := s.templates.ExecuteTemplate(w, s.templateToExecute, s.data)
+	err := s.templates.ExecuteTemplate(contextWriter{ctx: ctx, w: w}, s.templateToExecute, s.data)
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return renderAbortedError(ctxErr, s.templateToExecute)
+	}
 	if err != nil {
 		return HTTPError{
 			Err:    err,
@@ -111,14 +129,58 @@ func (s StdRenderer) Render(ctx context.Context, w io.Writer) error {
 	return err
 }
 
+// contextWriter fails the writes once the context is done, so that the execution of a template
+// is aborted between its partials instead of writing the whole page to a dead connection.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
+
+// renderAbortedError is the error of a rendering aborted by the context.
+func renderAbortedError(err error, templateName string) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return HTTPError{
+			Err:    err,
+			Status: http.StatusServiceUnavailable,
+			Title:  "Rendering timed out",
+			Detail: fmt.Sprintf("template '%s' not rendered in time", templateName),
+		}
+	}
+	return HTTPError{
+		Err:    err,
+		Status: StatusClientClosedRequest,
+		Title:  "Client closed request",
+		Detail: fmt.Sprintf("rendering of template '%s' canceled", templateName),
+	}
+}
+
 // loadTemplates
 func (s *Server) loadTemplates(patterns ...string) error {
-	tmpl, err := template.ParseFS(s.fs, patterns...)
+	tmpl, err := s.parseTemplates(patterns...)
 	if err != nil {
-		return fmt.Errorf("failed to parse templates: %w", err)
+		return err
 	}
 
 	s.template = tmpl
 
 	return nil
 }
+
+func (s *Server) parseTemplates(patterns ...string) (*template.Template, error) {
+	tmpl := template.New("").Funcs(TemplateFuncs())
+	if s.assets != nil {
+		tmpl.Funcs(s.assets.TemplateFuncs())
+	}
+	tmpl, err := tmpl.ParseFS(s.fs, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	return tmpl, nil
+}
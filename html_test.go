@@ -1,10 +1,14 @@
 package fuego
 
 import (
+	"context"
 	"embed"
+	"html/template"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -87,6 +91,60 @@ func TestRender(t *testing.T) {
 	})
 }
 
+type slowPage struct{}
+
+func (slowPage) Slow() string {
+	time.Sleep(20 * time.Millisecond)
+	return "slow"
+}
+
+func TestRenderAborted(t *testing.T) {
+	templates := template.Must(template.New("").Parse(
+		`{{define "nav.html"}}<nav>{{.Slow}}</nav>{{end}}{{define "page.html"}}<h1>Page</h1>{{template "nav.html" .}}<main>{{.Slow}}</main>{{end}}`,
+	))
+	newServer := func(options ...func(*Server)) *Server {
+		s := NewServer(append([]func(*Server){WithoutLogger(), WithTemplateFS(testdata), WithTemplates(templates)}, options...)...)
+		Get(s, "/page", func(c ContextNoBody) (CtxRenderer, error) {
+			return c.Render("page.html", slowPage{})
+		})
+		return s
+	}
+
+	t.Run("render timeout", func(t *testing.T) {
+		s := newServer(WithRenderTimeout(10 * time.Millisecond))
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+
+		require.True(t, strings.HasPrefix(w.Body.String(), "<h1>Page</h1><nav>"))
+		require.NotContains(t, w.Body.String(), "<main>", "aborted between the partials")
+		require.Contains(t, w.Body.String(), "Rendering timed out")
+	})
+
+	t.Run("client disconnected", func(t *testing.T) {
+		s := newServer()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil).WithContext(ctx))
+
+		require.Equal(t, StatusClientClosedRequest, w.Code)
+		require.NotContains(t, w.Body.String(), "<h1>")
+	})
+
+	t.Run("renderer", func(t *testing.T) {
+		renderer := StdRenderer{templates: templates, templateToExecute: "page.html", data: slowPage{}, timeout: 30 * time.Millisecond}
+		var out strings.Builder
+		err := renderer.Render(context.Background(), &out)
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		var httpErr HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		require.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode())
+		require.True(t, strings.HasPrefix(out.String(), "<h1>Page</h1><nav>"))
+		require.NotContains(t, out.String(), "</main>")
+	})
+}
+
 func BenchmarkRender(b *testing.B) {
 	s := NewServer(
 		WithTemplateFS(testdata),
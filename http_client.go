@@ -0,0 +1,24 @@
+package fuego
+
+import "net/http"
+
+// WithHTTPClient sets the base client returned by [ContextWithBody.HTTPClient], for the outgoing requests of the controllers.
+// Wrap its transport to trace the outgoing requests, for example with OpenTelemetry:
+//
+//	s := fuego.NewServer(
+//		fuego.WithEngineOptions(
+//			fuego.WithHTTPClient(&http.Client{
+//				Timeout:   10 * time.Second,
+//				Transport: otelhttp.NewTransport(http.DefaultTransport), // go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp
+//			}),
+//		),
+//	)
+func WithHTTPClient(client *http.Client) func(*Engine) {
+	return func(e *Engine) { e.httpClient = client }
+}
+
+// HTTPClient returns the base client set with [WithHTTPClient].
+// It is used by the adaptors to initialize the context.
+func (e *Engine) HTTPClient() *http.Client {
+	return e.httpClient
+}
@@ -0,0 +1,100 @@
+package fuego
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTransport records the outgoing requests instead of sending them.
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, r)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+}
+
+// requestIDHeaders returns the keys of the request ID headers, whatever their case.
+func requestIDHeaders(header http.Header) []string {
+	var keys []string
+	for key := range header {
+		if strings.EqualFold(key, "X-Request-ID") {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestHTTPClient(t *testing.T) {
+	transport := &recordingTransport{}
+	s := NewServer(
+		WithoutLogger(),
+		WithLoggingMiddleware(LoggingConfig{RequestIDFunc: func() string { return "generated-id" }}),
+		WithEngineOptions(WithHTTPClient(&http.Client{Transport: transport, Timeout: time.Minute})),
+	)
+	Get(s, "/orders", func(c ContextNoBody) (string, error) {
+		client := c.HTTPClient()
+		require.Equal(t, time.Minute, client.Timeout, "base client")
+
+		req, err := http.NewRequestWithContext(c, http.MethodGet, "http://inventory/items", nil)
+		require.NoError(t, err)
+		req.Header.Set("Baggage", "tenant=overridden")
+		res, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+
+		require.Empty(t, req.Header.Get("X-Request-ID"), "the request of the controller is not modified")
+		return "ok", nil
+	})
+
+	call := func(headers map[string]string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		for name, value := range headers {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		return transport.requests[len(transport.requests)-1]
+	}
+
+	t.Run("propagates the trace context and the request ID", func(t *testing.T) {
+		outgoing := call(map[string]string{
+			"X-Request-ID":  "incoming-id",
+			"traceparent":   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"tracestate":    "vendor=value",
+			"baggage":       "tenant=acme",
+			"Authorization": "Bearer secret",
+		})
+		require.Equal(t, []string{"incoming-id"}, outgoing.Header.Values("X-Request-ID"))
+		require.Len(t, requestIDHeaders(outgoing.Header), 1, "a single request ID header")
+		require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", outgoing.Header.Get("Traceparent"))
+		require.Equal(t, "vendor=value", outgoing.Header.Get("Tracestate"))
+		require.Equal(t, "tenant=overridden", outgoing.Header.Get("Baggage"), "headers set by the controller are kept")
+		require.Empty(t, outgoing.Header.Get("Authorization"), "only the trace and correlation headers")
+	})
+
+	t.Run("propagates the generated request ID", func(t *testing.T) {
+		outgoing := call(nil)
+		require.Equal(t, []string{"generated-id"}, outgoing.Header.Values("X-Request-ID"))
+		require.Len(t, requestIDHeaders(outgoing.Header), 1, "a single request ID header")
+		require.Empty(t, outgoing.Header.Get("Traceparent"))
+	})
+
+	t.Run("default client", func(t *testing.T) {
+		c := NewMockContextNoBody()
+		c.Headers.Set("X-Request-ID", "mock-id")
+		client := c.HTTPClient()
+		require.Zero(t, client.Timeout)
+		require.NotNil(t, client.Transport)
+	})
+}
@@ -2,8 +2,10 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
 	"strconv"
 	"time"
@@ -20,6 +22,10 @@ type OpenAPIParam struct {
 	Examples map[string]any
 	Type     ParamType
 
+	// Allowed values for the parameter.
+	// Types are checked at start-time.
+	Enum []any
+
 	// integer, string, bool
 	GoType string
 
@@ -28,8 +34,9 @@ type OpenAPIParam struct {
 	// If empty, it is required for 200 status codes.
 	StatusCodes []int
 
-	Required bool
-	Nullable bool
+	Required   bool
+	Nullable   bool
+	Deprecated bool
 }
 
 // CommonContext is a base context shared by all adaptors (net/http, gin, echo, etc...)
@@ -41,15 +48,69 @@ type CommonContext[B any] struct {
 
 	// default status code for the response
 	DefaultStatusCode int
+
+	// What happens when a query parameter not declared in the OpenAPI spec is read.
+	UndeclaredParamPolicy UndeclaredParamPolicy
+
+	// Transformers registered on the engine for the body type, applied in order after decoding.
+	BodyTransformers []any
+
+	// Validation scenario of the route, applied in addition to the validate tags.
+	ValidationScenario string
+
+	// Dependencies given to the ValidateCtx method of the body.
+	ValidationDeps any
+
+	// Timeout of the contexts of the database queries.
+	QueryTimeout time.Duration
+
+	// Base client of the outgoing requests. If nil, a client with the default transport.
+	BaseHTTPClient *http.Client
+
+	// First undeclared query parameter read with the [UndeclaredParamError] policy.
+	undeclaredParamErr error
 }
 
 type ParamType string // Query, Header, Cookie
 
+// UndeclaredParamPolicy defines what happens when a controller reads a query parameter
+// that is not declared in the OpenAPI spec of the route.
+type UndeclaredParamPolicy int
+
+const (
+	// UndeclaredParamWarn logs a warning. This is the default.
+	UndeclaredParamWarn UndeclaredParamPolicy = iota
+	// UndeclaredParamError responds with a 500 error once the controller returns.
+	UndeclaredParamError
+	// UndeclaredParamIgnore does nothing.
+	UndeclaredParamIgnore
+)
+
 // GetOpenAPIParams returns the OpenAPI parameters declared in the OpenAPI spec.
 func (c CommonContext[B]) GetOpenAPIParams() map[string]OpenAPIParam {
 	return c.OpenAPIParams
 }
 
+// GetBodyTransformers returns the transformers registered for the body type.
+func (c CommonContext[B]) GetBodyTransformers() []any {
+	return c.BodyTransformers
+}
+
+// GetValidationScenario returns the validation scenario of the route.
+func (c CommonContext[B]) GetValidationScenario() string {
+	return c.ValidationScenario
+}
+
+// GetQueryTimeout returns the timeout of the contexts of the database queries.
+func (c CommonContext[B]) GetQueryTimeout() time.Duration {
+	return c.QueryTimeout
+}
+
+// GetValidationDeps returns the dependencies given to the ValidateCtx method of the body.
+func (c CommonContext[B]) GetValidationDeps() any {
+	return c.ValidationDeps
+}
+
 func (c CommonContext[B]) Context() context.Context {
 	return c.CommonCtx
 }
@@ -69,6 +130,11 @@ func (c CommonContext[B]) Err() error {
 	return c.Context().Err()
 }
 
+// IsAborted reports whether the client has disconnected, canceling the request context.
+func (c CommonContext[B]) IsAborted() bool {
+	return errors.Is(c.Context().Err(), context.Canceled)
+}
+
 // Value implements the context interface via [net/http.Request.Context]
 func (c CommonContext[B]) Value(key any) any {
 	return c.Context().Value(key)
@@ -93,11 +159,8 @@ func (c CommonContext[B]) HasQueryParam(name string) bool {
 //	fuego.Get(s, "/test", myController,
 //	  option.Query("name", "Name", param.Default("hey"))
 //	)
-func (c CommonContext[B]) QueryParam(name string) string {
-	_, ok := c.OpenAPIParams[name]
-	if !ok {
-		slog.Warn("query parameter not expected in OpenAPI spec", "param", name, "expected_one_of", c.OpenAPIParams)
-	}
+func (c *CommonContext[B]) QueryParam(name string) string {
+	c.checkParamDeclared(name)
 
 	if !c.UrlValues.Has(name) {
 		defaultValue, _ := c.OpenAPIParams[name].Default.(string)
@@ -106,7 +169,7 @@ func (c CommonContext[B]) QueryParam(name string) string {
 	return c.UrlValues.Get(name)
 }
 
-func (c CommonContext[B]) QueryParamIntErr(name string) (int, error) {
+func (c *CommonContext[B]) QueryParamIntErr(name string) (int, error) {
 	param := c.QueryParam(name)
 	if param == "" {
 		defaultValue, ok := c.OpenAPIParams[name].Default.(int)
@@ -138,6 +201,16 @@ func (e QueryParamNotFoundError) Error() string {
 	return fmt.Errorf("param %s not found", e.ParamName).Error()
 }
 
+// QueryParamUndeclaredError is raised when a controller reads a query parameter
+// not declared in the OpenAPI spec, with the [UndeclaredParamError] policy.
+type QueryParamUndeclaredError struct {
+	ParamName string
+}
+
+func (e QueryParamUndeclaredError) Error() string {
+	return fmt.Sprintf("query parameter %s is not declared in the OpenAPI spec", e.ParamName)
+}
+
 type QueryParamInvalidTypeError struct {
 	Err          error
 	ParamName    string
@@ -150,14 +223,35 @@ func (e QueryParamInvalidTypeError) Error() string {
 }
 
 // QueryParamArr returns an slice of string from the given query parameter.
-func (c CommonContext[B]) QueryParamArr(name string) []string {
-	_, ok := c.OpenAPIParams[name]
-	if !ok {
-		slog.Warn("query parameter not expected in OpenAPI spec", "param", name)
-	}
+func (c *CommonContext[B]) QueryParamArr(name string) []string {
+	c.checkParamDeclared(name)
 	return c.UrlValues[name]
 }
 
+// UndeclaredParamErr returns the [QueryParamUndeclaredError] of the first query parameter read
+// but not declared in the OpenAPI spec, with the [UndeclaredParamError] policy.
+func (c CommonContext[B]) UndeclaredParamErr() error {
+	return c.undeclaredParamErr
+}
+
+// checkParamDeclared applies the [UndeclaredParamPolicy] if the query parameter is not declared in the OpenAPI spec.
+// With [UndeclaredParamError], it records a [QueryParamUndeclaredError], see [CommonContext.UndeclaredParamErr].
+func (c *CommonContext[B]) checkParamDeclared(name string) {
+	if _, ok := c.OpenAPIParams[name]; ok {
+		return
+	}
+
+	switch c.UndeclaredParamPolicy {
+	case UndeclaredParamIgnore:
+	case UndeclaredParamError:
+		if c.undeclaredParamErr == nil {
+			c.undeclaredParamErr = QueryParamUndeclaredError{ParamName: name}
+		}
+	default:
+		slog.Warn("query parameter not expected in OpenAPI spec", "param", name, "expected_one_of", c.OpenAPIParams)
+	}
+}
+
 // QueryParamInt returns the query parameter with the given name as an int.
 // If it does not exist, it returns the default value declared in the OpenAPI spec.
 // For example, if the query parameter is declared as:
@@ -168,7 +262,7 @@ func (c CommonContext[B]) QueryParamArr(name string) []string {
 //
 // and the query parameter does not exist, it will return 1.
 // If the query parameter does not exist and there is no default value, or if it is not an int, it returns 0.
-func (c CommonContext[B]) QueryParamInt(name string) int {
+func (c *CommonContext[B]) QueryParamInt(name string) int {
 	param, err := c.QueryParamIntErr(name)
 	if err != nil {
 		return 0
@@ -187,7 +281,7 @@ func (c CommonContext[B]) QueryParamInt(name string) int {
 //
 // and the query parameter does not exist in the HTTP request, it will return true.
 // Accepted values are defined as [strconv.ParseBool]
-func (c CommonContext[B]) QueryParamBoolErr(name string) (bool, error) {
+func (c *CommonContext[B]) QueryParamBoolErr(name string) (bool, error) {
 	param := c.QueryParam(name)
 	if param == "" {
 		defaultValue, ok := c.OpenAPIParams[name].Default.(bool)
@@ -220,7 +314,7 @@ func (c CommonContext[B]) QueryParamBoolErr(name string) (bool, error) {
 //	)
 //
 // and the query parameter does not exist in the HTTP request, it will return true.
-func (c CommonContext[B]) QueryParamBool(name string) bool {
+func (c *CommonContext[B]) QueryParamBool(name string) bool {
 	param, err := c.QueryParamBoolErr(name)
 	if err != nil {
 		return false
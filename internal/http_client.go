@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"net/http"
+)
+
+// PropagatedHeaders are the trace and correlation headers copied from the incoming requests to the outgoing ones,
+// in their canonical form.
+var PropagatedHeaders = []string{"X-Request-Id", "Traceparent", "Tracestate", "Baggage"}
+
+// PropagatingHTTPClient returns a copy of the base client of the engine, whose requests carry the trace
+// and correlation headers of the incoming request. The request ID generated by the server,
+// only set on the response, is propagated too.
+func (c CommonContext[B]) PropagatingHTTPClient(incoming, response http.Header) *http.Client {
+	headers := make(http.Header, len(PropagatedHeaders))
+	for _, name := range PropagatedHeaders {
+		for _, value := range incoming.Values(name) {
+			headers.Add(name, value)
+		}
+	}
+	if headers.Get("X-Request-Id") == "" && response != nil {
+		if requestID := response.Get("X-Request-Id"); requestID != "" {
+			headers.Set("X-Request-Id", requestID)
+		}
+	}
+
+	client := &http.Client{}
+	if c.BaseHTTPClient != nil {
+		*client = *c.BaseHTTPClient
+	}
+	client.Transport = propagatingTransport{base: client.Transport, headers: headers}
+	return client
+}
+
+// propagatingTransport adds the headers to the requests that do not already have them.
+type propagatingTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t propagatingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if len(t.headers) == 0 {
+		return base.RoundTrip(r)
+	}
+
+	// A RoundTripper must not modify the request.
+	r = r.Clone(r.Context())
+	for name, values := range t.headers {
+		if len(r.Header.Values(name)) == 0 {
+			for _, value := range values {
+				r.Header.Add(name, value)
+			}
+		}
+	}
+	return base.RoundTrip(r)
+}
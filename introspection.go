@@ -0,0 +1,128 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultRoutesIntrospectionPath is the path of the routes introspection endpoint.
+const DefaultRoutesIntrospectionPath = "/.well-known/fuego/routes"
+
+// RoutesIntrospectionConfig configures the routes introspection endpoint. See [WithRoutesIntrospection].
+type RoutesIntrospectionConfig struct {
+	// Path of the endpoint. Defaults to [DefaultRoutesIntrospectionPath].
+	Path string
+	// Checks the health of the service. If it returns an error, the endpoint responds with a 503 status code.
+	// If nil, the service is always reported as healthy.
+	HealthCheck func(ctx context.Context) error
+	// Options of the endpoint, for example [OptionMiddleware] to protect it with authentication.
+	RouteOptions []func(*BaseRoute)
+}
+
+// RoutesIntrospection describes the service and its routes.
+type RoutesIntrospection struct {
+	Service string       `json:"service"`
+	Version string       `json:"version"`
+	Health  RoutesHealth `json:"health"`
+	Routes  []RouteInfo  `json:"routes"`
+}
+
+// RoutesHealth is the health of the service.
+type RoutesHealth struct {
+	// "ok" or "unavailable"
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	Uptime    string    `json:"uptime"`
+}
+
+// RouteInfo describes a route of the service, as documented in the OpenAPI spec.
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operationId,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+}
+
+// WithRoutesIntrospection serves a JSON description of the service at /.well-known/fuego/routes:
+// its name and version, its health and the routes documented in the OpenAPI spec,
+// so that service meshes and internal portals can discover it.
+// The endpoint itself is hidden from the OpenAPI spec.
+//
+//	s := fuego.NewServer(
+//		fuego.WithRoutesIntrospection(fuego.RoutesIntrospectionConfig{
+//			HealthCheck:  db.PingContext,
+//			RouteOptions: []func(*fuego.BaseRoute){option.Middleware(adminOnly)},
+//		}),
+//	)
+func WithRoutesIntrospection(config RoutesIntrospectionConfig) func(*Server) {
+	if config.Path == "" {
+		config.Path = DefaultRoutesIntrospectionPath
+	}
+
+	return func(s *Server) {
+		s.routesIntrospection = &config
+	}
+}
+
+// registerRoutesIntrospection registers the routes introspection endpoint, if enabled.
+func (s *Server) registerRoutesIntrospection() {
+	if s.routesIntrospection == nil {
+		return
+	}
+	config := s.routesIntrospection
+
+	Get(s, config.Path, func(c ContextNoBody) (RoutesIntrospection, error) {
+		introspection := RoutesIntrospection{
+			Service: s.OpenAPI.Description().Info.Title,
+			Version: s.OpenAPI.Description().Info.Version,
+			Health: RoutesHealth{
+				Status:    "ok",
+				StartedAt: s.startTime,
+				Uptime:    s.now().Sub(s.startTime).Round(time.Second).String(),
+			},
+			Routes: s.routesInfo(),
+		}
+
+		if config.HealthCheck != nil {
+			if err := config.HealthCheck(c.Context()); err != nil {
+				introspection.Health.Status = "unavailable"
+				introspection.Health.Error = err.Error()
+				c.SetStatus(http.StatusServiceUnavailable)
+			}
+		}
+
+		return introspection, nil
+	}, append([]func(*BaseRoute){OptionHide()}, config.RouteOptions...)...)
+}
+
+// routesInfo returns the routes documented in the OpenAPI spec, sorted by path and method.
+func (e *Engine) routesInfo() []RouteInfo {
+	routes := []RouteInfo{}
+	for path, pathItem := range e.OpenAPI.Description().Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			routes = append(routes, RouteInfo{
+				Method:      method,
+				Path:        path,
+				OperationID: operation.OperationID,
+				Summary:     operation.Summary,
+				Tags:        operation.Tags,
+				Deprecated:  operation.Deprecated,
+			})
+		}
+	}
+
+	slices.SortFunc(routes, func(a, b RouteInfo) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	return routes
+}
@@ -0,0 +1,80 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutesIntrospection(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		s := NewServer()
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DefaultRoutesIntrospectionPath, nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("describes the documented routes", func(t *testing.T) {
+		s := NewServer(
+			WithRoutesIntrospection(RoutesIntrospectionConfig{}),
+		)
+		Get(s, "/pets", controller, OptionTags("pets"), OptionOperationID("listPets"))
+		Post(s, "/pets", controller, OptionDeprecated())
+		Get(s, "/hidden", controller, OptionHide())
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DefaultRoutesIntrospectionPath, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var introspection RoutesIntrospection
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &introspection))
+		require.Equal(t, s.OpenAPI.Description().Info.Title, introspection.Service)
+		require.Equal(t, s.OpenAPI.Description().Info.Version, introspection.Version)
+		require.Equal(t, "ok", introspection.Health.Status)
+		require.Len(t, introspection.Routes, 2)
+		require.Equal(t, RouteInfo{Method: http.MethodGet, Path: "/pets", OperationID: "listPets", Summary: introspection.Routes[0].Summary, Tags: []string{"pets"}}, introspection.Routes[0])
+		require.Equal(t, http.MethodPost, introspection.Routes[1].Method)
+		require.True(t, introspection.Routes[1].Deprecated)
+	})
+
+	t.Run("reports the health", func(t *testing.T) {
+		s := NewServer(
+			WithRoutesIntrospection(RoutesIntrospectionConfig{
+				Path: "/routes",
+				HealthCheck: func(ctx context.Context) error {
+					return errors.New("database unreachable")
+				},
+			}),
+		)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/routes", nil))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Contains(t, w.Body.String(), `"status":"unavailable"`)
+		require.Contains(t, w.Body.String(), "database unreachable")
+	})
+
+	t.Run("can be protected", func(t *testing.T) {
+		s := NewServer(
+			WithRoutesIntrospection(RoutesIntrospectionConfig{
+				RouteOptions: []func(*BaseRoute){
+					OptionMiddleware(func(next http.Handler) http.Handler {
+						return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusUnauthorized)
+						})
+					}),
+				},
+			}),
+		)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DefaultRoutesIntrospectionPath, nil))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,247 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+const contextKeyClientIP contextKey = "clientIP"
+
+// ErrIPNotAllowed is returned when the client IP address is rejected by an IP filter.
+var ErrIPNotAllowed = errors.New("IP address not allowed")
+
+// WithTrustedProxies declares the reverse proxies (IP addresses or CIDR ranges) in front of the server.
+// When a request comes from a trusted proxy, the client IP address is resolved from the
+// X-Forwarded-For (or X-Real-IP) header instead of the connection remote address,
+// and the public URL of the server advertised in the OpenAPI spec from the X-Forwarded-Proto
+// and X-Forwarded-Host headers (see [WithPublicURL]).
+// The resolved IP address is available with [ClientIP], and is used by the IP filters
+// ([WithIPFilter], [OptionAllowIPs], [OptionDenyIPs]).
+// For example:
+//
+//	s := fuego.NewServer(
+//		fuego.WithTrustedProxies("10.0.0.0/8", "127.0.0.1"),
+//	)
+//
+// It panics if an IP address or a CIDR range is invalid.
+func WithTrustedProxies(proxies ...string) func(*Server) {
+	trusted := mustParsePrefixes(proxies)
+	return func(s *Server) {
+		s.trustedProxies = append(s.trustedProxies, trusted...)
+	}
+}
+
+// clientIPMiddleware resolves the client IP address and stores it in the request context.
+// It is mounted before all global middlewares, see [Server.setup].
+func clientIPMiddleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			if ip.IsValid() {
+				r = r.WithContext(context.WithValue(r.Context(), contextKeyClientIP, ip))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP returns the IP address of the client.
+// If the request went through trusted proxies (see [WithTrustedProxies]), it is the resolved IP address,
+// otherwise it is the remote address of the connection.
+// Returns an empty string if the IP address cannot be determined.
+func ClientIP(r *http.Request) string {
+	ip := clientAddr(r)
+	if !ip.IsValid() {
+		return ""
+	}
+	return ip.String()
+}
+
+func clientAddr(r *http.Request) netip.Addr {
+	if ip, ok := r.Context().Value(contextKeyClientIP).(netip.Addr); ok {
+		return ip
+	}
+	return remoteAddr(r)
+}
+
+func remoteAddr(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
+
+// resolveClientIP walks the X-Forwarded-For chain from the right,
+// skipping trusted proxies, and returns the first untrusted address.
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) netip.Addr {
+	ip := remoteAddr(r)
+	if !ip.IsValid() || !containsAddr(trusted, ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for _, hop := range slices.Backward(forwarded) {
+		hopIP, err := netip.ParseAddr(strings.TrimSpace(hop))
+		if err != nil {
+			break
+		}
+		ip = hopIP.Unmap()
+		if !containsAddr(trusted, ip) {
+			return ip
+		}
+	}
+
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.Unmap()
+	}
+
+	return ip
+}
+
+// forwardedURL returns the URL of the server as requested by the client, like "https://api.example.com",
+// from the X-Forwarded-Proto and X-Forwarded-Host headers of a request coming from a trusted proxy.
+// It returns an empty string if the request does not come from a trusted proxy, or if the host is missing or invalid.
+func forwardedURL(r *http.Request, trusted []netip.Prefix) string {
+	ip := remoteAddr(r)
+	if !ip.IsValid() || !containsAddr(trusted, ip) {
+		return ""
+	}
+
+	// The first values are the ones set by the proxy closest to the client
+	host, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Host"), ",")
+	host = strings.TrimSpace(host)
+	if host == "" || strings.ContainsAny(host, "/?#@\\ ") {
+		return ""
+	}
+
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	switch proto = strings.ToLower(strings.TrimSpace(proto)); proto {
+	case "http", "https":
+	case "":
+		proto = "http"
+		if r.TLS != nil {
+			proto = "https"
+		}
+	default:
+		return ""
+	}
+
+	return proto + "://" + host
+}
+
+// IPFilterConfig configures an IP filter. See [WithIPFilter].
+// Both lists accept IP addresses and CIDR ranges.
+// Deny has precedence over Allow. If Allow is empty, all IP addresses that are not denied are allowed.
+type IPFilterConfig struct {
+	Allow []string
+	Deny  []string
+}
+
+// WithIPFilter filters all incoming requests by client IP address, including unregistered routes.
+// Rejected requests get a 403 Forbidden error.
+// To filter specific routes or groups, for example internal or admin endpoints,
+// use [OptionAllowIPs] and [OptionDenyIPs].
+// For example:
+//
+//	s := fuego.NewServer(
+//		fuego.WithTrustedProxies("10.0.0.1"),
+//		fuego.WithIPFilter(fuego.IPFilterConfig{
+//			Deny: []string{"203.0.113.0/24"},
+//		}),
+//	)
+//
+// It panics if an IP address or a CIDR range is invalid.
+func WithIPFilter(config IPFilterConfig) func(*Server) {
+	return WithGlobalMiddlewares(newIPFilter(config))
+}
+
+// OptionAllowIPs restricts the route to the given IP addresses or CIDR ranges.
+// Rejected requests get a 403 Forbidden error, documented in the OpenAPI spec.
+// The client IP address is resolved with [ClientIP].
+// For example:
+//
+//	admin := fuego.Group(s, "/admin", option.AllowIPs("10.0.0.0/8", "::1"))
+//
+// It panics if an IP address or a CIDR range is invalid.
+func OptionAllowIPs(cidrs ...string) func(*BaseRoute) {
+	return optionIPFilter(IPFilterConfig{Allow: cidrs})
+}
+
+// OptionDenyIPs rejects the given IP addresses or CIDR ranges on the route.
+// Rejected requests get a 403 Forbidden error, documented in the OpenAPI spec.
+// The client IP address is resolved with [ClientIP].
+//
+// It panics if an IP address or a CIDR range is invalid.
+func OptionDenyIPs(cidrs ...string) func(*BaseRoute) {
+	return optionIPFilter(IPFilterConfig{Deny: cidrs})
+}
+
+func optionIPFilter(config IPFilterConfig) func(*BaseRoute) {
+	filter := newIPFilter(config)
+	return func(r *BaseRoute) {
+		OptionMiddleware(filter)(r)
+		OptionAddResponse(http.StatusForbidden, "Forbidden _(IP address not allowed)_", Response{Type: HTTPError{}})(r)
+	}
+}
+
+func newIPFilter(config IPFilterConfig) func(http.Handler) http.Handler {
+	allowed := mustParsePrefixes(config.Allow)
+	denied := mustParsePrefixes(config.Deny)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientAddr(r)
+			if !ip.IsValid() || containsAddr(denied, ip) || (len(allowed) > 0 && !containsAddr(allowed, ip)) {
+				SendError(w, r, ForbiddenError{
+					Title:  "Forbidden",
+					Status: http.StatusForbidden,
+					Err:    fmt.Errorf("%w: %s", ErrIPNotAllowed, ip),
+					Detail: ErrIPNotAllowed.Error(),
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func mustParsePrefixes(cidrs []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip, err := netip.ParseAddr(cidr)
+			if err != nil {
+				panic(fmt.Sprintf("invalid IP address %q: %v", cidr, err))
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("invalid CIDR range %q: %v", cidr, err))
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+func containsAddr(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requestFrom(remoteAddr string, forwardedFor ...string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = remoteAddr
+	for _, f := range forwardedFor {
+		r.Header.Add("X-Forwarded-For", f)
+	}
+	return r
+}
+
+func TestClientIP(t *testing.T) {
+	s := NewServer(
+		WithoutLogger(),
+		WithTrustedProxies("10.0.0.0/8", "::1"),
+	)
+	GetStd(s, "/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(ClientIP(r)))
+	})
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		expected string
+	}{
+		{"without proxy", requestFrom("203.0.113.1:1234"), "203.0.113.1"},
+		{"untrusted proxy is ignored", requestFrom("203.0.113.1:1234", "198.51.100.1"), "203.0.113.1"},
+		{"trusted proxy", requestFrom("10.0.0.1:1234", "198.51.100.1"), "198.51.100.1"},
+		{"trusted proxy chain", requestFrom("10.0.0.1:1234", "198.51.100.1, 10.0.0.2, 10.0.0.3"), "198.51.100.1"},
+		{"spoofed header before untrusted hop", requestFrom("10.0.0.1:1234", "1.2.3.4, 198.51.100.1"), "198.51.100.1"},
+		{"multiple headers", requestFrom("[::1]:1234", "198.51.100.1", "10.0.0.2"), "198.51.100.1"},
+		{"only trusted hops", requestFrom("10.0.0.1:1234", "10.0.0.2"), "10.0.0.2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			serveWithGlobalMiddlewares(s, w, tc.request)
+			require.Equal(t, tc.expected, w.Body.String())
+		})
+	}
+
+	t.Run("X-Real-IP from trusted proxy", func(t *testing.T) {
+		r := requestFrom("10.0.0.1:1234")
+		r.Header.Set("X-Real-IP", "198.51.100.7")
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, r)
+		require.Equal(t, "198.51.100.7", w.Body.String())
+	})
+
+	t.Run("without trusted proxies", func(t *testing.T) {
+		r := requestFrom("10.0.0.1:1234", "198.51.100.1")
+		require.Equal(t, "10.0.0.1", ClientIP(r))
+	})
+}
+
+func TestForwardedURL(t *testing.T) {
+	trusted := mustParsePrefixes([]string{"10.0.0.0/8"})
+	request := func(remoteAddr, proto, host string) *http.Request {
+		r := requestFrom(remoteAddr)
+		if proto != "" {
+			r.Header.Set("X-Forwarded-Proto", proto)
+		}
+		if host != "" {
+			r.Header.Set("X-Forwarded-Host", host)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		expected string
+	}{
+		{"trusted proxy", request("10.0.0.1:1234", "https", "api.example.com"), "https://api.example.com"},
+		{"with port", request("10.0.0.1:1234", "http", "api.example.com:8443"), "http://api.example.com:8443"},
+		{"proto defaults to the connection", request("10.0.0.1:1234", "", "api.example.com"), "http://api.example.com"},
+		{"first values of the chain", request("10.0.0.1:1234", "HTTPS, http", "api.example.com, internal:9999"), "https://api.example.com"},
+		{"untrusted proxy", request("203.0.113.1:1234", "https", "evil.example.com"), ""},
+		{"without host", request("10.0.0.1:1234", "https", ""), ""},
+		{"invalid host", request("10.0.0.1:1234", "https", "evil.example.com/path"), ""},
+		{"invalid proto", request("10.0.0.1:1234", "javascript", "api.example.com"), ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, forwardedURL(tc.request, trusted))
+		})
+	}
+}
+
+func TestWithIPFilter(t *testing.T) {
+	s := NewServer(
+		WithoutLogger(),
+		WithIPFilter(IPFilterConfig{
+			Allow: []string{"192.168.0.0/16", "10.0.0.1"},
+			Deny:  []string{"192.168.1.0/24"},
+		}),
+	)
+	Get(s, "/", controller)
+
+	t.Run("allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, requestFrom("192.168.0.5:1234"))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, requestFrom("10.0.0.1:1234"))
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("not in allow list", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, requestFrom("10.0.0.2:1234"))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "IP address not allowed")
+	})
+
+	t.Run("deny has precedence", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, requestFrom("192.168.1.5:1234"))
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unregistered routes are filtered too", func(t *testing.T) {
+		r := requestFrom("10.0.0.2:1234")
+		r.URL.Path = "/unknown"
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid CIDR panics", func(t *testing.T) {
+		require.Panics(t, func() {
+			WithIPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/99"}})
+		})
+		require.Panics(t, func() {
+			OptionAllowIPs("not-an-ip")
+		})
+	})
+}
+
+func TestOptionAllowIPs(t *testing.T) {
+	s := NewServer(
+		WithoutLogger(),
+		WithTrustedProxies("10.0.0.1"),
+	)
+	admin := Group(s, "/admin", OptionAllowIPs("198.51.100.0/24"))
+	Get(admin, "/stats", controller)
+	Get(s, "/public", controller, OptionDenyIPs("203.0.113.1"))
+
+	t.Run("allowed through trusted proxy", func(t *testing.T) {
+		r := requestFrom("10.0.0.1:1234", "198.51.100.3")
+		r.URL.Path = "/admin/stats"
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		r := requestFrom("203.0.113.1:1234")
+		r.URL.Path = "/admin/stats"
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		r := requestFrom("203.0.113.1:1234")
+		r.URL.Path = "/public"
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		r = requestFrom("203.0.113.2:1234")
+		r.URL.Path = "/public"
+		w = httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("403 is documented", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/admin/stats").Get
+		require.NotNil(t, operation.Responses.Value("403"))
+	})
+}
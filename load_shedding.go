@@ -0,0 +1,249 @@
+package fuego
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLoadShed is returned when a request is rejected to protect the overloaded server. See [WithLoadShedding].
+var ErrLoadShed = errors.New("server overloaded")
+
+// Priority of a route for the load shedding: when the server is overloaded,
+// the requests of the lowest priorities are rejected first. See [OptionPriority].
+type Priority int
+
+const (
+	// PriorityLow routes are shed first, like exports or recommendations.
+	PriorityLow Priority = iota - 1
+	// PriorityNormal is the priority of the routes by default.
+	PriorityNormal
+	// PriorityHigh routes are shed last, like checkouts or logins.
+	PriorityHigh
+	// PriorityCritical routes are never shed, like health checks.
+	PriorityCritical
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	}
+	return "priority(" + strconv.Itoa(int(p)) + ")"
+}
+
+// Number of latencies kept per interval to estimate the p99 latency.
+const loadSheddingSamples = 1024
+
+// LoadSheddingPolicy defines when the server is overloaded. See [WithLoadShedding].
+// At least one threshold must be set.
+type LoadSheddingPolicy struct {
+	// p99 latency of the routes above which the server is overloaded.
+	MaxLatency time.Duration
+	// CPU usage of the Go runtime (between 0 and 1, as estimated by runtime/metrics) above which the server is overloaded.
+	MaxCPU float64
+	// Number of goroutines above which the server is overloaded.
+	MaxGoroutines int
+	// Interval between two evaluations of the load. Defaults to 1 second.
+	Interval time.Duration
+}
+
+// WithLoadShedding rejects the requests of the lowest priorities with a 503 Service Unavailable error
+// while the server is overloaded, so it keeps serving the most important routes instead of collapsing.
+//
+// The load is evaluated at each interval. While the server is overloaded, one more priority is shed
+// at each interval, starting with [PriorityLow]; once it recovers, the priorities are restored one by one.
+// [PriorityCritical] routes are never shed. Rejected requests get a Retry-After header,
+// and the 503 error is documented in the OpenAPI spec.
+//
+//	s := fuego.NewServer(
+//		fuego.WithLoadShedding(fuego.LoadSheddingPolicy{MaxLatency: 500 * time.Millisecond, MaxCPU: 0.9}),
+//	)
+//
+//	fuego.Get(s, "/reports/export", exportReports, option.Priority(fuego.PriorityLow))
+//	fuego.Get(s, "/health", health, option.Priority(fuego.PriorityCritical))
+func WithLoadShedding(policy LoadSheddingPolicy) func(*Server) {
+	if policy.MaxLatency <= 0 && policy.MaxCPU <= 0 && policy.MaxGoroutines <= 0 {
+		panic("load shedding needs a latency, CPU or goroutines threshold")
+	}
+	if policy.MaxCPU > 1 {
+		panic("load shedding CPU threshold must be between 0 and 1")
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = time.Second
+	}
+
+	shedder := newLoadShedder(policy)
+	return func(s *Server) {
+		shedder.now = s.now
+		s.loadShedder = shedder
+		s.routeOptions = append(s.routeOptions,
+			OptionAddResponse(http.StatusServiceUnavailable, "Service Unavailable _(server overloaded)_", Response{Type: HTTPError{}}),
+		)
+	}
+}
+
+// OptionPriority sets the priority of the route for the load shedding (see [WithLoadShedding]).
+// Defaults to [PriorityNormal].
+//
+//	fuego.Get(s, "/recommendations", getRecommendations, option.Priority(fuego.PriorityLow))
+func OptionPriority(priority Priority) func(*BaseRoute) {
+	if priority < PriorityLow || priority > PriorityCritical {
+		panic("unknown priority " + strconv.Itoa(int(priority)))
+	}
+	return func(r *BaseRoute) {
+		r.Priority = priority
+	}
+}
+
+type loadShedder struct {
+	policy LoadSheddingPolicy
+	// Clock of the evaluation intervals. See [WithClock].
+	now func() time.Time
+
+	// Requests of the priorities below are rejected.
+	shedBelow atomic.Int32
+
+	mu sync.Mutex
+	// Latencies of the requests served since the last evaluation, sampled.
+	latencies []time.Duration
+	served    int
+	nextEval  time.Time
+	cpu       cpuUsage
+}
+
+func newLoadShedder(policy LoadSheddingPolicy) *loadShedder {
+	l := &loadShedder{policy: policy, now: time.Now}
+	l.shedBelow.Store(int32(PriorityLow))
+	return l
+}
+
+// middleware rejects the requests of the shed priorities, and records the latency of the others.
+// It is mounted before the route middlewares, so rejected requests cost as little as possible.
+func (l *loadShedder) middleware(priority Priority) func(http.Handler) http.Handler {
+	retryAfter := strconv.Itoa(int(math.Ceil(l.policy.Interval.Seconds())))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l.evaluate(l.now())
+
+			if priority < PriorityCritical && int32(priority) < l.shedBelow.Load() {
+				w.Header().Set("Retry-After", retryAfter)
+				SendError(w, r, HTTPError{
+					Title:  "Service Unavailable",
+					Status: http.StatusServiceUnavailable,
+					Err:    ErrLoadShed,
+					Detail: ErrLoadShed.Error(),
+				})
+				return
+			}
+
+			start := time.Now()
+			next.ServeHTTP(w, r)
+			l.record(time.Since(start))
+		})
+	}
+}
+
+// record keeps a uniform sample of the latencies of the interval (reservoir sampling).
+func (l *loadShedder) record(latency time.Duration) {
+	if l.policy.MaxLatency <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.served++
+	if len(l.latencies) < loadSheddingSamples {
+		l.latencies = append(l.latencies, latency)
+	} else if i := rand.IntN(l.served); i < loadSheddingSamples { //nolint:gosec // no security purpose
+		l.latencies[i] = latency
+	}
+}
+
+// evaluate sheds one more priority if the server is overloaded, restores one otherwise.
+// It does nothing until the end of the interval.
+func (l *loadShedder) evaluate(now time.Time) {
+	if !l.mu.TryLock() {
+		// Another request is evaluating the load.
+		return
+	}
+	defer l.mu.Unlock()
+	if now.Before(l.nextEval) {
+		return
+	}
+	l.nextEval = now.Add(l.policy.Interval)
+
+	reason := l.overloaded()
+	l.latencies = l.latencies[:0]
+	l.served = 0
+
+	shedBelow := Priority(l.shedBelow.Load())
+	switch {
+	case reason != "" && shedBelow < PriorityCritical:
+		shedBelow++
+		slog.Warn("server overloaded, shedding requests", "reason", reason, "below_priority", shedBelow)
+	case reason == "" && shedBelow > PriorityLow:
+		shedBelow--
+		slog.Info("server load decreased, restoring requests", "below_priority", shedBelow)
+	}
+	l.shedBelow.Store(int32(shedBelow))
+}
+
+// overloaded returns the threshold crossed during the interval, or an empty string.
+func (l *loadShedder) overloaded() string {
+	if l.policy.MaxGoroutines > 0 && runtime.NumGoroutine() > l.policy.MaxGoroutines {
+		return "goroutines"
+	}
+	if l.policy.MaxCPU > 0 && l.cpu.usage() > l.policy.MaxCPU {
+		return "cpu"
+	}
+	if l.policy.MaxLatency > 0 && len(l.latencies) > 0 {
+		slices.Sort(l.latencies)
+		if l.latencies[len(l.latencies)*99/100] > l.policy.MaxLatency {
+			return "latency"
+		}
+	}
+	return ""
+}
+
+// cpuUsage measures the CPU usage of the Go runtime between two calls.
+type cpuUsage struct {
+	total, idle float64
+}
+
+// usage returns the share of the available CPU time used since the previous call, between 0 and 1.
+func (c *cpuUsage) usage() float64 {
+	samples := []metrics.Sample{
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+	}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindFloat64 || samples[1].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	total, idle := samples[0].Value.Float64(), samples[1].Value.Float64()
+
+	elapsed := total - c.total
+	used := elapsed - (idle - c.idle)
+	c.total, c.idle = total, idle
+	if elapsed <= 0 {
+		return 0
+	}
+	return used / elapsed
+}
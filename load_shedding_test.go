@@ -0,0 +1,115 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLoadShedding(t *testing.T) {
+	// The test process always runs more than one goroutine: the server is overloaded at the first request.
+	s := NewServer(WithoutLogger(), WithLoadShedding(LoadSheddingPolicy{MaxGoroutines: 1, Interval: time.Hour}))
+	Get(s, "/export", func(c ContextNoBody) (string, error) { return "ok", nil }, OptionPriority(PriorityLow))
+	Get(s, "/pets", func(c ContextNoBody) (string, error) { return "ok", nil })
+	Get(s, "/health", func(c ContextNoBody) (string, error) { return "ok", nil }, OptionPriority(PriorityCritical))
+
+	call := func(path string) *http.Response {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Result()
+	}
+
+	require.Equal(t, http.StatusOK, call("/pets").StatusCode)
+
+	res := call("/export")
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.Equal(t, "3600", res.Header.Get("Retry-After"))
+
+	require.Equal(t, http.StatusOK, call("/health").StatusCode)
+
+	t.Run("documents the 503 error", func(t *testing.T) {
+		require.NotNil(t, s.OpenAPI.Description().Paths.Value("/pets").Get.Responses.Value("503"))
+	})
+}
+
+func TestLoadShedder(t *testing.T) {
+	t.Run("sheds one more priority at each interval while overloaded", func(t *testing.T) {
+		l := newLoadShedder(LoadSheddingPolicy{MaxLatency: 10 * time.Millisecond, Interval: time.Second})
+		now := time.Now()
+		overload := func() {
+			for range 100 {
+				l.record(20 * time.Millisecond)
+			}
+			l.evaluate(now)
+			now = now.Add(time.Second)
+		}
+
+		overload()
+		require.Equal(t, PriorityNormal, Priority(l.shedBelow.Load()))
+		overload()
+		require.Equal(t, PriorityHigh, Priority(l.shedBelow.Load()))
+		overload()
+		overload()
+		require.Equal(t, PriorityCritical, Priority(l.shedBelow.Load()))
+
+		t.Run("not before the end of the interval", func(t *testing.T) {
+			l.evaluate(now.Add(-time.Millisecond))
+			require.Equal(t, PriorityCritical, Priority(l.shedBelow.Load()))
+		})
+
+		t.Run("restores the priorities once recovered", func(t *testing.T) {
+			for range 100 {
+				l.record(time.Millisecond)
+			}
+			l.evaluate(now)
+			require.Equal(t, PriorityHigh, Priority(l.shedBelow.Load()))
+
+			for range 3 {
+				now = now.Add(time.Second)
+				l.evaluate(now)
+			}
+			require.Equal(t, PriorityLow, Priority(l.shedBelow.Load()))
+		})
+	})
+
+	t.Run("p99 latency", func(t *testing.T) {
+		l := newLoadShedder(LoadSheddingPolicy{MaxLatency: 10 * time.Millisecond})
+		record := func(slowEvery int) {
+			for i := range 1000 {
+				latency := time.Millisecond
+				if i%slowEvery == 0 {
+					latency = time.Second
+				}
+				l.record(latency)
+			}
+		}
+
+		record(200)
+		require.Empty(t, l.overloaded(), "0.5% of slow requests")
+
+		l.latencies = l.latencies[:0]
+		record(50)
+		require.Equal(t, "latency", l.overloaded(), "2% of slow requests")
+	})
+
+	t.Run("keeps a sample of the latencies", func(t *testing.T) {
+		l := newLoadShedder(LoadSheddingPolicy{MaxLatency: 10 * time.Millisecond})
+		for range 5000 {
+			l.record(time.Millisecond)
+		}
+		require.Len(t, l.latencies, loadSheddingSamples)
+	})
+}
+
+func TestOptionPriority(t *testing.T) {
+	route := BaseRoute{}
+	OptionPriority(PriorityHigh)(&route)
+	require.Equal(t, PriorityHigh, route.Priority)
+	require.Equal(t, "high", route.Priority.String())
+
+	require.Panics(t, func() { OptionPriority(Priority(5)) })
+	require.Panics(t, func() { WithLoadShedding(LoadSheddingPolicy{}) })
+}
@@ -0,0 +1,458 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrSessionRevoked is returned by [Security.ValidateToken] for the tokens of a session ended by a logout.
+var ErrSessionRevoked = errors.New("session is revoked")
+
+// backChannelLogoutEvent is the event of the logout tokens sent to the back-channel logout URIs.
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// SessionStore stores the revoked sessions, identified by the "sid" claim of the tokens.
+// See [Security.Sessions]. Implement it on top of a shared database or cache when the server runs on several instances,
+// or use [NewInMemorySessionStore].
+type SessionStore interface {
+	// Revoke revokes the session until the given time, after which all its tokens are expired anyway.
+	Revoke(ctx context.Context, sid string, until time.Time) error
+	// IsRevoked reports whether the session is revoked.
+	IsRevoked(ctx context.Context, sid string) (bool, error)
+}
+
+// InMemorySessionStore is a [SessionStore] for a single instance of the server.
+type InMemorySessionStore struct {
+	// Clock of the revocations. Set to the clock of the server by [WithLogout], see [WithClock].
+	now func() time.Time
+
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+var _ SessionStore = &InMemorySessionStore{}
+
+// NewInMemorySessionStore creates an empty [InMemorySessionStore].
+func NewInMemorySessionStore() *InMemorySessionStore {
+	return &InMemorySessionStore{
+		now:     time.Now,
+		revoked: make(map[string]time.Time),
+	}
+}
+
+func (store *InMemorySessionStore) Revoke(_ context.Context, sid string, until time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// Forget the revocations of the sessions whose tokens are all expired
+	now := store.now()
+	for id, end := range store.revoked {
+		if end.Before(now) {
+			delete(store.revoked, id)
+		}
+	}
+	store.revoked[sid] = until
+	return nil
+}
+
+func (store *InMemorySessionStore) IsRevoked(_ context.Context, sid string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	until, ok := store.revoked[sid]
+	return ok && !until.Before(store.now()), nil
+}
+
+// sessionID returns the "sid" claim of the claims, if any.
+func sessionID(claims jwt.Claims) string {
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	sid, _ := mapClaims["sid"].(string)
+	return sid
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RevokeSession ends the session of the claims: its tokens are rejected by [Security.ValidateToken]
+// until they expire. It does nothing if the sessions are not enabled, or if the claims have no "sid" claim.
+func (security Security) RevokeSession(ctx context.Context, claims jwt.Claims) error {
+	sid := sessionID(claims)
+	if security.Sessions == nil || sid == "" {
+		return nil
+	}
+	return security.Sessions.Revoke(ctx, sid, security.Now().Add(security.ExpiresInterval))
+}
+
+// LogoutClient is an application (relying party) logged in with the tokens of the server.
+type LogoutClient struct {
+	// ID of the client, as the client_id parameter of the end-session requests and audience of the logout tokens.
+	ID string
+	// URIs the user agent can be redirected to after the logout, given as post_logout_redirect_uri.
+	// Other URIs are rejected, to avoid open redirects.
+	PostLogoutRedirectURIs []string
+	// URI loaded in an iframe of the logout page, with the iss and sid query parameters (OpenID Connect Front-Channel Logout).
+	FrontChannelLogoutURI string
+	// URI receiving a logout token in a POST request (OpenID Connect Back-Channel Logout).
+	BackChannelLogoutURI string
+}
+
+// LogoutConfig configures the end-session endpoint registered by [WithLogout].
+type LogoutConfig struct {
+	// Path of the end-session endpoint. Defaults to "/auth/end-session".
+	Path string
+	// Issuer of the tokens, as the iss claim of the logout tokens. Defaults to the base URL of the request.
+	Issuer string
+	// Clients notified of the logouts.
+	Clients []LogoutClient
+	// Client sending the logout tokens to the back-channel logout URIs. Defaults to a client with a 5s timeout.
+	HTTPClient *http.Client
+}
+
+// WithLogout registers an OpenID Connect RP-initiated logout endpoint (see [LogoutConfig.Path]), accepting
+// GET and POST requests with the id_token_hint, client_id, post_logout_redirect_uri and state parameters.
+// The GET requests without id_token_hint only end the session after the user confirms it, posting a form:
+// otherwise any site could log the users out, for example with an <img> tag.
+// It enables the sessions of the tokens: [Security.GenerateToken] adds a "sid" claim to the [jwt.MapClaims],
+// and ending a session revokes all its tokens, stored in an [InMemorySessionStore] unless [Security.Sessions] is already set.
+// The clients are notified with front-channel iframes and back-channel logout tokens.
+// The flows are documented in the security schemes of the OpenAPI spec.
+//
+//	s := fuego.NewServer(
+//		fuego.WithAutoAuth(verifyUserInfo),
+//		fuego.WithLogout(fuego.LogoutConfig{
+//			Clients: []fuego.LogoutClient{{
+//				ID:                     "dashboard",
+//				PostLogoutRedirectURIs: []string{"https://dashboard.example.com/"},
+//				BackChannelLogoutURI:   "https://dashboard.example.com/backchannel-logout",
+//			}},
+//		}),
+//	)
+func WithLogout(config LogoutConfig) func(*Server) {
+	if config.Path == "" {
+		config.Path = "/auth/end-session"
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return func(s *Server) {
+		s.logout = &config
+		if s.Security.Sessions == nil {
+			s.Security.Sessions = NewInMemorySessionStore()
+		}
+	}
+}
+
+// registerLogout registers the end-session endpoint configured with [WithLogout].
+func (s *Server) registerLogout() {
+	if s.logout == nil {
+		return
+	}
+	if store, ok := s.Security.Sessions.(*InMemorySessionStore); ok {
+		store.now = s.now
+	}
+
+	options := []func(*BaseRoute){
+		OptionTags("Auth"),
+		OptionSummary("End session"),
+		OptionDescription("Ends the session of the user: its tokens are revoked, and the clients are notified with front-channel and back-channel logouts. " +
+			"The GET requests without id_token_hint get a page asking the user to confirm the logout."),
+		OptionQuery("id_token_hint", "Token of the session to end. Defaults to the token of the request."),
+		OptionQuery("client_id", "Client requesting the logout"),
+		OptionQuery("post_logout_redirect_uri", "URI to redirect to after the logout, registered by the client"),
+		OptionQuery("state", "Value passed back to the post-logout redirect URI"),
+	}
+	GetStd(s, s.logout.Path, s.endSession, options...)
+	PostStd(s, s.logout.Path, s.endSession, options...)
+	s.documentLogout()
+}
+
+// endSession implements the OpenID Connect RP-initiated logout.
+func (s *Server) endSession(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.sessionToEnd(r)
+	if err != nil {
+		SendJSONError(w, nil, BadRequestError{Title: "Invalid id_token_hint", Err: err})
+		return
+	}
+
+	redirectURI := r.FormValue("post_logout_redirect_uri")
+	if redirectURI != "" && !s.logout.allowsRedirect(r.FormValue("client_id"), redirectURI) {
+		SendJSONError(w, nil, BadRequestError{Title: "Unregistered post_logout_redirect_uri"})
+		return
+	}
+	// Without id_token_hint, the GET request may come from another site, like an <img> tag:
+	// the user confirms the logout, posting the form to this endpoint.
+	if r.Method == http.MethodGet && r.FormValue("id_token_hint") == "" {
+		s.confirmLogout(w, r)
+		return
+	}
+	if state := r.FormValue("state"); redirectURI != "" && state != "" {
+		redirectURI = withQueryParam(redirectURI, "state", state)
+	}
+
+	if err := s.Security.RevokeSession(r.Context(), claims); err != nil {
+		SendJSONError(w, nil, err)
+		return
+	}
+	s.Security.CookieLogoutHandler(w, r)
+
+	issuer := s.logout.Issuer
+	if issuer == "" {
+		issuer = requestBaseURL(r, s)
+	}
+	sid := sessionID(claims)
+	if claims != nil {
+		s.backChannelLogout(context.WithoutCancel(r.Context()), issuer, claims)
+	}
+
+	var frames []string
+	for _, client := range s.logout.Clients {
+		if client.FrontChannelLogoutURI != "" && sid != "" {
+			frames = append(frames, withQueryParam(withQueryParam(client.FrontChannelLogoutURI, "iss", issuer), "sid", sid))
+		}
+	}
+	switch {
+	case len(frames) > 0:
+		var page bytes.Buffer
+		if err := frontChannelLogoutPage.Execute(&page, frontChannelLogout{Frames: frames, RedirectURI: redirectURI}); err != nil {
+			SendJSONError(w, nil, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(page.Bytes())
+	case redirectURI != "":
+		http.Redirect(w, r, redirectURI, http.StatusSeeOther)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// confirmLogout serves the page asking the user to confirm the logout.
+func (s *Server) confirmLogout(w http.ResponseWriter, r *http.Request) {
+	params := map[string]string{}
+	for _, name := range []string{"client_id", "post_logout_redirect_uri", "state"} {
+		if value := r.FormValue(name); value != "" {
+			params[name] = value
+		}
+	}
+	var page bytes.Buffer
+	if err := confirmLogoutPage.Execute(&page, confirmLogout{Action: r.URL.Path, Params: params}); err != nil {
+		SendJSONError(w, nil, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(page.Bytes())
+}
+
+// sessionToEnd returns the claims of the id_token_hint, or of the token of the request.
+// The id_token_hint can be expired: only its signature is verified.
+func (s *Server) sessionToEnd(r *http.Request) (jwt.Claims, error) {
+	if hint := r.FormValue("id_token_hint"); hint != "" {
+		t, err := jwt.Parse(hint, func(token *jwt.Token) (interface{}, error) {
+			return s.Security.key.Public(), nil
+		},
+			jwt.WithValidMethods([]string{"ES256"}),
+			jwt.WithoutClaimsValidation(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return t.Claims, nil
+	}
+
+	if claims, err := TokenFromContext(r.Context()); err == nil {
+		return claims, nil
+	}
+	for _, token := range []string{TokenFromCookie(r), TokenFromHeader(r)} {
+		if token == "" {
+			continue
+		}
+		if t, err := s.Security.ValidateToken(token); err == nil {
+			return t.Claims, nil
+		}
+	}
+	return nil, nil
+}
+
+// allowsRedirect reports whether the URI is a post-logout redirect URI of the client,
+// or of any client if the client is not given.
+func (config *LogoutConfig) allowsRedirect(clientID, uri string) bool {
+	for _, client := range config.Clients {
+		if (clientID == "" || client.ID == clientID) && slices.Contains(client.PostLogoutRedirectURIs, uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// backChannelLogout sends a logout token to the back-channel logout URI of each client.
+// Failures are logged: the session is revoked anyway.
+func (s *Server) backChannelLogout(ctx context.Context, issuer string, claims jwt.Claims) {
+	subject, _ := claims.GetSubject()
+	sid := sessionID(claims)
+	if subject == "" && sid == "" {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, client := range s.logout.Clients {
+		if client.BackChannelLogoutURI == "" {
+			continue
+		}
+		logoutClaims := jwt.MapClaims{
+			"iss":    issuer,
+			"aud":    client.ID,
+			"iat":    s.now().Unix(),
+			"exp":    s.now().Add(2 * time.Minute).Unix(),
+			"jti":    newSessionID(),
+			"events": map[string]any{backChannelLogoutEvent: map[string]any{}},
+		}
+		if subject != "" {
+			logoutClaims["sub"] = subject
+		}
+		if sid != "" {
+			logoutClaims["sid"] = sid
+		}
+		// Signed directly: GenerateToken would add a session to the logout token
+		token, err := jwt.NewWithClaims(jwt.SigningMethodES256, logoutClaims).SignedString(s.Security.key)
+		if err != nil {
+			slog.Error("Cannot sign the logout token", "client", client.ID, "error", err)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.logout.sendLogoutToken(ctx, client.BackChannelLogoutURI, token); err != nil {
+				slog.Warn("Back-channel logout failed", "client", client.ID, "uri", client.BackChannelLogoutURI, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (config *LogoutConfig) sendLogoutToken(ctx context.Context, uri, token string) error {
+	body := url.Values{"logout_token": {token}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return errors.New("unexpected status " + res.Status)
+	}
+	return nil
+}
+
+func withQueryParam(uri, key, value string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+type frontChannelLogout struct {
+	Frames      []string
+	RedirectURI string
+}
+
+// frontChannelLogoutPage loads the front-channel logout URIs of the clients,
+// then redirects to the post-logout redirect URI once they are all loaded.
+var frontChannelLogoutPage = template.Must(template.New("logout").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Logout</title></head>
+<body>
+<p>You are logged out.</p>
+{{range .Frames}}<iframe src="{{.}}" style="display:none"></iframe>
+{{end}}{{with .RedirectURI}}<script>
+let pending = document.getElementsByTagName("iframe").length;
+const done = () => { if (--pending <= 0) window.location.replace({{.}}); };
+for (const frame of document.getElementsByTagName("iframe")) frame.addEventListener("load", done);
+setTimeout(() => window.location.replace({{.}}), 5000);
+</script>
+<noscript><a href="{{.}}">Continue</a></noscript>{{end}}
+</body>
+</html>
+`))
+
+// documentLogout documents the tokens and the logout flows in the security schemes of the OpenAPI spec.
+func (s *Server) documentLogout() {
+	components := s.OpenAPI.Description().Components
+	if components.SecuritySchemes == nil {
+		components.SecuritySchemes = openapi3.SecuritySchemes{}
+	}
+
+	frontChannel := slices.ContainsFunc(s.logout.Clients, func(c LogoutClient) bool { return c.FrontChannelLogoutURI != "" })
+	backChannel := slices.ContainsFunc(s.logout.Clients, func(c LogoutClient) bool { return c.BackChannelLogoutURI != "" })
+	logout := map[string]any{
+		"end_session_endpoint":                  s.basePath + s.logout.Path,
+		"frontchannel_logout_supported":         frontChannel,
+		"frontchannel_logout_session_supported": frontChannel,
+		"backchannel_logout_supported":          backChannel,
+		"backchannel_logout_session_supported":  backChannel,
+	}
+	description := "JWT signed with ES256, sent in the Authorization header or in the " + JWTCookieName + " cookie. " +
+		"Each token belongs to a session (sid claim). Ending the session at " + s.basePath + s.logout.Path +
+		" revokes all its tokens, notifies the clients with front-channel iframes (iss and sid query parameters) " +
+		"and back-channel logout tokens (POST, logout_token form parameter), " +
+		"then redirects to the registered post_logout_redirect_uri, with the state parameter."
+
+	if _, exists := components.SecuritySchemes["bearerAuth"]; !exists {
+		scheme := openapi3.NewJWTSecurityScheme().WithDescription(description)
+		scheme.Extensions = map[string]any{"x-logout": logout}
+		components.SecuritySchemes["bearerAuth"] = &openapi3.SecuritySchemeRef{Value: scheme}
+	}
+	if _, exists := components.SecuritySchemes["cookieAuth"]; !exists {
+		scheme := openapi3.NewSecurityScheme().WithType("apiKey").WithIn("cookie").WithName(JWTCookieName).WithDescription(description)
+		scheme.Extensions = map[string]any{"x-logout": logout}
+		components.SecuritySchemes["cookieAuth"] = &openapi3.SecuritySchemeRef{Value: scheme}
+	}
+}
+
+type confirmLogout struct {
+	Action string
+	Params map[string]string
+}
+
+// confirmLogoutPage asks the user to confirm the logout requested without id_token_hint.
+var confirmLogoutPage = template.Must(template.New("confirm-logout").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Logout</title></head>
+<body>
+<form method="post" action="{{.Action}}">
+<p>Do you want to log out?</p>
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<button type="submit">Log out</button>
+</form>
+</body>
+</html>
+`))
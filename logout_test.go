@@ -0,0 +1,268 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemorySessionStore(t *testing.T) {
+	now := time.Now()
+	store := NewInMemorySessionStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, store.Revoke(ctx, "a", now.Add(time.Hour)))
+	revoked, err := store.IsRevoked(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, revoked)
+	revoked, _ = store.IsRevoked(ctx, "b")
+	require.False(t, revoked)
+
+	t.Run("revocations are forgotten once the tokens are expired", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		revoked, _ := store.IsRevoked(ctx, "a")
+		require.False(t, revoked)
+
+		require.NoError(t, store.Revoke(ctx, "b", now.Add(time.Hour)))
+		require.NotContains(t, store.revoked, "a")
+	})
+}
+
+func TestSessions(t *testing.T) {
+	security := NewSecurity()
+	security.Sessions = NewInMemorySessionStore()
+
+	claims := jwt.MapClaims{"sub": "123"}
+	token, err := security.GenerateToken(claims)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims["sid"])
+
+	_, err = security.ValidateToken(token)
+	require.NoError(t, err)
+
+	t.Run("all the tokens of a revoked session are rejected", func(t *testing.T) {
+		refreshed, err := security.GenerateToken(jwt.MapClaims{"sub": "123", "sid": claims["sid"]})
+		require.NoError(t, err)
+
+		require.NoError(t, security.RevokeSession(context.Background(), claims))
+		_, err = security.ValidateToken(token)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+		_, err = security.ValidateToken(refreshed)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+
+		other, err := security.GenerateToken(jwt.MapClaims{"sub": "123"})
+		require.NoError(t, err)
+		_, err = security.ValidateToken(other)
+		require.NoError(t, err, "other sessions")
+	})
+
+	t.Run("logout revokes the session", func(t *testing.T) {
+		claims := jwt.MapClaims{"sub": "123"}
+		token, err := security.GenerateToken(claims)
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+		r = r.WithContext(WithValue(r.Context(), claims))
+		security.CookieLogoutHandler(httptest.NewRecorder(), r)
+
+		_, err = security.ValidateToken(token)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("without sessions", func(t *testing.T) {
+		claims := jwt.MapClaims{"sub": "123"}
+		_, err := NewSecurity().GenerateToken(claims)
+		require.NoError(t, err)
+		require.NotContains(t, claims, "sid")
+	})
+}
+
+func TestWithLogout(t *testing.T) {
+	type backChannelCall struct {
+		path  string
+		token string
+	}
+	var mu sync.Mutex
+	var calls []backChannelCall
+	rp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, backChannelCall{path: r.URL.Path, token: r.FormValue("logout_token")})
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer rp.Close()
+
+	newServer := func(clients ...LogoutClient) *Server {
+		calls = nil
+		return NewServer(
+			WithoutLogger(),
+			WithAutoAuth(func(user, password string) (jwt.Claims, error) { return jwt.MapClaims{"sub": user}, nil }),
+			WithLogout(LogoutConfig{Issuer: "https://auth.example.com", Clients: clients}),
+		)
+	}
+	login := func(t *testing.T, s *Server) (string, jwt.MapClaims) {
+		t.Helper()
+		claims := jwt.MapClaims{"sub": "123"}
+		token, err := s.Security.GenerateToken(claims)
+		require.NoError(t, err)
+		return token, claims
+	}
+	endSession := func(s *Server, method, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, "/auth/end-session?"+query, nil)
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("revokes the session of the id_token_hint and redirects", func(t *testing.T) {
+		s := newServer(LogoutClient{ID: "dashboard", PostLogoutRedirectURIs: []string{"https://dashboard.example.com/bye"}})
+		token, _ := login(t, s)
+
+		w := endSession(s, http.MethodGet, url.Values{
+			"id_token_hint":            {token},
+			"post_logout_redirect_uri": {"https://dashboard.example.com/bye"},
+			"state":                    {"xyz"},
+		}.Encode())
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "https://dashboard.example.com/bye?state=xyz", w.Header().Get("Location"))
+		require.Equal(t, JWTCookieName, w.Result().Cookies()[0].Name)
+
+		_, err := s.Security.ValidateToken(token)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("expired id_token_hint", func(t *testing.T) {
+		s := newServer()
+		token, _ := login(t, s)
+		s.Security.Now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+
+		w := endSession(s, http.MethodPost, "id_token_hint="+token)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid id_token_hint", func(t *testing.T) {
+		token, _ := login(t, newServer())
+		w := endSession(newServer(), http.MethodGet, "id_token_hint="+token)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unregistered redirect URI", func(t *testing.T) {
+		s := newServer(
+			LogoutClient{ID: "dashboard", PostLogoutRedirectURIs: []string{"https://dashboard.example.com/bye"}},
+			LogoutClient{ID: "shop", PostLogoutRedirectURIs: []string{"https://shop.example.com/bye"}},
+		)
+		w := endSession(s, http.MethodGet, "post_logout_redirect_uri="+url.QueryEscape("https://evil.example.com"))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = endSession(s, http.MethodGet, "client_id=dashboard&post_logout_redirect_uri="+url.QueryEscape("https://shop.example.com/bye"))
+		require.Equal(t, http.StatusBadRequest, w.Code, "registered by another client")
+	})
+
+	t.Run("session of the cookie", func(t *testing.T) {
+		s := newServer()
+		token, _ := login(t, s)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/auth/end-session", nil)
+		r.AddCookie(&http.Cookie{Name: JWTCookieName, Value: token})
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		_, err := s.Security.ValidateToken(token)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("GET without id_token_hint asks for confirmation", func(t *testing.T) {
+		s := newServer(LogoutClient{ID: "dashboard", PostLogoutRedirectURIs: []string{"https://dashboard.example.com/bye"}})
+		token, _ := login(t, s)
+		query := url.Values{
+			"client_id":                {"dashboard"},
+			"post_logout_redirect_uri": {"https://dashboard.example.com/bye"},
+			"state":                    {"<xyz>"},
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/auth/end-session?"+query.Encode(), nil)
+		r.AddCookie(&http.Cookie{Name: JWTCookieName, Value: token})
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), `<form method="post" action="/auth/end-session">`)
+		require.Contains(t, w.Body.String(), `<input type="hidden" name="state" value="&lt;xyz&gt;">`)
+		require.Empty(t, w.Result().Cookies(), "the cookie is kept")
+		_, err := s.Security.ValidateToken(token)
+		require.NoError(t, err, "the session is not revoked")
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/auth/end-session", strings.NewReader(query.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: JWTCookieName, Value: token})
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "https://dashboard.example.com/bye?state=%3Cxyz%3E", w.Header().Get("Location"))
+		_, err = s.Security.ValidateToken(token)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("notifies the clients", func(t *testing.T) {
+		s := newServer(
+			LogoutClient{
+				ID:                     "dashboard",
+				PostLogoutRedirectURIs: []string{"https://dashboard.example.com/bye"},
+				FrontChannelLogoutURI:  "https://dashboard.example.com/frontchannel-logout",
+				BackChannelLogoutURI:   rp.URL + "/backchannel-logout",
+			},
+			LogoutClient{ID: "shop", BackChannelLogoutURI: rp.URL + "/broken"},
+		)
+		token, claims := login(t, s)
+
+		w := endSession(s, http.MethodGet, "id_token_hint="+token+"&post_logout_redirect_uri="+url.QueryEscape("https://dashboard.example.com/bye"))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), `<iframe src="https://dashboard.example.com/frontchannel-logout?iss=https%3A%2F%2Fauth.example.com&amp;sid=`+claims["sid"].(string)+`"`)
+		require.Contains(t, w.Body.String(), `window.location.replace("https://dashboard.example.com/bye")`)
+
+		require.Len(t, calls, 2, "failures do not prevent the logout")
+		call := calls[0]
+		if call.path != "/backchannel-logout" {
+			call = calls[1]
+		}
+		logoutToken, err := jwt.Parse(call.token, func(*jwt.Token) (interface{}, error) { return s.Security.key.Public(), nil })
+		require.NoError(t, err)
+		logoutClaims := logoutToken.Claims.(jwt.MapClaims)
+		require.Equal(t, "https://auth.example.com", logoutClaims["iss"])
+		require.Equal(t, "dashboard", logoutClaims["aud"])
+		require.Equal(t, "123", logoutClaims["sub"])
+		require.Equal(t, claims["sid"], logoutClaims["sid"])
+		require.Contains(t, logoutClaims["events"], backChannelLogoutEvent)
+		require.NotEmpty(t, logoutClaims["jti"])
+	})
+
+	t.Run("documents the flows in the security schemes", func(t *testing.T) {
+		s := newServer(LogoutClient{ID: "dashboard", BackChannelLogoutURI: rp.URL})
+		schemes := s.OpenAPI.Description().Components.SecuritySchemes
+		require.Contains(t, schemes, "bearerAuth")
+		require.Contains(t, schemes, "cookieAuth")
+		require.Equal(t, JWTCookieName, schemes["cookieAuth"].Value.Name)
+		require.Contains(t, schemes["bearerAuth"].Value.Description, "/auth/end-session")
+
+		logout := schemes["bearerAuth"].Value.Extensions["x-logout"].(map[string]any)
+		require.Equal(t, "/auth/end-session", logout["end_session_endpoint"])
+		require.Equal(t, true, logout["backchannel_logout_supported"])
+		require.Equal(t, false, logout["frontchannel_logout_supported"])
+
+		require.NotNil(t, s.OpenAPI.Description().Paths.Find("/auth/end-session").Get)
+		require.NotNil(t, s.OpenAPI.Description().Paths.Find("/auth/end-session").Post)
+	})
+}
@@ -0,0 +1,223 @@
+package fuego
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MagicLinkMailer sends the login links of the passwordless flow. See [WithMagicLink].
+type MagicLinkMailer interface {
+	// SendMagicLink sends the link to the email address. The link logs the user in once, until it expires.
+	SendMagicLink(ctx context.Context, email, link string) error
+}
+
+// MagicLinkMailerFunc is a [MagicLinkMailer] function.
+type MagicLinkMailerFunc func(ctx context.Context, email, link string) error
+
+func (f MagicLinkMailerFunc) SendMagicLink(ctx context.Context, email, link string) error {
+	return f(ctx, email, link)
+}
+
+// MagicLinkConfig configures the passwordless login registered by [WithMagicLink].
+type MagicLinkConfig struct {
+	// Mailer sending the links. Required.
+	Mailer MagicLinkMailer
+	// Users returns the claims of the token of the user with the given email address, or [ErrUserNotFound].
+	// No link is sent to the unknown addresses. Required.
+	Users func(ctx context.Context, email string) (jwt.Claims, error)
+	// Validity of the links. Defaults to 15 minutes.
+	TTL time.Duration
+	// Path of the route sending the links. Defaults to "/auth/magic-link".
+	// The links are verified by the route at Path + "/verify".
+	Path string
+	// URL of the links, followed by the token query parameter. Required.
+	// It is never built from the Host header of the request, which the requester controls:
+	// a forged Host would send the login links of the victims to the domain of the attacker.
+	// Use the public URL of the verification route, like "https://example.com/auth/magic-link/verify",
+	// or of a page of your frontend calling it, so that the links opened by the email scanners do not use the tokens.
+	URL string
+	// URL the users are redirected to once logged in. If empty, the verification route returns the token in JSON.
+	RedirectURL string
+}
+
+// MagicLinkRequest is the body of the request of a login link.
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type magicLinkResponse struct {
+	Message string `json:"message"`
+}
+
+// magicLinks holds the tokens of the links sent and not used yet.
+type magicLinks struct {
+	config MagicLinkConfig
+
+	mu     sync.Mutex
+	tokens map[[sha256.Size]byte]magicLinkToken
+}
+
+type magicLinkToken struct {
+	claims    jwt.Claims
+	expiresAt time.Time
+}
+
+// WithMagicLink registers a passwordless login: the users request a login link, sent to their email address,
+// and following it sets the token cookie like the login of [WithAutoAuth].
+//   - POST /auth/magic-link sends a link to the email of the body, if it belongs to a user
+//   - GET /auth/magic-link/verify?token=... logs the user in, once, until the link expires
+//
+// The links are kept in memory: they cannot be verified by other instances of the server.
+//
+//	s := fuego.NewServer(
+//		fuego.WithAutoAuth(credentials.Verify),
+//		fuego.WithMagicLink(fuego.MagicLinkConfig{
+//			Mailer: fuego.MagicLinkMailerFunc(func(ctx context.Context, email, link string) error {
+//				return mailer.Send(ctx, email, "Your login link", "Log in with "+link)
+//			}),
+//			Users: func(ctx context.Context, email string) (jwt.Claims, error) {
+//				user, err := users.UserByName(ctx, email)
+//				if err != nil {
+//					return nil, err
+//				}
+//				return jwt.MapClaims{"sub": user.ID, "roles": user.Roles}, nil
+//			},
+//			URL: "https://example.com/auth/magic-link/verify",
+//		}),
+//	)
+func WithMagicLink(config MagicLinkConfig) func(*Server) {
+	if config.Mailer == nil || config.Users == nil {
+		panic("magic links need a mailer and a user lookup")
+	}
+	if config.URL == "" {
+		panic("magic links need the public URL of the links")
+	}
+	if config.TTL <= 0 {
+		config.TTL = 15 * time.Minute
+	}
+	if config.Path == "" {
+		config.Path = "/auth/magic-link"
+	}
+	return func(s *Server) {
+		s.magicLinks = &magicLinks{
+			config: config,
+			tokens: make(map[[sha256.Size]byte]magicLinkToken),
+		}
+	}
+}
+
+// registerMagicLink registers the routes of the passwordless login configured with [WithMagicLink].
+func (s *Server) registerMagicLink() {
+	if s.magicLinks == nil {
+		return
+	}
+	path := s.magicLinks.config.Path
+
+	Post(s, path, s.sendMagicLink,
+		OptionTags("Auth"),
+		OptionSummary("Send login link"),
+		OptionDescription("Sends a single-use login link to the email address, if it belongs to a user. "+
+			"The response is the same for the unknown addresses, so that they cannot be guessed."),
+		OptionDefaultStatusCode(http.StatusAccepted),
+	)
+	GetStd(s, path+"/verify", s.verifyMagicLink,
+		OptionTags("Auth"),
+		OptionSummary("Verify login link"),
+		OptionDescription("Logs the user in with the token of a login link, and sets the token cookie. "+
+			"The links can be used once, until they expire after "+s.magicLinks.config.TTL.String()+"."),
+		OptionQuery("token", "Token of the login link", ParamRequired()),
+		OptionAddResponse(http.StatusOK, "Logged in", Response{Type: tokenResponse{}}),
+		OptionAddError(http.StatusUnauthorized, "Invalid or expired link"),
+	)
+}
+
+func (s *Server) sendMagicLink(c ContextWithBody[MagicLinkRequest]) (magicLinkResponse, error) {
+	body, err := c.Body()
+	if err != nil {
+		return magicLinkResponse{}, err
+	}
+	response := magicLinkResponse{Message: "If the address belongs to an account, a login link was sent to it."}
+
+	claims, err := s.magicLinks.config.Users(c.Context(), body.Email)
+	if errors.Is(err, ErrUserNotFound) {
+		return response, nil
+	} else if err != nil {
+		return magicLinkResponse{}, err
+	}
+
+	token, err := s.magicLinks.issue(claims, s.now())
+	if err != nil {
+		return magicLinkResponse{}, err
+	}
+	link := withQueryParam(s.magicLinks.config.URL, "token", token)
+
+	// A failure is not returned, it would reveal that the address belongs to an account
+	if err := s.magicLinks.config.Mailer.SendMagicLink(c.Context(), body.Email, link); err != nil {
+		slog.Error("Cannot send the login link", "error", err)
+	}
+	return response, nil
+}
+
+func (s *Server) verifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	claims, ok := s.magicLinks.use(r.URL.Query().Get("token"), s.now())
+	if !ok {
+		SendJSONError(w, nil, UnauthorizedError{Title: "Invalid or expired link", Detail: "Request a new login link"})
+		return
+	}
+
+	token, err := s.Security.GenerateTokenToCookies(claims, w)
+	if err != nil {
+		SendJSONError(w, nil, err)
+		return
+	}
+	if s.magicLinks.config.RedirectURL != "" {
+		http.Redirect(w, r, s.magicLinks.config.RedirectURL, http.StatusSeeOther)
+		return
+	}
+	_ = SendJSON(w, r, tokenResponse{Token: token})
+}
+
+// issue returns a new random token for the claims. Only its hash is kept.
+func (links *magicLinks) issue(claims jwt.Claims, now time.Time) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	links.mu.Lock()
+	defer links.mu.Unlock()
+	for hash, link := range links.tokens {
+		if now.After(link.expiresAt) {
+			delete(links.tokens, hash)
+		}
+	}
+	links.tokens[sha256.Sum256([]byte(token))] = magicLinkToken{claims: claims, expiresAt: now.Add(links.config.TTL)}
+	return token, nil
+}
+
+// use returns the claims of the token and forgets it, if it has not expired.
+func (links *magicLinks) use(token string, now time.Time) (jwt.Claims, bool) {
+	if token == "" {
+		return nil, false
+	}
+	hash := sha256.Sum256([]byte(token))
+
+	links.mu.Lock()
+	defer links.mu.Unlock()
+	link, ok := links.tokens[hash]
+	if !ok {
+		return nil, false
+	}
+	delete(links.tokens, hash)
+	return link.claims, !now.After(link.expiresAt)
+}
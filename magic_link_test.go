@@ -0,0 +1,152 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLink(t *testing.T) {
+	now := time.Now()
+	links := map[string]string{}
+	newServer := func(config MagicLinkConfig) *Server {
+		config.Mailer = MagicLinkMailerFunc(func(_ context.Context, email, link string) error {
+			links[email] = link
+			return nil
+		})
+		config.Users = func(_ context.Context, email string) (jwt.Claims, error) {
+			if email != "alice@example.com" {
+				return nil, ErrUserNotFound
+			}
+			return jwt.MapClaims{"sub": "1"}, nil
+		}
+		if config.URL == "" {
+			config.URL = "https://example.com/auth/magic-link/verify"
+		}
+		return NewServer(WithoutLogger(), WithClock(func() time.Time { return now }), WithMagicLink(config))
+	}
+	requestLink := func(t *testing.T, s *Server, email string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/auth/magic-link", strings.NewReader(`{"email":"`+email+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+	follow := func(s *Server, link string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+		return w
+	}
+
+	t.Run("logs the user in once", func(t *testing.T) {
+		s := newServer(MagicLinkConfig{})
+
+		w := requestLink(t, s, "alice@example.com")
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		link, err := url.Parse(links["alice@example.com"])
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/auth/magic-link/verify", link.Scheme+"://"+link.Host+link.Path)
+
+		w = follow(s, link.String())
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Contains(t, w.Body.String(), `"token"`)
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		require.Equal(t, JWTCookieName, cookies[0].Name)
+		token, err := s.Security.ValidateToken(cookies[0].Value)
+		require.NoError(t, err)
+		subject, _ := token.Claims.GetSubject()
+		require.Equal(t, "1", subject)
+
+		w = follow(s, link.String())
+		require.Equal(t, http.StatusUnauthorized, w.Code, "single use")
+	})
+
+	t.Run("same response for unknown addresses", func(t *testing.T) {
+		s := newServer(MagicLinkConfig{})
+		known := requestLink(t, s, "alice@example.com")
+		unknown := requestLink(t, s, "bob@example.com")
+		require.Equal(t, known.Code, unknown.Code)
+		require.Equal(t, known.Body.String(), unknown.Body.String())
+		require.NotContains(t, links, "bob@example.com")
+
+		w := requestLink(t, s, "not an email")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("links expire", func(t *testing.T) {
+		s := newServer(MagicLinkConfig{TTL: time.Minute})
+		requestLink(t, s, "alice@example.com")
+		now = now.Add(2 * time.Minute)
+		w := follow(s, links["alice@example.com"])
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = follow(s, "/auth/magic-link/verify?token=forged")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("custom URL and redirection", func(t *testing.T) {
+		s := newServer(MagicLinkConfig{URL: "https://app.example.com/login?from=email", RedirectURL: "/dashboard"})
+		requestLink(t, s, "alice@example.com")
+		link, err := url.Parse(links["alice@example.com"])
+		require.NoError(t, err)
+		require.Equal(t, "app.example.com", link.Host)
+		require.Equal(t, "email", link.Query().Get("from"))
+
+		w := follow(s, "/auth/magic-link/verify?token="+link.Query().Get("token"))
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/dashboard", w.Header().Get("Location"))
+		require.NotEmpty(t, w.Result().Cookies())
+	})
+
+	t.Run("mailer failures are not revealed", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithMagicLink(MagicLinkConfig{
+			Mailer: MagicLinkMailerFunc(func(context.Context, string, string) error { return errors.New("smtp down") }),
+			Users:  func(context.Context, string) (jwt.Claims, error) { return jwt.MapClaims{"sub": "1"}, nil },
+			URL:    "https://example.com/auth/magic-link/verify",
+		}))
+		w := requestLink(t, s, "alice@example.com")
+		require.Equal(t, http.StatusAccepted, w.Code)
+	})
+
+	t.Run("forged Host header", func(t *testing.T) {
+		s := newServer(MagicLinkConfig{})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/auth/magic-link", strings.NewReader(`{"email":"alice@example.com"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Host = "attacker.example"
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusAccepted, w.Code)
+
+		link, err := url.Parse(links["alice@example.com"])
+		require.NoError(t, err)
+		require.Equal(t, "example.com", link.Host)
+	})
+
+	t.Run("URL is required", func(t *testing.T) {
+		require.PanicsWithValue(t, "magic links need the public URL of the links", func() {
+			WithMagicLink(MagicLinkConfig{
+				Mailer: MagicLinkMailerFunc(func(context.Context, string, string) error { return nil }),
+				Users:  func(context.Context, string) (jwt.Claims, error) { return nil, ErrUserNotFound },
+			})
+		})
+	})
+
+	t.Run("documented in the spec", func(t *testing.T) {
+		s := newServer(MagicLinkConfig{})
+		spec := s.OutputOpenAPISpec()
+		require.NotNil(t, spec.Paths.Find("/auth/magic-link").Post)
+		verify := spec.Paths.Find("/auth/magic-link/verify").Get
+		require.NotNil(t, verify)
+		require.NotNil(t, verify.Parameters.GetByInAndName("query", "token"))
+	})
+}
@@ -238,3 +238,18 @@ func BenchmarkCache(b *testing.B) {
 		}
 	})
 }
+
+func TestTTLCache(t *testing.T) {
+	cache := NewInMemoryCache(time.Minute, 10)
+	now := time.Now()
+	cache.Now = func() time.Time { return now }
+
+	cache.Set("key", "value")
+	value, ok := cache.Get("key")
+	require.True(t, ok)
+	require.Equal(t, "value", value)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("key")
+	require.False(t, ok, "expired")
+}
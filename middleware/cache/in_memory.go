@@ -7,21 +7,37 @@ import (
 )
 
 type TTLCache struct {
-	cache *expirable.LRU[string, string]
+	// Clock of the expiry of the entries. Defaults to time.Now.
+	// Tests can freeze or advance it, like the clock of the server set with fuego.WithClock.
+	Now func() time.Time
+
+	duration time.Duration
+	cache    *expirable.LRU[string, ttlEntry]
+}
+
+type ttlEntry struct {
+	value     string
+	expiresAt time.Time
 }
 
 var _ Storage = (*TTLCache)(nil)
 
 func NewInMemoryCache(duration time.Duration, maxObjects int) *TTLCache {
 	return &TTLCache{
-		cache: expirable.NewLRU[string, string](maxObjects, nil, duration),
+		Now:      time.Now,
+		duration: duration,
+		cache:    expirable.NewLRU[string, ttlEntry](maxObjects, nil, duration),
 	}
 }
 
 func (t *TTLCache) Get(key string) (string, bool) {
-	return t.cache.Get(key)
+	entry, ok := t.cache.Get(key)
+	if !ok || !t.Now().Before(entry.expiresAt) {
+		return "", false
+	}
+	return entry.value, true
 }
 
 func (t *TTLCache) Set(key, value string) {
-	t.cache.Add(key, value)
+	t.cache.Add(key, ttlEntry{value: value, expiresAt: t.Now().Add(t.duration)})
 }
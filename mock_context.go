@@ -1,8 +1,11 @@
 package fuego
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -17,12 +20,17 @@ import (
 type MockContext[B any] struct {
 	internal.CommonContext[B]
 
-	RequestBody B
-	Headers     http.Header
-	PathParams  map[string]string
-	response    http.ResponseWriter
-	request     *http.Request
-	Cookies     map[string]*http.Cookie
+	RequestBody    B
+	RawRequestBody []byte
+	Headers        http.Header
+	PathParams     map[string]string
+	response       http.ResponseWriter
+	request        *http.Request
+	Cookies        map[string]*http.Cookie
+	// Events sent with the writer returned by SSE.
+	Events []SSEEvent
+	// Files of the multipart form, set with SetFile.
+	files map[string][]*multipart.FileHeader
 }
 
 // NewMockContext creates a new MockContext instance with the provided body
@@ -58,6 +66,24 @@ func (m *MockContext[B]) MustBody() B {
 	return m.RequestBody
 }
 
+// RawBody returns the previously set raw body
+func (m *MockContext[B]) RawBody() []byte {
+	return m.RawRequestBody
+}
+
+// FormFile returns the first file of the field, set with SetFile
+func (m *MockContext[B]) FormFile(name string) (*multipart.FileHeader, error) {
+	if len(m.files[name]) == 0 {
+		return nil, missingFileError(name)
+	}
+	return m.files[name][0], nil
+}
+
+// FormFiles returns the files set with SetFile, by field
+func (m *MockContext[B]) FormFiles() (map[string][]*multipart.FileHeader, error) {
+	return m.files, nil
+}
+
 // HasHeader checks if a header exists
 func (m *MockContext[B]) HasHeader(key string) bool {
 	_, exists := m.Headers[key]
@@ -106,6 +132,20 @@ func (m *MockContext[B]) Response() http.ResponseWriter {
 	return m.response
 }
 
+// HTTPClient returns a client propagating the request ID and the trace context of the mock headers
+func (m *MockContext[B]) HTTPClient() *http.Client {
+	var response http.Header
+	if m.response != nil {
+		response = m.response.Header()
+	}
+	return m.PropagatingHTTPClient(m.Headers, response)
+}
+
+// SSE returns a writer recording the events in Events, instead of sending them
+func (m *MockContext[B]) SSE() *SSEWriter {
+	return &SSEWriter{ctx: m.CommonCtx, recorded: &m.Events}
+}
+
 // SetStatus sets the response status code
 func (m *MockContext[B]) SetStatus(code int) {
 	if m.response != nil {
@@ -151,9 +191,24 @@ func (m *MockContext[B]) Redirect(code int, url string) (any, error) {
 
 // Render is a mock implementation that does nothing
 func (m *MockContext[B]) Render(templateToExecute string, data any, templateGlobsToOverride ...string) (CtxRenderer, error) {
-	panic("not implemented")
+	return mockRenderer{}, nil
 }
 
+// RenderPDF is a mock implementation that does nothing
+func (m *MockContext[B]) RenderPDF(templateToExecute string, data any, templateGlobsToOverride ...string) (PDF, error) {
+	return PDF{}, nil
+}
+
+// Pagination returns the page asked by the query parameters, with the default configuration.
+func (m *MockContext[B]) Pagination() Pagination {
+	return PaginationConfig{}.Parse(m.UrlValues)
+}
+
+// mockRenderer renders nothing.
+type mockRenderer struct{}
+
+func (mockRenderer) Render(context.Context, io.Writer) error { return nil }
+
 // SetQueryParam adds a query parameter to the mock context with OpenAPI validation
 func (m *MockContext[B]) SetQueryParam(name, value string) *MockContext[B] {
 	param := OpenAPIParam{
@@ -192,3 +247,22 @@ func (m *MockContext[B]) SetQueryParamBool(name string, value bool) *MockContext
 	m.CommonContext.UrlValues.Set(name, fmt.Sprintf("%t", value))
 	return m
 }
+
+// SetFile adds a file to the multipart form of the mock context, returned by FormFile and FormFiles
+func (m *MockContext[B]) SetFile(field, filename string, content []byte) *MockContext[B] {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile(field, filename)
+	_, _ = part.Write(content)
+	_ = writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(multipartMaxMemory)
+	if err != nil {
+		panic(err)
+	}
+	if m.files == nil {
+		m.files = make(map[string][]*multipart.FileHeader)
+	}
+	m.files[field] = append(m.files[field], form.File[field]...)
+	return m
+}
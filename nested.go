@@ -0,0 +1,61 @@
+package fuego
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// parentKey is the context key of the parents of type P loaded by [Nested].
+type parentKey[P any] struct{}
+
+// Nested groups the routes of the children of a resource, like the ingredients of a recipe, under a path
+// containing the ID of their parent. The loader fetches the parent once per request, before the controller,
+// which gets it with [Parent] instead of loading it again. If the loader returns nil, the request fails
+// with a 404 Not Found error, documented on the routes of the group. Its other errors are sent as is.
+//
+//	ingredients := fuego.Nested(s, "/recipes/{recipeID}/ingredients", func(c fuego.ContextNoBody) (*Recipe, error) {
+//		return store.GetRecipe(c, c.PathParam("recipeID"))
+//	}, option.Path("recipeID", "ID of the recipe"))
+//
+//	fuego.Get(ingredients, "/", func(c fuego.ContextNoBody) ([]Ingredient, error) {
+//		recipe := fuego.Parent[Recipe](c)
+//		return recipe.Ingredients, nil
+//	})
+//
+// Like [Group], the route options apply to all the routes of the group.
+func Nested[P any](s *Server, path string, loader func(c ContextNoBody) (*P, error), routeOptions ...func(*BaseRoute)) *Server {
+	notFound := OptionAddResponse(http.StatusNotFound, "Not Found", Response{Type: HTTPError{}})
+	group := Group(s, path, append([]func(*BaseRoute){notFound}, routeOptions...)...)
+
+	parentName := reflect.TypeFor[P]().Name()
+	Use(group, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := NewNetHTTPContext[any](BaseRoute{}, w, r, readOptions{})
+			ctx.errorSerializer = s.SerializeError
+
+			parent, err := loader(ctx)
+			if err == nil && parent == nil {
+				err = NotFoundError{Detail: parentName + " not found"}
+			}
+			if err != nil {
+				ctx.SerializeError(s.handleError(ctx, err))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), parentKey[P]{}, parent)))
+		})
+	})
+	return group
+}
+
+// Parent returns the parent loaded by [Nested] for the routes of the group.
+// It panics if the route is not in a group of [Nested] loading a P.
+func Parent[P any](ctx context.Context) *P {
+	parent, ok := ctx.Value(parentKey[P]{}).(*P)
+	if !ok {
+		panic(fmt.Sprintf("no parent %s: the route is not in a Nested group loading it", reflect.TypeFor[P]()))
+	}
+	return parent
+}
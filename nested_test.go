@@ -0,0 +1,75 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nestedRecipe struct {
+	ID          string   `json:"id"`
+	Ingredients []string `json:"ingredients"`
+}
+
+func TestNested(t *testing.T) {
+	loads := 0
+	s := NewServer()
+	ingredients := Nested(s, "/recipes/{recipeID}/ingredients", func(c ContextNoBody) (*nestedRecipe, error) {
+		loads++
+		switch c.PathParam("recipeID") {
+		case "pizza":
+			return &nestedRecipe{ID: "pizza", Ingredients: []string{"flour", "tomato"}}, nil
+		case "broken":
+			return nil, errors.New("database is down")
+		}
+		return nil, nil
+	}, OptionPath("recipeID", "ID of the recipe"))
+
+	Get(ingredients, "/", func(c ContextNoBody) ([]string, error) {
+		return Parent[nestedRecipe](c).Ingredients, nil
+	})
+	Get(ingredients, "/count", func(c ContextNoBody) (int, error) {
+		return len(Parent[nestedRecipe](c).Ingredients), nil
+	})
+
+	t.Run("loads the parent once per request", func(t *testing.T) {
+		loads = 0
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes/pizza/ingredients/", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `["flour","tomato"]`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes/pizza/ingredients/count", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "2\n", w.Body.String())
+		require.Equal(t, 2, loads)
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes/salad/ingredients/", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Contains(t, w.Body.String(), "nestedRecipe not found")
+	})
+
+	t.Run("loader error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes/broken/ingredients/", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("documents the parent and the 404", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/recipes/{recipeID}/ingredients/count").Get
+		require.NotNil(t, operation.Responses.Status(http.StatusNotFound))
+		require.NotNil(t, operation.Parameters.GetByInAndName("path", "recipeID"))
+	})
+}
+
+func TestParent(t *testing.T) {
+	require.Panics(t, func() { Parent[nestedRecipe](context.Background()) })
+}
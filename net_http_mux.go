@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -22,6 +23,13 @@ import (
 //		return ans{Ans: "users"}, nil
 //	})
 //	s.Run()
+//
+// The path can contain path parameters, declared once for all the routes of the group
+// with [OptionPath], and read with [ContextWithBody.PathParam] in the controllers:
+//
+//	tenantRoutes := fuego.Group(s, "/tenants/{tenantID}",
+//		option.Path("tenantID", "ID of the tenant", param.Integer(), param.Example("tenant", 42)),
+//	)
 func Group(s *Server, path string, routeOptions ...func(*BaseRoute)) *Server {
 	if path == "/" {
 		path = ""
@@ -33,8 +41,11 @@ func Group(s *Server, path string, routeOptions ...func(*BaseRoute)) *Server {
 	newServer := &ss
 	newServer.basePath += path
 
-	if autoTag := strings.TrimLeft(path, "/"); !s.disableAutoGroupTags && autoTag != "" {
-		newServer.routeOptions = append(s.routeOptions, OptionTags(autoTag))
+	// The options are copied, so that groups of the same parent never share them
+	newServer.routeOptions = slices.Clone(s.routeOptions)
+	newServer.middlewares = slices.Clone(s.middlewares)
+	if autoTag := groupTag(path); !s.disableAutoGroupTags && autoTag != "" {
+		newServer.routeOptions = append(newServer.routeOptions, OptionTags(autoTag))
 	}
 
 	newServer.routeOptions = append(newServer.routeOptions, routeOptions...)
@@ -42,6 +53,16 @@ func Group(s *Server, path string, routeOptions ...func(*BaseRoute)) *Server {
 	return newServer
 }
 
+// groupTag returns the tag of the routes of a group, from its path without the path parameters:
+// "tenants" for "/tenants/{tenantID}".
+func groupTag(path string) string {
+	segments := strings.Split(strings.TrimLeft(path, "/"), "/")
+	segments = slices.DeleteFunc(segments, func(segment string) bool {
+		return strings.HasPrefix(segment, "{")
+	})
+	return strings.Join(segments, "/")
+}
+
 // All captures all methods (GET, POST, PUT, PATCH, DELETE) and register a controller.
 func All[T, B any](s *Server, path string, controller func(ContextWithBody[B]) (T, error), options ...func(*BaseRoute)) *Route[T, B] {
 	return registerFuegoController(s, "", path, controller, options...)
@@ -67,6 +88,33 @@ func Patch[T, B any](s *Server, path string, controller func(ContextWithBody[B])
 	return registerFuegoController(s, http.MethodPatch, path, controller, options...)
 }
 
+// AllNoContent captures all methods and registers a controller that only returns an error,
+// for side-effect endpoints like webhook sinks. It responds with 204 No Content when the controller succeeds.
+// See [NoContent].
+func AllNoContent[B any](s *Server, path string, controller func(ContextWithBody[B]) error, options ...func(*BaseRoute)) *Route[NoContent, B] {
+	return registerNoContentController(s, "", path, controller, options...)
+}
+
+func GetNoContent[B any](s *Server, path string, controller func(ContextWithBody[B]) error, options ...func(*BaseRoute)) *Route[NoContent, B] {
+	return registerNoContentController(s, http.MethodGet, path, controller, options...)
+}
+
+func PostNoContent[B any](s *Server, path string, controller func(ContextWithBody[B]) error, options ...func(*BaseRoute)) *Route[NoContent, B] {
+	return registerNoContentController(s, http.MethodPost, path, controller, options...)
+}
+
+func DeleteNoContent[B any](s *Server, path string, controller func(ContextWithBody[B]) error, options ...func(*BaseRoute)) *Route[NoContent, B] {
+	return registerNoContentController(s, http.MethodDelete, path, controller, options...)
+}
+
+func PutNoContent[B any](s *Server, path string, controller func(ContextWithBody[B]) error, options ...func(*BaseRoute)) *Route[NoContent, B] {
+	return registerNoContentController(s, http.MethodPut, path, controller, options...)
+}
+
+func PatchNoContent[B any](s *Server, path string, controller func(ContextWithBody[B]) error, options ...func(*BaseRoute)) *Route[NoContent, B] {
+	return registerNoContentController(s, http.MethodPatch, path, controller, options...)
+}
+
 // Register registers a controller into the default net/http mux.
 //
 // Deprecated: Used internally. Please satisfy the [Registerer] interface instead and pass to [Registers].
@@ -83,8 +131,21 @@ func Register[T, B any](s *Server, route Route[T, B], controller http.Handler, o
 	}
 	slog.Debug("registering controller " + fullPath)
 
-	route.Middlewares = append(s.middlewares, route.Middlewares...)
-	s.Mux.Handle(fullPath, withMiddlewares(controller, route.Middlewares...))
+	route.Middlewares = slices.Concat(s.middlewares, route.Middlewares)
+	if s.loadShedder != nil {
+		route.Middlewares = slices.Insert(route.Middlewares, 0, s.loadShedder.middleware(route.Priority))
+	}
+	if s.rateLimit != nil && route.rateLimitCost() > 0 {
+		route.Middlewares = append(route.Middlewares, s.rateLimit.middleware(route.rateLimitCost(), s.now))
+	}
+	if route.Timeout > 0 {
+		route.Middlewares = append(route.Middlewares, timeoutMiddleware(route.Timeout, s.SerializeError))
+	}
+	if route.signatureVerifier != nil {
+		route.Middlewares = append(route.Middlewares, route.signatureVerifier.middleware(s.maxBodySize, s.now))
+	}
+	s.routes.Handle(fullPath, withMiddlewares(controller, route.Middlewares...))
+	s.sitemap.add(route.BaseRoute)
 
 	return &route
 }
@@ -133,8 +194,15 @@ func PatchStd(s *Server, path string, controller func(http.ResponseWriter, *http
 }
 
 func registerFuegoController[T, B any](s *Server, method, path string, controller func(ContextWithBody[B]) (T, error), options ...func(*BaseRoute)) *Route[T, B] {
-	options = append(options, OptionHeader("Accept", ""))
-	route := NewRoute[T, B](method, path, controller, s.Engine, append(s.routeOptions, options...)...)
+	s.OpenAPI.mu.Lock()
+	defer s.OpenAPI.mu.Unlock()
+
+	// Options of the server and of the groups first, so that the route can override them
+	options = slices.Concat(s.routeOptions, options, []func(*BaseRoute){optionAcceptHeader})
+	if isPageResponse[T]() {
+		options = append(options, optionPagination(s.pagination))
+	}
+	route := NewRoute[T, B](method, path, controller, s.Engine, options...)
 
 	return Registers(s.Engine, netHttpRouteRegisterer[T, B]{
 		s:          s,
@@ -143,13 +211,51 @@ func registerFuegoController[T, B any](s *Server, method, path string, controlle
 	})
 }
 
+// optionAcceptHeader declares the Accept header, unless the route already declares it.
+func optionAcceptHeader(r *BaseRoute) {
+	if r.Operation.Parameters.GetByInAndName(string(HeaderParamType), "Accept") == nil {
+		OptionHeader("Accept", "")(r)
+	}
+}
+
+// registerNoContentController registers a controller only returning an error as a [NoContent] controller.
+// The route keeps the name of the given controller, used in the OpenAPI spec.
+func registerNoContentController[B any](s *Server, method, path string, controller func(ContextWithBody[B]) error, options ...func(*BaseRoute)) *Route[NoContent, B] {
+	options = append(options, func(r *BaseRoute) { r.FullName = FuncName(controller) })
+	return registerFuegoController(s, method, path, func(c ContextWithBody[B]) (NoContent, error) {
+		return NoContent{}, controller(c)
+	}, options...)
+}
+
 func registerStdController(s *Server, method, path string, controller func(http.ResponseWriter, *http.Request), options ...func(*BaseRoute)) *Route[any, any] {
-	route := NewRoute[any, any](method, path, controller, s.Engine, append(s.routeOptions, options...)...)
+	s.OpenAPI.mu.Lock()
+	defer s.OpenAPI.mu.Unlock()
+
+	route := NewRoute[any, any](method, path, controller, s.Engine, slices.Concat(s.routeOptions, options)...)
+
+	var handler http.Handler = http.HandlerFunc(controller)
+	if s.stdParamsValidation {
+		handler = validateParamsHandler(s, route.BaseRoute, handler)
+	}
 
 	return Registers(s.Engine, netHttpRouteRegisterer[any, any]{
 		s:          s,
 		route:      route,
-		controller: http.HandlerFunc(controller),
+		controller: handler,
+	})
+}
+
+// validateParamsHandler responds with an error if the required parameters of the route are missing,
+// before calling the handler. See [WithStdParamsValidation].
+func validateParamsHandler(s *Server, route BaseRoute, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewNetHTTPContext[any](route, w, r, readOptions{})
+		ctx.errorSerializer = s.SerializeError
+		if err := ValidateParams(ctx); err != nil {
+			ctx.SerializeError(s.ErrorHandler(err))
+			return
+		}
+		handler.ServeHTTP(w, r)
 	})
 }
 
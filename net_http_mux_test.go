@@ -223,6 +223,54 @@ func TestDelete(t *testing.T) {
 	require.Equal(t, "test", "test", w.Body.String())
 }
 
+func deletePet(c ContextNoBody) error {
+	if c.PathParam("id") == "0" {
+		return NotFoundError{Title: "Pet not found"}
+	}
+	return nil
+}
+
+func TestNoContentControllers(t *testing.T) {
+	s := NewServer()
+	route := DeleteNoContent(s, "/pets/{id}", deletePet)
+	PostNoContent(s, "/webhooks", func(c ContextWithBody[map[string]any]) error {
+		_, err := c.Body()
+		return err
+	})
+
+	t.Run("responds 204 when the controller succeeds", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/pets/1", nil))
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Body.String())
+	})
+
+	t.Run("sends the error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/pets/0", nil))
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("reads the body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"event":"created"}`)))
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{`)))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("is documented with the controller name", func(t *testing.T) {
+		require.Equal(t, "github.com/go-fuego/fuego.deletePet", route.FullName)
+		require.Equal(t, "delete pet", route.Operation.Summary)
+		require.NotNil(t, route.Operation.Responses.Value("204"))
+		require.Nil(t, route.Operation.Responses.Value("200"))
+	})
+}
+
 func TestHandle(t *testing.T) {
 	s := NewServer()
 	Handle(s, "/test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -443,6 +491,48 @@ func TestGroupInheritance(t *testing.T) {
 	})
 }
 
+func TestGroupPathParams(t *testing.T) {
+	s := NewServer()
+	tenant := Group(s, "/tenants/{tenantID}",
+		OptionPath("tenantID", "ID of the tenant", ParamInteger(), ParamExample("tenant", 42)),
+	)
+	project := Group(tenant, "/projects/{projectID}")
+
+	users := Get(tenant, "/users", func(c ContextNoBody) (string, error) {
+		tenantID, err := c.PathParamIntErr("tenantID")
+		return fmt.Sprintf("users of tenant %d", tenantID), err
+	})
+	tasks := Get(project, "/tasks", func(c ContextNoBody) (string, error) {
+		return "tasks of " + c.PathParam("tenantID") + "/" + c.PathParam("projectID"), nil
+	})
+
+	t.Run("parameter is documented on all the routes", func(t *testing.T) {
+		for _, route := range []*BaseRoute{&users.BaseRoute, &tasks.BaseRoute} {
+			parameter := route.Operation.Parameters.GetByInAndName("path", "tenantID")
+			require.NotNil(t, parameter)
+			require.Equal(t, "ID of the tenant", parameter.Description)
+			require.True(t, parameter.Schema.Value.Type.Is("integer"))
+			require.Equal(t, 42, parameter.Examples["tenant"].Value.Value)
+		}
+		require.NotNil(t, tasks.Operation.Parameters.GetByInAndName("path", "projectID"))
+	})
+
+	t.Run("group tags do not contain the parameters", func(t *testing.T) {
+		require.Equal(t, []string{"tenants"}, users.Operation.Tags)
+		require.Equal(t, []string{"tenants", "projects"}, tasks.Operation.Tags)
+	})
+
+	t.Run("parameters are read by the controllers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tenants/7/users", nil))
+		require.Equal(t, "users of tenant 7", w.Body.String())
+
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tenants/7/projects/site/tasks", nil))
+		require.Equal(t, "tasks of 7/site", w.Body.String())
+	})
+}
+
 func TestGroupTagsOnRoute(t *testing.T) {
 	t.Run("route tag inheritance", func(t *testing.T) {
 		s := NewServer(
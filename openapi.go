@@ -4,12 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
 	"reflect"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3gen"
@@ -17,24 +19,67 @@ import (
 
 func NewOpenAPI() *OpenAPI {
 	desc := NewOpenApiSpec()
+	generator := openapi3gen.NewGenerator()
 	return &OpenAPI{
 		description:            &desc,
-		generator:              openapi3gen.NewGenerator(),
+		generator:              generator,
+		schemaGenerator:        generator,
 		globalOpenAPIResponses: []openAPIResponse{},
 	}
 }
 
 // OpenAPI holds the OpenAPI OpenAPIDescription (OAD) and OpenAPI capabilities.
 type OpenAPI struct {
-	description            *openapi3.T
-	generator              *openapi3gen.Generator
+	// Guards the description against the routes registered and removed while the spec is served.
+	mu          sync.RWMutex
+	description *openapi3.T
+	generator   *openapi3gen.Generator
+	// Generates the schemas of the types. Defaults to generator, see [WithSchemaGenerator].
+	schemaGenerator SchemaGenerator
+	// Composes the descriptions of the operations. Defaults to [ComposeDescription], see [WithDescriptionComposer].
+	composeDescription     func(route *BaseRoute) string
 	globalOpenAPIResponses []openAPIResponse
 }
 
+// SchemaGenerator generates the schemas of the Go types documented in the OpenAPI spec,
+// like the request and response bodies. See [WithSchemaGenerator].
+// [*openapi3gen.Generator] is the default generator.
+type SchemaGenerator interface {
+	// NewSchemaRefForValue returns the schema of the type of v.
+	// The schemas of the nested types may be added to schemas, the components of the spec,
+	// and referenced with "#/components/schemas/Name".
+	NewSchemaRefForValue(v any, schemas openapi3.Schemas) (*openapi3.SchemaRef, error)
+}
+
+var _ SchemaGenerator = (*openapi3gen.Generator)(nil)
+
 func (openAPI *OpenAPI) Description() *openapi3.T {
 	return openAPI.description
 }
 
+// snapshot returns a copy of the description that is not modified by the routes registered or removed later.
+// The operations and the schemas are shared: they are not modified once added.
+func (openAPI *OpenAPI) snapshot() openapi3.T {
+	openAPI.mu.RLock()
+	defer openAPI.mu.RUnlock()
+
+	description := *openAPI.description
+	if openAPI.description.Paths != nil {
+		description.Paths = openapi3.NewPaths()
+		for path, pathItem := range openAPI.description.Paths.Map() {
+			pathItemCopy := *pathItem
+			description.Paths.Set(path, &pathItemCopy)
+		}
+	}
+	if openAPI.description.Components != nil {
+		components := *openAPI.description.Components
+		components.Schemas = maps.Clone(components.Schemas)
+		description.Components = &components
+	}
+	return description
+}
+
+// Generator returns the default schema generator, even if another one is set with [WithSchemaGenerator].
 func (openAPI *OpenAPI) Generator() *openapi3gen.Generator {
 	return openAPI.generator
 }
@@ -161,6 +206,20 @@ func RegisterOpenAPIOperation[T, B any](openapi *OpenAPI, route Route[T, B]) (*o
 	}
 
 	// Request Body
+	if route.Operation.RequestBody == nil && len(route.formFiles) > 0 {
+		var bodyTag *SchemaTag
+		if tag := SchemaTagFromType(openapi, *new(B)); tag.Name != "unknown-interface" {
+			bodyTag = &tag
+		}
+		route.Operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: newMultipartRequestBody(route.formFiles, bodyTag),
+		}
+	}
+	if route.Operation.RequestBody == nil && isBinaryBody[B]() {
+		route.Operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: newBinaryRequestBody(route.RequestContentTypes),
+		}
+	}
 	if route.Operation.RequestBody == nil {
 		bodyTag := SchemaTagFromType(openapi, *new(B))
 
@@ -173,6 +232,9 @@ func RegisterOpenAPIOperation[T, B any](openapi *OpenAPI, route Route[T, B]) (*o
 			}
 		}
 	}
+	if route.OptionalBody && route.Operation.RequestBody != nil && route.Operation.RequestBody.Value != nil {
+		route.Operation.RequestBody.Value.Required = false
+	}
 
 	// Response - globals
 	for _, openAPIGlobalResponse := range openapi.globalOpenAPIResponses {
@@ -198,9 +260,28 @@ func RegisterOpenAPIOperation[T, B any](openapi *OpenAPI, route Route[T, B]) (*o
 	}
 
 	// Automatically add non-declared Content for 200 (or other) Response
-	if responseDefault.Value.Content == nil {
+	if responseDefault.Value.Content == nil && isSeekableResponse[T]() {
+		documentSeekableResponse(route.Operation, responseDefault.Value, route.ResponseContentTypes)
+	}
+	if responseDefault.Value.Content == nil && isXLSXResponse[T]() {
+		responseDefault.Value.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), []string{xlsxContentType}))
+	}
+	if responseDefault.Value.Content == nil && isPDFResponse[T]() {
+		responseDefault.Value.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), []string{"application/pdf"}))
+	}
+	if dataType, ok := templateDataType[T](); ok && responseDefault.Value.Content == nil {
+		documentTemplateResponse(openapi, responseDefault.Value, dataType)
+	}
+	if responseDefault.Value.Content == nil && !isNoContent[T]() {
 		responseSchema := SchemaTagFromType(openapi, *new(T))
-		content := openapi3.NewContentWithSchemaRef(&responseSchema.SchemaRef, []string{"application/json", "application/xml"})
+		produces := route.ResponseContentTypes
+		if len(produces) == 0 {
+			produces = []string{"application/json", "application/xml"}
+		}
+		content := openapi3.NewContentWithSchemaRef(&responseSchema.SchemaRef, produces)
+		if content.Get(xlsxContentType) != nil {
+			content[xlsxContentType] = openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema().WithFormat("binary"))
+		}
 		responseDefault.Value.WithContent(content)
 	}
 
@@ -217,6 +298,11 @@ func RegisterOpenAPIOperation[T, B any](openapi *OpenAPI, route Route[T, B]) (*o
 
 		route.Operation.AddParameter(parameter)
 	}
+
+	for _, callback := range route.operationCallbacks {
+		callback(route.Operation)
+	}
+
 	for _, params := range route.Operation.Parameters {
 		if params.Value.In == "path" {
 			if !strings.Contains(route.Path, "{"+params.Value.Name) {
@@ -231,7 +317,7 @@ func RegisterOpenAPIOperation[T, B any](openapi *OpenAPI, route Route[T, B]) (*o
 }
 
 // RegisterParams registers the parameters of a given type to an OpenAPI operation.
-// It inspects the fields of the provided struct, looking for "header" tags, and creates
+// It inspects the fields of the provided struct, looking for path, query, header and cookie tags, and creates
 // OpenAPI parameters for each tagged field.
 func (route *RouteWithParams[Params, ResponseBody, RequestBody]) RegisterParams() error {
 	if route.Operation == nil {
@@ -247,18 +333,7 @@ func (route *RouteWithParams[Params, ResponseBody, RequestBody]) RegisterParams(
 	}
 
 	if typeOfParams.Kind() == reflect.Struct {
-		for i := range typeOfParams.NumField() {
-			field := typeOfParams.Field(i)
-			if headerKey, ok := field.Tag.Lookup("header"); ok {
-				OptionHeader(headerKey, "string")(&route.BaseRoute)
-			}
-			if queryKey, ok := field.Tag.Lookup("query"); ok {
-				OptionQuery(queryKey, "string")(&route.BaseRoute)
-			}
-			if cookieKey, ok := field.Tag.Lookup("cookie"); ok {
-				OptionCookie(cookieKey, "string")(&route.BaseRoute)
-			}
-		}
+		registerStructParams(&route.BaseRoute, typeOfParams)
 	}
 
 	return nil
@@ -272,6 +347,19 @@ func newRequestBody[RequestBody any](tag SchemaTag, consumes []string) *openapi3
 		WithContent(content)
 }
 
+// newBinaryRequestBody documents a raw binary request body ([]byte or io.Reader body types).
+// Defaults to the application/octet-stream content type.
+func newBinaryRequestBody(consumes []string) *openapi3.RequestBody {
+	if len(consumes) == 0 {
+		consumes = []string{"application/octet-stream"}
+	}
+	schema := openapi3.NewStringSchema().WithFormat("binary")
+	return openapi3.NewRequestBody().
+		WithRequired(true).
+		WithDescription("Binary request body").
+		WithContent(openapi3.NewContentWithSchema(schema, consumes))
+}
+
 // SchemaTag is a struct that holds the name of the struct and the associated openapi3.SchemaRef
 type SchemaTag struct {
 	openapi3.SchemaRef
@@ -298,9 +386,9 @@ func SchemaTagFromType(openapi *OpenAPI, v any) SchemaTag {
 // the name of the struct being passed in.
 // If the type is a pointer, map, channel, function, or unsafe pointer,
 // it will dive into the type and return the name of the type it points to.
-// If the type is a slice or array type it will dive into the type as well as
-// build and openapi3.Schema where Type is array and Ref is set to the proper
-// components Schema
+// If the type is a slice or array type, an iterator ([iter.Seq]) or a receive channel,
+// it will dive into the type as well as build and openapi3.Schema where Type is array
+// and Ref is set to the proper components Schema
 func dive(openapi *OpenAPI, t reflect.Type, tag SchemaTag, maxDepth int) SchemaTag {
 	if maxDepth == 0 {
 		return SchemaTag{
@@ -311,6 +399,15 @@ func dive(openapi *OpenAPI, t reflect.Type, tag SchemaTag, maxDepth int) SchemaT
 		}
 	}
 
+	// Iterators and channels are sent as arrays
+	if elemType, ok := streamElemType(t); ok {
+		item := dive(openapi, elemType, tag, maxDepth-1)
+		tag.Name = item.Name
+		tag.Value = openapi3.NewArraySchema()
+		tag.Value.Items = &item.SchemaRef
+		return tag
+	}
+
 	switch t.Kind() {
 	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
 		return dive(openapi, t.Elem(), tag, maxDepth-1)
@@ -345,12 +442,15 @@ func (openAPI *OpenAPI) getOrCreateSchema(key string, v any) *openapi3.Schema {
 }
 
 // createSchema is used to create a new schema and add it to the OpenAPI spec.
-// Relies on the openapi3gen package to generate the schema, and adds custom struct tags.
+// Relies on the schema generator, openapi3gen by default, and adds custom struct tags.
 func (openAPI *OpenAPI) createSchema(key string, v any) *openapi3.SchemaRef {
-	schemaRef, err := openAPI.Generator().NewSchemaRefForValue(v, openAPI.Description().Components.Schemas)
+	schemaRef, err := openAPI.schemaGenerator.NewSchemaRefForValue(v, openAPI.Description().Components.Schemas)
 	if err != nil {
 		slog.Error("Error generating schema", "key", key, "error", err)
 	}
+	if schemaRef == nil || schemaRef.Value == nil {
+		schemaRef = openapi3.NewSchemaRef("", openapi3.NewSchema())
+	}
 	schemaRef.Value.Description = key + " schema"
 
 	descriptionable, ok := v.(OpenAPIDescriptioner)
@@ -477,6 +577,16 @@ func parseStructTags(t reflect.Type, schemaRef *openapi3.SchemaRef) {
 				propertyValue.Nullable = true
 			}
 		}
+
+		// Scopes allowed to see the field, see [ScopeFieldAuthorizer]
+		if scopes := fieldScopes(field); scopes != nil {
+			extensions := maps.Clone(propertyValue.Extensions)
+			if extensions == nil {
+				extensions = make(map[string]any)
+			}
+			extensions["x-scopes"] = scopes
+			propertyValue.Extensions = extensions
+		}
 		propertyCopy.Value = &propertyValue
 
 		schemaRef.Value.Properties[jsonFieldName] = &propertyCopy
@@ -0,0 +1,74 @@
+package fuego
+
+import (
+	"fmt"
+	"testing"
+)
+
+type benchOwner struct {
+	Name    string     `json:"name" description:"Name of the owner" example:"John"`
+	Email   string     `json:"email" validate:"required,email"`
+	Pets    []benchPet `json:"pets"`
+	Address struct {
+		Street string `json:"street" validate:"max=100"`
+		City   string `json:"city" validate:"required"`
+	} `json:"address"`
+}
+
+func BenchmarkSchemaGeneration(b *testing.B) {
+	b.Run("flat struct", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			SchemaTagFromType(NewOpenAPI(), benchPet{})
+		}
+	})
+
+	b.Run("nested struct", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			SchemaTagFromType(NewOpenAPI(), benchOwner{})
+		}
+	})
+
+	b.Run("cached schema", func(b *testing.B) {
+		openapi := NewOpenAPI()
+		b.ReportAllocs()
+		for range b.N {
+			SchemaTagFromType(openapi, []benchOwner{})
+		}
+	})
+}
+
+// newBenchSpecServer returns a server with 200 documented routes, in 10 groups.
+func newBenchSpecServer() *Server {
+	s := NewServer(WithoutLogger(), WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true, DisableMessages: true})))
+	for i := range 10 {
+		g := Group(s, fmt.Sprintf("/group%d", i), OptionTags(fmt.Sprintf("group%d", i)), OptionHeader("X-Org", "Organization"))
+		for j := range 10 {
+			Get(g, fmt.Sprintf("/owners/%d/{id}", j), func(ContextNoBody) (benchOwner, error) { return benchOwner{}, nil },
+				OptionQuery("search", "Search"), OptionQueryInt("page", "Page"))
+			Post(g, fmt.Sprintf("/owners/%d", j), func(ContextWithBody[benchOwner]) ([]benchPet, error) { return nil, nil })
+		}
+	}
+	return s
+}
+
+func BenchmarkRoutesDeclaration(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		newBenchSpecServer()
+	}
+}
+
+func BenchmarkSpecMarshalling(b *testing.B) {
+	s := newBenchSpecServer()
+	s.OutputOpenAPISpec()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.marshalSpec(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -626,3 +628,78 @@ func TestDeclareCustom200Response(t *testing.T) {
 	require.NotNil(t, openAPIResponse.Value.Content.Get("image/png"))
 	require.Equal(t, "Generated image", *openAPIResponse.Value.Description)
 }
+
+func TestBinaryRequestBody(t *testing.T) {
+	s := NewServer()
+	Post(s, "/upload", func(c ContextWithBody[[]byte]) (string, error) {
+		return "ok", nil
+	})
+	Post(s, "/firmware", func(c ContextWithBody[io.Reader]) (string, error) {
+		_, err := c.Body()
+		return "ok", err
+	}, OptionRequestContentType("application/x-firmware"))
+
+	upload := s.OpenAPI.Description().Paths.Find("/upload").Post.RequestBody.Value.Content.Get("application/octet-stream")
+	require.NotNil(t, upload)
+	require.Equal(t, "binary", upload.Schema.Value.Format)
+	require.True(t, upload.Schema.Value.Type.Is(openapi3.TypeString))
+
+	firmware := s.OpenAPI.Description().Paths.Find("/firmware").Post.RequestBody.Value.Content
+	require.Nil(t, firmware.Get("application/octet-stream"))
+	require.Equal(t, "binary", firmware.Get("application/x-firmware").Schema.Value.Format)
+
+	t.Run("route content type is enforced", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/firmware", strings.NewReader("data"))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
+
+// titleSchemaGenerator generates the schemas of structs, titling the properties with their jsonschema tag.
+type titleSchemaGenerator struct{}
+
+func (titleSchemaGenerator) NewSchemaRefForValue(v any, _ openapi3.Schemas) (*openapi3.SchemaRef, error) {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+
+	schema := openapi3.NewObjectSchema()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		property := openapi3.NewStringSchema()
+		property.Title = field.Tag.Get("jsonschema")
+		schema.WithProperty(name, property)
+	}
+	return openapi3.NewSchemaRef("", schema), nil
+}
+
+func TestWithSchemaGenerator(t *testing.T) {
+	type Pet struct {
+		Name string `json:"name" jsonschema:"Pet name" description:"Name of the pet"`
+	}
+
+	s := NewServer(
+		WithEngineOptions(WithSchemaGenerator(titleSchemaGenerator{})),
+	)
+	Get(s, "/pet", func(ContextNoBody) (Pet, error) { return Pet{}, nil })
+	Get(s, "/names", func(ContextNoBody) (int, error) { return 0, nil })
+
+	document := s.OutputOpenAPISpec()
+
+	t.Run("uses the generator", func(t *testing.T) {
+		name := document.Components.Schemas["Pet"].Value.Properties["name"].Value
+		require.Equal(t, "Pet name", name.Title)
+		require.Equal(t, "Name of the pet", name.Description)
+	})
+
+	t.Run("falls back to an empty schema on error", func(t *testing.T) {
+		require.NotNil(t, document.Components.Schemas["int"].Value)
+	})
+}
@@ -2,9 +2,13 @@ package fuego
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -40,10 +44,8 @@ func OptionMiddleware(middleware ...func(http.Handler) http.Handler) func(*BaseR
 //
 // The list of options is in the param package.
 func OptionQuery(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
-	options = append(options, ParamDescription(description), paramType(QueryParamType), ParamString())
-	return func(r *BaseRoute) {
-		OptionParam(name, options...)(r)
-	}
+	options = append([]func(*OpenAPIParam){ParamDescription(description), paramType(QueryParamType), ParamString()}, options...)
+	return OptionParam(name, options...)
 }
 
 // OptionQueryInt declares an integer query parameter for the route.
@@ -55,10 +57,8 @@ func OptionQuery(name, description string, options ...func(*OpenAPIParam)) func(
 //
 // The list of options is in the param package.
 func OptionQueryInt(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
-	options = append(options, ParamDescription(description), paramType(QueryParamType), ParamInteger())
-	return func(r *BaseRoute) {
-		OptionParam(name, options...)(r)
-	}
+	options = append([]func(*OpenAPIParam){ParamDescription(description), paramType(QueryParamType), ParamInteger()}, options...)
+	return OptionParam(name, options...)
 }
 
 // OptionQueryBool declares a boolean query parameter for the route.
@@ -70,10 +70,8 @@ func OptionQueryInt(name, description string, options ...func(*OpenAPIParam)) fu
 //
 // The list of options is in the param package.
 func OptionQueryBool(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
-	options = append(options, ParamDescription(description), paramType(QueryParamType), ParamBool())
-	return func(r *BaseRoute) {
-		OptionParam(name, options...)(r)
-	}
+	options = append([]func(*OpenAPIParam){ParamDescription(description), paramType(QueryParamType), ParamBool()}, options...)
+	return OptionParam(name, options...)
 }
 
 // OptionHeader declares a header parameter for the route.
@@ -84,10 +82,8 @@ func OptionQueryBool(name, description string, options ...func(*OpenAPIParam)) f
 //
 // The list of options is in the param package.
 func OptionHeader(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
-	options = append(options, ParamDescription(description), paramType(HeaderParamType))
-	return func(r *BaseRoute) {
-		OptionParam(name, options...)(r)
-	}
+	options = append([]func(*OpenAPIParam){ParamDescription(description), paramType(HeaderParamType)}, options...)
+	return OptionParam(name, options...)
 }
 
 // OptionCookie declares a cookie parameter for the route.
@@ -98,10 +94,8 @@ func OptionHeader(name, description string, options ...func(*OpenAPIParam)) func
 //
 // The list of options is in the param package.
 func OptionCookie(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
-	options = append(options, ParamDescription(description), paramType(CookieParamType))
-	return func(r *BaseRoute) {
-		OptionParam(name, options...)(r)
-	}
+	options = append([]func(*OpenAPIParam){ParamDescription(description), paramType(CookieParamType)}, options...)
+	return OptionParam(name, options...)
 }
 
 // OptionPath declares a path parameter for the route.
@@ -113,10 +107,8 @@ func OptionCookie(name, description string, options ...func(*OpenAPIParam)) func
 //
 // The list of options is in the param package.
 func OptionPath(name, description string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
-	options = append(options, ParamDescription(description), paramType(PathParamType), ParamRequired())
-	return func(r *BaseRoute) {
-		OptionParam(name, options...)(r)
-	}
+	options = append([]func(*OpenAPIParam){ParamDescription(description), paramType(PathParamType), ParamRequired()}, options...)
+	return OptionParam(name, options...)
 }
 
 func paramType(paramType ParamType) func(*OpenAPIParam) {
@@ -214,7 +206,9 @@ func buildParam(name string, options ...func(*OpenAPIParam)) (OpenAPIParam, *ope
 	if param.GoType != "" {
 		openapiParam.Schema.Value.Type = &openapi3.Types{param.GoType}
 	}
+	openapiParam.Deprecated = param.Deprecated
 	openapiParam.Schema.Value.Nullable = param.Nullable
+	openapiParam.Schema.Value.Enum = param.Enum
 	openapiParam.Schema.Value.Default = panicsIfNotCorrectType(openapiParam, param.Default)
 
 	if param.Required {
@@ -237,7 +231,17 @@ func OptionParam(name string, options ...func(*OpenAPIParam)) func(*BaseRoute) {
 	param, openapiParam := buildParam(name, options...)
 
 	return func(r *BaseRoute) {
-		r.Operation.AddParameter(openapiParam)
+		// A parameter declared again, for example by a route after its group, replaces the previous declaration
+		replaced := false
+		for i, existing := range r.Operation.Parameters {
+			if existing.Value != nil && existing.Value.In == openapiParam.In && existing.Value.Name == openapiParam.Name {
+				r.Operation.Parameters[i] = &openapi3.ParameterRef{Value: openapiParam}
+				replaced = true
+			}
+		}
+		if !replaced {
+			r.Operation.AddParameter(openapiParam)
+		}
 		if r.Params == nil {
 			r.Params = make(map[string]OpenAPIParam)
 		}
@@ -292,6 +296,16 @@ func OptionOverrideDescription(description string) func(*BaseRoute) {
 	}
 }
 
+// OptionExternalDocs links the route to external documentation, like a guide or a runbook,
+// in the externalDocs field of its operation. The description is optional.
+//
+//	fuego.Post(s, "/payments", createPayment, option.ExternalDocs("https://docs.example.com/payments", "Payments guide"))
+func OptionExternalDocs(url, description string) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.Operation.ExternalDocs = &openapi3.ExternalDocs{URL: url, Description: description}
+	}
+}
+
 // OptionOperationID adds an operation ID to the route.
 func OptionOperationID(operationID string) func(*BaseRoute) {
 	return func(r *BaseRoute) {
@@ -299,6 +313,19 @@ func OptionOperationID(operationID string) func(*BaseRoute) {
 	}
 }
 
+// OptionOperation gives access to the OpenAPI operation of the route, once generated by Fuego
+// and just before it is added to the spec, for what the other options do not cover.
+// The callbacks are called in the order of the options.
+//
+//	fuego.Get(s, "/pets", listPets, option.Operation(func(op *openapi3.Operation) {
+//		op.Extensions = map[string]any{"x-internal": true}
+//	}))
+func OptionOperation(callback func(op *openapi3.Operation)) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.operationCallbacks = append(r.operationCallbacks, callback)
+	}
+}
+
 // OptionDeprecated marks the route as deprecated.
 func OptionDeprecated() func(*BaseRoute) {
 	return func(r *BaseRoute) {
@@ -306,6 +333,54 @@ func OptionDeprecated() func(*BaseRoute) {
 	}
 }
 
+// OptionDeprecatedSince marks the route as deprecated since the given date.
+// The responses of the route get a Deprecation header, a Sunset header with the date after which
+// the route may be removed (if not zero), and a Link header to the given documentation (if not empty).
+// Each call to the route is logged, to follow how it is still used.
+//
+//	OptionDeprecatedSince(
+//		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+//		time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+//		"https://example.com/docs/migrate-to-v2",
+//	)
+func OptionDeprecatedSince(since, sunset time.Time, link string) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.Operation.Deprecated = true
+		r.Middlewares = append(r.Middlewares, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+				if !sunset.IsZero() {
+					w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+				}
+				if link != "" {
+					w.Header().Add("Link", "<"+link+">; rel=\"deprecation\"")
+				}
+				slog.Warn("deprecated route called", "method", req.Method, "path", req.URL.Path, "since", since, "sunset", sunset)
+				next.ServeHTTP(w, req)
+			})
+		})
+	}
+}
+
+// OptionNotice warns the clients of the route about an upcoming change, like a parameter becoming required.
+// The notice is sent in a Warning header of each response, with the 299 code (persistent warning),
+// and added to the description of the route. Declare notices with [WithRouteOptions] or on a group
+// to keep them in one place.
+//
+//	OptionNotice("parameter 'limit' will be required from 2025-07-01")
+func OptionNotice(message string) func(*BaseRoute) {
+	warning := `299 - ` + strconv.Quote(message)
+	return func(r *BaseRoute) {
+		r.Operation.Description += "\n\n**Notice:** " + message
+		r.Middlewares = append(r.Middlewares, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Add("Warning", warning)
+				next.ServeHTTP(w, req)
+			})
+		})
+	}
+}
+
 // OptionAddError adds an error to the route.
 // It replaces any existing error previously set with the same code.
 // Required: should only supply one type to `errorType`
@@ -349,13 +424,27 @@ type Response struct {
 // Required: Response.Type must be set
 // Optional: Response.ContentTypes will default to `application/json` and `application/xml` if not set
 func OptionAddResponse(code int, description string, response Response) func(*BaseRoute) {
+	// The response is built once per spec: global responses are added to every route.
+	// Each route gets its own copy, so that its headers can be documented separately.
+	var mu sync.Mutex
+	var builtFor *OpenAPI
+	var openapiResponse *openapi3.Response
+
 	return func(r *BaseRoute) {
+		mu.Lock()
+		if builtFor != r.OpenAPI || openapiResponse == nil {
+			openapiResponse = r.OpenAPI.buildOpenapi3Response(description, response)
+			builtFor = r.OpenAPI
+		}
+		routeResponse := *openapiResponse
+		mu.Unlock()
+
 		if r.Operation.Responses == nil {
 			r.Operation.Responses = openapi3.NewResponses()
 		}
 		r.Operation.Responses.Set(
 			strconv.Itoa(code), &openapi3.ResponseRef{
-				Value: r.OpenAPI.buildOpenapi3Response(description, response),
+				Value: &routeResponse,
 			},
 		)
 	}
@@ -370,6 +459,84 @@ func OptionRequestContentType(consumes ...string) func(*BaseRoute) {
 	}
 }
 
+// OptionResponseContentType sets the content types documented for the default response of the route.
+// By default, the documented content types are application/json and application/xml.
+// This will override any options set at the server level.
+// It only affects the OpenAPI spec: the response format is negotiated with the Accept header.
+// For example, to document YAML responses:
+//
+//	option.ResponseContentType("application/json", "application/yaml")
+func OptionResponseContentType(produces ...string) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.ResponseContentTypes = produces
+	}
+}
+
+// OptionBodyDecoder registers a request body decoder for the given media type on the route.
+// It takes precedence over the decoders registered with [WithBodyDecoder].
+// If the accepted content types of the route are set (see [OptionRequestContentType]),
+// the media type is added to them.
+func OptionBodyDecoder(mediaType string, decoder BodyDecoder) func(*BaseRoute) {
+	mediaType = strings.ToLower(mediaType)
+	return func(r *BaseRoute) {
+		if r.BodyDecoders == nil {
+			r.BodyDecoders = make(map[string]BodyDecoder)
+		}
+		r.BodyDecoders[mediaType] = decoder
+		if r.RequestContentTypes != nil && !slices.Contains(r.RequestContentTypes, mediaType) {
+			r.RequestContentTypes = append(slices.Clip(r.RequestContentTypes), mediaType)
+		}
+	}
+}
+
+// OptionOptionalBody makes the request body optional.
+// An empty body decodes to the zero value of the body type, without transformation nor validation,
+// and the request body is documented as not required in the OpenAPI spec.
+// Useful for PATCH routes where the body is not mandatory.
+func OptionOptionalBody() func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.OptionalBody = true
+	}
+}
+
+// OptionDisallowUnknownFields rejects the request bodies with fields unknown to the body type
+// with a 400 Bad Request error, whatever the DisallowUnknownFields option of the server.
+// Useful to keep internal APIs strict.
+func OptionDisallowUnknownFields() func(*BaseRoute) {
+	disallow := true
+	return func(r *BaseRoute) {
+		r.DisallowUnknownFields = &disallow
+	}
+}
+
+// OptionAllowUnknownFields ignores the fields of the request bodies unknown to the body type,
+// whatever the DisallowUnknownFields option of the server.
+// Useful for webhook receivers, whose payloads get new fields over time.
+//
+//	fuego.Post(s, "/webhooks/stripe", handleStripeEvent, option.AllowUnknownFields())
+func OptionAllowUnknownFields() func(*BaseRoute) {
+	disallow := false
+	return func(r *BaseRoute) {
+		r.DisallowUnknownFields = &disallow
+	}
+}
+
+// OptionValidationScenario validates the request body with the rules of the given scenario,
+// in addition to its validate tags. The rules of a scenario are read from the validate_<scenario> tags,
+// so that the same type can be reused by routes with different validation:
+//
+//	type Pet struct {
+//		Name string `json:"name" validate:"omitempty,max=50" validate_create:"required"`
+//	}
+//
+//	fuego.Post(s, "/pets", createPet, option.ValidationScenario("create")) // name is required
+//	fuego.Patch(s, "/pets/{id}", updatePet)                                // name is optional
+func OptionValidationScenario(scenario string) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.ValidationScenario = scenario
+	}
+}
+
 // OptionHide hides the route from the OpenAPI spec.
 func OptionHide() func(*BaseRoute) {
 	return func(r *BaseRoute) {
@@ -85,6 +85,27 @@ var Path = fuego.OptionPath
 // The list of options is in the param package.
 var ResponseHeader = fuego.OptionResponseHeader
 
+// File declares a file of the multipart/form-data request body, read with c.FormFile(name).
+// The request body is documented as multipart/form-data, with the file next to the fields of the body type.
+// Example:
+//
+//	File("avatar", "Profile picture", param.Required())
+var File = fuego.OptionFile
+
+// Files declares a field of the multipart/form-data request body with several files, read with c.FormFiles().
+// Example:
+//
+//	Files("attachments", "Attachments of the message")
+var Files = fuego.OptionFiles
+
+// Params declares the parameters of the request struct R in the OpenAPI spec,
+// from its path, query, header and cookie struct tags. Decode the request with [fuego.Bind].
+//
+//	fuego.Get(s, "/pets/{id}", getPet, option.Params[GetPetRequest]())
+func Params[R any]() func(*fuego.BaseRoute) {
+	return fuego.OptionParams[R]()
+}
+
 // Param registers a parameter for the route.
 //
 // Deprecated: Use [Query], [QueryInt], [Header], [Cookie], [Path] instead.
@@ -146,12 +167,27 @@ var OverrideDescription = fuego.OptionOverrideDescription
 //	})
 var Security = fuego.OptionSecurity
 
+// ExternalDocs links the route to external documentation, like a guide or a runbook.
+var ExternalDocs = fuego.OptionExternalDocs
+
 // OperationID adds an operation ID to the route.
 var OperationID = fuego.OptionOperationID
 
+// Operation gives access to the OpenAPI operation of the route, just before it is added to the spec.
+var Operation = fuego.OptionOperation
+
 // Deprecated marks the route as deprecated.
 var Deprecated = fuego.OptionDeprecated
 
+// Notice warns the clients of the route about an upcoming change, in a Warning header of each response
+// and in the description of the route.
+var Notice = fuego.OptionNotice
+
+// DeprecatedSince marks the route as deprecated since the given date.
+// Its responses get the Deprecation, Sunset (if the sunset date is not zero) and Link (if the link is not empty) headers,
+// and each call is logged.
+var DeprecatedSince = fuego.OptionDeprecatedSince
+
 // AddError adds an error to the route.
 // Deprecated: Use [AddResponse] instead.
 var AddError = fuego.OptionAddError
@@ -167,6 +203,36 @@ var AddResponse = fuego.OptionAddResponse
 // This will override any options set at the server level.
 var RequestContentType = fuego.OptionRequestContentType
 
+// ResponseContentType sets the content types documented for the default response of the route.
+// By default, the documented content types are application/json and application/xml.
+// This will override any options set at the server level.
+var ResponseContentType = fuego.OptionResponseContentType
+
+// OptionalBody makes the request body optional.
+// An empty body decodes to the zero value of the body type, without transformation nor validation,
+// and the request body is documented as not required in the OpenAPI spec.
+var OptionalBody = fuego.OptionOptionalBody
+
+// DisallowUnknownFields rejects the request bodies with fields unknown to the body type
+// with a 400 Bad Request error, whatever the DisallowUnknownFields option of the server.
+var DisallowUnknownFields = fuego.OptionDisallowUnknownFields
+
+// AllowUnknownFields ignores the fields of the request bodies unknown to the body type,
+// whatever the DisallowUnknownFields option of the server. Useful for webhook receivers.
+//
+//	fuego.Post(s, "/webhooks/stripe", handleStripeEvent, option.AllowUnknownFields())
+var AllowUnknownFields = fuego.OptionAllowUnknownFields
+
+// ValidationScenario validates the request body with the rules of the given scenario,
+// read from the validate_<scenario> tags, in addition to its validate tags.
+//
+//	type Pet struct {
+//		Name string `json:"name" validate:"omitempty,max=50" validate_create:"required"`
+//	}
+//
+//	fuego.Post(s, "/pets", createPet, option.ValidationScenario("create"))
+var ValidationScenario = fuego.OptionValidationScenario
+
 // Hide hides the route from the OpenAPI spec.
 var Hide = fuego.OptionHide
 
@@ -175,3 +241,77 @@ var Show = fuego.OptionShow
 
 // DefaultStatusCode sets the default status code for the route.
 var DefaultStatusCode = fuego.OptionDefaultStatusCode
+
+// SecurityHeaders overrides the security headers set by [fuego.WithSecurityHeaders] for the route.
+// Only the non-empty fields are overridden.
+var SecurityHeaders = fuego.OptionSecurityHeaders
+
+// AllowIPs restricts the route to the given IP addresses or CIDR ranges.
+// Rejected requests get a 403 Forbidden error, documented in the OpenAPI spec.
+// Example:
+//
+//	AllowIPs("10.0.0.0/8", "::1")
+var AllowIPs = fuego.OptionAllowIPs
+
+// DenyIPs rejects the given IP addresses or CIDR ranges on the route.
+// Rejected requests get a 403 Forbidden error, documented in the OpenAPI spec.
+var DenyIPs = fuego.OptionDenyIPs
+
+// Cost sets the cost of the route for the rate limiting set with [fuego.WithRateLimit]:
+// each request consumes weight units of the client budget. Defaults to 1, 0 excludes the route.
+// Example:
+//
+//	Cost(20) // for an export endpoint
+var Cost = fuego.OptionCost
+
+// Priority sets the priority of the route for the load shedding set with [fuego.WithLoadShedding]:
+// when the server is overloaded, the lowest priorities are rejected first. Defaults to [fuego.PriorityNormal].
+// Example:
+//
+//	Priority(fuego.PriorityCritical) // for a health check
+var Priority = fuego.OptionPriority
+
+// Indexable lists the route in the sitemap served by [fuego.Sitemap], with the given priority and change frequency.
+// Example:
+//
+//	Indexable(0.8, fuego.ChangeFreqWeekly)
+var Indexable = fuego.OptionIndexable
+
+// WebsocketOrigins allows the cross-origin connections from the given origins to a [fuego.Websocket] route.
+// Example:
+//
+//	WebsocketOrigins("https://*.example.com")
+var WebsocketOrigins = fuego.OptionWebsocketOrigins
+
+// VerifySignature verifies the signature of the request (typically a webhook) before the body is deserialized.
+// Requests with a missing or invalid signature get a 401 Unauthorized error.
+// Example:
+//
+//	VerifySignature(fuego.GitHubSignature, fuego.StaticSecret("my-secret"))
+var VerifySignature = fuego.OptionVerifySignature
+
+// BodyDecoder registers a request body decoder for the given media type on the route.
+// It takes precedence over the decoders registered with [fuego.WithBodyDecoder].
+var BodyDecoder = fuego.OptionBodyDecoder
+
+// Transactional runs the controller in a database transaction, committed if the controller succeeds
+// and rolled back if it fails or panics. The controller gets the transaction with [fuego.Transaction].
+// Example:
+//
+//	Transactional(func(ctx context.Context) (fuego.Tx, error) { return db.BeginTx(ctx, nil) })
+var Transactional = fuego.OptionTransactional
+
+// Variant serves the route with one of the variants, chosen for each request by the selector,
+// for gradual rollouts and A/B tests. The controller of the route serves the other requests.
+// Example:
+//
+//	Variant(fuego.VariantByHeader("X-Feature-Checkout"), fuego.Variant{Name: "v2", Controller: checkoutV2})
+var Variant = fuego.OptionVariant
+
+// Timeout limits the time of the route: after the timeout, the request context is canceled
+// and the client gets a 504 Gateway Timeout error, documented in the OpenAPI spec.
+// The controller must honour c.Context(): it keeps running after the timeout otherwise.
+// Example:
+//
+//	Timeout(5 * time.Second)
+var Timeout = fuego.OptionTimeout
@@ -2,10 +2,13 @@ package fuego_test
 
 import (
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/require"
@@ -247,6 +250,99 @@ func TestOpenAPI(t *testing.T) {
 	})
 }
 
+func TestOptionDeprecatedSince(t *testing.T) {
+	handler := slogassert.New(t, slog.LevelWarn, nil)
+	s := fuego.NewServer(
+		fuego.WithLogHandler(handler),
+	)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	route := fuego.Get(s, "/v1/pets", helloWorld,
+		option.DeprecatedSince(since, sunset, "https://example.com/migrate"),
+	)
+	fuego.Get(s, "/v2/pets", helloWorld,
+		option.DeprecatedSince(since, time.Time{}, ""),
+	)
+	require.True(t, route.Operation.Deprecated)
+
+	t.Run("sends the deprecation headers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/pets", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "@1735689600", w.Result().Header.Get("Deprecation"))
+		require.Equal(t, "Tue, 01 Jul 2025 00:00:00 GMT", w.Result().Header.Get("Sunset"))
+		require.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, w.Result().Header.Get("Link"))
+		handler.AssertSomeMessage("deprecated route called")
+	})
+
+	t.Run("sunset and link are optional", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/pets", nil))
+
+		require.Equal(t, "@1735689600", w.Result().Header.Get("Deprecation"))
+		require.Empty(t, w.Result().Header.Get("Sunset"))
+		require.Empty(t, w.Result().Header.Get("Link"))
+		handler.AssertSomeMessage("deprecated route called")
+	})
+}
+
+func TestOptionNotice(t *testing.T) {
+	s := fuego.NewServer()
+	group := fuego.Group(s, "/pets",
+		option.Notice("parameter 'limit' will be required"),
+	)
+	route := fuego.Get(group, "/", helloWorld,
+		option.Notice(`the "name" field will be removed`),
+	)
+
+	require.Contains(t, route.Operation.Description, "**Notice:** parameter 'limit' will be required")
+	require.Contains(t, route.Operation.Description, `**Notice:** the "name" field will be removed`)
+
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets/", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{
+		`299 - "parameter 'limit' will be required"`,
+		`299 - "the \"name\" field will be removed"`,
+	}, w.Result().Header.Values("Warning"))
+}
+
+func TestOptionOperation(t *testing.T) {
+	s := fuego.NewServer()
+	route := fuego.Get(s, "/pets/{id}", helloWorld,
+		option.Summary("Get a pet"),
+		option.Operation(func(op *openapi3.Operation) {
+			op.Extensions = map[string]any{"x-internal": true}
+			op.Responses.Value("200").Value.WithDescription("The pet")
+		}),
+		option.Operation(func(op *openapi3.Operation) {
+			op.Summary += " by ID"
+		}),
+	)
+
+	require.Equal(t, "Get a pet by ID", route.Operation.Summary)
+	require.Equal(t, true, route.Operation.Extensions["x-internal"])
+
+	documented := s.OpenAPI.Description().Paths.Find("/pets/{id}").Get
+	require.Equal(t, "The pet", *documented.Responses.Value("200").Value.Description)
+	require.NotNil(t, documented.Parameters.GetByInAndName("path", "id"))
+}
+
+func TestOptionExternalDocs(t *testing.T) {
+	s := fuego.NewServer()
+	route := fuego.Get(s, "/payments", helloWorld,
+		option.ExternalDocs("https://docs.example.com/payments", "Payments guide"),
+	)
+
+	require.Equal(t, &openapi3.ExternalDocs{
+		URL:         "https://docs.example.com/payments",
+		Description: "Payments guide",
+	}, route.Operation.ExternalDocs)
+}
+
 func TestGroup(t *testing.T) {
 	paramsGroup := fuego.GroupOptions(
 		fuego.OptionHeader("X-Test", "test header", param.Required(), param.Example("test", "My Header"), param.Default("test")),
@@ -395,6 +491,49 @@ func TestRequestContentType(t *testing.T) {
 	})
 }
 
+func TestResponseContentType(t *testing.T) {
+	t.Run("defaults to JSON and XML", func(t *testing.T) {
+		s := fuego.NewServer()
+		route := fuego.Get(s, "/test", dummyController)
+
+		content := route.Operation.Responses.Value("200").Value.Content
+		require.Len(t, content, 2)
+		require.NotNil(t, content.Get("application/json"))
+		require.NotNil(t, content.Get("application/xml"))
+	})
+
+	t.Run("YAML route", func(t *testing.T) {
+		s := fuego.NewServer()
+		route := fuego.Post(s, "/test", dummyController,
+			fuego.OptionRequestContentType("application/json", "application/yaml"),
+			fuego.OptionResponseContentType("application/json", "application/yaml"),
+		)
+
+		requestContent := route.Operation.RequestBody.Value.Content
+		require.NotNil(t, requestContent.Get("application/yaml"))
+
+		content := route.Operation.Responses.Value("200").Value.Content
+		require.Len(t, content, 2)
+		require.NotNil(t, content.Get("application/yaml"))
+		require.Nil(t, content.Get("application/xml"))
+		require.Equal(t, "#/components/schemas/Resp", content.Get("application/yaml").Schema.Ref)
+	})
+
+	t.Run("override server", func(t *testing.T) {
+		s := fuego.NewServer(fuego.WithEngineOptions(
+			fuego.WithResponseContentType("application/yaml"),
+		))
+		fromServer := fuego.Get(s, "/server", dummyController)
+		fromRoute := fuego.Get(s, "/route", dummyController,
+			fuego.OptionResponseContentType("application/json"),
+		)
+
+		require.NotNil(t, fromServer.Operation.Responses.Value("200").Value.Content.Get("application/yaml"))
+		require.Nil(t, fromServer.Operation.Responses.Value("200").Value.Content.Get("application/json"))
+		require.Nil(t, fromRoute.Operation.Responses.Value("200").Value.Content.Get("application/yaml"))
+	})
+}
+
 func TestAddError(t *testing.T) {
 	t.Run("Declare an error for the route", func(t *testing.T) {
 		s := fuego.NewServer()
@@ -909,3 +1048,91 @@ func TestDefaultStatusCode(t *testing.T) {
 		require.Equal(t, 500, w.Code)
 	})
 }
+
+func TestOptionalBody(t *testing.T) {
+	type petPatch struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	s := fuego.NewServer()
+	route := fuego.Patch(s, "/pets/{id}", func(c fuego.ContextWithBody[petPatch]) (petPatch, error) {
+		return c.Body()
+	}, option.OptionalBody())
+
+	require.False(t, route.Operation.RequestBody.Value.Required)
+
+	t.Run("empty body decodes to the zero value", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/pets/1", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name":""}`, w.Body.String())
+	})
+
+	t.Run("empty body of unknown length", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPatch, "/pets/1", io.NopCloser(strings.NewReader("")))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("non-empty body is still validated", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPatch, "/pets/1", io.NopCloser(strings.NewReader(`{"name":""}`)))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("body is required by default", func(t *testing.T) {
+		route := fuego.Patch(s, "/owners/{id}", func(c fuego.ContextWithBody[petPatch]) (petPatch, error) {
+			return c.Body()
+		})
+		require.True(t, route.Operation.RequestBody.Value.Required)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/owners/1", nil))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUnknownFields(t *testing.T) {
+	type event struct {
+		Type string `json:"type"`
+	}
+	controller := func(c fuego.ContextWithBody[event]) (event, error) {
+		return c.Body()
+	}
+	post := func(s *fuego.Server, path string) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"type":"paid","livemode":true}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	t.Run("lenient routes of a strict server", func(t *testing.T) {
+		s := fuego.NewServer(fuego.WithDisallowUnknownFields(true))
+		fuego.Post(s, "/internal", controller)
+		webhooks := fuego.Group(s, "/webhooks", option.AllowUnknownFields())
+		fuego.Post(webhooks, "/stripe", controller)
+		fuego.Post(webhooks, "/strict", controller, option.DisallowUnknownFields())
+
+		require.Equal(t, http.StatusBadRequest, post(s, "/internal"))
+		require.Equal(t, http.StatusOK, post(s, "/webhooks/stripe"))
+		require.Equal(t, http.StatusBadRequest, post(s, "/webhooks/strict"))
+	})
+
+	t.Run("strict routes of a lenient server", func(t *testing.T) {
+		s := fuego.NewServer(fuego.WithDisallowUnknownFields(false))
+		fuego.Post(s, "/public", controller)
+		fuego.Post(s, "/internal", controller, option.DisallowUnknownFields())
+
+		require.Equal(t, http.StatusOK, post(s, "/public"))
+		require.Equal(t, http.StatusBadRequest, post(s, "/internal"))
+	})
+}
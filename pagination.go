@@ -0,0 +1,132 @@
+package fuego
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// PaginationConfig configures the pagination read by c.Pagination. See [WithPagination].
+type PaginationConfig struct {
+	// DefaultPerPage is the number of items per page when the request does not set it. Defaults to 20.
+	DefaultPerPage int
+	// MaxPerPage caps the number of items per page asked by the requests. Defaults to 100.
+	MaxPerPage int
+}
+
+// WithPagination sets the default and the maximum number of items per page of c.Pagination,
+// also documented on the routes returning a [Page].
+func WithPagination(config PaginationConfig) func(*Server) {
+	return func(s *Server) { s.pagination = config }
+}
+
+func (config PaginationConfig) withDefaults() PaginationConfig {
+	if config.MaxPerPage <= 0 {
+		config.MaxPerPage = 100
+	}
+	if config.DefaultPerPage <= 0 {
+		config.DefaultPerPage = 20
+	}
+	config.DefaultPerPage = min(config.DefaultPerPage, config.MaxPerPage)
+	return config
+}
+
+// Parse reads the pagination from the page, per_page (or limit) and cursor query parameters.
+// Missing or invalid values are replaced by the defaults, and per_page is capped to [PaginationConfig.MaxPerPage].
+// c.Pagination calls it with the configuration of the server. Use it directly in the standard handlers.
+func (config PaginationConfig) Parse(query url.Values) Pagination {
+	config = config.withDefaults()
+	pagination := Pagination{
+		Page:    1,
+		PerPage: config.DefaultPerPage,
+		Cursor:  query.Get("cursor"),
+	}
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page > 0 {
+		pagination.Page = page
+	}
+	perPage := query.Get("per_page")
+	if perPage == "" {
+		perPage = query.Get("limit")
+	}
+	if perPage, err := strconv.Atoi(perPage); err == nil && perPage > 0 {
+		pagination.PerPage = min(perPage, config.MaxPerPage)
+	}
+	return pagination
+}
+
+// Pagination is the page asked by a request, returned by c.Pagination.
+type Pagination struct {
+	// Page number, from 1.
+	Page int
+	// PerPage is the number of items per page.
+	PerPage int
+	// Cursor is the next_cursor of the previous page, for the cursor-based pagination. Empty for the first page.
+	Cursor string
+}
+
+// Offset returns the number of items before the page, for the SQL OFFSET clause.
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Page is a page of items. The routes returning it declare the page, per_page and cursor query parameters,
+// read with c.Pagination. Build it with [NewPage], or set NextCursor for the cursor-based pagination:
+//
+//	fuego.Get(s, "/pets", func(c fuego.ContextNoBody) (fuego.Page[Pet], error) {
+//		p := c.Pagination()
+//		pets, total, err := store.ListPets(c, p.Offset(), p.PerPage)
+//		return fuego.NewPage(pets, p, total), err
+//	})
+type Page[T any] struct {
+	Items   []T `json:"items" xml:"items"`
+	Page    int `json:"page,omitempty" xml:"page,omitempty"`
+	PerPage int `json:"per_page" xml:"per_page"`
+	// Total number of items, if known.
+	Total *int `json:"total,omitempty" xml:"total,omitempty"`
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+}
+
+// NewPage returns the page of items asked by the pagination, out of total items.
+func NewPage[T any](items []T, pagination Pagination, total int) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{
+		Items:   items,
+		Page:    pagination.Page,
+		PerPage: pagination.PerPage,
+		Total:   &total,
+	}
+}
+
+func (Page[T]) paginated() {}
+
+// pageResponse is implemented by [Page].
+type pageResponse interface {
+	paginated()
+}
+
+func isPageResponse[T any]() bool {
+	return reflect.TypeFor[T]().Implements(reflect.TypeFor[pageResponse]())
+}
+
+// optionPagination declares the pagination query parameters, unless the route already declares them.
+func optionPagination(config PaginationConfig) func(*BaseRoute) {
+	config = config.withDefaults()
+	return func(r *BaseRoute) {
+		for _, param := range []struct {
+			name   string
+			option func(*BaseRoute)
+		}{
+			{"page", OptionQueryInt("page", "Page number, from 1", ParamDefault(1))},
+			{"per_page", OptionQueryInt("per_page", fmt.Sprintf("Number of items per page, up to %d", config.MaxPerPage), ParamDefault(config.DefaultPerPage))},
+			{"cursor", OptionQuery("cursor", "Cursor of the page, from the next_cursor of the previous page")},
+		} {
+			if r.Operation.Parameters.GetByInAndName(string(QueryParamType), param.name) == nil {
+				param.option(r)
+			}
+		}
+	}
+}
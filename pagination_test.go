@@ -0,0 +1,125 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginationConfigParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		config   PaginationConfig
+		expected Pagination
+	}{
+		{name: "defaults", expected: Pagination{Page: 1, PerPage: 20}},
+		{name: "page and per_page", query: "page=3&per_page=50", expected: Pagination{Page: 3, PerPage: 50}},
+		{name: "limit", query: "limit=5", expected: Pagination{Page: 1, PerPage: 5}},
+		{name: "per_page over limit", query: "per_page=5&limit=10", expected: Pagination{Page: 1, PerPage: 5}},
+		{name: "capped", query: "per_page=1000", expected: Pagination{Page: 1, PerPage: 100}},
+		{name: "invalid values", query: "page=-1&per_page=abc", expected: Pagination{Page: 1, PerPage: 20}},
+		{name: "cursor", query: "cursor=abc", expected: Pagination{Page: 1, PerPage: 20, Cursor: "abc"}},
+		{
+			name:     "config",
+			query:    "per_page=80",
+			config:   PaginationConfig{DefaultPerPage: 10, MaxPerPage: 50},
+			expected: Pagination{Page: 1, PerPage: 50},
+		},
+		{name: "default capped", config: PaginationConfig{DefaultPerPage: 80, MaxPerPage: 50}, expected: Pagination{Page: 1, PerPage: 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.config.Parse(query))
+		})
+	}
+}
+
+func TestPaginationOffset(t *testing.T) {
+	require.Equal(t, 0, Pagination{Page: 1, PerPage: 20}.Offset())
+	require.Equal(t, 40, Pagination{Page: 3, PerPage: 20}.Offset())
+}
+
+func TestPage(t *testing.T) {
+	pets := []string{"Rex", "Felix", "Nemo", "Kiki", "Rantanplan"}
+	s := NewServer(WithoutLogger(), WithPagination(PaginationConfig{DefaultPerPage: 2, MaxPerPage: 3}))
+	Get(s, "/pets", func(c ContextNoBody) (Page[string], error) {
+		p := c.Pagination()
+		start := min(p.Offset(), len(pets))
+		end := min(start+p.PerPage, len(pets))
+		return NewPage(pets[start:end], p, len(pets)), nil
+	})
+	Get(s, "/feed", func(c ContextNoBody) (Page[string], error) {
+		return Page[string]{Items: []string{c.Pagination().Cursor}, PerPage: 1, NextCursor: "next"}, nil
+	}, OptionQuery("cursor", "Opaque cursor", ParamRequired()))
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		w := get("/pets")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"items":["Rex","Felix"],"page":1,"per_page":2,"total":5}`, w.Body.String())
+	})
+
+	t.Run("capped page", func(t *testing.T) {
+		w := get("/pets?page=2&per_page=10")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"items":["Kiki","Rantanplan"],"page":2,"per_page":3,"total":5}`, w.Body.String())
+	})
+
+	t.Run("past the last page", func(t *testing.T) {
+		w := get("/pets?page=10")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"items":[],"page":10,"per_page":2,"total":5}`, w.Body.String())
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		w := get("/feed?cursor=abc")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"items":["abc"],"per_page":1,"next_cursor":"next"}`, w.Body.String())
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		spec := s.OutputOpenAPISpec()
+		operation := spec.Paths.Find("/pets").Get
+
+		page := operation.Parameters.GetByInAndName("query", "page")
+		require.NotNil(t, page)
+		require.Equal(t, 1, page.Schema.Value.Default)
+		perPage := operation.Parameters.GetByInAndName("query", "per_page")
+		require.NotNil(t, perPage)
+		require.Equal(t, 2, perPage.Schema.Value.Default)
+		require.Contains(t, perPage.Description, "up to 3")
+		require.NotNil(t, operation.Parameters.GetByInAndName("query", "cursor"))
+
+		schema := operation.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema
+		require.Contains(t, schema.Value.Properties, "items")
+		require.Contains(t, schema.Value.Properties, "per_page")
+		require.Contains(t, schema.Value.Properties, "next_cursor")
+		require.Equal(t, "array", schema.Value.Properties["items"].Value.Type.Slice()[0])
+
+		// Declared parameters are kept
+		cursor := spec.Paths.Find("/feed").Get.Parameters.GetByInAndName("query", "cursor")
+		require.True(t, cursor.Required)
+	})
+
+	t.Run("other routes are not paginated", func(t *testing.T) {
+		Get(s, "/all-pets", func(c ContextNoBody) ([]string, error) { return pets, nil })
+		operation := s.OutputOpenAPISpec().Paths.Find("/all-pets").Get
+		require.Nil(t, operation.Parameters.GetByInAndName("query", "page"))
+	})
+}
+
+func TestMockContextPagination(t *testing.T) {
+	c := NewMockContextNoBody().SetQueryParamInt("page", 2).SetQueryParamInt("per_page", 500)
+	require.Equal(t, Pagination{Page: 2, PerPage: 100}, c.Pagination())
+}
@@ -30,6 +30,15 @@ func ParamBool() func(param *OpenAPIParam) {
 	}
 }
 
+// ParamDeprecated marks the parameter as deprecated.
+func ParamDeprecated() func(param *OpenAPIParam) {
+	return func(param *OpenAPIParam) {
+		param.Deprecated = true
+	}
+}
+
+// ParamDescription sets the description of the parameter.
+// It overrides the description given to the shortcuts like [OptionQuery] or [OptionHeader].
 func ParamDescription(description string) func(param *OpenAPIParam) {
 	return func(param *OpenAPIParam) {
 		param.Description = description
@@ -42,6 +51,14 @@ func ParamDefault(value any) func(param *OpenAPIParam) {
 	}
 }
 
+// ParamEnum restricts the parameter to the given values.
+// The default value and the examples must be part of them, this is checked at start-time.
+func ParamEnum(values ...any) func(param *OpenAPIParam) {
+	return func(param *OpenAPIParam) {
+		param.Enum = values
+	}
+}
+
 // ParamExample adds an example to the parameter. As per the OpenAPI 3.0 standard, the example must be given a name.
 func ParamExample(exampleName string, value any) func(param *OpenAPIParam) {
 	return func(param *OpenAPIParam) {
@@ -52,6 +69,15 @@ func ParamExample(exampleName string, value any) func(param *OpenAPIParam) {
 	}
 }
 
+// ParamExamples adds several examples to the parameter, by name.
+func ParamExamples(examples map[string]any) func(param *OpenAPIParam) {
+	return func(param *OpenAPIParam) {
+		for exampleName, value := range examples {
+			ParamExample(exampleName, value)(param)
+		}
+	}
+}
+
 // ParamStatusCodes sets the status codes for which this parameter is required.
 // Only used for response parameters.
 // If empty, it is required for 200 status codes.
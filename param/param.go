@@ -19,16 +19,27 @@ var Integer = fuego.ParamInteger
 // Please prefer QueryBool for clarity.
 var Bool = fuego.ParamBool
 
+// Deprecated marks the parameter as deprecated.
+var Deprecated = fuego.ParamDeprecated
+
 // Description sets the description for the parameter.
+// It overrides the description given to the shortcuts like option.Query or option.Header.
 var Description = fuego.ParamDescription
 
 // Default sets the default value for the parameter.
 // Type is checked at start-time.
 var Default = fuego.ParamDefault
 
+// Enum restricts the parameter to the given values.
+// The default value and the examples must be part of them, this is checked at start-time.
+var Enum = fuego.ParamEnum
+
 // Example adds an example to the parameter. As per the OpenAPI 3.0 standard, the example must be given a name.
 var Example = fuego.ParamExample
 
+// Examples adds several examples to the parameter, by name.
+var Examples = fuego.ParamExamples
+
 // StatusCodes sets the status codes for which this parameter is required.
 // Only used for response parameters.
 // If empty, it is required for 200 status codes.
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-fuego/fuego"
 	"github.com/go-fuego/fuego/option"
+	"github.com/go-fuego/fuego/param"
 )
 
 func TestParams(t *testing.T) {
@@ -46,3 +47,28 @@ func TestParams(t *testing.T) {
 		require.Equal(t, "Accept", route.Params["Accept"].Name)
 	})
 }
+
+func TestParamsDocumentation(t *testing.T) {
+	s := fuego.NewServer()
+
+	route := fuego.Get(s, "/test", func(c fuego.ContextNoBody) (string, error) {
+		return "", nil
+	},
+		option.Query("sort", "Sort order", param.Deprecated()),
+		option.Header("X-Org", "Organization", param.Description("Organization of the caller")),
+		option.QueryInt("page", "Page", param.Examples(map[string]any{"first": 1, "last": 42})),
+	)
+
+	sort := route.Operation.Parameters.GetByInAndName("query", "sort")
+	require.True(t, sort.Deprecated)
+	require.Equal(t, "Sort order", sort.Description)
+
+	org := route.Operation.Parameters.GetByInAndName("header", "X-Org")
+	require.False(t, org.Deprecated)
+	require.Equal(t, "Organization of the caller", org.Description)
+
+	page := route.Operation.Parameters.GetByInAndName("query", "page")
+	require.Len(t, page.Examples, 2)
+	require.Equal(t, 1, page.Examples["first"].Value.Value)
+	require.Equal(t, 42, page.Examples["last"].Value.Value)
+}
@@ -0,0 +1,116 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var errNoPDFConverter = errors.New("cannot render a PDF without converter, see WithPDFConverter")
+
+// PDFConverter converts HTML documents to PDF, for c.RenderPDF.
+// Implement it with a headless browser, like chromedp, or use a [GotenbergConverter].
+type PDFConverter interface {
+	// ConvertPDF reads an HTML document from html, and writes the PDF document to w.
+	ConvertPDF(ctx context.Context, html io.Reader, w io.Writer) error
+}
+
+// WithPDFConverter sets the converter of the HTML renderings of c.RenderPDF to PDF.
+// For example:
+//
+//	WithPDFConverter(fuego.GotenbergConverter{URL: "http://gotenberg:3000"})
+func WithPDFConverter(converter PDFConverter) func(*Server) {
+	return func(s *Server) { s.pdfConverter = converter }
+}
+
+// PDF is a PDF document rendered from HTML templates, returned by c.RenderPDF.
+// It is sent as application/pdf whatever the Accept header of the request.
+// The templates are rendered and converted once the controller returns: a failure is sent
+// as an error response, before anything else is written.
+type PDF struct {
+	html      CtxRenderer
+	converter PDFConverter
+
+	// Name of the downloaded file, like "invoice.pdf". If set, the document is sent as an attachment.
+	Name string
+}
+
+func isPDFResponse[T any]() bool {
+	return reflect.TypeFor[T]() == reflect.TypeFor[PDF]()
+}
+
+// send renders the HTML, converts it to PDF, and sends the document.
+func (p PDF) send(w http.ResponseWriter, r *http.Request) error {
+	if p.html == nil || p.converter == nil {
+		return errNoPDFConverter
+	}
+	var html bytes.Buffer
+	if err := p.html.Render(r.Context(), &html); err != nil {
+		return err
+	}
+	var pdf bytes.Buffer
+	if err := p.converter.ConvertPDF(r.Context(), &html, &pdf); err != nil {
+		return fmt.Errorf("convert to PDF: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	if p.Name != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+p.Name+`"`)
+	}
+	_, err := pdf.WriteTo(w)
+	return err
+}
+
+// GotenbergConverter converts HTML to PDF with a Gotenberg server (https://gotenberg.dev),
+// through its Chromium route.
+type GotenbergConverter struct {
+	// URL of the Gotenberg server, like "http://gotenberg:3000".
+	URL string
+	// Client of the requests to the Gotenberg server. Defaults to [http.DefaultClient].
+	Client *http.Client
+}
+
+var _ PDFConverter = GotenbergConverter{}
+
+func (g GotenbergConverter) ConvertPDF(ctx context.Context, html io.Reader, w io.Writer) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("files", "index.html")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, html); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(g.URL, "/")+"/forms/chromium/convert/html", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("gotenberg: %s: %s", res.Status, strings.TrimSpace(string(message)))
+	}
+	_, err = io.Copy(w, res.Body)
+	return err
+}
@@ -0,0 +1,113 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakePDFConverter "converts" the HTML by prefixing it with the PDF header.
+type fakePDFConverter struct {
+	err error
+}
+
+func (f fakePDFConverter) ConvertPDF(_ context.Context, html io.Reader, w io.Writer) error {
+	if f.err != nil {
+		return f.err
+	}
+	_, _ = io.WriteString(w, "%PDF-1.7\n")
+	_, err := io.Copy(w, html)
+	return err
+}
+
+func TestRenderPDF(t *testing.T) {
+	templates := template.Must(template.New("invoice.html").Parse(`<h1>Invoice {{ .ID }}</h1>`))
+	newServer := func(converter PDFConverter) *Server {
+		s := NewServer(WithoutLogger(), WithTemplates(templates), WithPDFConverter(converter))
+		Get(s, "/invoices/{id}", func(c ContextNoBody) (PDF, error) {
+			pdf, err := c.RenderPDF("invoice.html", H{"ID": c.PathParam("id")})
+			pdf.Name = "invoice-" + c.PathParam("id") + ".pdf"
+			return pdf, err
+		})
+		return s
+	}
+
+	t.Run("pdf", func(t *testing.T) {
+		s := newServer(fakePDFConverter{})
+		r := httptest.NewRequest(http.MethodGet, "/invoices/42", nil)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		require.Equal(t, `attachment; filename="invoice-42.pdf"`, w.Header().Get("Content-Disposition"))
+		require.Equal(t, "%PDF-1.7\n<h1>Invoice 42</h1>", w.Body.String())
+	})
+
+	t.Run("conversion failure", func(t *testing.T) {
+		s := newServer(fakePDFConverter{err: errors.New("browser crashed")})
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/invoices/42", nil))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotContains(t, w.Body.String(), "Invoice 42")
+	})
+
+	t.Run("without converter", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithTemplates(templates))
+		Get(s, "/invoice", func(c ContextNoBody) (PDF, error) {
+			return c.RenderPDF("invoice.html", nil)
+		})
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/invoice", nil))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		s := newServer(fakePDFConverter{})
+		content := s.OutputOpenAPISpec().Paths.Find("/invoices/{id}").Get.Responses.Status(http.StatusOK).Value.Content
+		require.Len(t, content, 1)
+		require.Equal(t, "binary", content.Get("application/pdf").Schema.Value.Format)
+	})
+}
+
+func TestGotenbergConverter(t *testing.T) {
+	gotenberg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/forms/chromium/convert/html" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		file, header, err := r.FormFile("files")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		html, _ := io.ReadAll(file)
+		_, _ = io.WriteString(w, "%PDF "+header.Filename+" "+string(html))
+	}))
+	defer gotenberg.Close()
+
+	t.Run("convert", func(t *testing.T) {
+		var pdf strings.Builder
+		err := GotenbergConverter{URL: gotenberg.URL + "/"}.ConvertPDF(context.Background(), strings.NewReader("<p>hi</p>"), &pdf)
+		require.NoError(t, err)
+		require.Equal(t, "%PDF index.html <p>hi</p>", pdf.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var pdf strings.Builder
+		err := GotenbergConverter{URL: gotenberg.URL + "/prefix"}.ConvertPDF(context.Background(), strings.NewReader("<p>hi</p>"), &pdf)
+		require.EqualError(t, err, "gotenberg: 404 Not Found: not found")
+		require.Empty(t, pdf.String())
+	})
+}
@@ -0,0 +1,99 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Plugin bundles routes, middlewares and spec edits in a reusable extension,
+// for example a metrics or an authentication plugin. See [WithPlugins].
+//
+//	type metricsPlugin struct{ registry *prometheus.Registry }
+//
+//	func (p metricsPlugin) Install(s *fuego.Server) error {
+//		fuego.Use(s, p.middleware)
+//		fuego.GetStd(s, "/metrics", promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{}).ServeHTTP, option.Hide())
+//		return nil
+//	}
+type Plugin interface {
+	// Install is called once, when the server is created.
+	// The routes and middlewares are registered on the given server.
+	Install(s *Server) error
+}
+
+// PluginStarter is implemented by the plugins that start resources, like a connection
+// or a background worker, when the server runs.
+type PluginStarter interface {
+	// Start is called by [Server.Run], before the requests are served.
+	// An error prevents the server from running.
+	Start(ctx context.Context) error
+}
+
+// PluginStopper is implemented by the plugins releasing resources when the server shuts down.
+type PluginStopper interface {
+	// Stop is called by [Server.Shutdown], after the requests in progress are served.
+	Stop(ctx context.Context) error
+}
+
+// WithPlugins installs the plugins on the server, in order, once the other options are applied.
+// The plugins implementing [PluginStarter] and [PluginStopper] are started by [Server.Run]
+// and stopped, in the reverse order, by [Server.Shutdown].
+//
+//	s := fuego.NewServer(
+//		fuego.WithPlugins(metrics.New(), audit.New(db)),
+//	)
+func WithPlugins(plugins ...Plugin) func(*Server) {
+	return func(s *Server) {
+		s.plugins = append(s.plugins, plugins...)
+	}
+}
+
+// installPlugins installs the plugins, and keeps the first error to return it on [Server.Run].
+func (s *Server) installPlugins() {
+	for _, plugin := range s.plugins {
+		if err := plugin.Install(s); err != nil {
+			s.pluginsErr = fmt.Errorf("install plugin %s: %w", pluginName(plugin), err)
+			return
+		}
+	}
+}
+
+// startPlugins starts the plugins. If one of them fails, the plugins already started are stopped.
+func (s *Server) startPlugins(ctx context.Context) error {
+	for _, plugin := range s.plugins {
+		starter, ok := plugin.(PluginStarter)
+		if !ok {
+			s.startedPlugins = append(s.startedPlugins, plugin)
+			continue
+		}
+		if err := starter.Start(ctx); err != nil {
+			return errors.Join(
+				fmt.Errorf("start plugin %s: %w", pluginName(plugin), err),
+				s.stopPlugins(ctx),
+			)
+		}
+		s.startedPlugins = append(s.startedPlugins, plugin)
+	}
+	return nil
+}
+
+// stopPlugins stops the started plugins in the reverse order, and joins their errors.
+func (s *Server) stopPlugins(ctx context.Context) error {
+	var errs []error
+	for i := len(s.startedPlugins) - 1; i >= 0; i-- {
+		plugin := s.startedPlugins[i]
+		if stopper, ok := plugin.(PluginStopper); ok {
+			if err := stopper.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("stop plugin %s: %w", pluginName(plugin), err))
+			}
+		}
+	}
+	s.startedPlugins = nil
+	return errors.Join(errs...)
+}
+
+func pluginName(plugin Plugin) string {
+	return reflect.TypeOf(plugin).String()
+}
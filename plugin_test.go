@@ -0,0 +1,115 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// versionPlugin adds a version header, a version route and a spec extension.
+type versionPlugin struct{ version string }
+
+func (p versionPlugin) Install(s *Server) error {
+	Use(s, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Version", p.version)
+			next.ServeHTTP(w, r)
+		})
+	})
+	Get(s, "/version", func(c ContextNoBody) (string, error) { return p.version, nil })
+	s.OpenAPI.Description().Info.Extensions = map[string]any{"x-version": p.version}
+	return nil
+}
+
+// lifecyclePlugin records its lifecycle in a shared journal.
+type lifecyclePlugin struct {
+	name     string
+	journal  *[]string
+	startErr error
+}
+
+func (p lifecyclePlugin) Install(s *Server) error {
+	*p.journal = append(*p.journal, "install "+p.name)
+	return nil
+}
+
+func (p lifecyclePlugin) Start(ctx context.Context) error {
+	*p.journal = append(*p.journal, "start "+p.name)
+	return p.startErr
+}
+
+func (p lifecyclePlugin) Stop(ctx context.Context) error {
+	*p.journal = append(*p.journal, "stop "+p.name)
+	return nil
+}
+
+type failingPlugin struct{}
+
+func (failingPlugin) Install(s *Server) error { return errors.New("missing configuration") }
+
+func TestWithPlugins(t *testing.T) {
+	newServer := func(t *testing.T, plugins ...Plugin) *Server {
+		t.Helper()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		return NewServer(WithoutLogger(), WithListener(listener), WithoutStartupMessages(), WithPlugins(plugins...))
+	}
+
+	t.Run("installs routes, middlewares and spec edits", func(t *testing.T) {
+		s := newServer(t, versionPlugin{version: "1.2.0"})
+		Get(s, "/pets", testController)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "1.2.0", w.Body.String())
+
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets", nil))
+		require.Equal(t, "1.2.0", w.Header().Get("X-Version"))
+
+		require.Equal(t, map[string]any{"x-version": "1.2.0"}, s.OpenAPI.Description().Info.Extensions)
+		require.NotNil(t, s.OpenAPI.Description().Paths.Value("/version"))
+	})
+
+	t.Run("starts and stops the plugins", func(t *testing.T) {
+		journal := []string{}
+		s := newServer(t,
+			lifecyclePlugin{name: "db", journal: &journal},
+			versionPlugin{version: "1.2.0"},
+			lifecyclePlugin{name: "cache", journal: &journal},
+		)
+		require.Equal(t, []string{"install db", "install cache"}, journal)
+
+		require.NoError(t, s.setup())
+		require.Equal(t, []string{"install db", "install cache", "start db", "start cache"}, journal)
+
+		require.NoError(t, s.Shutdown(context.Background()))
+		require.Equal(t, []string{"install db", "install cache", "start db", "start cache", "stop cache", "stop db"}, journal)
+	})
+
+	t.Run("a plugin failing to start stops the started ones", func(t *testing.T) {
+		journal := []string{}
+		s := newServer(t,
+			lifecyclePlugin{name: "db", journal: &journal},
+			lifecyclePlugin{name: "broker", journal: &journal, startErr: errors.New("connection refused")},
+			lifecyclePlugin{name: "cache", journal: &journal},
+		)
+
+		err := s.setup()
+		require.ErrorContains(t, err, "start plugin fuego.lifecyclePlugin: connection refused")
+		require.Equal(t, []string{"install db", "install broker", "install cache", "start db", "start broker", "stop db"}, journal)
+	})
+
+	t.Run("installation errors are returned on run", func(t *testing.T) {
+		s := newServer(t, failingPlugin{})
+		require.EqualError(t, s.setup(), "install plugin fuego.failingPlugin: missing configuration")
+	})
+}
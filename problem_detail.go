@@ -0,0 +1,155 @@
+package fuego
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+)
+
+// ProblemDetail is an error sent as a RFC 7807 Problem Details object by [SendProblemDetails].
+//
+//	return fuego.ProblemDetail{
+//		Type:       "https://example.com/probs/out-of-credit",
+//		Title:      "You do not have enough credit.",
+//		Status:     http.StatusForbidden,
+//		Detail:     "Your current balance is 30, but that costs 50.",
+//		Extensions: map[string]any{"balance": 30},
+//	}
+type ProblemDetail struct {
+	// Developer readable error message. Not sent to the client.
+	Err error
+	// URI identifying the problem type. Defaults to "about:blank".
+	Type string
+	// Short summary of the problem type. Defaults to the text of the status code.
+	Title string
+	// HTTP status code. Defaults to 500.
+	Status int
+	// Explanation of this occurrence of the problem.
+	Detail string
+	// URI identifying this occurrence of the problem. Defaults to the path of the request.
+	Instance string
+	// Additional members of the problem object, like "balance" or "errors".
+	Extensions map[string]any
+}
+
+var (
+	_ ErrorWithStatus = ProblemDetail{}
+	_ ErrorWithDetail = ProblemDetail{}
+)
+
+func (p ProblemDetail) Error() string {
+	title := p.Title
+	if title == "" {
+		title = http.StatusText(p.StatusCode())
+	}
+	msg := fmt.Sprintf("%d %s", p.StatusCode(), title)
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	return msg
+}
+
+func (p ProblemDetail) StatusCode() int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+func (p ProblemDetail) DetailMsg() string { return p.Detail }
+
+func (p ProblemDetail) Unwrap() error { return p.Err }
+
+// MarshalJSON writes the extensions as members of the problem object.
+// They cannot override the standard members.
+func (p ProblemDetail) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	maps.Copy(members, p.Extensions)
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = "about:blank"
+	}
+	members["title"] = p.Title
+	if p.Title == "" {
+		members["title"] = http.StatusText(p.StatusCode())
+	}
+	members["status"] = p.StatusCode()
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	} else {
+		delete(members, "detail")
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	} else {
+		delete(members, "instance")
+	}
+	return json.Marshal(members)
+}
+
+// SendProblemDetails is an [ErrorSender] sending all the errors as RFC 7807 Problem Details,
+// with the application/problem+json content type, whatever the Accept header of the request.
+// The [HTTPError] and the errors implementing [ErrorWithStatus] or [ErrorWithDetail] are converted to a [ProblemDetail];
+// their errors are sent as the "errors" extension.
+//
+//	s := fuego.NewServer(fuego.WithErrorSerializer(fuego.SendProblemDetails))
+func SendProblemDetails(w http.ResponseWriter, r *http.Request, err error) {
+	problem := toProblemDetail(err)
+	if problem.Instance == "" && r != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.StatusCode())
+	if encodeErr := json.NewEncoder(w).Encode(problem); encodeErr != nil {
+		slog.Error("Cannot serialize the problem details", "error", encodeErr)
+	}
+}
+
+// toProblemDetail converts the error to a [ProblemDetail].
+// The outermost [ProblemDetail] or [HTTPError] of the chain is used,
+// so that the errors hidden by [WithErrorObfuscation] keep their details hidden.
+func toProblemDetail(err error) ProblemDetail {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch e := e.(type) {
+		case ProblemDetail:
+			return e
+		case HTTPError:
+			problem := ProblemDetail{
+				Err:      e.Err,
+				Type:     e.Type,
+				Title:    e.Title,
+				Status:   e.StatusCode(),
+				Detail:   e.Detail,
+				Instance: e.Instance,
+			}
+			if len(e.Errors) > 0 {
+				problem.Extensions = map[string]any{"errors": e.Errors}
+			}
+			// The status and detail of the wrapping errors, like BadRequestError, are not set on the HTTPError itself
+			var errorStatus ErrorWithStatus
+			if e.Status == 0 && errors.As(err, &errorStatus) {
+				problem.Status = errorStatus.StatusCode()
+			}
+			var errorDetail ErrorWithDetail
+			if e.Detail == "" && errors.As(err, &errorDetail) {
+				problem.Detail = errorDetail.DetailMsg()
+			}
+			return problem
+		}
+	}
+
+	problem := ProblemDetail{Err: err, Status: http.StatusInternalServerError}
+	var errorStatus ErrorWithStatus
+	if errors.As(err, &errorStatus) {
+		problem.Status = errorStatus.StatusCode()
+	}
+	var errorDetail ErrorWithDetail
+	if errors.As(err, &errorDetail) {
+		problem.Detail = errorDetail.DetailMsg()
+	}
+	return problem
+}
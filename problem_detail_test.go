@@ -0,0 +1,105 @@
+package fuego
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetail(t *testing.T) {
+	problem := ProblemDetail{
+		Err:        errors.New("balance too low"),
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     http.StatusForbidden,
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Extensions: map[string]any{"balance": 30, "status": 200},
+	}
+	require.Equal(t, "403 You do not have enough credit.: Your current balance is 30, but that costs 50.", problem.Error())
+	require.Equal(t, "balance too low", errors.Unwrap(problem).Error())
+
+	t.Run("extensions are members of the object", func(t *testing.T) {
+		data, err := problem.MarshalJSON()
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"type": "https://example.com/probs/out-of-credit",
+			"title": "You do not have enough credit.",
+			"status": 403,
+			"detail": "Your current balance is 30, but that costs 50.",
+			"balance": 30
+		}`, string(data))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		data, err := ProblemDetail{}.MarshalJSON()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500}`, string(data))
+	})
+}
+
+func TestSendProblemDetails(t *testing.T) {
+	send := func(err error) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/pets/42", nil)
+		r.Header.Set("Accept", "application/xml")
+		SendProblemDetails(w, r, err)
+		return w
+	}
+
+	t.Run("problem detail", func(t *testing.T) {
+		w := send(fmt.Errorf("cannot buy: %w", ProblemDetail{Title: "Out of credit", Status: http.StatusForbidden, Instance: "/orders/1"}))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"type":"about:blank","title":"Out of credit","status":403,"instance":"/orders/1"}`, w.Body.String())
+	})
+
+	t.Run("HTTP errors", func(t *testing.T) {
+		w := send(BadRequestError{Title: "Invalid pet", Errors: []ErrorItem{{Name: "name", Reason: "required"}}})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Invalid pet",
+			"status": 400,
+			"instance": "/pets/42",
+			"errors": [{"name": "name", "reason": "required"}]
+		}`, w.Body.String())
+	})
+
+	t.Run("errors with status and detail", func(t *testing.T) {
+		w := send(myError{status: http.StatusTeapot, detail: "short and stout"})
+		require.Equal(t, http.StatusTeapot, w.Code)
+		require.JSONEq(t, `{"type":"about:blank","title":"I'm a teapot","status":418,"detail":"short and stout","instance":"/pets/42"}`, w.Body.String())
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		w := send(errors.New("database is down"))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/pets/42"}`, w.Body.String())
+	})
+
+	t.Run("selected with WithErrorSerializer", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithErrorSerializer(SendProblemDetails), WithEngineOptions(WithErrorObfuscation(ErrorObfuscationInternal)))
+		Get(s, "/credit", func(c ContextNoBody) (any, error) {
+			return nil, ProblemDetail{Type: "https://example.com/probs/out-of-credit", Status: http.StatusForbidden, Extensions: map[string]any{"balance": 30}}
+		})
+		Get(s, "/internal", func(c ContextNoBody) (any, error) {
+			return nil, ProblemDetail{Status: http.StatusInternalServerError, Detail: "SELECT * FROM pets", Extensions: map[string]any{"host": "db-1"}}
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/credit", nil))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.JSONEq(t, `{"type":"https://example.com/probs/out-of-credit","title":"Forbidden","status":403,"instance":"/credit","balance":30}`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/internal", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotContains(t, w.Body.String(), "SELECT")
+		require.NotContains(t, w.Body.String(), "db-1")
+		require.Contains(t, w.Body.String(), "Error ID: ")
+	})
+}
@@ -0,0 +1,71 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// WithQueryTimeout sets the timeout of the contexts returned by [QueryContext], for the database queries
+// and the other calls made by the controllers. Choose it shorter than the write timeout of the server,
+// so that a slow query fails while the client still waits for the response.
+//
+// The errors wrapping [context.DeadlineExceeded] returned by the controllers, and that have no status,
+// are then sent as a 504 Gateway Timeout instead of a 500 Internal Server Error.
+//
+//	s := fuego.NewServer(
+//		fuego.WithEngineOptions(
+//			fuego.WithQueryTimeout(5*time.Second),
+//		),
+//	)
+func WithQueryTimeout(timeout time.Duration) func(*Engine) {
+	return func(e *Engine) { e.queryTimeout = timeout }
+}
+
+// QueryTimeout returns the timeout set with [WithQueryTimeout].
+// It is used by the adaptors to initialize the context.
+func (e *Engine) QueryTimeout() time.Duration {
+	return e.queryTimeout
+}
+
+// queryTimeoutProvider is implemented by the contexts giving access to the timeout of [WithQueryTimeout].
+type queryTimeoutProvider interface {
+	GetQueryTimeout() time.Duration
+}
+
+// QueryContext returns a context for a database query, or another call of the repositories,
+// canceled after the timeout of [WithQueryTimeout] or when the request ends.
+// Without query timeout, it is only canceled when the request ends.
+//
+//	func (h *UserResources) GetUser(c fuego.ContextNoBody) (*User, error) {
+//		ctx, cancel := fuego.QueryContext(c)
+//		defer cancel()
+//		return h.Users.GetUserByID(ctx, c.PathParam("id"))
+//	}
+func QueryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if provider, ok := ctx.(queryTimeoutProvider); ok && provider.GetQueryTimeout() > 0 {
+		return context.WithTimeout(ctx, provider.GetQueryTimeout())
+	}
+	return context.WithCancel(ctx)
+}
+
+// queryTimeoutError translates the errors of the queries that exceeded the timeout of [WithQueryTimeout]
+// into a 504 Gateway Timeout. The errors with a status are kept as is.
+func (e *Engine) queryTimeoutError(err error) error {
+	var errorStatus ErrorWithStatus
+	if e.queryTimeout == 0 || !errors.Is(err, context.DeadlineExceeded) || errors.As(err, &errorStatus) {
+		return err
+	}
+	return gatewayTimeoutError(err)
+}
+
+// gatewayTimeoutError is the 504 Gateway Timeout error of the requests that took too long.
+func gatewayTimeoutError(err error) HTTPError {
+	return HTTPError{
+		Err:    err,
+		Title:  http.StatusText(http.StatusGatewayTimeout),
+		Status: http.StatusGatewayTimeout,
+		Detail: "the request took too long to complete",
+	}
+}
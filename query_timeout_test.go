@@ -0,0 +1,93 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowQuery waits for the end of the context, like a query on an overloaded database.
+func slowQuery(ctx context.Context) error {
+	<-ctx.Done()
+	return fmt.Errorf("query users: %w", ctx.Err())
+}
+
+func TestWithQueryTimeout(t *testing.T) {
+	t.Run("query contexts have the timeout", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithQueryTimeout(10 * time.Millisecond)))
+		Get(s, "/users", func(c ContextNoBody) ([]MyStruct, error) {
+			ctx, cancel := QueryContext(c)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			require.WithinDuration(t, time.Now().Add(10*time.Millisecond), deadline, 10*time.Millisecond)
+			return nil, slowQuery(ctx)
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		require.Equal(t, http.StatusGatewayTimeout, w.Code)
+		require.Contains(t, w.Body.String(), "Gateway Timeout")
+	})
+
+	t.Run("errors with a status are kept", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithQueryTimeout(time.Millisecond)))
+		Get(s, "/users", func(c ContextNoBody) ([]MyStruct, error) {
+			ctx, cancel := QueryContext(c)
+			defer cancel()
+			return nil, NotFoundError{Err: slowQuery(ctx)}
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("without query timeout, deadlines are internal errors", func(t *testing.T) {
+		s := NewServer()
+		Get(s, "/users", func(c ContextNoBody) ([]MyStruct, error) {
+			ctx, cancel := QueryContext(c)
+			defer cancel()
+			_, ok := ctx.Deadline()
+			require.False(t, ok)
+			return nil, context.DeadlineExceeded
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestQueryContext(t *testing.T) {
+	t.Run("canceled with the request", func(t *testing.T) {
+		parent, cancelRequest := context.WithCancel(context.Background())
+		ctx, cancel := QueryContext(parent)
+		defer cancel()
+
+		cancelRequest()
+		<-ctx.Done()
+		require.True(t, errors.Is(ctx.Err(), context.Canceled))
+	})
+
+	t.Run("does not extend the deadline of the request", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		parent, cancelRequest := context.WithTimeout(r.Context(), time.Millisecond)
+		defer cancelRequest()
+		c := NewNetHTTPContext[any](BaseRoute{}, httptest.NewRecorder(), r.WithContext(parent), readOptions{})
+		c.QueryTimeout = time.Hour
+
+		ctx, cancel := QueryContext(c)
+		defer cancel()
+		parentDeadline, _ := parent.Deadline()
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.Equal(t, parentDeadline, deadline)
+	})
+}
@@ -0,0 +1,260 @@
+// Package radix provides a router for applications with many routes, to replace [http.ServeMux]
+// with [github.com/go-fuego/fuego.WithRouter].
+//
+// Routes are stored in a tree of path segments: a request is matched by walking the tree segment by segment,
+// whatever the number of routes, and registering a route only checks the routes of its own path,
+// instead of looking for conflicts with all the registered patterns.
+//
+// It accepts the patterns of [http.ServeMux], except host patterns:
+//   - "/pets/{id}" matches one segment, available with [http.Request.PathValue],
+//   - "/files/{path...}" matches the remaining segments,
+//   - "/static/" matches all the paths starting with /static/, and "/static/{$}" only /static/,
+//   - "GET /pets" matches GET and HEAD requests, "/pets" all methods.
+//
+// Static segments have precedence over parameters, and parameters over the remaining segments.
+// Unlike [http.ServeMux], paths are not cleaned: /a/../b does not match /b.
+package radix
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Router is a router storing its routes in a tree of path segments.
+// It implements [github.com/go-fuego/fuego.Router].
+type Router struct {
+	root node
+}
+
+// New returns an empty router.
+func New() *Router {
+	return &Router{}
+}
+
+type node struct {
+	static map[string]*node
+	param  *node
+	// Routes matching the remaining segments, like "/files/{path...}".
+	wildcard routes
+	// Routes ending with a slash, matching all the paths below, like "/static/".
+	subtree routes
+	// Routes ending at this node.
+	exact routes
+}
+
+// routes are the routes of a node, by method. The empty method matches all methods.
+type routes map[string]*route
+
+type route struct {
+	pattern string
+	handler http.Handler
+	// Names of the path parameters, in the order of the segments.
+	params []string
+}
+
+// Handle registers the handler for the pattern.
+// It panics if the pattern is invalid or already registered.
+func (rt *Router) Handle(pattern string, handler http.Handler) {
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	path = strings.TrimLeft(path, " ")
+	if !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("radix: invalid pattern %q: host patterns are not supported", pattern))
+	}
+
+	r := &route{pattern: pattern, handler: handler}
+	n := &rt.root
+	segments := strings.Split(path[1:], "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch {
+		case last && segment == "":
+			n.subtree = n.subtree.add(method, r)
+			return
+		case segment == "{$}":
+			if !last {
+				panic(fmt.Sprintf("radix: invalid pattern %q: {$} must be at the end", pattern))
+			}
+			n = n.staticChild("")
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "...}"):
+			if !last {
+				panic(fmt.Sprintf("radix: invalid pattern %q: %s must be at the end", pattern, segment))
+			}
+			r.params = append(r.params, strings.TrimSuffix(segment[1:], "...}"))
+			n.wildcard = n.wildcard.add(method, r)
+			return
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			r.params = append(r.params, segment[1:len(segment)-1])
+			if n.param == nil {
+				n.param = &node{}
+			}
+			n = n.param
+		default:
+			n = n.staticChild(segment)
+		}
+	}
+	n.exact = n.exact.add(method, r)
+}
+
+func (n *node) staticChild(segment string) *node {
+	if n.static == nil {
+		n.static = make(map[string]*node)
+	}
+	child, ok := n.static[segment]
+	if !ok {
+		child = &node{}
+		n.static[segment] = child
+	}
+	return child
+}
+
+func (rs routes) add(method string, r *route) routes {
+	if rs == nil {
+		rs = make(routes)
+	}
+	if existing, ok := rs[method]; ok {
+		panic(fmt.Sprintf("radix: pattern %q conflicts with pattern %q", r.pattern, existing.pattern))
+	}
+	rs[method] = r
+	return rs
+}
+
+// find returns the route of the method, or records the methods of the routes in allowed.
+func (rs routes) find(method string, allowed *[]string) *route {
+	if len(rs) == 0 {
+		return nil
+	}
+	if r, ok := rs[method]; ok {
+		return r
+	}
+	if r, ok := rs[http.MethodGet]; ok && method == http.MethodHead {
+		return r
+	}
+	if r, ok := rs[""]; ok {
+		return r
+	}
+	for m := range rs {
+		*allowed = append(*allowed, m)
+	}
+	return nil
+}
+
+// lookup returns the route matching the remaining path, without its leading slash,
+// and the values of its path parameters.
+func (n *node) lookup(method string, path string, values []string, allowed *[]string) (*route, []string) {
+	segment, rest, more := strings.Cut(path, "/")
+	if len(n.static) > 0 {
+		if child, ok := n.static[unescape(segment)]; ok {
+			if r, v := child.next(method, rest, more, values, allowed); r != nil {
+				return r, v
+			}
+		}
+	}
+	if n.param != nil && segment != "" {
+		if r, v := n.param.next(method, rest, more, append(values, unescape(segment)), allowed); r != nil {
+			return r, v
+		}
+	}
+	if r := n.wildcard.find(method, allowed); r != nil {
+		return r, append(values, unescape(path))
+	}
+	return n.subtree.find(method, allowed), values
+}
+
+// next returns the route matching the rest of the path, if there are more segments,
+// or the route ending at the node.
+func (n *node) next(method string, rest string, more bool, values []string, allowed *[]string) (*route, []string) {
+	if !more {
+		return n.exact.find(method, allowed), values
+	}
+	return n.lookup(method, rest, values, allowed)
+}
+
+// unescape decodes the escaped characters of a path segment.
+func unescape(segment string) string {
+	if !strings.Contains(segment, "%") {
+		return segment
+	}
+	if unescaped, err := url.PathUnescape(segment); err == nil {
+		return unescaped
+	}
+	return segment
+}
+
+// match returns the route of the request, the values of its path parameters,
+// and the allowed methods if the path matches routes of other methods only.
+func (rt *Router) match(method, path string) (*route, []string, []string) {
+	var allowed []string
+	r, values := rt.root.lookup(method, path[1:], nil, &allowed)
+	return r, values, allowed
+}
+
+// Handler returns the handler of the request and the pattern of its route.
+// If no route matches the request, the pattern is empty.
+// Like [http.ServeMux.Handler], the path parameters are not set on the request.
+func (rt *Router) Handler(r *http.Request) (http.Handler, string) {
+	matched, _, fallback := rt.route(r)
+	if matched == nil {
+		return fallback, ""
+	}
+	return matched.handler, matched.pattern
+}
+
+// ServeHTTP serves the request with the handler of its route.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	matched, values, fallback := rt.route(r)
+	if matched == nil {
+		fallback.ServeHTTP(w, r)
+		return
+	}
+
+	for i, name := range matched.params {
+		r.SetPathValue(name, values[i])
+	}
+	r.Pattern = matched.pattern
+	matched.handler.ServeHTTP(w, r)
+}
+
+// route returns the route of the request and the values of its path parameters.
+// If no route matches the request, it returns the handler of the error:
+// 405 Method Not Allowed, redirection or 404 Not Found.
+func (rt *Router) route(r *http.Request) (*route, []string, http.Handler) {
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	matched, values, allowed := rt.match(r.Method, path)
+	if matched != nil {
+		return matched, values, nil
+	}
+
+	if len(allowed) > 0 {
+		if slices.Contains(allowed, http.MethodGet) {
+			allowed = append(allowed, http.MethodHead)
+		}
+		slices.Sort(allowed)
+		allowed = slices.Compact(allowed)
+		return nil, nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		})
+	}
+
+	// Like http.ServeMux, redirects /static to /static/ if only the latter is registered
+	if !strings.HasSuffix(path, "/") {
+		if redirected, _, _ := rt.match(r.Method, path+"/"); redirected != nil {
+			target := *r.URL
+			target.Path += "/"
+			target.RawPath = ""
+			return nil, nil, http.RedirectHandler(target.String(), http.StatusMovedPermanently)
+		}
+	}
+
+	return nil, nil, http.NotFoundHandler()
+}
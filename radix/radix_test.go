@@ -0,0 +1,140 @@
+package radix
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var patterns = []string{
+	"GET /pets",
+	"POST /pets",
+	"GET /pets/{id}",
+	"DELETE /pets/{id}",
+	"GET /pets/mine",
+	"GET /pets/{id}/toys/{toy}",
+	"/owners/{id}",
+	"GET /files/{path...}",
+	"/static/",
+	"GET /exact/{$}",
+	"GET /{$}",
+}
+
+// respond writes the pattern of the route and its path parameters.
+func respond(pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s id=%s toy=%s path=%s", pattern, r.PathValue("id"), r.PathValue("toy"), r.PathValue("path"))
+	})
+}
+
+func TestRouterMatchesLikeServeMux(t *testing.T) {
+	router := New()
+	mux := http.NewServeMux()
+	for _, pattern := range patterns {
+		router.Handle(pattern, respond(pattern))
+		mux.Handle(pattern, respond(pattern))
+	}
+
+	requests := []struct{ method, path string }{
+		{http.MethodGet, "/"},
+		{http.MethodGet, "/pets"},
+		{http.MethodHead, "/pets"},
+		{http.MethodPost, "/pets"},
+		{http.MethodPut, "/pets"},
+		{http.MethodGet, "/pets/42"},
+		{http.MethodDelete, "/pets/42"},
+		{http.MethodGet, "/pets/mine"},
+		{http.MethodDelete, "/pets/mine"},
+		{http.MethodGet, "/pets/42/toys/ball"},
+		{http.MethodGet, "/pets/hello%20world"},
+		{http.MethodPatch, "/owners/7"},
+		{http.MethodGet, "/files/a/b/c.txt"},
+		{http.MethodGet, "/files/"},
+		{http.MethodGet, "/static/css/main.css"},
+		{http.MethodGet, "/static"},
+		{http.MethodGet, "/exact/"},
+		{http.MethodGet, "/exact/more"},
+		{http.MethodGet, "/unknown"},
+	}
+
+	for _, request := range requests {
+		t.Run(request.method+" "+request.path, func(t *testing.T) {
+			expected := httptest.NewRecorder()
+			mux.ServeHTTP(expected, httptest.NewRequest(request.method, request.path, nil))
+
+			actual := httptest.NewRecorder()
+			router.ServeHTTP(actual, httptest.NewRequest(request.method, request.path, nil))
+
+			// The redirection status of http.ServeMux depends on the Go version
+			if expected.Code/100 == 3 {
+				require.Equal(t, 3, actual.Code/100)
+			} else {
+				require.Equal(t, expected.Code, actual.Code)
+				require.Equal(t, expected.Body.String(), actual.Body.String())
+			}
+			require.Equal(t, expected.Header().Get("Allow"), actual.Header().Get("Allow"))
+			require.Equal(t, expected.Header().Get("Location"), actual.Header().Get("Location"))
+
+			_, expectedPattern := mux.Handler(httptest.NewRequest(request.method, request.path, nil))
+			_, actualPattern := router.Handler(httptest.NewRequest(request.method, request.path, nil))
+			if expected.Code == http.StatusOK {
+				require.Equal(t, expectedPattern, actualPattern)
+			} else {
+				require.Empty(t, actualPattern)
+			}
+		})
+	}
+}
+
+func TestRouterSetsPattern(t *testing.T) {
+	router := New()
+	router.Handle("GET /pets/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Pattern))
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets/1", nil))
+	require.Equal(t, "GET /pets/{id}", w.Body.String())
+}
+
+func TestRouterInvalidPatterns(t *testing.T) {
+	require.Panics(t, func() { New().Handle("example.com/pets", http.NotFoundHandler()) })
+	require.Panics(t, func() { New().Handle("/files/{path...}/more", http.NotFoundHandler()) })
+	require.Panics(t, func() { New().Handle("/a/{$}/b", http.NotFoundHandler()) })
+	require.Panics(t, func() {
+		router := New()
+		router.Handle("GET /pets/{id}", http.NotFoundHandler())
+		router.Handle("GET /pets/{name}", http.NotFoundHandler())
+	})
+}
+
+func BenchmarkRouter(b *testing.B) {
+	routers := []struct {
+		name   string
+		router interface {
+			http.Handler
+			Handle(string, http.Handler)
+		}
+	}{
+		{"ServeMux", http.NewServeMux()},
+		{"radix", New()},
+	}
+
+	for _, router := range routers {
+		for i := range 10_000 {
+			router.router.Handle(fmt.Sprintf("GET /resources%d/{id}/items/%d", i%100, i), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		}
+		b.Run(router.name, func(b *testing.B) {
+			r := httptest.NewRequest(http.MethodGet, "/resources42/1/items/9942", nil)
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				router.router.ServeHTTP(w, r)
+			}
+		})
+	}
+}
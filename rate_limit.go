@@ -0,0 +1,173 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when the client has consumed its budget of requests. See [WithRateLimit].
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitConfig configures the rate limiting. See [WithRateLimit].
+type RateLimitConfig struct {
+	// Budget of each client per window. Each request consumes the cost of its route, 1 by default (see [OptionCost]).
+	Limit int
+	// Duration of the window after which the budget is restored. Defaults to 1 minute.
+	Window time.Duration
+	// Identifies the client. Defaults to its IP address, see [ClientIP].
+	Key func(r *http.Request) string
+	// Stores the consumed budgets. Defaults to an in-memory store, local to the server instance.
+	Store RateLimitStore
+}
+
+// RateLimitStore stores the budgets consumed by the clients, for example in Redis to share them between instances.
+type RateLimitStore interface {
+	// Take consumes cost from the budget of the key in the current window, if enough remains.
+	// It returns whether the cost was consumed, the remaining budget and the end of the window.
+	Take(ctx context.Context, key string, cost, limit int, window time.Duration) (ok bool, remaining int, reset time.Time, err error)
+}
+
+// WithRateLimit limits the number of requests of each client in a window of time.
+// The budget is consumed by the cost of each route: declare heavy endpoints like exports
+// or searches with [OptionCost] so they consume more than cheap reads.
+//
+// Responses carry the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset (in seconds) headers.
+// When the budget is exhausted, requests get a 429 Too Many Requests error with a Retry-After header,
+// documented in the OpenAPI spec. If the store fails, requests are let through.
+//
+//	s := fuego.NewServer(
+//		fuego.WithRateLimit(fuego.RateLimitConfig{Limit: 100, Window: time.Minute}),
+//	)
+//
+//	fuego.Get(s, "/reports/export", exportReports, option.Cost(20))
+func WithRateLimit(config RateLimitConfig) func(*Server) {
+	if config.Limit <= 0 {
+		panic("rate limit must be positive")
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Key == nil {
+		config.Key = ClientIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+
+	return func(s *Server) {
+		if store, ok := config.Store.(*MemoryRateLimitStore); ok {
+			store.now = s.now
+		}
+		s.rateLimit = &config
+		s.routeOptions = append(s.routeOptions,
+			OptionAddResponse(http.StatusTooManyRequests, "Too Many Requests _(rate limit exceeded)_", Response{Type: HTTPError{}}),
+		)
+	}
+}
+
+// OptionCost sets the cost of the route for the rate limiting (see [WithRateLimit]):
+// each request consumes weight units of the client budget. Defaults to 1.
+// A cost of 0 excludes the route from the rate limiting.
+//
+//	fuego.Get(s, "/search", search, option.Cost(5))
+func OptionCost(weight int) func(*BaseRoute) {
+	if weight < 0 {
+		panic("cost must not be negative")
+	}
+	return func(r *BaseRoute) {
+		r.Cost = &weight
+	}
+}
+
+// middleware consumes the cost of the route from the client budget.
+// It is mounted after the route middlewares, so the key can depend on the authenticated client.
+func (config *RateLimitConfig) middleware(cost int, now func() time.Time) func(http.Handler) http.Handler {
+	limit := strconv.Itoa(config.Limit)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, remaining, reset, err := config.Store.Take(r.Context(), config.Key(r), cost, config.Limit, config.Window)
+			if err != nil {
+				slog.Error("rate limit store failed", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resetSeconds := strconv.Itoa(int(math.Ceil(reset.Sub(now()).Seconds())))
+			w.Header().Set("RateLimit-Limit", limit)
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("RateLimit-Reset", resetSeconds)
+
+			if !ok {
+				w.Header().Set("Retry-After", resetSeconds)
+				SendError(w, r, HTTPError{
+					Title:  "Too Many Requests",
+					Status: http.StatusTooManyRequests,
+					Err:    ErrRateLimited,
+					Detail: ErrRateLimited.Error(),
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MemoryRateLimitStore is the default [RateLimitStore], keeping the budgets in memory with fixed windows.
+type MemoryRateLimitStore struct {
+	// Clock of the windows. Set to the clock of the server by [WithRateLimit], see [WithClock].
+	now func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*rateLimitWindow
+	nextSweep time.Time
+}
+
+type rateLimitWindow struct {
+	consumed int
+	reset    time.Time
+}
+
+// NewMemoryRateLimitStore returns an empty [MemoryRateLimitStore].
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{now: time.Now, windows: make(map[string]*rateLimitWindow)}
+}
+
+func (m *MemoryRateLimitStore) Take(_ context.Context, key string, cost, limit int, window time.Duration) (bool, int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now, window)
+
+	current, exists := m.windows[key]
+	if !exists || !now.Before(current.reset) {
+		current = &rateLimitWindow{reset: now.Add(window)}
+		m.windows[key] = current
+	}
+
+	if current.consumed+cost > limit {
+		return false, limit - current.consumed, current.reset, nil
+	}
+	current.consumed += cost
+	return true, limit - current.consumed, current.reset, nil
+}
+
+// sweep removes the expired windows, at most once per window.
+func (m *MemoryRateLimitStore) sweep(now time.Time, window time.Duration) {
+	if now.Before(m.nextSweep) {
+		return
+	}
+	for key, w := range m.windows {
+		if !now.Before(w.reset) {
+			delete(m.windows, key)
+		}
+	}
+	m.nextSweep = now.Add(window)
+}
@@ -0,0 +1,106 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, int, int, time.Duration) (bool, int, time.Time, error) {
+	return false, 0, time.Time{}, errors.New("store unavailable")
+}
+
+func TestWithRateLimit(t *testing.T) {
+	newServer := func(config RateLimitConfig) *Server {
+		s := NewServer(WithoutLogger(), WithRateLimit(config))
+		Get(s, "/cheap", func(c ContextNoBody) (string, error) { return "ok", nil })
+		Get(s, "/export", func(c ContextNoBody) (string, error) { return "ok", nil }, OptionCost(4))
+		Get(s, "/free", func(c ContextNoBody) (string, error) { return "ok", nil }, OptionCost(0))
+		return s
+	}
+	call := func(s *Server, path, remoteAddr string) *http.Response {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	t.Run("routes consume their cost", func(t *testing.T) {
+		s := newServer(RateLimitConfig{Limit: 6, Window: time.Hour})
+
+		res := call(s, "/cheap", "203.0.113.1:1234")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "6", res.Header.Get("RateLimit-Limit"))
+		require.Equal(t, "5", res.Header.Get("RateLimit-Remaining"))
+		require.Equal(t, "3600", res.Header.Get("RateLimit-Reset"))
+
+		res = call(s, "/export", "203.0.113.1:1234")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "1", res.Header.Get("RateLimit-Remaining"))
+
+		res = call(s, "/export", "203.0.113.1:1234")
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		require.Equal(t, "1", res.Header.Get("RateLimit-Remaining"))
+		require.Equal(t, "3600", res.Header.Get("Retry-After"))
+
+		res = call(s, "/cheap", "203.0.113.1:1234")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "0", res.Header.Get("RateLimit-Remaining"))
+
+		t.Run("free routes are not limited", func(t *testing.T) {
+			res := call(s, "/free", "203.0.113.1:1234")
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Empty(t, res.Header.Get("RateLimit-Remaining"))
+		})
+
+		t.Run("budgets are per client", func(t *testing.T) {
+			res := call(s, "/export", "203.0.113.2:1234")
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, "2", res.Header.Get("RateLimit-Remaining"))
+		})
+	})
+
+	t.Run("budget is restored after the window", func(t *testing.T) {
+		s := newServer(RateLimitConfig{Limit: 1, Window: 10 * time.Millisecond})
+
+		require.Equal(t, http.StatusOK, call(s, "/cheap", "203.0.113.1:1234").StatusCode)
+		require.Equal(t, http.StatusTooManyRequests, call(s, "/cheap", "203.0.113.1:1234").StatusCode)
+		time.Sleep(20 * time.Millisecond)
+		require.Equal(t, http.StatusOK, call(s, "/cheap", "203.0.113.1:1234").StatusCode)
+	})
+
+	t.Run("custom key", func(t *testing.T) {
+		s := newServer(RateLimitConfig{Limit: 1, Key: func(r *http.Request) string { return "everyone" }})
+
+		require.Equal(t, http.StatusOK, call(s, "/cheap", "203.0.113.1:1234").StatusCode)
+		require.Equal(t, http.StatusTooManyRequests, call(s, "/cheap", "203.0.113.2:1234").StatusCode)
+	})
+
+	t.Run("failing store lets requests through", func(t *testing.T) {
+		s := newServer(RateLimitConfig{Limit: 1, Store: failingRateLimitStore{}})
+
+		res := call(s, "/cheap", "203.0.113.1:1234")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Empty(t, res.Header.Get("RateLimit-Remaining"))
+	})
+
+	t.Run("429 is documented", func(t *testing.T) {
+		s := newServer(RateLimitConfig{Limit: 1})
+
+		operation := s.OpenAPI.Description().Paths.Find("/export").Get
+		require.NotNil(t, operation.Responses.Value("429"))
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		require.Panics(t, func() { WithRateLimit(RateLimitConfig{}) })
+		require.Panics(t, func() { OptionCost(-1) })
+	})
+}
@@ -0,0 +1,92 @@
+package fuego
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+)
+
+// WithoutPanicRecovery lets the panics propagate to the [http.Server], which logs them and closes the connection,
+// instead of answering with a 500 Internal Server Error.
+func WithoutPanicRecovery() func(*Server) {
+	return func(s *Server) { s.disablePanicRecovery = true }
+}
+
+// recoverPanics answers the panics of the handlers with a 500 Internal Server Error and logs their stack trace.
+// It wraps all the middlewares, so the panics of the controllers, the middlewares and the serializers are recovered.
+// The error carries the stack trace in the [Dev] environment, only a generic message otherwise.
+// If the response has already been partially sent, the connection is aborted with [http.ErrAbortHandler],
+// so the client does not take a truncated response for a complete one.
+func (s *Server) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &headerRecorder{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := string(debug.Stack())
+			slog.Error("panic recovered", "panic", recovered, "method", r.Method, "path", r.URL.Path, "stack", stack)
+			if recorder.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			s.sendPanicError(w, r, recovered, stack)
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// sendPanicError sends the error of a recovered panic. If the error serializer panics too, a plain text error is sent.
+func (s *Server) sendPanicError(w http.ResponseWriter, r *http.Request, recovered any, stack string) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.Error("panic while serializing the error of a panic", "panic", recovered)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}()
+
+	err := InternalServerError{
+		Title: http.StatusText(http.StatusInternalServerError),
+		Err:   fmt.Errorf("panic: %v", recovered),
+	}
+	if s.environment == Dev {
+		err.Title = "Panic"
+		err.Detail = fmt.Sprint(recovered)
+		err.Errors = []ErrorItem{{
+			Name:   "stack",
+			Reason: "stack trace of the panic",
+			More:   map[string]any{"stack": strings.Split(stack, "\n")},
+		}}
+	}
+	s.SerializeError(w, r, err)
+}
+
+// headerRecorder records whether the response headers have been sent.
+type headerRecorder struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (h *headerRecorder) WriteHeader(code int) {
+	// Informational responses like 103 Early Hints are followed by the final response.
+	if code >= http.StatusOK {
+		h.wroteHeader = true
+	}
+	h.ResponseWriter.WriteHeader(code)
+}
+
+func (h *headerRecorder) Write(b []byte) (int, error) {
+	h.wroteHeader = true
+	return h.ResponseWriter.Write(b)
+}
+
+// Unwrap gives access to the features of the underlying writer, like flushing, with [http.ResponseController].
+func (h *headerRecorder) Unwrap() http.ResponseWriter {
+	return h.ResponseWriter
+}
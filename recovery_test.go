@@ -0,0 +1,144 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type panickingOutTransformer struct{}
+
+func (panickingOutTransformer) OutTransform(context.Context) error { panic("out transformer") }
+
+func panickingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("middleware") })
+}
+
+// TestRecoverPanics injects a panic at each stage of the pipeline serving a request.
+func TestRecoverPanics(t *testing.T) {
+	ok := func(c ContextNoBody) (string, error) { return "ok", nil }
+
+	stages := []struct {
+		name     string
+		options  []func(*Server)
+		register func(s *Server)
+	}{
+		{
+			name:     "global middleware",
+			options:  []func(*Server){WithGlobalMiddlewares(panickingMiddleware)},
+			register: func(s *Server) { Get(s, "/", ok) },
+		},
+		{
+			name: "server middleware",
+			register: func(s *Server) {
+				Use(s, panickingMiddleware)
+				Get(s, "/", ok)
+			},
+		},
+		{
+			name:     "route middleware",
+			register: func(s *Server) { Get(s, "/", ok, OptionMiddleware(panickingMiddleware)) },
+		},
+		{
+			name: "controller",
+			register: func(s *Server) {
+				Get(s, "/", func(c ContextNoBody) (string, error) { panic("controller") })
+			},
+		},
+		{
+			name: "out transformer",
+			register: func(s *Server) {
+				Get(s, "/", func(c ContextNoBody) (panickingOutTransformer, error) { return panickingOutTransformer{}, nil })
+			},
+		},
+		{
+			name:     "serializer",
+			options:  []func(*Server){WithSerializer(func(http.ResponseWriter, *http.Request, any) error { panic("serializer") })},
+			register: func(s *Server) { Get(s, "/", ok) },
+		},
+		{
+			name: "standard handler",
+			register: func(s *Server) {
+				GetStd(s, "/", func(http.ResponseWriter, *http.Request) { panic("handler") })
+			},
+		},
+		{
+			name:    "not found handler",
+			options: []func(*Server){WithNotFoundHandler(func(c ContextNoBody) (any, error) { panic("not found") })},
+		},
+	}
+
+	for _, stage := range stages {
+		t.Run(stage.name, func(t *testing.T) {
+			s := NewServer(append([]func(*Server){WithoutLogger()}, stage.options...)...)
+			if stage.register != nil {
+				stage.register(s)
+			}
+
+			w := httptest.NewRecorder()
+			require.NotPanics(t, func() {
+				s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			})
+			require.Equal(t, http.StatusInternalServerError, w.Code)
+			require.Contains(t, w.Body.String(), `"title":"Internal Server Error"`)
+			require.NotContains(t, w.Body.String(), "recovery_test.go")
+		})
+	}
+
+	t.Run("error serializer", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithErrorSerializer(func(http.ResponseWriter, *http.Request, error) { panic("error serializer") }))
+		Get(s, "/", func(c ContextNoBody) (string, error) { return "", errors.New("failed") })
+
+		w := httptest.NewRecorder()
+		require.NotPanics(t, func() {
+			s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "Internal Server Error\n", w.Body.String())
+	})
+
+	t.Run("stack trace in dev", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithEnvironment(Dev), WithGlobalMiddlewares(panickingMiddleware))
+		Get(s, "/", ok)
+
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Contains(t, w.Body.String(), `"detail":"middleware"`)
+		require.Contains(t, w.Body.String(), "recovery_test.go")
+	})
+
+	t.Run("partial responses abort the connection", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		GetStd(s, "/", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"items":[`))
+			panic("handler")
+		})
+
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			s.handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+
+	t.Run("without panic recovery", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithoutPanicRecovery())
+		Get(s, "/", func(c ContextNoBody) (string, error) { panic("controller") })
+
+		require.PanicsWithValue(t, "controller", func() {
+			s.handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+
+	t.Run("without panic recovery in dev", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithEnvironment(Dev), WithoutPanicRecovery())
+		Get(s, "/", func(c ContextNoBody) (string, error) { panic("controller") })
+
+		require.PanicsWithValue(t, "controller", func() {
+			s.handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
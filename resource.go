@@ -0,0 +1,72 @@
+package fuego
+
+import (
+	"net/http"
+	"slices"
+)
+
+// ResourceHandlers are the controllers of the methods of a resource, wrapped with [Controller].
+// The methods without controller are not registered. See [Resource].
+type ResourceHandlers struct {
+	Get    ResourceController
+	Post   ResourceController
+	Put    ResourceController
+	Patch  ResourceController
+	Delete ResourceController
+}
+
+// ResourceController is a controller of one method of a resource, created with [Controller].
+type ResourceController interface {
+	register(s *Server, method, path string, options []func(*BaseRoute)) *BaseRoute
+}
+
+type resourceController[T, B any] struct {
+	controller func(ContextWithBody[B]) (T, error)
+	options    []func(*BaseRoute)
+}
+
+func (c resourceController[T, B]) register(s *Server, method, path string, options []func(*BaseRoute)) *BaseRoute {
+	route := registerFuegoController(s, method, path, c.controller, slices.Concat(options, c.options)...)
+	return &route.BaseRoute
+}
+
+// Controller wraps a controller for [Resource], with the options specific to its method.
+// They are applied after the options of the resource.
+func Controller[T, B any](controller func(ContextWithBody[B]) (T, error), options ...func(*BaseRoute)) ResourceController {
+	return resourceController[T, B]{controller: controller, options: options}
+}
+
+// Resource registers the controllers of several methods on the same path, in one declaration.
+// The options are shared by all the methods, and documented in a single path item of the OpenAPI spec.
+// It returns the registered routes, by method.
+//
+//	fuego.Resource(s, "/users/{id}", fuego.ResourceHandlers{
+//		Get:    fuego.Controller(getUser),
+//		Put:    fuego.Controller(updateUser),
+//		Delete: fuego.Controller(deleteUser, option.AddResponse(http.StatusConflict, "User has orders", fuego.Response{Type: fuego.HTTPError{}})),
+//	}, option.Tags("users"), option.Path("id", "ID of the user", param.Integer()))
+//
+// It panics if no controller is given.
+func Resource(s *Server, path string, handlers ResourceHandlers, options ...func(*BaseRoute)) map[string]*BaseRoute {
+	methods := []struct {
+		method     string
+		controller ResourceController
+	}{
+		{http.MethodGet, handlers.Get},
+		{http.MethodPost, handlers.Post},
+		{http.MethodPut, handlers.Put},
+		{http.MethodPatch, handlers.Patch},
+		{http.MethodDelete, handlers.Delete},
+	}
+
+	routes := make(map[string]*BaseRoute)
+	for _, m := range methods {
+		if m.controller != nil {
+			routes[m.method] = m.controller.register(s, m.method, path, options)
+		}
+	}
+	if len(routes) == 0 {
+		panic("resource " + path + " has no controller")
+	}
+	return routes
+}
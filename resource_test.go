@@ -0,0 +1,64 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResource(t *testing.T) {
+	s := NewServer()
+	routes := Resource(s, "/users/{id}", ResourceHandlers{
+		Get: Controller(func(c ContextNoBody) (MyStruct, error) {
+			return MyStruct{B: c.PathParam("id")}, nil
+		}),
+		Put: Controller(func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+			return c.Body()
+		}, OptionSummary("Replace a user")),
+		Delete: Controller(func(c ContextNoBody) (NoContent, error) {
+			return NoContent{}, nil
+		}),
+	}, OptionTags("users"), OptionPath("id", "ID of the user", ParamInteger()))
+
+	t.Run("registers the given methods", func(t *testing.T) {
+		require.Len(t, routes, 3)
+		require.NotContains(t, routes, http.MethodPost)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"b":"42","c":0,"d":false}`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/users/42", strings.NewReader(`{"b":"Ada","c":5}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/42", nil))
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/42", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("documents a single path item with the shared options", func(t *testing.T) {
+		pathItem := s.OpenAPI.Description().Paths.Find("/users/{id}")
+		require.NotNil(t, pathItem)
+		require.Len(t, pathItem.Operations(), 3)
+		for _, operation := range pathItem.Operations() {
+			require.Equal(t, []string{"users"}, operation.Tags)
+			require.True(t, operation.Parameters.GetByInAndName("path", "id").Schema.Value.Type.Is("integer"))
+		}
+		require.Equal(t, "Replace a user", pathItem.Put.Summary)
+	})
+
+	t.Run("panics without controller", func(t *testing.T) {
+		require.Panics(t, func() { Resource(s, "/empty", ResourceHandlers{}) })
+	})
+}
@@ -1,8 +1,11 @@
 package fuego
 
 import (
+	"context"
 	"net/http"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -18,9 +21,26 @@ type RouteWithParams[RequestParams any, ResponseBody any, RequestBody any] struc
 }
 
 func NewRoute[T, B any](method, path string, handler any, e *Engine, options ...func(*BaseRoute)) Route[T, B] {
-	return Route[T, B]{
+	route := Route[T, B]{
 		BaseRoute: NewBaseRoute(method, path, handler, e, options...),
 	}
+	if isNoContent[T]() && route.DefaultStatusCode == 0 {
+		route.DefaultStatusCode = http.StatusNoContent
+	}
+	return route
+}
+
+// NoContent is the return type of the controllers sending no response body.
+// The route responds with 204 No Content, unless another default status code is set,
+// and no response body is documented.
+//
+//	fuego.Delete(s, "/pets/{id}", func(c fuego.ContextNoBody) (fuego.NoContent, error) {
+//		return fuego.NoContent{}, store.DeletePet(c.PathParam("id"))
+//	})
+type NoContent struct{}
+
+func isNoContent[T any]() bool {
+	return reflect.TypeFor[T]() == reflect.TypeFor[NoContent]()
 }
 
 // Route is the main struct for a route in Fuego.
@@ -32,13 +52,14 @@ type Route[ResponseBody any, RequestBody any] struct {
 
 func NewBaseRoute(method, path string, handler any, e *Engine, options ...func(*BaseRoute)) BaseRoute {
 	baseRoute := BaseRoute{
-		Method:              method,
-		Path:                path,
-		Params:              make(map[string]OpenAPIParam),
-		FullName:            FuncName(handler),
-		Operation:           openapi3.NewOperation(),
-		OpenAPI:             e.OpenAPI,
-		RequestContentTypes: e.requestContentTypes,
+		Method:               method,
+		Path:                 path,
+		Params:               make(map[string]OpenAPIParam),
+		FullName:             FuncName(handler),
+		Operation:            openapi3.NewOperation(),
+		OpenAPI:              e.OpenAPI,
+		RequestContentTypes:  e.requestContentTypes,
+		ResponseContentTypes: e.responseContentTypes,
 	}
 
 	for _, o := range options {
@@ -74,23 +95,89 @@ type BaseRoute struct {
 	// Content types accepted for the request body. If nil, all content types (*/*) are accepted.
 	RequestContentTypes []string
 
+	// Content types documented for the default response. If nil, application/json and application/xml.
+	ResponseContentTypes []string
+
 	Middlewares []func(http.Handler) http.Handler
 
+	// Custom request body decoders, by media type. They take precedence over the server ones.
+	BodyDecoders map[string]BodyDecoder
+
 	// Default status code for the response
 	DefaultStatusCode int
 
+	// If true, the request body is optional: an empty body decodes to the zero value, without validation.
+	OptionalBody bool
+
+	// If set, overrides the DisallowUnknownFields option of the server for the request body.
+	// See [OptionDisallowUnknownFields] and [OptionAllowUnknownFields].
+	DisallowUnknownFields *bool
+
+	// Validation scenario of the request body. See [OptionValidationScenario].
+	ValidationScenario string
+
+	// Cost of the route for the rate limiting. If nil, 1. See [OptionCost].
+	Cost *int
+
+	// Priority of the route for the load shedding. See [OptionPriority].
+	Priority Priority
+
+	// Maximum duration of the requests of the route. See [OptionTimeout].
+	Timeout time.Duration
+
+	// Sitemap entry of the route, if it is listed in the sitemap. See [OptionIndexable].
+	Indexable *SitemapEntry
+
+	// Origins allowed to open cross-origin connections to a websocket route. See [OptionWebsocketOrigins].
+	WebsocketOrigins []string
+
 	// If true, the route will not be documented in the OpenAPI spec
 	Hidden bool
 
 	// Override the default description
 	overrideDescription bool
+
+	// Files of the multipart request body. See [OptionFile].
+	formFiles []formFile
+
+	// Called with the generated operation, before it is added to the spec. See [OptionOperation].
+	operationCallbacks []func(*openapi3.Operation)
+
+	// Verifies the signature of the requests. See [OptionVerifySignature].
+	signatureVerifier *signatureVerifier
+
+	// Begins the transaction of the controller. See [OptionTransactional].
+	beginTx func(ctx context.Context) (Tx, error)
+
+	// Chooses the variant serving each request, among the variants of the route. See [OptionVariant].
+	variantSelector VariantSelector
+	variants        []Variant
+}
+
+// rateLimitCost returns the cost of the route for the rate limiting.
+func (r *BaseRoute) rateLimitCost() int {
+	if r.Cost == nil {
+		return 1
+	}
+	return *r.Cost
 }
 
 func (r *BaseRoute) GenerateDefaultDescription() {
 	if r.overrideDescription {
 		return
 	}
-	r.Operation.Description = DefaultDescription(r.FullName, r.Middlewares) + r.Operation.Description
+	compose := ComposeDescription
+	if r.OpenAPI != nil && r.OpenAPI.composeDescription != nil {
+		compose = r.OpenAPI.composeDescription
+	}
+	r.Operation.Description = compose(r)
+}
+
+// ComposeDescription is the default composition of the description of a route in the OpenAPI spec:
+// the controller and middlewares of the route (see [DefaultDescription]), followed by the description
+// given with the options. See [WithDescriptionComposer].
+func ComposeDescription(route *BaseRoute) string {
+	return DefaultDescription(route.FullName, route.Middlewares) + route.Operation.Description
 }
 
 func (r *BaseRoute) GenerateDefaultOperationID() {
@@ -0,0 +1,146 @@
+package fuego
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// routeTable is the router of a server and of its groups. Once the server runs, the routes are added
+// and removed on a new router, swapped with the one serving the requests, so that the requests
+// in progress are not affected. See [Server.RemoveRoute].
+type routeTable struct {
+	mu sync.Mutex
+	// Router serving the requests.
+	router atomic.Value
+	// Routes registered on the router, in order, to build a new one.
+	entries []routeEntry
+	// If true, the requests are served: the router cannot be modified anymore.
+	running bool
+}
+
+type routeEntry struct {
+	pattern string
+	handler http.Handler
+}
+
+var _ Router = (*routeTable)(nil)
+
+func newRouteTable(router Router) *routeTable {
+	t := &routeTable{}
+	t.router.Store(router)
+	return t
+}
+
+func (t *routeTable) current() Router {
+	return t.router.Load().(Router)
+}
+
+func (t *routeTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.current().ServeHTTP(w, r)
+}
+
+func (t *routeTable) Handler(r *http.Request) (http.Handler, string) {
+	return t.current().Handler(r)
+}
+
+// Handle registers the handler on the router, or on a new router if the server runs.
+func (t *routeTable) Handle(pattern string, handler http.Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.running {
+		t.current().Handle(pattern, handler)
+		t.entries = append(t.entries, routeEntry{pattern: pattern, handler: handler})
+		return
+	}
+
+	entries := append(slices.Clip(t.entries), routeEntry{pattern: pattern, handler: handler})
+	if err := t.rebuild(entries); err != nil {
+		panic(err)
+	}
+}
+
+// remove removes the route of the pattern, with a new router.
+func (t *routeTable) remove(pattern string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := slices.IndexFunc(t.entries, func(e routeEntry) bool { return e.pattern == pattern })
+	if i == -1 {
+		return fmt.Errorf("no route %q", pattern)
+	}
+	return t.rebuild(slices.Delete(slices.Clone(t.entries), i, i+1))
+}
+
+// rebuild registers the entries on a new router of the type of the current one, from its zero value, and swaps them.
+func (t *routeTable) rebuild(entries []routeEntry) (err error) {
+	routerType := reflect.TypeOf(t.current())
+	if routerType.Kind() != reflect.Pointer {
+		return fmt.Errorf("router %s cannot be rebuilt: it is not a pointer", routerType)
+	}
+	router := reflect.New(routerType.Elem()).Interface().(Router)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot register the routes on a new router: %v", r)
+		}
+	}()
+	for _, e := range entries {
+		router.Handle(e.pattern, e.handler)
+	}
+
+	t.router.Store(router)
+	t.entries = entries
+	return nil
+}
+
+// serve marks the router as serving the requests: the later changes are made on new routers.
+func (t *routeTable) serve() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running = true
+}
+
+// RemoveRoute removes the route registered with the method and the path, like a route of a plugin
+// that is unloaded. The method is empty for the routes registered with [All] or [Handle].
+// The path is relative to the group, like for the registration.
+//
+// The routes can also be registered with [Get], [Post]... once the server runs.
+// In both cases, the router is rebuilt from the zero value of its type, like [http.ServeMux],
+// with all the routes, then swapped with the one serving the requests. The routes registered
+// directly on [Server.Mux] are lost. The route is removed from the OpenAPI spec.
+func (s *Server) RemoveRoute(method, path string) error {
+	path = s.basePath + path
+	pattern := path
+	if method != "" {
+		pattern = method + " " + path
+	}
+
+	s.OpenAPI.mu.Lock()
+	defer s.OpenAPI.mu.Unlock()
+
+	if err := s.routes.remove(pattern); err != nil {
+		return fmt.Errorf("remove route: %w", err)
+	}
+	if mux, ok := s.routes.current().(*http.ServeMux); ok && !s.routes.running {
+		s.Mux = mux
+	}
+
+	pathItem := s.OpenAPI.Description().Paths.Value(path)
+	if pathItem == nil {
+		return nil
+	}
+	if method == "" {
+		s.OpenAPI.Description().Paths.Delete(path)
+		return nil
+	}
+	pathItem.SetOperation(method, nil)
+	if len(pathItem.Operations()) == 0 {
+		s.OpenAPI.Description().Paths.Delete(path)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package fuego
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego/radix"
+)
+
+func TestRemoveRoute(t *testing.T) {
+	newRunningServer := func(t *testing.T, options ...func(*Server)) *Server {
+		t.Helper()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		s := NewServer(append([]func(*Server){WithoutLogger(), WithListener(listener), WithoutStartupMessages()}, options...)...)
+		Get(s, "/pets", testController)
+		Post(s, "/pets", testController)
+		require.NoError(t, s.setup())
+		return s
+	}
+
+	serve := func(s *Server, method, path string) int {
+		w := httptest.NewRecorder()
+		s.Server.Handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	t.Run("before run", func(t *testing.T) {
+		s := NewServer()
+		Get(s, "/pets", testController)
+		Get(s, "/users", testController)
+
+		require.NoError(t, s.RemoveRoute(http.MethodGet, "/pets"))
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Nil(t, s.OpenAPI.Description().Paths.Value("/pets"))
+	})
+
+	t.Run("while running", func(t *testing.T) {
+		s := newRunningServer(t)
+		require.Equal(t, http.StatusOK, serve(s, http.MethodGet, "/pets"))
+
+		require.NoError(t, s.RemoveRoute(http.MethodGet, "/pets"))
+		require.Equal(t, http.StatusMethodNotAllowed, serve(s, http.MethodGet, "/pets"))
+		require.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/pets"))
+
+		pathItem := s.OpenAPI.Description().Paths.Value("/pets")
+		require.Nil(t, pathItem.Get)
+		require.NotNil(t, pathItem.Post)
+
+		require.NoError(t, s.RemoveRoute(http.MethodPost, "/pets"))
+		require.Equal(t, http.StatusNotFound, serve(s, http.MethodPost, "/pets"))
+		require.Nil(t, s.OpenAPI.Description().Paths.Value("/pets"))
+	})
+
+	t.Run("registration while running", func(t *testing.T) {
+		s := newRunningServer(t)
+		plugins := Group(s, "/plugins/weather")
+		Get(plugins, "/forecast", testController)
+		require.Equal(t, http.StatusOK, serve(s, http.MethodGet, "/plugins/weather/forecast"))
+		require.NotNil(t, s.OpenAPI.Description().Paths.Value("/plugins/weather/forecast"))
+
+		require.NoError(t, plugins.RemoveRoute(http.MethodGet, "/forecast"))
+		require.Equal(t, http.StatusNotFound, serve(s, http.MethodGet, "/plugins/weather/forecast"))
+
+		// The route can be registered again
+		Get(plugins, "/forecast", testController)
+		require.Equal(t, http.StatusOK, serve(s, http.MethodGet, "/plugins/weather/forecast"))
+	})
+
+	t.Run("with another router", func(t *testing.T) {
+		s := newRunningServer(t, WithRouter(radix.New()))
+		require.NoError(t, s.RemoveRoute(http.MethodGet, "/pets"))
+		require.Equal(t, http.StatusMethodNotAllowed, serve(s, http.MethodGet, "/pets"))
+		require.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/pets"))
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		s := newRunningServer(t)
+		require.ErrorContains(t, s.RemoveRoute(http.MethodDelete, "/pets"), `no route "DELETE /pets"`)
+	})
+
+	t.Run("spec served during the changes", func(t *testing.T) {
+		s := newRunningServer(t)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				require.Equal(t, http.StatusOK, serve(s, http.MethodGet, s.OpenAPIConfig.SpecURL))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				Delete(s, "/pets/{id}", testController)
+				require.NoError(t, s.RemoveRoute(http.MethodDelete, "/pets/{id}"))
+			}
+		}()
+		wg.Wait()
+	})
+}
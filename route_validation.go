@@ -0,0 +1,187 @@
+package fuego
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RouteIssue is a misconfiguration found in a route declaration.
+type RouteIssue struct {
+	Method  string
+	Path    string
+	Message string
+}
+
+// RouteValidationError lists all the misconfigurations found by [Engine.ValidateRoutes].
+type RouteValidationError struct {
+	Issues []RouteIssue
+}
+
+func (e RouteValidationError) Error() string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%d invalid route declarations:", len(e.Issues))
+	for _, issue := range e.Issues {
+		fmt.Fprintf(&report, "\n  - %s %s: %s", issue.Method, issue.Path, issue.Message)
+	}
+	return report.String()
+}
+
+// ValidateRoutes checks the declarations of all the documented routes:
+//   - the path parameters used in the path are declared, and the declared ones are in the path
+//   - the security schemes used by the routes exist
+//   - the defaults, examples and enum values of the parameters match their type
+//   - the defaults and examples of the parameters are part of their enum
+//
+// It returns a [RouteValidationError] listing all the issues found, or nil.
+// [Server.Run] calls it before accepting requests: the issues are logged,
+// or returned if [WithStrictRouteValidation] is used.
+func (e *Engine) ValidateRoutes() error {
+	description := e.OpenAPI.Description()
+	var issues []RouteIssue
+
+	for _, requirement := range description.Security {
+		for _, message := range checkSecurityRequirement(description, requirement) {
+			issues = append(issues, RouteIssue{Method: "*", Path: "*", Message: message})
+		}
+	}
+
+	paths := description.Paths.Map()
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		operations := paths[path].Operations()
+		for _, method := range slices.Sorted(maps.Keys(operations)) {
+			for _, message := range checkOperation(description, path, operations[method]) {
+				issues = append(issues, RouteIssue{Method: method, Path: path, Message: message})
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return RouteValidationError{Issues: issues}
+	}
+	return nil
+}
+
+func checkOperation(description *openapi3.T, path string, operation *openapi3.Operation) []string {
+	var messages []string
+
+	pathParams := parsePathParams(path)
+	for _, name := range pathParams {
+		if operation.Parameters.GetByInAndName("path", name) == nil {
+			messages = append(messages, fmt.Sprintf("path parameter '%s' is not declared", name))
+		}
+	}
+
+	for _, parameterRef := range operation.Parameters {
+		param := parameterRef.Value
+		if param == nil {
+			continue
+		}
+		if param.In == "path" && !slices.Contains(pathParams, param.Name) {
+			messages = append(messages, fmt.Sprintf("path parameter '%s' is not in the path", param.Name))
+		}
+		if param.Schema != nil && param.Schema.Value != nil {
+			messages = append(messages, checkParamValues(param, param.Schema.Value)...)
+		}
+	}
+
+	if operation.Security != nil {
+		for _, requirement := range *operation.Security {
+			messages = append(messages, checkSecurityRequirement(description, requirement)...)
+		}
+	}
+
+	return messages
+}
+
+func checkSecurityRequirement(description *openapi3.T, requirement openapi3.SecurityRequirement) []string {
+	var messages []string
+	for _, name := range slices.Sorted(maps.Keys(requirement)) {
+		if description.Components == nil || description.Components.SecuritySchemes[name] == nil {
+			messages = append(messages, fmt.Sprintf("security scheme '%s' is not defined in components", name))
+		}
+	}
+	return messages
+}
+
+func checkParamValues(param *openapi3.Parameter, schema *openapi3.Schema) []string {
+	var messages []string
+
+	for _, value := range schema.Enum {
+		if !matchesSchemaType(schema, value) {
+			messages = append(messages, fmt.Sprintf("enum value %v of parameter '%s' is not of type %s", value, param.Name, schemaType(schema)))
+		}
+	}
+
+	check := func(kind string, value any) {
+		if !matchesSchemaType(schema, value) {
+			messages = append(messages, fmt.Sprintf("%s %v of parameter '%s' is not of type %s", kind, value, param.Name, schemaType(schema)))
+		} else if value != nil && len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+			messages = append(messages, fmt.Sprintf("%s %v of parameter '%s' is not one of %v", kind, value, param.Name, schema.Enum))
+		}
+	}
+
+	if schema.Default != nil {
+		check("default value", schema.Default)
+	}
+	if param.Example != nil {
+		check("example", param.Example)
+	}
+	for _, name := range slices.Sorted(maps.Keys(param.Examples)) {
+		example := param.Examples[name]
+		if example != nil && example.Value != nil && example.Value.Value != nil {
+			check("example '"+name+"'", example.Value.Value)
+		}
+	}
+
+	return messages
+}
+
+func schemaType(schema *openapi3.Schema) string {
+	if schema.Type == nil {
+		return "any"
+	}
+	return strings.Join(*schema.Type, " or ")
+}
+
+func matchesSchemaType(schema *openapi3.Schema, value any) bool {
+	if value == nil {
+		return schema.Nullable
+	}
+	if schema.Type == nil || len(*schema.Type) == 0 {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case schema.Type.Is("integer"):
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return true
+		case reflect.Float32, reflect.Float64:
+			return v.Float() == float64(int64(v.Float()))
+		}
+		return false
+	case schema.Type.Is("number"):
+		return v.CanInt() || v.CanUint() || v.CanFloat()
+	case schema.Type.Is("boolean"):
+		return v.Kind() == reflect.Bool
+	case schema.Type.Is("string"):
+		return v.Kind() == reflect.String
+	case schema.Type.Is("array"):
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	}
+	return true
+}
+
+// inEnum compares the values by their representation, so that 1 and int64(1) are equal.
+func inEnum(enum []any, value any) bool {
+	return slices.ContainsFunc(enum, func(allowed any) bool {
+		return fmt.Sprint(allowed) == fmt.Sprint(value)
+	})
+}
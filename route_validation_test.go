@@ -0,0 +1,61 @@
+package fuego
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRoutes(t *testing.T) {
+	t.Run("valid routes", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		Get(s, "/pets/{id}", testController,
+			OptionQuery("sort", "Sort order", ParamEnum("asc", "desc"), ParamDefault("asc"), ParamExample("descending", "desc")),
+			OptionQueryInt("per_page", "Page size", ParamEnum(10, 20, 50), ParamDefault(20)),
+		)
+
+		require.NoError(t, s.ValidateRoutes())
+	})
+
+	t.Run("all the issues are reported", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		Get(s, "/pets", testController,
+			OptionQuery("sort", "Sort order", ParamEnum("asc", "desc"), ParamDefault("random"), ParamExample("by name", "name")),
+			OptionQueryInt("per_page", "Page size", ParamEnum(10, "twenty")),
+		)
+		route := Get(s, "/admin", testController)
+		route.Operation.Security = &openapi3.SecurityRequirements{{"oauth": []string{}}}
+		s.OpenAPI.Description().AddOperation("/users/{id}", "DELETE", openapi3.NewOperation())
+
+		err := s.ValidateRoutes()
+		require.Error(t, err)
+
+		var validationErr RouteValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, []RouteIssue{
+			{Method: "GET", Path: "/admin", Message: "security scheme 'oauth' is not defined in components"},
+			{Method: "GET", Path: "/pets", Message: "default value random of parameter 'sort' is not one of [asc desc]"},
+			{Method: "GET", Path: "/pets", Message: "example 'by name' name of parameter 'sort' is not one of [asc desc]"},
+			{Method: "GET", Path: "/pets", Message: "enum value twenty of parameter 'per_page' is not of type integer"},
+			{Method: "DELETE", Path: "/users/{id}", Message: "path parameter 'id' is not declared"},
+		}, validationErr.Issues)
+		require.Contains(t, err.Error(), "5 invalid route declarations:\n  - GET /admin: security scheme 'oauth' is not defined in components")
+	})
+
+	t.Run("global security requirement", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		s.OpenAPI.Description().Security = openapi3.SecurityRequirements{{"bearerAuth": []string{}}}
+
+		require.EqualError(t, s.ValidateRoutes(), "1 invalid route declarations:\n  - * *: security scheme 'bearerAuth' is not defined in components")
+	})
+
+	t.Run("strict validation prevents the server from starting", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithStrictRouteValidation(), WithAddr("localhost:0"))
+		Get(s, "/pets", testController, OptionQueryBool("full", "Full details", ParamEnum(true), ParamDefault(false)))
+
+		err := s.Run()
+		require.ErrorAs(t, err, &RouteValidationError{})
+		require.Nil(t, s.listener, "the server did not listen")
+	})
+}
@@ -0,0 +1,30 @@
+package fuego
+
+import "net/http"
+
+// Router routes the requests to the handlers of the server. [http.ServeMux] is the default router.
+//
+// Routes are registered with the patterns of [http.ServeMux], like "GET /pets/{id}" or "/static/{path...}".
+// The router must set the path parameters of the matched route with [http.Request.SetPathValue],
+// and should set [http.Request.Pattern].
+type Router interface {
+	http.Handler
+	// Handle registers the handler for the pattern.
+	Handle(pattern string, handler http.Handler)
+	// Handler returns the handler of the request and the pattern of its route.
+	// If no route matches the request, the pattern is empty.
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+var _ Router = (*http.ServeMux)(nil)
+
+// WithRouter replaces [http.ServeMux] with another router, for example one that performs better
+// with tens of thousands of routes, like the router of the [github.com/go-fuego/fuego/radix] package.
+// Routes are still declared with [Get], [Post]... and documented in the OpenAPI spec.
+//
+//	s := fuego.NewServer(
+//		fuego.WithRouter(radix.New()),
+//	)
+func WithRouter(router Router) func(*Server) {
+	return func(s *Server) { s.router = router }
+}
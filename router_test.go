@@ -0,0 +1,64 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego/radix"
+)
+
+func TestWithRouter(t *testing.T) {
+	s := NewServer(
+		WithoutLogger(),
+		WithRouter(radix.New()),
+		WithNotFoundHandler(NotFoundController),
+	)
+	Get(s, "/pets/{id}", func(c ContextNoBody) (string, error) {
+		return "pet " + c.PathParam("id"), nil
+	})
+	Post(s, "/pets", func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+		return c.Body()
+	})
+	s.Engine.RegisterOpenAPIRoutes(s)
+
+	t.Run("routes are served by the router", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets/42", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "pet 42", w.Body.String())
+	})
+
+	t.Run("body is deserialized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"b":"b","c":3}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.handler().ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"b":"b","c":3,"d":false}`, w.Body.String())
+	})
+
+	t.Run("not found handler is used", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "application/problem+json", w.Result().Header.Get("Content-Type"))
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/pets/42", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		require.Equal(t, "GET, HEAD", w.Result().Header.Get("Allow"))
+	})
+
+	t.Run("spec is served", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/openapi.json", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"/pets/{id}"`)
+	})
+}
@@ -0,0 +1,253 @@
+package fuego
+
+import (
+	"html/template"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SanitizePolicy describes the HTML allowed in user-generated content.
+// Elements that are not allowed are removed but their text is kept,
+// except for elements like <script> or <style> that are removed with their content.
+// Comments are removed and text is always escaped.
+type SanitizePolicy struct {
+	// AllowedTags maps the allowed elements to their allowed attributes.
+	AllowedTags map[string][]string
+	// AllowedURLSchemes are the schemes allowed in the href, src and cite attributes. Relative URLs are always allowed.
+	AllowedURLSchemes []string
+}
+
+// DefaultSanitizePolicy allows the formatting elements commonly produced by rich text editors and Markdown,
+// links and images with http, https and mailto URLs.
+var DefaultSanitizePolicy = SanitizePolicy{
+	AllowedTags: map[string][]string{
+		"a":          {"href", "title"},
+		"abbr":       {"title"},
+		"b":          nil,
+		"blockquote": {"cite"},
+		"br":         nil,
+		"code":       nil,
+		"dd":         nil,
+		"del":        nil,
+		"dl":         nil,
+		"dt":         nil,
+		"em":         nil,
+		"h1":         nil,
+		"h2":         nil,
+		"h3":         nil,
+		"h4":         nil,
+		"h5":         nil,
+		"h6":         nil,
+		"hr":         nil,
+		"i":          nil,
+		"img":        {"src", "alt", "title", "width", "height"},
+		"ins":        nil,
+		"li":         nil,
+		"mark":       nil,
+		"ol":         {"start"},
+		"p":          nil,
+		"pre":        nil,
+		"s":          nil,
+		"small":      nil,
+		"span":       nil,
+		"strong":     nil,
+		"sub":        nil,
+		"sup":        nil,
+		"table":      nil,
+		"tbody":      nil,
+		"td":         nil,
+		"th":         nil,
+		"thead":      nil,
+		"tr":         nil,
+		"u":          nil,
+		"ul":         nil,
+	},
+	AllowedURLSchemes: []string{"http", "https", "mailto"},
+}
+
+// Elements removed with their content.
+var unsafeElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "noembed": true, "noframes": true,
+	"noscript": true, "plaintext": true, "template": true, "textarea": true, "title": true, "xmp": true,
+}
+
+// Elements without closing tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// Attributes containing URLs.
+var urlAttributes = map[string]bool{"href": true, "src": true, "cite": true}
+
+// SafeHTML sanitizes user-generated HTML with the [DefaultSanitizePolicy],
+// so it can be rendered in a template without XSS risk.
+// It is available in the templates as the safeHTML function:
+//
+//	<div class="bio">{{ safeHTML .User.Bio }}</div>
+func SafeHTML(userInput string) template.HTML {
+	//nolint:gosec // G203 // the input is sanitized
+	return template.HTML(DefaultSanitizePolicy.Sanitize(userInput))
+}
+
+// Sanitize removes from the input the elements, attributes and URLs not allowed by the policy.
+// Unclosed elements are closed at the end of the output.
+func (p SanitizePolicy) Sanitize(input string) string {
+	var out strings.Builder
+	var open []string // allowed elements not closed yet
+	skipping := ""    // unsafe element being removed with its content
+
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		if skipping != "" {
+			if tokenType == html.EndTagToken && token.Data == skipping {
+				skipping = ""
+			}
+			continue
+		}
+
+		switch tokenType {
+		case html.TextToken:
+			out.WriteString(html.EscapeString(token.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if unsafeElements[token.Data] {
+				if tokenType == html.StartTagToken {
+					skipping = token.Data
+				}
+				continue
+			}
+			allowedAttributes, ok := p.AllowedTags[token.Data]
+			if !ok {
+				continue
+			}
+			out.WriteString("<" + token.Data)
+			for _, attribute := range token.Attr {
+				if attribute.Namespace != "" || !slices.Contains(allowedAttributes, attribute.Key) {
+					continue
+				}
+				if urlAttributes[attribute.Key] && !p.allowedURL(attribute.Val) {
+					continue
+				}
+				out.WriteString(" " + attribute.Key + `="` + html.EscapeString(attribute.Val) + `"`)
+			}
+			out.WriteString(">")
+			if tokenType == html.StartTagToken && !voidElements[token.Data] {
+				open = append(open, token.Data)
+			}
+
+		case html.EndTagToken:
+			// Close the element and the elements left open inside it.
+			i := slices.Index(open, token.Data)
+			if i < 0 {
+				continue
+			}
+			for j := len(open) - 1; j >= i; j-- {
+				out.WriteString("</" + open[j] + ">")
+			}
+			open = open[:i]
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+
+	return out.String()
+}
+
+func (p SanitizePolicy) allowedURL(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || slices.Contains(p.AllowedURLSchemes, u.Scheme)
+}
+
+// TemplateFuncs returns the functions available by default in the templates loaded by Fuego:
+//   - safeHTML: sanitizes user-generated HTML, see [SafeHTML]
+//   - asset: returns the URL of a static file, fingerprinted with [WithAssets] (the name is kept as is without it)
+//
+// Use it when parsing the templates yourself before passing them to [WithTemplates]:
+//
+//	templates := template.Must(template.New("").Funcs(fuego.TemplateFuncs()).ParseFS(fs, "*.html"))
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"safeHTML": SafeHTML,
+		"asset":    func(name string) string { return name },
+	}
+}
+
+// sanitizeRenderData returns a copy of the template data where the given fields of type [template.HTML]
+// are sanitized with [SafeHTML]. Data can be a map with string keys or a struct, or a pointer to them.
+// Fields of type string don't need to be sanitized: they are escaped by html/template.
+func sanitizeRenderData(data any, fields []string) any {
+	if len(fields) == 0 || data == nil {
+		return data
+	}
+
+	v := reflect.ValueOf(data)
+	isPointer := v.Kind() == reflect.Pointer
+	if isPointer {
+		if v.IsNil() {
+			return data
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return data
+		}
+		sanitized := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value := iter.Value()
+			if slices.Contains(fields, iter.Key().String()) {
+				value = sanitizeHTMLValue(value)
+			}
+			sanitized.SetMapIndex(iter.Key(), value)
+		}
+		return sanitized.Interface()
+
+	case reflect.Struct:
+		sanitized := reflect.New(v.Type())
+		sanitized.Elem().Set(v)
+		for _, field := range fields {
+			f := sanitized.Elem().FieldByName(field)
+			if f.IsValid() && f.CanSet() {
+				f.Set(sanitizeHTMLValue(f))
+			}
+		}
+		if isPointer {
+			return sanitized.Interface()
+		}
+		return sanitized.Elem().Interface()
+	}
+
+	return data
+}
+
+var htmlType = reflect.TypeFor[template.HTML]()
+
+func sanitizeHTMLValue(v reflect.Value) reflect.Value {
+	value := v
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		value = v.Elem()
+	}
+	if value.Type() != htmlType {
+		return v
+	}
+	return reflect.ValueOf(SafeHTML(value.String())).Convert(v.Type())
+}
@@ -0,0 +1,110 @@
+package fuego
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected template.HTML
+	}{
+		{name: "plain text", input: "Tom & Jerry", expected: "Tom &amp; Jerry"},
+		{name: "allowed formatting", input: "<p>Hello <strong>world</strong><br/></p>", expected: "<p>Hello <strong>world</strong><br></p>"},
+		{name: "script removed with its content", input: `<p>hi</p><script>alert("xss")</script>`, expected: "<p>hi</p>"},
+		{name: "style removed with its content", input: "<style>body{display:none}</style>ok", expected: "ok"},
+		{name: "unknown element removed, text kept", input: "<marquee>hello</marquee>", expected: "hello"},
+		{name: "event handlers removed", input: `<img src="/cat.png" onerror="alert(1)" alt="cat">`, expected: `<img src="/cat.png" alt="cat">`},
+		{name: "javascript URL removed", input: `<a href="javascript:alert(1)">click</a>`, expected: "<a>click</a>"},
+		{name: "encoded javascript URL removed", input: `<a href="JaVaScRiPt&colon;alert(1)">click</a>`, expected: "<a>click</a>"},
+		{name: "safe URL kept", input: `<a href="https://go-fuego.dev?a=1&b=2" title="Fuego">Fuego</a>`, expected: `<a href="https://go-fuego.dev?a=1&amp;b=2" title="Fuego">Fuego</a>`},
+		{name: "relative URL kept", input: `<a href="/docs">docs</a>`, expected: `<a href="/docs">docs</a>`},
+		{name: "comments removed", input: "a<!-- <script> -->b", expected: "ab"},
+		{name: "unclosed elements closed", input: "<ul><li><em>one", expected: "<ul><li><em>one</em></li></ul>"},
+		{name: "misnested elements", input: "<b><i>text</b></i>", expected: "<b><i>text</i></b>"},
+		{name: "stray closing tag ignored", input: "</div>text</p>", expected: "text"},
+		{name: "attribute breaking out", input: `<abbr title='"><script>alert(1)</script>'>x</abbr>`, expected: `<abbr title="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;">x</abbr>`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, SafeHTML(tc.input))
+		})
+	}
+}
+
+func TestSanitizePolicy(t *testing.T) {
+	policy := SanitizePolicy{
+		AllowedTags:       map[string][]string{"a": {"href"}},
+		AllowedURLSchemes: []string{"https"},
+	}
+
+	require.Equal(t, `<a href="https://go-fuego.dev">x</a> y`, policy.Sanitize(`<a href="https://go-fuego.dev">x</a> <b>y</b>`))
+	require.Equal(t, `<a>x</a>`, policy.Sanitize(`<a href="http://go-fuego.dev">x</a>`))
+}
+
+type renderData struct {
+	Bio     template.HTML
+	Comment template.HTML
+	Name    string
+}
+
+func TestSanitizeRenderData(t *testing.T) {
+	unsafe := template.HTML(`<b>hi</b><script>alert(1)</script>`)
+
+	t.Run("map", func(t *testing.T) {
+		data := H{"Bio": unsafe, "Comment": unsafe, "Count": 1}
+		sanitized := sanitizeRenderData(data, []string{"Bio", "Count"}).(H)
+		require.Equal(t, SafeHTML(string(unsafe)), sanitized["Bio"])
+		require.Equal(t, unsafe, sanitized["Comment"])
+		require.Equal(t, 1, sanitized["Count"])
+		require.Equal(t, unsafe, data["Bio"], "original data is not modified")
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		data := renderData{Bio: unsafe, Comment: unsafe, Name: "<b>"}
+		sanitized := sanitizeRenderData(data, []string{"Bio", "Name", "Unknown"}).(renderData)
+		require.Equal(t, SafeHTML(string(unsafe)), sanitized.Bio)
+		require.Equal(t, unsafe, sanitized.Comment)
+		require.Equal(t, "<b>", sanitized.Name)
+	})
+
+	t.Run("struct pointer", func(t *testing.T) {
+		data := &renderData{Bio: unsafe}
+		sanitized := sanitizeRenderData(data, []string{"Bio"}).(*renderData)
+		require.Equal(t, SafeHTML(string(unsafe)), sanitized.Bio)
+		require.Equal(t, unsafe, data.Bio)
+	})
+
+	t.Run("other types are left as is", func(t *testing.T) {
+		require.Equal(t, "text", sanitizeRenderData("text", []string{"Bio"}))
+		require.Nil(t, sanitizeRenderData(nil, []string{"Bio"}))
+	})
+}
+
+func TestRenderSanitization(t *testing.T) {
+	s := NewServer(
+		WithTemplateFS(testdata),
+		WithTemplateGlobs("testdata/*.html"),
+		WithSanitizedRenderFields("Comment"),
+	)
+
+	Get(s, "/sanitize", func(c ContextNoBody) (CtxRenderer, error) {
+		return c.Render("sanitize.html", H{
+			"Bio":     `<em>chef</em><script>alert(1)</script>`,
+			"Comment": template.HTML(`<a href="javascript:alert(1)">great</a>`),
+		})
+	})
+
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sanitize", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "<div><em>chef</em></div>\n<p><a>great</a></p>\n", w.Body.String())
+}
@@ -0,0 +1,107 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SearchQuery is the query of a [Search] endpoint, read from its query parameters.
+type SearchQuery struct {
+	// Searched text, from the q parameter.
+	Q string
+	// Fields to search in, from the fields parameter. If empty, all the searchable fields.
+	Fields []string
+	// If true, approximate matches are accepted, from the fuzzy parameter.
+	Fuzzy bool
+}
+
+// SearchBackend runs the queries of a [Search] endpoint, for example with a SQL LIKE clause,
+// a bleve index or an Elasticsearch cluster.
+//
+// If the backend has a SearchableFields() []string method, the fields parameter is restricted to its fields:
+// they are documented as the allowed values, and the other fields are rejected with a 400 Bad Request error.
+type SearchBackend[T any] interface {
+	Search(ctx context.Context, query SearchQuery) ([]T, error)
+}
+
+// SearchFunc is a function used as a [SearchBackend].
+type SearchFunc[T any] func(ctx context.Context, query SearchQuery) ([]T, error)
+
+func (f SearchFunc[T]) Search(ctx context.Context, query SearchQuery) ([]T, error) {
+	return f(ctx, query)
+}
+
+// searchableFields is implemented by the backends restricting the fields that can be searched.
+type searchableFields interface {
+	SearchableFields() []string
+}
+
+// Search registers a GET search endpoint returning the items found by the backend, so that the search endpoints
+// of an API share the same contract. The query parameters are declared in the OpenAPI spec:
+//   - q, required: the searched text,
+//   - fields: the fields to search in, repeated or separated by commas, like ?fields=name,description,
+//   - fuzzy: if true, approximate matches are accepted.
+//
+// The backend gets them as a [SearchQuery].
+//
+//	fuego.Search(s, "/recipes/search", fuego.SearchFunc[Recipe](func(ctx context.Context, query fuego.SearchQuery) ([]Recipe, error) {
+//		return store.SearchRecipes(ctx, query.Q, query.Fields)
+//	}), option.Summary("Search recipes"))
+func Search[T any](s *Server, path string, backend SearchBackend[T], options ...func(*BaseRoute)) *Route[[]T, any] {
+	var allowedFields []string
+	if searchable, ok := backend.(searchableFields); ok {
+		allowedFields = searchable.SearchableFields()
+	}
+
+	searchOptions := []func(*BaseRoute){
+		OptionQuery("q", "Searched text", ParamRequired()),
+		OptionQuery("fields", "Fields to search in, repeated or separated by commas. If empty, all the searchable fields"),
+		OptionQueryBool("fuzzy", "If true, approximate matches are accepted", ParamDefault(false)),
+		func(r *BaseRoute) {
+			items := openapi3.NewStringSchema()
+			for _, field := range allowedFields {
+				items.Enum = append(items.Enum, field)
+			}
+			parameter := r.Operation.Parameters.GetByInAndName(string(QueryParamType), "fields")
+			parameter.Schema = openapi3.NewArraySchema().WithItems(items).NewRef()
+		},
+	}
+	if f, ok := backend.(SearchFunc[T]); ok {
+		searchOptions = append(searchOptions, func(r *BaseRoute) { r.FullName = FuncName(f) })
+	}
+
+	return registerFuegoController(s, http.MethodGet, path, func(c ContextNoBody) ([]T, error) {
+		query := SearchQuery{
+			Q:     c.QueryParam("q"),
+			Fuzzy: c.QueryParamBool("fuzzy"),
+		}
+		for _, fields := range c.QueryParamArr("fields") {
+			for _, field := range strings.Split(fields, ",") {
+				if field = strings.TrimSpace(field); field != "" {
+					query.Fields = append(query.Fields, field)
+				}
+			}
+		}
+
+		if allowedFields != nil {
+			var errs []ErrorItem
+			for _, field := range query.Fields {
+				if !slices.Contains(allowedFields, field) {
+					errs = append(errs, ErrorItem{
+						Name:   "fields",
+						Reason: field + " is not searchable, use one of: " + strings.Join(allowedFields, ", "),
+					})
+				}
+			}
+			if errs != nil {
+				return nil, BadRequestError{Title: "Invalid Search Fields", Detail: errs[0].Reason, Errors: errs}
+			}
+		}
+
+		return backend.Search(c, query)
+	}, slices.Concat(searchOptions, options)...)
+}
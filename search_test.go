@@ -0,0 +1,92 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recipeIndex searches recipes by name or description, and records the last query.
+type recipeIndex struct {
+	recipes   []MyStruct
+	lastQuery SearchQuery
+}
+
+func (idx *recipeIndex) Search(ctx context.Context, query SearchQuery) ([]MyStruct, error) {
+	idx.lastQuery = query
+	found := []MyStruct{}
+	for _, recipe := range idx.recipes {
+		if strings.Contains(recipe.B, query.Q) {
+			found = append(found, recipe)
+		}
+	}
+	return found, nil
+}
+
+func (idx *recipeIndex) SearchableFields() []string {
+	return []string{"name", "description"}
+}
+
+func TestSearch(t *testing.T) {
+	index := &recipeIndex{recipes: []MyStruct{{B: "pizza"}, {B: "pasta"}, {B: "salad"}}}
+	s := NewServer()
+	Search[MyStruct](s, "/recipes/search", index, OptionTags("recipes"))
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	t.Run("searches with the backend", func(t *testing.T) {
+		w := get("/recipes/search?q=pa&fields=name,description&fields=name&fuzzy=true")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `[{"b":"pasta","c":0,"d":false}]`, w.Body.String())
+		require.Equal(t, SearchQuery{Q: "pa", Fields: []string{"name", "description", "name"}, Fuzzy: true}, index.lastQuery)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		w := get("/recipes/search?q=pizza")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, SearchQuery{Q: "pizza"}, index.lastQuery)
+	})
+
+	t.Run("q is required", func(t *testing.T) {
+		w := get("/recipes/search")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown fields are rejected", func(t *testing.T) {
+		w := get("/recipes/search?q=pizza&fields=name,price")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "price is not searchable, use one of: name, description")
+	})
+
+	t.Run("documents the contract", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/recipes/search").Get
+		require.True(t, operation.Parameters.GetByInAndName("query", "q").Required)
+		require.True(t, operation.Parameters.GetByInAndName("query", "fuzzy").Schema.Value.Type.Is("boolean"))
+		fields := operation.Parameters.GetByInAndName("query", "fields").Schema.Value
+		require.True(t, fields.Type.Is("array"))
+		require.Equal(t, []any{"name", "description"}, fields.Items.Value.Enum)
+	})
+}
+
+func TestSearchFunc(t *testing.T) {
+	s := NewServer()
+	route := Search(s, "/search", SearchFunc[MyStruct](searchAnything))
+
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?q=anything&fields=whatever", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `[{"b":"anything","c":0,"d":false}]`, w.Body.String())
+	require.Equal(t, "github.com/go-fuego/fuego.searchAnything", route.FullName)
+}
+
+func searchAnything(ctx context.Context, query SearchQuery) ([]MyStruct, error) {
+	return []MyStruct{{B: query.Q}}, nil
+}
@@ -31,6 +31,11 @@ type Security struct {
 	key             *ecdsa.PrivateKey
 	Now             func() time.Time
 	ExpiresInterval time.Duration
+	// Sessions stores the revoked sessions. If set, the tokens belong to a session (the "sid" claim)
+	// that can be ended with [Security.RevokeSession]. Set by [WithLogout].
+	Sessions SessionStore
+	// Cache caches the validations of the tokens. Set by [WithTokenCache].
+	Cache *TokenCache
 }
 
 func NewSecurity() Security {
@@ -48,9 +53,14 @@ func NewSecurity() Security {
 
 // GenerateToken generates a JWT token with the given claims.
 // The claims must be a jwt.MapClaims or embed jwt.RegisteredClaims.
+// If the sessions are enabled, a new session is started for the jwt.MapClaims without a "sid" claim:
+// the custom claims must have their own `json:"sid"` field to be revocable.
 func (security Security) GenerateToken(claims jwt.Claims) (token string, err error) {
-	if _, ok := claims.(jwt.MapClaims); ok {
-		claims.(jwt.MapClaims)["iat"] = security.Now().Unix()
+	if mapClaims, ok := claims.(jwt.MapClaims); ok {
+		mapClaims["iat"] = security.Now().Unix()
+		if _, hasSession := mapClaims["sid"]; security.Sessions != nil && !hasSession {
+			mapClaims["sid"] = newSessionID()
+		}
 	}
 
 	tok := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
@@ -78,7 +88,35 @@ func (security Security) GenerateTokenToCookies(claims jwt.Claims, w http.Respon
 	return token, nil
 }
 
+// ValidateToken verifies the signature and the expiry of the token, and that its session is not revoked.
+// The validations are cached if [Security.Cache] is set.
 func (security Security) ValidateToken(token string) (*jwt.Token, error) {
+	var t *jwt.Token
+	var err error
+	if security.Cache != nil {
+		t, err = security.Cache.validate(token, security.Now(), security.parseToken)
+	} else {
+		t, _, err = security.parseToken(token)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sid := sessionID(t.Claims); security.Sessions != nil && sid != "" {
+		revoked, err := security.Sessions.IsRevoked(context.Background(), sid)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrSessionRevoked
+		}
+	}
+
+	return t, nil
+}
+
+// parseToken verifies the signature and the expiry of the token, and returns the time it expires.
+func (security Security) parseToken(token string) (*jwt.Token, time.Time, error) {
 	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		return security.key.Public(), nil
 	},
@@ -86,17 +124,22 @@ func (security Security) ValidateToken(token string) (*jwt.Token, error) {
 		jwt.WithValidMethods([]string{"ES256"}),
 		jwt.WithLeeway(5*time.Second),
 		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(security.Now),
 	)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
 	iat, err := t.Claims.GetIssuedAt()
 	if err != nil || iat == nil || float64(iat.Unix())+security.ExpiresInterval.Seconds() < float64(security.Now().Unix()) {
-		return nil, ErrExpired
+		return nil, time.Time{}, ErrExpired
 	}
 
-	return t, nil
+	expiresAt := iat.Add(security.ExpiresInterval)
+	if exp, err := t.Claims.GetExpirationTime(); err == nil && exp != nil && exp.Before(expiresAt) {
+		expiresAt = exp.Time
+	}
+	return t, expiresAt, nil
 }
 
 type AutoAuthConfig struct {
@@ -487,13 +530,20 @@ func (security Security) RefreshHandler(w http.ResponseWriter, r *http.Request)
 	)
 }
 
-// CookieLogoutHandler generates a JWT token with the given claims and writes it to the cookies.
+// CookieLogoutHandler removes the JWT token from the cookies.
+// If the sessions are enabled, the session of the token is revoked.
 // Usage:
 //
 //	fuego.PostStd(s, "/auth/logout", security.CookieLogoutHandler)
 //
 // Dependency to [Security] is for symmetry with [RefreshHandler].
 func (security Security) CookieLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if claims, err := TokenFromContext(r.Context()); err == nil {
+		if err := security.RevokeSession(r.Context(), claims); err != nil {
+			SendJSONError(w, nil, err)
+			return
+		}
+	}
 	http.SetCookie(w, &http.Cookie{
 		Name:    JWTCookieName,
 		Expires: security.Now().Add(-security.ExpiresInterval),
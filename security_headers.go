@@ -0,0 +1,132 @@
+package fuego
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SecurityHeadersOptions configures the security headers sent with every response.
+// See [WithSecurityHeaders] and [OptionSecurityHeaders].
+type SecurityHeadersOptions struct {
+	// Content-Security-Policy header.
+	// Defaults to "default-src 'self'; base-uri 'self'; object-src 'none'; frame-ancestors 'none'".
+	ContentSecurityPolicy string
+	// Referrer-Policy header. Defaults to "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+	// X-Frame-Options header. Defaults to "DENY".
+	FrameOptions string
+	// max-age of the Strict-Transport-Security header. Defaults to 1 year.
+	HSTSMaxAge time.Duration
+	// Adds includeSubDomains to the Strict-Transport-Security header.
+	HSTSIncludeSubdomains bool
+	// Adds preload to the Strict-Transport-Security header.
+	HSTSPreload bool
+	// Do not send the Strict-Transport-Security header.
+	DisableHSTS bool
+	// Do not send the "X-Content-Type-Options: nosniff" header.
+	DisableContentTypeNosniff bool
+}
+
+var defaultSecurityHeadersOptions = SecurityHeadersOptions{
+	ContentSecurityPolicy: "default-src 'self'; base-uri 'self'; object-src 'none'; frame-ancestors 'none'",
+	ReferrerPolicy:        "strict-origin-when-cross-origin",
+	FrameOptions:          "DENY",
+	HSTSMaxAge:            365 * 24 * time.Hour,
+}
+
+// Content-Security-Policy used for the OpenAPI UI, which loads its scripts and styles from unpkg.com.
+const openAPIUIContentSecurityPolicy = "default-src 'self'; script-src 'self' https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data: https:; font-src 'self' data: https:; worker-src 'self' blob:"
+
+// WithSecurityHeaders adds security headers to all responses:
+// Strict-Transport-Security, Content-Security-Policy, X-Content-Type-Options,
+// Referrer-Policy and X-Frame-Options.
+// Empty fields are set to sane defaults, so the zero value can be used.
+// Headers can be overridden per route or per group with [OptionSecurityHeaders],
+// typically to relax the Content-Security-Policy of HTML routes.
+// For example:
+//
+//	s := fuego.NewServer(
+//		fuego.WithSecurityHeaders(fuego.SecurityHeadersOptions{
+//			HSTSIncludeSubdomains: true,
+//		}),
+//	)
+//
+//	fuego.Get(s, "/", homePage,
+//		option.SecurityHeaders(fuego.SecurityHeadersOptions{
+//			ContentSecurityPolicy: "default-src 'self'; script-src 'self' https://cdn.example.com",
+//		}),
+//	)
+func WithSecurityHeaders(options SecurityHeadersOptions) func(*Server) {
+	if options.ContentSecurityPolicy == "" {
+		options.ContentSecurityPolicy = defaultSecurityHeadersOptions.ContentSecurityPolicy
+	}
+	if options.ReferrerPolicy == "" {
+		options.ReferrerPolicy = defaultSecurityHeadersOptions.ReferrerPolicy
+	}
+	if options.FrameOptions == "" {
+		options.FrameOptions = defaultSecurityHeadersOptions.FrameOptions
+	}
+	if options.HSTSMaxAge == 0 {
+		options.HSTSMaxAge = defaultSecurityHeadersOptions.HSTSMaxAge
+	}
+
+	return func(s *Server) {
+		s.securityHeaders = &options
+		s.globalMiddlewares = append(s.globalMiddlewares, securityHeadersMiddleware(options))
+	}
+}
+
+// OptionSecurityHeaders overrides the security headers set by [WithSecurityHeaders] for the route.
+// Only the non-empty fields are overridden.
+// For example, to allow inline scripts on an HTML page:
+//
+//	fuego.Get(s, "/", homePage,
+//		option.SecurityHeaders(fuego.SecurityHeadersOptions{
+//			ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'",
+//		}),
+//	)
+func OptionSecurityHeaders(overrides SecurityHeadersOptions) func(*BaseRoute) {
+	return OptionMiddleware(securityHeadersMiddleware(overrides))
+}
+
+func securityHeadersMiddleware(options SecurityHeadersOptions) func(http.Handler) http.Handler {
+	hsts := ""
+	if options.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(options.HSTSMaxAge.Seconds()))
+		if options.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if options.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers := w.Header()
+
+			if options.ContentSecurityPolicy != "" {
+				headers.Set("Content-Security-Policy", options.ContentSecurityPolicy)
+			}
+			if options.ReferrerPolicy != "" {
+				headers.Set("Referrer-Policy", options.ReferrerPolicy)
+			}
+			if options.FrameOptions != "" {
+				headers.Set("X-Frame-Options", options.FrameOptions)
+			}
+			if options.DisableHSTS {
+				headers.Del("Strict-Transport-Security")
+			} else if hsts != "" {
+				headers.Set("Strict-Transport-Security", hsts)
+			}
+			if options.DisableContentTypeNosniff {
+				headers.Del("X-Content-Type-Options")
+			} else {
+				headers.Set("X-Content-Type-Options", "nosniff")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
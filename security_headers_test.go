@@ -0,0 +1,124 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func serveWithGlobalMiddlewares(s *Server, w http.ResponseWriter, r *http.Request) {
+	s.handler().ServeHTTP(w, r)
+}
+
+func TestWithSecurityHeaders(t *testing.T) {
+	t.Run("sane defaults", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithSecurityHeaders(SecurityHeadersOptions{}),
+		)
+		Get(s, "/", controller)
+
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "default-src 'self'; base-uri 'self'; object-src 'none'; frame-ancestors 'none'", w.Header().Get("Content-Security-Policy"))
+		require.Equal(t, "strict-origin-when-cross-origin", w.Header().Get("Referrer-Policy"))
+		require.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+		require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		require.Equal(t, "max-age=31536000", w.Header().Get("Strict-Transport-Security"))
+	})
+
+	t.Run("also applies to unregistered routes", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithSecurityHeaders(SecurityHeadersOptions{}),
+		)
+
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, httptest.NewRequest(http.MethodGet, "/not-found", nil))
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("custom values", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithSecurityHeaders(SecurityHeadersOptions{
+				ContentSecurityPolicy:     "default-src 'none'",
+				ReferrerPolicy:            "no-referrer",
+				FrameOptions:              "SAMEORIGIN",
+				HSTSMaxAge:                2 * time.Hour,
+				HSTSIncludeSubdomains:     true,
+				HSTSPreload:               true,
+				DisableContentTypeNosniff: true,
+			}),
+		)
+		Get(s, "/", controller)
+
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, "default-src 'none'", w.Header().Get("Content-Security-Policy"))
+		require.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+		require.Equal(t, "SAMEORIGIN", w.Header().Get("X-Frame-Options"))
+		require.Equal(t, "max-age=7200; includeSubDomains; preload", w.Header().Get("Strict-Transport-Security"))
+		require.Empty(t, w.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("disable HSTS", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithSecurityHeaders(SecurityHeadersOptions{DisableHSTS: true}),
+		)
+		Get(s, "/", controller)
+
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	})
+
+	t.Run("route override", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithSecurityHeaders(SecurityHeadersOptions{}),
+		)
+		Get(s, "/html", func(c ContextNoBody) (HTML, error) {
+			return "<h1>Hello</h1>", nil
+		}, OptionSecurityHeaders(SecurityHeadersOptions{
+			ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'",
+		}))
+		Get(s, "/json", controller)
+
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, httptest.NewRequest(http.MethodGet, "/html", nil))
+
+		require.Equal(t, "default-src 'self'; script-src 'self' 'unsafe-inline'", w.Header().Get("Content-Security-Policy"))
+		require.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+
+		w = httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, httptest.NewRequest(http.MethodGet, "/json", nil))
+
+		require.Equal(t, "default-src 'self'; base-uri 'self'; object-src 'none'; frame-ancestors 'none'", w.Header().Get("Content-Security-Policy"))
+	})
+
+	t.Run("OpenAPI UI can load its assets", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithSecurityHeaders(SecurityHeadersOptions{}),
+			WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})),
+		)
+		s.Engine.RegisterOpenAPIRoutes(s)
+
+		w := httptest.NewRecorder()
+		serveWithGlobalMiddlewares(s, w, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, openAPIUIContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+	})
+}
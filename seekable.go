@@ -0,0 +1,99 @@
+package fuego
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SeekableResponse is a response body read from an [io.ReadSeeker], like a file or a blob,
+// for endpoints serving large content such as videos or datasets.
+// Range requests are honored: the client can download only parts of the content, and resume downloads.
+// The matching parts are sent with a 206 Partial Content status, and unsatisfiable ranges get a
+// 416 Range Not Satisfiable status. If-Range, If-Modified-Since and If-None-Match are checked against
+// ModTime and ETag. If Content implements [io.Closer], it is closed once sent.
+//
+//	fuego.Get(s, "/videos/{id}", func(c fuego.ContextNoBody) (fuego.SeekableResponse, error) {
+//		video, err := os.Open(videoPath(c.PathParam("id")))
+//		if err != nil {
+//			return fuego.SeekableResponse{}, fuego.NotFoundError{Err: err}
+//		}
+//		info, _ := video.Stat()
+//		return fuego.SeekableResponse{Content: video, ContentType: "video/mp4", ModTime: info.ModTime()}, nil
+//	})
+type SeekableResponse struct {
+	Content io.ReadSeeker
+	// Content type of the content. If empty, it is inferred from the extension of Name, then from the content itself.
+	ContentType string
+	// Name of the content, like "video.mp4".
+	Name string
+	// Last modification time of the content, sent in the Last-Modified header if not zero.
+	ModTime time.Time
+	// Entity tag of the content, sent in the ETag header if not empty. Quoted if needed.
+	ETag string
+}
+
+// seekableResponse returns the [SeekableResponse] returned by a controller, if any.
+func seekableResponse(ans any) (SeekableResponse, bool) {
+	switch ans := ans.(type) {
+	case SeekableResponse:
+		return ans, true
+	case *SeekableResponse:
+		if ans != nil {
+			return *ans, true
+		}
+	}
+	return SeekableResponse{}, false
+}
+
+func isSeekableResponse[T any]() bool {
+	t := reflect.TypeFor[T]()
+	return t == reflect.TypeFor[SeekableResponse]() || t == reflect.TypeFor[*SeekableResponse]()
+}
+
+// send sends the content, or the requested ranges of the content.
+func (s SeekableResponse) send(w http.ResponseWriter, r *http.Request) error {
+	if s.Content == nil {
+		return errors.New("seekable response without content")
+	}
+	if closer, ok := s.Content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if s.ContentType != "" {
+		w.Header().Set("Content-Type", s.ContentType)
+	}
+	if s.ETag != "" {
+		etag := s.ETag
+		if !strings.HasSuffix(etag, `"`) {
+			etag = `"` + etag + `"`
+		}
+		w.Header().Set("ETag", etag)
+	}
+	http.ServeContent(w, r, s.Name, s.ModTime, s.Content)
+	return nil
+}
+
+// documentSeekableResponse documents the binary content of the default response, and the partial responses.
+func documentSeekableResponse(operation *openapi3.Operation, response *openapi3.Response, produces []string) {
+	if len(produces) == 0 {
+		produces = []string{"application/octet-stream"}
+	}
+	content := openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), produces)
+	response.WithContent(content)
+
+	if operation.Responses.Value("206") == nil {
+		operation.AddResponse(http.StatusPartialContent, openapi3.NewResponse().
+			WithDescription("Partial Content _(requested ranges)_").
+			WithContent(content))
+	}
+	if operation.Responses.Value("416") == nil {
+		operation.AddResponse(http.StatusRequestedRangeNotSatisfiable, openapi3.NewResponse().
+			WithDescription("Range Not Satisfiable"))
+	}
+}
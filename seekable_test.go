@@ -0,0 +1,104 @@
+package fuego
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSeekableResponse(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	content := &closeRecorder{}
+
+	s := NewServer(WithoutLogger())
+	Get(s, "/dataset", func(c ContextNoBody) (SeekableResponse, error) {
+		content.Reader = strings.NewReader("0123456789")
+		return SeekableResponse{
+			Content:     content,
+			ContentType: "text/csv",
+			ModTime:     modTime,
+			ETag:        "v1",
+		}, nil
+	})
+	Get(s, "/empty", func(c ContextNoBody) (*SeekableResponse, error) {
+		return &SeekableResponse{}, nil
+	})
+
+	serve := func(path string, headers map[string]string) *http.Response {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, value := range headers {
+			r.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w.Result()
+	}
+	body := func(res *http.Response) string {
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("whole content", func(t *testing.T) {
+		res := serve("/dataset", nil)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "0123456789", body(res))
+		require.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+		require.Equal(t, "bytes", res.Header.Get("Accept-Ranges"))
+		require.Equal(t, `"v1"`, res.Header.Get("ETag"))
+		require.Equal(t, modTime.Format(http.TimeFormat), res.Header.Get("Last-Modified"))
+		require.True(t, content.closed)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		res := serve("/dataset", map[string]string{"Range": "bytes=2-5"})
+		require.Equal(t, http.StatusPartialContent, res.StatusCode)
+		require.Equal(t, "2345", body(res))
+		require.Equal(t, "bytes 2-5/10", res.Header.Get("Content-Range"))
+	})
+
+	t.Run("If-Range matching", func(t *testing.T) {
+		res := serve("/dataset", map[string]string{"Range": "bytes=8-", "If-Range": `"v1"`})
+		require.Equal(t, http.StatusPartialContent, res.StatusCode)
+		require.Equal(t, "89", body(res))
+	})
+
+	t.Run("If-Range outdated sends the whole content", func(t *testing.T) {
+		res := serve("/dataset", map[string]string{"Range": "bytes=8-", "If-Range": `"v0"`})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "0123456789", body(res))
+	})
+
+	t.Run("unsatisfiable range", func(t *testing.T) {
+		res := serve("/dataset", map[string]string{"Range": "bytes=20-30"})
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, res.StatusCode)
+	})
+
+	t.Run("missing content", func(t *testing.T) {
+		res := serve("/empty", nil)
+		require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+
+	t.Run("documented as binary", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/dataset").Get
+		schema := operation.Responses.Value("200").Value.Content["application/octet-stream"].Schema.Value
+		require.Equal(t, "binary", schema.Format)
+		require.NotNil(t, operation.Responses.Value("206"))
+		require.NotNil(t, operation.Responses.Value("416"))
+	})
+}
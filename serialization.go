@@ -84,8 +84,14 @@ type Sender func(http.ResponseWriter, *http.Request, any) error
 // Send sends a response.
 // The format is determined by the Accept header.
 // If Accept header `*/*` is found Send will Attempt to send
-// HTML, and then JSON.
+// HTML, and then JSON. [XLSX] responses are always sent as spreadsheets, and [PDF] ones as PDF documents.
 func Send(w http.ResponseWriter, r *http.Request, ans any) (err error) {
+	switch response := ans.(type) {
+	case xlsxResponse:
+		return SendXLSX(w, r, ans)
+	case PDF:
+		return response.send(w, r)
+	}
 	for _, header := range parseAcceptHeader(r.Header) {
 		switch inferAcceptHeader(header, ans) {
 		case "application/xml":
@@ -96,8 +102,10 @@ func Send(w http.ResponseWriter, r *http.Request, ans any) (err error) {
 			err = SendText(w, nil, ans)
 		case "application/json":
 			err = SendJSON(w, nil, ans)
-		case "application/x-yaml", "text/yaml; charset=utf-8", "application/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
+		case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
 			err = SendYAML(w, nil, ans)
+		case xlsxContentType:
+			err = SendXLSX(w, r, ans)
 		default:
 			// if we don't support the header, try the next one
 			continue
@@ -185,7 +193,7 @@ var SendError = func(w http.ResponseWriter, r *http.Request, err error) {
 			SendTextError(w, r, err)
 		case "application/json":
 			SendJSONError(w, nil, err)
-		case "application/x-yaml", "text/yaml; charset=utf-8", "application/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
+		case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
 			SendYAMLError(w, nil, err)
 		default:
 			continue
@@ -207,7 +215,8 @@ func SendJSONError(w http.ResponseWriter, _ *http.Request, err error) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var httpError HTTPError
-	if errors.As(err, &httpError) {
+	var problem ProblemDetail
+	if errors.As(err, &httpError) || errors.As(err, &problem) {
 		w.Header().Set("Content-Type", "application/problem+json")
 	}
 
@@ -384,8 +393,9 @@ func parseAcceptHeader(header http.Header) []string {
 		// ignore quality parameters if they exist
 		index := strings.Index(v, ";")
 		if index > 0 {
-			vals[i] = v[:index]
+			v = v[:index]
 		}
+		vals[i] = strings.TrimSpace(v)
 	}
 	return vals
 }
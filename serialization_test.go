@@ -39,6 +39,20 @@ func TestSend(t *testing.T) {
 	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
 }
 
+func TestSendYAMLOnAccept(t *testing.T) {
+	for _, accept := range []string{"application/yaml", "text/yaml", "text/csv, application/x-yaml;q=0.8"} {
+		t.Run(accept, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", accept)
+			err := Send(w, r, response{Message: "Hello World", Code: http.StatusOK})
+			require.NoError(t, err)
+			require.Equal(t, "application/x-yaml", w.Header().Get("Content-Type"))
+			require.Contains(t, w.Body.String(), "message: Hello World")
+		})
+	}
+}
+
 func TestSendWhenError(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -567,6 +581,13 @@ func TestParseAcceptHeader(t *testing.T) {
 		accept := parseAcceptHeader(header)
 		require.Equal(t, []string{"text/html", "application/xhtml+xml", "application/xml", "*/*"}, accept)
 	})
+
+	t.Run("trims spaces", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Accept", "application/yaml, application/json; q=0.5")
+		accept := parseAcceptHeader(header)
+		require.Equal(t, []string{"application/yaml", "application/json"}, accept)
+	})
 }
 
 func TestSendError(t *testing.T) {
@@ -611,6 +632,12 @@ func TestSendError(t *testing.T) {
 
 			expectedContentType: "application/x-yaml",
 		},
+		{
+			name:         "yaml rfc9512",
+			acceptHeader: "application/yaml",
+
+			expectedContentType: "application/x-yaml",
+		},
 		{
 			name:         "no case header",
 			acceptHeader: "application/foo",
@@ -1,10 +1,14 @@
 package fuego
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"html/template"
 	"log/slog"
 	"net"
 	"net/http"
+	"os"
 	"reflect"
 	"time"
 
@@ -15,11 +19,12 @@ import (
 // It is blocking.
 // It returns an error if the server could not start (it could not bind to the port for example).
 // It also generates the OpenAPI spec and outputs it to a file, the UI, and a handler (if enabled).
+// On SIGINT or SIGTERM, it shuts the server down gracefully (see [Server.Shutdown]) and returns nil.
 func (s *Server) Run() error {
 	if err := s.setup(); err != nil {
 		return err
 	}
-	return s.Server.Serve(s.listener)
+	return s.serveUntilSignal(func() error { return s.Server.Serve(s.listener) })
 }
 
 // RunTLS starts the server with a TLS listener
@@ -31,44 +36,139 @@ func (s *Server) RunTLS(certFile, keyFile string) error {
 	if err := s.setup(); err != nil {
 		return err
 	}
-	return s.Server.ServeTLS(s.listener, certFile, keyFile)
+	return s.serveUntilSignal(func() error { return s.Server.ServeTLS(s.listener, certFile, keyFile) })
 }
 
 func (s *Server) setup() error {
+	if s.pluginsErr != nil {
+		return s.pluginsErr
+	}
+	if err := s.ValidateRoutes(); err != nil {
+		if s.strictRouteValidation {
+			return err
+		}
+		slog.Warn(err.Error())
+	}
+	if err := s.checkEmbeddedSpec(); err != nil {
+		var mismatch RouteValidationError
+		if s.strictRouteValidation || !errors.As(err, &mismatch) {
+			return err
+		}
+		slog.Warn(err.Error())
+	}
 	if err := s.setupDefaultListener(); err != nil {
 		return err
 	}
-	s.OpenAPI.Description().Servers = append(s.OpenAPI.Description().Servers, &openapi3.Server{
-		URL:         s.url(),
-		Description: "local server",
-	})
-	go s.OutputOpenAPISpec()
+	if !s.openAPIServersDeclared && s.embeddedSpec == nil {
+		server := &openapi3.Server{URL: s.publicURL, Description: "public server"}
+		if s.publicURL == "" {
+			server = &openapi3.Server{URL: s.url(), Description: "local server"}
+			s.localOpenAPIServer = server
+		}
+		s.OpenAPI.Description().Servers = append(s.OpenAPI.Description().Servers, server)
+	}
+	if s.embeddedSpec == nil {
+		go s.OutputOpenAPISpec()
+	}
 	s.Engine.RegisterOpenAPIRoutes(s)
+	if err := s.startPlugins(context.Background()); err != nil {
+		return err
+	}
+	s.routes.serve()
 	s.printStartupMessage()
 
-	s.Server.Handler = s.Mux
+	s.Server.Handler = s.handler()
+
+	return nil
+}
+
+// handler returns the handler serving all the requests: the router wrapped by the global middlewares.
+func (s *Server) handler() http.Handler {
+	handler := http.Handler(s.routes)
+
+	if s.notFoundController != nil {
+		route := NewBaseRoute("", "", s.notFoundController, s.Engine, OptionDefaultStatusCode(http.StatusNotFound))
+		handler = notFoundFallback(s.routes, HTTPHandler(s, s.notFoundController, route))
+	}
 
 	for _, middleware := range s.globalMiddlewares {
-		s.Server.Handler = middleware(s.Server.Handler)
+		handler = middleware(handler)
 	}
 
-	return nil
+	if len(s.trustedProxies) > 0 {
+		handler = clientIPMiddleware(s.trustedProxies)(handler)
+	}
+
+	if !s.disablePanicRecovery {
+		handler = s.recoverPanics(handler)
+	}
+
+	return handler
+}
+
+// notFoundFallback serves the requests matching no route with the notFound handler,
+// instead of the plain-text 404 of the router. Other requests, including the ones answered
+// with 405 Method Not Allowed, are served by the router.
+func notFoundFallback(mux Router, notFound http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		interceptor := &notFoundInterceptor{ResponseWriter: w}
+		mux.ServeHTTP(interceptor, r)
+		if interceptor.notFound {
+			w.Header().Del("Content-Type")
+			w.Header().Del("X-Content-Type-Options")
+			notFound.ServeHTTP(w, r)
+		}
+	})
+}
+
+// notFoundInterceptor discards the 404 response written by the router.
+type notFoundInterceptor struct {
+	http.ResponseWriter
+	notFound bool
+}
+
+func (w *notFoundInterceptor) WriteHeader(code int) {
+	if code == http.StatusNotFound {
+		w.notFound = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *notFoundInterceptor) Write(b []byte) (int, error) {
+	if w.notFound {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
 }
 
 func (s *Server) setupDefaultListener() error {
 	if s.listener != nil {
 		s.Addr = s.listener.Addr().String()
-		return nil
+	} else {
+		listener, err := net.Listen("tcp", s.Addr)
+		if err != nil {
+			return err
+		}
+		s.listener = listener
 	}
-	listener, err := net.Listen("tcp", s.Addr)
-	s.listener = listener
-	return err
+	s.listener = s.limitListener(s.listener)
+	return nil
 }
 
 func (s *Server) printStartupMessage() {
 	if !s.disableStartupMessages {
-		elapsed := time.Since(s.startTime)
+		elapsed := s.now().Sub(s.startTime)
 		slog.Debug("Server started in "+elapsed.String(), "info", "time between since server creation (fuego.NewServer) and server startup (fuego.Run). Depending on your implementation, there might be things that do not depend on fuego slowing start time")
+		if s.environment == Dev {
+			fmt.Fprintf(os.Stderr, "\033[1;32mServer running ✅ on \033[4m%s\033[0m \033[2m(started in %s)\033[0m\n", s.url(), elapsed)
+			return
+		}
 		slog.Info("Server running ✅ on "+s.url(), "started in", elapsed.String())
 	}
 }
@@ -88,23 +188,64 @@ func (s *Server) url() string {
 // Uses Server for configuration.
 // Uses Route for route configuration. Optional.
 func HTTPHandler[ReturnType, Body any](s *Server, controller func(c ContextWithBody[Body]) (ReturnType, error), route BaseRoute) http.HandlerFunc {
+	bodyDecoders := mergeBodyDecoders(s.bodyDecoders, route.BodyDecoders)
+	bodyTransformers := s.BodyTransformers(reflect.TypeFor[Body]())
+	disallowUnknownFields := s.DisallowUnknownFields
+	if route.DisallowUnknownFields != nil {
+		disallowUnknownFields = *route.DisallowUnknownFields
+	}
+	options := readOptions{
+		DisallowUnknownFields: disallowUnknownFields,
+		MaxBodySize:           s.maxBodySize,
+		MaxDepth:              s.jsonDecoderOptions.maxDepth(),
+		UseNumber:             s.jsonDecoderOptions.UseNumber,
+		DisallowNaN:           s.jsonDecoderOptions.DisallowNaN,
+		BodyDecoders:          bodyDecoders,
+		BinaryContentTypes:    route.RequestContentTypes,
+		TimeLayouts:           s.formTimeLayouts,
+		OptionalBody:          route.OptionalBody,
+	}
+	options.formDecoder = newDecoder(options)
+	if s.validationWarmup {
+		warmUpBody[Body](options.formDecoder, route.ValidationScenario)
+	}
+	compiled := compileRoute[ReturnType](route.Params)
+	controller = withVariants(route, controller)
+	if route.beginTx != nil {
+		controller = transactional(route.beginTx, controller)
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		var templates *template.Template
 		if s.template != nil {
 			templates = template.Must(s.template.Clone())
 		}
+		if s.reloadTemplates && s.templatePatterns != nil {
+			reloaded, err := s.parseTemplates(s.templatePatterns...)
+			if err != nil {
+				slog.Error("Error reloading templates", "error", err)
+			} else {
+				templates = reloaded
+			}
+		}
 
 		// CONTEXT INITIALIZATION
-		ctx := NewNetHTTPContext[Body](route, w, r, readOptions{
-			DisallowUnknownFields: s.DisallowUnknownFields,
-			MaxBodySize:           s.maxBodySize,
-		})
+		ctx := NewNetHTTPContext[Body](route, w, r, options)
 		ctx.serializer = s.Serialize
 		ctx.errorSerializer = s.SerializeError
 		ctx.fs = s.fs
 		ctx.templates = templates
+		ctx.sanitizedRenderFields = s.sanitizedRenderFields
+		ctx.renderTimeout = s.renderTimeout
+		ctx.pdfConverter = s.pdfConverter
+		ctx.pagination = s.pagination
+		ctx.UndeclaredParamPolicy = s.UndeclaredParamPolicy
+		ctx.BodyTransformers = bodyTransformers
+		ctx.ValidationDeps = s.ValidationDeps
+		ctx.QueryTimeout = s.queryTimeout
+		ctx.BaseHTTPClient = s.httpClient
 
-		Flow(s.Engine, ctx, controller)
+		flow(s.Engine, ctx, controller, compiled)
 	}
 }
 
@@ -121,17 +262,90 @@ type ContextFlowable[B any] interface {
 	SerializeError(err error)
 }
 
+// undeclaredParamRecorder is implemented by the contexts recording the query parameters
+// read by the controller but not declared in the OpenAPI spec, see [UndeclaredParamError].
+type undeclaredParamRecorder interface {
+	UndeclaredParamErr() error
+}
+
+// callController calls the controller. A query parameter read by the controller but not declared
+// in the OpenAPI spec, with the [UndeclaredParamError] policy, is returned as a 500 error instead of its answer.
+func callController[B, T any](ctx ContextWithBody[B], controller func(c ContextWithBody[B]) (T, error)) (T, error) {
+	ans, err := controller(ctx)
+
+	if recorder, ok := ctx.(undeclaredParamRecorder); ok {
+		if undeclaredErr := recorder.UndeclaredParamErr(); undeclaredErr != nil {
+			return ans, InternalServerError{
+				Title:  "Undeclared Parameter",
+				Detail: undeclaredErr.Error(),
+				Err:    undeclaredErr,
+			}
+		}
+	}
+
+	return ans, err
+}
+
+// compiledRoute is the knowledge of a route needed to serve it, gathered once when registering it
+// instead of on each request.
+type compiledRoute struct {
+	// Params to check on each request, see [requiredParams].
+	requiredParams []OpenAPIParam
+	// How the answers of the controller are sent.
+	response responsePlan
+}
+
+func compileRoute[T any](params map[string]OpenAPIParam) compiledRoute {
+	return compiledRoute{
+		requiredParams: requiredParams(params),
+		response:       newResponsePlan[T](),
+	}
+}
+
+// responsePlan tells how the answers of type T of a controller are sent, from T only.
+// If T is an interface, like any, the answers are inspected on each request.
+type responsePlan struct {
+	dynamic      bool
+	noContent    bool
+	stream       bool
+	seekable     bool
+	outTransform bool
+}
+
+func newResponsePlan[T any]() responsePlan {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Interface {
+		return responsePlan{dynamic: true}
+	}
+	_, stream := streamElemType(t)
+	outTransformerType := reflect.TypeFor[OutTransformer]()
+	return responsePlan{
+		noContent:    isNoContent[T](),
+		stream:       stream,
+		seekable:     isSeekableResponse[T](),
+		outTransform: t.Implements(outTransformerType) || reflect.PointerTo(t).Implements(outTransformerType),
+	}
+}
+
 // Flow is generic handler for Fuego controllers.
 func Flow[B, T any](s *Engine, ctx ContextFlowable[B], controller func(c ContextWithBody[B]) (T, error)) {
+	flow(s, ctx, controller, compileRoute[T](ctx.GetOpenAPIParams()))
+}
+
+func flow[B, T any](s *Engine, ctx ContextFlowable[B], controller func(c ContextWithBody[B]) (T, error), compiled compiledRoute) {
+	if s.errorReporter != nil {
+		defer s.reportPanic(ctx)
+	}
+
 	ctx.SetHeader("X-Powered-By", "Fuego")
 	ctx.SetHeader("Trailer", "Server-Timing")
 
 	timeCtxInit := time.Now()
 
 	// PARAMS VALIDATION
-	err := ValidateParams(ctx)
+	err := validateRequiredParams(ctx, compiled.requiredParams)
 	if err != nil {
-		err = s.ErrorHandler(err)
+		err = s.handleError(ctx, err)
 		ctx.SerializeError(err)
 		return
 	}
@@ -140,9 +354,24 @@ func Flow[B, T any](s *Engine, ctx ContextFlowable[B], controller func(c Context
 	ctx.SetHeader("Server-Timing", Timing{"fuegoReqInit", "", timeController.Sub(timeCtxInit)}.String())
 
 	// CONTROLLER
-	ans, err := controller(ctx)
+	ans, err := callController(ctx, controller)
+	if s.clientDisconnectDetection && ctx.IsAborted() {
+		slog.Debug("client disconnected before the response", "path", ctx.Request().URL.Path, "error", err)
+		ctx.SetStatus(StatusClientClosedRequest)
+		return
+	}
+	if isEventStream(ctx.Response()) {
+		// The events are already sent, see [SSEWriter]
+		if err != nil {
+			sendSSEError(ctx.Response(), ctx.Request(), s.handleError(ctx, err))
+		}
+		return
+	}
 	if err != nil {
-		err = s.ErrorHandler(err)
+		if s.validationErrorFormatter != nil {
+			err = formatValidationError(err, s.validationErrorFormatter)
+		}
+		err = s.handleError(ctx, err)
 		ctx.SerializeError(err)
 		return
 	}
@@ -150,26 +379,49 @@ func Flow[B, T any](s *Engine, ctx ContextFlowable[B], controller func(c Context
 
 	ctx.SetDefaultStatusCode()
 
-	if reflect.TypeOf(ans) == nil {
+	plan := compiled.response
+	if plan.noContent || (plan.dynamic && reflect.TypeOf(ans) == nil) {
 		return
 	}
 
 	// TRANSFORM OUT
 	timeTransformOut := time.Now()
-	ans, err = transformOut(ctx.Context(), ans)
-	if err != nil {
-		err = s.ErrorHandler(err)
-		ctx.SerializeError(err)
-		return
+	if plan.outTransform || plan.dynamic {
+		ans, err = transformOut(ctx.Context(), ans)
+		if err != nil {
+			err = s.handleError(ctx, err)
+			ctx.SerializeError(err)
+			return
+		}
 	}
 	timeAfterTransformOut := time.Now()
 	ctx.SetHeader("Server-Timing", Timing{"transformOut", "transformOut", timeAfterTransformOut.Sub(timeTransformOut)}.String())
 
 	// SERIALIZATION
-	err = ctx.Serialize(ans)
+	if s.fieldAuthorizer != nil && !plan.stream && !plan.seekable {
+		err = sendAnswer(ctx, authorizeFields(ctx.Context(), s.fieldAuthorizer, ans), plan)
+	} else {
+		err = sendAnswer(ctx, ans, plan)
+	}
 	if err != nil {
-		err = s.ErrorHandler(err)
+		err = s.handleError(ctx, err)
 		ctx.SerializeError(err)
 	}
 	ctx.SetHeader("Server-Timing", Timing{"serialize", "", time.Since(timeAfterTransformOut)}.String())
 }
+
+// sendAnswer sends the answer of the controller as a stream, a seekable content or a serialized body.
+func sendAnswer[B, T any](ctx ContextFlowable[B], ans T, plan responsePlan) error {
+	if plan.stream || plan.dynamic {
+		if values, ok := streamValues(ans); ok {
+			sendJSONStream(ctx.Response(), values)
+			return nil
+		}
+	}
+	if plan.seekable || plan.dynamic {
+		if seekable, ok := seekableResponse(ans); ok {
+			return seekable.send(ctx.Response(), ctx.Request())
+		}
+	}
+	return ctx.Serialize(ans)
+}
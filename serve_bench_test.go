@@ -0,0 +1,70 @@
+package fuego
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type benchPet struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name" validate:"required,max=50"`
+	Tags []string `json:"tags"`
+}
+
+// newBenchServer returns a server with many routes, so that routing is not benchmarked on a trivial mux.
+func newBenchServer(options ...func(*Server)) *Server {
+	s := NewServer(append([]func(*Server){WithoutLogger()}, options...)...)
+	pets := Group(s, "/pets", OptionTags("pets"))
+	for i := range 50 {
+		Get(pets, fmt.Sprintf("/other/%d", i), func(c ContextNoBody) (benchPet, error) { return benchPet{}, nil })
+	}
+	Get(pets, "/{id}", func(c ContextNoBody) (benchPet, error) {
+		return benchPet{ID: c.PathParamInt("id"), Name: "Rex", Tags: []string{c.QueryParam("tag")}}, nil
+	}, OptionQuery("tag", "Tag of the pet"))
+	Post(pets, "/", func(c ContextWithBody[benchPet]) (benchPet, error) {
+		return c.Body()
+	})
+	GetStd(pets, "/std/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.PathValue("id")))
+	})
+	return s
+}
+
+func BenchmarkServe(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		accept  string
+		options []func(*Server)
+	}{
+		{name: "std handler", method: http.MethodGet, path: "/pets/std/1"},
+		{name: "get with params", method: http.MethodGet, path: "/pets/1?tag=dog"},
+		{name: "post with body", method: http.MethodPost, path: "/pets/", body: `{"id":1,"name":"Rex","tags":["dog"]}`},
+		{name: "not found", method: http.MethodGet, path: "/unknown"},
+		{name: "get as XML", method: http.MethodGet, path: "/pets/1?tag=dog", accept: "application/xml"},
+		{name: "production server", method: http.MethodPost, path: "/pets/", body: `{"id":1,"name":"Rex","tags":["dog"]}`, options: []func(*Server){WithEnvironment(Prod), WithSecurityHeaders(SecurityHeadersOptions{})}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			s := newBenchServer(bm.options...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				r := httptest.NewRequest(bm.method, bm.path, strings.NewReader(bm.body))
+				r.Header.Set("Content-Type", "application/json")
+				r.Header.Set("Accept", bm.accept)
+				w := httptest.NewRecorder()
+				s.Mux.ServeHTTP(w, r)
+				if w.Code >= http.StatusInternalServerError {
+					b.Fatal(w.Code, w.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"math/big"
 	"net"
 	"net/http"
@@ -684,3 +685,171 @@ func TestFlow(t *testing.T) {
 		}
 	})
 }
+
+func TestWithClientDisconnectDetection(t *testing.T) {
+	disconnectingController := func(cancel context.CancelFunc) func(c ContextNoBody) (ans, error) {
+		return func(c ContextNoBody) (ans, error) {
+			require.False(t, c.IsAborted())
+			cancel()
+			<-c.Done()
+			require.True(t, c.IsAborted())
+			return ans{Ans: "too late"}, c.Err()
+		}
+	}
+	call := func(e *Engine) *httptest.ResponseRecorder {
+		ctx, cancel := context.WithCancel(context.Background())
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		Flow(e, NewNetHTTPContext[any](BaseRoute{}, w, r, readOptions{}), disconnectingController(cancel))
+		return w
+	}
+
+	t.Run("response skipped when the client disconnects", func(t *testing.T) {
+		w := call(NewEngine(WithClientDisconnectDetection()))
+		require.Equal(t, StatusClientClosedRequest, w.Code)
+		require.Empty(t, w.Body.String())
+	})
+
+	t.Run("error sent by default", func(t *testing.T) {
+		w := call(NewEngine())
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotEmpty(t, w.Body.String())
+	})
+
+	t.Run("timeouts are not cancellations", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		Flow(NewEngine(WithClientDisconnectDetection()), NewNetHTTPContext[any](BaseRoute{}, w, r, readOptions{}), func(c ContextNoBody) (ans, error) {
+			<-c.Done()
+			require.False(t, c.IsAborted())
+			return ans{}, c.Err()
+		})
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestWithNotFoundHandler(t *testing.T) {
+	t.Run("default mux 404", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "404 page not found\n", w.Body.String())
+	})
+
+	s := NewServer(
+		WithoutLogger(),
+		WithNotFoundHandler(NotFoundController),
+	)
+	Get(s, "/pets", func(c ContextNoBody) (string, error) { return "pets", nil })
+
+	t.Run("unmatched path uses the error format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "application/problem+json", w.Result().Header.Get("Content-Type"))
+		require.JSONEq(t, `{"title":"Not Found","status":404,"detail":"no route matches GET /unknown"}`, w.Body.String())
+	})
+
+	t.Run("error format follows the Accept header", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+		r.Header.Set("Accept", "application/xml")
+		s.handler().ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Contains(t, w.Body.String(), "<title>Not Found</title>")
+	})
+
+	t.Run("matched routes are served", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pets", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "pets", w.Body.String())
+	})
+
+	t.Run("method not allowed is kept", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/pets", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		require.Contains(t, w.Header().Get("Allow"), http.MethodGet)
+	})
+
+	t.Run("rendered page", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithNotFoundHandler(func(c ContextNoBody) (any, error) {
+				return HTML("<h1>Nothing at " + c.Request().URL.Path + "</h1>"), nil
+			}),
+		)
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "<h1>Nothing at /unknown</h1>", w.Body.String())
+	})
+}
+
+func TestNoContent(t *testing.T) {
+	s := NewServer()
+
+	route := Delete(s, "/pets/{id}", func(c ContextNoBody) (NoContent, error) {
+		return NoContent{}, nil
+	})
+
+	t.Run("responds 204 without body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/pets/1", nil))
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Body.String())
+		require.Empty(t, w.Result().Header.Get("Content-Type"))
+	})
+
+	t.Run("documents 204 without content", func(t *testing.T) {
+		require.Nil(t, route.Operation.Responses.Value("200"))
+		response := route.Operation.Responses.Value("204")
+		require.NotNil(t, response)
+		require.Equal(t, "No Content", *response.Value.Description)
+		require.Empty(t, response.Value.Content)
+	})
+
+	t.Run("errors are still sent", func(t *testing.T) {
+		Delete(s, "/owners/{id}", func(c ContextNoBody) (NoContent, error) {
+			return NoContent{}, NotFoundError{Title: "Owner not found"}
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/owners/1", nil))
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Contains(t, w.Body.String(), "Owner not found")
+	})
+
+	t.Run("default status code can be overridden", func(t *testing.T) {
+		route := Post(s, "/pets/{id}/feed", func(c ContextNoBody) (NoContent, error) {
+			return NoContent{}, nil
+		}, OptionDefaultStatusCode(http.StatusAccepted))
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pets/1/feed", nil))
+
+		require.Equal(t, http.StatusAccepted, w.Code)
+		require.Empty(t, w.Body.String())
+		require.Empty(t, route.Operation.Responses.Value("202").Value.Content)
+	})
+}
+
+func TestNewResponsePlan(t *testing.T) {
+	require.Equal(t, responsePlan{}, newResponsePlan[ans]())
+	require.Equal(t, responsePlan{}, newResponsePlan[*ans]())
+	require.Equal(t, responsePlan{dynamic: true}, newResponsePlan[any]())
+	require.Equal(t, responsePlan{dynamic: true}, newResponsePlan[error]())
+	require.Equal(t, responsePlan{noContent: true}, newResponsePlan[NoContent]())
+	require.Equal(t, responsePlan{stream: true}, newResponsePlan[<-chan ans]())
+	require.Equal(t, responsePlan{stream: true}, newResponsePlan[iter.Seq[ans]]())
+	require.Equal(t, responsePlan{seekable: true}, newResponsePlan[SeekableResponse]())
+	require.Equal(t, responsePlan{outTransform: true}, newResponsePlan[testOutTransformer]())
+	require.Equal(t, responsePlan{outTransform: true}, newResponsePlan[*testOutTransformer]())
+	require.Equal(t, responsePlan{outTransform: true}, newResponsePlan[testOutTransformerOnNotReceiver]())
+}
@@ -1,6 +1,7 @@
 package fuego
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -8,7 +9,10 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"net/netip"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -25,29 +29,66 @@ type Server struct {
 	// Will be plugged into the Server field.
 	// Not using directly the Server field so
 	// [http.ServeMux.Handle] can also be used to register routes.
+	// Not used if another router is set with [WithRouter].
 	Mux *http.ServeMux
 
+	// Router of the routes. Defaults to Mux. See [WithRouter].
+	router Router
+	// Serves the routes with the router, shared with the groups. See [Server.RemoveRoute].
+	routes *routeTable
+
 	// globalMiddlewares is used to store the options
 	// that will be applied on ALL routes.
 	globalMiddlewares []func(http.Handler) http.Handler
 
+	// Reverse proxies allowed to set the client IP address. See [WithTrustedProxies].
+	trustedProxies []netip.Prefix
+
 	*Engine
 
 	listener net.Listener
+	// Maximum numbers of open connections, in total and per IP address. See [WithConnectionLimits].
+	maxConnections      int
+	maxConnectionsPerIP int
+	// Counts the connections by state. See [WithConnMetrics].
+	connTracker *connTracker
 
 	template *template.Template // TODO: use preparsed templates
 
+	// Fields of the render data sanitized before rendering. See [WithSanitizedRenderFields].
+	sanitizedRenderFields []string
+	renderTimeout         time.Duration
+	// Converts the HTML renderings to PDF, for c.RenderPDF. See [WithPDFConverter].
+	pdfConverter PDFConverter
+	// Default and maximum number of items per page of c.Pagination. See [WithPagination].
+	pagination PaginationConfig
+
+	// Fingerprinted static files, set by [WithAssets].
+	assets *Assets
+	// Routes listed in the sitemap, see [OptionIndexable].
+	sitemap *sitemapRoutes
+
 	// Custom serializer that overrides the default one.
 	Serialize Sender
 	// Used to serialize the error response. Defaults to [SendError].
 	SerializeError ErrorSender
 
+	// Controller serving the requests matching no route. See [WithNotFoundHandler].
+	notFoundController func(ContextNoBody) (any, error)
+
 	startTime time.Time
+	// Clock of the built-in features. See [WithClock].
+	clock func() time.Time
 
 	Security Security
 
-	autoAuth AutoAuthConfig
-	fs       fs.FS
+	// Security headers configuration, set by [WithSecurityHeaders].
+	securityHeaders *SecurityHeadersOptions
+
+	autoAuth   AutoAuthConfig
+	logout     *LogoutConfig
+	magicLinks *magicLinks
+	fs         fs.FS
 
 	// Base path of the group
 	basePath string
@@ -61,11 +102,57 @@ type Server struct {
 	middlewares []func(http.Handler) http.Handler
 
 	maxBodySize int64
+	// Options of the JSON decoder of the request bodies. See [WithJSONDecoderOptions].
+	jsonDecoderOptions JSONDecoderOptions
+
+	// Custom request body decoders, by media type. See [WithBodyDecoder].
+	bodyDecoders map[string]BodyDecoder
+	// Layouts used to decode time.Time form fields. See [WithFormTimeLayouts].
+	formTimeLayouts []string
 	// If true, the server will return an error if the request body contains unknown fields. Useful for quick debugging in development.
 	DisallowUnknownFields  bool
 	disableStartupMessages bool
 	disableAutoGroupTags   bool
 	isTLS                  bool
+	// If true, the panics propagate to the [http.Server]. See [WithoutPanicRecovery].
+	disablePanicRecovery bool
+	// If true, [Server.Run] fails when the route declarations are invalid. See [WithStrictRouteValidation].
+	strictRouteValidation bool
+	// If true, the required parameters of the net/http handlers are checked. See [WithStdParamsValidation].
+	stdParamsValidation bool
+	// Configuration of the routes introspection endpoint. See [WithRoutesIntrospection].
+	routesIntrospection *RoutesIntrospectionConfig
+	// Configuration of the rate limiting. See [WithRateLimit].
+	rateLimit *RateLimitConfig
+	// Sheds the requests of the lowest priorities when the server is overloaded. See [WithLoadShedding].
+	loadShedder *loadShedder
+	// If true, the local server is not added to the servers of the OpenAPI spec. See [WithOpenAPIServers].
+	openAPIServersDeclared bool
+	// URL of the server advertised in the OpenAPI spec instead of the local address. See [WithPublicURL].
+	publicURL string
+	// Local server added to the OpenAPI spec on [Server.Run], replaced by the forwarded URL for
+	// the requests of trusted proxies. See [Server.specHandler].
+	localOpenAPIServer *openapi3.Server
+	// Spec served instead of the generated one, and the error met when loading it. See [WithEmbeddedSpec].
+	embeddedSpec    *openapi3.T
+	embeddedSpecErr error
+
+	// Environment of the server. See [WithEnvironment].
+	environment Environment
+	// Patterns of the templates loaded with [WithTemplateGlobs].
+	templatePatterns []string
+	// If true, the templates loaded with [WithTemplateGlobs] are parsed again on each request.
+	reloadTemplates bool
+
+	// Plugins installed on the server, the ones started by [Server.Run], and the error met when installing them. See [WithPlugins].
+	plugins        []Plugin
+	startedPlugins []Plugin
+	pluginsErr     error
+
+	// Callbacks run by [Server.Shutdown], shared with the groups. See [Server.OnShutdown].
+	shutdownHooks *shutdownHooks
+	// Time allowed to the requests in progress when [Server.Run] receives a signal. See [WithShutdownTimeout].
+	shutdownTimeout time.Duration
 }
 
 // NewServer creates a new server with the given options.
@@ -82,6 +169,7 @@ type Server struct {
 // Some options are at engine level, and can be set with [WithEngineOptions].
 // Some default options are set in the function body.
 func NewServer(options ...func(*Server)) *Server {
+	mux := http.NewServeMux()
 	s := &Server{
 		Server: &http.Server{
 			ReadTimeout:       30 * time.Second,
@@ -89,12 +177,17 @@ func NewServer(options ...func(*Server)) *Server {
 			WriteTimeout:      30 * time.Second,
 			IdleTimeout:       30 * time.Second,
 		},
-		Mux:    http.NewServeMux(),
+		Mux:    mux,
+		router: mux,
 		Engine: NewEngine(),
 
 		Security: NewSecurity(),
 
-		loggingConfig: defaultLoggingConfig,
+		loggingConfig:   defaultLoggingConfig,
+		clock:           time.Now,
+		sitemap:         &sitemapRoutes{},
+		shutdownHooks:   &shutdownHooks{},
+		shutdownTimeout: 30 * time.Second,
 	}
 
 	// Default options that can be overridden
@@ -113,7 +206,8 @@ func NewServer(options ...func(*Server)) *Server {
 		option(s)
 	}
 
-	s.startTime = time.Now()
+	s.routes = newRouteTable(s.router)
+	s.startTime = s.now()
 
 	if s.autoAuth.Enabled {
 		Post(s, "/auth/login", s.Security.LoginHandler(s.autoAuth.VerifyUserInfo),
@@ -135,20 +229,60 @@ func NewServer(options ...func(*Server)) *Server {
 		)
 	}
 
+	s.registerMagicLink()
+	s.registerLogout()
+	s.registerRoutesIntrospection()
+	s.registerAssets()
+
 	if !s.loggingConfig.Disabled() {
 		s.middlewares = append(s.middlewares, newDefaultLogger(s).middleware)
 	}
 
+	s.installPlugins()
+
 	return s
 }
 
 func (s *Server) SpecHandler(_ *Engine) {
-	Get(s, s.OpenAPIConfig.SpecURL, s.Engine.SpecHandler(), OptionHide())
+	Get(s, s.OpenAPIConfig.SpecURL, s.specHandler(), OptionHide())
 	s.printOpenAPIMessage(fmt.Sprintf("JSON spec: %s%s", s.url(), s.OpenAPIConfig.SpecURL))
 }
 
+// specHandler serves the spec. For the requests coming from trusted proxies, the local server
+// is replaced by the public URL of the server, derived from the forwarded headers.
+func (s *Server) specHandler() func(c ContextNoBody) (openapi3.T, error) {
+	spec := s.Engine.SpecHandler()
+	return func(c ContextNoBody) (openapi3.T, error) {
+		if s.embeddedSpec != nil {
+			return *s.embeddedSpec, nil
+		}
+		document, err := spec(c)
+		if err != nil || s.localOpenAPIServer == nil {
+			return document, err
+		}
+
+		url := forwardedURL(c.Request(), s.trustedProxies)
+		if url == "" {
+			return document, nil
+		}
+		document.Servers = slices.Clone(document.Servers)
+		for i, server := range document.Servers {
+			if server == s.localOpenAPIServer {
+				document.Servers[i] = &openapi3.Server{URL: url, Description: "public server"}
+			}
+		}
+		return document, nil
+	}
+}
+
 func (s *Server) UIHandler(_ *Engine) {
-	GetStd(s, s.OpenAPIConfig.SwaggerURL+"/", s.OpenAPIConfig.UIHandler(s.OpenAPIConfig.SpecURL).ServeHTTP, OptionHide())
+	options := []func(*BaseRoute){OptionHide()}
+	if s.securityHeaders != nil {
+		options = append(options, OptionSecurityHeaders(SecurityHeadersOptions{
+			ContentSecurityPolicy: openAPIUIContentSecurityPolicy,
+		}))
+	}
+	GetStd(s, s.OpenAPIConfig.SwaggerURL+"/", s.OpenAPIConfig.UIHandler(s.OpenAPIConfig.SpecURL).ServeHTTP, options...)
 	s.printOpenAPIMessage(fmt.Sprintf("OpenAPI UI: %s%s/index.html", s.url(), s.OpenAPIConfig.SwaggerURL))
 }
 
@@ -173,7 +307,7 @@ func WithTemplateFS(fs fs.FS) func(*Server) {
 // Global Middlewares are mounted on the [http.Server] Handler, when executing [Server.Run].
 // Route Middlewares are mounted directly on [http.ServeMux] added at route registration.
 //
-// For example, to add CORS middleware:
+// For example, to add a CORS middleware other than the built-in [WithCORS]:
 //
 //	import "github.com/rs/cors"
 //
@@ -193,7 +327,7 @@ func WithGlobalMiddlewares(middlewares ...func(http.Handler) http.Handler) func(
 
 // WithCorsMiddleware adds CORS middleware to the server.
 //
-// Deprecated: Please use [WithGlobalMiddlewares] instead.
+// Deprecated: Please use [WithCORS] or [WithGlobalMiddlewares] instead.
 func WithCorsMiddleware(corsMiddleware func(http.Handler) http.Handler) func(*Server) {
 	return WithGlobalMiddlewares(corsMiddleware)
 }
@@ -243,6 +377,57 @@ func WithSecurity(schemes openapi3.SecuritySchemes) func(*Server) {
 	}
 }
 
+// WithOpenAPIServers declares the servers of the OpenAPI spec, like the staging and production base URLs,
+// used by the OpenAPI UI to send the requests. The local server is no longer added to the spec on [Server.Run]:
+// its address is not reachable by the clients behind a reverse proxy.
+//
+//	s := fuego.NewServer(
+//		fuego.WithOpenAPIServers(
+//			&openapi3.Server{URL: "https://api.example.com/v1", Description: "Production"},
+//			&openapi3.Server{
+//				URL:         "https://{region}.staging.example.com/v1",
+//				Description: "Staging",
+//				Variables: map[string]*openapi3.ServerVariable{
+//					"region": {Default: "eu", Enum: []string{"eu", "us"}},
+//				},
+//			},
+//		),
+//	)
+func WithOpenAPIServers(servers ...*openapi3.Server) func(*Server) {
+	return func(s *Server) {
+		s.openAPIServersDeclared = true
+		s.OpenAPI.Description().Servers = append(s.OpenAPI.Description().Servers, servers...)
+	}
+}
+
+// WithPublicURL sets the URL of the server advertised in the OpenAPI spec, like "https://api.example.com",
+// instead of its local address, so that the requests sent from the OpenAPI UI reach the server
+// behind a load balancer. Without it, the URL is derived from the X-Forwarded-Proto and X-Forwarded-Host
+// headers of the requests coming from trusted proxies, see [WithTrustedProxies].
+func WithPublicURL(url string) func(*Server) {
+	return func(s *Server) { s.publicURL = strings.TrimSuffix(url, "/") }
+}
+
+// WithTagExternalDocs links a tag to external documentation, like a guide or a runbook,
+// in the externalDocs field of the tag at the root of the spec. The description is optional.
+//
+//	s := fuego.NewServer(
+//		fuego.WithTagExternalDocs("payments", "https://docs.example.com/payments", "Payments guide"),
+//	)
+func WithTagExternalDocs(tag, url, description string) func(*Server) {
+	return func(s *Server) {
+		externalDocs := &openapi3.ExternalDocs{URL: url, Description: description}
+		if existing := s.OpenAPI.Description().Tags.Get(tag); existing != nil {
+			existing.ExternalDocs = externalDocs
+			return
+		}
+		s.OpenAPI.Description().Tags = append(s.OpenAPI.Description().Tags, &openapi3.Tag{
+			Name:         tag,
+			ExternalDocs: externalDocs,
+		})
+	}
+}
+
 // WithoutAutoGroupTags disables the automatic grouping of routes by tags.
 // By default, routes are tagged by group.
 // For example:
@@ -290,11 +475,33 @@ func WithTemplateGlobs(patterns ...string) func(*Server) {
 			slog.Error("Error loading templates", "error", err)
 			panic(err)
 		}
+		s.templatePatterns = patterns
 
 		slog.Debug("Loaded templates", "templates", s.template.DefinedTemplates())
 	}
 }
 
+// WithSanitizedRenderFields sanitizes the given fields of the data passed to c.Render
+// with [SafeHTML] before rendering, to reduce the XSS risk of user-generated HTML.
+// The fields are the keys of a map (like [H]) or the names of the struct fields.
+// Only the values of type [template.HTML] are sanitized: strings are already escaped by html/template.
+// For example:
+//
+//	WithSanitizedRenderFields("Bio", "Comment")
+func WithSanitizedRenderFields(fields ...string) func(*Server) {
+	return func(s *Server) { s.sanitizedRenderFields = append(s.sanitizedRenderFields, fields...) }
+}
+
+// WithRenderTimeout sets the maximum duration of each template rendered with c.Render.
+// A rendering that takes longer stops writing and returns a 503 Service Unavailable,
+// like a rendering aborted by a client disconnection returns a 499 Client Closed Request.
+// For example:
+//
+//	WithRenderTimeout(2 * time.Second)
+func WithRenderTimeout(timeout time.Duration) func(*Server) {
+	return func(s *Server) { s.renderTimeout = timeout }
+}
+
 func WithBasePath(basePath string) func(*Server) {
 	return func(c *Server) { c.basePath = basePath }
 }
@@ -303,6 +510,72 @@ func WithMaxBodySize(maxBodySize int64) func(*Server) {
 	return func(c *Server) { c.maxBodySize = maxBodySize }
 }
 
+// WithJSONDecoderOptions sets the options of the JSON decoder of the request bodies,
+// for example to keep the precision of the int64 IDs decoded into any, or to limit the nesting depth of the bodies.
+//
+//	s := fuego.NewServer(
+//		fuego.WithJSONDecoderOptions(fuego.JSONDecoderOptions{UseNumber: true, DisallowNaN: true, MaxDepth: 20}),
+//	)
+func WithJSONDecoderOptions(options JSONDecoderOptions) func(*Server) {
+	return func(s *Server) { s.jsonDecoderOptions = options }
+}
+
+// WithBodyDecoder registers a request body decoder for the given media type, for all routes.
+// Bodies decoded with it are transformed and validated like JSON bodies.
+// Use [OptionBodyDecoder] to register a decoder for a single route.
+// For example:
+//
+//	s := fuego.NewServer(
+//		fuego.WithBodyDecoder("application/vnd.custom+json", func(r io.Reader, dst any) error {
+//			return custom.NewDecoder(r).Decode(dst)
+//		}),
+//	)
+func WithBodyDecoder(mediaType string, decoder BodyDecoder) func(*Server) {
+	return func(c *Server) {
+		if c.bodyDecoders == nil {
+			c.bodyDecoders = make(map[string]BodyDecoder)
+		}
+		c.bodyDecoders[strings.ToLower(mediaType)] = decoder
+	}
+}
+
+// WithNotFoundHandler sets the controller serving the requests matching no route,
+// instead of the plain-text 404 of the mux. The response goes through the usual flow:
+// errors are serialized with the server error format (see [WithErrorSerializer]),
+// and HTML pages can be rendered for template apps. The default status code is 404.
+// For example:
+//
+//	fuego.WithNotFoundHandler(fuego.NotFoundController)
+//
+// or, to render a page:
+//
+//	fuego.WithNotFoundHandler(func(c fuego.ContextNoBody) (any, error) {
+//		return c.Render("404.page.html", fuego.H{"Path": c.Request().URL.Path})
+//	})
+func WithNotFoundHandler(controller func(c ContextNoBody) (any, error)) func(*Server) {
+	return func(s *Server) { s.notFoundController = controller }
+}
+
+// NotFoundController is a controller returning a 404 [NotFoundError] for the requested path.
+// See [WithNotFoundHandler].
+func NotFoundController(c ContextNoBody) (any, error) {
+	return nil, NotFoundError{
+		Title:  "Not Found",
+		Detail: fmt.Sprintf("no route matches %s %s", c.Request().Method, c.Request().URL.Path),
+		Err:    errors.New("route not found"),
+	}
+}
+
+// WithFormTimeLayouts sets the layouts used to decode the time.Time fields of form bodies.
+// They are tried in order, before the default ones: RFC 3339 and the formats of the HTML
+// datetime-local, date and time inputs.
+// For example, to accept French dates:
+//
+//	WithFormTimeLayouts("02/01/2006")
+func WithFormTimeLayouts(layouts ...string) func(*Server) {
+	return func(c *Server) { c.formTimeLayouts = append(c.formTimeLayouts, layouts...) }
+}
+
 func WithAutoAuth(verifyUserInfo func(user, password string) (jwt.Claims, error)) func(*Server) {
 	return func(c *Server) {
 		c.autoAuth.Enabled = true
@@ -366,6 +639,13 @@ func WithoutStartupMessages() func(*Server) {
 	}
 }
 
+// WithStrictRouteValidation makes [Server.Run] return the [RouteValidationError]
+// listing the invalid route declarations, instead of only logging it.
+// See [Engine.ValidateRoutes] for the checks performed.
+func WithStrictRouteValidation() func(*Server) {
+	return func(s *Server) { s.strictRouteValidation = true }
+}
+
 // WithoutLogger disables the default logger.
 func WithoutLogger() func(*Server) {
 	return func(*Server) {
@@ -433,12 +713,28 @@ func WithValidator(newValidator *validator.Validate) func(*Server) {
 	}
 }
 
+// WithRouteOptions applies the options to all the routes of the server, like global parameters.
+// Groups inherit them, and the options of the groups and of the routes are applied after them:
+// redeclaring a parameter in a group or a route overrides the global one.
+//
+//	s := fuego.NewServer(
+//		fuego.WithRouteOptions(
+//			option.Header("X-Request-ID", "Request ID", param.Required()),
+//		),
+//	)
 func WithRouteOptions(options ...func(*BaseRoute)) func(*Server) {
 	return func(s *Server) {
 		s.routeOptions = append(s.routeOptions, options...)
 	}
 }
 
+// WithStdParamsValidation checks the required parameters of the routes registered with net/http handlers,
+// like [GetStd] or [PostStd], before calling them. Fuego controllers always check them.
+// Missing parameters are reported with a 400 Bad Request error.
+func WithStdParamsValidation() func(*Server) {
+	return func(s *Server) { s.stdParamsValidation = true }
+}
+
 // WithLoggingMiddleware configures the default logging middleware for the server.
 func WithLoggingMiddleware(loggingConfig LoggingConfig) func(*Server) {
 	return func(s *Server) {
@@ -1,11 +1,13 @@
 package fuego
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -286,6 +288,107 @@ func TestWithoutStartupMessages(t *testing.T) {
 	require.True(t, s.Engine.OpenAPIConfig.DisableMessages)
 }
 
+func TestWithOpenAPIServers(t *testing.T) {
+	newServer := func(t *testing.T, options ...func(*Server)) *Server {
+		t.Helper()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		s := NewServer(append([]func(*Server){WithoutLogger(), WithListener(listener)}, options...)...)
+		require.NoError(t, s.setup())
+		return s
+	}
+
+	t.Run("local server by default", func(t *testing.T) {
+		s := newServer(t)
+		servers := s.OpenAPI.Description().Servers
+		require.Len(t, servers, 1)
+		require.Equal(t, "local server", servers[0].Description)
+	})
+
+	t.Run("declared servers replace the local server", func(t *testing.T) {
+		production := &openapi3.Server{URL: "https://api.example.com", Description: "Production"}
+		staging := &openapi3.Server{
+			URL:         "https://{region}.staging.example.com",
+			Description: "Staging",
+			Variables: map[string]*openapi3.ServerVariable{
+				"region": {Default: "eu", Enum: []string{"eu", "us"}},
+			},
+		}
+		s := newServer(t, WithOpenAPIServers(production, staging))
+		require.Equal(t, openapi3.Servers{production, staging}, s.OpenAPI.Description().Servers)
+		require.NoError(t, s.OpenAPI.Description().Servers.Validate(context.Background()))
+	})
+}
+
+func TestWithPublicURL(t *testing.T) {
+	serveSpec := func(s *Server, r *http.Request) openapi3.Servers {
+		w := httptest.NewRecorder()
+		s.Handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var document openapi3.T
+		require.NoError(t, document.UnmarshalJSON(w.Body.Bytes()))
+		return document.Servers
+	}
+	setup := func(t *testing.T, options ...func(*Server)) *Server {
+		t.Helper()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+
+		s := NewServer(append([]func(*Server){WithoutLogger(), WithListener(listener)}, options...)...)
+		require.NoError(t, s.setup())
+		return s
+	}
+
+	t.Run("explicit public URL", func(t *testing.T) {
+		s := setup(t, WithPublicURL("https://api.example.com/"))
+		servers := serveSpec(s, httptest.NewRequest(http.MethodGet, "/swagger/openapi.json", nil))
+		require.Len(t, servers, 1)
+		require.Equal(t, "https://api.example.com", servers[0].URL)
+	})
+
+	s := setup(t, WithTrustedProxies("10.0.0.0/8"))
+	forwarded := func(remoteAddr string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/swagger/openapi.json", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-Forwarded-Proto", "https")
+		r.Header.Set("X-Forwarded-Host", "api.example.com")
+		return r
+	}
+
+	t.Run("derived from the forwarded headers of trusted proxies", func(t *testing.T) {
+		servers := serveSpec(s, forwarded("10.0.0.1:1234"))
+		require.Len(t, servers, 1)
+		require.Equal(t, "https://api.example.com", servers[0].URL)
+	})
+
+	t.Run("local address for other clients", func(t *testing.T) {
+		servers := serveSpec(s, forwarded("203.0.113.1:1234"))
+		require.Len(t, servers, 1)
+		require.Equal(t, s.url(), servers[0].URL)
+		require.Equal(t, s.url(), s.OpenAPI.Description().Servers[0].URL)
+	})
+}
+
+func TestWithTagExternalDocs(t *testing.T) {
+	s := NewServer(
+		WithTagExternalDocs("payments", "https://docs.example.com/payments", "Payments guide"),
+		WithTagExternalDocs("payments", "https://docs.example.com/payments/v2", ""),
+		WithTagExternalDocs("runbooks", "https://runbooks.example.com", ""),
+	)
+	Get(s, "/payments", controller, OptionTags("payments"))
+	Get(s, "/pets", controller, OptionTags("pets"))
+
+	document := s.OutputOpenAPISpec()
+
+	require.Equal(t, []string{"payments", "pets", "runbooks"}, []string{document.Tags[0].Name, document.Tags[1].Name, document.Tags[2].Name})
+	require.Equal(t, &openapi3.ExternalDocs{URL: "https://docs.example.com/payments/v2"}, document.Tags.Get("payments").ExternalDocs)
+	require.Nil(t, document.Tags.Get("pets").ExternalDocs)
+}
+
 func TestWithoutAutoGroupTags(t *testing.T) {
 	s := NewServer(
 		WithoutAutoGroupTags(),
@@ -451,6 +554,106 @@ func TestGroupParamsInMainServerInstance(t *testing.T) {
 	require.Equal(t, "test-value", route.Operation.Parameters.GetByInAndName("header", "X-Test-Header").Description)
 }
 
+func TestGlobalParamsInheritedBySiblingGroups(t *testing.T) {
+	s := NewServer(
+		WithRouteOptions(
+			OptionHeader("X-Global", "global"),
+		),
+	)
+	users := Group(s, "/users")
+	pets := Group(s, "/pets")
+
+	usersRoute := Get(users, "/", controller)
+	petsRoute := Get(pets, "/", controller)
+
+	require.NotNil(t, usersRoute.Operation.Parameters.GetByInAndName("header", "X-Global"))
+	require.NotNil(t, petsRoute.Operation.Parameters.GetByInAndName("header", "X-Global"))
+	require.Equal(t, []string{"users"}, usersRoute.Operation.Tags)
+	require.Equal(t, []string{"pets"}, petsRoute.Operation.Tags)
+}
+
+func TestParamsOverrideOrder(t *testing.T) {
+	s := NewServer(
+		WithRouteOptions(
+			OptionHeader("X-Org", "server", ParamRequired()),
+			OptionQuery("lang", "server"),
+		),
+	)
+	group := Group(s, "/api", OptionHeader("X-Org", "group"))
+
+	route := Get(group, "/test", controller, OptionQuery("lang", "route"))
+	groupRoute := Get(group, "/other", controller)
+
+	countParams := func(params openapi3.Parameters, name string) int {
+		count := 0
+		for _, param := range params {
+			if param.Value.Name == name {
+				count++
+			}
+		}
+		return count
+	}
+
+	require.Equal(t, "group", route.Operation.Parameters.GetByInAndName("header", "X-Org").Description)
+	require.False(t, route.Params["X-Org"].Required, "the group declaration replaces the server one")
+	require.Equal(t, "route", route.Operation.Parameters.GetByInAndName("query", "lang").Description)
+	require.Equal(t, "server", groupRoute.Operation.Parameters.GetByInAndName("query", "lang").Description)
+	require.Equal(t, 1, countParams(route.Operation.Parameters, "X-Org"))
+	require.Equal(t, 1, countParams(route.Operation.Parameters, "lang"))
+	require.Equal(t, 1, countParams(route.Operation.Parameters, "Accept"))
+
+	acceptRoute := Get(s, "/accept", controller, OptionHeader("Accept", "Response format"))
+	require.Equal(t, "Response format", acceptRoute.Operation.Parameters.GetByInAndName("header", "Accept").Description)
+}
+
+func TestGlobalParamsValidation(t *testing.T) {
+	stdController := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	globalParam := WithRouteOptions(OptionHeader("X-Org", "Organization", ParamRequired()))
+
+	t.Run("fuego controllers check the global params", func(t *testing.T) {
+		s := NewServer(globalParam)
+		Get(Group(s, "/api"), "/test", controller)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "X-Org is a required header")
+
+		r.Header.Set("X-Org", "acme")
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("net/http handlers are not checked by default", func(t *testing.T) {
+		s := NewServer(globalParam)
+		GetStd(s, "/test", stdController)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("net/http handlers are checked with WithStdParamsValidation", func(t *testing.T) {
+		s := NewServer(globalParam, WithStdParamsValidation())
+		GetStd(s, "/test", stdController)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "X-Org is a required header")
+
+		r := httptest.NewRequest(http.MethodGet, "/test", nil)
+		r.Header.Set("X-Org", "acme")
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
 func TestHideGroupAfterGroupParam(t *testing.T) {
 	s := NewServer()
 	group := Group(s, "/api",
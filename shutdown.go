@@ -0,0 +1,82 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// WithShutdownTimeout sets the time allowed to the requests in progress, the shutdown callbacks
+// and the plugins when [Server.Run] receives SIGINT or SIGTERM. Defaults to 30s.
+// After it, the remaining connections are closed.
+func WithShutdownTimeout(timeout time.Duration) func(*Server) {
+	return func(s *Server) { s.shutdownTimeout = timeout }
+}
+
+// shutdownHooks are the callbacks registered with [Server.OnShutdown].
+type shutdownHooks struct {
+	mu    sync.Mutex
+	hooks []func()
+}
+
+// OnShutdown registers a callback run by [Server.Shutdown] once the requests in progress are served,
+// like closing a database connection or flushing the metrics.
+// The callbacks are run in the reverse order of their registration, before the plugins are stopped.
+//
+//	db, _ := sql.Open("postgres", dsn)
+//	s.OnShutdown(func() { db.Close() })
+func (s *Server) OnShutdown(hook func()) {
+	s.shutdownHooks.mu.Lock()
+	defer s.shutdownHooks.mu.Unlock()
+	s.shutdownHooks.hooks = append(s.shutdownHooks.hooks, hook)
+}
+
+// Shutdown gracefully shuts down the server, like [http.Server.Shutdown]: it stops accepting
+// new connections and waits for the requests in progress, until the context is done.
+// Then it runs the callbacks registered with [Server.OnShutdown] and stops the plugins. See [WithPlugins].
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.Server.Shutdown(ctx)
+	s.runShutdownHooks()
+	return errors.Join(err, s.stopPlugins(ctx))
+}
+
+// runShutdownHooks runs the callbacks registered with [Server.OnShutdown], once.
+func (s *Server) runShutdownHooks() {
+	s.shutdownHooks.mu.Lock()
+	hooks := s.shutdownHooks.hooks
+	s.shutdownHooks.hooks = nil
+	s.shutdownHooks.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// serveUntilSignal serves the requests until the server is shut down, or until SIGINT or SIGTERM
+// is received, then shuts the server down gracefully. A second signal stops the process immediately.
+func (s *Server) serveUntilSignal(serve func() error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() { served <- serve() }()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+		stop()
+		slog.Info("Shutting down the server", "timeout", s.shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			return errors.Join(err, s.Server.Close())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package fuego
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Run("runs the callbacks in the reverse order, once", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		journal := []string{}
+		s.OnShutdown(func() { journal = append(journal, "close db") })
+		Group(s, "/admin").OnShutdown(func() { journal = append(journal, "flush metrics") })
+
+		require.NoError(t, s.Shutdown(context.Background()))
+		require.Equal(t, []string{"flush metrics", "close db"}, journal)
+
+		require.NoError(t, s.Shutdown(context.Background()))
+		require.Len(t, journal, 2)
+	})
+
+	t.Run("Run drains the requests in progress on SIGTERM", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("signals cannot be sent on Windows")
+		}
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		s := NewServer(WithoutLogger(), WithoutStartupMessages(), WithListener(listener), WithShutdownTimeout(5*time.Second))
+
+		started := make(chan struct{})
+		Get(s, "/slow", func(c ContextNoBody) (string, error) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			return "done", nil
+		})
+		closed := false
+		s.OnShutdown(func() { closed = true })
+
+		ran := make(chan error, 1)
+		go func() { ran <- s.Run() }()
+
+		url := "http://" + listener.Addr().String() + "/slow"
+		responses := make(chan string, 1)
+		go func() {
+			res, err := http.Get(url)
+			if err != nil {
+				responses <- err.Error()
+				return
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+			responses <- string(body)
+		}()
+
+		<-started
+		process, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, process.Signal(syscall.SIGTERM))
+
+		require.Equal(t, "done", <-responses)
+		require.NoError(t, <-ran)
+		require.True(t, closed)
+
+		_, err = http.Get(url)
+		require.Error(t, err, "no longer accepting connections")
+	})
+}
@@ -0,0 +1,217 @@
+package fuego
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMissingSignature is returned when the signature header is missing.
+	ErrMissingSignature = errors.New("missing signature")
+	// ErrInvalidSignature is returned when the signature does not match the request body.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrSignatureExpired is returned when the signature timestamp is outside of the tolerance.
+	ErrSignatureExpired = errors.New("signature timestamp outside of tolerance")
+)
+
+// SignatureScheme verifies the signature of a request, typically a webhook.
+// See [HMACSignature], [GitHubSignature] and [StripeSignature].
+type SignatureScheme interface {
+	// VerifySignature checks the signature of the request against its raw body.
+	VerifySignature(r *http.Request, body []byte, secret []byte) error
+}
+
+// SecretLookup returns the secret used to verify the signature of a request.
+// It can be used to select the secret of a tenant, or to fetch it from a secret store.
+type SecretLookup func(r *http.Request) ([]byte, error)
+
+// StaticSecret returns a [SecretLookup] that always returns the given secret.
+func StaticSecret(secret string) SecretLookup {
+	return func(*http.Request) ([]byte, error) {
+		return []byte(secret), nil
+	}
+}
+
+// HMACSignature is a HMAC-SHA256 signature of the raw body, hex-encoded in a header.
+type HMACSignature struct {
+	// Header containing the signature.
+	Header string
+	// Prefix of the header value, stripped before comparing the signature. For example "sha256=".
+	Prefix string
+}
+
+var _ SignatureScheme = HMACSignature{}
+
+func (s HMACSignature) VerifySignature(r *http.Request, body []byte, secret []byte) error {
+	value := r.Header.Get(s.Header)
+	if value == "" {
+		return fmt.Errorf("%w: header %s", ErrMissingSignature, s.Header)
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(value, s.Prefix))
+	if err != nil || !hmac.Equal(signature, computeHMACSHA256(secret, body)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// GitHubSignature is the signature scheme used by GitHub webhooks (X-Hub-Signature-256 header).
+var GitHubSignature = HMACSignature{
+	Header: "X-Hub-Signature-256",
+	Prefix: "sha256=",
+}
+
+// StripeSignature is the signature scheme used by Stripe webhooks.
+// The Stripe-Signature header contains a timestamp and one or more HMAC-SHA256 signatures
+// of "<timestamp>.<body>", for example "t=1492774577,v1=5257a869...".
+type StripeSignature struct {
+	// Maximum age of the signature timestamp, to prevent replay attacks. Defaults to 5 minutes.
+	// A negative value disables the check.
+	Tolerance time.Duration
+
+	// Clock of the timestamp checks. Set to the clock of the server by [OptionVerifySignature], see [WithClock].
+	now func() time.Time
+}
+
+var _ SignatureScheme = StripeSignature{}
+
+func (s StripeSignature) VerifySignature(r *http.Request, body []byte, secret []byte) error {
+	value := r.Header.Get("Stripe-Signature")
+	if value == "" {
+		return fmt.Errorf("%w: header Stripe-Signature", ErrMissingSignature)
+	}
+
+	timestamp := ""
+	var signatures [][]byte
+	for _, part := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = val
+		case "v1":
+			signature, err := hex.DecodeString(val)
+			if err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed Stripe-Signature header", ErrInvalidSignature)
+	}
+
+	if err := checkSignatureTimestamp(unix, s.Tolerance, s.now); err != nil {
+		return err
+	}
+
+	expected := computeHMACSHA256(secret, append([]byte(timestamp+"."), body...))
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// checkSignatureTimestamp returns [ErrSignatureExpired] if the Unix timestamp is outside of the tolerance,
+// 5 minutes if zero. A negative tolerance disables the check.
+func checkSignatureTimestamp(unix int64, tolerance time.Duration, now func() time.Time) error {
+	if tolerance == 0 {
+		tolerance = 5 * time.Minute
+	}
+	if now == nil {
+		now = time.Now
+	}
+	if tolerance > 0 && now().Sub(time.Unix(unix, 0)).Abs() > tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func computeHMACSHA256(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// OptionVerifySignature verifies the signature of the request before the body is deserialized.
+// It is typically used to build webhook receivers.
+// The raw body is read to check the signature, then made available again to the controller.
+// Requests with a missing or invalid signature get a 401 Unauthorized error, documented in the OpenAPI spec.
+// The body is read before the request is authenticated: it is limited to the maximum body size
+// of the server (see [WithMaxBodySize], 1 MiB if unset), larger bodies get a 413 Request Entity Too Large error.
+// For example:
+//
+//	fuego.Post(s, "/webhooks/github", handleGitHubEvent,
+//		option.VerifySignature(fuego.GitHubSignature, fuego.StaticSecret(os.Getenv("GITHUB_WEBHOOK_SECRET"))),
+//	)
+func OptionVerifySignature(scheme SignatureScheme, secretLookup SecretLookup) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.signatureVerifier = &signatureVerifier{scheme: scheme, secretLookup: secretLookup}
+		OptionAddResponse(http.StatusUnauthorized, "Unauthorized _(invalid signature)_", Response{Type: HTTPError{}})(r)
+		OptionAddResponse(http.StatusRequestEntityTooLarge, "Request Entity Too Large", Response{Type: HTTPError{}})(r)
+	}
+}
+
+type signatureVerifier struct {
+	scheme       SignatureScheme
+	secretLookup SecretLookup
+}
+
+// middleware verifies the signature of the requests, whose body is limited to bodyLimit bytes.
+// The timestamps of the built-in schemes are checked against now, unless they have their own clock.
+func (v signatureVerifier) middleware(bodyLimit int64, now func() time.Time) func(http.Handler) http.Handler {
+	if bodyLimit <= 0 {
+		bodyLimit = maxBodySize
+	}
+	switch scheme := v.scheme.(type) {
+	case StripeSignature:
+		if scheme.now == nil {
+			scheme.now = now
+		}
+		v.scheme = scheme
+	case WebhookSignatureScheme:
+		if scheme.now == nil {
+			scheme.now = now
+		}
+		v.scheme = scheme
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, bodyLimit))
+			if err != nil {
+				SendError(w, r, bodyError(err, "Bad Request", "cannot read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			secret, err := v.secretLookup(r)
+			if err == nil {
+				err = v.scheme.VerifySignature(r, body, secret)
+			}
+			if err != nil {
+				SendError(w, r, UnauthorizedError{
+					Title:  "Unauthorized",
+					Status: http.StatusUnauthorized,
+					Err:    err,
+					Detail: "invalid request signature",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
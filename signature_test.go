@@ -0,0 +1,164 @@
+package fuego
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type webhookEvent struct {
+	Action string `json:"action"`
+}
+
+func hmacHex(secret, payload string) string {
+	return hex.EncodeToString(computeHMACSHA256([]byte(secret), []byte(payload)))
+}
+
+func TestGitHubSignature(t *testing.T) {
+	body := `{"action":"opened"}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	t.Run("missing header", func(t *testing.T) {
+		err := GitHubSignature.VerifySignature(r, []byte(body), []byte("secret"))
+		require.ErrorIs(t, err, ErrMissingSignature)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		r.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex("secret", body))
+		require.NoError(t, GitHubSignature.VerifySignature(r, []byte(body), []byte("secret")))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		r.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex("other", body))
+		require.ErrorIs(t, GitHubSignature.VerifySignature(r, []byte(body), []byte("secret")), ErrInvalidSignature)
+	})
+
+	t.Run("not hex", func(t *testing.T) {
+		r.Header.Set("X-Hub-Signature-256", "sha256=zzz")
+		require.ErrorIs(t, GitHubSignature.VerifySignature(r, []byte(body), []byte("secret")), ErrInvalidSignature)
+	})
+}
+
+func TestStripeSignature(t *testing.T) {
+	body := `{"type":"charge.succeeded"}`
+	now := time.Unix(1700000000, 0)
+	scheme := StripeSignature{now: func() time.Time { return now }}
+
+	signedHeader := func(timestamp time.Time, secret string) string {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		return "t=" + ts + ",v1=" + hmacHex(secret, ts+"."+body)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr error
+	}{
+		{"valid", signedHeader(now, "whsec"), nil},
+		{"valid with multiple signatures", signedHeader(now, "old") + ",v1=" + strings.TrimPrefix(strings.Split(signedHeader(now, "whsec"), ",")[1], "v1="), nil},
+		{"missing", "", ErrMissingSignature},
+		{"wrong secret", signedHeader(now, "other"), ErrInvalidSignature},
+		{"malformed", "v1=abcd", ErrInvalidSignature},
+		{"too old", signedHeader(now.Add(-10*time.Minute), "whsec"), ErrSignatureExpired},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.header != "" {
+				r.Header.Set("Stripe-Signature", tc.header)
+			}
+			err := scheme.VerifySignature(r, []byte(body), []byte("whsec"))
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+
+	t.Run("tolerance disabled", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Stripe-Signature", signedHeader(now.Add(-24*time.Hour), "whsec"))
+		scheme := StripeSignature{Tolerance: -1, now: func() time.Time { return now }}
+		require.NoError(t, scheme.VerifySignature(r, []byte(body), []byte("whsec")))
+	})
+}
+
+func TestOptionVerifySignature(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Post(s, "/webhook", func(c ContextWithBody[webhookEvent]) (string, error) {
+		event, err := c.Body()
+		if err != nil {
+			return "", err
+		}
+		return event.Action, nil
+	}, OptionVerifySignature(GitHubSignature, StaticSecret("secret")))
+
+	body := `{"action":"opened"}`
+
+	t.Run("valid signature, body is still readable", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex("secret", body))
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "opened", w.Body.String())
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex("secret", `{"action":"closed"}`))
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Body.String(), "invalid request signature")
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		s := NewServer(WithoutLogger(), WithMaxBodySize(16))
+		Post(s, "/webhook", func(c ContextNoBody) (string, error) {
+			return "unreachable", nil
+		}, OptionVerifySignature(GitHubSignature, StaticSecret("secret")))
+
+		large := strings.Repeat("a", 1000)
+		r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(large))
+		r.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex("secret", large))
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Contains(t, w.Body.String(), "larger than 16 bytes")
+	})
+
+	t.Run("timestamps checked against the clock of the server", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		s := NewServer(WithoutLogger(), WithClock(func() time.Time { return now }))
+		Post(s, "/stripe", func(c ContextNoBody) (string, error) {
+			return "ok", nil
+		}, OptionVerifySignature(StripeSignature{}, StaticSecret("whsec")))
+
+		ts := strconv.FormatInt(now.Unix(), 10)
+		r := httptest.NewRequest(http.MethodPost, "/stripe", strings.NewReader(body))
+		r.Header.Set("Stripe-Signature", "t="+ts+",v1="+hmacHex("whsec", ts+"."+body))
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("401 is documented", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/webhook").Post
+		require.NotNil(t, operation.Responses.Value("401"))
+	})
+}
@@ -0,0 +1,179 @@
+package fuego
+
+import (
+	"context"
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChangeFreq is how frequently a page is likely to change, a hint for the crawlers. See [OptionIndexable].
+type ChangeFreq string
+
+const (
+	ChangeFreqAlways  ChangeFreq = "always"
+	ChangeFreqHourly  ChangeFreq = "hourly"
+	ChangeFreqDaily   ChangeFreq = "daily"
+	ChangeFreqWeekly  ChangeFreq = "weekly"
+	ChangeFreqMonthly ChangeFreq = "monthly"
+	ChangeFreqYearly  ChangeFreq = "yearly"
+	ChangeFreqNever   ChangeFreq = "never"
+)
+
+// SitemapEntry is a page listed in the sitemap. See [Sitemap].
+type SitemapEntry struct {
+	// Path of the page, like "/recipes/42", or its absolute URL.
+	Loc string
+	// Last modification of the page, if not zero.
+	LastMod    time.Time
+	ChangeFreq ChangeFreq
+	// Priority of the page relative to the other pages of the site, between 0 and 1.
+	// Omitted if 0: the crawlers then use 0.5.
+	Priority float64
+}
+
+// SitemapConfig configures the sitemap served by [Sitemap].
+type SitemapConfig struct {
+	// Base URL of the pages, like "https://example.com".
+	// Defaults to the public URL of the server, from the forwarded headers of the trusted proxies, or the Host header.
+	BaseURL string
+	// Path of the sitemap. Defaults to "/sitemap.xml".
+	Path string
+	// Returns the dynamic pages, like the page of each recipe, listed after the indexable routes.
+	Entries func(ctx context.Context) ([]SitemapEntry, error)
+}
+
+// OptionIndexable lists the route in the sitemap served by [Sitemap], with the given priority
+// (between 0 and 1, 0 to omit it) and change frequency.
+// Only GET routes without path parameters can be listed: list the other pages with [SitemapConfig.Entries].
+//
+//	fuego.Get(s, "/", homePage, option.Indexable(1, fuego.ChangeFreqDaily))
+//	fuego.Get(s, "/about", aboutPage, option.Indexable(0.3, fuego.ChangeFreqYearly))
+func OptionIndexable(priority float64, changeFreq ChangeFreq) func(*BaseRoute) {
+	if priority < 0 || priority > 1 {
+		panic("sitemap priority must be between 0 and 1")
+	}
+	return func(r *BaseRoute) {
+		r.Indexable = &SitemapEntry{Priority: priority, ChangeFreq: changeFreq}
+	}
+}
+
+// sitemapRoutes are the indexable routes, shared by the server and its groups.
+type sitemapRoutes struct {
+	mu      sync.Mutex
+	entries []SitemapEntry
+}
+
+// add lists the route in the sitemap, if it is indexable.
+func (routes *sitemapRoutes) add(route BaseRoute) {
+	if route.Indexable == nil {
+		return
+	}
+	if route.Method != http.MethodGet || strings.Contains(route.Path, "{") {
+		slog.Warn("Only GET routes without path parameters can be listed in the sitemap", "method", route.Method, "path", route.Path)
+		return
+	}
+
+	entry := *route.Indexable
+	entry.Loc = route.Path
+	routes.mu.Lock()
+	defer routes.mu.Unlock()
+	routes.entries = append(routes.entries, entry)
+}
+
+func (routes *sitemapRoutes) list() []SitemapEntry {
+	routes.mu.Lock()
+	defer routes.mu.Unlock()
+	return append([]SitemapEntry(nil), routes.entries...)
+}
+
+// Sitemap serves the sitemap.xml of the server, listing the routes marked with [OptionIndexable]
+// and the dynamic pages returned by [SitemapConfig.Entries]. The route is hidden from the OpenAPI spec.
+//
+//	fuego.Sitemap(s, fuego.SitemapConfig{
+//		BaseURL: "https://recipes.example.com",
+//		Entries: func(ctx context.Context) ([]fuego.SitemapEntry, error) {
+//			recipes, err := store.GetRecipes(ctx)
+//			entries := make([]fuego.SitemapEntry, 0, len(recipes))
+//			for _, recipe := range recipes {
+//				entries = append(entries, fuego.SitemapEntry{Loc: "/recipes/" + recipe.ID, LastMod: recipe.UpdatedAt})
+//			}
+//			return entries, err
+//		},
+//	})
+func Sitemap(s *Server, config SitemapConfig) {
+	if config.Path == "" {
+		config.Path = "/sitemap.xml"
+	}
+
+	GetStd(s, config.Path, func(w http.ResponseWriter, r *http.Request) {
+		entries := s.sitemap.list()
+		if config.Entries != nil {
+			dynamic, err := config.Entries(r.Context())
+			if err != nil {
+				slog.Error("Cannot list the dynamic pages of the sitemap", "error", err)
+				http.Error(w, "Cannot generate the sitemap", http.StatusInternalServerError)
+				return
+			}
+			entries = append(entries, dynamic...)
+		}
+
+		baseURL := strings.TrimSuffix(config.BaseURL, "/")
+		if baseURL == "" {
+			baseURL = requestBaseURL(r, s)
+		}
+		urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: make([]sitemapURL, 0, len(entries))}
+		for _, entry := range entries {
+			urlSet.URLs = append(urlSet.URLs, newSitemapURL(baseURL, entry))
+		}
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		_, _ = w.Write([]byte(xml.Header))
+		if err := xml.NewEncoder(w).Encode(urlSet); err != nil {
+			slog.Error("Cannot write the sitemap", "error", err)
+		}
+	}, OptionHide())
+}
+
+// requestBaseURL returns the public URL of the server, from the forwarded headers of the trusted proxies, or the Host header.
+func requestBaseURL(r *http.Request, s *Server) string {
+	if url := forwardedURL(r, s.trustedProxies); url != "" {
+		return url
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string     `xml:"loc"`
+	LastMod    string     `xml:"lastmod,omitempty"`
+	ChangeFreq ChangeFreq `xml:"changefreq,omitempty"`
+	Priority   string     `xml:"priority,omitempty"`
+}
+
+func newSitemapURL(baseURL string, entry SitemapEntry) sitemapURL {
+	url := sitemapURL{Loc: entry.Loc, ChangeFreq: entry.ChangeFreq}
+	if !strings.HasPrefix(url.Loc, "http://") && !strings.HasPrefix(url.Loc, "https://") {
+		url.Loc = baseURL + "/" + strings.TrimPrefix(url.Loc, "/")
+	}
+	if !entry.LastMod.IsZero() {
+		url.LastMod = entry.LastMod.UTC().Format(time.RFC3339)
+	}
+	if entry.Priority > 0 {
+		url.Priority = strconv.FormatFloat(entry.Priority, 'f', 1, 64)
+	}
+	return url
+}
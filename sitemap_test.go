@@ -0,0 +1,72 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSitemap(t *testing.T) {
+	page := func(c ContextNoBody) (HTML, error) { return "<h1>Page</h1>", nil }
+	newServer := func(config SitemapConfig) *Server {
+		s := NewServer(WithoutLogger(), WithBasePath("/app"))
+		Sitemap(s, config)
+		Get(s, "/", page, OptionIndexable(1, ChangeFreqDaily))
+		blog := Group(s, "/blog")
+		Get(blog, "/about", page, OptionIndexable(0.3, ChangeFreqYearly))
+		Get(blog, "/drafts", page)
+		Get(blog, "/posts/{id}", page, OptionIndexable(0.5, ChangeFreqWeekly))
+		return s
+	}
+	get := func(s *Server) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/app/sitemap.xml", nil)
+		r.Host = "recipes.example.com"
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("indexable routes and dynamic entries", func(t *testing.T) {
+		s := newServer(SitemapConfig{
+			BaseURL: "https://example.com/",
+			Entries: func(ctx context.Context) ([]SitemapEntry, error) {
+				return []SitemapEntry{
+					{Loc: "/app/blog/posts/1", LastMod: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)},
+					{Loc: "https://cdn.example.com/guide.pdf"},
+				}, nil
+			},
+		})
+
+		w := get(s)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+			`<url><loc>https://example.com/app/</loc><changefreq>daily</changefreq><priority>1.0</priority></url>`+
+			`<url><loc>https://example.com/app/blog/about</loc><changefreq>yearly</changefreq><priority>0.3</priority></url>`+
+			`<url><loc>https://example.com/app/blog/posts/1</loc><lastmod>2025-03-01T12:00:00Z</lastmod></url>`+
+			`<url><loc>https://cdn.example.com/guide.pdf</loc></url>`+
+			`</urlset>`, w.Body.String())
+	})
+
+	t.Run("base URL from the request", func(t *testing.T) {
+		w := get(newServer(SitemapConfig{}))
+		require.Contains(t, w.Body.String(), "<loc>http://recipes.example.com/app/blog/about</loc>")
+	})
+
+	t.Run("failing entries", func(t *testing.T) {
+		w := get(newServer(SitemapConfig{Entries: func(context.Context) ([]SitemapEntry, error) {
+			return nil, errors.New("database unavailable")
+		}}))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("invalid priority", func(t *testing.T) {
+		require.Panics(t, func() { OptionIndexable(2, ChangeFreqDaily) })
+	})
+}
@@ -0,0 +1,176 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// eventStreamContentType is the content type of the Server-Sent Events responses.
+const eventStreamContentType = "text/event-stream"
+
+// SSEEvent is a Server-Sent Event.
+type SSEEvent struct {
+	// ID of the event, sent back by the browsers in the Last-Event-ID header when they reconnect.
+	ID string
+	// Name of the event, "message" if empty.
+	Event string
+	// Data of the event. Strings and byte slices are sent as is, the other values as JSON.
+	Data any
+	// Reconnection delay of the client, if not zero.
+	Retry time.Duration
+}
+
+// SSEWriter sends Server-Sent Events to the client, created with c.SSE().
+// Each event is flushed as soon as it is sent.
+// Its methods return an error once the client has disconnected, so that the controller stops streaming:
+//
+//	fuego.Get(s, "/jobs/{id}/progress", func(c fuego.ContextNoBody) (any, error) {
+//		events := c.SSE()
+//		for progress := range job.Progress(c.PathParam("id")) {
+//			if err := events.Send("progress", progress); err != nil {
+//				return nil, err
+//			}
+//		}
+//		return nil, events.Send("done", "")
+//	})
+//
+// Once the stream has started, the error returned by the controller is sent as an "error" event,
+// and the answer of the controller is ignored.
+type SSEWriter struct {
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	ctx context.Context
+
+	mu sync.Mutex
+	// Events recorded instead of sent, by the [MockContext].
+	recorded *[]SSEEvent
+}
+
+// NewSSEWriter starts a stream of Server-Sent Events: it sends the text/event-stream headers,
+// and removes the write timeout of the server for the response. It is used by the adaptors to implement c.SSE().
+func NewSSEWriter(w http.ResponseWriter, r *http.Request) *SSEWriter {
+	header := w.Header()
+	header.Set("Content-Type", eventStreamContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // Disables the buffering of the nginx proxies
+	header.Del("Content-Length")
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	return &SSEWriter{w: w, rc: rc, ctx: r.Context()}
+}
+
+// Send sends an event with the given name and data. See [SSEEvent] for the encoding of the data.
+func (s *SSEWriter) Send(event string, data any) error {
+	return s.SendEvent(SSEEvent{Event: event, Data: data})
+}
+
+// SendEvent sends the event.
+func (s *SSEWriter) SendEvent(event SSEEvent) error {
+	message, err := formatSSEEvent(event)
+	if err != nil {
+		return err
+	}
+	if s.recorded != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		*s.recorded = append(*s.recorded, event)
+		return nil
+	}
+	return s.write(message)
+}
+
+// formatSSEEvent formats the event in the text/event-stream format.
+func formatSSEEvent(event SSEEvent) ([]byte, error) {
+	if strings.ContainsAny(event.Event, "\r\n") || strings.ContainsAny(event.ID, "\r\n") {
+		return nil, errors.New("event name and ID must not contain line breaks")
+	}
+
+	var data []byte
+	switch d := event.Data.(type) {
+	case string:
+		data = []byte(d)
+	case []byte:
+		data = d
+	default:
+		var err error
+		data, err = json.Marshal(d)
+		if err != nil {
+			return nil, fmt.Errorf("cannot serialize event data to JSON: %w", err)
+		}
+	}
+
+	var b bytes.Buffer
+	if event.ID != "" {
+		b.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Event != "" {
+		b.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range bytes.Split(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// Comment sends a comment, ignored by the clients. Sent periodically, it keeps the connection open
+// through the proxies closing the idle connections.
+func (s *SSEWriter) Comment(text string) error {
+	if s.recorded != nil {
+		return nil
+	}
+	return s.write([]byte(": " + strings.ReplaceAll(text, "\n", " ") + "\n\n"))
+}
+
+// Done is closed when the client disconnects.
+func (s *SSEWriter) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+func (s *SSEWriter) write(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(p); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// isEventStream reports whether the response is a stream of Server-Sent Events, started with c.SSE().
+func isEventStream(w http.ResponseWriter) bool {
+	return w != nil && w.Header().Get("Content-Type") == eventStreamContentType
+}
+
+// sendSSEError sends the error of the controller as an "error" event, as the status code is already sent.
+func sendSSEError(w http.ResponseWriter, r *http.Request, err error) {
+	var httpErr HTTPError
+	if !errors.As(err, &httpErr) {
+		httpErr = HTTPError{Err: err, Status: http.StatusInternalServerError, Title: "Internal Server Error"}
+	}
+	events := &SSEWriter{w: w, rc: http.NewResponseController(w), ctx: r.Context()}
+	if sendErr := events.Send("error", httpErr); sendErr != nil {
+		slog.Debug("Cannot send the error event", "error", err, "send_error", sendErr)
+	}
+}
@@ -0,0 +1,115 @@
+package fuego
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSE(t *testing.T) {
+	type progress struct {
+		Percent int `json:"percent"`
+	}
+	s := NewServer(WithoutLogger())
+	Get(s, "/progress", func(c ContextNoBody) (string, error) {
+		events := c.SSE()
+		for _, percent := range []int{50, 100} {
+			if err := events.Send("progress", progress{Percent: percent}); err != nil {
+				return "", err
+			}
+		}
+		if err := events.SendEvent(SSEEvent{ID: "3", Data: "multi\nline", Retry: 2 * time.Second}); err != nil {
+			return "", err
+		}
+		return "ignored", events.Comment("keep-alive")
+	})
+	Get(s, "/failing", func(c ContextNoBody) (any, error) {
+		_ = c.SSE().Send("progress", progress{Percent: 10})
+		return nil, NotFoundError{Title: "Job not found"}
+	})
+
+	t.Run("sends the events", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/progress", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+		require.True(t, w.Flushed)
+		require.Equal(t, "event: progress\ndata: {\"percent\":50}\n\n"+
+			"event: progress\ndata: {\"percent\":100}\n\n"+
+			"id: 3\nretry: 2000\ndata: multi\ndata: line\n\n"+
+			": keep-alive\n\n", w.Body.String())
+	})
+
+	t.Run("errors of the controller are sent as events", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/failing", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "event: error\ndata: {")
+		require.Contains(t, w.Body.String(), `"title":"Job not found"`)
+	})
+
+	t.Run("stops when the client disconnects", func(t *testing.T) {
+		disconnected := make(chan error, 1)
+		Get(s, "/endless", func(c ContextNoBody) (any, error) {
+			events := c.SSE()
+			for {
+				if err := events.Send("tick", "tick"); err != nil {
+					disconnected <- err
+					return nil, err
+				}
+				select {
+				case <-events.Done():
+				case <-time.After(time.Millisecond):
+				}
+			}
+		})
+		server := httptest.NewServer(s.Mux)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/endless", nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		line, err := bufio.NewReader(res.Body).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "event: tick\n", line, "flushed")
+
+		cancel()
+		_ = res.Body.Close()
+		select {
+		case err := <-disconnected:
+			require.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the controller did not stop")
+		}
+	})
+
+	t.Run("invalid event name", func(t *testing.T) {
+		err := NewMockContextNoBody().SSE().Send("progress\ndata: injected", "")
+		require.ErrorContains(t, err, "line breaks")
+	})
+
+	t.Run("mock context records the events", func(t *testing.T) {
+		c := NewMockContextNoBody()
+		require.NoError(t, c.SSE().Send("progress", progress{Percent: 50}))
+		require.Equal(t, []SSEEvent{{Event: "progress", Data: progress{Percent: 50}}}, c.Events)
+
+		require.Error(t, c.SSE().Send("progress", func() {}), "not serializable")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		events := NewSSEWriter(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		require.ErrorIs(t, events.Send("tick", "tick"), context.Canceled)
+	})
+}
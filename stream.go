@@ -0,0 +1,78 @@
+package fuego
+
+import (
+	"encoding/json"
+	"iter"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// streamFlushInterval is the maximum time the encoded elements of a stream are buffered before being flushed.
+const streamFlushInterval = 100 * time.Millisecond
+
+// streamElemType returns the type of the elements of a stream, if t is an iterator ([iter.Seq])
+// or a receive channel. Controllers returning a stream respond with a JSON array, see [sendJSONStream].
+func streamElemType(t reflect.Type) (reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Chan:
+		if t.ChanDir()&reflect.RecvDir != 0 {
+			return t.Elem(), true
+		}
+	case reflect.Func:
+		if t.NumIn() != 1 || t.NumOut() != 0 {
+			return nil, false
+		}
+		yield := t.In(0)
+		if yield.Kind() == reflect.Func && yield.NumIn() == 1 && yield.NumOut() == 1 && yield.Out(0).Kind() == reflect.Bool {
+			return yield.In(0), true
+		}
+	}
+	return nil, false
+}
+
+// streamValues returns the elements of ans, if it is an iterator ([iter.Seq]) or a receive channel.
+func streamValues(ans any) (iter.Seq[reflect.Value], bool) {
+	v := reflect.ValueOf(ans)
+	if _, ok := streamElemType(v.Type()); !ok {
+		return nil, false
+	}
+	if v.IsNil() {
+		return func(func(reflect.Value) bool) {}, true
+	}
+	return v.Seq(), true
+}
+
+// sendJSONStream sends the elements of the stream as a JSON array, one by one, without holding them in memory.
+// The response is flushed periodically so that the client receives the elements as they come.
+// As the status code is already sent, an error in the middle of the stream truncates the array:
+// it is logged, and the client gets an invalid JSON document.
+// The iteration stops when the client disconnects.
+func sendJSONStream(w http.ResponseWriter, values iter.Seq[reflect.Value]) {
+	w.Header().Set("Content-Type", "application/json")
+	rc := http.NewResponseController(w)
+
+	if _, err := w.Write([]byte{'['}); err != nil {
+		return
+	}
+	lastFlush := time.Now()
+	separator := []byte{}
+	for value := range values {
+		element, err := json.Marshal(value.Interface())
+		if err != nil {
+			slog.Error("Cannot serialize stream element to JSON", "error", err)
+			return
+		}
+		if _, err := w.Write(append(separator, element...)); err != nil {
+			return
+		}
+		separator = []byte{','}
+
+		if time.Since(lastFlush) >= streamFlushInterval {
+			_ = rc.Flush()
+			lastFlush = time.Now()
+		}
+	}
+	_, _ = w.Write([]byte("]\n"))
+}
@@ -0,0 +1,83 @@
+package fuego
+
+import (
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type streamedPet struct {
+	Name string `json:"name"`
+}
+
+func TestStream(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Get(s, "/iterator", func(c ContextNoBody) (iter.Seq[streamedPet], error) {
+		return slices.Values([]streamedPet{{"Rex"}, {"Felix"}}), nil
+	})
+	Get(s, "/channel", func(c ContextNoBody) (<-chan streamedPet, error) {
+		pets := make(chan streamedPet)
+		go func() {
+			defer close(pets)
+			pets <- streamedPet{"Rex"}
+			pets <- streamedPet{"Felix"}
+		}()
+		return pets, nil
+	})
+	Get(s, "/empty", func(c ContextNoBody) (iter.Seq[streamedPet], error) {
+		return nil, nil
+	})
+	Get(s, "/slow", func(c ContextNoBody) (iter.Seq[int], error) {
+		return func(yield func(int) bool) {
+			for i := range 3 {
+				time.Sleep(streamFlushInterval / 2)
+				if !yield(i) {
+					return
+				}
+			}
+		}, nil
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("iterator", func(t *testing.T) {
+		w := serve("/iterator")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `[{"name":"Rex"},{"name":"Felix"}]`, w.Body.String())
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		w := serve("/channel")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `[{"name":"Rex"},{"name":"Felix"}]`, w.Body.String())
+	})
+
+	t.Run("nil iterator", func(t *testing.T) {
+		w := serve("/empty")
+		require.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("flushed periodically", func(t *testing.T) {
+		w := serve("/slow")
+		require.JSONEq(t, `[0,1,2]`, w.Body.String())
+		require.True(t, w.Flushed)
+	})
+
+	t.Run("documented as arrays", func(t *testing.T) {
+		for _, path := range []string{"/iterator", "/channel"} {
+			schema := s.OpenAPI.Description().Paths.Find(path).Get.Responses.Value("200").Value.Content["application/json"].Schema.Value
+			require.True(t, schema.Type.Is("array"), path)
+			require.Equal(t, "#/components/schemas/streamedPet", schema.Items.Ref, path)
+		}
+	})
+}
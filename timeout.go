@@ -0,0 +1,148 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// OptionTimeout limits the time of the route. The request context is canceled after the timeout,
+// and the client gets a 504 Gateway Timeout error, documented in the OpenAPI spec, even if the controller
+// is still running: unlike the write timeout of the server, the client always gets a response.
+//
+// The controller must honour c.Context(): pass it to the database queries and the outgoing requests,
+// and return when it is done. Go cannot stop a running goroutine, so a controller ignoring its context keeps
+// running after the timeout, and the stuck controllers pile up. A warning logs how long each controller
+// overran its timeout, to spot the ones ignoring their context, and the panics after the timeout are logged
+// with their stack trace.
+//
+// The response of the controller is buffered until it completes, so the option does not suit
+// the streaming routes, like Server-Sent Events. The timeout covers the controller, not the route middlewares.
+// The last timeout declared on the route wins, so a route can override the timeout of its group.
+//
+//	fuego.Get(s, "/reports", getReports, option.Timeout(5*time.Second))
+func OptionTimeout(timeout time.Duration) func(*BaseRoute) {
+	if timeout <= 0 {
+		panic("timeout must be positive")
+	}
+	documentTimeout := OptionAddResponse(http.StatusGatewayTimeout, "Gateway Timeout _(the request took too long)_", Response{Type: HTTPError{}})
+	return func(r *BaseRoute) {
+		r.Timeout = timeout
+		documentTimeout(r)
+	}
+}
+
+// timeoutMiddleware sends a 504 Gateway Timeout error if the handler is not done after the timeout.
+// It is mounted after the route middlewares.
+func timeoutMiddleware(timeout time.Duration, sendError ErrorSender) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{header: http.Header{}, code: http.StatusOK}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			start := time.Now()
+			go func() {
+				defer func() {
+					p := recover()
+					if p == nil {
+						return
+					}
+					tw.mu.Lock()
+					defer tw.mu.Unlock()
+					if tw.timedOut {
+						// The 504 error is already sent: nobody reads the panic anymore
+						slog.Error("panic after the timeout", "panic", p, "method", r.Method, "path", r.URL.Path, "stack", string(debug.Stack()))
+						return
+					}
+					panicked <- p
+				}()
+				next.ServeHTTP(tw, r)
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.inTime = ctx.Err() == nil
+				if tw.timedOut {
+					slog.Warn("handler returned after its timeout",
+						"path", r.URL.Path, "timeout", timeout, "overrun", time.Since(start)-timeout)
+				}
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+			case <-ctx.Done():
+			}
+
+			// Both cases may be ready: the response of the handler is sent only if it completed in time.
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			if tw.inTime {
+				tw.flush(w)
+				return
+			}
+			// The handler may have panicked while the context was done
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+			tw.timedOut = true
+			if ctx.Err() == context.DeadlineExceeded {
+				sendError(w, r, gatewayTimeoutError(ctx.Err()))
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers the response of the handler of [timeoutMiddleware].
+// The writes after the timeout fail with [http.ErrHandlerTimeout].
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+	// The handler returned before the request context was done.
+	inTime bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+// flush sends the buffered response. tw.mu must be held.
+func (tw *timeoutWriter) flush(w http.ResponseWriter) {
+	for name, values := range tw.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(tw.code)
+	_, _ = w.Write(tw.body.Bytes())
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.code = code
+}
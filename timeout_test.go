@@ -0,0 +1,146 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionTimeout(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	canceled := make(chan error, 1)
+	Get(s, "/slow", func(c ContextNoBody) (string, error) {
+		<-c.Context().Done()
+		canceled <- c.Context().Err()
+		c.SetHeader("X-Late", "true")
+		return "too late", nil
+	}, OptionTimeout(10*time.Millisecond))
+	Get(s, "/fast", func(c ContextNoBody) (string, error) {
+		c.SetHeader("X-Fast", "true")
+		c.SetStatus(http.StatusCreated)
+		return "ok", nil
+	}, OptionTimeout(time.Second))
+
+	t.Run("timeout", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		require.Equal(t, http.StatusGatewayTimeout, w.Code)
+		require.Contains(t, w.Body.String(), "the request took too long to complete")
+		require.ErrorIs(t, <-canceled, context.DeadlineExceeded)
+		require.Empty(t, w.Header().Get("X-Late"))
+	})
+
+	t.Run("in time", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, "true", w.Header().Get("X-Fast"))
+		require.Equal(t, "ok", w.Body.String())
+	})
+
+	t.Run("client gone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+		require.ErrorIs(t, <-canceled, context.Canceled)
+		require.Empty(t, w.Body.String())
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		spec := s.OutputOpenAPISpec()
+		require.NotNil(t, spec.Paths.Find("/slow").Get.Responses.Status(http.StatusGatewayTimeout))
+	})
+}
+
+func TestOptionTimeoutOverride(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	api := Group(s, "/api", OptionTimeout(time.Millisecond))
+	route := Get(api, "/reports", func(c ContextNoBody) (string, error) {
+		select {
+		case <-c.Context().Done():
+			return "", c.Context().Err()
+		case <-time.After(20 * time.Millisecond):
+			return "report", nil
+		}
+	}, OptionTimeout(time.Second))
+	require.Equal(t, time.Second, route.Timeout)
+
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/reports", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestOptionTimeoutPanic(t *testing.T) {
+	require.Panics(t, func() { OptionTimeout(0) })
+
+	s := NewServer(WithoutLogger())
+	GetStd(s, "/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic(errors.New("boom"))
+	}, OptionTimeout(time.Second))
+	require.PanicsWithError(t, "boom", func() {
+		s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	})
+
+	t.Run("panics after the timeout are logged", func(t *testing.T) {
+		logs := make(chan string, 10)
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(chanWriter(logs), nil)))
+
+		sent := make(chan struct{})
+		GetStd(s, "/late-panic", func(w http.ResponseWriter, r *http.Request) {
+			<-sent
+			panic(errors.New("late boom"))
+		}, OptionTimeout(10*time.Millisecond))
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/late-panic", nil))
+		require.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+		close(sent)
+		log := <-logs
+		for !strings.Contains(log, "panic after the timeout") {
+			log = <-logs
+		}
+		require.Contains(t, log, "panic after the timeout")
+		require.Contains(t, log, "late boom")
+		require.Contains(t, log, "timeout_test.go")
+	})
+}
+
+// chanWriter sends each write to the channel.
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+func TestOptionTimeoutRace(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	GetStd(s, "/deadline", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		w.Header().Set("X-Handler", "true")
+		_, _ = w.Write([]byte("ok"))
+	}, OptionTimeout(time.Millisecond))
+
+	// The handler returns around the timeout: the client gets either its whole response or the 504 error.
+	for range 50 {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/deadline", nil))
+		if w.Code == http.StatusOK {
+			require.Equal(t, "true", w.Header().Get("X-Handler"))
+			require.Equal(t, "ok", w.Body.String())
+		} else {
+			require.Equal(t, http.StatusGatewayTimeout, w.Code)
+			require.Empty(t, w.Header().Get("X-Handler"))
+		}
+	}
+}
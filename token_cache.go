@@ -0,0 +1,164 @@
+package fuego
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenCacheConfig configures the cache of the token validations. See [WithTokenCache].
+type TokenCacheConfig struct {
+	// Maximum number of tokens kept. The least recently used ones are evicted first. Defaults to 10000.
+	MaxEntries int
+	// Duration the malformed tokens and the tokens with an invalid signature are rejected without being parsed again.
+	// Defaults to 1 minute. Negative to disable the negative caching.
+	NegativeTTL time.Duration
+}
+
+// TokenCacheStats is a snapshot of the counters of a [TokenCache].
+type TokenCacheStats struct {
+	// Validations answered from the cache, valid and rejected tokens.
+	Hits         int64 `json:"hits"`
+	NegativeHits int64 `json:"negativeHits"`
+	// Validations that parsed and verified the token.
+	Misses int64 `json:"misses"`
+	// Tokens evicted to keep the cache under its maximum size.
+	Evictions int64 `json:"evictions"`
+	// Tokens currently cached.
+	Entries int64 `json:"entries"`
+}
+
+// HitRate returns the ratio of the validations answered from the cache, between 0 and 1.
+func (s TokenCacheStats) HitRate() float64 {
+	total := s.Hits + s.NegativeHits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.NegativeHits) / float64(total)
+}
+
+// TokenCache caches the results of [Security.ValidateToken], keyed by the SHA-256 hash of the tokens.
+// The valid tokens are cached until they expire, the malformed ones for [TokenCacheConfig.NegativeTTL].
+// The revocation of the sessions (see [WithLogout]) is checked at each validation.
+type TokenCache struct {
+	maxEntries  int
+	negativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // most recently used first
+	stats   TokenCacheStats
+}
+
+type tokenCacheEntry struct {
+	key       [sha256.Size]byte
+	token     *jwt.Token
+	err       error
+	expiresAt time.Time
+}
+
+// NewTokenCache creates an empty [TokenCache].
+func NewTokenCache(config TokenCacheConfig) *TokenCache {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+	if config.NegativeTTL == 0 {
+		config.NegativeTTL = time.Minute
+	}
+	return &TokenCache{
+		maxEntries:  config.MaxEntries,
+		negativeTTL: config.NegativeTTL,
+		entries:     make(map[[sha256.Size]byte]*list.Element),
+		order:       list.New(),
+	}
+}
+
+// WithTokenCache caches the validations of the tokens (see [TokenCache]), to avoid verifying their signature
+// at each request. The hit rate is read with [TokenCache.Stats]:
+//
+//	s := fuego.NewServer(fuego.WithAutoAuth(verifyUserInfo), fuego.WithTokenCache(fuego.TokenCacheConfig{MaxEntries: 50000}))
+//	expvar.Publish("tokenCache", expvar.Func(func() any { return s.Security.Cache.Stats() }))
+func WithTokenCache(config TokenCacheConfig) func(*Server) {
+	return func(s *Server) { s.Security.Cache = NewTokenCache(config) }
+}
+
+// Stats returns the counters of the cache.
+func (c *TokenCache) Stats() TokenCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = int64(c.order.Len())
+	return stats
+}
+
+// validate returns the cached validation of the token, or validates it with the given function and caches the result.
+// The function returns the time the valid tokens expire.
+func (c *TokenCache) validate(token string, now time.Time, validate func(string) (*jwt.Token, time.Time, error)) (*jwt.Token, error) {
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*tokenCacheEntry)
+		if now.Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			if entry.err != nil {
+				c.stats.NegativeHits++
+				c.mu.Unlock()
+				return nil, entry.err
+			}
+			c.stats.Hits++
+			c.mu.Unlock()
+			return cloneToken(entry.token), nil
+		}
+		c.remove(element)
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	t, expiresAt, err := validate(token)
+	entry := &tokenCacheEntry{key: key, token: t, err: err}
+	switch {
+	case err == nil:
+		entry.expiresAt = expiresAt
+		t = cloneToken(t)
+	case c.negativeTTL > 0 && (errors.Is(err, jwt.ErrTokenMalformed) || errors.Is(err, jwt.ErrTokenSignatureInvalid) || errors.Is(err, jwt.ErrTokenUnverifiable)):
+		entry.expiresAt = now.Add(c.negativeTTL)
+	}
+	if now.Before(entry.expiresAt) {
+		c.add(entry)
+	}
+	return t, err
+}
+
+func (c *TokenCache) add(entry *tokenCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[entry.key]; ok {
+		c.remove(element)
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *TokenCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*tokenCacheEntry).key)
+}
+
+// cloneToken copies the token and its claims, so that the handlers can modify them
+// without changing the cached ones.
+func cloneToken(t *jwt.Token) *jwt.Token {
+	clone := *t
+	if claims, ok := t.Claims.(jwt.MapClaims); ok {
+		clone.Claims = maps.Clone(claims)
+	}
+	return &clone
+}
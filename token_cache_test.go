@@ -0,0 +1,136 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCache(t *testing.T) {
+	newSecurity := func(config TokenCacheConfig) (Security, *time.Time) {
+		security := NewSecurity()
+		now := time.Now()
+		security.Now = func() time.Time { return now }
+		security.Cache = NewTokenCache(config)
+		return security, &now
+	}
+
+	t.Run("valid tokens are cached until they expire", func(t *testing.T) {
+		security, now := newSecurity(TokenCacheConfig{})
+		token, err := security.GenerateToken(jwt.MapClaims{"sub": "123", "exp": now.Add(time.Hour).Unix()})
+		require.NoError(t, err)
+
+		for range 3 {
+			parsed, err := security.ValidateToken(token)
+			require.NoError(t, err)
+			require.Equal(t, "123", parsed.Claims.(jwt.MapClaims)["sub"])
+		}
+		require.Equal(t, TokenCacheStats{Hits: 2, Misses: 1, Entries: 1}, security.Cache.Stats())
+		require.InDelta(t, 2.0/3, security.Cache.Stats().HitRate(), 0.001)
+
+		*now = now.Add(time.Hour + 10*time.Second)
+		_, err = security.ValidateToken(token)
+		require.ErrorIs(t, err, jwt.ErrTokenExpired, "exp claim")
+		require.Equal(t, int64(2), security.Cache.Stats().Misses)
+	})
+
+	t.Run("expiry interval of the server", func(t *testing.T) {
+		security, now := newSecurity(TokenCacheConfig{})
+		token, err := security.GenerateToken(jwt.MapClaims{"sub": "123"})
+		require.NoError(t, err)
+		_, err = security.ValidateToken(token)
+		require.NoError(t, err)
+
+		*now = now.Add(security.ExpiresInterval + time.Second)
+		_, err = security.ValidateToken(token)
+		require.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("malformed tokens are rejected from the cache", func(t *testing.T) {
+		security, now := newSecurity(TokenCacheConfig{NegativeTTL: time.Minute})
+		forged, err := NewSecurity().GenerateToken(jwt.MapClaims{"sub": "123"})
+		require.NoError(t, err)
+
+		for _, token := range []string{"not-a-token", "not-a-token", forged, forged} {
+			_, err := security.ValidateToken(token)
+			require.Error(t, err)
+		}
+		require.Equal(t, TokenCacheStats{NegativeHits: 2, Misses: 2, Entries: 2}, security.Cache.Stats())
+
+		*now = now.Add(time.Minute)
+		_, err = security.ValidateToken("not-a-token")
+		require.ErrorIs(t, err, jwt.ErrTokenMalformed)
+		require.Equal(t, int64(3), security.Cache.Stats().Misses)
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		security, _ := newSecurity(TokenCacheConfig{MaxEntries: 2})
+		tokens := make([]string, 3)
+		for i := range tokens {
+			tokens[i], _ = security.GenerateToken(jwt.MapClaims{"sub": string(rune('a' + i))})
+		}
+		for _, token := range []string{tokens[0], tokens[1], tokens[0], tokens[2], tokens[0], tokens[1]} {
+			_, err := security.ValidateToken(token)
+			require.NoError(t, err)
+		}
+		stats := security.Cache.Stats()
+		require.Equal(t, int64(2), stats.Entries)
+		require.Equal(t, int64(2), stats.Evictions)
+		require.Equal(t, int64(2), stats.Hits, "the least recently used token is evicted")
+	})
+
+	t.Run("cached claims are not shared", func(t *testing.T) {
+		security, _ := newSecurity(TokenCacheConfig{})
+		token, _ := security.GenerateToken(jwt.MapClaims{"sub": "123"})
+		parsed, _ := security.ValidateToken(token)
+		parsed.Claims.(jwt.MapClaims)["sub"] = "admin"
+
+		parsed, _ = security.ValidateToken(token)
+		require.Equal(t, "123", parsed.Claims.(jwt.MapClaims)["sub"])
+	})
+
+	t.Run("revoked sessions are checked at each validation", func(t *testing.T) {
+		security, _ := newSecurity(TokenCacheConfig{})
+		security.Sessions = NewInMemorySessionStore()
+		claims := jwt.MapClaims{"sub": "123"}
+		token, _ := security.GenerateToken(claims)
+		_, err := security.ValidateToken(token)
+		require.NoError(t, err)
+
+		require.NoError(t, security.RevokeSession(context.Background(), claims))
+		_, err = security.ValidateToken(token)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("WithTokenCache", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithAutoAuth(func(user, password string) (jwt.Claims, error) { return jwt.MapClaims{"sub": user}, nil }),
+			WithTokenCache(TokenCacheConfig{}),
+		)
+		Get(s, "/me", func(c ContextNoBody) (string, error) {
+			claims, err := TokenFromContext(c.Context())
+			if err != nil {
+				return "", err
+			}
+			return claims.GetSubject()
+		})
+		token, err := s.Security.GenerateToken(jwt.MapClaims{"sub": "123"})
+		require.NoError(t, err)
+
+		for range 2 {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/me", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			s.Mux.ServeHTTP(w, r)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Contains(t, w.Body.String(), "123")
+		}
+		require.Equal(t, int64(1), s.Security.Cache.Stats().Hits)
+	})
+}
@@ -0,0 +1,145 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum size of the bodies of the mirrored requests. Larger requests are not mirrored.
+	trafficMirrorMaxBodySize = 1 << 20
+	// Maximum number of mirrored requests in flight. Requests above it are not mirrored.
+	trafficMirrorMaxInFlight = 64
+	// Timeout of the mirrored requests.
+	trafficMirrorTimeout = 10 * time.Second
+)
+
+// TrafficMirrorHeader is set on the mirrored requests, so the shadow deployment can tell them apart,
+// for example to avoid sending emails or charging cards.
+const TrafficMirrorHeader = "X-Traffic-Mirror"
+
+// WithTrafficMirror replays a sample of the requests to a shadow deployment, to test a new version under real load.
+// The sampled requests are sent asynchronously to the target URL, with their path, query, headers and body,
+// and the [TrafficMirrorHeader] header. The responses of the shadow deployment are discarded:
+// the clients are always served by the server, and the shadow deployment never slows them down.
+//
+// Requests with a body larger than 1 MiB are not mirrored, nor the requests sampled while 64 mirrored requests are in flight.
+//
+//	s := fuego.NewServer(
+//		fuego.WithTrafficMirror("http://api-canary.internal:8080", 5), // 5% of the requests
+//	)
+func WithTrafficMirror(target string, samplePercent float64) func(*Server) {
+	targetURL, err := url.Parse(target)
+	if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+		panic("traffic mirror target must be an absolute URL, got " + target)
+	}
+	if samplePercent < 0 || samplePercent > 100 {
+		panic("traffic mirror sample must be a percentage between 0 and 100")
+	}
+
+	mirror := &trafficMirror{
+		target:   targetURL,
+		sample:   samplePercent / 100,
+		client:   &http.Client{Timeout: trafficMirrorTimeout},
+		inFlight: make(chan struct{}, trafficMirrorMaxInFlight),
+	}
+	return func(s *Server) {
+		s.globalMiddlewares = append(s.globalMiddlewares, mirror.middleware)
+	}
+}
+
+type trafficMirror struct {
+	target *url.URL
+	// Share of the requests to mirror, between 0 and 1.
+	sample   float64
+	client   *http.Client
+	inFlight chan struct{}
+}
+
+func (m *trafficMirror) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rand.Float64() >= m.sample { //nolint:gosec // no security purpose
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case m.inFlight <- struct{}{}:
+		default:
+			slog.Debug("traffic mirror saturated, request not mirrored", "path", r.URL.Path)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, complete := m.readBody(r)
+		if !complete {
+			<-m.inFlight
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mirrored, cancel := m.newRequest(r, body)
+		go m.send(mirrored, cancel)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// readBody reads the body of the request, up to the maximum size, and gives it back to the request.
+// It returns false if the body is larger than the maximum size.
+func (m *trafficMirror) readBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, trafficMirrorMaxBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil || len(body) > trafficMirrorMaxBodySize {
+		return nil, false
+	}
+	return body, true
+}
+
+// newRequest copies the request to the target. Its context is not canceled with the original request.
+func (m *trafficMirror) newRequest(r *http.Request, body []byte) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), trafficMirrorTimeout)
+	mirrored := r.Clone(ctx)
+
+	mirrored.RequestURI = ""
+	mirrored.URL.Scheme = m.target.Scheme
+	mirrored.URL.Host = m.target.Host
+	mirrored.URL.Path = strings.TrimSuffix(m.target.Path, "/") + r.URL.Path
+	mirrored.URL.RawPath = ""
+	mirrored.Host = m.target.Host
+	mirrored.Header.Set(TrafficMirrorHeader, "true")
+	mirrored.Body = http.NoBody
+	mirrored.ContentLength = int64(len(body))
+	if body != nil {
+		mirrored.Body = io.NopCloser(bytes.NewReader(body))
+		mirrored.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+	return mirrored, cancel
+}
+
+// send sends the mirrored request and discards the response.
+func (m *trafficMirror) send(r *http.Request, cancel context.CancelFunc) {
+	defer func() { <-m.inFlight }()
+	defer cancel()
+
+	resp, err := m.client.Do(r)
+	if err != nil {
+		slog.Debug("traffic mirror request failed", "path", r.URL.Path, "error", err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
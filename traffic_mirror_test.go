@@ -0,0 +1,80 @@
+package fuego
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mirroredRequest struct {
+	method, path, query, body, header string
+}
+
+func TestWithTrafficMirror(t *testing.T) {
+	received := make(chan mirroredRequest, 10)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- mirroredRequest{r.Method, r.URL.Path, r.URL.RawQuery, string(body), r.Header.Get(TrafficMirrorHeader)}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	newServer := func(sample float64) *Server {
+		s := NewServer(WithoutLogger(), WithTrafficMirror(shadow.URL+"/shadow", sample))
+		Post(s, "/pets", func(c ContextWithBody[MyStruct]) (MyStruct, error) { return c.Body() })
+		return s
+	}
+
+	t.Run("mirrors the sampled requests", func(t *testing.T) {
+		s := newServer(100)
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pets?dry=true", strings.NewReader(`{"b":"kitty","c":3}`)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"b":"kitty","c":3,"d":false}`, w.Body.String())
+
+		select {
+		case mirrored := <-received:
+			require.Equal(t, mirroredRequest{http.MethodPost, "/shadow/pets", "dry=true", `{"b":"kitty","c":3}`, "true"}, mirrored)
+		case <-time.After(time.Second):
+			t.Fatal("request not mirrored")
+		}
+	})
+
+	t.Run("requests out of the sample are not mirrored", func(t *testing.T) {
+		s := newServer(0)
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"b":"kitty","c":3}`)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		select {
+		case <-received:
+			t.Fatal("request mirrored")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("large bodies are not mirrored but served", func(t *testing.T) {
+		s := newServer(100)
+		large := `{"b":"` + strings.Repeat("a", trafficMirrorMaxBodySize) + `","c":3}`
+		w := httptest.NewRecorder()
+		s.handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(large)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), strings.Repeat("a", 100))
+
+		select {
+		case <-received:
+			t.Fatal("request mirrored")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		require.Panics(t, func() { WithTrafficMirror("/relative", 10) })
+		require.Panics(t, func() { WithTrafficMirror(shadow.URL, 150) })
+	})
+}
@@ -0,0 +1,102 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Tx is a database transaction, like [*database/sql.Tx]. See [OptionTransactional].
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// OptionTransactional runs the controller of the route in a database transaction, begun with the given function.
+// The transaction is given to the controller through its context, see [Transaction].
+// It is committed if the controller returns no error, before the response is sent, so that a failed commit
+// is reported to the client. It is rolled back if the controller returns an error or panics.
+//
+// If the context of the request already has a transaction, for example begun by a middleware,
+// the controller joins it: no transaction is begun, and the owner of the transaction commits it or rolls it back.
+//
+//	fuego.Post(s, "/orders", createOrder, option.Transactional(func(ctx context.Context) (fuego.Tx, error) {
+//		return db.BeginTx(ctx, nil)
+//	}))
+//
+//	func createOrder(c fuego.ContextWithBody[OrderCreate]) (Order, error) {
+//		tx, _ := fuego.Transaction[*sql.Tx](c)
+//		// ...
+//	}
+//
+// It is only supported by the net/http server.
+func OptionTransactional(begin func(ctx context.Context) (Tx, error)) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.beginTx = begin
+	}
+}
+
+type transactionKey struct{}
+
+// WithTransaction returns a copy of the context holding the transaction, for the controllers of the routes
+// with [OptionTransactional] to join it. Use it in the middlewares owning a transaction.
+func WithTransaction(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, transactionKey{}, tx)
+}
+
+// Transaction returns the transaction of the context, begun by [OptionTransactional] or set by [WithTransaction].
+// It returns false if there is no transaction, or if it is not a T.
+func Transaction[T Tx](ctx context.Context) (T, bool) {
+	tx, ok := ctx.Value(transactionKey{}).(T)
+	return tx, ok
+}
+
+// requestContextSetter is implemented by the contexts whose request context can be replaced,
+// to give values like the transaction to the controller.
+type requestContextSetter interface {
+	setRequestContext(ctx context.Context)
+}
+
+// transactional wraps the controller in a transaction, see [OptionTransactional].
+func transactional[B, T any](begin func(ctx context.Context) (Tx, error), controller func(c ContextWithBody[B]) (T, error)) func(c ContextWithBody[B]) (T, error) {
+	return func(c ContextWithBody[B]) (ans T, err error) {
+		if c.Value(transactionKey{}) != nil {
+			return controller(c)
+		}
+		setter, ok := c.(requestContextSetter)
+		if !ok {
+			return ans, errors.New("transactions are not supported by this context")
+		}
+
+		tx, err := begin(c)
+		if err != nil {
+			return ans, fmt.Errorf("begin transaction: %w", err)
+		}
+		setter.setRequestContext(WithTransaction(c.Context(), tx))
+
+		defer func() {
+			if r := recover(); r != nil {
+				rollback(tx)
+				panic(r)
+			}
+		}()
+
+		ans, err = controller(c)
+		if err != nil {
+			rollback(tx)
+			return ans, err
+		}
+		if err := tx.Commit(); err != nil {
+			return ans, fmt.Errorf("commit transaction: %w", err)
+		}
+		return ans, nil
+	}
+}
+
+// rollback rolls the transaction back, logging the failures: the error of the controller is the one reported.
+func rollback(tx Tx) {
+	if err := tx.Rollback(); err != nil {
+		slog.Error("Cannot roll back the transaction", "error", err)
+	}
+}
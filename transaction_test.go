@@ -0,0 +1,157 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTx struct {
+	committed  bool
+	rolledBack bool
+	commitErr  error
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+// fakeDB begins fake transactions, and keeps the last one.
+type fakeDB struct {
+	tx       *fakeTx
+	begun    int
+	beginErr error
+}
+
+func (db *fakeDB) begin(ctx context.Context) (Tx, error) {
+	if db.beginErr != nil {
+		return nil, db.beginErr
+	}
+	db.begun++
+	db.tx = &fakeTx{}
+	return db.tx, nil
+}
+
+func TestOptionTransactional(t *testing.T) {
+	serve := func(s *Server, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	newServer := func(db *fakeDB, controller func(c ContextWithBody[MyStruct]) (MyStruct, error)) *Server {
+		s := NewServer()
+		Post(s, "/orders", controller, OptionTransactional(db.begin))
+		return s
+	}
+
+	t.Run("commits on success", func(t *testing.T) {
+		db := &fakeDB{}
+		s := newServer(db, func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+			tx, ok := Transaction[*fakeTx](c)
+			require.True(t, ok)
+			require.Same(t, db.tx, tx)
+			require.False(t, tx.committed)
+			return c.Body()
+		})
+
+		w := serve(s, `{"b":"order","c":3}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.True(t, db.tx.committed)
+		require.False(t, db.tx.rolledBack)
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		db := &fakeDB{}
+		s := newServer(db, func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+			return MyStruct{}, ConflictError{Detail: "order already exists"}
+		})
+
+		w := serve(s, `{"b":"order"}`)
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.False(t, db.tx.committed)
+		require.True(t, db.tx.rolledBack)
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		db := &fakeDB{}
+		s := newServer(db, func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+			panic("boom")
+		})
+
+		require.PanicsWithValue(t, "boom", func() { serve(s, `{}`) })
+		require.False(t, db.tx.committed)
+		require.True(t, db.tx.rolledBack)
+	})
+
+	t.Run("failed commit is reported", func(t *testing.T) {
+		db := &fakeDB{}
+		s := NewServer()
+		Post(s, "/orders", func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+			tx, _ := Transaction[*fakeTx](c)
+			tx.commitErr = errors.New("serialization failure")
+			return MyStruct{B: "order"}, nil
+		}, OptionTransactional(db.begin))
+
+		w := serve(s, `{}`)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotContains(t, w.Body.String(), "order")
+	})
+
+	t.Run("failed begin is reported", func(t *testing.T) {
+		db := &fakeDB{beginErr: errors.New("too many connections")}
+		called := false
+		s := newServer(db, func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+			called = true
+			return MyStruct{}, nil
+		})
+
+		w := serve(s, `{}`)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.False(t, called)
+	})
+
+	t.Run("joins the transaction of the request", func(t *testing.T) {
+		db := &fakeDB{}
+		outer := &fakeTx{}
+		s := NewServer()
+		Post(s, "/orders", func(c ContextWithBody[MyStruct]) (MyStruct, error) {
+			tx, _ := Transaction[*fakeTx](c)
+			require.Same(t, outer, tx)
+			return MyStruct{}, nil
+		}, OptionTransactional(db.begin), OptionMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(WithTransaction(r.Context(), outer)))
+			})
+		}))
+
+		w := serve(s, `{}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Zero(t, db.begun)
+		require.False(t, outer.committed)
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	_, ok := Transaction[*fakeTx](context.Background())
+	require.False(t, ok)
+
+	ctx := WithTransaction(context.Background(), &fakeTx{})
+	_, ok = Transaction[*fakeTx](ctx)
+	require.True(t, ok)
+	_, ok = Transaction[Tx](ctx)
+	require.True(t, ok)
+}
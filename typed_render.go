@@ -0,0 +1,72 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// HT is the response of a route rendering a template with data of type T, created with [Render].
+// The type of the data is checked at compile time, and documented in the OpenAPI spec:
+// the response is documented as text/html, with the schema of the data in the x-template extension.
+//
+//	func getRecipes(c fuego.ContextNoBody) (fuego.HT[RecipesPage], error) {
+//		recipes, err := store.GetRecipes(c.Context())
+//		if err != nil {
+//			return fuego.HT[RecipesPage]{}, err
+//		}
+//		return fuego.Render(c, "recipes.page.html", RecipesPage{Recipes: recipes})
+//	}
+type HT[T any] struct {
+	// Name of the rendered template.
+	Template string
+	// Data passed to the template.
+	Data T
+
+	renderer CtxRenderer
+}
+
+var _ CtxRenderer = HT[any]{}
+
+// ContextWithRender is a context able to render the templates of the server, like [ContextWithBody].
+type ContextWithRender interface {
+	Render(templateToExecute string, data any, templateGlobsToOverride ...string) (CtxRenderer, error)
+}
+
+// Render renders the template with the typed data. See [HT].
+// The layouts globs override the blocks of the template, like with c.Render.
+func Render[T any](c ContextWithRender, templateToExecute string, data T, layoutsGlobs ...string) (HT[T], error) {
+	renderer, err := c.Render(templateToExecute, data, layoutsGlobs...)
+	return HT[T]{Template: templateToExecute, Data: data, renderer: renderer}, err
+}
+
+func (h HT[T]) Render(ctx context.Context, w io.Writer) error {
+	if h.renderer == nil {
+		return errors.New("template response not created with fuego.Render")
+	}
+	return h.renderer.Render(ctx, w)
+}
+
+func (HT[T]) templateDataType() reflect.Type { return reflect.TypeFor[T]() }
+
+// templateDataType returns the type of the data of the [HT] responses.
+func templateDataType[T any]() (reflect.Type, bool) {
+	ht, ok := any(*new(T)).(interface{ templateDataType() reflect.Type })
+	if !ok {
+		return nil, false
+	}
+	return ht.templateDataType(), true
+}
+
+// documentTemplateResponse documents the HTML content of the default response, and the schema of the template data.
+func documentTemplateResponse(openapi *OpenAPI, response *openapi3.Response, dataType reflect.Type) {
+	dataSchema := dive(openapi, dataType, SchemaTag{}, 5)
+	mediaType := openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema())
+	mediaType.Extensions = map[string]any{
+		"x-template": map[string]any{"data": &dataSchema.SchemaRef},
+	}
+	response.WithContent(openapi3.Content{"text/html": mediaType})
+}
@@ -0,0 +1,61 @@
+package fuego
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+)
+
+type RecipesPage struct {
+	Title   string   `json:"title"`
+	Recipes []string `json:"recipes"`
+}
+
+func TestRenderTyped(t *testing.T) {
+	templates := template.Must(template.New("").Parse(
+		`{{define "recipes.html"}}<h1>{{.Title}}</h1>{{range .Recipes}}<li>{{.}}</li>{{end}}{{end}}`,
+	))
+	s := NewServer(WithoutLogger(), WithTemplateFS(testdata), WithTemplates(templates))
+	Get(s, "/recipes", func(c ContextNoBody) (HT[RecipesPage], error) {
+		return Render(c, "recipes.html", RecipesPage{Title: "Recipes", Recipes: []string{"Pizza", "Pasta"}})
+	})
+
+	t.Run("renders the template", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "<h1>Recipes</h1><li>Pizza</li><li>Pasta</li>", w.Body.String())
+	})
+
+	t.Run("documents the template data", func(t *testing.T) {
+		response := s.OpenAPI.Description().Paths.Find("/recipes").Get.Responses.Status(http.StatusOK).Value
+		require.Len(t, response.Content, 1)
+		mediaType := response.Content.Get("text/html")
+		require.NotNil(t, mediaType)
+		require.True(t, mediaType.Schema.Value.Type.Is(openapi3.TypeString))
+
+		data := mediaType.Extensions["x-template"].(map[string]any)["data"].(*openapi3.SchemaRef)
+		require.Equal(t, "#/components/schemas/RecipesPage", data.Ref)
+		require.Contains(t, s.OpenAPI.Description().Components.Schemas["RecipesPage"].Value.Properties, "recipes")
+	})
+
+	t.Run("typed data in the tests of the controllers", func(t *testing.T) {
+		ans, err := Render(NewMockContextNoBody(), "recipes.html", RecipesPage{Title: "Recipes"})
+		require.NoError(t, err)
+		require.Equal(t, "recipes.html", ans.Template)
+		require.Equal(t, "Recipes", ans.Data.Title)
+	})
+
+	t.Run("not created with Render", func(t *testing.T) {
+		err := HT[RecipesPage]{}.Render(context.Background(), &strings.Builder{})
+		require.ErrorContains(t, err, "fuego.Render")
+	})
+}
@@ -1,6 +1,10 @@
 package fuego
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
 
 type ValidableCtx interface {
 	GetOpenAPIParams() map[string]OpenAPIParam
@@ -11,40 +15,53 @@ type ValidableCtx interface {
 
 // ValidateParams checks if all required parameters are present in the request.
 func ValidateParams(c ValidableCtx) error {
-	for k, param := range c.GetOpenAPIParams() {
-		if param.Default != nil {
-			// skip: param has a default
-			continue
+	return validateRequiredParams(c, requiredParams(c.GetOpenAPIParams()))
+}
+
+// requiredParams returns the params that must be present in the requests: required and without default.
+// They are sorted by name, so that the first missing one is reported consistently.
+func requiredParams(params map[string]OpenAPIParam) []OpenAPIParam {
+	var required []OpenAPIParam
+	for name, param := range params {
+		if param.Required && param.Default == nil {
+			param.Name = name
+			required = append(required, param)
 		}
+	}
+	slices.SortFunc(required, func(a, b OpenAPIParam) int { return strings.Compare(a.Name, b.Name) })
+	return required
+}
 
-		if param.Required {
-			switch param.Type {
-			case QueryParamType:
-				if !c.HasQueryParam(k) {
-					err := fmt.Errorf("%s is a required query param", k)
-					return BadRequestError{
-						Title:  "Query Param Not Found",
-						Err:    err,
-						Detail: "cannot parse request parameter: " + err.Error(),
-					}
+// validateRequiredParams checks if the required parameters, listed by [requiredParams], are present in the request.
+func validateRequiredParams(c ValidableCtx, required []OpenAPIParam) error {
+	for _, param := range required {
+		k := param.Name
+		switch param.Type {
+		case QueryParamType:
+			if !c.HasQueryParam(k) {
+				err := fmt.Errorf("%s is a required query param", k)
+				return BadRequestError{
+					Title:  "Query Param Not Found",
+					Err:    err,
+					Detail: "cannot parse request parameter: " + err.Error(),
 				}
-			case HeaderParamType:
-				if !c.HasHeader(k) {
-					err := fmt.Errorf("%s is a required header", k)
-					return BadRequestError{
-						Title:  "Header Not Found",
-						Err:    err,
-						Detail: "cannot parse request parameter: " + err.Error(),
-					}
+			}
+		case HeaderParamType:
+			if !c.HasHeader(k) {
+				err := fmt.Errorf("%s is a required header", k)
+				return BadRequestError{
+					Title:  "Header Not Found",
+					Err:    err,
+					Detail: "cannot parse request parameter: " + err.Error(),
 				}
-			case CookieParamType:
-				if !c.HasCookie(k) {
-					err := fmt.Errorf("%s is a required cookie", k)
-					return BadRequestError{
-						Title:  "Cookie Not Found",
-						Err:    err,
-						Detail: "cannot parse request parameter: " + err.Error(),
-					}
+			}
+		case CookieParamType:
+			if !c.HasCookie(k) {
+				err := fmt.Errorf("%s is a required cookie", k)
+				return BadRequestError{
+					Title:  "Cookie Not Found",
+					Err:    err,
+					Detail: "cannot parse request parameter: " + err.Error(),
 				}
 			}
 		}
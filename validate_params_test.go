@@ -51,4 +51,22 @@ func TestParamsValidation(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, w.Code)
 		require.Contains(t, w.Body.String(), "bar is a required cookie")
 	})
+
+	t.Run("Should report the missing parameters in a stable order", func(t *testing.T) {
+		s := fuego.NewServer()
+
+		fuego.Get(s, "/test", dummyController,
+			option.Query("zeta", "Zeta", param.Required()),
+			option.Query("alpha", "Alpha", param.Required()),
+			option.Query("beta", "Beta", param.Required()),
+			option.Query("with-default", "With default", param.Required(), param.Default("x")),
+		)
+		for range 10 {
+			r := httptest.NewRequest("GET", "/test?beta=1", nil)
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Contains(t, w.Body.String(), "alpha is a required query param")
+		}
+	})
 }
@@ -1,11 +1,18 @@
 package fuego
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/schema"
 )
 
 // explainError translates a validator error into a human readable string.
@@ -30,13 +37,123 @@ func explainError(err validator.FieldError) string {
 
 var v = validator.New()
 
+// CtxValidator is implemented by the request bodies with validation rules that need the request
+// or other dependencies, like cross-field rules or uniqueness checks against a database.
+// ValidateCtx runs after the validation of the struct tags, only if they are valid.
+// The context is the one of the request, and deps the dependencies set with [WithValidationDeps].
+//
+// Errors that have a status code, like [HTTPError], are returned as is.
+// Other errors are reported as a 400 Validation Error, with one item per error
+// joined with [errors.Join]. Use [ErrorItem] errors to name the invalid fields:
+//
+//	func (u *UserCreate) ValidateCtx(ctx context.Context, deps any) error {
+//		db := deps.(*sql.DB)
+//		if exists(ctx, db, u.Email) {
+//			return fuego.ErrorItem{Name: "email", Reason: "email is already used"}
+//		}
+//		return nil
+//	}
+type CtxValidator interface {
+	ValidateCtx(ctx context.Context, deps any) error
+}
+
+// validationDepsProvider is implemented by the contexts giving access to the dependencies of [WithValidationDeps].
+type validationDepsProvider interface {
+	GetValidationDeps() any
+}
+
+// validateCtx validates the body with its [CtxValidator] method, if any.
+func validateCtx[B any](ctx context.Context, body *B) error {
+	ctxValidator, ok := any(body).(CtxValidator)
+	if !ok {
+		ctxValidator, ok = any(*body).(CtxValidator)
+		if !ok {
+			return nil
+		}
+	}
+
+	var deps any
+	if provider, ok := ctx.(validationDepsProvider); ok {
+		deps = provider.GetValidationDeps()
+	}
+
+	err := ctxValidator.ValidateCtx(ctx, deps)
+	if err == nil {
+		return nil
+	}
+	var errorWithStatus ErrorWithStatus
+	if errors.As(err, &errorWithStatus) {
+		return err
+	}
+
+	validationError := HTTPError{
+		Err:    err,
+		Status: http.StatusBadRequest,
+		Title:  "Validation Error",
+	}
+	errs := []error{err}
+	if joinedErr, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joinedErr.Unwrap()
+	}
+	errorsSummary := make([]string, 0, len(errs))
+	for _, err := range errs {
+		var item ErrorItem
+		if !errors.As(err, &item) {
+			item = ErrorItem{Reason: err.Error()}
+		}
+		errorsSummary = append(errorsSummary, item.Reason)
+		validationError.Errors = append(validationError.Errors, item)
+	}
+	validationError.Detail = strings.Join(errorsSummary, ", ")
+
+	return validationError
+}
+
+// Validators of the scenarios set with [OptionValidationScenario], by scenario name.
+var scenarioValidators sync.Map
+
+// validationScenarioProvider is implemented by the contexts of the routes with a validation scenario.
+type validationScenarioProvider interface {
+	GetValidationScenario() string
+}
+
+// validateScenario validates the struct with the rules of the validate_<scenario> tags.
+func validateScenario(a any, scenario string) error {
+	if !isStruct(a) {
+		return nil
+	}
+
+	return validationError(scenarioValidator(scenario).Struct(a))
+}
+
+// scenarioValidator returns the validator of the validate_<scenario> tags.
+func scenarioValidator(scenario string) *validator.Validate {
+	scenarioValidator, ok := scenarioValidators.Load(scenario)
+	if !ok {
+		newValidator := validator.New()
+		newValidator.SetTagName("validate_" + scenario)
+		scenarioValidator, _ = scenarioValidators.LoadOrStore(scenario, newValidator)
+	}
+	return scenarioValidator.(*validator.Validate)
+}
+
 func validate(a any) error {
-	_, ok := a.(map[string]any)
-	if ok {
+	if !isStruct(a) {
 		return nil
 	}
 
-	err := v.Struct(a)
+	return validationError(v.Struct(a))
+}
+
+// isStruct reports whether a is a struct or a pointer to a struct, that can be validated.
+// Other values, like the collections, the scalars or nil, are not validated:
+// the items of a slice are validated one by one by [Batch].
+func isStruct(a any) bool {
+	return reflect.Indirect(reflect.ValueOf(a)).Kind() == reflect.Struct
+}
+
+// validationError translates an error of the validator into an [HTTPError] listing the invalid fields.
+func validationError(err error) error {
 	if err == nil {
 		return nil
 	}
@@ -72,3 +189,73 @@ func validate(a any) error {
 
 	return validationError
 }
+
+// formatValidationError reports the invalid fields of a validation error with the formatter.
+// Other errors are returned as is.
+func formatValidationError(err error, formatter func(validator.FieldError) ErrorItem) error {
+	var validationErr HTTPError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	fieldErrors := validatorFieldErrors(validationErr.Err)
+	if len(fieldErrors) == 0 {
+		return err
+	}
+
+	validationErr.Errors = make([]ErrorItem, 0, len(fieldErrors))
+	errorsSummary := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		item := formatter(fieldError)
+		validationErr.Errors = append(validationErr.Errors, item)
+		errorsSummary = append(errorsSummary, item.Reason)
+	}
+	validationErr.Detail = strings.Join(errorsSummary, ", ")
+
+	return validationErr
+}
+
+// validatorFieldErrors returns the invalid fields of the validator errors wrapped in err.
+func validatorFieldErrors(err error) []validator.FieldError {
+	switch err := err.(type) {
+	case validator.ValidationErrors:
+		return err
+	case interface{ Unwrap() []error }:
+		var fieldErrors []validator.FieldError
+		for _, err := range err.Unwrap() {
+			fieldErrors = append(fieldErrors, validatorFieldErrors(err)...)
+		}
+		return fieldErrors
+	case interface{ Unwrap() error }:
+		return validatorFieldErrors(err.Unwrap())
+	default:
+		return nil
+	}
+}
+
+// warmUpBody fills the caches of the validators and of the decoders with the type of the request body,
+// or of its items for slice bodies. See [WithValidationWarmup].
+func warmUpBody[B any](formDecoder *schema.Decoder, scenario string) {
+	t := reflect.TypeFor[B]()
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	body := reflect.New(t).Interface()
+	warmUpValidator(v, body)
+	if scenario != "" {
+		warmUpValidator(scenarioValidator(scenario), body)
+	}
+	_ = json.Unmarshal([]byte("{}"), body)
+	if formDecoder != nil {
+		_ = formDecoder.Decode(body, url.Values{})
+	}
+}
+
+// warmUpValidator validates a zero value, for the validator to parse the tags of its type and cache them.
+// The validation errors are expected and ignored, but invalid tags panic like on the first request.
+func warmUpValidator(val *validator.Validate, body any) {
+	_ = val.Struct(body)
+}
@@ -1,8 +1,16 @@
 package fuego
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,3 +39,166 @@ func TestValidate(t *testing.T) {
 	require.Equal(t, "400 Validation Error: Name should be max=10, Age should be min=18, Required is required, Email should be a valid email, ExternalID should be a valid UUID", errStructValidation.Error())
 	require.Len(t, errStructValidation.Errors, 5)
 }
+
+func TestValidateCollections(t *testing.T) {
+	require.NoError(t, validate([]validatableStruct{{}}))
+	require.NoError(t, validate(map[string]int{"a": 1}))
+	require.NoError(t, validateScenario(&[]validatableStruct{{}}, "create"))
+	require.Error(t, validate(validatableStruct{}))
+}
+
+type scenarioPet struct {
+	Name string `json:"name" validate:"omitempty,max=10" validate_create:"required"`
+}
+
+func TestOptionValidationScenario(t *testing.T) {
+	s := NewServer()
+	controller := func(c ContextWithBody[scenarioPet]) (scenarioPet, error) {
+		return c.Body()
+	}
+	Post(s, "/pets", controller, OptionValidationScenario("create"))
+	Patch(s, "/pets", controller)
+
+	send := func(method, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/pets", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("scenario rules apply to the route", func(t *testing.T) {
+		w := send(http.MethodPost, `{}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Name is required")
+	})
+
+	t.Run("scenario rules do not apply to other routes", func(t *testing.T) {
+		w := send(http.MethodPatch, `{}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("validate tags still apply", func(t *testing.T) {
+		w := send(http.MethodPost, `{"name":"a very long name"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Name should be max=10")
+	})
+}
+
+func TestWithValidationErrorFormatter(t *testing.T) {
+	s := NewServer(
+		WithEngineOptions(
+			WithValidationErrorFormatter(func(err validator.FieldError) ErrorItem {
+				return ErrorItem{
+					Name:   strings.ToLower(err.Field()),
+					Reason: err.Field() + " is invalid",
+					More:   map[string]any{"code": "invalid_" + err.Tag()},
+				}
+			}),
+		),
+	)
+	Post(s, "/pets", func(c ContextWithBody[scenarioPet]) (scenarioPet, error) {
+		return c.Body()
+	}, OptionValidationScenario("create"))
+	Get(s, "/error", func(c ContextNoBody) (any, error) {
+		return nil, BadRequestError{Detail: "not a validation error"}
+	})
+
+	t.Run("formats the invalid fields", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var body HTTPError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, "Name is invalid", body.Detail)
+		require.Equal(t, []ErrorItem{{
+			Name:   "name",
+			Reason: "Name is invalid",
+			More:   map[string]any{"code": "invalid_required"},
+		}}, body.Errors)
+	})
+
+	t.Run("other errors are unchanged", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/error", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "not a validation error")
+	})
+}
+
+type ctxValidatedUser struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password"`
+	Confirm  string `json:"confirm"`
+}
+
+func (u *ctxValidatedUser) ValidateCtx(ctx context.Context, deps any) error {
+	if u.Email == "admin" {
+		return ForbiddenError{Detail: "reserved email"}
+	}
+
+	var errs []error
+	if slices.Contains(deps.([]string), u.Email) {
+		errs = append(errs, ErrorItem{Name: "email", Reason: "email is already used"})
+	}
+	if u.Password != u.Confirm {
+		errs = append(errs, errors.New("passwords do not match"))
+	}
+	return errors.Join(errs...)
+}
+
+var _ CtxValidator = (*ctxValidatedUser)(nil)
+
+func TestCtxValidator(t *testing.T) {
+	s := NewServer(
+		WithEngineOptions(
+			WithValidationDeps([]string{"taken@example.com"}),
+		),
+	)
+	Post(s, "/users", func(c ContextWithBody[ctxValidatedUser]) (ctxValidatedUser, error) {
+		return c.Body()
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("valid body", func(t *testing.T) {
+		w := send(`{"email":"new@example.com","password":"a","confirm":"a"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("errors are reported together", func(t *testing.T) {
+		w := send(`{"email":"taken@example.com","password":"a","confirm":"b"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		var body HTTPError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, "Validation Error", body.Title)
+		require.Equal(t, "email is already used, passwords do not match", body.Detail)
+		require.Equal(t, []ErrorItem{
+			{Name: "email", Reason: "email is already used"},
+			{Reason: "passwords do not match"},
+		}, body.Errors)
+	})
+
+	t.Run("errors with a status are returned as is", func(t *testing.T) {
+		w := send(`{"email":"admin"}`)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("not called if the struct tags are invalid", func(t *testing.T) {
+		w := send(`{"password":"a","confirm":"b"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.NotContains(t, w.Body.String(), "passwords do not match")
+	})
+}
@@ -0,0 +1,111 @@
+package fuego
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+)
+
+// VariantHeader is the response header holding the name of the variant that served the request,
+// to label the logs and the metrics. See [OptionVariant].
+const VariantHeader = "X-Variant"
+
+// DefaultVariant is the name of the variant of the controller given to the route. See [OptionVariant].
+const DefaultVariant = "default"
+
+// Variant is an alternative controller of a route. See [OptionVariant].
+type Variant struct {
+	Name string
+	// Controller with the same signature as the controller of the route.
+	Controller any
+}
+
+// VariantSelector returns the name of the variant serving the request,
+// or an empty string (or an unknown name) for the controller of the route.
+type VariantSelector func(r *http.Request) string
+
+// OptionVariant serves the route with one of the variants, chosen for each request by the selector,
+// for gradual rollouts and A/B tests. The controller of the route serves the requests for which no variant is selected.
+// The name of the variant ([DefaultVariant] for the controller of the route) is sent in the [VariantHeader] header,
+// logged with the response, and the variants are documented in the OpenAPI spec with the x-variants extension.
+//
+//	fuego.Get(s, "/recommendations", getRecommendations,
+//		option.Variant(fuego.VariantByPercentage("ml-ranking", 10, fuego.ClientIP),
+//			fuego.Variant{Name: "ml-ranking", Controller: getRecommendationsML},
+//		),
+//	)
+//
+// It panics at registration if a variant controller has not the signature of the route controller.
+// It is only supported by the net/http server.
+func OptionVariant(selector VariantSelector, variants ...Variant) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.variantSelector = selector
+		r.variants = append(r.variants, variants...)
+
+		documented := []map[string]string{{"name": DefaultVariant, "controller": r.FullName}}
+		for _, variant := range variants {
+			documented = append(documented, map[string]string{"name": variant.Name, "controller": FuncName(variant.Controller)})
+		}
+		if r.Operation.Extensions == nil {
+			r.Operation.Extensions = make(map[string]any)
+		}
+		r.Operation.Extensions["x-variants"] = documented
+	}
+}
+
+// VariantByHeader selects the variant named by the value of the request header, for example set by a feature flag proxy.
+func VariantByHeader(header string) VariantSelector {
+	return func(r *http.Request) string {
+		return r.Header.Get(header)
+	}
+}
+
+// VariantByPercentage selects the variant for the given percentage of the requests.
+// With a key, like [ClientIP] or the user ID, a client always gets the same variant;
+// without one, the variant is picked at random for each request.
+func VariantByPercentage(name string, percent int, key func(r *http.Request) string) VariantSelector {
+	return func(r *http.Request) string {
+		var bucket int
+		if key == nil {
+			bucket = rand.IntN(100) //nolint:gosec // no security purpose
+		} else {
+			hash := fnv.New32a()
+			hash.Write([]byte(key(r)))
+			bucket = int(hash.Sum32() % 100)
+		}
+		if bucket < percent {
+			return name
+		}
+		return ""
+	}
+}
+
+// withVariants returns the controller choosing between the controller of the route and its variants.
+func withVariants[B, T any](route BaseRoute, controller func(c ContextWithBody[B]) (T, error)) func(c ContextWithBody[B]) (T, error) {
+	if route.variantSelector == nil || len(route.variants) == 0 {
+		return controller
+	}
+
+	controllers := make(map[string]func(c ContextWithBody[B]) (T, error), len(route.variants))
+	for _, variant := range route.variants {
+		variantController, ok := variant.Controller.(func(c ContextWithBody[B]) (T, error))
+		if !ok {
+			panic(fmt.Sprintf("variant %q of %s %s: the controller is a %T, expected a %s",
+				variant.Name, route.Method, route.Path, variant.Controller, reflect.TypeOf(controller)))
+		}
+		controllers[variant.Name] = variantController
+	}
+
+	return func(c ContextWithBody[B]) (T, error) {
+		name := route.variantSelector(c.Request())
+		variantController, ok := controllers[name]
+		if !ok {
+			c.SetHeader(VariantHeader, DefaultVariant)
+			return controller(c)
+		}
+		c.SetHeader(VariantHeader, name)
+		return variantController(c)
+	}
+}
@@ -0,0 +1,87 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func recommendations(c ContextNoBody) ([]string, error) {
+	return []string{"popular"}, nil
+}
+
+func recommendationsML(c ContextNoBody) ([]string, error) {
+	return []string{"personalized"}, nil
+}
+
+func TestOptionVariant(t *testing.T) {
+	s := NewServer()
+	Get(s, "/recommendations", recommendations,
+		OptionVariant(VariantByHeader("X-Ranking"), Variant{Name: "ml", Controller: recommendationsML}),
+	)
+
+	get := func(ranking string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/recommendations", nil)
+		r.Header.Set("X-Ranking", ranking)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	t.Run("selected variant", func(t *testing.T) {
+		w := get("ml")
+		require.JSONEq(t, `["personalized"]`, w.Body.String())
+		require.Equal(t, "ml", w.Header().Get(VariantHeader))
+	})
+
+	t.Run("controller of the route", func(t *testing.T) {
+		for _, ranking := range []string{"", "unknown"} {
+			w := get(ranking)
+			require.JSONEq(t, `["popular"]`, w.Body.String())
+			require.Equal(t, DefaultVariant, w.Header().Get(VariantHeader))
+		}
+	})
+
+	t.Run("documents the variants", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Value("/recommendations").Get
+		require.Equal(t, []map[string]string{
+			{"name": "default", "controller": "github.com/go-fuego/fuego.recommendations"},
+			{"name": "ml", "controller": "github.com/go-fuego/fuego.recommendationsML"},
+		}, operation.Extensions["x-variants"])
+	})
+
+	t.Run("variants with another signature panic", func(t *testing.T) {
+		require.PanicsWithValue(t,
+			`variant "ml" of GET /other: the controller is a func(fuego.ContextWithBody[interface {}]) (string, error), expected a func(fuego.ContextWithBody[interface {}]) ([]string, error)`,
+			func() {
+				Get(s, "/other", recommendations, OptionVariant(VariantByHeader("X-Ranking"), Variant{
+					Name:       "ml",
+					Controller: func(c ContextNoBody) (string, error) { return "", nil },
+				}))
+			})
+	})
+}
+
+func TestVariantByPercentage(t *testing.T) {
+	byUser := VariantByPercentage("beta", 30, func(r *http.Request) string { return r.Header.Get("X-User") })
+
+	selected := 0
+	for i := range 1000 {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-User", "user-"+strconv.Itoa(i))
+		variant := byUser(r)
+		if variant == "beta" {
+			selected++
+		}
+		// The same user always gets the same variant
+		require.Equal(t, variant, byUser(r))
+	}
+	require.InDelta(t, 300, selected, 60)
+
+	require.Equal(t, "beta", VariantByPercentage("beta", 100, nil)(httptest.NewRequest(http.MethodGet, "/", nil)))
+	require.Empty(t, VariantByPercentage("beta", 0, nil)(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
@@ -0,0 +1,597 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrWebhookDeliveryNotFound is returned by a [WebhookStore] when a delivery does not exist.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrWebhooksClosed is returned by [Webhooks.Send] and [Webhooks.Redeliver] after [Webhooks.Close].
+	ErrWebhooksClosed = errors.New("webhooks closed")
+	// ErrWebhookDeliveryPending is returned by [Webhooks.Redeliver] when the delivery is still in progress
+	// or waiting for a retry.
+	ErrWebhookDeliveryPending = errors.New("webhook delivery still pending")
+)
+
+// WebhookSignature is the signature scheme of the webhooks sent by [Webhooks].
+// Receivers written with Fuego can verify them with:
+//
+//	option.VerifySignature(fuego.WebhookSignature, fuego.StaticSecret(secret))
+var WebhookSignature = WebhookSignatureScheme{}
+
+// WebhookSignatureScheme verifies the signature of the webhooks sent by [Webhooks].
+// The X-Webhook-Timestamp header contains the Unix time of the attempt, in seconds,
+// and the X-Webhook-Signature header the HMAC-SHA256 of "<timestamp>.<body>", for example "sha256=5257a869...".
+// Signing the timestamp prevents replay attacks: receivers must reject the deliveries
+// whose timestamp is outside of a tolerance window, 5 minutes by default.
+type WebhookSignatureScheme struct {
+	// Maximum age of the signature timestamp. Defaults to 5 minutes.
+	// A negative value disables the check.
+	Tolerance time.Duration
+
+	// Clock of the timestamp checks. Set to the clock of the server by [OptionVerifySignature], see [WithClock].
+	now func() time.Time
+}
+
+const (
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookSignaturePrefix = "sha256="
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+)
+
+var _ SignatureScheme = WebhookSignatureScheme{}
+
+func (s WebhookSignatureScheme) VerifySignature(r *http.Request, body []byte, secret []byte) error {
+	value := r.Header.Get(webhookSignatureHeader)
+	if value == "" {
+		return fmt.Errorf("%w: header %s", ErrMissingSignature, webhookSignatureHeader)
+	}
+	timestamp := r.Header.Get(webhookTimestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidSignature, webhookTimestampHeader)
+	}
+
+	if err := checkSignatureTimestamp(unix, s.Tolerance, s.now); err != nil {
+		return err
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(value, webhookSignaturePrefix))
+	if err != nil || !hmac.Equal(signature, signWebhook(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// signWebhook returns the HMAC-SHA256 of "<timestamp>.<body>".
+func signWebhook(secret []byte, timestamp string, body []byte) []byte {
+	return computeHMACSHA256(secret, append([]byte(timestamp+"."), body...))
+}
+
+// WebhookSubscription is an endpoint subscribed to some events.
+type WebhookSubscription struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret used to sign the deliveries. Never serialized.
+	Secret string `json:"-"`
+	// Events the subscription receives. Empty means all events.
+	Events []string `json:"events,omitempty"`
+}
+
+func (s WebhookSubscription) subscribedTo(event string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, event)
+}
+
+// WebhookDeliveryStatus is the status of a [WebhookDelivery].
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending means the delivery is in progress or waiting for a retry.
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliverySucceeded means the endpoint answered with a 2xx status code.
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed means all attempts failed: the delivery is in the dead-letter queue.
+	// It can be retried with [Webhooks.Redeliver].
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event sent to a [WebhookSubscription].
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	SubscriptionID string                `json:"subscriptionId"`
+	URL            string                `json:"url"`
+	Event          string                `json:"event"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode int                   `json:"lastStatusCode,omitempty"`
+	LastError      string                `json:"lastError,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+}
+
+// WebhookStore stores the subscriptions and the deliveries of [Webhooks].
+// [NewInMemoryWebhookStore] is provided for tests and single-instance deployments.
+type WebhookStore interface {
+	// Subscriptions returns the subscriptions to the given event.
+	Subscriptions(ctx context.Context, event string) ([]WebhookSubscription, error)
+	// Subscription returns a subscription by ID.
+	Subscription(ctx context.Context, id string) (WebhookSubscription, error)
+	// SaveDelivery creates or updates a delivery.
+	SaveDelivery(ctx context.Context, delivery WebhookDelivery) error
+	// Delivery returns a delivery by ID, or [ErrWebhookDeliveryNotFound].
+	Delivery(ctx context.Context, id string) (WebhookDelivery, error)
+	// Deliveries returns the deliveries with the given status, or all deliveries if status is empty.
+	Deliveries(ctx context.Context, status WebhookDeliveryStatus) ([]WebhookDelivery, error)
+}
+
+// InMemoryWebhookStore is a [WebhookStore] keeping everything in memory.
+type InMemoryWebhookStore struct {
+	mu            sync.RWMutex
+	subscriptions []WebhookSubscription
+	deliveries    map[string]WebhookDelivery
+}
+
+var _ WebhookStore = &InMemoryWebhookStore{}
+
+// NewInMemoryWebhookStore creates an empty [InMemoryWebhookStore].
+func NewInMemoryWebhookStore(subscriptions ...WebhookSubscription) *InMemoryWebhookStore {
+	return &InMemoryWebhookStore{
+		subscriptions: subscriptions,
+		deliveries:    make(map[string]WebhookDelivery),
+	}
+}
+
+// AddSubscription subscribes an endpoint. An ID is generated if empty.
+func (s *InMemoryWebhookStore) AddSubscription(subscription WebhookSubscription) WebhookSubscription {
+	if subscription.ID == "" {
+		subscription.ID = newWebhookID()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions = append(s.subscriptions, subscription)
+	return subscription
+}
+
+func (s *InMemoryWebhookStore) Subscriptions(_ context.Context, event string) ([]WebhookSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var subscriptions []WebhookSubscription
+	for _, subscription := range s.subscriptions {
+		if subscription.subscribedTo(event) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (s *InMemoryWebhookStore) Subscription(_ context.Context, id string) (WebhookSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			return subscription, nil
+		}
+	}
+	return WebhookSubscription{}, fmt.Errorf("webhook subscription %s not found", id)
+}
+
+func (s *InMemoryWebhookStore) SaveDelivery(_ context.Context, delivery WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (s *InMemoryWebhookStore) Delivery(_ context.Context, id string) (WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return WebhookDelivery{}, ErrWebhookDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+func (s *InMemoryWebhookStore) Deliveries(_ context.Context, status WebhookDeliveryStatus) ([]WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	deliveries := make([]WebhookDelivery, 0, len(s.deliveries))
+	for _, delivery := range s.deliveries {
+		if status == "" || delivery.Status == status {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b WebhookDelivery) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return deliveries, nil
+}
+
+// WebhooksConfig configures [Webhooks].
+type WebhooksConfig struct {
+	// Store of the subscriptions and deliveries. Defaults to an empty [InMemoryWebhookStore].
+	Store WebhookStore
+	// HTTP client used to send the deliveries. Defaults to a client with a 10 seconds timeout.
+	Client *http.Client
+	// Maximum number of attempts before the delivery is moved to the dead-letter queue. Defaults to 5.
+	MaxAttempts int
+	// Delay before the first retry, doubled after each attempt. Defaults to 1 second.
+	InitialBackoff time.Duration
+	// Maximum delay between two attempts. Defaults to 1 hour.
+	MaxBackoff time.Duration
+	// Called when a delivery failed all its attempts.
+	OnDeadLetter func(WebhookDelivery)
+	// Clock of the deliveries dates. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Webhooks sends events to subscribed endpoints.
+// Each delivery is signed with HMAC-SHA256, timestamp included (see [WebhookSignature]), and retried
+// with exponential backoff. Deliveries failing all their attempts are kept
+// with the [WebhookDeliveryFailed] status and can be redelivered.
+type Webhooks struct {
+	config WebhooksConfig
+
+	// Serializes the redeliveries, so that a delivery is not dispatched twice.
+	redeliverMu sync.Mutex
+	// Serializes the dispatches and Close, so that no delivery starts once closed.
+	dispatchMu sync.Mutex
+	// IDs of the deliveries in progress or waiting for a retry in this process.
+	inFlight map[string]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWebhooks creates a webhook sender. Call [Webhooks.Close] to stop pending retries,
+// and [Webhooks.Resume] to restart them with a persistent store.
+// For example:
+//
+//	store := fuego.NewInMemoryWebhookStore()
+//	store.AddSubscription(fuego.WebhookSubscription{URL: "https://example.com/hook", Secret: "s3cr3t"})
+//	webhooks := fuego.NewWebhooks(fuego.WebhooksConfig{Store: store})
+//	defer webhooks.Close()
+//
+//	webhooks.Send(ctx, "order.created", order)
+func NewWebhooks(config WebhooksConfig) *Webhooks {
+	if config.Store == nil {
+		config.Store = NewInMemoryWebhookStore()
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Hour
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Webhooks{
+		config:   config,
+		inFlight: make(map[string]struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Send delivers the event to all the subscribed endpoints, in the background.
+// The payload is serialized to JSON. It returns the created deliveries, or [ErrWebhooksClosed] after [Webhooks.Close].
+func (wh *Webhooks) Send(ctx context.Context, event string, payload any) ([]WebhookDelivery, error) {
+	if wh.ctx.Err() != nil {
+		return nil, ErrWebhooksClosed
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize webhook payload: %w", err)
+	}
+
+	subscriptions, err := wh.config.Store.Subscriptions(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		delivery := WebhookDelivery{
+			ID:             newWebhookID(),
+			SubscriptionID: subscription.ID,
+			URL:            subscription.URL,
+			Event:          event,
+			Payload:        body,
+			Status:         WebhookDeliveryPending,
+			CreatedAt:      wh.config.Now(),
+		}
+		if err := wh.config.Store.SaveDelivery(ctx, delivery); err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+		if err := wh.dispatch(delivery, subscription.Secret, 0); err != nil {
+			return deliveries, err
+		}
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver sends a delivery again, typically one from the dead-letter queue.
+// Its attempts are reset. The pending deliveries, still in progress or waiting for a retry,
+// return [ErrWebhookDeliveryPending], unless their retry is overdue and not in progress in this process:
+// they were interrupted by a restart, see also [Webhooks.Resume].
+func (wh *Webhooks) Redeliver(ctx context.Context, id string) (WebhookDelivery, error) {
+	wh.redeliverMu.Lock()
+	defer wh.redeliverMu.Unlock()
+
+	if wh.ctx.Err() != nil {
+		return WebhookDelivery{}, ErrWebhooksClosed
+	}
+	delivery, err := wh.config.Store.Delivery(ctx, id)
+	if err != nil {
+		return delivery, err
+	}
+	if delivery.Status == WebhookDeliveryPending && !wh.stuck(delivery) {
+		return delivery, ErrWebhookDeliveryPending
+	}
+	subscription, err := wh.config.Store.Subscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return delivery, err
+	}
+
+	delivery.Status = WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Time{}
+	if err := wh.config.Store.SaveDelivery(ctx, delivery); err != nil {
+		return delivery, err
+	}
+	return delivery, wh.dispatch(delivery, subscription.Secret, 0)
+}
+
+// Resume dispatches again the pending deliveries of the store not in progress in this process,
+// typically interrupted by a restart. Their retries are sent at their NextAttemptAt.
+// With a persistent store shared by several instances, call it once at startup, on a single instance:
+//
+//	webhooks := fuego.NewWebhooks(fuego.WebhooksConfig{Store: store})
+//	defer webhooks.Close()
+//	if err := webhooks.Resume(ctx); err != nil {
+//		return err
+//	}
+func (wh *Webhooks) Resume(ctx context.Context) error {
+	deliveries, err := wh.config.Store.Deliveries(ctx, WebhookDeliveryPending)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		subscription, err := wh.config.Store.Subscription(ctx, delivery.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		delay := delivery.NextAttemptAt.Sub(wh.config.Now())
+		if err := wh.dispatch(delivery, subscription.Secret, delay); err != nil && !errors.Is(err, ErrWebhookDeliveryPending) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stuck tells if the pending delivery is not in progress in this process and its retry is overdue.
+func (wh *Webhooks) stuck(delivery WebhookDelivery) bool {
+	wh.dispatchMu.Lock()
+	defer wh.dispatchMu.Unlock()
+	_, inFlight := wh.inFlight[delivery.ID]
+	return !inFlight && !delivery.NextAttemptAt.IsZero() && delivery.NextAttemptAt.Before(wh.config.Now())
+}
+
+// Close stops the pending retries and waits for in-flight deliveries.
+// Interrupted deliveries stay pending in the store, see [Webhooks.Resume].
+// The later sends fail with [ErrWebhooksClosed].
+func (wh *Webhooks) Close() {
+	wh.dispatchMu.Lock()
+	wh.cancel()
+	wh.dispatchMu.Unlock()
+	wh.wg.Wait()
+}
+
+// dispatch delivers in the background after the delay, unless the webhooks are closed
+// or the delivery is already in progress in this process.
+func (wh *Webhooks) dispatch(delivery WebhookDelivery, secret string, delay time.Duration) error {
+	wh.dispatchMu.Lock()
+	defer wh.dispatchMu.Unlock()
+	if wh.ctx.Err() != nil {
+		return ErrWebhooksClosed
+	}
+	if _, ok := wh.inFlight[delivery.ID]; ok {
+		return ErrWebhookDeliveryPending
+	}
+
+	wh.inFlight[delivery.ID] = struct{}{}
+	wh.wg.Add(1)
+	go func() {
+		defer wh.wg.Done()
+		defer func() {
+			wh.dispatchMu.Lock()
+			defer wh.dispatchMu.Unlock()
+			delete(wh.inFlight, delivery.ID)
+		}()
+		wh.deliver(delivery, secret, delay)
+	}()
+	return nil
+}
+
+func (wh *Webhooks) deliver(delivery WebhookDelivery, secret string, delay time.Duration) {
+	for {
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-wh.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		delivery.Attempts++
+		delivery.LastStatusCode, delivery.LastError = wh.attempt(delivery, secret)
+
+		switch {
+		case delivery.LastError == "":
+			delivery.Status = WebhookDeliverySucceeded
+			delivery.NextAttemptAt = time.Time{}
+		case delivery.Attempts >= wh.config.MaxAttempts:
+			delivery.Status = WebhookDeliveryFailed
+			delivery.NextAttemptAt = time.Time{}
+		default:
+			delay = wh.backoff(delivery.Attempts)
+			delivery.NextAttemptAt = wh.config.Now().Add(delay)
+		}
+
+		// The sender context may be canceled: the delivery state must still be saved.
+		if err := wh.config.Store.SaveDelivery(context.WithoutCancel(wh.ctx), delivery); err != nil {
+			slog.Error("cannot save webhook delivery", "id", delivery.ID, "status", delivery.Status, "error", err)
+		}
+
+		if delivery.Status == WebhookDeliveryFailed && wh.config.OnDeadLetter != nil {
+			wh.config.OnDeadLetter(delivery)
+		}
+		if delivery.Status != WebhookDeliveryPending {
+			return
+		}
+	}
+}
+
+// attempt sends the delivery once and returns the response status code and the error message, if any.
+func (wh *Webhooks) attempt(delivery WebhookDelivery, secret string) (int, string) {
+	req, err := http.NewRequestWithContext(wh.ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	timestamp := strconv.FormatInt(wh.config.Now().Unix(), 10)
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookSignatureHeader, webhookSignaturePrefix+hex.EncodeToString(signWebhook([]byte(secret), timestamp, delivery.Payload)))
+
+	resp, err := wh.config.Client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, "unexpected status code " + resp.Status
+	}
+	return resp.StatusCode, ""
+}
+
+func (wh *Webhooks) backoff(attempts int) time.Duration {
+	backoff := wh.config.InitialBackoff
+	for i := 1; i < attempts && backoff < wh.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, wh.config.MaxBackoff)
+}
+
+// RegisterAdminRoutes registers the delivery-status endpoints on the given server or group:
+//
+//   - GET /webhooks/deliveries: list the deliveries, optionally filtered by status
+//   - GET /webhooks/deliveries/{id}: get a delivery
+//   - POST /webhooks/deliveries/{id}/redeliver: send a delivery again
+//
+// These endpoints expose the payloads: protect them, for example:
+//
+//	webhooks.RegisterAdminRoutes(fuego.Group(s, "/admin", option.AllowIPs("10.0.0.0/8")))
+func (wh *Webhooks) RegisterAdminRoutes(s *Server) {
+	group := Group(s, "/webhooks/deliveries", OptionTags("webhooks"))
+
+	Get(group, "", func(c ContextNoBody) ([]WebhookDelivery, error) {
+		return wh.config.Store.Deliveries(c.Context(), WebhookDeliveryStatus(c.QueryParam("status")))
+	},
+		OptionSummary("List webhook deliveries"),
+		OptionQuery("status", "Filter by delivery status",
+			ParamExample("dead letters", string(WebhookDeliveryFailed)),
+		),
+	)
+
+	Get(group, "/{id}", func(c ContextNoBody) (WebhookDelivery, error) {
+		delivery, err := wh.config.Store.Delivery(c.Context(), c.PathParam("id"))
+		return delivery, webhookDeliveryError(err)
+	},
+		OptionSummary("Get a webhook delivery"),
+		OptionAddResponse(http.StatusNotFound, "Delivery not found", Response{Type: HTTPError{}}),
+	)
+
+	Post(group, "/{id}/redeliver", func(c ContextNoBody) (WebhookDelivery, error) {
+		delivery, err := wh.Redeliver(c.Context(), c.PathParam("id"))
+		return delivery, webhookDeliveryError(err)
+	},
+		OptionSummary("Redeliver a webhook delivery"),
+		OptionAddResponse(http.StatusNotFound, "Delivery not found", Response{Type: HTTPError{}}),
+		OptionAddResponse(http.StatusConflict, "Delivery still pending", Response{Type: HTTPError{}}),
+		OptionAddResponse(http.StatusServiceUnavailable, "Webhooks closed", Response{Type: HTTPError{}}),
+		OptionDefaultStatusCode(http.StatusAccepted),
+	)
+}
+
+func webhookDeliveryError(err error) error {
+	if errors.Is(err, ErrWebhooksClosed) {
+		return HTTPError{
+			Title:  "Service Unavailable",
+			Status: http.StatusServiceUnavailable,
+			Err:    err,
+			Detail: "the webhooks are shutting down",
+		}
+	}
+	if errors.Is(err, ErrWebhookDeliveryPending) {
+		return ConflictError{
+			Title:  "Conflict",
+			Status: http.StatusConflict,
+			Err:    err,
+			Detail: "the delivery is still in progress or waiting for a retry",
+		}
+	}
+	if errors.Is(err, ErrWebhookDeliveryNotFound) {
+		return NotFoundError{
+			Title:  "Not Found",
+			Status: http.StatusNotFound,
+			Err:    err,
+			Detail: err.Error(),
+		}
+	}
+	return err
+}
+
+func newWebhookID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,333 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func waitForDeliveryStatus(t *testing.T, store WebhookStore, id string, status WebhookDeliveryStatus) WebhookDelivery {
+	t.Helper()
+	var delivery WebhookDelivery
+	require.Eventually(t, func() bool {
+		var err error
+		delivery, err = store.Delivery(context.Background(), id)
+		return err == nil && delivery.Status == status
+	}, 2*time.Second, 5*time.Millisecond)
+	return delivery
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Run("signed delivery verified by the receiver", func(t *testing.T) {
+		receiver := NewServer(WithoutLogger())
+		received := make(chan string, 1)
+		Post(receiver, "/hook", func(c ContextWithBody[webhookEvent]) (string, error) {
+			event, err := c.Body()
+			if err != nil {
+				return "", err
+			}
+			received <- c.Header("X-Webhook-Event") + ":" + event.Action
+			return "ok", nil
+		}, OptionVerifySignature(WebhookSignature, StaticSecret("s3cr3t")))
+		endpoint := httptest.NewServer(receiver.Mux)
+		defer endpoint.Close()
+
+		store := NewInMemoryWebhookStore()
+		store.AddSubscription(WebhookSubscription{URL: endpoint.URL + "/hook", Secret: "s3cr3t", Events: []string{"pet.created"}})
+		store.AddSubscription(WebhookSubscription{URL: endpoint.URL + "/other", Events: []string{"pet.deleted"}})
+		webhooks := NewWebhooks(WebhooksConfig{Store: store})
+		defer webhooks.Close()
+
+		deliveries, err := webhooks.Send(context.Background(), "pet.created", webhookEvent{Action: "adopted"})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+
+		require.Equal(t, "pet.created:adopted", <-received)
+		delivery := waitForDeliveryStatus(t, store, deliveries[0].ID, WebhookDeliverySucceeded)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, http.StatusOK, delivery.LastStatusCode)
+	})
+
+	t.Run("retries then succeeds", func(t *testing.T) {
+		var calls atomic.Int32
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer endpoint.Close()
+
+		store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: endpoint.URL})
+		webhooks := NewWebhooks(WebhooksConfig{Store: store, InitialBackoff: time.Millisecond})
+		defer webhooks.Close()
+
+		deliveries, err := webhooks.Send(context.Background(), "ping", nil)
+		require.NoError(t, err)
+
+		delivery := waitForDeliveryStatus(t, store, deliveries[0].ID, WebhookDeliverySucceeded)
+		require.Equal(t, 3, delivery.Attempts)
+		require.Empty(t, delivery.LastError)
+	})
+
+	t.Run("dead letter and redelivery", func(t *testing.T) {
+		var healthy atomic.Bool
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !healthy.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer endpoint.Close()
+
+		deadLetters := make(chan WebhookDelivery, 1)
+		store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: endpoint.URL})
+		webhooks := NewWebhooks(WebhooksConfig{
+			Store:          store,
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			OnDeadLetter:   func(d WebhookDelivery) { deadLetters <- d },
+		})
+		defer webhooks.Close()
+
+		deliveries, err := webhooks.Send(context.Background(), "ping", nil)
+		require.NoError(t, err)
+
+		deadLetter := <-deadLetters
+		require.Equal(t, WebhookDeliveryFailed, deadLetter.Status)
+		require.Equal(t, 2, deadLetter.Attempts)
+		require.Equal(t, http.StatusInternalServerError, deadLetter.LastStatusCode)
+
+		failed, err := store.Deliveries(context.Background(), WebhookDeliveryFailed)
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+
+		healthy.Store(true)
+		_, err = webhooks.Redeliver(context.Background(), deliveries[0].ID)
+		require.NoError(t, err)
+		delivery := waitForDeliveryStatus(t, store, deliveries[0].ID, WebhookDeliverySucceeded)
+		require.Equal(t, 1, delivery.Attempts)
+
+		_, err = webhooks.Redeliver(context.Background(), deliveries[0].ID)
+		require.NoError(t, err, "succeeded deliveries can be sent again")
+	})
+
+	t.Run("close stops pending retries", func(t *testing.T) {
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer endpoint.Close()
+
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: endpoint.URL})
+		webhooks := NewWebhooks(WebhooksConfig{Store: store, InitialBackoff: time.Hour, Now: func() time.Time { return now }})
+
+		deliveries, err := webhooks.Send(context.Background(), "ping", nil)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			delivery, _ := store.Delivery(context.Background(), deliveries[0].ID)
+			return delivery.Attempts == 1
+		}, time.Second, 5*time.Millisecond)
+
+		webhooks.Close()
+		delivery, err := store.Delivery(context.Background(), deliveries[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, WebhookDeliveryPending, delivery.Status)
+		require.Equal(t, now, delivery.CreatedAt)
+		require.Equal(t, now.Add(time.Hour), delivery.NextAttemptAt)
+	})
+
+	t.Run("resume after a restart", func(t *testing.T) {
+		var calls atomic.Int32
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+		}))
+		defer endpoint.Close()
+
+		now := time.Now()
+		store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: endpoint.URL})
+		interrupted := WebhookDelivery{ID: "interrupted", SubscriptionID: "sub", URL: endpoint.URL, Status: WebhookDeliveryPending, Attempts: 1, NextAttemptAt: now.Add(-time.Minute)}
+		later := WebhookDelivery{ID: "later", SubscriptionID: "sub", URL: endpoint.URL, Status: WebhookDeliveryPending, Attempts: 1, NextAttemptAt: now.Add(time.Hour)}
+		require.NoError(t, store.SaveDelivery(context.Background(), interrupted))
+		require.NoError(t, store.SaveDelivery(context.Background(), later))
+
+		webhooks := NewWebhooks(WebhooksConfig{Store: store})
+		require.NoError(t, webhooks.Resume(context.Background()))
+		require.NoError(t, webhooks.Resume(context.Background()), "the deliveries in progress are skipped")
+
+		delivery := waitForDeliveryStatus(t, store, "interrupted", WebhookDeliverySucceeded)
+		require.Equal(t, 2, delivery.Attempts)
+
+		_, err := webhooks.Redeliver(context.Background(), "later")
+		require.ErrorIs(t, err, ErrWebhookDeliveryPending, "waiting for its retry in this process")
+
+		webhooks.Close()
+		require.Equal(t, int32(1), calls.Load())
+		delivery, err = store.Delivery(context.Background(), "later")
+		require.NoError(t, err)
+		require.Equal(t, WebhookDeliveryPending, delivery.Status)
+	})
+
+	t.Run("redeliver overdue pending deliveries", func(t *testing.T) {
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer endpoint.Close()
+
+		store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: endpoint.URL})
+		stuck := WebhookDelivery{ID: "stuck", SubscriptionID: "sub", URL: endpoint.URL, Status: WebhookDeliveryPending, Attempts: 3, NextAttemptAt: time.Now().Add(-time.Hour)}
+		require.NoError(t, store.SaveDelivery(context.Background(), stuck))
+		webhooks := NewWebhooks(WebhooksConfig{Store: store})
+		defer webhooks.Close()
+
+		_, err := webhooks.Redeliver(context.Background(), "stuck")
+		require.NoError(t, err)
+		delivery := waitForDeliveryStatus(t, store, "stuck", WebhookDeliverySucceeded)
+		require.Equal(t, 1, delivery.Attempts)
+	})
+
+	t.Run("no delivery after close", func(t *testing.T) {
+		var calls atomic.Int32
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+		}))
+		defer endpoint.Close()
+
+		store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: endpoint.URL})
+		require.NoError(t, store.SaveDelivery(context.Background(), WebhookDelivery{ID: "failed", SubscriptionID: "sub", Status: WebhookDeliveryFailed}))
+		webhooks := NewWebhooks(WebhooksConfig{Store: store})
+		webhooks.Close()
+
+		deliveries, err := webhooks.Send(context.Background(), "ping", nil)
+		require.ErrorIs(t, err, ErrWebhooksClosed)
+		require.Empty(t, deliveries)
+		_, err = webhooks.Redeliver(context.Background(), "failed")
+		require.ErrorIs(t, err, ErrWebhooksClosed)
+		require.Zero(t, calls.Load())
+	})
+
+	t.Run("backoff is capped", func(t *testing.T) {
+		webhooks := NewWebhooks(WebhooksConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+		require.Equal(t, time.Second, webhooks.backoff(1))
+		require.Equal(t, 4*time.Second, webhooks.backoff(3))
+		require.Equal(t, 5*time.Second, webhooks.backoff(10))
+	})
+}
+
+func TestWebhookSignature(t *testing.T) {
+	body := `{"action":"adopted"}`
+	now := time.Unix(1700000000, 0)
+	scheme := WebhookSignatureScheme{now: func() time.Time { return now }}
+
+	request := func(timestamp, signature string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("X-Webhook-Timestamp", timestamp)
+		r.Header.Set("X-Webhook-Signature", signature)
+		return r
+	}
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		expected  error
+	}{
+		{name: "valid", timestamp: "1700000000", signature: "sha256=" + hmacHex("s3cr3t", "1700000000."+body)},
+		{name: "within tolerance", timestamp: "1700000200", signature: "sha256=" + hmacHex("s3cr3t", "1700000200."+body)},
+		{name: "missing signature", timestamp: "1700000000", expected: ErrMissingSignature},
+		{name: "missing timestamp", signature: "sha256=" + hmacHex("s3cr3t", body), expected: ErrInvalidSignature},
+		{name: "body only signed", timestamp: "1700000000", signature: "sha256=" + hmacHex("s3cr3t", body), expected: ErrInvalidSignature},
+		{name: "timestamp changed", timestamp: "1700000100", signature: "sha256=" + hmacHex("s3cr3t", "1700000000."+body), expected: ErrInvalidSignature},
+		{name: "replayed", timestamp: "1699999000", signature: "sha256=" + hmacHex("s3cr3t", "1699999000."+body), expected: ErrSignatureExpired},
+		{name: "wrong secret", timestamp: "1700000000", signature: "sha256=" + hmacHex("other", "1700000000."+body), expected: ErrInvalidSignature},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := scheme.VerifySignature(request(tc.timestamp, tc.signature), []byte(body), []byte("s3cr3t"))
+			if tc.expected == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.expected)
+			}
+		})
+	}
+
+	t.Run("signed by the sender", func(t *testing.T) {
+		received := make(chan *http.Request, 1)
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- r
+		}))
+		defer endpoint.Close()
+
+		store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: endpoint.URL, Secret: "s3cr3t"})
+		webhooks := NewWebhooks(WebhooksConfig{Store: store, Now: func() time.Time { return now }})
+		defer webhooks.Close()
+
+		_, err := webhooks.Send(context.Background(), "pet.created", webhookEvent{Action: "adopted"})
+		require.NoError(t, err)
+
+		r := <-received
+		require.Equal(t, "1700000000", r.Header.Get("X-Webhook-Timestamp"))
+		require.NoError(t, scheme.VerifySignature(r, []byte(body), []byte("s3cr3t")))
+	})
+}
+
+func TestWebhooksAdminRoutes(t *testing.T) {
+	store := NewInMemoryWebhookStore(WebhookSubscription{ID: "sub", URL: "http://127.0.0.1:0"})
+	webhooks := NewWebhooks(WebhooksConfig{Store: store, MaxAttempts: 1})
+	defer webhooks.Close()
+
+	s := NewServer(WithoutLogger())
+	webhooks.RegisterAdminRoutes(Group(s, "/admin"))
+
+	deliveries, err := webhooks.Send(context.Background(), "ping", map[string]int{"n": 1})
+	require.NoError(t, err)
+	waitForDeliveryStatus(t, store, deliveries[0].ID, WebhookDeliveryFailed)
+
+	t.Run("list dead letters", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/webhooks/deliveries?status=failed", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var list []WebhookDelivery
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list, 1)
+		require.Equal(t, deliveries[0].ID, list[0].ID)
+		require.JSONEq(t, `{"n":1}`, string(list[0].Payload))
+	})
+
+	t.Run("get unknown delivery", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/webhooks/deliveries/unknown", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/webhooks/deliveries/"+deliveries[0].ID+"/redeliver", nil))
+		require.Equal(t, http.StatusAccepted, w.Code)
+		body, _ := io.ReadAll(w.Body)
+		require.Contains(t, string(body), `"status":"pending"`)
+	})
+
+	t.Run("pending deliveries are not redelivered", func(t *testing.T) {
+		pending := WebhookDelivery{ID: "pending", SubscriptionID: "sub", Status: WebhookDeliveryPending}
+		require.NoError(t, store.SaveDelivery(context.Background(), pending))
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/webhooks/deliveries/pending/redeliver", nil))
+		require.Equal(t, http.StatusConflict, w.Code)
+
+		delivery, err := store.Delivery(context.Background(), "pending")
+		require.NoError(t, err)
+		require.Equal(t, pending, delivery, "the delivery is not dispatched")
+	})
+
+	t.Run("routes are documented", func(t *testing.T) {
+		require.NotNil(t, s.OpenAPI.Description().Paths.Find("/admin/webhooks/deliveries/{id}/redeliver"))
+	})
+}
@@ -0,0 +1,150 @@
+package fuego
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"golang.org/x/net/websocket"
+)
+
+// WebsocketConn is a websocket connection exchanging JSON messages:
+// the messages received from the client are of type In, and the messages sent to the client of type Out.
+type WebsocketConn[In, Out any] struct {
+	conn    *websocket.Conn
+	request *http.Request
+}
+
+// Receive waits for the next message of the client. The messages are validated like the request bodies.
+// An invalid message returns an [HTTPError] with a 400 status, and the connection stays open.
+// It returns [io.EOF] when the client closes the connection.
+func (c *WebsocketConn[In, Out]) Receive() (In, error) {
+	var message In
+	var data []byte
+	if err := websocket.Message.Receive(c.conn, &data); err != nil {
+		return message, err
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return message, BadRequestError{Err: err, Title: "Invalid message", Detail: err.Error()}
+	}
+	return message, validate(message)
+}
+
+// Send sends a message to the client. It can be called concurrently with Receive.
+func (c *WebsocketConn[In, Out]) Send(message Out) error {
+	return websocket.JSON.Send(c.conn, message)
+}
+
+// Request returns the upgraded HTTP request.
+func (c *WebsocketConn[In, Out]) Request() *http.Request {
+	return c.request
+}
+
+// Context returns the context of the upgraded HTTP request.
+func (c *WebsocketConn[In, Out]) Context() context.Context {
+	return c.request.Context()
+}
+
+// Close closes the connection.
+func (c *WebsocketConn[In, Out]) Close() error {
+	return c.conn.Close()
+}
+
+// Websocket registers a websocket endpoint on a GET route: the connection is upgraded, then given to the handler,
+// and closed when the handler returns. The messages are JSON documents: In is the type of the messages
+// received from the client, and Out of the messages sent to the client.
+// Both are documented in the x-websocket extension of the operation in the OpenAPI spec.
+//
+//	fuego.Websocket(s, "/chat", func(conn *fuego.WebsocketConn[ChatMessage, ChatEvent]) error {
+//		for {
+//			message, err := conn.Receive()
+//			if errors.Is(err, io.EOF) {
+//				return nil
+//			}
+//			if err != nil {
+//				return err
+//			}
+//			if err := conn.Send(ChatEvent{Author: message.Author, Text: message.Text}); err != nil {
+//				return err
+//			}
+//		}
+//	})
+//
+// Only the browser connections from the same origin as the server are accepted, and the origins of [OptionWebsocketOrigins].
+// Clients that are not browsers may not send an origin: their connections are accepted.
+// The maximum size of the messages is the maximum body size of the server, see [WithMaxBodySize].
+func Websocket[In, Out any](s *Server, path string, handler func(conn *WebsocketConn[In, Out]) error, options ...func(*BaseRoute)) *Route[any, any] {
+	var allowedOrigin func(origin string) bool
+	options = append([]func(*BaseRoute){
+		func(r *BaseRoute) { r.FullName = FuncName(handler) },
+		optionWebsocket[In, Out],
+	}, options...)
+	options = append(options, func(r *BaseRoute) { allowedOrigin = originMatcher(r.WebsocketOrigins) })
+
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			origin, err := websocket.Origin(config, r)
+			if err != nil {
+				return err
+			}
+			if origin != nil && origin.Host != r.Host && !allowedOrigin(origin.Scheme+"://"+origin.Host) {
+				return errors.New("websocket origin not allowed")
+			}
+			config.Origin = origin
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			if s.maxBodySize > 0 {
+				conn.MaxPayloadBytes = int(s.maxBodySize)
+			}
+			if err := handler(&WebsocketConn[In, Out]{conn: conn, request: conn.Request()}); err != nil {
+				slog.Error("Websocket handler error", "path", conn.Request().URL.Path, "error", err)
+			}
+		},
+	}
+
+	return GetStd(s, path, func(w http.ResponseWriter, r *http.Request) {
+		server.ServeHTTP(hijackableWriter{w}, r)
+	}, options...)
+}
+
+// OptionWebsocketOrigins allows the cross-origin connections from the given origins to a [Websocket] route.
+// A single wildcard can be used in the origin, for example "https://*.example.com".
+func OptionWebsocketOrigins(origins ...string) func(*BaseRoute) {
+	return func(r *BaseRoute) {
+		r.WebsocketOrigins = append(r.WebsocketOrigins, origins...)
+	}
+}
+
+// optionWebsocket documents the upgrade response and the messages of a [Websocket] route.
+func optionWebsocket[In, Out any](r *BaseRoute) {
+	r.DefaultStatusCode = http.StatusSwitchingProtocols
+	r.Operation.AddResponse(http.StatusSwitchingProtocols,
+		openapi3.NewResponse().WithDescription("Switching Protocols _(websocket connection)_").WithContent(openapi3.Content{}))
+
+	receive := SchemaTagFromType(r.OpenAPI, *new(In))
+	send := SchemaTagFromType(r.OpenAPI, *new(Out))
+	if r.Operation.Extensions == nil {
+		r.Operation.Extensions = make(map[string]any)
+	}
+	r.Operation.Extensions["x-websocket"] = map[string]any{
+		"receive": &receive.SchemaRef,
+		"send":    &send.SchemaRef,
+	}
+}
+
+// hijackableWriter gives access to the connection of the response writers wrapped by the middlewares,
+// with [http.ResponseController].
+type hijackableWriter struct {
+	http.ResponseWriter
+}
+
+func (w hijackableWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
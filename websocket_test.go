@@ -0,0 +1,90 @@
+package fuego
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+type ChatMessage struct {
+	Text string `json:"text" validate:"required"`
+}
+
+type ChatEvent struct {
+	Echo  string `json:"echo,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func echoChat(conn *WebsocketConn[ChatMessage, ChatEvent]) error {
+	for {
+		message, err := conn.Receive()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var httpErr HTTPError
+		if errors.As(err, &httpErr) {
+			err = conn.Send(ChatEvent{Error: httpErr.Title})
+		} else if err == nil {
+			err = conn.Send(ChatEvent{Echo: message.Text})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestWebsocket(t *testing.T) {
+	s := NewServer() // The logging middleware wraps the response writer
+	Websocket(s, "/chat", echoChat, OptionWebsocketOrigins("https://*.example.com"))
+	server := httptest.NewServer(s.Mux)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/chat"
+
+	t.Run("typed messages", func(t *testing.T) {
+		conn, err := websocket.Dial(wsURL, "", server.URL)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, websocket.Message.Send(conn, `{"text":"hello"}`))
+		var event ChatEvent
+		require.NoError(t, websocket.JSON.Receive(conn, &event))
+		require.Equal(t, ChatEvent{Echo: "hello"}, event)
+
+		t.Run("invalid messages", func(t *testing.T) {
+			require.NoError(t, websocket.Message.Send(conn, `{"text":`))
+			var event ChatEvent
+			require.NoError(t, websocket.JSON.Receive(conn, &event))
+			require.Equal(t, ChatEvent{Error: "Invalid message"}, event)
+
+			require.NoError(t, websocket.Message.Send(conn, `{}`))
+			require.NoError(t, websocket.JSON.Receive(conn, &event))
+			require.Equal(t, ChatEvent{Error: "Validation Error"}, event)
+		})
+	})
+
+	t.Run("allowed origins", func(t *testing.T) {
+		conn, err := websocket.Dial(wsURL, "", "https://app.example.com")
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+
+		_, err = websocket.Dial(wsURL, "", "https://evil.com")
+		require.Error(t, err)
+	})
+
+	t.Run("documented in the spec", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/chat").Get
+		require.NotNil(t, operation.Responses.Status(http.StatusSwitchingProtocols))
+		require.Nil(t, operation.Responses.Status(http.StatusOK))
+
+		documented := operation.Extensions["x-websocket"].(map[string]any)
+		require.Equal(t, "#/components/schemas/ChatMessage", documented["receive"].(*openapi3.SchemaRef).Ref)
+		require.Equal(t, "#/components/schemas/ChatEvent", documented["send"].(*openapi3.SchemaRef).Ref)
+	})
+}
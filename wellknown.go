@@ -0,0 +1,146 @@
+package fuego
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// WellKnownConfig configures the documents served by [WellKnown].
+// The documents set in the config take precedence over the files of FS.
+type WellKnownConfig struct {
+	// Content of /robots.txt.
+	RobotsTxt string
+	// Content of /favicon.ico.
+	Favicon []byte
+	// Served at /.well-known/security.txt.
+	SecurityTxt *SecurityTxt
+	// Other documents served under /.well-known/, by name, like "apple-app-site-association".
+	Documents map[string][]byte
+	// Filesystem of the robots.txt, favicon.ico and .well-known/* files, like an embedded directory.
+	// Embed it with the all: prefix, so that the .well-known directory is included.
+	FS fs.FS
+	// Cache-Control header of the documents. Defaults to one day.
+	CacheControl string
+}
+
+// SecurityTxt is the security.txt document of the server, telling security researchers
+// how to report vulnerabilities. See RFC 9116.
+type SecurityTxt struct {
+	// URIs to report vulnerabilities, like "mailto:security@example.com". Required.
+	Contact []string
+	// Date after which the document is stale. Defaults to one year after the server start.
+	Expires            time.Time
+	Encryption         []string
+	Acknowledgments    []string
+	PreferredLanguages []string
+	Canonical          []string
+	Policy             []string
+	Hiring             []string
+}
+
+func (s SecurityTxt) String() string {
+	var b strings.Builder
+	field := func(name string, values ...string) {
+		for _, value := range values {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	field("Contact", s.Contact...)
+	field("Expires", s.Expires.UTC().Format(time.RFC3339))
+	field("Encryption", s.Encryption...)
+	field("Acknowledgments", s.Acknowledgments...)
+	if len(s.PreferredLanguages) > 0 {
+		field("Preferred-Languages", strings.Join(s.PreferredLanguages, ", "))
+	}
+	field("Canonical", s.Canonical...)
+	field("Policy", s.Policy...)
+	field("Hiring", s.Hiring...)
+	return b.String()
+}
+
+// WellKnown serves the robots.txt, the favicon, the security.txt and the /.well-known/ documents
+// of the server, from the config or from files. The routes are hidden from the OpenAPI spec.
+// It panics if a file cannot be read, or if the security.txt has no contact.
+//
+//	//go:embed all:public
+//	var public embed.FS
+//
+//	fuego.WellKnown(s, fuego.WellKnownConfig{
+//		RobotsTxt:   "User-agent: *\nDisallow: /admin/\n",
+//		SecurityTxt: &fuego.SecurityTxt{Contact: []string{"mailto:security@example.com"}},
+//		FS:          must(fs.Sub(public, "public")),
+//	})
+func WellKnown(s *Server, config WellKnownConfig) {
+	if config.CacheControl == "" {
+		config.CacheControl = "public, max-age=86400"
+	}
+	documents := map[string][]byte{}
+
+	if config.FS != nil {
+		names, err := fs.Glob(config.FS, ".well-known/*")
+		if err != nil {
+			panic(fmt.Errorf("cannot list the well-known documents: %w", err))
+		}
+		for _, name := range append([]string{"robots.txt", "favicon.ico"}, names...) {
+			content, err := fs.ReadFile(config.FS, name)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				panic(fmt.Errorf("cannot read %s: %w", name, err))
+			}
+			documents["/"+name] = content
+		}
+	}
+
+	if config.RobotsTxt != "" {
+		documents["/robots.txt"] = []byte(config.RobotsTxt)
+	}
+	if config.Favicon != nil {
+		documents["/favicon.ico"] = config.Favicon
+	}
+	if config.SecurityTxt != nil {
+		securityTxt := *config.SecurityTxt
+		if len(securityTxt.Contact) == 0 {
+			panic("security.txt must have a contact")
+		}
+		if securityTxt.Expires.IsZero() {
+			securityTxt.Expires = s.now().AddDate(1, 0, 0)
+		}
+		documents["/.well-known/security.txt"] = []byte(securityTxt.String())
+	}
+	for name, content := range config.Documents {
+		documents["/.well-known/"+strings.TrimPrefix(name, "/")] = content
+	}
+
+	for name, content := range documents {
+		contentType := wellKnownContentType(name, content)
+		GetStd(s, name, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Cache-Control", config.CacheControl)
+			_, _ = w.Write(content)
+		}, OptionHide())
+	}
+}
+
+// wellKnownContentType returns the content type of the document, from its extension or its content.
+// The documents without extension, like apple-app-site-association, are often JSON.
+func wellKnownContentType(name string, content []byte) string {
+	if name == "/favicon.ico" {
+		return "image/x-icon"
+	}
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+	if json.Valid(content) {
+		return "application/json"
+	}
+	return http.DetectContentType(content)
+}
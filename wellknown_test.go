@@ -0,0 +1,75 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWellKnown(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewServer(WithoutLogger(), WithClock(func() time.Time { return now }))
+	WellKnown(s, WellKnownConfig{
+		RobotsTxt: "User-agent: *\nDisallow: /admin/\n",
+		SecurityTxt: &SecurityTxt{
+			Contact:            []string{"mailto:security@example.com", "https://example.com/security"},
+			PreferredLanguages: []string{"en", "fr"},
+		},
+		Documents: map[string][]byte{"apple-app-site-association": []byte(`{"applinks":{}}`)},
+		FS: fstest.MapFS{
+			"robots.txt":                  {Data: []byte("overridden by the config")},
+			"favicon.ico":                 {Data: []byte{0, 0, 1, 0}},
+			".well-known/assetlinks.json": {Data: []byte(`[]`)},
+		},
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("robots.txt", func(t *testing.T) {
+		w := get("/robots.txt")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "User-agent: *\nDisallow: /admin/\n", w.Body.String())
+		require.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("favicon", func(t *testing.T) {
+		w := get("/favicon.ico")
+		require.Equal(t, []byte{0, 0, 1, 0}, w.Body.Bytes())
+		require.Equal(t, "image/x-icon", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("security.txt", func(t *testing.T) {
+		w := get("/.well-known/security.txt")
+		require.Equal(t, "Contact: mailto:security@example.com\n"+
+			"Contact: https://example.com/security\n"+
+			"Expires: 2026-01-01T00:00:00Z\n"+
+			"Preferred-Languages: en, fr\n", w.Body.String())
+	})
+
+	t.Run("well-known documents", func(t *testing.T) {
+		w := get("/.well-known/apple-app-site-association")
+		require.Equal(t, `{"applinks":{}}`, w.Body.String())
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		w = get("/.well-known/assetlinks.json")
+		require.Equal(t, `[]`, w.Body.String())
+	})
+
+	t.Run("hidden from the spec", func(t *testing.T) {
+		require.Nil(t, s.OpenAPI.Description().Paths.Find("/robots.txt"))
+	})
+
+	t.Run("security.txt without contact", func(t *testing.T) {
+		require.Panics(t, func() {
+			WellKnown(NewServer(WithoutLogger()), WellKnownConfig{SecurityTxt: &SecurityTxt{}})
+		})
+	})
+}
@@ -0,0 +1,149 @@
+package fuego
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// XLSX is a list sent as an Excel spreadsheet, whatever the Accept header of the request.
+// The spreadsheet has a header row, then a row per item. The columns are the JSON attributes of the items,
+// and the xlsx struct tag overrides their header, or skips them with "-":
+//
+//	type Order struct {
+//		ID       int    `json:"id" xlsx:"Order number"`
+//		Customer string `json:"customer"`
+//		Secret   string `json:"secret" xlsx:"-"`
+//	}
+//
+//	fuego.Get(s, "/orders.xlsx", func(c fuego.ContextNoBody) (fuego.XLSX[Order], error) {
+//		orders, err := listOrders(c)
+//		return fuego.XLSX[Order]{Rows: orders, Name: "orders.xlsx"}, err
+//	})
+//
+// The text cells starting like a formula are escaped, see [ExportXLSX].
+//
+// The lists returned as []T are also sent as spreadsheets to the clients accepting
+// application/vnd.openxmlformats-officedocument.spreadsheetml.sheet: declare it with [OptionResponseContentType].
+type XLSX[T any] struct {
+	Rows []T
+	// Name of the downloaded file, like "orders.xlsx". If set, the file is sent as an attachment.
+	Name string
+}
+
+func (x XLSX[T]) xlsx() (reflect.Value, string) {
+	return reflect.ValueOf(x.Rows), x.Name
+}
+
+// xlsxResponse is implemented by [XLSX].
+type xlsxResponse interface {
+	xlsx() (rows reflect.Value, name string)
+}
+
+func isXLSXResponse[T any]() bool {
+	return reflect.TypeFor[T]().Implements(reflect.TypeFor[xlsxResponse]())
+}
+
+// SendXLSX sends a list, or an [XLSX] response, as an Excel spreadsheet.
+// Declared as a variable to be able to override it for clients that need to customize serialization.
+// It fails without writing to the response writer if the answer is not a list.
+var SendXLSX = func(w http.ResponseWriter, _ *http.Request, ans any) error {
+	var items reflect.Value
+	var name string
+	if response, ok := ans.(xlsxResponse); ok {
+		items, name = response.xlsx()
+	} else {
+		items = reflect.ValueOf(ans)
+		for items.Kind() == reflect.Pointer && !items.IsNil() {
+			items = items.Elem()
+		}
+	}
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return fmt.Errorf("cannot send %T as a spreadsheet: it is not a list", ans)
+	}
+
+	w.Header().Set("Content-Type", xlsxContentType)
+	if name != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	}
+	return writeXLSX(w, items)
+}
+
+// writeXLSX writes a workbook with a single sheet: the header row and the rows of the items.
+// The rows are written to w as they are converted to XML.
+func writeXLSX(w io.Writer, items reflect.Value) error {
+	headers, rows, err := exportRows(items, "xlsx")
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	} {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, file.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeXLSXSheet(sheet, headers, rows); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func writeXLSXSheet(w io.Writer, headers []string, rows [][]exportCell) error {
+	if _, err := io.WriteString(w, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+	header := make([]exportCell, len(headers))
+	for i, value := range headers {
+		header[i] = exportCell{value: value}
+	}
+	for i, row := range append([][]exportCell{header}, rows...) {
+		_, _ = io.WriteString(w, `<row r="`+strconv.Itoa(i+1)+`">`)
+		for _, cell := range row {
+			if cell.number {
+				_, _ = io.WriteString(w, `<c><v>`+cell.value+`</v></c>`)
+				continue
+			}
+			_, _ = io.WriteString(w, `<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(w, []byte(cell.value)); err != nil {
+				return err
+			}
+			_, _ = io.WriteString(w, `</t></is></c>`)
+		}
+		if _, err := io.WriteString(w, `</row>`); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, `</sheetData></worksheet>`)
+	return err
+}
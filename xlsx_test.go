@@ -0,0 +1,101 @@
+package fuego
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type spreadsheetOrder struct {
+	ID       int    `json:"id" xlsx:"Order number"`
+	Customer string `json:"customer"`
+	Secret   string `json:"secret" xlsx:"-"`
+}
+
+// readSheet returns the XML of the sheet of an XLSX file.
+func readSheet(t *testing.T, body []byte) string {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	f, err := archive.Open("xl/worksheets/sheet1.xml")
+	require.NoError(t, err)
+	defer f.Close()
+	sheet, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(sheet)
+}
+
+func TestXLSX(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Get(s, "/orders.xlsx", func(c ContextNoBody) (XLSX[spreadsheetOrder], error) {
+		return XLSX[spreadsheetOrder]{
+			Rows: []spreadsheetOrder{{ID: 1, Customer: "Ada <ada@example.com>", Secret: "s3cr3t"}},
+			Name: "orders.xlsx",
+		}, nil
+	})
+
+	t.Run("sent as a spreadsheet whatever the Accept header", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/orders.xlsx", nil)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, xlsxContentType, w.Header().Get("Content-Type"))
+		require.Equal(t, `attachment; filename="orders.xlsx"`, w.Header().Get("Content-Disposition"))
+		sheet := readSheet(t, w.Body.Bytes())
+		require.Contains(t, sheet, `<row r="1"><c t="inlineStr"><is><t xml:space="preserve">Order number</t></is></c>`+
+			`<c t="inlineStr"><is><t xml:space="preserve">customer</t></is></c></row>`)
+		require.Contains(t, sheet, `<row r="2"><c><v>1</v></c><c t="inlineStr"><is><t xml:space="preserve">Ada &lt;ada@example.com&gt;</t></is></c></row>`)
+		require.NotContains(t, sheet, "s3cr3t")
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		content := s.OutputOpenAPISpec().Paths.Find("/orders.xlsx").Get.Responses.Status(http.StatusOK).Value.Content
+		require.Len(t, content, 1)
+		require.Equal(t, "binary", content.Get(xlsxContentType).Schema.Value.Format)
+	})
+}
+
+func TestSendXLSX(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Get(s, "/orders", func(c ContextNoBody) ([]spreadsheetOrder, error) {
+		return []spreadsheetOrder{{ID: 1, Customer: "Ada"}, {ID: 2, Customer: "Grace"}}, nil
+	}, OptionResponseContentType("application/json", xlsxContentType))
+
+	t.Run("negotiated with the Accept header", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		r.Header.Set("Accept", xlsxContentType)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, xlsxContentType, w.Header().Get("Content-Type"))
+		require.Empty(t, w.Header().Get("Content-Disposition"))
+		require.Contains(t, readSheet(t, w.Body.Bytes()), `<row r="3"><c><v>2</v></c>`)
+	})
+
+	t.Run("json by default", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	})
+
+	t.Run("not a list", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		require.EqualError(t, SendXLSX(w, nil, spreadsheetOrder{}), "cannot send fuego.spreadsheetOrder as a spreadsheet: it is not a list")
+		require.Empty(t, w.Header())
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		content := s.OutputOpenAPISpec().Paths.Find("/orders").Get.Responses.Status(http.StatusOK).Value.Content
+		require.NotNil(t, content.Get("application/json"))
+		require.Equal(t, "binary", content.Get(xlsxContentType).Schema.Value.Format)
+	})
+}